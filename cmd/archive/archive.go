@@ -0,0 +1,195 @@
+package archive
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/archive"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// archiveFileMode keeps exported archives readable by the current user only
+const archiveFileMode = 0o600
+
+// fileOptions holds the options of the encrypt and decrypt commands
+type fileOptions struct {
+	In      string
+	Out     string
+	KeyFile string
+}
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Encrypt and decrypt exported backup archives",
+		Long: `Exported backup archives, such as settings exports written to local files or an offsite bucket, are encrypted
+with AES-256-GCM when archives.encryption.secretName is configured. The Secret holds a base64 encoded 256-bit key,
+e.g. created with:
+
+  kubectl create secret generic backup-encryption-key --from-literal=key=$(openssl rand -base64 32)
+
+Use these commands to decrypt an archive for inspection or to encrypt an archive created elsewhere. With --key-file
+the key is read from a file instead of the Secret, e.g. when the cluster holding the Secret is unavailable.`,
+	}
+
+	cmd.AddCommand(encryptCmd(cliCtx))
+	cmd.AddCommand(decryptCmd(cliCtx))
+	return cmd
+}
+
+func encryptCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &fileOptions{}
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypt an archive with the configured key",
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runEncrypt(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	addFileFlags(cmd, opts)
+	return cmd
+}
+
+func decryptCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &fileOptions{}
+	cmd := &cobra.Command{
+		Use:   "decrypt",
+		Short: "Decrypt an archive with the configured key",
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runDecrypt(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	addFileFlags(cmd, opts)
+	return cmd
+}
+
+func addFileFlags(cmd *cobra.Command, opts *fileOptions) {
+	cmd.Flags().StringVar(&opts.In, "in", "", "Archive file to read (required)")
+	cmd.Flags().StringVar(&opts.Out, "out", "", "File to write (required)")
+	cmd.Flags().StringVar(&opts.KeyFile, "key-file", "", "File holding the base64 encoded key (default: the configured Secret)")
+	_ = cmd.MarkFlagRequired("in")
+	_ = cmd.MarkFlagRequired("out")
+}
+
+func runEncrypt(cliCtx *config.Context, opts *fileOptions) error {
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	data, err := os.ReadFile(opts.In)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("failed to read archive: %w", err))
+	}
+	if archive.IsEncrypted(data) {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is already encrypted", opts.In))
+	}
+
+	key, err := loadKey(cliCtx, opts.KeyFile, log)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := archive.Encrypt(data, key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(opts.Out, encrypted, archiveFileMode); err != nil {
+		return fmt.Errorf("failed to write encrypted archive: %w", err)
+	}
+
+	log.Successf("Encrypted '%s' to '%s'", opts.In, opts.Out)
+	return nil
+}
+
+func runDecrypt(cliCtx *config.Context, opts *fileOptions) error {
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	data, err := os.ReadFile(opts.In)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("failed to read archive: %w", err))
+	}
+	if !archive.IsEncrypted(data) {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is not an encrypted archive", opts.In))
+	}
+
+	key, err := loadKey(cliCtx, opts.KeyFile, log)
+	if err != nil {
+		return err
+	}
+
+	decrypted, err := archive.Decrypt(data, key)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ValidationFailed, err)
+	}
+	if err := os.WriteFile(opts.Out, decrypted, archiveFileMode); err != nil {
+		return fmt.Errorf("failed to write decrypted archive: %w", err)
+	}
+
+	log.Successf("Decrypted '%s' to '%s'", opts.In, opts.Out)
+	return nil
+}
+
+// loadKey reads the key from keyFile when given, and otherwise from the Secret configured in archives.encryption
+func loadKey(cliCtx *config.Context, keyFile string, log *logger.Logger) ([]byte, error) {
+	if keyFile != "" {
+		value, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("failed to read key file: %w", err))
+		}
+		key, err := archive.ParseKey(string(value))
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.Usage, err)
+		}
+		return key, nil
+	}
+
+	// Create Kubernetes client
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName, config.WithHelmValues(cliCtx.Config.HelmValues))
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+
+	encryption := cfg.Archives.Encryption
+	if encryption.SecretName == "" {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("archives.encryption.secretName is not configured: configure it or use --key-file"))
+	}
+
+	log.Debugf("Reading encryption key from Secret '%s'...", encryption.SecretName)
+	key, err := archive.LoadKey(k8sClient.Clientset(), cliCtx.Config.Namespace, encryption.SecretName, encryption.SecretKey)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, err)
+	}
+	return key, nil
+}
+
+// Sealer returns the Sealer that exported archives are written with. Encryption is enabled when
+// archives.encryption.secretName is configured, in which case the key must be readable and valid.
+func Sealer(k8sClient *k8s.Client, namespace string, cfg *config.Config, log *logger.Logger) (*archive.Sealer, error) {
+	encryption := cfg.Archives.Encryption
+	if encryption.SecretName == "" {
+		log.Debugf("Archive encryption is not configured, archives are written unencrypted")
+		return archive.NewSealer(nil), nil
+	}
+
+	key, err := archive.LoadKey(k8sClient.Clientset(), namespace, encryption.SecretName, encryption.SecretKey)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, err)
+	}
+	log.Debugf("Archives are encrypted with the key from Secret '%s'", encryption.SecretName)
+	return archive.NewSealer(key), nil
+}
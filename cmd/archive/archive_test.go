@@ -0,0 +1,115 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/archive"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, archive.KeySize)
+
+// TestCmd_Unit tests the command structure
+func TestCmd_Unit(t *testing.T) {
+	cmd := Cmd(config.NewContext())
+
+	assert.Equal(t, "archive", cmd.Use)
+	assert.NotEmpty(t, cmd.Long)
+
+	for _, name := range []string{"encrypt", "decrypt"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.Equal(t, name, sub.Use)
+		assert.NotNil(t, sub.Flags().Lookup("in"))
+		assert.NotNil(t, sub.Flags().Lookup("out"))
+		assert.NotNil(t, sub.Flags().Lookup("key-file"))
+	}
+}
+
+func TestRunEncryptDecrypt_KeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(testKey)+"\n"), 0o600))
+
+	plain := filepath.Join(dir, "settings.json")
+	encrypted := filepath.Join(dir, "settings.json.enc")
+	decrypted := filepath.Join(dir, "settings.decrypted.json")
+	require.NoError(t, os.WriteFile(plain, []byte(`{"persistent": {}}`), 0o600))
+
+	cliCtx := config.NewContext()
+	cliCtx.Config.LogLevel = logger.LevelError
+
+	require.NoError(t, runEncrypt(cliCtx, &fileOptions{In: plain, Out: encrypted, KeyFile: keyFile}))
+	data, err := os.ReadFile(encrypted)
+	require.NoError(t, err)
+	assert.True(t, archive.IsEncrypted(data))
+
+	info, err := os.Stat(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(archiveFileMode), info.Mode().Perm())
+
+	require.NoError(t, runDecrypt(cliCtx, &fileOptions{In: encrypted, Out: decrypted, KeyFile: keyFile}))
+	data, err = os.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"persistent": {}}`, string(data))
+
+	// Encrypting twice or decrypting plaintext is a usage error
+	err = runEncrypt(cliCtx, &fileOptions{In: encrypted, Out: filepath.Join(dir, "twice"), KeyFile: keyFile})
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	err = runDecrypt(cliCtx, &fileOptions{In: plain, Out: filepath.Join(dir, "plain"), KeyFile: keyFile})
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+
+	// A wrong key fails validation
+	wrongKey := filepath.Join(dir, "wrong")
+	require.NoError(t, os.WriteFile(wrongKey, []byte(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x01}, archive.KeySize))), 0o600))
+	err = runDecrypt(cliCtx, &fileOptions{In: encrypted, Out: decrypted, KeyFile: wrongKey})
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+}
+
+func TestSealer(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-encryption-key", Namespace: "test-ns"},
+		Data:       map[string][]byte{"key": []byte(base64.StdEncoding.EncodeToString(testKey))},
+	})
+	k8sClient := k8s.NewTestClient(clientset)
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("not configured", func(t *testing.T) {
+		sealer, err := Sealer(k8sClient, "test-ns", &config.Config{}, log)
+
+		require.NoError(t, err)
+		assert.False(t, sealer.Enabled())
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Archives.Encryption.SecretName = "backup-encryption-key"
+
+		sealer, err := Sealer(k8sClient, "test-ns", cfg, log)
+
+		require.NoError(t, err)
+		assert.True(t, sealer.Enabled())
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		cfg := &config.Config{}
+		cfg.Archives.Encryption.SecretName = "missing"
+
+		_, err := Sealer(k8sClient, "test-ns", cfg, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.ConfigError, exitcode.Of(err))
+	})
+}
@@ -0,0 +1,198 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/catalog"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// helmChartLabel is set by Helm on the configuration ConfigMap to the chart name and version
+const helmChartLabel = "helm.sh/chart"
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Browse the backup catalog stored in the backup bucket",
+		Long: `The backup catalog is an inventory of backups across components, kept as JSON manifests under the
+catalog/ prefix of the backup bucket, independent of the datastores that took the backups.
+
+Manifests of new Elasticsearch snapshots are written by 'serve' after every check, or explicitly with 'catalog sync'.`,
+	}
+
+	cmd.AddCommand(listCmd(cliCtx))
+	cmd.AddCommand(syncCmd(cliCtx))
+	return cmd
+}
+
+func listCmd(cliCtx *config.Context) *cobra.Command {
+	var component string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List backups recorded in the catalog",
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runList(cliCtx, component); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&component, "component", "", "Only list backups of this component (e.g. elasticsearch)")
+	return cmd
+}
+
+func syncCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "Record completed Elasticsearch snapshots missing from the catalog",
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runSync(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runList(cliCtx *config.Context, component string) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cat, cleanup, err := Open(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env.Log.Infof("Fetching catalog from bucket '%s'...", env.Config.Elasticsearch.SnapshotRepository.Bucket)
+	manifests, err := cat.List(component)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+
+	if len(manifests) == 0 {
+		formatter.PrintMessage("No backups recorded in the catalog")
+		return nil
+	}
+
+	return formatter.PrintTable(manifestsTable(manifests))
+}
+
+func runSync(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cat, cleanup, err := Open(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Setup port-forward to Elasticsearch
+	serviceName := env.Config.Elasticsearch.Service.SnapshotServiceName()
+	localPort := env.Config.Elasticsearch.Service.LocalPortForwardPort
+	remotePort := env.Config.Elasticsearch.Service.Port
+
+	pf, err := portforward.SetupPortForward(env.K8s, cliCtx.Config.Namespace, serviceName, localPort, remotePort, env.Log)
+	if err != nil {
+		return err
+	}
+	defer close(pf.StopChan)
+
+	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", pf.LocalPort), target.ElasticsearchOptions(env.K8s, cliCtx, env.Config, env.Log)...)
+	if err != nil {
+		return fmt.Errorf("failed to create Elasticsearch client: %w", err)
+	}
+
+	repository := env.Config.Elasticsearch.SLM.Repository
+	env.Log.Infof("Recording snapshots of repository '%s' in the catalog...", repository)
+	added, err := cat.SyncElasticsearch(esClient, repository, Metadata(env.K8s, cliCtx), time.Now())
+	for _, name := range added {
+		env.Log.Infof("  - %s", name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(added) == 0 {
+		env.Log.Successf("Catalog is up to date")
+	} else {
+		env.Log.Successf("Recorded %d snapshot(s) in the catalog", len(added))
+	}
+	return nil
+}
+
+// Open returns the catalog in the backup bucket. In-cluster object storage is reached through a
+// port-forward when running outside the cluster, which is closed by the returned cleanup function.
+func Open(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) (*catalog.Catalog, func(), error) {
+	repo := cfg.Elasticsearch.SnapshotRepository
+
+	endpoint, cleanup, err := portforward.ServiceEndpointAddress(k8sClient, repo.Endpoint, cliCtx.Config.Namespace, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := s3.NewClient(s3.EndpointURL(repo.S3Protocol(), endpoint), repo.AccessKey, repo.SecretKey, repo.S3Region(), s3.WithReadOnly(cliCtx.Config.ReadOnly))
+	if err != nil {
+		cleanup()
+		return nil, nil, exitcode.Wrap(exitcode.ConfigError, err)
+	}
+	return catalog.New(client, repo.Bucket), cleanup, nil
+}
+
+// Metadata returns the metadata recorded in manifests written by this run. The chart version is taken
+// from the Helm label of the configuration ConfigMap and left empty when it cannot be determined.
+func Metadata(k8sClient *k8s.Client, cliCtx *config.Context) catalog.Metadata {
+	meta := catalog.Metadata{Namespace: cliCtx.Config.Namespace, RunID: cliCtx.RunID}
+
+	cm, err := k8sClient.Clientset().CoreV1().ConfigMaps(cliCtx.Config.Namespace).Get(context.Background(), cliCtx.Config.ConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		meta.ChartVersion = cm.Labels[helmChartLabel]
+	}
+	return meta
+}
+
+// manifestsTable converts catalog manifests into a table
+func manifestsTable(manifests []catalog.Manifest) output.Table {
+	table := output.Table{
+		Headers:      []string{"COMPONENT", "SNAPSHOT", "START TIME", "STATE", "INDICES", "SIZE", "CLUSTER", "CHART"},
+		Rows:         make([][]string, 0, len(manifests)),
+		StateColumns: []string{"STATE"},
+	}
+
+	for _, manifest := range manifests {
+		row := []string{
+			manifest.Component,
+			manifest.Snapshot,
+			manifest.StartTime.Local().Format(time.RFC3339),
+			manifest.State,
+			fmt.Sprintf("%d", len(manifest.Indices)),
+			output.FormatBytes(manifest.SizeInBytes),
+			manifest.Cluster,
+			manifest.ChartVersion,
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
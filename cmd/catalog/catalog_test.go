@@ -0,0 +1,82 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/catalog"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestCatalogCmd_Unit tests the command structure
+func TestCatalogCmd_Unit(t *testing.T) {
+	cmd := Cmd(config.NewContext())
+
+	assert.Equal(t, "catalog", cmd.Use)
+	assert.NotEmpty(t, cmd.Long)
+
+	names := make([]string, 0, len(cmd.Commands()))
+	for _, sub := range cmd.Commands() {
+		names = append(names, sub.Name())
+		assert.NotNil(t, sub.Run, sub.Name())
+	}
+	assert.ElementsMatch(t, []string{"list", "sync"}, names)
+
+	list, _, err := cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	assert.NotNil(t, list.Flags().Lookup("component"))
+}
+
+func TestMetadata(t *testing.T) {
+	cliCtx := config.NewContext()
+	cliCtx.Config.Namespace = "suse-observability"
+	cliCtx.Config.ConfigMapName = "backup-config"
+
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-config",
+			Namespace: "suse-observability",
+			Labels:    map[string]string{helmChartLabel: "suse-observability-2.3.0"},
+		},
+	})
+
+	meta := Metadata(k8s.NewTestClient(clientset), cliCtx)
+
+	assert.Equal(t, "suse-observability", meta.Namespace)
+	assert.Equal(t, "suse-observability-2.3.0", meta.ChartVersion)
+	assert.Equal(t, cliCtx.RunID, meta.RunID)
+
+	// Without the ConfigMap the chart version is unknown
+	meta = Metadata(k8s.NewTestClient(fake.NewSimpleClientset()), cliCtx)
+	assert.Empty(t, meta.ChartVersion)
+}
+
+func TestManifestsTable(t *testing.T) {
+	manifests := []catalog.Manifest{
+		{
+			Component:    catalog.ComponentElasticsearch,
+			Snapshot:     "snap-1",
+			State:        "SUCCESS",
+			Indices:      []string{"a", "b"},
+			SizeInBytes:  2048,
+			StartTime:    time.Date(2025, 1, 10, 3, 0, 0, 0, time.UTC),
+			Cluster:      "sts-es",
+			ChartVersion: "suse-observability-2.3.0",
+		},
+	}
+
+	table := manifestsTable(manifests)
+
+	require.Len(t, table.Rows, 1)
+	assert.Equal(t, []string{"STATE"}, table.StateColumns)
+	assert.Equal(t, "snap-1", table.Rows[0][1])
+	assert.Equal(t, "2", table.Rows[0][4])
+	assert.Equal(t, "2.0 KiB", table.Rows[0][5])
+	assert.Equal(t, "suse-observability-2.3.0", table.Rows[0][7])
+}
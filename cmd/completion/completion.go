@@ -0,0 +1,73 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+func Cmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a shell completion script for sts-backup.
+
+Besides commands and flags, --namespace completes the namespaces of the cluster
+and --snapshot-name completes the snapshots available in the configured repository.
+
+Bash:
+  source <(sts-backup completion bash)
+
+Zsh:
+  sts-backup completion zsh > "${fpath[1]}/_sts-backup"
+
+Fish:
+  sts-backup completion fish > ~/.config/fish/completions/sts-backup.fish`,
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := generate(cmd.Root(), args[0], os.Stdout); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// generate writes the completion script for shell to w
+func generate(root *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	default:
+		return fmt.Errorf("unsupported shell: %s", shell)
+	}
+}
+
+// Namespaces returns a completion function listing the namespaces of the cluster
+func Namespaces(cliCtx *config.Context) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, false, cliCtx.Config.K8sClientOptions()...)
+		if err != nil {
+			cobra.CompDebugln("failed to create Kubernetes client: "+err.Error(), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		namespaces, err := k8sClient.ListNamespaces()
+		if err != nil {
+			cobra.CompDebugln("failed to complete namespaces: "+err.Error(), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return namespaces, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,51 @@
+package completion
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompletionCmd_Unit tests the command structure
+func TestCompletionCmd_Unit(t *testing.T) {
+	cmd := Cmd()
+
+	assert.Equal(t, "completion [bash|zsh|fish]", cmd.Use)
+	assert.Equal(t, "Generate shell completion scripts", cmd.Short)
+	assert.Equal(t, []string{"bash", "zsh", "fish"}, cmd.ValidArgs)
+	assert.NotNil(t, cmd.Run)
+
+	assert.Error(t, cmd.Args(cmd, []string{}))
+	assert.Error(t, cmd.Args(cmd, []string{"powershell"}))
+	assert.NoError(t, cmd.Args(cmd, []string{"zsh"}))
+}
+
+func TestGenerate(t *testing.T) {
+	root := &cobra.Command{Use: "sts-backup"}
+	root.AddCommand(Cmd())
+
+	tests := []struct {
+		shell    string
+		contains string
+	}{
+		{shell: "bash", contains: "__start_sts-backup"},
+		{shell: "zsh", contains: "#compdef sts-backup"},
+		{shell: "fish", contains: "complete -c sts-backup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, generate(root, tt.shell, buf))
+			assert.Contains(t, buf.String(), tt.contains)
+		})
+	}
+}
+
+func TestGenerate_UnsupportedShell(t *testing.T) {
+	err := generate(&cobra.Command{Use: "sts-backup"}, "powershell", &bytes.Buffer{})
+	assert.Error(t, err)
+}
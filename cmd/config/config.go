@@ -0,0 +1,228 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Presence of the Secret next to a discovered ConfigMap
+const (
+	secretFound     = "yes"
+	secretMissing   = "no"
+	secretForbidden = "forbidden"
+)
+
+// location is a namespace holding backup configuration
+type location struct {
+	Namespace string
+	ConfigMap string
+	Secret    string
+	// HasConfig is false when the ConfigMap lacks the config key LoadConfig reads
+	HasConfig bool
+}
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the backup configuration",
+	}
+
+	cmd.AddCommand(discoverCmd(cliCtx))
+	return cmd
+}
+
+func discoverCmd(cliCtx *config.Context) *cobra.Command {
+	var selector string
+	cmd := &cobra.Command{
+		Use:   "discover",
+		Short: "Find the namespaces holding backup configuration",
+		Long: `Search all namespaces for the backup configuration ConfigMap (--configmap) and, with --selector, for
+ConfigMaps matching a label selector, and list the namespaces that have one together with the presence of the
+Secret (--secret). Use it to find the right --namespace on clusters running several SUSE Observability instances.
+
+Namespaces the current user is not allowed to read are skipped.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runDiscover(cliCtx, selector); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Also report ConfigMaps matching this label selector (e.g. app.kubernetes.io/part-of=suse-observability)")
+	return cmd
+}
+
+func runDiscover(cliCtx *config.Context, selector string) error {
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	// Create Kubernetes client
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	namespaces, err := k8sClient.ListNamespaces()
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+
+	log.Infof("Searching %d namespaces for backup configuration...", len(namespaces))
+
+	locations, skipped, err := discover(k8sClient.Clientset(), namespaces, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName, selector)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	if len(skipped) > 0 {
+		log.Warningf("Skipped %d namespaces that are not accessible: %v", len(skipped), skipped)
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+
+	if len(locations) == 0 {
+		formatter.PrintMessage("No backup configuration found")
+		return nil
+	}
+
+	return formatter.PrintTable(locationsTable(locations))
+}
+
+// discover looks up the backup configuration in each namespace: the ConfigMap named configMapName and, when
+// selector is set, the ConfigMaps matching it. Namespaces the user may not read are returned as skipped.
+func discover(clientset kubernetes.Interface, namespaces []string, configMapName, secretName, selector string) ([]location, []string, error) {
+	ctx := context.Background()
+	var locations []location
+	var skipped []string
+
+	for _, namespace := range namespaces {
+		found, err := namespaceConfigMaps(ctx, clientset, namespace, configMapName, selector)
+		if apierrors.IsForbidden(err) {
+			skipped = append(skipped, namespace)
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(found) == 0 {
+			continue
+		}
+
+		secret, err := secretPresence(ctx, clientset, namespace, secretName)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, cm := range found {
+			_, hasConfig := cm.Data["config"]
+			locations = append(locations, location{
+				Namespace: namespace,
+				ConfigMap: cm.Name,
+				Secret:    secret,
+				HasConfig: hasConfig,
+			})
+		}
+	}
+
+	sort.SliceStable(locations, func(i, j int) bool {
+		return locations[i].Namespace < locations[j].Namespace
+	})
+	return locations, skipped, nil
+}
+
+// ConfiguredNamespaces returns the namespaces whose backup ConfigMap holds the configuration, for commands running
+// against every installation on a cluster, and the namespaces the user may not read
+func ConfiguredNamespaces(clientset kubernetes.Interface, namespaces []string, configMapName, secretName string) ([]string, []string, error) {
+	locations, skipped, err := discover(clientset, namespaces, configMapName, secretName, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	configured := make([]string, 0, len(locations))
+	for _, loc := range locations {
+		if loc.HasConfig {
+			configured = append(configured, loc.Namespace)
+		}
+	}
+	return configured, skipped, nil
+}
+
+// namespaceConfigMaps returns the ConfigMap named configMapName and those matching selector in namespace
+func namespaceConfigMaps(ctx context.Context, clientset kubernetes.Interface, namespace, configMapName, selector string) ([]corev1.ConfigMap, error) {
+	var found []corev1.ConfigMap
+	seen := map[string]bool{}
+
+	if configMapName != "" {
+		cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			// not configured in this namespace
+		case err != nil:
+			return nil, fmt.Errorf("failed to get ConfigMap '%s' in namespace '%s': %w", configMapName, namespace, err)
+		default:
+			found = append(found, *cm)
+			seen[cm.Name] = true
+		}
+	}
+
+	if selector != "" {
+		list, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ConfigMaps in namespace '%s': %w", namespace, err)
+		}
+		for _, cm := range list.Items {
+			if !seen[cm.Name] {
+				found = append(found, cm)
+				seen[cm.Name] = true
+			}
+		}
+	}
+	return found, nil
+}
+
+// secretPresence reports whether the Secret exists in namespace, without reading its contents
+func secretPresence(ctx context.Context, clientset kubernetes.Interface, namespace, secretName string) (string, error) {
+	if secretName == "" {
+		return secretMissing, nil
+	}
+	_, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return secretFound, nil
+	case apierrors.IsNotFound(err):
+		return secretMissing, nil
+	case apierrors.IsForbidden(err):
+		return secretForbidden, nil
+	default:
+		return "", fmt.Errorf("failed to get Secret '%s' in namespace '%s': %w", secretName, namespace, err)
+	}
+}
+
+// locationsTable converts discovered locations into a table
+func locationsTable(locations []location) output.Table {
+	table := output.Table{
+		Headers: []string{"NAMESPACE", "CONFIGMAP", "SECRET", "CONFIG KEY"},
+		Rows:    make([][]string, 0, len(locations)),
+	}
+
+	for _, loc := range locations {
+		configKey := "yes"
+		if !loc.HasConfig {
+			configKey = "missing"
+		}
+		table.Rows = append(table.Rows, []string{loc.Namespace, loc.ConfigMap, loc.Secret, configKey})
+	}
+
+	return table
+}
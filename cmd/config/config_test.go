@@ -0,0 +1,126 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestConfigCmd_Unit tests the command structure
+func TestConfigCmd_Unit(t *testing.T) {
+	cliCtx := config.NewContext()
+	cmd := Cmd(cliCtx)
+
+	assert.Equal(t, "config", cmd.Use)
+	require.Len(t, cmd.Commands(), 1)
+
+	discover := cmd.Commands()[0]
+	assert.Equal(t, "discover", discover.Use)
+	assert.NotEmpty(t, discover.Long)
+	assert.NotNil(t, discover.Flags().Lookup("selector"))
+}
+
+func TestDiscover(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.DefaultConfigMapName, Namespace: "tenant-b"},
+			Data:       map[string]string{"config": "elasticsearch: {}"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: config.DefaultSecretName, Namespace: "tenant-b"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.DefaultConfigMapName, Namespace: "tenant-a"},
+			Data:       map[string]string{"other": "value"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "custom-backup", Namespace: "tenant-c", Labels: map[string]string{"backup": "true"}},
+			Data:       map[string]string{"config": "elasticsearch: {}"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "tenant-c"},
+		},
+	)
+
+	namespaces := []string{"tenant-c", "tenant-b", "tenant-a", "empty"}
+
+	t.Run("by name", func(t *testing.T) {
+		locations, skipped, err := discover(fakeClient, namespaces, config.DefaultConfigMapName, config.DefaultSecretName, "")
+		require.NoError(t, err)
+		assert.Empty(t, skipped)
+		assert.Equal(t, []location{
+			{Namespace: "tenant-a", ConfigMap: config.DefaultConfigMapName, Secret: secretMissing, HasConfig: false},
+			{Namespace: "tenant-b", ConfigMap: config.DefaultConfigMapName, Secret: secretFound, HasConfig: true},
+		}, locations)
+	})
+
+	t.Run("by name and selector", func(t *testing.T) {
+		locations, _, err := discover(fakeClient, namespaces, config.DefaultConfigMapName, config.DefaultSecretName, "backup=true")
+		require.NoError(t, err)
+		require.Len(t, locations, 3)
+		assert.Equal(t, "tenant-c", locations[2].Namespace)
+		assert.Equal(t, "custom-backup", locations[2].ConfigMap)
+	})
+}
+
+func TestConfiguredNamespaces(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.DefaultConfigMapName, Namespace: "tenant-b"},
+			Data:       map[string]string{"config": "elasticsearch: {}"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.DefaultConfigMapName, Namespace: "tenant-a"},
+			Data:       map[string]string{"config": "elasticsearch: {}"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.DefaultConfigMapName, Namespace: "tenant-c"},
+			Data:       map[string]string{"other": "value"},
+		},
+	)
+
+	namespaces, skipped, err := ConfiguredNamespaces(fakeClient, []string{"tenant-c", "tenant-b", "tenant-a", "empty"}, config.DefaultConfigMapName, config.DefaultSecretName)
+
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	assert.Equal(t, []string{"tenant-a", "tenant-b"}, namespaces, "sorted, without the ConfigMap lacking the config key")
+}
+
+func TestDiscover_SkipsForbiddenNamespaces(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.DefaultConfigMapName, Namespace: "allowed"},
+		Data:       map[string]string{"config": "elasticsearch: {}"},
+	})
+	fakeClient.PrependReactor("get", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "restricted" {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, config.DefaultConfigMapName, nil)
+		}
+		return false, nil, nil
+	})
+
+	locations, skipped, err := discover(fakeClient, []string{"restricted", "allowed"}, config.DefaultConfigMapName, config.DefaultSecretName, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"restricted"}, skipped)
+	require.Len(t, locations, 1)
+	assert.Equal(t, "allowed", locations[0].Namespace)
+}
+
+func TestLocationsTable(t *testing.T) {
+	table := locationsTable([]location{
+		{Namespace: "tenant-a", ConfigMap: "backup", Secret: secretFound, HasConfig: true},
+		{Namespace: "tenant-b", ConfigMap: "backup", Secret: secretForbidden, HasConfig: false},
+	})
+
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []string{"tenant-a", "backup", "yes", "yes"}, table.Rows[0])
+	assert.Equal(t, []string{"tenant-b", "backup", "forbidden", "missing"}, table.Rows[1])
+}
@@ -0,0 +1,70 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/stackvista/stackstate-backup-cli/cmd/version"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// Documentation formats of docs generate
+const (
+	FormatMan      = "man"
+	FormatMarkdown = "markdown"
+)
+
+// dirPermissions are the permissions of the created output directory
+const dirPermissions = 0o755
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate reference documentation",
+	}
+	cmd.AddCommand(generateCmd())
+	return cmd
+}
+
+func generateCmd() *cobra.Command {
+	var format, dir string
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate man pages or Markdown for every command",
+		Long: `Generate a man page (section 1) or a Markdown page for every command from its help and flags, so
+packages can ship man pages and the documentation site stays in sync with the actual flags.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := generate(cmd.Root(), format, dir); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+			_, _ = fmt.Fprintf(os.Stderr, "Documentation written to %s\n", dir)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", FormatMarkdown, "Documentation format (man, markdown)")
+	cmd.Flags().StringVar(&dir, "dir", "docs", "Directory to write the documentation to")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{FormatMan, FormatMarkdown}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// generate writes the documentation of root and its subcommands to dir in the given format. The generation date
+// is left out, so the output only changes when the commands do.
+func generate(root *cobra.Command, format, dir string) error {
+	if format != FormatMan && format != FormatMarkdown {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --format '%s' (expected one of: %s, %s)", format, FormatMan, FormatMarkdown))
+	}
+	if err := os.MkdirAll(dir, dirPermissions); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	root.DisableAutoGenTag = true
+	if format == FormatMan {
+		header := &doc.GenManHeader{Title: "STS-BACKUP", Section: "1", Source: "sts-backup " + version.Version}
+		return doc.GenManTree(root, header, dir)
+	}
+	return doc.GenMarkdownTree(root, dir)
+}
@@ -0,0 +1,51 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRoot returns a small command tree with a flag to document
+func testRoot() *cobra.Command {
+	root := &cobra.Command{Use: "sts-backup", Short: "Backup and restore tool"}
+	restore := &cobra.Command{Use: "restore-snapshot", Short: "Restore Elasticsearch from a snapshot", Run: func(_ *cobra.Command, _ []string) {}}
+	restore.Flags().String("snapshot-name", "", "Snapshot name to restore")
+	root.AddCommand(restore)
+	root.AddCommand(Cmd())
+	return root
+}
+
+func TestGenerate_Markdown(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "docs")
+
+	require.NoError(t, generate(testRoot(), FormatMarkdown, dir))
+
+	page, err := os.ReadFile(filepath.Join(dir, "sts-backup_restore-snapshot.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "Restore Elasticsearch from a snapshot")
+	assert.Contains(t, string(page), "--snapshot-name")
+	assert.NotContains(t, string(page), "Auto generated")
+	assert.FileExists(t, filepath.Join(dir, "sts-backup_docs_generate.md"))
+}
+
+func TestGenerate_Man(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, generate(testRoot(), FormatMan, dir))
+
+	page, err := os.ReadFile(filepath.Join(dir, "sts-backup-restore-snapshot.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), `.TH "STS-BACKUP" "1"`)
+	assert.Contains(t, string(page), "snapshot-name")
+}
+
+func TestGenerate_InvalidFormat(t *testing.T) {
+	err := generate(testRoot(), "html", t.TempDir())
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
@@ -0,0 +1,304 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Check results
+const (
+	StatusPass = "PASS"
+	StatusWarn = "WARN"
+	StatusFail = "FAIL"
+	StatusSkip = "SKIP"
+)
+
+// Check names, in the order they run
+const (
+	checkKubernetes    = "Kubernetes API"
+	checkConfigMap     = "ConfigMap"
+	checkSecret        = "Secret"
+	checkConfiguration = "Configuration"
+	checkPortForward   = "Port-forward"
+	checkESHealth      = "Elasticsearch health"
+	checkRepository    = "Snapshot repository"
+	checkS3            = "S3 credentials"
+	checkSLM           = "SLM policy"
+)
+
+// slmStaleAfter is how long since the last successful SLM snapshot before a warning is reported
+const slmStaleAfter = 48 * time.Hour
+
+// CheckResult is the outcome of a single diagnostic check
+type CheckResult struct {
+	Name    string
+	Status  string
+	Details string
+}
+
+// report collects the results of all checks
+type report struct {
+	checks []CheckResult
+}
+
+func (r *report) add(name, status, details string) {
+	r.checks = append(r.checks, CheckResult{Name: name, Status: status, Details: details})
+}
+
+// skip marks checks that cannot run because a prerequisite failed
+func (r *report) skip(reason string, names ...string) {
+	for _, name := range names {
+		r.add(name, StatusSkip, reason)
+	}
+}
+
+// failed reports whether any check failed
+func (r *report) failed() bool {
+	for _, check := range r.checks {
+		if check.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// outputChecks converts the results for printing with output.Formatter.PrintChecks
+func (r *report) outputChecks() []output.Check {
+	checks := make([]output.Check, 0, len(r.checks))
+	for _, check := range r.checks {
+		checks = append(checks, output.Check{Name: check.Name, Status: check.Status, Details: check.Details})
+	}
+	return checks
+}
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the backup environment end-to-end",
+		Long: `Diagnose the backup environment end-to-end: Kubernetes access, ConfigMap and Secret,
+port-forwarding, Elasticsearch health, snapshot repository, S3 credentials and SLM policy.
+
+Prints a single PASS/WARN/FAIL report that can be attached to support tickets. With -o junit the report is
+printed as JUnit XML, for CI systems and scheduled Jobs.
+Exits with code 5 when any check fails.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runDoctor(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runDoctor(cliCtx *config.Context) error {
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	r := &report{}
+	runChecks(cliCtx, r, log)
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintChecks("doctor", r.outputChecks()); err != nil {
+		return err
+	}
+
+	if r.failed() {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("one or more checks failed"))
+	}
+	return nil
+}
+
+// runChecks runs all checks in order, skipping the checks whose prerequisites failed
+func runChecks(cliCtx *config.Context, r *report, log *logger.Logger) {
+	k8sClient := checkKubernetesAccess(cliCtx, r, log)
+	if k8sClient == nil {
+		r.skip("Kubernetes API not reachable", checkConfigMap, checkSecret, checkConfiguration, checkPortForward, checkESHealth, checkRepository, checkS3, checkSLM)
+		return
+	}
+
+	cfg := checkConfigurationSources(cliCtx, k8sClient, r)
+	if cfg == nil {
+		r.skip("configuration invalid", checkPortForward, checkESHealth, checkRepository, checkS3, checkSLM)
+		return
+	}
+
+	// S3 does not depend on Elasticsearch, so check it before the Elasticsearch checks can bail out
+	s3Result := checkS3Credentials(k8sClient, cliCtx.Config.Namespace, cfg, log)
+	checkElasticsearch(cliCtx, k8sClient, cfg, s3Result, r, log)
+}
+
+// checkKubernetesAccess creates the Kubernetes client and checks that the API server responds. It returns nil when
+// it does not.
+func checkKubernetesAccess(cliCtx *config.Context, r *report, log *logger.Logger) *k8s.Client {
+	log.Infof("Checking Kubernetes access...")
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		r.add(checkKubernetes, StatusFail, err.Error())
+		return nil
+	}
+	version, err := k8sClient.Clientset().Discovery().ServerVersion()
+	if err != nil {
+		r.add(checkKubernetes, StatusFail, fmt.Sprintf("failed to reach API server: %v", err))
+		return nil
+	}
+	r.add(checkKubernetes, StatusPass, fmt.Sprintf("connected (server %s)", version.GitVersion))
+	return k8sClient
+}
+
+// checkConfigurationSources checks the ConfigMap and Secret and loads the configuration from them. It returns nil
+// when the configuration is invalid.
+func checkConfigurationSources(cliCtx *config.Context, k8sClient *k8s.Client, r *report) *config.Config {
+	namespace := cliCtx.Config.Namespace
+	ctx := context.Background()
+
+	if _, err := k8sClient.Clientset().CoreV1().ConfigMaps(namespace).Get(ctx, cliCtx.Config.ConfigMapName, metav1.GetOptions{}); err != nil {
+		r.add(checkConfigMap, StatusFail, err.Error())
+	} else {
+		r.add(checkConfigMap, StatusPass, fmt.Sprintf("%s/%s found", namespace, cliCtx.Config.ConfigMapName))
+	}
+	if _, err := k8sClient.Clientset().CoreV1().Secrets(namespace).Get(ctx, cliCtx.Config.SecretName, metav1.GetOptions{}); err != nil {
+		r.add(checkSecret, StatusWarn, fmt.Sprintf("%v (credentials must then be in the ConfigMap)", err))
+	} else {
+		r.add(checkSecret, StatusPass, fmt.Sprintf("%s/%s found", namespace, cliCtx.Config.SecretName))
+	}
+
+	cfg, err := config.LoadConfig(k8sClient.Clientset(), namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName, config.WithHelmValues(cliCtx.Config.HelmValues))
+	if err != nil {
+		r.add(checkConfiguration, StatusFail, strings.Join(strings.Fields(err.Error()), " "))
+		return nil
+	}
+	r.add(checkConfiguration, StatusPass, "loaded and validated")
+	return cfg
+}
+
+// checkElasticsearch checks the port-forward, the cluster health, the snapshot repository and the SLM policy.
+// s3Result is reported in its place among them.
+func checkElasticsearch(cliCtx *config.Context, k8sClient *k8s.Client, cfg *config.Config, s3Result CheckResult, r *report, log *logger.Logger) {
+	log.Infof("Checking Elasticsearch...")
+	pf, err := portforward.SetupPortForward(k8sClient, cliCtx.Config.Namespace, cfg.Elasticsearch.Service.SnapshotServiceName(),
+		cfg.Elasticsearch.Service.LocalPortForwardPort, cfg.Elasticsearch.Service.Port, log)
+	if err != nil {
+		r.add(checkPortForward, StatusFail, err.Error())
+		r.skip("Elasticsearch not reachable", checkESHealth, checkRepository)
+		r.checks = append(r.checks, s3Result)
+		r.skip("Elasticsearch not reachable", checkSLM)
+		return
+	}
+	defer close(pf.StopChan)
+	r.add(checkPortForward, StatusPass, fmt.Sprintf("service %s:%d", cfg.Elasticsearch.Service.Name, cfg.Elasticsearch.Service.Port))
+
+	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", pf.LocalPort), target.ElasticsearchOptions(k8sClient, cliCtx, cfg, log)...)
+	if err != nil {
+		r.add(checkESHealth, StatusFail, err.Error())
+		r.skip("Elasticsearch client unavailable", checkRepository)
+		r.checks = append(r.checks, s3Result)
+		r.skip("Elasticsearch client unavailable", checkSLM)
+		return
+	}
+
+	health, err := esClient.ClusterHealth()
+	if err != nil {
+		r.add(checkESHealth, StatusFail, err.Error())
+	} else {
+		r.checks = append(r.checks, evaluateHealth(health))
+	}
+
+	repository := cfg.Elasticsearch.SnapshotRepository.Name
+	if nodes, err := esClient.VerifyRepository(repository); err != nil {
+		r.add(checkRepository, StatusFail, fmt.Sprintf("repository '%s': %v", repository, err))
+	} else {
+		r.add(checkRepository, StatusPass, fmt.Sprintf("repository '%s' verified on %d node(s)", repository, nodes))
+	}
+
+	r.checks = append(r.checks, s3Result)
+
+	mode, err := esClient.SLMStatus()
+	if err != nil {
+		r.add(checkSLM, StatusFail, err.Error())
+		return
+	}
+	policy, err := esClient.GetSLMPolicy(cfg.Elasticsearch.SLM.Name)
+	if err != nil {
+		r.add(checkSLM, StatusFail, fmt.Sprintf("policy '%s': %v", cfg.Elasticsearch.SLM.Name, err))
+		return
+	}
+	r.checks = append(r.checks, evaluateSLM(policy, mode, time.Now()))
+}
+
+// checkS3Credentials checks the repository bucket with the configured credentials,
+// port-forwarding to the object storage service when the endpoint is an in-cluster service
+func checkS3Credentials(k8sClient *k8s.Client, namespace string, cfg *config.Config, log *logger.Logger) CheckResult {
+	repo := cfg.Elasticsearch.SnapshotRepository
+
+	log.Infof("Checking S3 credentials...")
+	endpoint, cleanup, err := portforward.ServiceEndpointAddress(k8sClient, repo.Endpoint, namespace, log)
+	if err != nil {
+		return CheckResult{Name: checkS3, Status: StatusFail, Details: err.Error()}
+	}
+	defer cleanup()
+
+	client, err := s3.NewClient(s3.EndpointURL(repo.S3Protocol(), endpoint), repo.AccessKey, repo.SecretKey, repo.S3Region())
+	if err != nil {
+		return CheckResult{Name: checkS3, Status: StatusFail, Details: err.Error()}
+	}
+	if err := client.HeadBucket(repo.Bucket); err != nil {
+		return CheckResult{Name: checkS3, Status: StatusFail, Details: fmt.Sprintf("bucket '%s' at %s: %v", repo.Bucket, repo.Endpoint, err)}
+	}
+	return CheckResult{Name: checkS3, Status: StatusPass, Details: fmt.Sprintf("bucket '%s' accessible at %s", repo.Bucket, repo.Endpoint)}
+}
+
+// evaluateHealth maps the Elasticsearch cluster status to a check result
+func evaluateHealth(health *elasticsearch.ClusterHealth) CheckResult {
+	details := fmt.Sprintf("cluster '%s' is %s (%d node(s), %d unassigned shard(s))",
+		health.ClusterName, health.Status, health.NumberOfNodes, health.UnassignedShards)
+
+	switch health.Status {
+	case "green":
+		return CheckResult{Name: checkESHealth, Status: StatusPass, Details: details}
+	case "yellow":
+		return CheckResult{Name: checkESHealth, Status: StatusWarn, Details: details}
+	default:
+		return CheckResult{Name: checkESHealth, Status: StatusFail, Details: details}
+	}
+}
+
+// evaluateSLM checks that SLM is running and the policy recently produced a snapshot
+func evaluateSLM(policy *elasticsearch.SLMPolicy, mode string, now time.Time) CheckResult {
+	if mode != "RUNNING" {
+		return CheckResult{Name: checkSLM, Status: StatusFail, Details: fmt.Sprintf("SLM is %s, no snapshots are being taken", mode)}
+	}
+
+	if policy.LastSuccess == nil {
+		if policy.LastFailure != nil {
+			return CheckResult{Name: checkSLM, Status: StatusFail, Details: fmt.Sprintf("policy '%s' never succeeded, last failure: %s", policy.Name, policy.LastFailure.Details)}
+		}
+		return CheckResult{Name: checkSLM, Status: StatusWarn, Details: fmt.Sprintf("policy '%s' has not run yet", policy.Name)}
+	}
+
+	lastSuccess := time.UnixMilli(policy.LastSuccess.Time)
+	age := output.FormatAge(now.Sub(lastSuccess))
+
+	if policy.LastFailure != nil && policy.LastFailure.Time > policy.LastSuccess.Time {
+		return CheckResult{Name: checkSLM, Status: StatusFail, Details: fmt.Sprintf("policy '%s' last run failed: %s (last success %s ago)", policy.Name, policy.LastFailure.Details, age)}
+	}
+	if now.Sub(lastSuccess) > slmStaleAfter {
+		return CheckResult{Name: checkSLM, Status: StatusWarn, Details: fmt.Sprintf("policy '%s' last succeeded %s ago", policy.Name, age)}
+	}
+	return CheckResult{Name: checkSLM, Status: StatusPass, Details: fmt.Sprintf("policy '%s' last succeeded %s ago (%s)", policy.Name, age, policy.LastSuccess.SnapshotName)}
+}
@@ -0,0 +1,128 @@
+package doctor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDoctorCmd_Unit tests the command structure
+func TestDoctorCmd_Unit(t *testing.T) {
+	cliCtx := config.NewContext()
+	cmd := Cmd(cliCtx)
+
+	assert.Equal(t, "doctor", cmd.Use)
+	assert.Equal(t, "Diagnose the backup environment end-to-end", cmd.Short)
+	assert.NotEmpty(t, cmd.Long)
+	assert.NotNil(t, cmd.Run)
+}
+
+func TestReport(t *testing.T) {
+	r := &report{}
+	r.add(checkKubernetes, StatusPass, "connected")
+	r.add(checkSecret, StatusWarn, "not found")
+	assert.False(t, r.failed())
+
+	r.add(checkConfiguration, StatusFail, "invalid")
+	r.skip("configuration invalid", checkPortForward, checkSLM)
+	assert.True(t, r.failed())
+
+	checks := r.outputChecks()
+	require.Len(t, checks, 5)
+	assert.Equal(t, output.Check{Name: checkSLM, Status: output.CheckSkip, Details: "configuration invalid"}, checks[4])
+}
+
+func TestEvaluateHealth(t *testing.T) {
+	tests := []struct {
+		status   string
+		expected string
+	}{
+		{status: "green", expected: StatusPass},
+		{status: "yellow", expected: StatusWarn},
+		{status: "red", expected: StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			result := evaluateHealth(&elasticsearch.ClusterHealth{ClusterName: "sts", Status: tt.status, NumberOfNodes: 3})
+			assert.Equal(t, tt.expected, result.Status)
+			assert.Contains(t, result.Details, "3 node(s)")
+		})
+	}
+}
+
+func TestEvaluateSLM(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	execution := func(ago time.Duration, details string) *elasticsearch.SLMExecution {
+		return &elasticsearch.SLMExecution{SnapshotName: "sts-backup", Time: now.Add(-ago).UnixMilli(), Details: details}
+	}
+
+	tests := []struct {
+		name            string
+		policy          elasticsearch.SLMPolicy
+		mode            string
+		expectedStatus  string
+		expectedDetails string
+	}{
+		{
+			name:           "recent success",
+			policy:         elasticsearch.SLMPolicy{Name: "auto", LastSuccess: execution(2*time.Hour, "")},
+			mode:           "RUNNING",
+			expectedStatus: StatusPass,
+		},
+		{
+			name:            "SLM stopped",
+			policy:          elasticsearch.SLMPolicy{Name: "auto", LastSuccess: execution(2*time.Hour, "")},
+			mode:            "STOPPED",
+			expectedStatus:  StatusFail,
+			expectedDetails: "SLM is STOPPED",
+		},
+		{
+			name:            "failure after success",
+			policy:          elasticsearch.SLMPolicy{Name: "auto", LastSuccess: execution(26*time.Hour, ""), LastFailure: execution(2*time.Hour, "repository missing")},
+			mode:            "RUNNING",
+			expectedStatus:  StatusFail,
+			expectedDetails: "repository missing",
+		},
+		{
+			name:           "success after failure",
+			policy:         elasticsearch.SLMPolicy{Name: "auto", LastSuccess: execution(2*time.Hour, ""), LastFailure: execution(26*time.Hour, "transient")},
+			mode:           "RUNNING",
+			expectedStatus: StatusPass,
+		},
+		{
+			name:            "stale success",
+			policy:          elasticsearch.SLMPolicy{Name: "auto", LastSuccess: execution(72*time.Hour, "")},
+			mode:            "RUNNING",
+			expectedStatus:  StatusWarn,
+			expectedDetails: "3d0h ago",
+		},
+		{
+			name:           "never ran",
+			policy:         elasticsearch.SLMPolicy{Name: "auto"},
+			mode:           "RUNNING",
+			expectedStatus: StatusWarn,
+		},
+		{
+			name:            "never succeeded",
+			policy:          elasticsearch.SLMPolicy{Name: "auto", LastFailure: execution(time.Hour, "access denied")},
+			mode:            "RUNNING",
+			expectedStatus:  StatusFail,
+			expectedDetails: "never succeeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := evaluateSLM(&tt.policy, tt.mode, now)
+			assert.Equal(t, checkSLM, result.Name)
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Contains(t, result.Details, tt.expectedDetails)
+		})
+	}
+}
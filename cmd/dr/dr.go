@@ -0,0 +1,18 @@
+package dr
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dr",
+		Short: "Disaster recovery planning",
+		Long: `Plan the recovery of the whole installation from its backups, across Kafka, PostgreSQL, StackGraph and
+Elasticsearch.`,
+	}
+
+	cmd.AddCommand(planCmd(cliCtx))
+	return cmd
+}
@@ -0,0 +1,363 @@
+package dr
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/catalog"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/hbase"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/postgres"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/runbook"
+)
+
+// program is the name of the CLI in the commands of the runbook
+const program = "sts-backup"
+
+func planCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "plan",
+		Short: "Print the runbook recovering the installation from its latest backups",
+		Long: `Inspect the configuration and the backup bucket and print an ordered recovery runbook for the installation:
+the latest backup of every component, the commands restoring them and how long each is expected to take, the
+median of the earlier runs in the audit log (see 'history').
+
+The components are restored in dependency order: the Kafka topics first, so nothing produced after the recovery is
+lost, then the PostgreSQL databases and StackGraph, and Elasticsearch last, with its cluster settings imported
+before the snapshot. Components without a backup are left out of the runbook with a warning.
+
+The runbook is printed as Markdown, or as JSON or YAML with --output json or yaml. Nothing is changed.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runPlan(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runPlan(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	entries, err := audit.NewStore(env.K8s.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.AuditConfigMapName).List()
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	bucket, cleanup, err := target.OpenBucket(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env.Log.Infof("Looking up the latest backups in bucket '%s'...", env.Config.Elasticsearch.SnapshotRepository.Bucket)
+	p := &planner{cfg: env.Config, bucket: bucket, flags: commandFlags(cliCtx), durations: durationsByOperation(entries)}
+	plan, err := p.plan(cliCtx.Config.Namespace)
+	if err != nil {
+		return err
+	}
+	plan.GeneratedAt = time.Now()
+	plan.RunID = cliCtx.RunID
+
+	for _, warning := range plan.Warnings {
+		env.Log.Warningf("%s", warning)
+	}
+	return writeRunbook(cliCtx, plan)
+}
+
+// writeRunbook prints the runbook as Markdown, or as the object itself in JSON and YAML format
+func writeRunbook(cliCtx *config.Context, plan *runbook.Runbook) error {
+	switch cliCtx.Config.OutputFormat {
+	case "json", "yaml":
+		formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+		return formatter.PrintDetail(plan)
+	}
+
+	data := []byte(redact.String(string(runbook.Markdown(plan))))
+	if cliCtx.Config.OutputFile != "" {
+		if err := output.WriteFileAtomic(cliCtx.Config.OutputFile, data); err != nil {
+			return fmt.Errorf("failed to write output file: %w", err)
+		}
+		return nil
+	}
+	_, err := os.Stdout.Write(data)
+	return err
+}
+
+// commandFlags returns the flags every command of the runbook needs to reach the installation the way this one did
+func commandFlags(cliCtx *config.Context) string {
+	flags := []string{"--namespace " + cliCtx.Config.Namespace}
+	if cliCtx.Config.ConfigMapName != config.DefaultConfigMapName {
+		flags = append(flags, "--configmap "+cliCtx.Config.ConfigMapName)
+	}
+	if cliCtx.Config.SecretName != config.DefaultSecretName {
+		flags = append(flags, "--secret "+cliCtx.Config.SecretName)
+	}
+	if cliCtx.Config.HelmValues != "" {
+		flags = append(flags, "--helm-values "+cliCtx.Config.HelmValues)
+	}
+	return strings.Join(flags, " ")
+}
+
+// duration is the expected duration of an audited operation
+type duration struct {
+	Millis  int64
+	Samples int
+}
+
+// durationsByOperation returns the median duration of the successful runs of each operation that records its duration
+func durationsByOperation(entries []audit.Entry) map[string]duration {
+	samples := map[string][]int64{}
+	for _, entry := range entries {
+		if entry.Outcome == audit.OutcomeSuccess && entry.DurationMillis > 0 {
+			samples[entry.Operation] = append(samples[entry.Operation], entry.DurationMillis)
+		}
+	}
+
+	durations := make(map[string]duration, len(samples))
+	for operation, millis := range samples {
+		slices.Sort(millis)
+		median := millis[len(millis)/2]
+		if len(millis)%2 == 0 {
+			median = (millis[len(millis)/2-1] + median) / 2
+		}
+		durations[operation] = duration{Millis: median, Samples: len(millis)}
+	}
+	return durations
+}
+
+// planner builds the runbook from the backups in the bucket
+type planner struct {
+	cfg    *config.Config
+	bucket catalog.ObjectStore
+	// flags are appended to every command
+	flags     string
+	durations map[string]duration
+}
+
+// command returns a command of the runbook
+func (p *planner) command(args ...string) string {
+	return program + " " + strings.Join(args, " ") + " " + p.flags
+}
+
+// timed sets the expected duration of a step running its operation count times
+func (p *planner) timed(step runbook.Step, operation string, count int) runbook.Step {
+	step.Operation = operation
+	if d, ok := p.durations[operation]; ok {
+		step.ExpectedDurationMillis = d.Millis * int64(count)
+		step.DurationSamples = d.Samples
+	}
+	return step
+}
+
+// latestExport returns the key of the most recent export of kind, empty when there is none
+func (p *planner) latestExport(kind string) (string, error) {
+	// Only keys are listed, which needs no archive encryption key
+	keys, err := export.New(p.bucket, p.cfg.Elasticsearch.SnapshotRepository.Bucket, nil).Keys(kind)
+	if err != nil || len(keys) == 0 {
+		return "", err
+	}
+	return keys[0], nil
+}
+
+// plan returns the runbook of the installation in namespace
+func (p *planner) plan(namespace string) (*runbook.Runbook, error) {
+	plan := &runbook.Runbook{Namespace: namespace}
+	plan.Add(runbook.Step{
+		Component:   "all",
+		Title:       "Check the installation",
+		Description: "Check that the cluster, the configuration and the backup bucket are reachable before anything is restored.",
+		Commands:    []string{p.command("doctor")},
+	})
+
+	for _, add := range []func(*runbook.Runbook) error{p.planKafka, p.planPostgres, p.planStackGraph, p.planElasticsearch} {
+		if err := add(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	plan.Add(runbook.Step{
+		Component:   "all",
+		Title:       "Verify the recovery",
+		Description: "Check the installation again and confirm in the audit log that every restore succeeded.",
+		Commands:    []string{p.command("doctor"), p.command("history")},
+	})
+	return plan, nil
+}
+
+// planKafka adds the restore of the latest Kafka metadata snapshot
+func (p *planner) planKafka(plan *runbook.Runbook) error {
+	key, err := p.latestExport(export.KindKafkaMetadata)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		plan.Warnings = append(plan.Warnings, "Kafka: no metadata snapshot found, run 'kafka metadata snapshot'")
+		return nil
+	}
+
+	plan.Add(p.timed(runbook.Step{
+		Component: "kafka",
+		Title:     "Restore the Kafka topics and ACLs",
+		Description: fmt.Sprintf("Recreate the topics, their configuration and the ACLs of the snapshot on the brokers (%s), "+
+			"before anything produces to Kafka again.", p.cfg.Kafka.PodSelector),
+		Backups:  []string{key},
+		Commands: []string{p.command("kafka metadata restore --key", key)},
+	}, "kafka-metadata-restore", 1))
+	return nil
+}
+
+// planPostgres adds the restore of the latest dump of each database, the configured databases or all dumped ones
+func (p *planner) planPostgres(plan *runbook.Runbook) error {
+	objects, err := p.bucket.ListObjects(p.cfg.Elasticsearch.SnapshotRepository.Bucket, postgres.Prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list PostgreSQL dumps: %w", err)
+	}
+	// Keys of one database sort chronologically
+	latest := map[string]string{}
+	for _, object := range objects {
+		if database, _, ok := postgres.ParseDumpKey(object.Key); ok && object.Key > latest[database] {
+			latest[database] = object.Key
+		}
+	}
+
+	databases := p.cfg.Postgres.Databases
+	if len(databases) == 0 {
+		databases = slices.Sorted(maps.Keys(latest))
+	}
+	var keys, commands []string
+	for _, database := range databases {
+		key, ok := latest[database]
+		if !ok {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("PostgreSQL: no dump of database '%s' found, run 'postgres dump'", database))
+			continue
+		}
+		keys = append(keys, key)
+		commands = append(commands, p.command("postgres restore --key", key))
+	}
+	if len(keys) == 0 {
+		if len(databases) == 0 {
+			plan.Warnings = append(plan.Warnings, "PostgreSQL: no dumps found, run 'postgres dump'")
+		}
+		return nil
+	}
+
+	plan.Add(p.timed(runbook.Step{
+		Component: "postgres",
+		Title:     "Restore the PostgreSQL databases",
+		Description: fmt.Sprintf("Replace each database with its latest dump. The sessions of the database are drained and "+
+			"the Deployments matching '%s' are scaled down while it is restored.", p.cfg.Postgres.ScaleDownLabelSelector),
+		Backups:  keys,
+		Commands: commands,
+	}, "postgres-restore", len(commands)))
+	return nil
+}
+
+// planStackGraph adds the restore of the latest exported set of HBase snapshots
+func (p *planner) planStackGraph(plan *runbook.Runbook) error {
+	location := hbase.S3Location{Bucket: p.cfg.Elasticsearch.SnapshotRepository.Bucket, Prefix: p.cfg.StackGraph.ExportPrefix}
+	objects, err := p.bucket.ListObjects(location.Bucket, location.SnapshotInfoPrefix())
+	if err != nil {
+		return fmt.Errorf("failed to list exported HBase snapshots: %w", err)
+	}
+	sets := map[string][]string{}
+	for _, object := range objects {
+		if name, ok := location.ExportedSnapshot(object.Key); ok {
+			setID, _ := hbase.SetID(name)
+			sets[setID] = append(sets[setID], name)
+		}
+	}
+	if len(sets) == 0 {
+		plan.Warnings = append(plan.Warnings, "StackGraph: no exported snapshots found, run 'stackgraph hbase snapshot' and 'stackgraph hbase export'")
+		return nil
+	}
+
+	setID := slices.Max(slices.Collect(maps.Keys(sets)))
+	plan.Add(p.timed(runbook.Step{
+		Component: "stackgraph",
+		Title:     "Restore the StackGraph tables",
+		Description: fmt.Sprintf("Import the snapshots of set %s from %s into HBase and restore the tables. The Deployments "+
+			"matching '%s' are scaled down while the tables are restored.", setID, location.URI(), p.cfg.StackGraph.ScaleDownLabelSelector),
+		Backups:  slices.Sorted(slices.Values(sets[setID])),
+		Commands: []string{p.command("stackgraph hbase restore --set", setID)},
+	}, "stackgraph-restore", 1))
+	return nil
+}
+
+// planElasticsearch adds the import of the latest cluster settings and the restore of the latest successful snapshot
+// in the catalog
+func (p *planner) planElasticsearch(plan *runbook.Runbook) error {
+	settingsKey, err := p.latestExport(export.KindClusterSettings)
+	if err != nil {
+		return err
+	}
+	if settingsKey != "" {
+		plan.Add(p.timed(runbook.Step{
+			Component:   "elasticsearch",
+			Title:       "Import the Elasticsearch cluster settings",
+			Description: "Import the persistent and transient cluster settings, which snapshots of the STS indices do not hold.",
+			Backups:     []string{settingsKey},
+			Commands:    []string{p.command("elasticsearch import-cluster-settings --key", settingsKey)},
+		}, "import-cluster-settings", 1))
+	}
+
+	manifests, err := catalog.New(p.bucket, p.cfg.Elasticsearch.SnapshotRepository.Bucket).List(catalog.ComponentElasticsearch)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(manifests, func(m catalog.Manifest) bool { return m.State == "SUCCESS" })
+	if index < 0 {
+		plan.Warnings = append(plan.Warnings, "Elasticsearch: no successful snapshot in the catalog, run 'catalog sync' "+
+			"or pick a snapshot with 'elasticsearch list-snapshots'")
+		return nil
+	}
+	manifest := manifests[index]
+	snapshot := manifest.Snapshot
+	if manifest.Repository != "" && manifest.Repository != p.cfg.Elasticsearch.SnapshotRepository.Name {
+		snapshot = manifest.Repository + "/" + snapshot
+	}
+
+	description := fmt.Sprintf("Replace all STS indices with snapshot %s, taken at %s (%s). The Deployments matching '%s' "+
+		"are scaled down during the restore.", snapshot, manifest.StartTime.UTC().Format(time.RFC3339),
+		output.FormatBytes(manifest.SizeInBytes), p.cfg.Elasticsearch.Restore.ScaleDownLabelSelector)
+	backups := []string{snapshot}
+	args := []string{"elasticsearch restore-snapshot --snapshot-name", snapshot, "--drop-all-indices"}
+	for _, imported := range []struct{ kind, flag, description string }{
+		{export.KindILMPolicies, "--import-ilm", "The exported ILM policies are imported before the snapshot is restored."},
+		{export.KindPipelines, "--import-pipelines", "The exported ingest pipelines are imported after the snapshot is restored."},
+	} {
+		key, err := p.latestExport(imported.kind)
+		if err != nil {
+			return err
+		}
+		if key != "" {
+			backups = append(backups, key)
+			args = append(args, imported.flag)
+			description += " " + imported.description
+		}
+	}
+	args = append(args, "--report dr-elasticsearch-restore.md")
+
+	plan.Add(p.timed(runbook.Step{
+		Component:   "elasticsearch",
+		Title:       "Restore the Elasticsearch snapshot",
+		Description: description,
+		Backups:     backups,
+		Commands:    []string{p.command(args...)},
+	}, "restore", 1))
+	return nil
+}
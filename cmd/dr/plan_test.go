@@ -0,0 +1,152 @@
+package dr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/catalog"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBucket is an in-memory catalog.ObjectStore
+type memoryBucket map[string][]byte
+
+func (m memoryBucket) PutObject(_, key string, body []byte) error {
+	m[key] = body
+	return nil
+}
+
+func (m memoryBucket) GetObject(_, key string) ([]byte, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, &s3.Error{StatusCode: 404, Code: "NoSuchKey"}
+	}
+	return data, nil
+}
+
+func (m memoryBucket) ListObjects(_, prefix string) ([]s3.Object, error) {
+	var objects []s3.Object
+	for key, data := range m {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, s3.Object{Key: key, Size: int64(len(data))})
+		}
+	}
+	return objects, nil
+}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Elasticsearch.SnapshotRepository.Name = "sts-backup"
+	cfg.Elasticsearch.SnapshotRepository.Bucket = "sts-backup"
+	cfg.Elasticsearch.Restore.ScaleDownLabelSelector = "observability.suse.com/scalable-during-es-restore=true"
+	cfg.Kafka.PodSelector = "app.kubernetes.io/component=kafka"
+	cfg.Postgres.Databases = []string{"stackstate", "keycloak"}
+	cfg.StackGraph.ExportPrefix = "hbase"
+	return cfg
+}
+
+func putManifest(t *testing.T, bucket memoryBucket, manifest catalog.Manifest) {
+	manifest.Component = catalog.ComponentElasticsearch
+	require.NoError(t, catalog.New(bucket, "sts-backup").Put(&manifest))
+}
+
+func TestPlan(t *testing.T) {
+	bucket := memoryBucket{
+		"exports/kafka/metadata/20260301T000000Z.json":                               nil,
+		"exports/kafka/metadata/20260302T000000Z.json":                               nil,
+		"exports/elasticsearch/cluster-settings/20260302T000000Z.json":               nil,
+		"exports/elasticsearch/pipelines/20260302T000000Z.json":                      nil,
+		"dumps/postgres/stackstate/20260301T000000Z.sql.gz":                          nil,
+		"dumps/postgres/stackstate/20260302T000000Z.sql.gz":                          nil,
+		"dumps/postgres/other/20260302T000000Z.sql.gz":                               nil,
+		"hbase/.hbase-snapshot/sts-backup-20260301T000000Z-stackgraph/.snapshotinfo": nil,
+		"hbase/.hbase-snapshot/sts-backup-20260302T000000Z-stackgraph/.snapshotinfo": nil,
+		"hbase/.hbase-snapshot/sts-backup-20260302T000000Z-tephra/.snapshotinfo":     nil,
+	}
+	putManifest(t, bucket, catalog.Manifest{Snapshot: "sts-backup-20260303-0300", State: "FAILED", StartTime: time.Date(2026, 3, 3, 3, 0, 0, 0, time.UTC)})
+	putManifest(t, bucket, catalog.Manifest{Snapshot: "sts-backup-20260302-0300", Repository: "sts-backup", State: "SUCCESS",
+		StartTime: time.Date(2026, 3, 2, 3, 0, 0, 0, time.UTC), SizeInBytes: 1 << 30})
+
+	p := &planner{cfg: testConfig(), bucket: bucket, flags: "--namespace sts", durations: durationsByOperation([]audit.Entry{
+		{Operation: "postgres-restore", Outcome: audit.OutcomeSuccess, DurationMillis: 60000},
+		{Operation: "restore", Outcome: audit.OutcomeSuccess, DurationMillis: 600000},
+	})}
+	plan, err := p.plan("sts")
+	require.NoError(t, err)
+
+	var commands []string
+	for _, step := range plan.Steps {
+		commands = append(commands, step.Commands...)
+	}
+	assert.Equal(t, []string{
+		"sts-backup doctor --namespace sts",
+		"sts-backup kafka metadata restore --key exports/kafka/metadata/20260302T000000Z.json --namespace sts",
+		"sts-backup postgres restore --key dumps/postgres/stackstate/20260302T000000Z.sql.gz --namespace sts",
+		"sts-backup stackgraph hbase restore --set 20260302T000000Z --namespace sts",
+		"sts-backup elasticsearch import-cluster-settings --key exports/elasticsearch/cluster-settings/20260302T000000Z.json --namespace sts",
+		"sts-backup elasticsearch restore-snapshot --snapshot-name sts-backup-20260302-0300 --drop-all-indices --import-pipelines " +
+			"--report dr-elasticsearch-restore.md --namespace sts",
+		"sts-backup doctor --namespace sts",
+		"sts-backup history --namespace sts",
+	}, commands)
+	assert.Equal(t, []string{"PostgreSQL: no dump of database 'keycloak' found, run 'postgres dump'"}, plan.Warnings)
+
+	stackgraph := plan.Steps[3]
+	assert.Equal(t, 4, stackgraph.Number)
+	assert.Equal(t, []string{"sts-backup-20260302T000000Z-stackgraph", "sts-backup-20260302T000000Z-tephra"}, stackgraph.Backups)
+	assert.Zero(t, stackgraph.DurationSamples)
+
+	restore := plan.Steps[5]
+	assert.Equal(t, []string{"sts-backup-20260302-0300", "exports/elasticsearch/pipelines/20260302T000000Z.json"}, restore.Backups)
+	assert.Equal(t, int64(600000), restore.ExpectedDurationMillis)
+	assert.Contains(t, restore.Description, "taken at 2026-03-02T03:00:00Z (1.0 GiB)")
+	assert.Contains(t, restore.Description, "ingest pipelines")
+	assert.NotContains(t, restore.Description, "ILM")
+
+	data, err := json.Marshal(plan)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"operation":"postgres-restore","expectedDurationMillis":60000,"durationSamples":1`)
+}
+
+func TestPlan_NoBackups(t *testing.T) {
+	cfg := testConfig()
+	cfg.Postgres.Databases = nil
+	p := &planner{cfg: cfg, bucket: memoryBucket{}, flags: "--namespace sts"}
+	plan, err := p.plan("sts")
+	require.NoError(t, err)
+
+	assert.Len(t, plan.Steps, 2, "only the checks are left")
+	assert.Len(t, plan.Warnings, 4)
+	assert.Contains(t, plan.Warnings[3], "no successful snapshot in the catalog")
+}
+
+func TestDurationsByOperation(t *testing.T) {
+	durations := durationsByOperation([]audit.Entry{
+		{Operation: "restore", Outcome: audit.OutcomeSuccess, DurationMillis: 300},
+		{Operation: "restore", Outcome: audit.OutcomeSuccess, DurationMillis: 100},
+		{Operation: "restore", Outcome: audit.OutcomeFailed, DurationMillis: 5},
+		{Operation: "restore", Outcome: audit.OutcomeSuccess, DurationMillis: 200},
+		{Operation: "stackgraph-restore", Outcome: audit.OutcomeSuccess, DurationMillis: 100},
+		{Operation: "stackgraph-restore", Outcome: audit.OutcomeSuccess, DurationMillis: 400},
+		{Operation: "import-ilm", Outcome: audit.OutcomeSuccess},
+	})
+
+	assert.Equal(t, map[string]duration{
+		"restore":            {Millis: 200, Samples: 3},
+		"stackgraph-restore": {Millis: 250, Samples: 2},
+	}, durations)
+}
+
+func TestCommandFlags(t *testing.T) {
+	cliCtx := config.NewContext()
+	cliCtx.Config.Namespace = "sts"
+	cliCtx.Config.ConfigMapName = config.DefaultConfigMapName
+	cliCtx.Config.SecretName = "backup-credentials"
+	assert.Equal(t, "--namespace sts --secret backup-credentials", commandFlags(cliCtx))
+}
@@ -0,0 +1,266 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// aliasUpdater lists and updates index aliases
+type aliasUpdater interface {
+	ListAliases(pattern string) ([]elasticsearch.Alias, error)
+	UpdateAliases(actions []elasticsearch.AliasAction) error
+}
+
+func aliasesCmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "aliases",
+		Short: "List and change index aliases",
+		Long: `List and change index aliases, e.g. to point the application at copies of indices restored under other
+names.`,
+	}
+	cmd.AddCommand(listAliasesCmd(cliCtx))
+	cmd.AddCommand(setAliasCmd(cliCtx))
+	cmd.AddCommand(removeAliasCmd(cliCtx))
+	return cmd
+}
+
+func listAliasesCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list [pattern]",
+		Short: "List the aliases and the indices they point to",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			pattern := ""
+			if len(args) > 0 {
+				pattern = args[0]
+			}
+			if err := runListAliases(cliCtx, pattern); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func setAliasCmd(cliCtx *config.Context) *cobra.Command {
+	var indices []string
+	cmd := &cobra.Command{
+		Use:   "set <alias>",
+		Short: "Point an alias at the given indices",
+		Long: `Point an alias at exactly the indices given with --index: the alias is added to them and removed from every
+other index in a single atomic update, so searches through the alias never see both or neither.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if err := runUpdateAlias(cliCtx, "set-alias", args[0], func(current []string) ([]elasticsearch.AliasAction, error) {
+				return setAliasActions(args[0], current, indices), nil
+			}); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&indices, "index", nil, "Index to point the alias at, repeatable")
+	_ = cmd.MarkFlagRequired("index")
+	return cmd
+}
+
+func removeAliasCmd(cliCtx *config.Context) *cobra.Command {
+	var indices []string
+	cmd := &cobra.Command{
+		Use:   "remove <alias>",
+		Short: "Remove an alias",
+		Long:  `Remove an alias from the indices given with --index, or from every index it points to without --index.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if err := runUpdateAlias(cliCtx, "remove-alias", args[0], func(current []string) ([]elasticsearch.AliasAction, error) {
+				return removeAliasActions(args[0], current, indices)
+			}); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&indices, "index", nil, "Index to remove the alias from, repeatable (default: all indices of the alias)")
+	return cmd
+}
+
+func runListAliases(cliCtx *config.Context, pattern string) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env.Log.Infof("Fetching aliases...")
+	aliases, err := esClient.ListAliases(pattern)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list aliases: %w", err))
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(aliases) == 0 {
+		formatter.PrintMessage("No aliases found")
+		return nil
+	}
+	return formatter.PrintTable(aliasesTable(aliases))
+}
+
+// runUpdateAlias applies the actions plan returns for the indices alias currently points to
+func runUpdateAlias(cliCtx *config.Context, operation, alias string, plan func(current []string) ([]elasticsearch.AliasAction, error)) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: operation}, err, env.Log)
+	}()
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	current, err := aliasIndices(esClient, alias)
+	if err != nil {
+		return err
+	}
+	actions, err := plan(current)
+	if err != nil {
+		return err
+	}
+	if len(actions) == 0 {
+		env.Log.Successf("Alias '%s' already points to the requested indices", alias)
+		return nil
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintTable(aliasActionsTable(actions)); err != nil {
+		return err
+	}
+	return applyAliasActions(esClient, alias, actions, prompt.New(cliCtx.Config.AssumeYes), env.Log)
+}
+
+// aliasIndices returns the sorted indices alias points to
+func aliasIndices(client aliasUpdater, alias string) ([]string, error) {
+	aliases, err := client.ListAliases(alias)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list aliases: %w", err))
+	}
+	var indices []string
+	for _, a := range aliases {
+		if a.Alias == alias {
+			indices = append(indices, a.Index)
+		}
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// applyAliasActions applies actions after confirmation
+func applyAliasActions(client aliasUpdater, alias string, actions []elasticsearch.AliasAction, prompter *prompt.Prompter, log *logger.Logger) error {
+	if err := prompter.Confirm(fmt.Sprintf("Apply %d change(s) to alias '%s'?", len(actions), alias)); err != nil {
+		return fmt.Errorf("alias update aborted: %w", err)
+	}
+	if err := client.UpdateAliases(actions); err != nil {
+		return fmt.Errorf("failed to update alias '%s': %w", alias, err)
+	}
+	log.Successf("Updated alias '%s'", alias)
+	return nil
+}
+
+// setAliasActions returns the actions that make alias point to exactly the target indices: removals from the
+// current indices that are not a target, then additions to the targets it does not point to yet
+func setAliasActions(alias string, current, targets []string) []elasticsearch.AliasAction {
+	var actions []elasticsearch.AliasAction
+	for _, index := range current {
+		if !slices.Contains(targets, index) {
+			actions = append(actions, elasticsearch.AliasAction{Type: elasticsearch.AliasActionRemove, Index: index, Alias: alias})
+		}
+	}
+	for _, index := range slices.Compact(slices.Sorted(slices.Values(targets))) {
+		if !slices.Contains(current, index) {
+			actions = append(actions, elasticsearch.AliasAction{Type: elasticsearch.AliasActionAdd, Index: index, Alias: alias})
+		}
+	}
+	return actions
+}
+
+// removeAliasActions returns the actions that remove alias from indices, or from all current indices when indices
+// is empty. Removing an alias that does not exist, or from an index it does not point to, is a usage error.
+func removeAliasActions(alias string, current, indices []string) ([]elasticsearch.AliasAction, error) {
+	if len(current) == 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("alias '%s' does not exist", alias))
+	}
+	if len(indices) == 0 {
+		indices = current
+	}
+
+	var unknown []string
+	for _, index := range indices {
+		if !slices.Contains(current, index) {
+			unknown = append(unknown, index)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("alias '%s' does not point to %s (it points to %s)",
+			alias, strings.Join(unknown, ", "), strings.Join(current, ", ")))
+	}
+
+	actions := make([]elasticsearch.AliasAction, 0, len(indices))
+	for _, index := range indices {
+		actions = append(actions, elasticsearch.AliasAction{Type: elasticsearch.AliasActionRemove, Index: index, Alias: alias})
+	}
+	return actions, nil
+}
+
+// aliasesTable lists the aliases sorted by alias and index
+func aliasesTable(aliases []elasticsearch.Alias) output.Table {
+	sorted := append([]elasticsearch.Alias{}, aliases...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Alias != sorted[j].Alias {
+			return sorted[i].Alias < sorted[j].Alias
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	table := output.Table{
+		Headers: []string{"ALIAS", "INDEX", "WRITE INDEX"},
+		Rows:    make([][]string, 0, len(sorted)),
+	}
+	for _, alias := range sorted {
+		table.Rows = append(table.Rows, []string{alias.Alias, alias.Index, alias.IsWriteIndex})
+	}
+	return table
+}
+
+// aliasActionsTable lists the alias changes to apply
+func aliasActionsTable(actions []elasticsearch.AliasAction) output.Table {
+	table := output.Table{
+		Headers: []string{"ACTION", "ALIAS", "INDEX"},
+		Rows:    make([][]string, 0, len(actions)),
+	}
+	for _, action := range actions {
+		table.Rows = append(table.Rows, []string{action.Type, action.Alias, action.Index})
+	}
+	return table
+}
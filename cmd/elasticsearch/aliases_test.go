@@ -0,0 +1,147 @@
+package elasticsearch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAliasUpdater struct {
+	aliases   []elasticsearch.Alias
+	updated   []elasticsearch.AliasAction
+	updateErr error
+}
+
+func (m *mockAliasUpdater) ListAliases(_ string) ([]elasticsearch.Alias, error) {
+	return m.aliases, nil
+}
+
+func (m *mockAliasUpdater) UpdateAliases(actions []elasticsearch.AliasAction) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	m.updated = append(m.updated, actions...)
+	return nil
+}
+
+func TestAliasesCmd_Unit(t *testing.T) {
+	cmd := aliasesCmd(config.NewContext())
+
+	assert.Equal(t, "aliases", cmd.Use)
+	for _, name := range []string{"list", "set", "remove"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(sub.Use, name))
+	}
+	set, _, err := cmd.Find([]string{"set"})
+	require.NoError(t, err)
+	assert.Error(t, set.Args(set, nil), "an alias is required")
+	assert.Equal(t, []string{"true"}, set.Flags().Lookup("index").Annotations["cobra_annotation_bash_completion_one_required_flag"])
+}
+
+func TestAliasIndices(t *testing.T) {
+	client := &mockAliasUpdater{aliases: []elasticsearch.Alias{
+		{Alias: "topology", Index: "sts_topology-2"},
+		{Alias: "topology-old", Index: "sts_topology-0"},
+		{Alias: "topology", Index: "sts_topology-1"},
+	}}
+
+	indices, err := aliasIndices(client, "topology")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sts_topology-1", "sts_topology-2"}, indices)
+}
+
+func TestSetAliasActions(t *testing.T) {
+	actions := setAliasActions("topology", []string{"sts_topology", "sts_topology-old"}, []string{"restored-sts_topology", "sts_topology", "restored-sts_topology"})
+
+	assert.Equal(t, []elasticsearch.AliasAction{
+		{Type: elasticsearch.AliasActionRemove, Index: "sts_topology-old", Alias: "topology"},
+		{Type: elasticsearch.AliasActionAdd, Index: "restored-sts_topology", Alias: "topology"},
+	}, actions)
+	assert.Empty(t, setAliasActions("topology", []string{"sts_topology"}, []string{"sts_topology"}))
+}
+
+func TestRemoveAliasActions(t *testing.T) {
+	current := []string{"sts_topology-1", "sts_topology-2"}
+
+	tests := []struct {
+		name          string
+		current       []string
+		indices       []string
+		expected      []string
+		expectedError string
+	}{
+		{name: "all indices", current: current, expected: current},
+		{name: "selected index", current: current, indices: []string{"sts_topology-2"}, expected: []string{"sts_topology-2"}},
+		{name: "index without the alias", current: current, indices: []string{"sts_metrics"}, expectedError: "does not point to sts_metrics"},
+		{name: "missing alias", expectedError: "alias 'topology' does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions, err := removeAliasActions("topology", tt.current, tt.indices)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+				return
+			}
+			require.NoError(t, err)
+			var indices []string
+			for _, action := range actions {
+				assert.Equal(t, elasticsearch.AliasActionRemove, action.Type)
+				indices = append(indices, action.Index)
+			}
+			assert.Equal(t, tt.expected, indices)
+		})
+	}
+}
+
+func TestApplyAliasActions(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	actions := []elasticsearch.AliasAction{{Type: elasticsearch.AliasActionAdd, Index: "restored-sts_topology", Alias: "topology"}}
+
+	t.Run("applies after confirmation", func(t *testing.T) {
+		client := &mockAliasUpdater{}
+		err := applyAliasActions(client, "topology", actions, prompt.NewWithIO(strings.NewReader("y\n"), &strings.Builder{}, false, true), log)
+		require.NoError(t, err)
+		assert.Equal(t, actions, client.updated)
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		client := &mockAliasUpdater{}
+		err := applyAliasActions(client, "topology", actions, prompt.NewWithIO(strings.NewReader("n\n"), &strings.Builder{}, false, true), log)
+		require.Error(t, err)
+		assert.Empty(t, client.updated)
+	})
+
+	t.Run("update fails", func(t *testing.T) {
+		client := &mockAliasUpdater{updateErr: errors.New("boom")}
+		err := applyAliasActions(client, "topology", actions, prompt.NewWithIO(strings.NewReader(""), &strings.Builder{}, true, false), log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to update alias 'topology': boom")
+	})
+}
+
+func TestAliasesTable(t *testing.T) {
+	table := aliasesTable([]elasticsearch.Alias{
+		{Alias: "topology", Index: "sts_topology-2", IsWriteIndex: "true"},
+		{Alias: "metrics", Index: "sts_metrics", IsWriteIndex: "-"},
+		{Alias: "topology", Index: "sts_topology-1", IsWriteIndex: "false"},
+	})
+
+	assert.Equal(t, [][]string{
+		{"metrics", "sts_metrics", "-"},
+		{"topology", "sts_topology-1", "false"},
+		{"topology", "sts_topology-2", "true"},
+	}, table.Rows)
+}
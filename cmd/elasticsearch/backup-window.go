@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// maxListedTimes is the number of times of day listed before the rest is counted
+const maxListedTimes = 3
+
+// addForceFlag adds --force, which runs a command outside of the backup window
+func addForceFlag(cmd *cobra.Command, cliCtx *config.Context) {
+	cmd.Flags().BoolVar(&cliCtx.Config.IgnoreBackupWindow, "force", false,
+		"Run outside of the backup window (elasticsearch.backupWindow)")
+}
+
+// checkBackupWindow refuses to run an operation outside of the configured backup window, so heavy snapshots and
+// deletions stay out of the peak ingest hours, unless --force is given. The refusal is an exitcode.ValidationFailed
+// error.
+func checkBackupWindow(window config.BackupWindowConfig, operation string, cliCtx *config.Context, now time.Time, log *logger.Logger) error {
+	err := window.Check(now)
+	switch {
+	case err == nil:
+		return nil
+	case !errors.Is(err, config.ErrOutsideBackupWindow):
+		return exitcode.Wrap(exitcode.ConfigError, err)
+	case cliCtx.Config.IgnoreBackupWindow:
+		log.Warningf("Running %s %s (--force)", operation, err)
+		return nil
+	default:
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%s refused: %w; use --force to run it anyway", operation, err))
+	}
+}
+
+// checkScheduleWindow fails with an exitcode.ConfigError when an SLM policy is scheduled outside of the backup
+// window. SLM evaluates its cron schedules in UTC, so the times of day are compared on the date of now in UTC.
+func checkScheduleWindow(policies []config.SLMConfig, window config.BackupWindowConfig, now time.Time, log *logger.Logger) error {
+	if !window.Enabled() {
+		return nil
+	}
+
+	var problems []string
+	for _, policy := range policies {
+		outside, err := scheduleOutsideWindow(policy.Schedule, window, now)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("SLM policy '%s': %s", policy.Name, err))
+			continue
+		}
+		if len(outside) > 0 {
+			problems = append(problems, fmt.Sprintf("SLM policy '%s' with schedule '%s' takes snapshots at %s UTC",
+				policy.Name, policy.Schedule, summarizeTimes(outside)))
+		}
+	}
+	if len(problems) > 0 {
+		return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("SLM schedules outside of the backup window %s:\n  %s",
+			window, strings.Join(problems, "\n  ")))
+	}
+	log.Infof("All SLM schedules are inside the backup window %s", window)
+	return nil
+}
+
+// scheduleOutsideWindow returns the times of day, formatted as HH:MM:SS, an SLM cron schedule fires at outside of
+// the window
+func scheduleOutsideWindow(schedule string, window config.BackupWindowConfig, now time.Time) ([]string, error) {
+	times, err := elasticsearch.ScheduleTimesOfDay(schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	year, month, day := now.UTC().Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	var outside []string
+	for _, offset := range times {
+		fire := midnight.Add(offset)
+		inside, err := window.Contains(fire)
+		if err != nil {
+			return nil, err
+		}
+		if !inside {
+			outside = append(outside, fire.Format(time.TimeOnly))
+		}
+	}
+	return outside, nil
+}
+
+// summarizeTimes lists the first times and counts the others, since e.g. an hourly schedule fires 24 times a day
+func summarizeTimes(times []string) string {
+	if len(times) <= maxListedTimes {
+		return strings.Join(times, ", ")
+	}
+	return fmt.Sprintf("%s and %d more time(s)", strings.Join(times[:maxListedTimes], ", "), len(times)-maxListedTimes)
+}
@@ -0,0 +1,77 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBackupWindow(t *testing.T) {
+	window := config.BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}
+	log := logger.New(logger.LevelError, "")
+	peak := time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		window       config.BackupWindowConfig
+		now          time.Time
+		force        bool
+		expectedCode int
+	}{
+		{name: "no window", now: peak, expectedCode: exitcode.Success},
+		{name: "inside the window", window: window, now: time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC), expectedCode: exitcode.Success},
+		{name: "outside the window", window: window, now: peak, expectedCode: exitcode.ValidationFailed},
+		{name: "outside the window with --force", window: window, now: peak, force: true, expectedCode: exitcode.Success},
+		{name: "invalid window", window: config.BackupWindowConfig{Start: "1am", End: "05:00"}, now: peak, expectedCode: exitcode.ConfigError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliCtx := config.NewContext()
+			cliCtx.Config.IgnoreBackupWindow = tt.force
+
+			err := checkBackupWindow(tt.window, "create-snapshot", cliCtx, tt.now, log)
+
+			assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+		})
+	}
+
+	err := checkBackupWindow(window, "enforce-retention", config.NewContext(), peak, log)
+	assert.EqualError(t, err, "enforce-retention refused: outside of the backup window 01:00-05:00 UTC (it is 14:30); use --force to run it anyway")
+}
+
+func TestCheckScheduleWindow(t *testing.T) {
+	window := config.BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}
+	log := logger.New(logger.LevelError, "")
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, checkScheduleWindow([]config.SLMConfig{{Name: "daily", Schedule: "0 0 3 * * ?"}}, window, now, log))
+	require.NoError(t, checkScheduleWindow([]config.SLMConfig{{Name: "hourly", Schedule: "0 0 * * * ?"}}, config.BackupWindowConfig{}, now, log),
+		"no window configured")
+
+	err := checkScheduleWindow([]config.SLMConfig{
+		{Name: "daily", Schedule: "0 0 3 * * ?"},
+		{Name: "hourly", Schedule: "0 0 * * * ?"},
+		{Name: "interval", Schedule: "1h"},
+	}, window, now, log)
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.ConfigError, exitcode.Of(err))
+	assert.NotContains(t, err.Error(), "'daily'")
+	assert.Contains(t, err.Error(), "SLM policy 'hourly' with schedule '0 0 * * * ?' takes snapshots at 00:00:00, 05:00:00, 06:00:00 and 17 more time(s) UTC")
+	assert.Contains(t, err.Error(), "SLM policy 'interval': schedule '1h' is not a cron expression")
+}
+
+func TestCheckScheduleWindow_TimeZone(t *testing.T) {
+	// 01:00-05:00 in Amsterdam is 00:00-04:00 UTC in winter
+	window := config.BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "Europe/Amsterdam"}
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, checkScheduleWindow([]config.SLMConfig{{Name: "daily", Schedule: "0 30 3 * * ?"}}, window, now, logger.New(logger.LevelError, "")))
+	assert.Error(t, checkScheduleWindow([]config.SLMConfig{{Name: "daily", Schedule: "0 30 4 * * ?"}}, window, now, logger.New(logger.LevelError, "")))
+}
@@ -0,0 +1,244 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// defaultMaxBackupAge leaves a daily SLM schedule two hours to finish before the backup counts as stale
+const defaultMaxBackupAge = 26 * time.Hour
+
+// Freshness statuses and the reasons a backup is stale
+const (
+	freshnessFresh = "FRESH"
+	freshnessStale = "STALE"
+
+	staleReasonTooOld     = "snapshot-too-old"
+	staleReasonNoSnapshot = "no-successful-snapshot"
+)
+
+// freshness is the outcome of check-freshness, printed as is with --output json or yaml
+type freshness struct {
+	// Namespace is only set when several namespaces are checked
+	Namespace string `json:"namespace,omitempty"`
+	Status    string `json:"status"`
+	// Reason is a stable identifier of why the backup is stale, for alerting rules
+	Reason        string `json:"reason,omitempty"`
+	Repository    string `json:"repository"`
+	Snapshot      string `json:"snapshot,omitempty"`
+	StartTime     string `json:"startTime,omitempty"`
+	AgeSeconds    int64  `json:"ageSeconds,omitempty"`
+	MaxAgeSeconds int64  `json:"maxAgeSeconds"`
+}
+
+func checkFreshnessCmd(cliCtx *config.Context) *cobra.Command {
+	var maxAge time.Duration
+	var namespaces namespacesOptions
+	cmd := &cobra.Command{
+		Use:   "check-freshness",
+		Short: "Check that the latest successful snapshot is recent enough",
+		Long: `Check that the most recent SUCCESS snapshot in the SLM repository is younger than --max-age. Exits with
+code 5 when it is older, or when there is no successful snapshot at all, and prints the reason (snapshot-too-old or
+no-successful-snapshot), so a CronJob running it can serve as a cheap backup SLO monitor.
+
+--namespaces ns1,ns2 or --all-configured-namespaces check the installations in several namespaces one after another
+and print one row per namespace (a list with --output json or yaml). The exit code is that of the first namespace
+that is stale or cannot be checked.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := runCheckFreshness(cmd, cliCtx, maxAge, &namespaces); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&maxAge, "max-age", defaultMaxBackupAge, "Maximum age of the latest successful snapshot")
+	addNamespacesFlags(cmd, &namespaces)
+	return cmd
+}
+
+func runCheckFreshness(cmd *cobra.Command, cliCtx *config.Context, maxAge time.Duration, namespaces *namespacesOptions) error {
+	if maxAge <= 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--max-age must be positive, got %s", maxAge))
+	}
+
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	if namespaces.Enabled() {
+		return checkFreshnessInNamespaces(cmd, cliCtx, maxAge, namespaces, log)
+	}
+
+	startedAt := time.Now()
+	result, cfg, err := fetchFreshness(cliCtx, maxAge, startedAt)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintDetail(result, freshnessTable(result)); err != nil {
+		return err
+	}
+	return reportFreshness(cfg, cliCtx, result, maxAge, startedAt, log)
+}
+
+// checkFreshnessInNamespaces checks the backups of the installations in several namespaces and prints them in one
+// table. Every namespace is notified about on its own; the namespaces that are stale or fail are returned as error.
+func checkFreshnessInNamespaces(cmd *cobra.Command, cliCtx *config.Context, maxAge time.Duration, opts *namespacesOptions, log *logger.Logger) error {
+	namespaces, err := resolveNamespaces(cmd, cliCtx, opts, log)
+	if err != nil {
+		return err
+	}
+
+	results := make([]freshness, 0, len(namespaces))
+	var errs []error
+	for _, namespace := range namespaces {
+		log.Infof("Checking the backup freshness of namespace '%s'...", namespace)
+		nsCtx := namespaceContext(cliCtx, namespace)
+		startedAt := time.Now()
+		result, cfg, err := fetchFreshness(nsCtx, maxAge, startedAt)
+		if err != nil {
+			log.Errorf("Failed to check the backup freshness of namespace '%s': %v", namespace, err)
+			errs = append(errs, fmt.Errorf("namespace '%s': %w", namespace, err))
+			continue
+		}
+		result.Namespace = namespace
+		results = append(results, result)
+		if err := reportFreshness(cfg, nsCtx, result, maxAge, startedAt, log); err != nil {
+			errs = append(errs, fmt.Errorf("namespace '%s': %w", namespace, err))
+		}
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintDetail(results, freshnessNamespacesTable(results)); err != nil {
+		return err
+	}
+	return namespaceErrors(errs, len(namespaces))
+}
+
+// fetchFreshness checks the age at now of the latest successful snapshot of the installation of cliCtx and returns
+// it with the configuration of the installation
+func fetchFreshness(cliCtx *config.Context, maxAge time.Duration, now time.Time) (freshness, *config.Config, error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return freshness{}, nil, err
+	}
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return freshness{}, nil, err
+	}
+	defer cleanup()
+
+	repository := env.Config.Elasticsearch.SLM.Repository
+	env.Log.Infof("Fetching snapshots from repository '%s'...", repository)
+	snapshots, err := esClient.ListSnapshots(repository)
+	if err != nil {
+		return freshness{}, nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list snapshots: %w", err))
+	}
+	return evaluateFreshness(snapshots, repository, maxAge, now), env.Config, nil
+}
+
+// reportFreshness notifies the configured targets of a stale backup and returns it as error
+func reportFreshness(cfg *config.Config, cliCtx *config.Context, result freshness, maxAge time.Duration, startedAt time.Time, log *logger.Logger) error {
+	staleErr := freshnessError(result, maxAge)
+	// A stale backup is reported like a failed operation, so it shows up where failed restores do
+	sendNotification(cfg, cliCtx, "check-freshness", startedAt, staleErr, map[string]string{"snapshot": result.Snapshot}, log)
+	if staleErr != nil {
+		return staleErr
+	}
+	log.Successf("Latest successful snapshot '%s' is %s old", result.Snapshot, time.Duration(result.AgeSeconds)*time.Second)
+	return nil
+}
+
+// freshnessError returns the reason the backup is stale as an error, or nil when it is fresh
+func freshnessError(result freshness, maxAge time.Duration) error {
+	switch result.Reason {
+	case staleReasonNoSnapshot:
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%s: repository '%s' has no successful snapshot", result.Reason, result.Repository))
+	case staleReasonTooOld:
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%s: latest successful snapshot '%s' is %s old (max %s)",
+			result.Reason, result.Snapshot, time.Duration(result.AgeSeconds)*time.Second, maxAge))
+	}
+	return nil
+}
+
+// evaluateFreshness checks the age at now of the latest successful snapshot against maxAge
+func evaluateFreshness(snapshots []elasticsearch.Snapshot, repository string, maxAge time.Duration, now time.Time) freshness {
+	result := freshness{Status: freshnessFresh, Repository: repository, MaxAgeSeconds: int64(maxAge.Seconds())}
+
+	latest := elasticsearch.LatestSuccessful(snapshots)
+	if latest == nil {
+		result.Status, result.Reason = freshnessStale, staleReasonNoSnapshot
+		return result
+	}
+
+	startTime := time.UnixMilli(latest.StartTimeMillis)
+	age := now.Sub(startTime)
+	result.Snapshot = latest.Snapshot
+	result.StartTime = startTime.UTC().Format(time.RFC3339)
+	result.AgeSeconds = int64(age.Seconds())
+	if age > maxAge {
+		result.Status, result.Reason = freshnessStale, staleReasonTooOld
+	}
+	return result
+}
+
+// freshnessTable shows the outcome of check-freshness
+func freshnessTable(result freshness) output.Table {
+	table := output.Table{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"Status", result.Status},
+			{"Repository", result.Repository},
+			{"Max age", (time.Duration(result.MaxAgeSeconds) * time.Second).String()},
+		},
+	}
+	if result.Reason != "" {
+		table.Rows = append(table.Rows, []string{"Reason", result.Reason})
+	}
+	if result.Snapshot != "" {
+		table.Rows = append(table.Rows,
+			[]string{"Latest snapshot", result.Snapshot},
+			[]string{"Start time", result.StartTime},
+			[]string{"Age", output.FormatAge(time.Duration(result.AgeSeconds) * time.Second)},
+		)
+	}
+	return table
+}
+
+// freshnessNamespacesTable shows the outcome of check-freshness for several namespaces, one row per namespace
+func freshnessNamespacesTable(results []freshness) output.Table {
+	table := output.Table{
+		Headers:      []string{"NAMESPACE", "STATUS", "REASON", "SNAPSHOT", "AGE", "MAX AGE"},
+		Rows:         make([][]string, 0, len(results)),
+		StateColumns: []string{"STATUS"},
+	}
+	for _, result := range results {
+		reason, snapshot, age := "-", "-", "-"
+		if result.Reason != "" {
+			reason = result.Reason
+		}
+		if result.Snapshot != "" {
+			snapshot = result.Snapshot
+			age = output.FormatAge(time.Duration(result.AgeSeconds) * time.Second)
+		}
+		table.Rows = append(table.Rows, []string{
+			result.Namespace,
+			result.Status,
+			reason,
+			snapshot,
+			age,
+			(time.Duration(result.MaxAgeSeconds) * time.Second).String(),
+		})
+	}
+	return table
+}
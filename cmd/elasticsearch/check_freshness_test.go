@@ -0,0 +1,97 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckFreshnessCmd_Unit(t *testing.T) {
+	cmd := checkFreshnessCmd(config.NewContext())
+
+	assert.Equal(t, "check-freshness", cmd.Use)
+	flag := cmd.Flags().Lookup("max-age")
+	if assert.NotNil(t, flag) {
+		assert.Equal(t, "26h0m0s", flag.DefValue)
+	}
+}
+
+func TestEvaluateFreshness(t *testing.T) {
+	now := time.Date(2025, 3, 5, 6, 0, 0, 0, time.UTC)
+	snapshot := func(name, state string, age time.Duration) elasticsearch.Snapshot {
+		return elasticsearch.Snapshot{Snapshot: name, State: state, StartTimeMillis: now.Add(-age).UnixMilli()}
+	}
+
+	tests := []struct {
+		name      string
+		snapshots []elasticsearch.Snapshot
+		expected  freshness
+	}{
+		{
+			name:      "fresh",
+			snapshots: []elasticsearch.Snapshot{snapshot("sts-backup-2", "SUCCESS", 3*time.Hour), snapshot("sts-backup-1", "SUCCESS", 27*time.Hour)},
+			expected: freshness{Status: freshnessFresh, Repository: "sts-backup", Snapshot: "sts-backup-2",
+				StartTime: "2025-03-05T03:00:00Z", AgeSeconds: 3 * 3600, MaxAgeSeconds: 26 * 3600},
+		},
+		{
+			name:      "newer snapshot failed",
+			snapshots: []elasticsearch.Snapshot{snapshot("sts-backup-2", "FAILED", 3*time.Hour), snapshot("sts-backup-1", "SUCCESS", 27*time.Hour)},
+			expected: freshness{Status: freshnessStale, Reason: staleReasonTooOld, Repository: "sts-backup", Snapshot: "sts-backup-1",
+				StartTime: "2025-03-04T03:00:00Z", AgeSeconds: 27 * 3600, MaxAgeSeconds: 26 * 3600},
+		},
+		{
+			name:      "no successful snapshot",
+			snapshots: []elasticsearch.Snapshot{snapshot("sts-backup-1", "PARTIAL", time.Hour)},
+			expected:  freshness{Status: freshnessStale, Reason: staleReasonNoSnapshot, Repository: "sts-backup", MaxAgeSeconds: 26 * 3600},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, evaluateFreshness(tt.snapshots, "sts-backup", defaultMaxBackupAge, now))
+		})
+	}
+}
+
+func TestFreshnessTable(t *testing.T) {
+	table := freshnessTable(freshness{Status: freshnessStale, Reason: staleReasonTooOld, Repository: "sts-backup",
+		Snapshot: "sts-backup-1", StartTime: "2025-03-04T03:00:00Z", AgeSeconds: 27 * 3600, MaxAgeSeconds: 26 * 3600})
+
+	assert.Equal(t, [][]string{
+		{"Status", "STALE"},
+		{"Repository", "sts-backup"},
+		{"Max age", "26h0m0s"},
+		{"Reason", "snapshot-too-old"},
+		{"Latest snapshot", "sts-backup-1"},
+		{"Start time", "2025-03-04T03:00:00Z"},
+		{"Age", "1d3h"},
+	}, table.Rows)
+}
+
+func TestFreshnessError(t *testing.T) {
+	assert.NoError(t, freshnessError(freshness{Status: freshnessFresh}, defaultMaxBackupAge))
+
+	err := freshnessError(freshness{Status: freshnessStale, Reason: staleReasonTooOld, Snapshot: "sts-backup-1", AgeSeconds: 27 * 3600}, defaultMaxBackupAge)
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+	assert.ErrorContains(t, err, "snapshot-too-old: latest successful snapshot 'sts-backup-1' is 27h0m0s old (max 26h0m0s)")
+
+	err = freshnessError(freshness{Status: freshnessStale, Reason: staleReasonNoSnapshot, Repository: "sts-backup"}, defaultMaxBackupAge)
+	assert.ErrorContains(t, err, "no-successful-snapshot: repository 'sts-backup' has no successful snapshot")
+}
+
+func TestFreshnessNamespacesTable(t *testing.T) {
+	table := freshnessNamespacesTable([]freshness{
+		{Namespace: "obs-a", Status: freshnessFresh, Repository: "sts-backup", Snapshot: "sts-backup-2", AgeSeconds: 3600, MaxAgeSeconds: 26 * 3600},
+		{Namespace: "obs-b", Status: freshnessStale, Reason: staleReasonNoSnapshot, Repository: "sts-backup", MaxAgeSeconds: 26 * 3600},
+	})
+
+	assert.Equal(t, []string{"NAMESPACE", "STATUS", "REASON", "SNAPSHOT", "AGE", "MAX AGE"}, table.Headers)
+	assert.Equal(t, []string{"STATUS"}, table.StateColumns)
+	assert.Equal(t, [][]string{
+		{"obs-a", "FRESH", "-", "sts-backup-2", "1h0m", "26h0m0s"},
+		{"obs-b", "STALE", "no-successful-snapshot", "-", "-", "26h0m0s"},
+	}, table.Rows)
+}
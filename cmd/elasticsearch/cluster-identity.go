@@ -0,0 +1,45 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+)
+
+// clusterIdentity identifies the cluster a Kubernetes client talks to
+type clusterIdentity interface {
+	Host() string
+	ContextName() string
+}
+
+// addConfirmNamespaceFlag adds the --confirm-namespace flag to a destructive command
+func addConfirmNamespaceFlag(cmd *cobra.Command, cliCtx *config.Context) {
+	cmd.Flags().StringVar(&cliCtx.Config.ConfirmNamespace, "confirm-namespace", "",
+		"Confirm the target cluster non-interactively by repeating the namespace name")
+}
+
+// confirmClusterIdentity shows the API server, context and namespace a destructive operation is about to run
+// against, and has the operator type the namespace name to confirm them. This guards against running against
+// the wrong cluster of a kubeconfig with several environments. With --confirm-namespace the given name has to
+// match instead; --yes skips the prompt.
+func confirmClusterIdentity(cluster clusterIdentity, cliCtx *config.Context, prompter *prompt.Prompter, log *logger.Logger) error {
+	namespace := cliCtx.Config.Namespace
+	log.Infof("Target cluster: API server %s, context '%s', namespace '%s'", cluster.Host(), cluster.ContextName(), namespace)
+
+	if confirm := cliCtx.Config.ConfirmNamespace; confirm != "" {
+		if confirm != namespace {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--confirm-namespace '%s' does not match the target namespace '%s'", confirm, namespace))
+		}
+		return nil
+	}
+
+	question := fmt.Sprintf("Running against context '%s' (%s). Type the namespace name to confirm:", cluster.ContextName(), cluster.Host())
+	if err := prompter.ConfirmValue(question, namespace); err != nil {
+		return fmt.Errorf("target cluster not confirmed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+type importClusterSettingsOptions struct {
+	Key      string
+	Settings []string
+}
+
+func exportClusterSettingsCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export-cluster-settings",
+		Short: "Export the persistent and transient cluster settings to the backup bucket",
+		Long: `Export the explicitly set persistent and transient cluster settings as a JSON artifact under
+exports/elasticsearch/cluster-settings/ in the backup bucket. Cluster settings, such as slow log thresholds and
+allocation awareness, are not part of index snapshots and are lost when the cluster is rebuilt.
+
+The artifact is encrypted when archives.encryption is configured. Re-apply it with 'import-cluster-settings'.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runExportClusterSettings(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func importClusterSettingsCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &importClusterSettingsOptions{}
+	cmd := &cobra.Command{
+		Use:   "import-cluster-settings",
+		Short: "Re-apply cluster settings from the backup bucket",
+		Long: `Re-apply cluster settings exported with 'export-cluster-settings' as persistent settings. Transient settings of
+the export are applied as persistent settings too, taking precedence like they did when exported. Only settings
+that differ from the current value are changed; settings that are not part of the export are left unchanged.
+
+Select settings with --setting, e.g. --setting 'cluster.routing.allocation.awareness.*'. The most recent export is
+imported unless --key is given.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runImportClusterSettings(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Key, "key", "", "Object key of the export to import (default: the most recent export)")
+	cmd.Flags().StringSliceVar(&opts.Settings, "setting", nil, "Setting name or glob pattern to import, repeatable (default: all settings of the export)")
+	return cmd
+}
+
+func runExportClusterSettings(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	store, cleanup, err := target.OpenExportStore(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	esClient, closeES, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer closeES()
+
+	env.Log.Infof("Fetching cluster settings...")
+	settings, err := esClient.GetClusterSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	count := len(mergedClusterSettings(settings))
+	if count == 0 {
+		env.Log.Warningf("No persistent or transient cluster settings are set, nothing to export")
+		return nil
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode cluster settings: %w", err)
+	}
+	key, err := store.Put(&export.Artifact{
+		Kind:       export.KindClusterSettings,
+		ExportedAt: time.Now(),
+		Namespace:  cliCtx.Config.Namespace,
+		RunID:      cliCtx.RunID,
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	env.Log.Successf("Exported %d cluster setting(s) to '%s'", count, key)
+	return nil
+}
+
+func runImportClusterSettings(cliCtx *config.Context, opts *importClusterSettingsOptions) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "import-cluster-settings"}, err, env.Log)
+	}()
+
+	exported, err := loadClusterSettings(env, opts.Key)
+	if err != nil {
+		return err
+	}
+	selected := selectClusterSettings(exported, opts.Settings)
+	if len(selected) == 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("no setting of the export matches %v", opts.Settings))
+	}
+
+	esClient, closeES, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer closeES()
+
+	current, err := esClient.GetClusterSettings()
+	if err != nil {
+		return fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	changes := clusterSettingChanges(selected, current)
+	if len(changes) == 0 {
+		env.Log.Successf("All %d selected cluster setting(s) already have the exported value", len(selected))
+		return nil
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintTable(clusterSettingsTable(changes, current)); err != nil {
+		return err
+	}
+
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := prompter.Confirm(fmt.Sprintf("Apply %d cluster setting(s) as persistent settings?", len(changes))); err != nil {
+		return fmt.Errorf("import aborted: %w", err)
+	}
+
+	if err := esClient.PutClusterSettings(changes); err != nil {
+		return fmt.Errorf("failed to apply cluster settings: %w", err)
+	}
+	env.Log.Successf("Applied %d cluster setting(s)", len(changes))
+	return nil
+}
+
+// loadClusterSettings reads the cluster settings of the export stored under key, or of the most recent export
+// when key is empty
+func loadClusterSettings(env *target.Env, key string) (*elasticsearch.ClusterSettings, error) {
+	store, cleanup, err := target.OpenExportStore(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var artifact *export.Artifact
+	if key == "" {
+		env.Log.Infof("Fetching most recent cluster settings export...")
+		key, artifact, err = store.Latest(export.KindClusterSettings)
+	} else {
+		env.Log.Infof("Fetching cluster settings export '%s'...", key)
+		artifact, err = store.Get(key)
+	}
+	if errors.Is(err, export.ErrNotFound) {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%w: run 'export-cluster-settings' first", err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Kind != export.KindClusterSettings {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is an export of %s, not of cluster settings", key, artifact.Kind))
+	}
+
+	var settings elasticsearch.ClusterSettings
+	if err := json.Unmarshal(artifact.Data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode cluster settings of '%s': %w", key, err)
+	}
+	env.Log.Infof("Export '%s' from %s contains %d cluster setting(s)", key, artifact.ExportedAt.Local().Format(time.RFC3339), len(mergedClusterSettings(&settings)))
+	return &settings, nil
+}
+
+// mergedClusterSettings returns the persistent and transient settings together, transient taking precedence
+func mergedClusterSettings(settings *elasticsearch.ClusterSettings) map[string]interface{} {
+	merged := make(map[string]interface{}, len(settings.Persistent)+len(settings.Transient))
+	for name, value := range settings.Persistent {
+		merged[name] = value
+	}
+	for name, value := range settings.Transient {
+		merged[name] = value
+	}
+	return merged
+}
+
+// selectClusterSettings returns the exported settings matching one of patterns, or all of them without patterns
+func selectClusterSettings(exported *elasticsearch.ClusterSettings, patterns []string) map[string]interface{} {
+	merged := mergedClusterSettings(exported)
+	if len(patterns) == 0 {
+		return merged
+	}
+
+	selected := make(map[string]interface{})
+	for name, value := range merged {
+		for _, pattern := range patterns {
+			if m, _ := path.Match(pattern, name); m {
+				selected[name] = value
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// clusterSettingChanges returns the settings whose value differs from the current one
+func clusterSettingChanges(settings map[string]interface{}, current *elasticsearch.ClusterSettings) map[string]interface{} {
+	changes := make(map[string]interface{})
+	for name, value := range settings {
+		if currentValue, ok := current.Get(name); ok && reflect.DeepEqual(currentValue, value) {
+			continue
+		}
+		changes[name] = value
+	}
+	return changes
+}
+
+// clusterSettingsTable lists the settings to apply with their current value
+func clusterSettingsTable(changes map[string]interface{}, current *elasticsearch.ClusterSettings) output.Table {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	table := output.Table{
+		Headers: []string{"SETTING", "CURRENT", "EXPORTED"},
+		Rows:    make([][]string, 0, len(names)),
+	}
+	for _, name := range names {
+		currentValue := "(default)"
+		if value, ok := current.Get(name); ok {
+			currentValue = settingValue(value)
+		}
+		table.Rows = append(table.Rows, []string{name, currentValue, settingValue(changes[name])})
+	}
+	return table
+}
+
+// settingValue formats a setting value: strings as they are, lists and other values as JSON
+func settingValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
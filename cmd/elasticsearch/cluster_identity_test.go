@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClusterIdentity struct{}
+
+func (mockClusterIdentity) Host() string        { return "https://production:6443" }
+func (mockClusterIdentity) ContextName() string { return "production" }
+
+func TestConfirmClusterIdentity(t *testing.T) {
+	tests := []struct {
+		name             string
+		confirmNamespace string
+		assumeYes        bool
+		interactive      bool
+		input            string
+		expectedCode     int
+	}{
+		{name: "namespace typed", interactive: true, input: "suse-observability\n"},
+		{name: "wrong namespace typed", interactive: true, input: "staging\n", expectedCode: exitcode.Cancelled},
+		{name: "confirm-namespace matches", confirmNamespace: "suse-observability"},
+		{name: "confirm-namespace does not match", confirmNamespace: "staging", assumeYes: true, expectedCode: exitcode.Usage},
+		{name: "assume yes", assumeYes: true},
+		{name: "not a terminal", expectedCode: exitcode.Usage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliCtx := &config.Context{Config: &config.CLIConfig{Namespace: "suse-observability", ConfirmNamespace: tt.confirmNamespace}}
+			var out bytes.Buffer
+			prompter := prompt.NewWithIO(strings.NewReader(tt.input), &out, tt.assumeYes, tt.interactive)
+
+			err := confirmClusterIdentity(mockClusterIdentity{}, cliCtx, prompter, logger.New(logger.LevelError, ""))
+
+			if tt.expectedCode == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+			}
+			if tt.interactive {
+				assert.Contains(t, out.String(), "context 'production' (https://production:6443)")
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stretchr/testify/assert"
+)
+
+func testExportedClusterSettings() *elasticsearch.ClusterSettings {
+	return &elasticsearch.ClusterSettings{
+		Persistent: map[string]interface{}{
+			"cluster.routing.allocation.awareness.attributes": "zone",
+			"cluster.routing.allocation.disk.watermark.low":   "85%",
+			"index.search.slowlog.threshold.query.warn":       "10s",
+		},
+		Transient: map[string]interface{}{
+			"cluster.routing.allocation.disk.watermark.low": "90%",
+			"cluster.routing.allocation.exclude._name":      []interface{}{"es-data-2"},
+		},
+	}
+}
+
+func TestClusterSettingsCmd_Unit(t *testing.T) {
+	exportCmd := exportClusterSettingsCmd(config.NewContext())
+	assert.Equal(t, "export-cluster-settings", exportCmd.Use)
+	assert.NotEmpty(t, exportCmd.Long)
+	assert.NotNil(t, exportCmd.Run)
+
+	importCmd := importClusterSettingsCmd(config.NewContext())
+	assert.Equal(t, "import-cluster-settings", importCmd.Use)
+	assert.NotNil(t, importCmd.Flags().Lookup("key"))
+	assert.NotNil(t, importCmd.Flags().Lookup("setting"))
+}
+
+func TestSelectClusterSettings(t *testing.T) {
+	all := selectClusterSettings(testExportedClusterSettings(), nil)
+	assert.Len(t, all, 4)
+	assert.Equal(t, "90%", all["cluster.routing.allocation.disk.watermark.low"], "transient takes precedence")
+
+	selected := selectClusterSettings(testExportedClusterSettings(), []string{"cluster.routing.allocation.awareness.*", "index.search.slowlog.threshold.query.warn"})
+	assert.Equal(t, map[string]interface{}{
+		"cluster.routing.allocation.awareness.attributes": "zone",
+		"index.search.slowlog.threshold.query.warn":       "10s",
+	}, selected)
+
+	assert.Empty(t, selectClusterSettings(testExportedClusterSettings(), []string{"xpack.*"}))
+}
+
+func TestClusterSettingChanges(t *testing.T) {
+	current := &elasticsearch.ClusterSettings{
+		Persistent: map[string]interface{}{
+			"cluster.routing.allocation.awareness.attributes": "zone",
+			"cluster.routing.allocation.exclude._name":        []interface{}{"es-data-2"},
+			"index.search.slowlog.threshold.query.warn":       "5s",
+		},
+	}
+
+	changes := clusterSettingChanges(selectClusterSettings(testExportedClusterSettings(), nil), current)
+
+	assert.Equal(t, map[string]interface{}{
+		"cluster.routing.allocation.disk.watermark.low": "90%",
+		"index.search.slowlog.threshold.query.warn":     "10s",
+	}, changes)
+
+	table := clusterSettingsTable(changes, current)
+	assert.Equal(t, []string{"SETTING", "CURRENT", "EXPORTED"}, table.Headers)
+	assert.Equal(t, [][]string{
+		{"cluster.routing.allocation.disk.watermark.low", "(default)", "90%"},
+		{"index.search.slowlog.threshold.query.warn", "5s", "10s"},
+	}, table.Rows)
+}
+
+func TestSettingValue(t *testing.T) {
+	assert.Equal(t, "zone", settingValue("zone"))
+	assert.Equal(t, `["es-data-1","es-data-2"]`, settingValue([]interface{}{"es-data-1", "es-data-2"}))
+	assert.Equal(t, "true", settingValue(true))
+}
@@ -0,0 +1,84 @@
+package elasticsearch
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/cache"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// snapshotNamesCacheTTL is how long completed snapshot names are reused before Elasticsearch is queried again.
+// Setting up a port-forward takes a second or two, which is noticeable on every <TAB>.
+const snapshotNamesCacheTTL = 60 * time.Second
+
+// completeSnapshotNames returns a completion function listing the snapshots of the configured repository
+func completeSnapshotNames(cliCtx *config.Context) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		names, err := snapshotNames(cliCtx)
+		if err != nil {
+			cobra.CompDebugln("failed to complete snapshot names: "+err.Error(), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// snapshotNames lists snapshot names, served from the completion cache when it is fresh. The configuration is only
+// loaded on a cache miss, so a cached <TAB> does not read the ConfigMap and Secret either.
+func snapshotNames(cliCtx *config.Context) ([]string, error) {
+	// Completion output goes to the shell, so keep operational messages out of it
+	log := logger.New(logger.LevelError, "")
+
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, false, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := cache.New("completion", snapshotNamesCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	cacheKey := snapshotNamesCacheKey(k8sClient.ContextName(), k8sClient.Host(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName)
+
+	var names []string
+	if store.Get(cacheKey, &names) {
+		return names, nil
+	}
+
+	cfg, err := config.LoadConfig(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName, config.WithHelmValues(cliCtx.Config.HelmValues))
+	if err != nil {
+		return nil, err
+	}
+	repository := cfg.Elasticsearch.Restore.Repository
+
+	pf, err := portForwardElasticsearch(k8sClient, cliCtx, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	defer close(pf.StopChan)
+
+	esClient, err := newESClient(k8sClient, cliCtx, cfg, pf.LocalPort, log)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err = esClient.ListSnapshotNames(repository)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Put(cacheKey, names); err != nil {
+		cobra.CompDebugln("failed to cache snapshot names: "+err.Error(), true)
+	}
+	return names, nil
+}
+
+// snapshotNamesCacheKey identifies the installation whose snapshot names are cached: the same namespace on another
+// cluster, or with another configuration, has other snapshots
+func snapshotNamesCacheKey(contextName, host, namespace, configMapName string) string {
+	return strings.Join([]string{"snapshots", contextName, host, namespace, configMapName}, "-")
+}
@@ -0,0 +1,15 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotNamesCacheKey(t *testing.T) {
+	key := snapshotNamesCacheKey("prod", "https://prod.example.com:6443", "suse-observability", "suse-observability-backup-config")
+
+	assert.Equal(t, "snapshots-prod-https://prod.example.com:6443-suse-observability-suse-observability-backup-config", key)
+	assert.NotEqual(t, key, snapshotNamesCacheKey("staging", "https://staging.example.com:6443", "suse-observability", "suse-observability-backup-config"))
+	assert.NotEqual(t, key, snapshotNamesCacheKey("prod", "https://prod.example.com:6443", "suse-observability", "other-backup-config"))
+}
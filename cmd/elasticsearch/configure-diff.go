@@ -0,0 +1,200 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+)
+
+// Outcomes of configuring an object
+const (
+	configureCreated   = "created"
+	configureUpdated   = "updated"
+	configureUnchanged = "unchanged"
+)
+
+// redactedValue replaces the values of secret fields in the output
+const redactedValue = "<redacted>"
+
+// secretFields are the fields holding credentials. Elasticsearch may not return them, so they only count as
+// changed when the existing object has them.
+var secretFields = map[string]bool{
+	"settings.access_key": true,
+	"settings.secret_key": true,
+}
+
+// fieldChange is a field whose existing value differs from the desired value
+type fieldChange struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// configureResult is the outcome of configuring a repository or SLM policy
+type configureResult struct {
+	Object  string
+	Name    string
+	Status  string
+	Changes []fieldChange
+}
+
+// repositoryConfigurer gets and puts snapshot repositories
+type repositoryConfigurer interface {
+	GetRepository(name string) (*elasticsearch.Repository, error)
+	ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string, conn elasticsearch.S3Connection, tuning elasticsearch.RepositoryTuning) error
+}
+
+// configureRepository creates the snapshot repository, or updates it when it differs from the configuration
+func configureRepository(esClient repositoryConfigurer, repo config.SnapshotRepositoryConfig, log *logger.Logger) (*configureResult, error) {
+	conn := repositoryConnection(repo)
+	tuning := repositoryTuning(repo)
+	desired := elasticsearch.Repository{
+		Type:     "s3",
+		Settings: elasticsearch.S3RepositorySettings(repo.Bucket, repo.Endpoint, repo.BasePath, repo.AccessKey, repo.SecretKey, conn, tuning),
+	}
+
+	current, err := esClient.GetRepository(repo.Name)
+	if err != nil && !errors.Is(err, elasticsearch.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get snapshot repository: %w", err)
+	}
+
+	result := &configureResult{Object: "repository", Name: repo.Name, Status: configureCreated}
+	if current != nil {
+		if result.Changes, err = compareObjects(current, desired); err != nil {
+			return nil, err
+		}
+		result.Status = changedStatus(result.Changes)
+	}
+	if result.Status == configureUnchanged {
+		return result, nil
+	}
+
+	log.Infof("Configuring snapshot repository '%s' (bucket: %s)...", repo.Name, repo.Bucket)
+	err = esClient.ConfigureSnapshotRepository(repo.Name, repo.Bucket, repo.Endpoint, repo.BasePath, repo.AccessKey, repo.SecretKey, conn, tuning)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// compareObjects returns the fields of the existing object that differ from the desired object
+func compareObjects(current, desired interface{}) ([]fieldChange, error) {
+	currentFields, err := flattenFields(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredFields, err := flattenFields(desired)
+	if err != nil {
+		return nil, err
+	}
+	return diffFields(currentFields, desiredFields), nil
+}
+
+// changedStatus returns the outcome of putting an existing object with the given changes
+func changedStatus(changes []fieldChange) string {
+	if len(changes) == 0 {
+		return configureUnchanged
+	}
+	return configureUpdated
+}
+
+// diffFields compares flattened fields; fields only in current are reported as removed since a put replaces
+// the whole object
+func diffFields(current, desired map[string]string) []fieldChange {
+	fields := make(map[string]bool, len(current)+len(desired))
+	for field := range current {
+		fields[field] = true
+	}
+	for field := range desired {
+		fields[field] = true
+	}
+
+	var changes []fieldChange
+	for field := range fields {
+		currentValue, inCurrent := current[field]
+		desiredValue := desired[field]
+		if currentValue == desiredValue || (secretFields[field] && !inCurrent) {
+			continue
+		}
+		if secretFields[field] {
+			currentValue, desiredValue = redactedValue, redactedValue
+		}
+		changes = append(changes, fieldChange{Field: field, Current: currentValue, Desired: desiredValue})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// flattenFields converts an object to its JSON fields keyed by their dotted path. Values are compared as text,
+// since Elasticsearch returns repository settings as strings; lists are joined with commas.
+func flattenFields(object interface{}) (map[string]string, error) {
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode definition: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode definition: %w", err)
+	}
+
+	fields := map[string]string{}
+	flattenValue("", value, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		fields[path] = strings.Join(items, ",")
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
+
+// logChanges logs the outcome of configuring an object and the fields that changed
+func logChanges(result *configureResult, log *logger.Logger) {
+	log.Successf("%s '%s' %s", result.Object, result.Name, result.Status)
+	for _, change := range result.Changes {
+		log.Infof("  %s: %q -> %q", change.Field, change.Current, change.Desired)
+	}
+}
+
+// configureResultsTable lists the outcome of configuring every object
+func configureResultsTable(results []*configureResult) output.Table {
+	table := output.Table{
+		Headers: []string{"OBJECT", "NAME", "STATUS", "CHANGED FIELDS"},
+		Rows:    make([][]string, 0, len(results)),
+	}
+
+	for _, result := range results {
+		fields := make([]string, 0, len(result.Changes))
+		for _, change := range result.Changes {
+			fields = append(fields, change.Field)
+		}
+		table.Rows = append(table.Rows, []string{result.Object, result.Name, result.Status, strings.Join(fields, ", ")})
+	}
+
+	return table
+}
@@ -0,0 +1,55 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+const (
+	// verifySnapshotPrefix is the name prefix of the test snapshots taken to verify a repository
+	verifySnapshotPrefix = "configure-verify-"
+	// noIndices selects no data streams or indices, so the test snapshot holds no data
+	noIndices = "-*"
+)
+
+// repositoryVerifier verifies a repository and takes and deletes snapshots in it
+type repositoryVerifier interface {
+	VerifyRepository(name string) (int, error)
+	CreateSnapshot(repository, snapshotName string, indices []string, metadata map[string]string) (*elasticsearch.Snapshot, error)
+	DeleteSnapshot(repository, snapshotName string) error
+}
+
+// verifyRepository checks that all nodes can access the repository, then takes and deletes an empty snapshot in it.
+// Repository verification only writes a test file, so the snapshot cycle is what detects bucket policies that
+// forbid listing or deleting objects, which SLM snapshots and retention need.
+func verifyRepository(client repositoryVerifier, repository string, now time.Time, log *logger.Logger) error {
+	log.Infof("Verifying snapshot repository '%s'...", repository)
+	nodes, err := client.VerifyRepository(repository)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("snapshot repository %s failed verification: %w", repository, err))
+	}
+	log.Debugf("Snapshot repository '%s' is accessible from %d node(s)", repository, nodes)
+
+	name := verifySnapshotPrefix + now.UTC().Format(safetySnapshotTimeFormat)
+	log.Infof("Taking test snapshot '%s'...", name)
+	snapshot, err := client.CreateSnapshot(repository, name, []string{noIndices}, nil)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("failed to take test snapshot in repository %s: %w", repository, err))
+	}
+
+	// Delete the test snapshot even when it failed, so it does not linger in the repository
+	deleteErr := client.DeleteSnapshot(repository, name)
+	if snapshot.State != "SUCCESS" {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("test snapshot %s in repository %s finished with state %s", name, repository, snapshot.State))
+	}
+	if deleteErr != nil {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("failed to delete test snapshot %s from repository %s: %w", name, repository, deleteErr))
+	}
+
+	log.Successf("Snapshot repository '%s' verified: %d node(s) can access it, snapshots can be taken and deleted", repository, nodes)
+	return nil
+}
@@ -1,91 +1,180 @@
 package elasticsearch
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
 	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
-	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
 	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 )
 
 func configureCmd(cliCtx *config.Context) *cobra.Command {
-	return &cobra.Command{
+	var verify bool
+	cmd := &cobra.Command{
 		Use:   "configure",
 		Short: "Configure Elasticsearch snapshot repository and SLM policy",
-		Long:  `Configure Elasticsearch snapshot repository and Snapshot Lifecycle Management (SLM) policy for automated backups.`,
+		Long: `Configure Elasticsearch snapshot repository and Snapshot Lifecycle Management (SLM) policy for automated backups.
+
+The existing repository and policies are compared with the configuration and only put when they differ, so the
+command can safely run repeatedly, e.g. from CI or GitOps pipelines. Every object is reported as created, updated
+(listing the changed fields) or unchanged.
+
+Afterwards the repository is verified, and an empty test snapshot is taken and deleted, so bad credentials or
+bucket policies are detected now rather than when the first scheduled snapshot fails.
+
+With elasticsearch.backupWindow configured, every SLM schedule has to take its snapshots inside the window; SLM
+evaluates the schedules in UTC.`,
 		Run: func(_ *cobra.Command, _ []string) {
-			if err := runConfigure(cliCtx); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
+			if err := runConfigure(cliCtx, verify); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
 			}
 		},
 	}
+	cmd.Flags().BoolVar(&verify, "verify", true, "Verify the repository and take and delete a test snapshot after configuring")
+	addSkipHealthCheckFlag(cmd, cliCtx)
+	return cmd
 }
 
-func runConfigure(cliCtx *config.Context) error {
-	// Create logger
-	log := logger.New(cliCtx.Config.Quiet, cliCtx.Config.Debug)
-
-	// Create Kubernetes client
-	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.Debug)
+func runConfigure(cliCtx *config.Context, verify bool) error {
+	env, err := target.Connect(cliCtx)
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
 	}
+	return configure(env, verify)
+}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName)
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
+// configure configures the snapshot repository and the SLM policies, and verifies the repository when verify is set
+func configure(env *target.Env, verify bool) (err error) {
+	cliCtx, k8sClient, cfg, log := env.CLI, env.K8s, env.Config, env.Log
+
+	// Configuring replaces the repository and SLM policy, so it is audited
+	defer func() {
+		err = target.RecordAudit(k8sClient, cliCtx, audit.Entry{Operation: "configure"}, err, log)
+	}()
 
 	// Validate required configuration
 	if cfg.Elasticsearch.SnapshotRepository.AccessKey == "" || cfg.Elasticsearch.SnapshotRepository.SecretKey == "" {
-		return fmt.Errorf("accessKey and secretKey are required in the secret configuration")
+		return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("accessKey and secretKey are required in the secret configuration"))
+	}
+	if err := checkScheduleWindow(cfg.Elasticsearch.Policies(), cfg.Elasticsearch.BackupWindow, time.Now(), log); err != nil {
+		return err
 	}
 
-	// Setup port-forward to Elasticsearch
-	serviceName := cfg.Elasticsearch.Service.Name
-	localPort := cfg.Elasticsearch.Service.LocalPortForwardPort
-	remotePort := cfg.Elasticsearch.Service.Port
+	if err := checkElasticsearchPods(k8sClient, cliCtx, cfg, log); err != nil {
+		return err
+	}
 
-	pf, err := portforward.SetupPortForward(k8sClient, cliCtx.Config.Namespace, serviceName, localPort, remotePort, log)
+	// Setup port-forward to Elasticsearch
+	pf, err := portForwardElasticsearch(k8sClient, cliCtx, cfg, log)
 	if err != nil {
 		return err
 	}
 	defer close(pf.StopChan)
 
 	// Create Elasticsearch client
-	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", pf.LocalPort))
+	esClient, err := newESClient(k8sClient, cliCtx, cfg, pf.LocalPort, log)
 	if err != nil {
-		return fmt.Errorf("failed to create Elasticsearch client: %w", err)
+		return err
 	}
 
 	// Configure snapshot repository
-	repo := cfg.Elasticsearch.SnapshotRepository
-	log.Infof("Configuring snapshot repository '%s' (bucket: %s)...", repo.Name, repo.Bucket)
-
-	err = esClient.ConfigureSnapshotRepository(
-		repo.Name,
-		repo.Bucket,
-		repo.Endpoint,
-		repo.BasePath,
-		repo.AccessKey,
-		repo.SecretKey,
-	)
+	repoResult, err := configureRepository(esClient, cfg.Elasticsearch.SnapshotRepository, log)
 	if err != nil {
-		return fmt.Errorf("failed to configure snapshot repository: %w", err)
+		return fmt.Errorf("failed to configure snapshot repository: %w", explainRepositoryError(esClient, err))
+	}
+	logChanges(repoResult, log)
+
+	if verify {
+		if err := verifyRepository(esClient, cfg.Elasticsearch.SnapshotRepository.Name, time.Now(), log); err != nil {
+			return err
+		}
 	}
 
-	log.Successf("Snapshot repository configured successfully")
+	// Configure SLM policies
+	policyResults, err := configureSLMPolicies(esClient, cfg.Elasticsearch.Policies(), log)
+	if err != nil {
+		return err
+	}
 
-	// Configure SLM policy
-	slm := cfg.Elasticsearch.SLM
-	log.Infof("Configuring SLM policy '%s'...", slm.Name)
+	log.Println()
+	log.Successf("Configuration completed successfully")
 
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	return formatter.PrintTable(configureResultsTable(append([]*configureResult{repoResult}, policyResults...)))
+}
+
+// repositoryConnection returns how Elasticsearch connects to the S3 endpoint of a snapshot repository
+func repositoryConnection(repo config.SnapshotRepositoryConfig) elasticsearch.S3Connection {
+	return elasticsearch.S3Connection{
+		Protocol:        repo.S3Protocol(),
+		Region:          repo.S3Region(),
+		PathStyleAccess: repo.S3PathStyleAccess(),
+	}
+}
+
+// repositoryTuning returns the optional tuning settings of a snapshot repository
+func repositoryTuning(repo config.SnapshotRepositoryConfig) elasticsearch.RepositoryTuning {
+	return elasticsearch.RepositoryTuning{
+		ChunkSize:              repo.ChunkSize,
+		Compress:               repo.Compress,
+		MaxSnapshotBytesPerSec: repo.MaxSnapshotBytesPerSec,
+		MaxRestoreBytesPerSec:  repo.MaxRestoreBytesPerSec,
+	}
+}
+
+// slmPolicyConfigurer gets and puts SLM policies
+type slmPolicyConfigurer interface {
+	GetSLMPolicy(name string) (*elasticsearch.SLMPolicy, error)
+	ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int, featureStates []string) error
+}
+
+// configureSLMPolicies creates every SLM policy, or updates it when it differs from the configuration,
+// stopping at the first failure
+func configureSLMPolicies(esClient slmPolicyConfigurer, policies []config.SLMConfig, log *logger.Logger) ([]*configureResult, error) {
+	results := make([]*configureResult, 0, len(policies))
+	for _, slm := range policies {
+		result, err := configureSLMPolicy(esClient, slm, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SLM policy %s: %w", slm.Name, err)
+		}
+		logChanges(result, log)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// configureSLMPolicy creates or updates a single SLM policy
+func configureSLMPolicy(esClient slmPolicyConfigurer, slm config.SLMConfig, log *logger.Logger) (*configureResult, error) {
+	desired := elasticsearch.NewSLMPolicyDefinition(slm.Schedule, slm.SnapshotTemplateName, slm.Repository, slm.Indices,
+		slm.RetentionExpireAfter, slm.RetentionMinCount, slm.RetentionMaxCount, slm.FeatureStates)
+
+	current, err := esClient.GetSLMPolicy(slm.Name)
+	if err != nil && !errors.Is(err, elasticsearch.ErrNotFound) {
+		return nil, err
+	}
+
+	result := &configureResult{Object: "SLM policy", Name: slm.Name, Status: configureCreated}
+	if current != nil && current.Policy != nil {
+		if result.Changes, err = compareObjects(current.Policy, desired); err != nil {
+			return nil, err
+		}
+		result.Status = changedStatus(result.Changes)
+	}
+	if result.Status == configureUnchanged {
+		return result, nil
+	}
+
+	log.Infof("Configuring SLM policy '%s' (schedule: %s, retention: %s)...", slm.Name, slm.Schedule, slm.RetentionExpireAfter)
 	err = esClient.ConfigureSLMPolicy(
 		slm.Name,
 		slm.Schedule,
@@ -95,14 +184,10 @@ func runConfigure(cliCtx *config.Context) error {
 		slm.RetentionExpireAfter,
 		slm.RetentionMinCount,
 		slm.RetentionMaxCount,
+		slm.FeatureStates,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to configure SLM policy: %w", err)
+		return nil, err
 	}
-
-	log.Successf("SLM policy configured successfully")
-	log.Println()
-	log.Successf("Configuration completed successfully")
-
-	return nil
+	return result, nil
 }
@@ -0,0 +1,119 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:funlen
+func TestConfigureRepository(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	compress := true
+	repo := config.SnapshotRepositoryConfig{
+		Name: "sts-backup", Bucket: "sts-backup", Endpoint: "minio:9000", BasePath: "elasticsearch",
+		AccessKey: "key", SecretKey: "secret", Compress: &compress,
+	}
+	// Elasticsearch returns repository settings as strings and may leave out the credentials
+	existing := func(bucket string) *elasticsearch.Repository {
+		return &elasticsearch.Repository{Type: "s3", Settings: map[string]interface{}{
+			"bucket":            bucket,
+			"region":            "minio",
+			"endpoint":          "minio:9000",
+			"base_path":         "elasticsearch",
+			"protocol":          "http",
+			"path_style_access": "true",
+			"compress":          "true",
+		}}
+	}
+
+	tests := []struct {
+		name            string
+		repositories    map[string]*elasticsearch.Repository
+		expectedStatus  string
+		expectedChanges []fieldChange
+		expectPut       bool
+	}{
+		{
+			name:           "missing repository is created",
+			expectedStatus: configureCreated,
+			expectPut:      true,
+		},
+		{
+			name:           "identical repository is left unchanged",
+			repositories:   map[string]*elasticsearch.Repository{"sts-backup": existing("sts-backup")},
+			expectedStatus: configureUnchanged,
+		},
+		{
+			name:            "changed bucket is updated",
+			repositories:    map[string]*elasticsearch.Repository{"sts-backup": existing("old-bucket")},
+			expectedStatus:  configureUpdated,
+			expectedChanges: []fieldChange{{Field: "settings.bucket", Current: "old-bucket", Desired: "sts-backup"}},
+			expectPut:       true,
+		},
+		{
+			name: "changed credentials are redacted",
+			repositories: map[string]*elasticsearch.Repository{"sts-backup": func() *elasticsearch.Repository {
+				repository := existing("sts-backup")
+				repository.Settings["secret_key"] = "old-secret"
+				return repository
+			}()},
+			expectedStatus:  configureUpdated,
+			expectedChanges: []fieldChange{{Field: "settings.secret_key", Current: redactedValue, Desired: redactedValue}},
+			expectPut:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockESClientForConfigure{repositories: tt.repositories}
+
+			result, err := configureRepository(mockClient, repo, log)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, result.Status)
+			assert.Equal(t, tt.expectedChanges, result.Changes)
+			assert.Equal(t, tt.expectPut, mockClient.repoConfigured)
+		})
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	current := map[string]string{"schedule": "0 0 1 * * ?", "config.indices": "sts*", "config.partial": "true"}
+	desired := map[string]string{"schedule": "0 0 3 * * ?", "config.indices": "sts*", "config.feature_states": "none"}
+
+	changes := diffFields(current, desired)
+
+	assert.Equal(t, []fieldChange{
+		{Field: "config.feature_states", Current: "", Desired: "none"},
+		{Field: "config.partial", Current: "true", Desired: ""},
+		{Field: "schedule", Current: "0 0 1 * * ?", Desired: "0 0 3 * * ?"},
+	}, changes)
+}
+
+func TestFlattenFields(t *testing.T) {
+	definition := elasticsearch.NewSLMPolicyDefinition("0 0 3 * * ?", "<sts-backup-{now/d}>", "sts-backup", "sts*", "30d", 5, 30, []string{"a", "b"})
+
+	fields, err := flattenFields(definition)
+
+	require.NoError(t, err)
+	assert.Equal(t, "0 0 3 * * ?", fields["schedule"])
+	assert.Equal(t, "a,b", fields["config.feature_states"])
+	assert.Equal(t, "false", fields["config.include_global_state"])
+	assert.Equal(t, "5", fields["retention.min_count"])
+}
+
+func TestConfigureResultsTable(t *testing.T) {
+	table := configureResultsTable([]*configureResult{
+		{Object: "repository", Name: "sts-backup", Status: configureUnchanged},
+		{Object: "SLM policy", Name: "daily", Status: configureUpdated, Changes: []fieldChange{{Field: "schedule"}, {Field: "retention.max_count"}}},
+	})
+
+	assert.Equal(t, []string{"OBJECT", "NAME", "STATUS", "CHANGED FIELDS"}, table.Headers)
+	assert.Equal(t, []string{"repository", "sts-backup", "unchanged", ""}, table.Rows[0])
+	assert.Equal(t, []string{"SLM policy", "daily", "updated", "schedule, retention.max_count"}, table.Rows[1])
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
 	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
@@ -22,9 +23,26 @@ type mockESClientForConfigure struct {
 	slmConfigured    bool
 	lastRepoConfig   map[string]string
 	lastSLMConfig    map[string]interface{}
+	slmPolicyNames   []string
+	repositories     map[string]*elasticsearch.Repository
+	policies         map[string]*elasticsearch.SLMPolicy
 }
 
-func (m *mockESClientForConfigure) ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string) error {
+func (m *mockESClientForConfigure) GetRepository(name string) (*elasticsearch.Repository, error) {
+	if repository, ok := m.repositories[name]; ok {
+		return repository, nil
+	}
+	return nil, fmt.Errorf("snapshot repository %s %w", name, elasticsearch.ErrNotFound)
+}
+
+func (m *mockESClientForConfigure) GetSLMPolicy(name string) (*elasticsearch.SLMPolicy, error) {
+	if policy, ok := m.policies[name]; ok {
+		return policy, nil
+	}
+	return nil, fmt.Errorf("SLM policy %s %w", name, elasticsearch.ErrNotFound)
+}
+
+func (m *mockESClientForConfigure) ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string, _ elasticsearch.S3Connection, _ elasticsearch.RepositoryTuning) error {
 	if m.configureRepoErr != nil {
 		return m.configureRepoErr
 	}
@@ -40,20 +58,22 @@ func (m *mockESClientForConfigure) ConfigureSnapshotRepository(name, bucket, end
 	return nil
 }
 
-func (m *mockESClientForConfigure) ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int) error {
+func (m *mockESClientForConfigure) ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int, featureStates []string) error {
 	if m.configureSLMErr != nil {
 		return m.configureSLMErr
 	}
 	m.slmConfigured = true
+	m.slmPolicyNames = append(m.slmPolicyNames, name)
 	m.lastSLMConfig = map[string]interface{}{
-		"name":         name,
-		"schedule":     schedule,
-		"snapshotName": snapshotName,
-		"repository":   repository,
-		"indices":      indices,
-		"expireAfter":  expireAfter,
-		"minCount":     minCount,
-		"maxCount":     maxCount,
+		"name":          name,
+		"schedule":      schedule,
+		"snapshotName":  snapshotName,
+		"repository":    repository,
+		"indices":       indices,
+		"expireAfter":   expireAfter,
+		"minCount":      minCount,
+		"maxCount":      maxCount,
+		"featureStates": featureStates,
 	}
 	return nil
 }
@@ -303,6 +323,8 @@ func TestMockESClientForConfigure(t *testing.T) {
 				"snapshots",
 				"access-key",
 				"secret-key",
+				elasticsearch.S3Connection{},
+				elasticsearch.RepositoryTuning{},
 			)
 
 			if tt.expectRepoOK {
@@ -325,6 +347,7 @@ func TestMockESClientForConfigure(t *testing.T) {
 				"30d",
 				5,
 				50,
+				nil,
 			)
 
 			if tt.expectSLMOK {
@@ -392,3 +415,83 @@ func TestConfigureValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigureSLMPolicies(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	policies := []config.SLMConfig{
+		{Name: "daily", Schedule: "0 0 3 * * ?", RetentionExpireAfter: "30d", RetentionMinCount: 5, RetentionMaxCount: 30},
+		{Name: "hourly", Schedule: "0 0 * * * ?", RetentionExpireAfter: "1d", RetentionMinCount: 1, RetentionMaxCount: 24, FeatureStates: []string{"none"}},
+	}
+
+	t.Run("configures every policy", func(t *testing.T) {
+		mockClient := &mockESClientForConfigure{}
+
+		results, err := configureSLMPolicies(mockClient, policies, log)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"daily", "hourly"}, mockClient.slmPolicyNames)
+		assert.Equal(t, configureCreated, results[0].Status)
+		assert.Equal(t, "1d", mockClient.lastSLMConfig["expireAfter"])
+		assert.Equal(t, []string{"none"}, mockClient.lastSLMConfig["featureStates"])
+	})
+
+	t.Run("names the failed policy", func(t *testing.T) {
+		mockClient := &mockESClientForConfigure{configureSLMErr: fmt.Errorf("invalid schedule")}
+
+		_, err := configureSLMPolicies(mockClient, policies, log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "SLM policy daily")
+	})
+}
+
+func TestConfigureSLMPolicies_Drift(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	slm := config.SLMConfig{
+		Name: "daily", Schedule: "0 0 3 * * ?", SnapshotTemplateName: "<sts-backup-{now/d}>", Repository: "sts-backup",
+		Indices: "sts*", RetentionExpireAfter: "30d", RetentionMinCount: 5, RetentionMaxCount: 30,
+	}
+	existing := func(schedule string) *elasticsearch.SLMPolicy {
+		definition := elasticsearch.NewSLMPolicyDefinition(schedule, slm.SnapshotTemplateName, slm.Repository, slm.Indices,
+			slm.RetentionExpireAfter, slm.RetentionMinCount, slm.RetentionMaxCount, nil)
+		return &elasticsearch.SLMPolicy{Name: slm.Name, Policy: &definition}
+	}
+
+	tests := []struct {
+		name            string
+		policies        map[string]*elasticsearch.SLMPolicy
+		expectedStatus  string
+		expectedChanges []fieldChange
+		expectPut       bool
+	}{
+		{
+			name:           "missing policy is created",
+			expectedStatus: configureCreated,
+			expectPut:      true,
+		},
+		{
+			name:           "identical policy is left unchanged",
+			policies:       map[string]*elasticsearch.SLMPolicy{"daily": existing(slm.Schedule)},
+			expectedStatus: configureUnchanged,
+		},
+		{
+			name:            "changed schedule is updated",
+			policies:        map[string]*elasticsearch.SLMPolicy{"daily": existing("0 0 1 * * ?")},
+			expectedStatus:  configureUpdated,
+			expectedChanges: []fieldChange{{Field: "schedule", Current: "0 0 1 * * ?", Desired: "0 0 3 * * ?"}},
+			expectPut:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &mockESClientForConfigure{policies: tt.policies}
+
+			results, err := configureSLMPolicies(mockClient, []config.SLMConfig{slm}, log)
+
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, tt.expectedStatus, results[0].Status)
+			assert.Equal(t, tt.expectedChanges, results[0].Changes)
+			assert.Equal(t, tt.expectPut, mockClient.slmConfigured)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRepositoryVerifier struct {
+	verifyErr error
+	state     string
+	createErr error
+	deleteErr error
+	created   []string
+	indices   []string
+	deleted   []string
+}
+
+func (m *mockRepositoryVerifier) VerifyRepository(_ string) (int, error) {
+	if m.verifyErr != nil {
+		return 0, m.verifyErr
+	}
+	return 3, nil
+}
+
+func (m *mockRepositoryVerifier) CreateSnapshot(_, snapshotName string, indices []string, _ map[string]string) (*elasticsearch.Snapshot, error) {
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	m.created = append(m.created, snapshotName)
+	m.indices = indices
+	return &elasticsearch.Snapshot{Snapshot: snapshotName, State: m.state}, nil
+}
+
+func (m *mockRepositoryVerifier) DeleteSnapshot(_, snapshotName string) error {
+	m.deleted = append(m.deleted, snapshotName)
+	return m.deleteErr
+}
+
+func TestVerifyRepository(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		client        *mockRepositoryVerifier
+		errorContains string
+		expectDeleted bool
+	}{
+		{
+			name:          "repository verified",
+			client:        &mockRepositoryVerifier{state: "SUCCESS"},
+			expectDeleted: true,
+		},
+		{
+			name:          "verification fails",
+			client:        &mockRepositoryVerifier{verifyErr: errors.New("access denied")},
+			errorContains: "failed verification: access denied",
+		},
+		{
+			name:          "test snapshot cannot be taken",
+			client:        &mockRepositoryVerifier{createErr: errors.New("bucket policy")},
+			errorContains: "failed to take test snapshot",
+		},
+		{
+			name:          "failed test snapshot is still deleted",
+			client:        &mockRepositoryVerifier{state: "FAILED"},
+			errorContains: "finished with state FAILED",
+			expectDeleted: true,
+		},
+		{
+			name:          "test snapshot cannot be deleted",
+			client:        &mockRepositoryVerifier{state: "SUCCESS", deleteErr: errors.New("forbidden")},
+			errorContains: "failed to delete test snapshot",
+			expectDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyRepository(tt.client, "sts-backup", now, log)
+
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, []string{"configure-verify-20250304-050607"}, tt.client.created)
+				assert.Equal(t, []string{"-*"}, tt.client.indices)
+			}
+			if tt.expectDeleted {
+				assert.Equal(t, []string{"configure-verify-20250304-050607"}, tt.client.deleted)
+			} else {
+				assert.Empty(t, tt.client.deleted)
+			}
+		})
+	}
+}
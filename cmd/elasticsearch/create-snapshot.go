@@ -0,0 +1,298 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// Metadata keys of the snapshots taken by the CLI
+const (
+	metadataTakenBy = "taken_by"
+	metadataTicket  = "ticket"
+	metadataReason  = "reason"
+)
+
+// manualSnapshotPrefix is the default name prefix of the snapshots taken with create-snapshot
+const manualSnapshotPrefix = "manual-"
+
+// slmNameTemplate is the --name-template value that selects the snapshot name template of the SLM policy
+const slmNameTemplate = "slm"
+
+// indexLister resolves index patterns to the indices they match
+type indexLister interface {
+	ListIndices(pattern string) ([]string, error)
+}
+
+type createSnapshotOptions struct {
+	SnapshotName string
+	// NameTemplate is a date math name, e.g. <sts-backup-{now{yyyyMMdd-HHmm}}>, or slm for the template of the policy
+	NameTemplate string
+	Indices      string
+	// Exclude lists indices or patterns left out of the snapshot
+	Exclude []string
+	TakenBy string
+	Ticket  string
+	Reason  string
+	// Labels are key=value pairs added to the metadata
+	Labels []string
+	// DryRun shows the snapshot that would be taken without taking it
+	DryRun bool
+}
+
+// snapshotPlan is the snapshot create-snapshot --dry-run would take
+type snapshotPlan struct {
+	Name         string            `json:"name"`
+	NameTemplate string            `json:"nameTemplate,omitempty"`
+	Repository   string            `json:"repository"`
+	Indices      []string          `json:"indices"`
+	Metadata     map[string]string `json:"metadata"`
+}
+
+func createSnapshotCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &createSnapshotOptions{}
+	cmd := &cobra.Command{
+		Use:   "create-snapshot",
+		Short: "Take a snapshot now, with metadata describing why",
+		Long: `Take a snapshot of the indices of elasticsearch.slm.indices in the SLM repository now and wait until it has
+finished, e.g. before an upgrade. Use --indices and --exclude to snapshot only a subset, e.g. only the topology
+indices before a risky migration; a selection that matches no index is refused. Who took the snapshot, the ticket
+and the reason are attached to it as metadata, together with any --label, and shown by list-snapshots and
+get-snapshot, so manual snapshots can be told apart from the snapshots of the SLM policies (whose metadata holds
+their policy). The global cluster state is not included.
+
+--name-template names the snapshot with date math, like the SLM policy does, e.g. '<sts-backup-{now{yyyyMMdd-HHmm}}>';
+--name-template slm uses elasticsearch.slm.snapshotTemplateName, so manual snapshots are named like the scheduled
+ones. The template is resolved by the CLI and the name it resolves to is logged; --dry-run shows the name, indices
+and metadata of the snapshot without taking it.
+
+Outside of the configured elasticsearch.backupWindow the snapshot is refused with exit code 5, unless --force is
+given. A snapshot that finishes with failed shards fails the command with exit code 5.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runCreateSnapshot(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&opts.SnapshotName, "snapshot-name", "s", "", "Name of the snapshot (default: manual-<UTC time>)")
+	cmd.Flags().StringVar(&opts.NameTemplate, "name-template", "", "Date math name of the snapshot, e.g. '<sts-backup-{now{yyyyMMdd-HHmm}}>', or slm for the template of the SLM policy")
+	cmd.Flags().StringVar(&opts.Indices, "indices", "", "Indices or patterns to snapshot, comma-separated (default: elasticsearch.slm.indices)")
+	cmd.Flags().StringSliceVar(&opts.Exclude, "exclude", nil, "Indices or patterns to leave out of the snapshot, e.g. sts_k8s_logs*")
+	cmd.Flags().StringVar(&opts.TakenBy, "taken-by", "", "Who takes the snapshot (default: the Kubernetes user)")
+	cmd.Flags().StringVar(&opts.Ticket, "ticket", "", "Change or incident ticket the snapshot is taken for")
+	cmd.Flags().StringVar(&opts.Reason, "reason", "", "Why the snapshot is taken, e.g. 'before upgrade to 2.3'")
+	cmd.Flags().StringArrayVar(&opts.Labels, "label", nil, "Metadata as key=value, repeatable")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show the name, indices and metadata of the snapshot without taking it")
+	addForceFlag(cmd, cliCtx)
+	cmd.MarkFlagsMutuallyExclusive("snapshot-name", "name-template")
+	return cmd
+}
+
+func runCreateSnapshot(cliCtx *config.Context, opts *createSnapshotOptions) (err error) {
+	labels, err := parseLabels(opts.Labels)
+	if err != nil {
+		return err
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	// Notify configured targets about the outcome of the snapshot (not for dry runs)
+	var name string
+	if !opts.DryRun {
+		if err := checkBackupWindow(env.Config.Elasticsearch.BackupWindow, "create-snapshot", cliCtx, time.Now(), env.Log); err != nil {
+			return err
+		}
+		startedAt := time.Now()
+		defer func() {
+			sendNotification(env.Config, cliCtx, "create-snapshot", startedAt, err, map[string]string{"snapshot": name}, env.Log)
+		}()
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if opts.TakenBy == "" {
+		opts.TakenBy = env.K8s.CurrentUser()
+	}
+	template := opts.NameTemplate
+	if template == slmNameTemplate {
+		template = env.Config.Elasticsearch.SLM.SnapshotTemplateName
+	}
+	name, err = manualSnapshotName(opts.SnapshotName, template, time.Now(), env.Log)
+	if err != nil {
+		return err
+	}
+	indices := opts.Indices
+	if indices == "" {
+		indices = env.Config.Elasticsearch.SLM.Indices
+	}
+
+	patterns, err := selectSnapshotIndices(esClient, indices, opts.Exclude, env.Log)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if opts.DryRun {
+		plan := snapshotPlan{Name: name, NameTemplate: template, Repository: env.Config.Elasticsearch.SLM.Repository, Indices: patterns, Metadata: snapshotMetadata(opts, labels)}
+		return formatter.PrintDetail(plan, snapshotPlanTable(plan))
+	}
+
+	snapshot, err := createSnapshot(esClient, env.Config.Elasticsearch.SLM.Repository, name, patterns, snapshotMetadata(opts, labels), env.Log)
+	if err != nil {
+		return err
+	}
+	return formatter.PrintDetail(snapshot, snapshotSummaryTable(snapshot))
+}
+
+// manualSnapshotName returns the name of a snapshot taken at now: the given name, the name template resolved like
+// Elasticsearch resolves it, or manual-<UTC time>. An invalid template is an exitcode.Usage error.
+func manualSnapshotName(name, template string, now time.Time, log *logger.Logger) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	if template == "" {
+		return manualSnapshotPrefix + now.UTC().Format(safetySnapshotTimeFormat), nil
+	}
+	resolved, err := elasticsearch.ResolveDateMathName(template, now)
+	if err != nil {
+		return "", exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --name-template: %w", err))
+	}
+	log.Infof("Snapshot name template '%s' resolves to '%s'", template, resolved)
+	return resolved, nil
+}
+
+// snapshotPlanTable shows the snapshot create-snapshot --dry-run would take
+func snapshotPlanTable(plan snapshotPlan) output.Table {
+	return output.Table{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"Name", plan.Name},
+			{"Name template", orNone(plan.NameTemplate)},
+			{"Repository", plan.Repository},
+			{"Indices", strings.Join(plan.Indices, ",")},
+			{"Metadata", orNone(strings.Join(labelsOf(plan.Metadata), ", "))},
+		},
+	}
+}
+
+// labelsOf returns metadata as key=value pairs, sorted by key
+func labelsOf(metadata map[string]string) []string {
+	labels := make([]string, 0, len(metadata))
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		labels = append(labels, key+"="+metadata[key])
+	}
+	return labels
+}
+
+// createSnapshot takes a snapshot and waits for it; a snapshot that did not succeed is an exitcode.ValidationFailed error
+func createSnapshot(client snapshotCreator, repository, name string, indices []string, metadata map[string]string, log *logger.Logger) (*elasticsearch.Snapshot, error) {
+	log.Infof("Taking snapshot '%s' of '%s' in repository '%s' - this may take several minutes...", name, strings.Join(indices, ","), repository)
+	snapshot, err := client.CreateSnapshot(repository, name, indices, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take snapshot: %w", err)
+	}
+	if snapshot.State != "SUCCESS" {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("snapshot %s finished with state %s (%d failed shard(s))", name, snapshot.State, snapshot.Shards.Failed))
+	}
+	log.Successf("Snapshot '%s' taken", name)
+	return snapshot, nil
+}
+
+// selectSnapshotIndices returns the patterns to snapshot: the comma-separated indices with the excluded ones as
+// negated patterns (-name), which Elasticsearch resolves when taking the snapshot, so data streams are kept whole.
+// A selection that matches no index is an exitcode.Usage error rather than an empty snapshot.
+func selectSnapshotIndices(client indexLister, indices string, exclude []string, log *logger.Logger) ([]string, error) {
+	patterns := strings.Split(indices, ",")
+	for _, pattern := range exclude {
+		patterns = append(patterns, "-"+strings.TrimPrefix(pattern, "-"))
+	}
+
+	matching, err := client.ListIndices(strings.Join(patterns, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list indices matching '%s': %w", strings.Join(patterns, ","), err)
+	}
+	if len(matching) == 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("no indices match '%s'", strings.Join(patterns, ",")))
+	}
+	log.Infof("%d index(es) match '%s'", len(matching), strings.Join(patterns, ","))
+	return patterns, nil
+}
+
+// snapshotMetadata returns the metadata of a snapshot taken with create-snapshot: the labels, with the values of
+// --taken-by, --ticket and --reason taking precedence
+func snapshotMetadata(opts *createSnapshotOptions, labels map[string]string) map[string]string {
+	metadata := maps.Clone(labels)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	for key, value := range map[string]string{metadataTakenBy: opts.TakenBy, metadataTicket: opts.Ticket, metadataReason: opts.Reason} {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// parseLabels parses key=value pairs; a key may only be given once
+func parseLabels(values []string) (map[string]string, error) {
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		key, labelValue, found := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--label must be key=value, got '%s'", value))
+		}
+		if _, ok := labels[key]; ok {
+			return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--label %s is given more than once", key))
+		}
+		labels[key] = labelValue
+	}
+	return labels, nil
+}
+
+// metadataValue returns a metadata value of a snapshot as text: strings as they are, other values as JSON
+func metadataValue(snapshot elasticsearch.Snapshot, key string) (string, bool) {
+	value, ok := snapshot.Metadata[key]
+	if !ok {
+		return "", false
+	}
+	if s, isString := value.(string); isString {
+		return s, true
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value), true
+	}
+	return string(data), true
+}
+
+// metadataLabels returns the metadata of a snapshot as key=value pairs, sorted by key
+func metadataLabels(snapshot elasticsearch.Snapshot) []string {
+	labels := make([]string, 0, len(snapshot.Metadata))
+	for _, key := range slices.Sorted(maps.Keys(snapshot.Metadata)) {
+		value, _ := metadataValue(snapshot, key)
+		labels = append(labels, key+"="+value)
+	}
+	return labels
+}
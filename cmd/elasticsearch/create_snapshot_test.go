@@ -0,0 +1,146 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSnapshot(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	metadata := map[string]string{metadataTakenBy: "alice", metadataReason: "before upgrade"}
+
+	t.Run("complete snapshot", func(t *testing.T) {
+		client := &mockSnapshotCreator{state: "SUCCESS"}
+
+		snapshot, err := createSnapshot(client, "sts-backup", "manual-1", []string{"sts*"}, metadata, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "manual-1", snapshot.Snapshot)
+		assert.Equal(t, "sts-backup", client.repository)
+		assert.Equal(t, metadata, client.metadata)
+	})
+
+	t.Run("partial snapshot fails validation", func(t *testing.T) {
+		_, err := createSnapshot(&mockSnapshotCreator{state: "PARTIAL"}, "sts-backup", "manual-1", []string{"sts*"}, metadata, log)
+
+		assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+	})
+
+	t.Run("snapshot fails", func(t *testing.T) {
+		_, err := createSnapshot(&mockSnapshotCreator{err: errors.New("repository is read-only")}, "sts-backup", "manual-1", []string{"sts*"}, metadata, log)
+
+		assert.ErrorContains(t, err, "repository is read-only")
+	})
+}
+
+func TestManualSnapshotName(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	now := time.Date(2025, 1, 15, 3, 4, 5, 0, time.UTC)
+
+	t.Run("the template is resolved like SLM does", func(t *testing.T) {
+		name, err := manualSnapshotName("", "<sts-backup-{now{yyyyMMdd-HHmm}}>", now, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "sts-backup-20250115-0304", name)
+	})
+
+	t.Run("a given name is used as it is", func(t *testing.T) {
+		name, err := manualSnapshotName("before-upgrade", "", now, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "before-upgrade", name)
+	})
+
+	t.Run("without name or template the name is manual-<UTC time>", func(t *testing.T) {
+		name, err := manualSnapshotName("", "", now, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "manual-20250115-030405", name)
+	})
+
+	t.Run("an invalid template is a usage error", func(t *testing.T) {
+		_, err := manualSnapshotName("", "<sts-backup-{now{yyyyMMdd}>", now, log)
+
+		assert.ErrorContains(t, err, "invalid --name-template")
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	})
+}
+
+func TestSnapshotPlanTable(t *testing.T) {
+	table := snapshotPlanTable(snapshotPlan{
+		Name:       "sts-backup-20250115-0304",
+		Repository: "sts-backup",
+		Indices:    []string{"sts*", "-sts_k8s_logs*"},
+		Metadata:   map[string]string{metadataTakenBy: "alice", metadataReason: "before upgrade"},
+	})
+
+	assert.Equal(t, [][]string{
+		{"Name", "sts-backup-20250115-0304"},
+		{"Name template", "none"},
+		{"Repository", "sts-backup"},
+		{"Indices", "sts*,-sts_k8s_logs*"},
+		{"Metadata", "reason=before upgrade, taken_by=alice"},
+	}, table.Rows)
+}
+
+func TestSnapshotMetadata(t *testing.T) {
+	labels, err := parseLabels([]string{"team=platform", "reason=overridden", "note=a=b"})
+	require.NoError(t, err)
+
+	metadata := snapshotMetadata(&createSnapshotOptions{TakenBy: "alice", Reason: "before upgrade"}, labels)
+
+	assert.Equal(t, map[string]string{"team": "platform", "note": "a=b", metadataTakenBy: "alice", metadataReason: "before upgrade"}, metadata)
+}
+
+func TestParseLabels_Errors(t *testing.T) {
+	for _, values := range [][]string{{"reason"}, {"=value"}, {"a=1", "a=2"}} {
+		_, err := parseLabels(values)
+
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err), "%v", values)
+	}
+}
+
+func TestMetadataLabels(t *testing.T) {
+	snapshot := elasticsearch.Snapshot{Metadata: map[string]interface{}{"taken_by": "alice", "policy": "auto-sts-backup", "shards": float64(3)}}
+
+	assert.Equal(t, []string{"policy=auto-sts-backup", "shards=3", "taken_by=alice"}, metadataLabels(snapshot))
+}
+
+// indicesOf lists the indices matching a pattern like Elasticsearch does
+type indicesOf []string
+
+func (i indicesOf) ListIndices(pattern string) ([]string, error) {
+	var result []string
+	for _, index := range i {
+		if matchesIndexPattern(index, pattern) {
+			result = append(result, index)
+		}
+	}
+	return result, nil
+}
+
+func TestSelectSnapshotIndices(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	cluster := indicesOf{"sts_topology", "sts_multi_metrics-000001", ".ds-sts_k8s_logs-000001"}
+
+	t.Run("excluded patterns are negated", func(t *testing.T) {
+		patterns, err := selectSnapshotIndices(cluster, "sts*,.ds-sts*", []string{"sts_multi_metrics*", "-.ds-sts_k8s_logs*"}, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sts*", ".ds-sts*", "-sts_multi_metrics*", "-.ds-sts_k8s_logs*"}, patterns)
+	})
+
+	t.Run("empty selection is refused", func(t *testing.T) {
+		_, err := selectSnapshotIndices(cluster, "sts_topology*", []string{"sts_topology"}, log)
+
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+		assert.ErrorContains(t, err, "no indices match 'sts_topology*,-sts_topology'")
+	})
+}
@@ -1,8 +1,17 @@
 package elasticsearch
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/notify"
 )
 
 func Cmd(cliCtx *config.Context) *cobra.Command {
@@ -10,11 +19,88 @@ func Cmd(cliCtx *config.Context) *cobra.Command {
 		Use:   "elasticsearch",
 		Short: "Elasticsearch backup and restore operations",
 	}
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.ElasticsearchPod, "pod", "", "Elasticsearch pod to port-forward to (default: the healthiest pod of the Elasticsearch service)")
 
 	cmd.AddCommand(listSnapshotsCmd(cliCtx))
+	cmd.AddCommand(getSnapshotCmd(cliCtx))
+	cmd.AddCommand(createSnapshotCmd(cliCtx))
 	cmd.AddCommand(listIndicesCmd(cliCtx))
+	cmd.AddCommand(recoveryStatusCmd(cliCtx))
+	cmd.AddCommand(explainAllocationCmd(cliCtx))
+	cmd.AddCommand(tasksCmd(cliCtx))
+	cmd.AddCommand(rolloverCmd(cliCtx))
+	cmd.AddCommand(aliasesCmd(cliCtx))
+	cmd.AddCommand(migratePrefixCmd(cliCtx))
 	cmd.AddCommand(restoreCmd(cliCtx))
+	cmd.AddCommand(rollbackRestoreCmd(cliCtx))
+	cmd.AddCommand(scaleUpCmd(cliCtx))
+	cmd.AddCommand(restoreHistoryCmd(cliCtx))
 	cmd.AddCommand(configureCmd(cliCtx))
+	cmd.AddCommand(rotateCredentialsCmd(cliCtx))
+	cmd.AddCommand(enforceRetentionCmd(cliCtx))
+	cmd.AddCommand(runRetentionCmd(cliCtx))
+	cmd.AddCommand(snapshotUsageCmd(cliCtx))
+	cmd.AddCommand(checkFreshnessCmd(cliCtx))
+	cmd.AddCommand(verifyRestoreCmd(cliCtx))
+	cmd.AddCommand(exportPipelinesCmd(cliCtx))
+	cmd.AddCommand(importPipelinesCmd(cliCtx))
+	cmd.AddCommand(exportClusterSettingsCmd(cliCtx))
+	cmd.AddCommand(importClusterSettingsCmd(cliCtx))
+	cmd.AddCommand(exportILMCmd(cliCtx))
+	cmd.AddCommand(importILMCmd(cliCtx))
 
 	return cmd
 }
+
+// portForwardElasticsearch sets up the port-forward to Elasticsearch: to the pod given with --pod, or else to the
+// healthiest pod of the service snapshot operations go through (see config.ServiceConfig.SnapshotServiceName)
+func portForwardElasticsearch(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) (*portforward.Conn, error) {
+	service := cfg.Elasticsearch.Service
+	if pod := cliCtx.Config.ElasticsearchPod; pod != "" {
+		return portforward.SetupPodPortForward(k8sClient, cliCtx.Config.Namespace, pod, service.LocalPortForwardPort, service.Port, log)
+	}
+	return portforward.SetupPortForward(k8sClient, cliCtx.Config.Namespace, service.SnapshotServiceName(), service.LocalPortForwardPort, service.Port, log)
+}
+
+// newESClient creates an Elasticsearch client for a port-forwarded connection.
+// Requests are limited to --max-requests-per-second and authenticated as configured in elasticsearch.auth, and at
+// trace level every HTTP request and response is dumped to the log.
+func newESClient(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, localPort int, log *logger.Logger) (*elasticsearch.Client, error) {
+	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", localPort), target.ElasticsearchOptions(k8sClient, cliCtx, cfg, log)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Elasticsearch client: %w", err)
+	}
+	return esClient, nil
+}
+
+// recordEvent records a Kubernetes Event against the backup ConfigMap, tagged with the run ID.
+// Events are informational, so failures to record them are only logged.
+func recordEvent(k8sClient *k8s.Client, cliCtx *config.Context, eventType, reason, message string, log *logger.Logger) {
+	err := k8sClient.RecordEvent(cliCtx.Config.Namespace, k8s.Event{
+		ObjectKind: "ConfigMap",
+		ObjectName: cliCtx.Config.ConfigMapName,
+		Type:       eventType,
+		Reason:     reason,
+		Message:    message,
+		RunID:      cliCtx.RunID,
+	})
+	if err != nil {
+		log.Debugf("Failed to record %s event: %v", reason, err)
+	}
+}
+
+// sendNotification notifies the configured webhook/Slack targets about the outcome of an operation.
+// Delivery failures are logged but never change the outcome of the operation itself.
+func sendNotification(cfg *config.Config, cliCtx *config.Context, operation string, startedAt time.Time, opErr error, details map[string]string, log *logger.Logger) {
+	notifier := notify.New(cfg.Notifications)
+	if !notifier.Enabled() {
+		return
+	}
+
+	payload := notify.NewPayload(cliCtx.RunID, operation, cliCtx.Config.Namespace, startedAt, opErr, details)
+	if err := notifier.Send(payload); err != nil {
+		log.Warningf("Failed to send %s notification: %v", operation, err)
+		return
+	}
+	log.Debugf("Sent %s notification (status: %s)", operation, payload.Status)
+}
@@ -0,0 +1,289 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// esDurationPattern matches Elasticsearch time units as used by SLM retention (e.g. 30d, 12h)
+var esDurationPattern = regexp.MustCompile(`^(\d+)(d|h|m|s|ms)$`)
+
+// esDurationUnits maps Elasticsearch time units to durations
+var esDurationUnits = map[string]time.Duration{
+	"d":  day,
+	"h":  time.Hour,
+	"m":  time.Minute,
+	"s":  time.Second,
+	"ms": time.Millisecond,
+}
+
+// metadataPolicy is the metadata key SLM records the policy that took a snapshot under
+const metadataPolicy = "policy"
+
+// retentionPolicy mirrors the SLM retention settings: snapshots of the SLM policy Policy older than ExpireAfter or
+// beyond MaxCount are deleted, but the MinCount most recent successful snapshots are always kept
+type retentionPolicy struct {
+	Policy      string
+	ExpireAfter time.Duration
+	MinCount    int
+	MaxCount    int
+}
+
+// snapshotDeleter deletes snapshots from a repository
+type snapshotDeleter interface {
+	DeleteSnapshot(repository, snapshotName string) error
+}
+
+// expiredSnapshot is a snapshot selected for deletion
+type expiredSnapshot struct {
+	Snapshot elasticsearch.Snapshot
+	Reason   string
+}
+
+func enforceRetentionCmd(cliCtx *config.Context) *cobra.Command {
+	var dryRun bool
+	var safetySnapshotMaxAge string
+	cmd := &cobra.Command{
+		Use:   "enforce-retention",
+		Short: "Delete snapshots beyond the configured retention",
+		Long: `Delete snapshots from the SLM repository that are older than the configured retention (slm.retentionExpireAfter)
+or exceed the maximum count (slm.retentionMaxCount), always keeping the most recent slm.retentionMinCount successful
+snapshots. Like SLM retention only the snapshots of the SLM policy (slm.name) are considered: manual snapshots taken
+with create-snapshot and the safety snapshots taken before a restore are never deleted, unless
+--safety-snapshot-max-age is given: safety snapshots older than that are deleted as well.
+
+This is a fallback for clusters where SLM retention is disabled or not running. Snapshots that are still
+in progress are never deleted. Use --dry-run to show what would be removed.
+
+Outside of the configured elasticsearch.backupWindow no snapshots are deleted, unless --force is given.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runEnforceRetention(cliCtx, dryRun, safetySnapshotMaxAge); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the snapshots that would be deleted without deleting them")
+	cmd.Flags().StringVar(&safetySnapshotMaxAge, "safety-snapshot-max-age", "", "Also delete pre-restore- safety snapshots older than this, e.g. 30d (default: keep them)")
+	addConfirmNamespaceFlag(cmd, cliCtx)
+	addForceFlag(cmd, cliCtx)
+	return cmd
+}
+
+func runEnforceRetention(cliCtx *config.Context, dryRun bool, safetySnapshotMaxAge string) (err error) {
+	var safetyMaxAge time.Duration
+	if safetySnapshotMaxAge != "" {
+		if safetyMaxAge, err = parseESDuration(safetySnapshotMaxAge); err != nil {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --safety-snapshot-max-age: %w", err))
+		}
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		if err := checkBackupWindow(env.Config.Elasticsearch.BackupWindow, "enforce-retention", cliCtx, time.Now(), env.Log); err != nil {
+			return err
+		}
+	}
+
+	expireAfter, err := parseESDuration(env.Config.Elasticsearch.SLM.RetentionExpireAfter)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("invalid slm.retentionExpireAfter: %w", err))
+	}
+	policy := retentionPolicy{
+		Policy:      env.Config.Elasticsearch.SLM.Name,
+		ExpireAfter: expireAfter,
+		MinCount:    env.Config.Elasticsearch.SLM.RetentionMinCount,
+		MaxCount:    env.Config.Elasticsearch.SLM.RetentionMaxCount,
+	}
+
+	// Record deleted snapshots in the audit log and notify configured targets (not for dry runs)
+	var deleted []string
+	if !dryRun {
+		defer func() {
+			err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "enforce-retention", SnapshotsDeleted: deleted}, err, env.Log)
+		}()
+		startedAt := time.Now()
+		defer func() {
+			sendNotification(env.Config, cliCtx, "enforce-retention", startedAt, err, map[string]string{"deleted": strconv.Itoa(len(deleted))}, env.Log)
+		}()
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repository := env.Config.Elasticsearch.SLM.Repository
+	env.Log.Infof("Fetching snapshots from repository '%s'...", repository)
+	snapshots, err := esClient.ListSnapshots(repository)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	now := time.Now()
+	expired := selectExpiredSnapshots(snapshots, policy, now)
+	if safetyMaxAge > 0 {
+		expired = append(expired, selectExpiredSafetySnapshots(snapshots, safetyMaxAge, now)...)
+	}
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+
+	if len(expired) == 0 {
+		formatter.PrintMessage(fmt.Sprintf("No snapshots exceed the retention (expire after %s, min %d, max %d)",
+			env.Config.Elasticsearch.SLM.RetentionExpireAfter, policy.MinCount, policy.MaxCount))
+		return nil
+	}
+
+	if err := formatter.PrintTable(expiredTable(expired, now)); err != nil {
+		return err
+	}
+	if dryRun {
+		env.Log.Infof("Dry run: %d snapshot(s) would be deleted", len(expired))
+		return nil
+	}
+
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := confirmClusterIdentity(env.K8s, cliCtx, prompter, env.Log); err != nil {
+		return err
+	}
+	deleted, err = deleteSnapshots(esClient, repository, expired, prompter, env.Log)
+	return err
+}
+
+// deleteSnapshots deletes the expired snapshots after confirmation and returns the deleted snapshot names,
+// also when a later deletion fails
+func deleteSnapshots(esClient snapshotDeleter, repository string, expired []expiredSnapshot, prompter *prompt.Prompter, log *logger.Logger) ([]string, error) {
+	if err := prompter.Confirm(fmt.Sprintf("Delete %d snapshot(s) from repository '%s'?", len(expired), repository)); err != nil {
+		return nil, fmt.Errorf("retention enforcement aborted: %w", err)
+	}
+
+	deleted := make([]string, 0, len(expired))
+	for _, candidate := range expired {
+		log.Infof("Deleting snapshot '%s' (%s)...", candidate.Snapshot.Snapshot, candidate.Reason)
+		if err := esClient.DeleteSnapshot(repository, candidate.Snapshot.Snapshot); err != nil {
+			return deleted, fmt.Errorf("failed to delete snapshot %s: %w", candidate.Snapshot.Snapshot, err)
+		}
+		deleted = append(deleted, candidate.Snapshot.Snapshot)
+	}
+
+	log.Successf("Deleted %d snapshot(s)", len(deleted))
+	return deleted, nil
+}
+
+// selectExpiredSnapshots returns the completed snapshots of the SLM policy that violate the retention policy, newest
+// first. Like SLM retention it leaves snapshots taken outside of the policy alone, e.g. those of create-snapshot and
+// the safety snapshots taken before a restore, and it ignores snapshots in progress. Only successful snapshots count
+// towards the minimum count, so a series of failed snapshots cannot push out the last good one.
+func selectExpiredSnapshots(snapshots []elasticsearch.Snapshot, policy retentionPolicy, now time.Time) []expiredSnapshot {
+	completed := make([]elasticsearch.Snapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.State != "IN_PROGRESS" && snapshotPolicy(snapshot) == policy.Policy {
+			completed = append(completed, snapshot)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].StartTimeMillis > completed[j].StartTimeMillis
+	})
+
+	var expired []expiredSnapshot
+	successful := 0
+	for i, snapshot := range completed {
+		if snapshot.State == "SUCCESS" {
+			if successful++; successful <= policy.MinCount {
+				continue
+			}
+		}
+		age := now.Sub(time.UnixMilli(snapshot.StartTimeMillis))
+		switch {
+		case policy.MaxCount > 0 && i >= policy.MaxCount:
+			expired = append(expired, expiredSnapshot{Snapshot: snapshot, Reason: fmt.Sprintf("exceeds max count %d", policy.MaxCount)})
+		case policy.ExpireAfter > 0 && age > policy.ExpireAfter:
+			expired = append(expired, expiredSnapshot{Snapshot: snapshot, Reason: "older than " + formatRetention(policy.ExpireAfter)})
+		}
+	}
+	return expired
+}
+
+// selectExpiredSafetySnapshots returns the completed safety snapshots taken before a restore that are older than
+// maxAge, newest first
+func selectExpiredSafetySnapshots(snapshots []elasticsearch.Snapshot, maxAge time.Duration, now time.Time) []expiredSnapshot {
+	var expired []expiredSnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.State == "IN_PROGRESS" || !isSafetySnapshot(snapshot.Snapshot) {
+			continue
+		}
+		if now.Sub(time.UnixMilli(snapshot.StartTimeMillis)) > maxAge {
+			expired = append(expired, expiredSnapshot{Snapshot: snapshot, Reason: "safety snapshot older than " + formatRetention(maxAge)})
+		}
+	}
+	sort.SliceStable(expired, func(i, j int) bool {
+		return expired[i].Snapshot.StartTimeMillis > expired[j].Snapshot.StartTimeMillis
+	})
+	return expired
+}
+
+// snapshotPolicy returns the SLM policy that took snapshot, or "" when it was taken otherwise
+func snapshotPolicy(snapshot elasticsearch.Snapshot) string {
+	policy, _ := snapshot.Metadata[metadataPolicy].(string)
+	return policy
+}
+
+// expiredTable converts the snapshots selected for deletion into a table
+func expiredTable(expired []expiredSnapshot, now time.Time) output.Table {
+	table := output.Table{
+		Headers:      []string{"SNAPSHOT", "STATE", "START TIME", "AGE", "REASON"},
+		Rows:         make([][]string, 0, len(expired)),
+		StateColumns: []string{"STATE"},
+	}
+
+	for _, candidate := range expired {
+		table.Rows = append(table.Rows, []string{
+			candidate.Snapshot.Snapshot,
+			candidate.Snapshot.State,
+			candidate.Snapshot.StartTime,
+			snapshotAge(candidate.Snapshot, now),
+			candidate.Reason,
+		})
+	}
+
+	return table
+}
+
+// parseESDuration parses an Elasticsearch time value such as 30d or 12h
+func parseESDuration(value string) (time.Duration, error) {
+	match := esDurationPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("'%s' is not a time value like 30d, 12h or 90m", value)
+	}
+	amount, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a time value: %w", value, err)
+	}
+	return time.Duration(amount) * esDurationUnits[match[2]], nil
+}
+
+// formatRetention formats a retention period in whole days when possible (e.g. 30d)
+func formatRetention(d time.Duration) string {
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
@@ -0,0 +1,246 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var retentionNow = time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+// retentionTestPolicy is the SLM policy the snapshots of snapshotAged are taken by
+const retentionTestPolicy = "auto-sts-backup"
+
+// snapshotAged returns a snapshot of retentionTestPolicy started the given number of days before retentionNow
+func snapshotAged(name, state string, days int) elasticsearch.Snapshot {
+	return elasticsearch.Snapshot{
+		Snapshot:        name,
+		State:           state,
+		StartTimeMillis: retentionNow.Add(-time.Duration(days) * 24 * time.Hour).UnixMilli(),
+		Metadata:        map[string]interface{}{metadataPolicy: retentionTestPolicy},
+	}
+}
+
+// withoutPolicy returns snapshot as taken outside of an SLM policy, with metadata
+func withoutPolicy(snapshot elasticsearch.Snapshot, metadata map[string]interface{}) elasticsearch.Snapshot {
+	snapshot.Metadata = metadata
+	return snapshot
+}
+
+type mockSnapshotDeleter struct {
+	deleted []string
+	failOn  string
+}
+
+func (m *mockSnapshotDeleter) DeleteSnapshot(_, snapshotName string) error {
+	if snapshotName == m.failOn {
+		return errors.New("snapshot is in use")
+	}
+	m.deleted = append(m.deleted, snapshotName)
+	return nil
+}
+
+// TestEnforceRetentionCmd_Unit tests the command structure
+func TestEnforceRetentionCmd_Unit(t *testing.T) {
+	cmd := enforceRetentionCmd(config.NewContext())
+
+	assert.Equal(t, "enforce-retention", cmd.Use)
+	assert.NotEmpty(t, cmd.Long)
+	assert.NotNil(t, cmd.Run)
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"))
+	assert.NotNil(t, cmd.Flags().Lookup("safety-snapshot-max-age"))
+}
+
+func TestSelectExpiredSnapshots(t *testing.T) {
+	snapshots := []elasticsearch.Snapshot{
+		snapshotAged("snap-40d", "SUCCESS", 40),
+		snapshotAged("snap-1d", "SUCCESS", 1),
+		snapshotAged("snap-running", "IN_PROGRESS", 50),
+		snapshotAged("snap-31d", "FAILED", 31),
+		snapshotAged("snap-10d", "PARTIAL", 10),
+		snapshotAged("snap-2d", "SUCCESS", 2),
+		withoutPolicy(snapshotAged("pre-restore-20241201-120000", "SUCCESS", 60), map[string]interface{}{metadataReason: "safety snapshot before restore"}),
+		withoutPolicy(snapshotAged("manual-20241201-120000", "SUCCESS", 60), map[string]interface{}{metadataReason: "before upgrade"}),
+		withoutPolicy(snapshotAged("other-policy-60d", "SUCCESS", 60), map[string]interface{}{metadataPolicy: "other-policy"}),
+	}
+
+	tests := []struct {
+		name     string
+		policy   retentionPolicy
+		expected map[string]string
+	}{
+		{
+			name:   "expire after",
+			policy: retentionPolicy{Policy: retentionTestPolicy, ExpireAfter: 30 * 24 * time.Hour, MinCount: 1, MaxCount: 10},
+			expected: map[string]string{
+				"snap-31d": "older than 30d",
+				"snap-40d": "older than 30d",
+			},
+		},
+		{
+			name:   "max count",
+			policy: retentionPolicy{Policy: retentionTestPolicy, ExpireAfter: 100 * 24 * time.Hour, MinCount: 1, MaxCount: 2},
+			expected: map[string]string{
+				"snap-10d": "exceeds max count 2",
+				"snap-31d": "exceeds max count 2",
+				"snap-40d": "exceeds max count 2",
+			},
+		},
+		{
+			name:   "min count keeps expired snapshots",
+			policy: retentionPolicy{Policy: retentionTestPolicy, ExpireAfter: 12 * time.Hour, MinCount: 3, MaxCount: 10},
+			expected: map[string]string{
+				"snap-10d": "older than 12h0m0s",
+				"snap-31d": "older than 12h0m0s",
+			},
+		},
+		{
+			name:     "nothing expired",
+			policy:   retentionPolicy{Policy: retentionTestPolicy, ExpireAfter: 100 * 24 * time.Hour, MinCount: 1, MaxCount: 10},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := selectExpiredSnapshots(snapshots, tt.policy, retentionNow)
+
+			reasons := map[string]string{}
+			for _, candidate := range expired {
+				reasons[candidate.Snapshot.Snapshot] = candidate.Reason
+			}
+			assert.Equal(t, tt.expected, reasons)
+
+			// Newest first, never a snapshot in progress or one taken outside of the SLM policy
+			for i := 1; i < len(expired); i++ {
+				assert.GreaterOrEqual(t, expired[i-1].Snapshot.StartTimeMillis, expired[i].Snapshot.StartTimeMillis)
+			}
+			assert.NotContains(t, reasons, "snap-running")
+			assert.NotContains(t, reasons, "pre-restore-20241201-120000")
+			assert.NotContains(t, reasons, "manual-20241201-120000")
+			assert.NotContains(t, reasons, "other-policy-60d")
+		})
+	}
+}
+
+func TestSelectExpiredSnapshots_FailedSnapshotsDoNotCountTowardsMinCount(t *testing.T) {
+	snapshots := []elasticsearch.Snapshot{
+		snapshotAged("snap-1d", "FAILED", 1),
+		snapshotAged("snap-2d", "FAILED", 2),
+		snapshotAged("snap-3d", "PARTIAL", 3),
+		snapshotAged("snap-40d", "SUCCESS", 40),
+	}
+
+	expired := selectExpiredSnapshots(snapshots, retentionPolicy{Policy: retentionTestPolicy, ExpireAfter: 30 * 24 * time.Hour, MinCount: 1, MaxCount: 3}, retentionNow)
+
+	// The last good snapshot is kept although it is expired and beyond the max count
+	assert.Empty(t, expired)
+}
+
+func TestSelectExpiredSafetySnapshots(t *testing.T) {
+	safety := map[string]interface{}{metadataReason: "safety snapshot before restore"}
+	snapshots := []elasticsearch.Snapshot{
+		withoutPolicy(snapshotAged("pre-restore-20241201-120000", "SUCCESS", 60), safety),
+		withoutPolicy(snapshotAged("pre-restore-20250130-120000", "SUCCESS", 1), safety),
+		withoutPolicy(snapshotAged("pre-restore-20241215-120000", "PARTIAL", 45), safety),
+		withoutPolicy(snapshotAged("pre-restore-20241210-120000", "IN_PROGRESS", 50), safety),
+		snapshotAged("snap-40d", "SUCCESS", 40),
+	}
+
+	expired := selectExpiredSafetySnapshots(snapshots, 30*24*time.Hour, retentionNow)
+
+	require.Len(t, expired, 2)
+	assert.Equal(t, "pre-restore-20241215-120000", expired[0].Snapshot.Snapshot)
+	assert.Equal(t, "pre-restore-20241201-120000", expired[1].Snapshot.Snapshot)
+	assert.Equal(t, "safety snapshot older than 30d", expired[0].Reason)
+}
+
+func TestExpiredTable(t *testing.T) {
+	table := expiredTable([]expiredSnapshot{{Snapshot: snapshotAged("snap-40d", "SUCCESS", 40), Reason: "older than 30d"}}, retentionNow)
+
+	require.Len(t, table.Rows, 1)
+	assert.Equal(t, []string{"STATE"}, table.StateColumns)
+	assert.Equal(t, []string{"snap-40d", "SUCCESS", "", "40d0h", "older than 30d"}, table.Rows[0])
+}
+
+func TestDeleteSnapshots(t *testing.T) {
+	expired := []expiredSnapshot{
+		{Snapshot: elasticsearch.Snapshot{Snapshot: "snap-1"}},
+		{Snapshot: elasticsearch.Snapshot{Snapshot: "snap-2"}},
+		{Snapshot: elasticsearch.Snapshot{Snapshot: "snap-3"}},
+	}
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("deletes all after confirmation", func(t *testing.T) {
+		deleter := &mockSnapshotDeleter{}
+		prompter := prompt.NewWithIO(strings.NewReader("y\n"), &bytes.Buffer{}, false, true)
+
+		deleted, err := deleteSnapshots(deleter, "repo", expired, prompter, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"snap-1", "snap-2", "snap-3"}, deleted)
+		assert.Equal(t, deleted, deleter.deleted)
+	})
+
+	t.Run("returns snapshots deleted before a failure", func(t *testing.T) {
+		deleter := &mockSnapshotDeleter{failOn: "snap-2"}
+		prompter := prompt.NewWithIO(strings.NewReader(""), &bytes.Buffer{}, true, false)
+
+		deleted, err := deleteSnapshots(deleter, "repo", expired, prompter, log)
+
+		require.Error(t, err)
+		assert.Equal(t, []string{"snap-1"}, deleted)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		deleter := &mockSnapshotDeleter{}
+		prompter := prompt.NewWithIO(strings.NewReader("n\n"), &bytes.Buffer{}, false, true)
+
+		deleted, err := deleteSnapshots(deleter, "repo", expired, prompter, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.Cancelled, exitcode.Of(err))
+		assert.Empty(t, deleted)
+		assert.Empty(t, deleter.deleted)
+	})
+}
+
+func TestParseESDuration(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{value: "30d", expected: 30 * 24 * time.Hour},
+		{value: "12h", expected: 12 * time.Hour},
+		{value: "90m", expected: 90 * time.Minute},
+		{value: "45s", expected: 45 * time.Second},
+		{value: "500ms", expected: 500 * time.Millisecond},
+		{value: "", expectError: true},
+		{value: "30", expectError: true},
+		{value: "1w", expectError: true},
+		{value: "-1d", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			d, err := parseESDuration(tt.value)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// shardStateUnassigned is the cat shards state of a shard copy that is not allocated to any node
+const shardStateUnassigned = "UNASSIGNED"
+
+// allocationExplainer lists shards and explains their allocation
+type allocationExplainer interface {
+	ListShards(pattern string) ([]elasticsearch.ShardInfo, error)
+	ExplainAllocation(index string, shard int, primary bool) (*elasticsearch.AllocationExplanation, error)
+}
+
+type explainAllocationOptions struct {
+	Index string
+	// Shard is the shard to explain, -1 to explain every unassigned shard of Index
+	Shard   int
+	Replica bool
+}
+
+func explainAllocationCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &explainAllocationOptions{}
+	cmd := &cobra.Command{
+		Use:   "explain-allocation",
+		Short: "Explain why the shards of an index are unassigned",
+		Long: `Ask Elasticsearch why shards of an index are not allocated, through the cluster allocation explain API: the
+reason a shard became unassigned and, per node, the deciders refusing it, such as the disk watermarks, shard
+allocation awareness or the number of shards per node.
+
+Without --shard every unassigned shard of the index is explained, primaries first; when all shards are assigned
+there is nothing to explain. With --shard the primary (or with --replica a replica) of that shard is explained, also
+when it is assigned. Run it when restored shards stay unassigned after a restore.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runExplainAllocation(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Index, "index", "", "Index (or index pattern) whose shard allocation to explain (required)")
+	cmd.Flags().IntVar(&opts.Shard, "shard", -1, "Shard number to explain (default: every unassigned shard)")
+	cmd.Flags().BoolVar(&opts.Replica, "replica", false, "Explain a replica of --shard instead of the primary")
+	_ = cmd.MarkFlagRequired("index")
+	return cmd
+}
+
+func runExplainAllocation(cliCtx *config.Context, opts *explainAllocationOptions) error {
+	if opts.Replica && opts.Shard < 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--replica requires --shard"))
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	explanations, err := explainAllocation(esClient, opts, env.Log)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(explanations) == 0 {
+		formatter.PrintMessage(fmt.Sprintf("All shards of '%s' are assigned", opts.Index))
+		return nil
+	}
+	return formatter.PrintDetail(explanations, allocationTable(explanations), allocationDecidersTable(explanations))
+}
+
+// explainAllocation explains the shard of opts, or else every unassigned shard copy of the index. Unassigned replicas
+// of the same shard share their explanation, so each is explained once.
+func explainAllocation(client allocationExplainer, opts *explainAllocationOptions, log *logger.Logger) ([]elasticsearch.AllocationExplanation, error) {
+	if opts.Shard >= 0 {
+		explanation, err := client.ExplainAllocation(opts.Index, opts.Shard, !opts.Replica)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to explain the allocation of shard %d of %s: %w", opts.Shard, opts.Index, err))
+		}
+		return []elasticsearch.AllocationExplanation{*explanation}, nil
+	}
+
+	log.Infof("Listing the shards of '%s'...", opts.Index)
+	shards, err := client.ListShards(opts.Index)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list the shards of %s: %w", opts.Index, err))
+	}
+	if len(shards) == 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("no indices match '%s'", opts.Index))
+	}
+
+	unassigned := unassignedShards(shards)
+	if len(unassigned) == 0 {
+		log.Successf("All %d shard copies of '%s' are assigned", len(shards), opts.Index)
+		return nil, nil
+	}
+
+	log.Infof("Explaining %d unassigned shard(s)...", len(unassigned))
+	explanations := make([]elasticsearch.AllocationExplanation, 0, len(unassigned))
+	for _, shard := range unassigned {
+		number, _ := strconv.Atoi(shard.Shard)
+		explanation, err := client.ExplainAllocation(shard.Index, number, shard.Primary())
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to explain the allocation of shard %s of %s: %w", shard.Shard, shard.Index, err))
+		}
+		explanations = append(explanations, *explanation)
+	}
+	return explanations, nil
+}
+
+// unassignedShards returns one unassigned copy per index, shard and primary or replica, sorted with the primaries first
+func unassignedShards(shards []elasticsearch.ShardInfo) []elasticsearch.ShardInfo {
+	type shardKey struct {
+		index, shard string
+		primary      bool
+	}
+	seen := map[shardKey]bool{}
+	var unassigned []elasticsearch.ShardInfo
+	for _, shard := range shards {
+		key := shardKey{shard.Index, shard.Shard, shard.Primary()}
+		if shard.State != shardStateUnassigned || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unassigned = append(unassigned, shard)
+	}
+	sort.SliceStable(unassigned, func(i, j int) bool {
+		a, b := unassigned[i], unassigned[j]
+		if a.Primary() != b.Primary() {
+			return a.Primary()
+		}
+		if a.Index != b.Index {
+			return a.Index < b.Index
+		}
+		return shardNumber(a.Shard) < shardNumber(b.Shard)
+	})
+	return unassigned
+}
+
+func allocationTable(explanations []elasticsearch.AllocationExplanation) output.Table {
+	table := output.Table{
+		Headers: []string{"INDEX", "SHARD", "PRIMARY", "STATE", "NODE", "REASON", "CAN ALLOCATE", "EXPLANATION"},
+		Rows:    make([][]string, 0, len(explanations)),
+	}
+	for _, explanation := range explanations {
+		node := "-"
+		if explanation.CurrentNode != nil {
+			node = explanation.CurrentNode.Name
+		}
+		reason := "-"
+		if info := explanation.UnassignedInfo; info != nil {
+			reason = info.Reason
+			if info.Details != "" {
+				reason += ": " + info.Details
+			}
+		}
+		canAllocate, summary := explanation.CanAllocate, explanation.AllocateExplanation
+		if canAllocate == "" {
+			// Assigned shards are explained by whether they can remain on their node
+			canAllocate = "-"
+			summary = "can remain on current node: " + orNone(explanation.CanRemainOnCurrentNode)
+		}
+		table.Rows = append(table.Rows, []string{
+			explanation.Index,
+			strconv.Itoa(explanation.Shard),
+			strconv.FormatBool(explanation.Primary),
+			explanation.CurrentState,
+			node,
+			reason,
+			canAllocate,
+			summary,
+		})
+	}
+	return table
+}
+
+// allocationDecidersTable lists, per shard and node, the deciders that did not allow the allocation
+func allocationDecidersTable(explanations []elasticsearch.AllocationExplanation) output.Table {
+	table := output.Table{
+		Headers: []string{"INDEX", "SHARD", "PRIMARY", "NODE", "NODE DECISION", "DECIDER", "DECISION", "EXPLANATION"},
+	}
+	for _, explanation := range explanations {
+		for _, node := range explanation.NodeDecisions {
+			for _, decider := range node.Deciders {
+				table.Rows = append(table.Rows, []string{
+					explanation.Index,
+					strconv.Itoa(explanation.Shard),
+					strconv.FormatBool(explanation.Primary),
+					node.NodeName,
+					node.Decision,
+					decider.Decider,
+					decider.Decision,
+					decider.Explanation,
+				})
+			}
+		}
+	}
+	return table
+}
@@ -0,0 +1,114 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAllocationExplainer struct {
+	shards    []elasticsearch.ShardInfo
+	explained []string
+	err       error
+}
+
+func (m *mockAllocationExplainer) ListShards(_ string) ([]elasticsearch.ShardInfo, error) {
+	return m.shards, m.err
+}
+
+func (m *mockAllocationExplainer) ExplainAllocation(index string, shard int, primary bool) (*elasticsearch.AllocationExplanation, error) {
+	m.explained = append(m.explained, fmt.Sprintf("%s/%d/%t", index, shard, primary))
+	return &elasticsearch.AllocationExplanation{Index: index, Shard: shard, Primary: primary, CurrentState: "unassigned", CanAllocate: "no"}, nil
+}
+
+func TestExplainAllocation(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	shard := func(number, prirep, state string) elasticsearch.ShardInfo {
+		return elasticsearch.ShardInfo{Index: "sts_topology", Shard: number, Prirep: prirep, State: state}
+	}
+
+	t.Run("unassigned shards are explained once, primaries first", func(t *testing.T) {
+		client := &mockAllocationExplainer{shards: []elasticsearch.ShardInfo{
+			shard("0", "p", "STARTED"),
+			shard("0", "r", "UNASSIGNED"),
+			shard("0", "r", "UNASSIGNED"),
+			shard("10", "p", "UNASSIGNED"),
+			shard("2", "p", "UNASSIGNED"),
+		}}
+
+		explanations, err := explainAllocation(client, &explainAllocationOptions{Index: "sts_topology", Shard: -1}, log)
+
+		require.NoError(t, err)
+		assert.Len(t, explanations, 3)
+		assert.Equal(t, []string{"sts_topology/2/true", "sts_topology/10/true", "sts_topology/0/false"}, client.explained)
+	})
+
+	t.Run("nothing to explain when all shards are assigned", func(t *testing.T) {
+		client := &mockAllocationExplainer{shards: []elasticsearch.ShardInfo{shard("0", "p", "STARTED"), shard("0", "r", "INITIALIZING")}}
+
+		explanations, err := explainAllocation(client, &explainAllocationOptions{Index: "sts_topology", Shard: -1}, log)
+
+		require.NoError(t, err)
+		assert.Empty(t, explanations)
+		assert.Empty(t, client.explained)
+	})
+
+	t.Run("an explicit shard is explained without listing", func(t *testing.T) {
+		client := &mockAllocationExplainer{err: errors.New("must not be called")}
+
+		explanations, err := explainAllocation(client, &explainAllocationOptions{Index: "sts_topology", Shard: 1, Replica: true}, log)
+
+		require.NoError(t, err)
+		assert.Len(t, explanations, 1)
+		assert.Equal(t, []string{"sts_topology/1/false"}, client.explained)
+	})
+
+	t.Run("no matching index", func(t *testing.T) {
+		_, err := explainAllocation(&mockAllocationExplainer{}, &explainAllocationOptions{Index: "missing", Shard: -1}, log)
+
+		assert.ErrorContains(t, err, "no indices match 'missing'")
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	})
+
+	t.Run("listing fails", func(t *testing.T) {
+		_, err := explainAllocation(&mockAllocationExplainer{err: errors.New("connection refused")}, &explainAllocationOptions{Index: "sts_topology", Shard: -1}, log)
+
+		assert.Equal(t, exitcode.ConnectivityError, exitcode.Of(err))
+	})
+}
+
+func TestAllocationTables(t *testing.T) {
+	explanations := []elasticsearch.AllocationExplanation{
+		{
+			Index: "sts_topology", Shard: 0, Primary: true, CurrentState: "unassigned",
+			UnassignedInfo:      &elasticsearch.UnassignedInfo{Reason: "NEW_INDEX_RESTORED"},
+			CanAllocate:         "no",
+			AllocateExplanation: "cannot allocate because allocation is not permitted to any of the nodes",
+			NodeDecisions: []elasticsearch.NodeAllocationDecision{{
+				NodeName: "es-master-0", Decision: "no",
+				Deciders: []elasticsearch.AllocationDecider{
+					{Decider: "disk_threshold", Decision: "NO", Explanation: "the node is above the high watermark"},
+					{Decider: "awareness", Decision: "NO", Explanation: "there are too many copies of the shard allocated to nodes with attribute [zone]"},
+				},
+			}},
+		},
+		{Index: "sts_topology", Shard: 1, Primary: true, CurrentState: "started", CurrentNode: &elasticsearch.AllocationNode{Name: "es-master-1"}, CanRemainOnCurrentNode: "yes"},
+	}
+
+	summary := allocationTable(explanations)
+	require.Len(t, summary.Rows, 2)
+	assert.Equal(t, []string{"sts_topology", "0", "true", "unassigned", "-", "NEW_INDEX_RESTORED", "no",
+		"cannot allocate because allocation is not permitted to any of the nodes"}, summary.Rows[0])
+	assert.Equal(t, []string{"sts_topology", "1", "true", "started", "es-master-1", "-", "-", "can remain on current node: yes"}, summary.Rows[1])
+
+	deciders := allocationDecidersTable(explanations)
+	require.Len(t, deciders.Rows, 2)
+	assert.Equal(t, []string{"sts_topology", "0", "true", "es-master-0", "no", "disk_threshold", "NO", "the node is above the high watermark"}, deciders.Rows[0])
+	assert.Equal(t, "awareness", deciders.Rows[1][5])
+}
@@ -0,0 +1,156 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+func getSnapshotCmd(cliCtx *config.Context) *cobra.Command {
+	var snapshotName string
+	cmd := &cobra.Command{
+		Use:   "get-snapshot",
+		Short: "Show the details of an Elasticsearch snapshot",
+		Long: `Show the details of a snapshot in the restore repository: its state, timing, shard statistics, indices,
+data streams, feature states, metadata and shard failures. With --output json the full snapshot is printed.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runGetSnapshot(cliCtx, snapshotName); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&snapshotName, "snapshot-name", "s", "", "Snapshot name to show (required)")
+	_ = cmd.MarkFlagRequired("snapshot-name")
+	return cmd
+}
+
+func runGetSnapshot(cliCtx *config.Context, snapshotName string) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repository := env.Config.Elasticsearch.Restore.Repository
+	env.Log.Infof("Fetching snapshot '%s' from repository '%s'...", snapshotName, repository)
+	snapshot, err := esClient.GetSnapshot(repository, snapshotName)
+	if err != nil {
+		return snapshotLookupError(err)
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	return formatter.PrintDetail(snapshot,
+		snapshotSummaryTable(snapshot),
+		snapshotIndicesTable(snapshot),
+		snapshotFeatureStatesTable(snapshot),
+		snapshotFailuresTable(snapshot),
+	)
+}
+
+// snapshotLookupError wraps an error of GetSnapshot. A snapshot that does not exist is a usage error, since it is
+// the name the user passed that is wrong.
+func snapshotLookupError(err error) error {
+	err = fmt.Errorf("failed to get snapshot: %w", err)
+	if errors.Is(err, elasticsearch.ErrSnapshotNotFound) {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
+	return err
+}
+
+// snapshotSummaryTable lists the state, timing and shard statistics of a snapshot
+func snapshotSummaryTable(snapshot *elasticsearch.Snapshot) output.Table {
+	featureStates := make([]string, 0, len(snapshot.FeatureStates))
+	for _, featureState := range snapshot.FeatureStates {
+		featureStates = append(featureStates, featureState.FeatureName)
+	}
+
+	return output.Table{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"Snapshot", snapshot.Snapshot},
+			{"UUID", snapshot.UUID},
+			{"Repository", snapshot.Repository},
+			{"State", snapshot.State},
+			{"Version", snapshot.Version},
+			{"Start time", snapshot.StartTime},
+			{"End time", snapshot.EndTime},
+			{"Duration", (time.Duration(snapshot.DurationInMillis) * time.Millisecond).String()},
+			{"Shards", fmt.Sprintf("%d total, %d successful, %d failed", snapshot.Shards.Total, snapshot.Shards.Successful, snapshot.Shards.Failed)},
+			{"Indices", strconv.Itoa(len(snapshot.Indices))},
+			{"Data streams", orNone(strings.Join(snapshot.DataStreams, ", "))},
+			{"Global state", strconv.FormatBool(snapshot.IncludeGlobalState)},
+			{"Feature states", orNone(strings.Join(featureStates, ", "))},
+			{"Metadata", orNone(strings.Join(metadataLabels(*snapshot), ", "))},
+		},
+	}
+}
+
+// snapshotIndicesTable lists the indices of a snapshot in name order, with the number of shards that failed
+func snapshotIndicesTable(snapshot *elasticsearch.Snapshot) output.Table {
+	failedShards := make(map[string]int, len(snapshot.Failures))
+	for _, failure := range snapshot.Failures {
+		failedShards[failure.Index]++
+	}
+
+	indices := append([]string(nil), snapshot.Indices...)
+	sort.Strings(indices)
+
+	table := output.Table{
+		Headers: []string{"INDEX", "FAILED SHARDS"},
+		Rows:    make([][]string, 0, len(indices)),
+	}
+	for _, index := range indices {
+		table.Rows = append(table.Rows, []string{index, strconv.Itoa(failedShards[index])})
+	}
+	return table
+}
+
+// snapshotFeatureStatesTable lists the feature states of a snapshot with their system indices
+func snapshotFeatureStatesTable(snapshot *elasticsearch.Snapshot) output.Table {
+	table := output.Table{
+		Headers: []string{"FEATURE STATE", "SYSTEM INDICES"},
+		Rows:    make([][]string, 0, len(snapshot.FeatureStates)),
+	}
+	for _, featureState := range snapshot.FeatureStates {
+		table.Rows = append(table.Rows, []string{featureState.FeatureName, strings.Join(featureState.Indices, ", ")})
+	}
+	return table
+}
+
+// snapshotFailuresTable lists the shard failures of a snapshot
+func snapshotFailuresTable(snapshot *elasticsearch.Snapshot) output.Table {
+	table := output.Table{
+		Headers: []string{"FAILED INDEX", "SHARD", "STATUS", "REASON"},
+		Rows:    make([][]string, 0, len(snapshot.Failures)),
+	}
+	for _, failure := range snapshot.Failures {
+		table.Rows = append(table.Rows, []string{failure.Index, strconv.Itoa(failure.ShardID), failure.Status, failure.Reason})
+	}
+	return table
+}
+
+// orNone returns "none" for empty values
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
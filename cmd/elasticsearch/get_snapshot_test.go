@@ -0,0 +1,81 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSnapshotDetail() *elasticsearch.Snapshot {
+	snapshot := &elasticsearch.Snapshot{
+		Snapshot:         "sts-backup-20250304-0300",
+		UUID:             "uuid-1",
+		Repository:       "sts-backup",
+		State:            "PARTIAL",
+		Version:          "8.19.0",
+		StartTime:        "2025-03-04T03:00:00.000Z",
+		EndTime:          "2025-03-04T03:01:30.000Z",
+		DurationInMillis: 90000,
+		Indices:          []string{"sts_topology", "sts_metrics"},
+		DataStreams:      []string{"sts_k8s_logs"},
+		FeatureStates:    []elasticsearch.SnapshotFeatureState{{FeatureName: "kibana", Indices: []string{".kibana_1", ".kibana_task_manager_1"}}},
+		Failures:         []elasticsearch.SnapshotShardFailure{{Index: "sts_metrics", ShardID: 2, Status: "INTERNAL_SERVER_ERROR", Reason: "node left"}},
+	}
+	snapshot.Shards.Total = 4
+	snapshot.Shards.Successful = 3
+	snapshot.Shards.Failed = 1
+	return snapshot
+}
+
+func TestGetSnapshotCmd_Unit(t *testing.T) {
+	cmd := getSnapshotCmd(config.NewContext())
+
+	assert.Equal(t, "get-snapshot", cmd.Use)
+	assert.NotNil(t, cmd.Flags().ShorthandLookup("s"))
+	assert.NotNil(t, cmd.Run)
+}
+
+func TestSnapshotSummaryTable(t *testing.T) {
+	table := snapshotSummaryTable(testSnapshotDetail())
+
+	values := map[string]string{}
+	for _, row := range table.Rows {
+		values[row[0]] = row[1]
+	}
+	assert.Equal(t, "PARTIAL", values["State"])
+	assert.Equal(t, "1m30s", values["Duration"])
+	assert.Equal(t, "4 total, 3 successful, 1 failed", values["Shards"])
+	assert.Equal(t, "2", values["Indices"])
+	assert.Equal(t, "sts_k8s_logs", values["Data streams"])
+	assert.Equal(t, "kibana", values["Feature states"])
+}
+
+func TestSnapshotDetailTables(t *testing.T) {
+	snapshot := testSnapshotDetail()
+
+	indices := snapshotIndicesTable(snapshot)
+	assert.Equal(t, [][]string{{"sts_metrics", "1"}, {"sts_topology", "0"}}, indices.Rows)
+
+	featureStates := snapshotFeatureStatesTable(snapshot)
+	assert.Equal(t, [][]string{{"kibana", ".kibana_1, .kibana_task_manager_1"}}, featureStates.Rows)
+
+	failures := snapshotFailuresTable(snapshot)
+	assert.Equal(t, [][]string{{"sts_metrics", "2", "INTERNAL_SERVER_ERROR", "node left"}}, failures.Rows)
+
+	assert.Empty(t, snapshotFailuresTable(&elasticsearch.Snapshot{}).Rows)
+}
+
+func TestSnapshotLookupError(t *testing.T) {
+	missing := snapshotLookupError(fmt.Errorf("snapshot snap-1 not found: %w", elasticsearch.ErrSnapshotNotFound))
+	assert.Equal(t, exitcode.Usage, exitcode.Of(missing))
+	assert.ErrorIs(t, missing, elasticsearch.ErrSnapshotNotFound)
+
+	failed := snapshotLookupError(errors.New("connection refused"))
+	assert.Equal(t, 1, exitcode.Of(failed))
+	assert.EqualError(t, failed, "failed to get snapshot: connection refused")
+}
@@ -0,0 +1,264 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// ilmPolicyPutter creates or replaces ILM policies
+type ilmPolicyPutter interface {
+	PutILMPolicy(name string, policy json.RawMessage) error
+}
+
+func exportILMCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export-ilm",
+		Short: "Export the ILM policies of the STS indices to the backup bucket",
+		Long: `Export the ILM policies referenced by the indices of the restore pattern (elasticsearch.restore.indicesPattern)
+as a JSON artifact under exports/elasticsearch/ilm-policies/ in the backup bucket. ILM policies are not part of
+index snapshots; restored indices that reference a missing policy make Elasticsearch log lifecycle errors for
+every index, and the indices are never rolled over or deleted.
+
+The artifact is encrypted when archives.encryption is configured. Import it with 'import-ilm' or
+'restore-snapshot --import-ilm'.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runExportILM(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func importILMCmd(cliCtx *config.Context) *cobra.Command {
+	var key string
+	cmd := &cobra.Command{
+		Use:   "import-ilm",
+		Short: "Import ILM policies from the backup bucket",
+		Long: `Import ILM policies exported with 'export-ilm', replacing existing policies of the same name. Policies that
+are not part of the export are left unchanged. The most recent export is imported unless --key is given.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runImportILM(cliCtx, key); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&key, "key", "", "Object key of the export to import (default: the most recent export)")
+	return cmd
+}
+
+func runExportILM(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	store, cleanup, err := target.OpenExportStore(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	esClient, closeES, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer closeES()
+
+	pattern := env.Config.Elasticsearch.Restore.IndicesPattern
+	env.Log.Infof("Fetching the ILM policies of indices '%s'...", pattern)
+	indexPolicies, err := esClient.IndexLifecyclePolicies(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to get the ILM policies of the indices: %w", err)
+	}
+	allPolicies, err := esClient.GetILMPolicies()
+	if err != nil {
+		return err
+	}
+
+	policies, missing := referencedILMPolicies(indexPolicies, allPolicies)
+	if len(missing) > 0 {
+		env.Log.Warningf("Indices reference ILM policies that do not exist: %s", strings.Join(missing, ", "))
+	}
+	if len(policies) == 0 {
+		env.Log.Warningf("No ILM policies are used by indices '%s', nothing to export", pattern)
+		return nil
+	}
+
+	data, err := json.Marshal(policies)
+	if err != nil {
+		return fmt.Errorf("failed to encode ILM policies: %w", err)
+	}
+	key, err := store.Put(&export.Artifact{
+		Kind:       export.KindILMPolicies,
+		ExportedAt: time.Now(),
+		Namespace:  cliCtx.Config.Namespace,
+		RunID:      cliCtx.RunID,
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	env.Log.Successf("Exported %d ILM policy(ies) to '%s'", len(policies), key)
+	return nil
+}
+
+func runImportILM(cliCtx *config.Context, key string) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "import-ilm"}, err, env.Log)
+	}()
+
+	policies, err := loadILMPolicies(env.K8s, cliCtx, env.Config, key, env.Log)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintTable(ilmPoliciesTable(policies)); err != nil {
+		return err
+	}
+
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := prompter.Confirm(fmt.Sprintf("Import %d ILM policy(ies), replacing existing policies of the same name?", len(policies))); err != nil {
+		return fmt.Errorf("import aborted: %w", err)
+	}
+
+	esClient, closeES, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer closeES()
+
+	return importILMPolicies(esClient, policies, env.Log)
+}
+
+// referencedILMPolicies returns the policies used by the given indices, and the sorted names of the policies
+// the indices use that do not exist
+func referencedILMPolicies(indexPolicies map[string]string, allPolicies map[string]json.RawMessage) (map[string]json.RawMessage, []string) {
+	policies := make(map[string]json.RawMessage)
+	var missing []string
+	for _, name := range indexPolicies {
+		if _, done := policies[name]; done || slices.Contains(missing, name) {
+			continue
+		}
+		if policy, ok := allPolicies[name]; ok {
+			policies[name] = policy
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return policies, missing
+}
+
+// loadILMPolicies reads the ILM policies of the export stored under key, or of the most recent export when key
+// is empty
+func loadILMPolicies(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, key string, log *logger.Logger) (map[string]json.RawMessage, error) {
+	store, cleanup, err := target.OpenExportStore(k8sClient, cliCtx, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var artifact *export.Artifact
+	if key == "" {
+		log.Infof("Fetching most recent ILM policy export...")
+		key, artifact, err = store.Latest(export.KindILMPolicies)
+	} else {
+		log.Infof("Fetching ILM policy export '%s'...", key)
+		artifact, err = store.Get(key)
+	}
+	if errors.Is(err, export.ErrNotFound) {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%w: run 'export-ilm' first", err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Kind != export.KindILMPolicies {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is an export of %s, not of ILM policies", key, artifact.Kind))
+	}
+
+	var policies map[string]json.RawMessage
+	if err := json.Unmarshal(artifact.Data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to decode ILM policies of '%s': %w", key, err)
+	}
+	log.Infof("Export '%s' from %s contains %d ILM policy(ies)", key, artifact.ExportedAt.Local().Format(time.RFC3339), len(policies))
+	return policies, nil
+}
+
+// importILMPolicies puts every policy in name order. A failed policy does not stop the others; all failures are
+// returned together.
+func importILMPolicies(esClient ilmPolicyPutter, policies map[string]json.RawMessage, log *logger.Logger) error {
+	var failed []error
+	for _, name := range slices.Sorted(maps.Keys(policies)) {
+		log.Debugf("Importing ILM policy '%s'", name)
+		if err := esClient.PutILMPolicy(name, policies[name]); err != nil {
+			failed = append(failed, fmt.Errorf("ILM policy %s: %w", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to import %d of %d ILM policy(ies): %w", len(failed), len(policies), errors.Join(failed...))
+	}
+
+	log.Successf("Imported %d ILM policy(ies)", len(policies))
+	return nil
+}
+
+// ilmPoliciesTable lists the policies of an export with their phases
+func ilmPoliciesTable(policies map[string]json.RawMessage) output.Table {
+	table := output.Table{
+		Headers: []string{"POLICY", "PHASES"},
+		Rows:    make([][]string, 0, len(policies)),
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(policies)) {
+		var policy struct {
+			Phases map[string]json.RawMessage `json:"phases"`
+		}
+		// Policies that cannot be summarized are still imported as they are
+		_ = json.Unmarshal(policies[name], &policy)
+		table.Rows = append(table.Rows, []string{name, strings.Join(orderedILMPhases(policy.Phases), ", ")})
+	}
+
+	return table
+}
+
+// ilmPhaseOrder is the order in which ILM moves an index through its phases
+var ilmPhaseOrder = []string{"hot", "warm", "cold", "frozen", "delete"}
+
+// orderedILMPhases returns the names of phases in the order ILM runs them
+func orderedILMPhases(phases map[string]json.RawMessage) []string {
+	var names []string
+	for _, phase := range ilmPhaseOrder {
+		if _, ok := phases[phase]; ok {
+			names = append(names, phase)
+		}
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockILMPolicyPutter struct {
+	imported []string
+	failOn   string
+}
+
+func (m *mockILMPolicyPutter) PutILMPolicy(name string, _ json.RawMessage) error {
+	if name == m.failOn {
+		return errors.New("unknown action")
+	}
+	m.imported = append(m.imported, name)
+	return nil
+}
+
+func testILMPolicies() map[string]json.RawMessage {
+	return map[string]json.RawMessage{
+		"sts-logs":    json.RawMessage(`{"phases": {"delete": {"min_age": "7d"}, "hot": {"actions": {"rollover": {}}}}}`),
+		"sts-metrics": json.RawMessage(`{"phases": {"warm": {}, "hot": {}, "delete": {}}}`),
+		"broken":      json.RawMessage(`[]`),
+	}
+}
+
+func TestILMCmd_Unit(t *testing.T) {
+	exportCmd := exportILMCmd(config.NewContext())
+	assert.Equal(t, "export-ilm", exportCmd.Use)
+	assert.NotEmpty(t, exportCmd.Long)
+	assert.NotNil(t, exportCmd.Run)
+
+	importCmd := importILMCmd(config.NewContext())
+	assert.Equal(t, "import-ilm", importCmd.Use)
+	assert.NotNil(t, importCmd.Flags().Lookup("key"))
+
+	restore := restoreCmd(config.NewContext())
+	assert.NotNil(t, restore.Flags().Lookup("import-ilm"))
+}
+
+func TestReferencedILMPolicies(t *testing.T) {
+	indexPolicies := map[string]string{
+		".ds-sts_k8s_logs-2025.03.04-000001": "sts-logs",
+		".ds-sts_k8s_logs-2025.03.05-000002": "sts-logs",
+		"sts_metrics":                        "sts-metrics",
+		"sts_old":                            "removed",
+	}
+
+	policies, missing := referencedILMPolicies(indexPolicies, testILMPolicies())
+
+	assert.Equal(t, []string{"removed"}, missing)
+	assert.Len(t, policies, 2)
+	assert.Contains(t, policies, "sts-logs")
+	assert.Contains(t, policies, "sts-metrics")
+}
+
+func TestImportILMPolicies(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("imports all in name order", func(t *testing.T) {
+		putter := &mockILMPolicyPutter{}
+
+		require.NoError(t, importILMPolicies(putter, testILMPolicies(), log))
+		assert.Equal(t, []string{"broken", "sts-logs", "sts-metrics"}, putter.imported)
+	})
+
+	t.Run("continues after a failure", func(t *testing.T) {
+		putter := &mockILMPolicyPutter{failOn: "sts-logs"}
+
+		err := importILMPolicies(putter, testILMPolicies(), log)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to import 1 of 3 ILM policy(ies)")
+		assert.Contains(t, err.Error(), "ILM policy sts-logs")
+		assert.Equal(t, []string{"broken", "sts-metrics"}, putter.imported)
+	})
+}
+
+func TestILMPoliciesTable(t *testing.T) {
+	table := ilmPoliciesTable(testILMPolicies())
+
+	assert.Equal(t, []string{"POLICY", "PHASES"}, table.Headers)
+	assert.Equal(t, [][]string{
+		{"broken", ""},
+		{"sts-logs", "hot, delete"},
+		{"sts-metrics", "hot, warm, delete"},
+	}, table.Rows)
+}
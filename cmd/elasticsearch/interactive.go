@@ -0,0 +1,191 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/color"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+)
+
+const (
+	// maxInteractiveSnapshots is the number of most recent snapshots offered for selection
+	maxInteractiveSnapshots = 20
+	// maxSelectionAttempts is the number of invalid answers accepted before giving up
+	maxSelectionAttempts = 3
+)
+
+// restorePlan summarizes what an interactive restore is about to do
+type restorePlan struct {
+	Snapshot          elasticsearch.Snapshot
+	SizeInBytes       int64 // negative when the size could not be determined
+	Repository        string
+	Namespace         string
+	ScaleDownSelector string
+	IndicesPattern    string
+	IndicesToDelete   []string
+	DropAllIndices    bool
+	// StatefulSetSelectors select the Elasticsearch StatefulSets scaled down after the deployments
+	StatefulSetSelectors []string
+	// SafetySnapshotRepository is the repository the indices to delete are snapshotted to first; empty skips it
+	SafetySnapshotRepository string
+	// Conflicts are the snapshot indices that exist already, handled with ConflictStrategy (see --on-conflict)
+	Conflicts        []string
+	ConflictStrategy string
+	RenameSuffix     string
+	DisableRebalance bool
+	Maintenance      config.MaintenanceConfig
+}
+
+// selectSnapshot shows the most recent snapshots and lets the operator pick one by number
+func selectSnapshot(snapshots []elasticsearch.Snapshot, prompter *prompt.Prompter, out io.Writer, now time.Time) (*elasticsearch.Snapshot, error) {
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots found to restore")
+	}
+
+	// Newest first
+	sorted := make([]elasticsearch.Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartTimeMillis > sorted[j].StartTimeMillis
+	})
+	if len(sorted) > maxInteractiveSnapshots {
+		sorted = sorted[:maxInteractiveSnapshots]
+	}
+
+	printSnapshotMenu(out, sorted, now)
+
+	question := fmt.Sprintf("Select a snapshot to restore [1-%d] (q to quit):", len(sorted))
+	for attempt := 0; attempt < maxSelectionAttempts; attempt++ {
+		answer, err := prompter.Ask(question)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(answer, "q") {
+			return nil, exitcode.Wrap(exitcode.Cancelled, fmt.Errorf("restore cancelled by user"))
+		}
+
+		choice, err := strconv.Atoi(answer)
+		if err == nil && choice >= 1 && choice <= len(sorted) {
+			return &sorted[choice-1], nil
+		}
+		_, _ = fmt.Fprintf(out, "Invalid selection '%s'\n", answer)
+	}
+
+	return nil, exitcode.Wrap(exitcode.Cancelled, fmt.Errorf("no valid snapshot selected"))
+}
+
+// printSnapshotMenu prints the numbered list of snapshots to choose from
+func printSnapshotMenu(out io.Writer, snapshots []elasticsearch.Snapshot, now time.Time) {
+	_, _ = fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "#\tSNAPSHOT\tAGE\tSTATE\tINDICES")
+	for i, snapshot := range snapshots {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n",
+			i+1,
+			snapshot.Snapshot,
+			snapshotAge(snapshot, now),
+			color.State(out, snapshot.State),
+			len(snapshot.Indices),
+		)
+	}
+	_ = w.Flush()
+}
+
+// printRestorePlan prints the actions an interactive restore will perform, in the order the restore performs them
+func printRestorePlan(out io.Writer, plan restorePlan, now time.Time) {
+	size := "unknown"
+	if plan.SizeInBytes >= 0 {
+		size = output.FormatBytes(plan.SizeInBytes)
+	}
+
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Restore plan:")
+	_, _ = fmt.Fprintf(out, "  Snapshot:    %s (%s)\n", plan.Snapshot.Snapshot, color.State(out, plan.Snapshot.State))
+	_, _ = fmt.Fprintf(out, "  Taken:       %s (%s ago)\n", plan.Snapshot.StartTime, snapshotAge(plan.Snapshot, now))
+	_, _ = fmt.Fprintf(out, "  Size:        %s\n", size)
+	_, _ = fmt.Fprintf(out, "  Indices:     %d (pattern: %s)\n", len(plan.Snapshot.Indices), plan.IndicesPattern)
+	_, _ = fmt.Fprintf(out, "  Repository:  %s\n", plan.Repository)
+	_, _ = fmt.Fprintf(out, "  Namespace:   %s\n", plan.Namespace)
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "Steps:")
+
+	number := 0
+	step := func(format string, args ...interface{}) {
+		number++
+		_, _ = fmt.Fprintf(out, "  %d. %s\n", number, fmt.Sprintf(format, args...))
+	}
+	detail := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(out, "     %s\n", fmt.Sprintf(format, args...))
+	}
+
+	if plan.Maintenance.Enabled() {
+		step("Enable maintenance mode (ConfigMap %s, %s=%s)", plan.Maintenance.ConfigMap, plan.Maintenance.Key, plan.Maintenance.Value)
+	}
+	step("Scale down deployments matching '%s'", plan.ScaleDownSelector)
+	for _, selector := range plan.StatefulSetSelectors {
+		detail("then statefulsets matching '%s'", selector)
+	}
+	if plan.DisableRebalance {
+		step("Disable shard rebalancing")
+	}
+	if plan.DropAllIndices {
+		if plan.SafetySnapshotRepository != "" {
+			step("Take a safety snapshot of the indices to delete in repository '%s'", plan.SafetySnapshotRepository)
+		} else {
+			step("%s", color.Sprint(out, color.Yellow, "Skip the safety snapshot: the deleted indices cannot be rolled back"))
+		}
+		step("%s", color.Sprint(out, color.Red, fmt.Sprintf("DELETE %d existing STS index(es)", len(plan.IndicesToDelete))))
+	} else {
+		printConflictStep(out, plan, step, detail)
+	}
+	step("Restore snapshot '%s'", plan.Snapshot.Snapshot)
+	if plan.DisableRebalance {
+		step("Enable shard rebalancing again")
+	}
+	if len(plan.StatefulSetSelectors) > 0 {
+		step("Scale statefulsets and deployments back up")
+	} else {
+		step("Scale deployments back up")
+	}
+	if plan.Maintenance.Enabled() {
+		step("Disable maintenance mode")
+	}
+}
+
+// printConflictStep prints how the snapshot indices that exist already are handled, see resolveRestoreConflicts
+func printConflictStep(out io.Writer, plan restorePlan, step, detail func(format string, args ...interface{})) {
+	if len(plan.Conflicts) == 0 {
+		step("Keep existing indices (none of them is in the snapshot)")
+		return
+	}
+
+	switch plan.ConflictStrategy {
+	case conflictSkip:
+		step("Leave the %d existing index(es) out of the restore (--on-conflict skip):", len(plan.Conflicts))
+	case conflictRename:
+		step("Restore the %d existing index(es) with suffix '%s', without aliases (--on-conflict rename):", len(plan.Conflicts), plan.RenameSuffix)
+	default:
+		step("%s", color.Sprint(out, color.Red, fmt.Sprintf("ABORT: %d index(es) of the snapshot already exist (--on-conflict abort):", len(plan.Conflicts))))
+	}
+	for _, index := range plan.Conflicts {
+		detail("- %s", index)
+	}
+}
+
+// snapshotAge returns how long ago a snapshot was started
+func snapshotAge(snapshot elasticsearch.Snapshot, now time.Time) string {
+	if snapshot.StartTimeMillis == 0 {
+		return "unknown"
+	}
+	return output.FormatAge(now.Sub(time.UnixMilli(snapshot.StartTimeMillis)))
+}
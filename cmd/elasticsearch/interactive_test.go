@@ -0,0 +1,167 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var interactiveNow = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+func interactiveSnapshots() []elasticsearch.Snapshot {
+	return []elasticsearch.Snapshot{
+		{Snapshot: "sts-backup-20250108", State: "SUCCESS", StartTimeMillis: interactiveNow.Add(-50 * time.Hour).UnixMilli(), Indices: []string{"a"}},
+		{Snapshot: "sts-backup-20250110", State: "PARTIAL", StartTimeMillis: interactiveNow.Add(-2 * time.Hour).UnixMilli(), Indices: []string{"a", "b"}},
+		{Snapshot: "sts-backup-20250109", State: "SUCCESS", StartTimeMillis: interactiveNow.Add(-26 * time.Hour).UnixMilli()},
+	}
+}
+
+func TestSelectSnapshot(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedSnapshot string
+		expectedCode     int
+	}{
+		{name: "first is newest", input: "1\n", expectedSnapshot: "sts-backup-20250110"},
+		{name: "last is oldest", input: "3\n", expectedSnapshot: "sts-backup-20250108"},
+		{name: "retry after invalid input", input: "9\nabc\n2\n", expectedSnapshot: "sts-backup-20250109"},
+		{name: "quit", input: "q\n", expectedCode: exitcode.Cancelled},
+		{name: "too many invalid answers", input: "0\n4\nx\n1\n", expectedCode: exitcode.Cancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			prompter := prompt.NewWithIO(strings.NewReader(tt.input), out, false, true)
+
+			selected, err := selectSnapshot(interactiveSnapshots(), prompter, out, interactiveNow)
+
+			if tt.expectedSnapshot == "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSnapshot, selected.Snapshot)
+			assert.Contains(t, out.String(), "2h0m")
+		})
+	}
+}
+
+func TestSelectSnapshot_NoSnapshots(t *testing.T) {
+	prompter := prompt.NewWithIO(strings.NewReader("1\n"), &bytes.Buffer{}, false, true)
+
+	_, err := selectSnapshot(nil, prompter, &bytes.Buffer{}, interactiveNow)
+
+	assert.ErrorContains(t, err, "no snapshots found")
+}
+
+func TestSelectSnapshot_LimitsMenu(t *testing.T) {
+	snapshots := make([]elasticsearch.Snapshot, 0, maxInteractiveSnapshots+5)
+	for i := 0; i < maxInteractiveSnapshots+5; i++ {
+		snapshots = append(snapshots, elasticsearch.Snapshot{
+			Snapshot:        fmt.Sprintf("snap-%02d", i),
+			StartTimeMillis: interactiveNow.Add(-time.Duration(i) * time.Hour).UnixMilli(),
+		})
+	}
+	out := &bytes.Buffer{}
+	prompter := prompt.NewWithIO(strings.NewReader(fmt.Sprintf("%d\n", maxInteractiveSnapshots+1)), out, false, true)
+
+	_, err := selectSnapshot(snapshots, prompter, out, interactiveNow)
+
+	assert.Error(t, err)
+	assert.Contains(t, out.String(), "snap-19")
+	assert.NotContains(t, out.String(), "snap-20")
+}
+
+func TestPrintRestorePlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     restorePlan
+		contains []string
+	}{
+		{
+			name: "drop all indices with known size",
+			plan: restorePlan{
+				Snapshot:          interactiveSnapshots()[1],
+				SizeInBytes:       2 * 1024 * 1024 * 1024,
+				Repository:        "sts-backup",
+				Namespace:         "suse-observability",
+				ScaleDownSelector: "observability.suse.com/scalable-during-es-restore=true",
+				IndicesPattern:    "sts*",
+				IndicesToDelete:   []string{"sts_a", "sts_b", "sts_c"},
+				DropAllIndices:    true,
+			},
+			contains: []string{"sts-backup-20250110", "2.0 GiB", "2h0m ago", "DELETE 3 existing STS index(es)", "suse-observability"},
+		},
+		{
+			name: "keep indices with unknown size",
+			plan: restorePlan{
+				Snapshot:             interactiveSnapshots()[0],
+				SizeInBytes:          -1,
+				StatefulSetSelectors: []string{"role=ingest"},
+			},
+			contains: []string{"Size:        unknown", "Keep existing indices", "then statefulsets matching 'role=ingest'", "Scale statefulsets and deployments back up"},
+		},
+		{
+			name: "rename conflicting indices",
+			plan: restorePlan{
+				Snapshot:         interactiveSnapshots()[0],
+				Conflicts:        []string{"sts_a", "sts_b"},
+				ConflictStrategy: conflictRename,
+				RenameSuffix:     "-restored",
+			},
+			contains: []string{"2. Restore the 2 existing index(es) with suffix '-restored'", "- sts_a", "- sts_b", "3. Restore snapshot"},
+		},
+		{
+			name: "abort on conflicting indices",
+			plan: restorePlan{
+				Snapshot:  interactiveSnapshots()[0],
+				Conflicts: []string{"sts_a"},
+			},
+			contains: []string{"ABORT: 1 index(es) of the snapshot already exist", "- sts_a"},
+		},
+		{
+			name: "optional steps",
+			plan: restorePlan{
+				Snapshot:                 interactiveSnapshots()[0],
+				IndicesToDelete:          []string{"sts_a"},
+				DropAllIndices:           true,
+				SafetySnapshotRepository: "sts-backup",
+				DisableRebalance:         true,
+				Maintenance:              config.MaintenanceConfig{ConfigMap: "receiver-config", Key: "maintenance", Value: "true"},
+			},
+			contains: []string{
+				"1. Enable maintenance mode (ConfigMap receiver-config, maintenance=true)",
+				"2. Scale down deployments",
+				"3. Disable shard rebalancing",
+				"4. Take a safety snapshot of the indices to delete in repository 'sts-backup'",
+				"5. DELETE 1 existing STS index(es)",
+				"6. Restore snapshot",
+				"7. Enable shard rebalancing again",
+				"8. Scale deployments back up",
+				"9. Disable maintenance mode",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			printRestorePlan(out, tt.plan, interactiveNow)
+			for _, expected := range tt.contains {
+				assert.Contains(t, out.String(), expected)
+			}
+		})
+	}
+}
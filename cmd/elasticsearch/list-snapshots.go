@@ -3,84 +3,293 @@ package elasticsearch
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
 	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
-	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
 	"github.com/stackvista/stackstate-backup-cli/internal/logger"
 	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 )
 
+// snapshotStates are the snapshot states that can be filtered on
+var snapshotStates = []string{"SUCCESS", "PARTIAL", "FAILED", "IN_PROGRESS", "INCOMPATIBLE"}
+
+// listSnapshotsOptions holds the flags of list-snapshots
+type listSnapshotsOptions struct {
+	State   string
+	Since   string
+	Until   string
+	Details bool
+	// Labels are key=value pairs the metadata of the listed snapshots holds
+	Labels     []string
+	Namespaces namespacesOptions
+}
+
+// snapshotFilter selects snapshots by state, start time and metadata; zero values match every snapshot
+type snapshotFilter struct {
+	State  string
+	Since  time.Time
+	Until  time.Time
+	Labels map[string]string
+}
+
 func listSnapshotsCmd(cliCtx *config.Context) *cobra.Command {
-	return &cobra.Command{
+	opts := &listSnapshotsOptions{}
+	cmd := &cobra.Command{
 		Use:   "list-snapshots",
 		Short: "List available Elasticsearch snapshots",
-		Run: func(_ *cobra.Command, _ []string) {
-			if err := runListSnapshots(cliCtx); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
+		Long: `List the snapshots in the restore repository, optionally filtered by state and start time.
+
+--since and --until take a date (2025-03-04), an RFC3339 time (2025-03-04T05:00:00Z) or an age such as 7d or 12h,
+e.g. '--state FAILED --since 7d' lists the snapshots that failed during the last week.
+
+--label selects snapshots by their metadata, e.g. '--label reason=pre-upgrade' for snapshots taken with
+create-snapshot or '--label policy=auto-sts-backup' for the snapshots of an SLM policy.
+
+With --details the total size of every snapshot is listed as well. It is read from the snapshot status API, which
+reads the snapshot metadata from the repository and can take a while for many snapshots.
+
+--namespaces ns1,ns2 or --all-configured-namespaces list the snapshots of several installations one after another,
+with a NAMESPACE column, instead of those of --namespace. A namespace that fails is reported and the others are
+still listed.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := runListSnapshots(cmd, cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
 			}
 		},
 	}
+	cmd.Flags().StringVar(&opts.State, "state", "", "Only list snapshots in this state ("+strings.Join(snapshotStates, "|")+")")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only list snapshots started at or after this date, time or age")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "Only list snapshots started before this date, time or age")
+	cmd.Flags().StringArrayVar(&opts.Labels, "label", nil, "Only list snapshots whose metadata holds this key=value, repeatable")
+	cmd.Flags().BoolVar(&opts.Details, "details", false, "Include the total size of every snapshot (queries the snapshot status API, which is slow on large repositories)")
+	addNamespacesFlags(cmd, &opts.Namespaces)
+	return cmd
+}
+
+// newSnapshotFilter validates the filter flags and resolves ages relative to now
+func newSnapshotFilter(opts *listSnapshotsOptions, now time.Time) (*snapshotFilter, error) {
+	filter := &snapshotFilter{State: strings.ToUpper(opts.State)}
+	if filter.State != "" && !slices.Contains(snapshotStates, filter.State) {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--state must be one of %s, got '%s'", strings.Join(snapshotStates, ", "), opts.State))
+	}
+
+	var err error
+	if filter.Labels, err = parseLabels(opts.Labels); err != nil {
+		return nil, err
+	}
+	if filter.Since, err = parseTimeBound(opts.Since, now); err != nil {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --since: %w", err))
+	}
+	if filter.Until, err = parseTimeBound(opts.Until, now); err != nil {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --until: %w", err))
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--since must be before --until"))
+	}
+	return filter, nil
+}
+
+// parseTimeBound parses a date, an RFC3339 time or an age (e.g. 7d) relative to now; empty is the zero time
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if age, err := parseESDuration(value); err == nil {
+		return now.Add(-age), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a date (2025-03-04), RFC3339 time or age like 7d", value)
+}
+
+// Matches reports whether a snapshot passes the filter
+func (f *snapshotFilter) Matches(snapshot elasticsearch.Snapshot) bool {
+	if f.State != "" && snapshot.State != f.State {
+		return false
+	}
+	started := time.UnixMilli(snapshot.StartTimeMillis)
+	if !f.Since.IsZero() && started.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !started.Before(f.Until) {
+		return false
+	}
+	for key, value := range f.Labels {
+		if actual, ok := metadataValue(snapshot, key); !ok || actual != value {
+			return false
+		}
+	}
+	return true
 }
 
-func runListSnapshots(cliCtx *config.Context) error {
+func runListSnapshots(cmd *cobra.Command, cliCtx *config.Context, opts *listSnapshotsOptions) error {
 	// Create logger
-	log := logger.New(cliCtx.Config.Quiet, cliCtx.Config.Debug)
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
 
-	// Create Kubernetes client
-	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.Debug)
+	filter, err := newSnapshotFilter(opts, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
+	}
+	if opts.Namespaces.Enabled() {
+		return listSnapshotsInNamespaces(cmd, cliCtx, opts, filter, log)
 	}
 
-	// Load configuration
-	cfg, err := config.LoadConfig(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName)
+	snapshots, stats, err := fetchSnapshots(cliCtx, filter, opts.Details)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return err
 	}
 
-	// Setup port-forward to Elasticsearch
-	serviceName := cfg.Elasticsearch.Service.Name
-	localPort := cfg.Elasticsearch.Service.LocalPortForwardPort
-	remotePort := cfg.Elasticsearch.Service.Port
+	// Format and print snapshots
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+
+	if len(snapshots) == 0 {
+		formatter.PrintMessage("No snapshots found")
+		return nil
+	}
+	return formatter.PrintTable(snapshotsTable(snapshots, stats))
+}
 
-	pf, err := portforward.SetupPortForward(k8sClient, cliCtx.Config.Namespace, serviceName, localPort, remotePort, log)
+// listSnapshotsInNamespaces lists the snapshots of the installations in several namespaces in one table with a
+// NAMESPACE column. The namespaces that fail are logged and returned as error after the table is printed.
+func listSnapshotsInNamespaces(cmd *cobra.Command, cliCtx *config.Context, opts *listSnapshotsOptions, filter *snapshotFilter, log *logger.Logger) error {
+	namespaces, err := resolveNamespaces(cmd, cliCtx, &opts.Namespaces, log)
 	if err != nil {
 		return err
 	}
-	defer close(pf.StopChan)
 
-	// Create Elasticsearch client
-	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", pf.LocalPort))
+	type namespaceSnapshots struct {
+		namespace string
+		snapshots []elasticsearch.Snapshot
+		stats     map[string]elasticsearch.SnapshotStats
+	}
+	var results []namespaceSnapshots
+	var errs []error
+	for _, namespace := range namespaces {
+		log.Infof("Listing the snapshots of namespace '%s'...", namespace)
+		snapshots, stats, err := fetchSnapshots(namespaceContext(cliCtx, namespace), filter, opts.Details)
+		if err != nil {
+			log.Errorf("Failed to list the snapshots of namespace '%s': %v", namespace, err)
+			errs = append(errs, fmt.Errorf("namespace '%s': %w", namespace, err))
+			continue
+		}
+		results = append(results, namespaceSnapshots{namespace: namespace, snapshots: snapshots, stats: stats})
+	}
+
+	withMetadata := slices.ContainsFunc(results, func(result namespaceSnapshots) bool { return hasMetadata(result.snapshots) })
+	table := namespaceTable(snapshotsTableHeaders(opts.Details, withMetadata))
+	for _, result := range results {
+		rows := snapshotRows(result.snapshots, result.stats, opts.Details, withMetadata)
+		table.Rows = append(table.Rows, namespaceRows(result.namespace, rows)...)
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(table.Rows) == 0 {
+		formatter.PrintMessage("No snapshots found")
+	} else if err := formatter.PrintTable(table); err != nil {
+		return err
+	}
+	return namespaceErrors(errs, len(namespaces))
+}
+
+// fetchSnapshots returns the snapshots in the restore repository of the installation of cliCtx that pass filter,
+// with their statistics when details is set
+func fetchSnapshots(cliCtx *config.Context, filter *snapshotFilter, details bool) ([]elasticsearch.Snapshot, map[string]elasticsearch.SnapshotStats, error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	esClient, cleanup, err := connectElasticsearch(env)
 	if err != nil {
-		return fmt.Errorf("failed to create Elasticsearch client: %w", err)
+		return nil, nil, err
 	}
+	defer cleanup()
 
 	// List snapshots
-	repository := cfg.Elasticsearch.Restore.Repository
-	log.Infof("Fetching snapshots from repository '%s'...", repository)
+	repository := env.Config.Elasticsearch.Restore.Repository
+	env.Log.Infof("Fetching snapshots from repository '%s'...", repository)
 
-	snapshots, err := esClient.ListSnapshots(repository)
+	// The filter is applied while the response is read, so only the matching snapshots are kept in memory
+	snapshots, err := esClient.ListSnapshotsMatching(repository, filter.Matches)
 	if err != nil {
-		return fmt.Errorf("failed to list snapshots: %w", err)
+		return nil, nil, fmt.Errorf("failed to list snapshots: %w", err)
 	}
 
-	// Format and print snapshots
-	formatter := output.NewFormatter(cliCtx.Config.OutputFormat)
+	var stats map[string]elasticsearch.SnapshotStats
+	if details && len(snapshots) > 0 {
+		env.Log.Infof("Fetching statistics of %d snapshot(s)...", len(snapshots))
+		if stats, err = fetchSnapshotStats(esClient, repository, snapshots); err != nil {
+			return nil, nil, err
+		}
+	}
+	return snapshots, stats, nil
+}
 
-	if len(snapshots) == 0 {
-		formatter.PrintMessage("No snapshots found")
-		return nil
+// ListSnapshots returns the snapshots in the restore repository. It is used to list snapshots outside of the CLI,
+// such as from pkg/backup.
+func ListSnapshots(cliCtx *config.Context) ([]elasticsearch.Snapshot, error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	snapshots, err := esClient.ListSnapshots(env.Config.Elasticsearch.Restore.Repository)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list snapshots: %w", err))
 	}
+	return snapshots, nil
+}
+
+// snapshotsTable lists the snapshots with their index count, their total size when stats are given and their
+// metadata when any snapshot has metadata
+func snapshotsTable(snapshots []elasticsearch.Snapshot, stats map[string]elasticsearch.SnapshotStats) output.Table {
+	withMetadata := hasMetadata(snapshots)
+	table := snapshotsTableHeaders(stats != nil, withMetadata)
+	table.Rows = snapshotRows(snapshots, stats, stats != nil, withMetadata)
+	return table
+}
 
+// snapshotsTableHeaders returns the snapshots table without rows, with a SIZE column when withStats is set and a
+// METADATA column when withMetadata is set
+func snapshotsTableHeaders(withStats, withMetadata bool) output.Table {
 	table := output.Table{
-		Headers: []string{"SNAPSHOT", "STATE", "START TIME", "DURATION (ms)", "FAILURES"},
-		Rows:    make([][]string, 0, len(snapshots)),
+		Headers:      []string{"SNAPSHOT", "STATE", "START TIME", "DURATION (ms)", "INDICES", "FAILURES"},
+		StateColumns: []string{"STATE"},
 	}
+	if withStats {
+		table.Headers = append(table.Headers, "SIZE")
+	}
+	if withMetadata {
+		table.Headers = append(table.Headers, "METADATA")
+	}
+	return table
+}
+
+// hasMetadata reports whether any snapshot has metadata
+func hasMetadata(snapshots []elasticsearch.Snapshot) bool {
+	return slices.ContainsFunc(snapshots, func(snapshot elasticsearch.Snapshot) bool { return len(snapshot.Metadata) > 0 })
+}
 
+// snapshotRows returns the rows of the snapshots table, see snapshotsTableHeaders
+func snapshotRows(snapshots []elasticsearch.Snapshot, stats map[string]elasticsearch.SnapshotStats, withStats, withMetadata bool) [][]string {
+	rows := make([][]string, 0, len(snapshots))
 	for _, snapshot := range snapshots {
 		failures := "0"
 		if len(snapshot.Failures) > 0 {
@@ -92,10 +301,24 @@ func runListSnapshots(cliCtx *config.Context) error {
 			snapshot.State,
 			snapshot.StartTime,
 			fmt.Sprintf("%d", snapshot.DurationInMillis),
+			fmt.Sprintf("%d", len(snapshot.Indices)),
 			failures,
 		}
-		table.Rows = append(table.Rows, row)
+		if withStats {
+			size := "-"
+			if s, ok := stats[snapshot.Snapshot]; ok {
+				size = output.FormatBytes(s.TotalSizeInBytes)
+			}
+			row = append(row, size)
+		}
+		if withMetadata {
+			labels := "-"
+			if len(snapshot.Metadata) > 0 {
+				labels = strings.Join(metadataLabels(snapshot), ", ")
+			}
+			row = append(row, labels)
+		}
+		rows = append(rows, row)
 	}
-
-	return formatter.PrintTable(table)
+	return rows
 }
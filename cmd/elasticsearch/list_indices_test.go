@@ -57,11 +57,11 @@ func (m *mockESClientForIndices) RestoreSnapshot(_, _, _ string, _ bool) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (m *mockESClientForIndices) ConfigureSnapshotRepository(_, _, _, _, _, _ string) error {
+func (m *mockESClientForIndices) ConfigureSnapshotRepository(_, _, _, _, _, _ string, _ elasticsearch.S3Connection, _ elasticsearch.RepositoryTuning) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (m *mockESClientForIndices) ConfigureSLMPolicy(_, _, _, _, _, _ string, _, _ int) error {
+func (m *mockESClientForIndices) ConfigureSLMPolicy(_, _, _, _, _, _ string, _, _ int, _ []string) error {
 	return fmt.Errorf("not implemented")
 }
 
@@ -3,6 +3,7 @@ package elasticsearch
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -58,11 +59,11 @@ func (m *mockESClient) RestoreSnapshot(_, _, _ string, _ bool) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (m *mockESClient) ConfigureSnapshotRepository(_, _, _, _, _, _ string) error {
+func (m *mockESClient) ConfigureSnapshotRepository(_, _, _, _, _, _ string, _ elasticsearch.S3Connection, _ elasticsearch.RepositoryTuning) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (m *mockESClient) ConfigureSLMPolicy(_, _, _, _, _, _ string, _, _ int) error {
+func (m *mockESClient) ConfigureSLMPolicy(_, _, _, _, _, _ string, _, _ int, _ []string) error {
 	return fmt.Errorf("not implemented")
 }
 
@@ -211,3 +212,184 @@ func TestMockESClient(t *testing.T) {
 		})
 	}
 }
+
+//nolint:funlen
+func TestNewSnapshotFilter(t *testing.T) {
+	now := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		opts          listSnapshotsOptions
+		expected      *snapshotFilter
+		errorContains string
+	}{
+		{
+			name:     "no filters",
+			expected: &snapshotFilter{},
+		},
+		{
+			name:     "state is case insensitive",
+			opts:     listSnapshotsOptions{State: "failed"},
+			expected: &snapshotFilter{State: "FAILED"},
+		},
+		{
+			name:     "age and RFC3339 time",
+			opts:     listSnapshotsOptions{Since: "7d", Until: "2025-03-10T00:00:00Z"},
+			expected: &snapshotFilter{Since: now.Add(-7 * 24 * time.Hour), Until: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:     "labels",
+			opts:     listSnapshotsOptions{Labels: []string{"reason=before upgrade", "ticket=OPS-1"}},
+			expected: &snapshotFilter{Labels: map[string]string{"reason": "before upgrade", "ticket": "OPS-1"}},
+		},
+		{
+			name:          "label without value",
+			opts:          listSnapshotsOptions{Labels: []string{"reason"}},
+			errorContains: "--label must be key=value",
+		},
+		{
+			name:          "unknown state",
+			opts:          listSnapshotsOptions{State: "BROKEN"},
+			errorContains: "--state must be one of",
+		},
+		{
+			name:          "invalid since",
+			opts:          listSnapshotsOptions{Since: "last week"},
+			errorContains: "invalid --since",
+		},
+		{
+			name:          "since after until",
+			opts:          listSnapshotsOptions{Since: "1d", Until: "7d"},
+			errorContains: "--since must be before --until",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newSnapshotFilter(&tt.opts, now)
+
+			if tt.errorContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.State, filter.State)
+			assert.True(t, tt.expected.Since.Equal(filter.Since), "since %s", filter.Since)
+			assert.True(t, tt.expected.Until.Equal(filter.Until), "until %s", filter.Until)
+			assert.Equal(t, len(tt.expected.Labels), len(filter.Labels))
+			for key, value := range tt.expected.Labels {
+				assert.Equal(t, value, filter.Labels[key])
+			}
+		})
+	}
+
+	t.Run("date is local midnight", func(t *testing.T) {
+		filter, err := newSnapshotFilter(&listSnapshotsOptions{Since: "2025-03-04"}, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 4, 0, 0, 0, 0, time.Local), filter.Since)
+	})
+}
+
+func TestFilterSnapshots(t *testing.T) {
+	day := func(d int) int64 { return time.Date(2025, 3, d, 3, 0, 0, 0, time.UTC).UnixMilli() }
+	snapshots := []elasticsearch.Snapshot{
+		{Snapshot: "sts-backup-20250301", State: "SUCCESS", StartTimeMillis: day(1)},
+		{Snapshot: "sts-backup-20250305", State: "FAILED", StartTimeMillis: day(5)},
+		{Snapshot: "sts-backup-20250308", State: "PARTIAL", StartTimeMillis: day(8), Metadata: map[string]interface{}{"reason": "upgrade", "ticket": "OPS-1"}},
+		{Snapshot: "sts-backup-20250310", State: "FAILED", StartTimeMillis: day(10)},
+	}
+	names := func(snapshots []elasticsearch.Snapshot) []string {
+		result := []string{}
+		for _, snapshot := range snapshots {
+			result = append(result, snapshot.Snapshot)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		filter   snapshotFilter
+		expected []string
+	}{
+		{
+			name:     "no filters",
+			expected: []string{"sts-backup-20250301", "sts-backup-20250305", "sts-backup-20250308", "sts-backup-20250310"},
+		},
+		{
+			name:     "state",
+			filter:   snapshotFilter{State: "FAILED"},
+			expected: []string{"sts-backup-20250305", "sts-backup-20250310"},
+		},
+		{
+			name:     "since is inclusive and until exclusive",
+			filter:   snapshotFilter{Since: time.UnixMilli(day(5)), Until: time.UnixMilli(day(10))},
+			expected: []string{"sts-backup-20250305", "sts-backup-20250308"},
+		},
+		{
+			name:     "state and since",
+			filter:   snapshotFilter{State: "FAILED", Since: time.UnixMilli(day(6))},
+			expected: []string{"sts-backup-20250310"},
+		},
+		{
+			name:     "label",
+			filter:   snapshotFilter{Labels: map[string]string{"reason": "upgrade"}},
+			expected: []string{"sts-backup-20250308"},
+		},
+		{
+			name:     "all labels must match",
+			filter:   snapshotFilter{Labels: map[string]string{"reason": "upgrade", "ticket": "OPS-2"}},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var matching []elasticsearch.Snapshot
+			for _, snapshot := range snapshots {
+				if tt.filter.Matches(snapshot) {
+					matching = append(matching, snapshot)
+				}
+			}
+			assert.Equal(t, tt.expected, names(matching))
+		})
+	}
+}
+
+func TestSnapshotsTable(t *testing.T) {
+	snapshots := []elasticsearch.Snapshot{
+		{Snapshot: "sts-backup-1", State: "SUCCESS", StartTime: "2025-03-01T03:00:00.000Z", DurationInMillis: 1500, Indices: []string{"sts_a", "sts_b"}},
+		{Snapshot: "sts-backup-2", State: "PARTIAL", StartTime: "2025-03-02T03:00:00.000Z", DurationInMillis: 900, Indices: []string{"sts_a"},
+			Failures: []elasticsearch.SnapshotShardFailure{{Index: "sts_a"}}},
+	}
+
+	t.Run("without details", func(t *testing.T) {
+		table := snapshotsTable(snapshots, nil)
+
+		assert.Equal(t, []string{"SNAPSHOT", "STATE", "START TIME", "DURATION (ms)", "INDICES", "FAILURES"}, table.Headers)
+		assert.Equal(t, []string{"sts-backup-1", "SUCCESS", "2025-03-01T03:00:00.000Z", "1500", "2", "0"}, table.Rows[0])
+		assert.Equal(t, []string{"sts-backup-2", "PARTIAL", "2025-03-02T03:00:00.000Z", "900", "1", "1"}, table.Rows[1])
+	})
+
+	t.Run("with details", func(t *testing.T) {
+		stats := map[string]elasticsearch.SnapshotStats{"sts-backup-1": {Snapshot: "sts-backup-1", TotalSizeInBytes: 2048}}
+
+		table := snapshotsTable(snapshots, stats)
+
+		assert.Equal(t, "SIZE", table.Headers[len(table.Headers)-1])
+		assert.Equal(t, "2.0 KiB", table.Rows[0][6])
+		assert.Equal(t, "-", table.Rows[1][6])
+	})
+
+	t.Run("with metadata", func(t *testing.T) {
+		withMetadata := append(slices.Clone(snapshots), elasticsearch.Snapshot{Snapshot: "manual-1", State: "SUCCESS",
+			Metadata: map[string]interface{}{"taken_by": "alice", "reason": "before upgrade"}})
+
+		table := snapshotsTable(withMetadata, nil)
+
+		assert.Equal(t, "METADATA", table.Headers[len(table.Headers)-1])
+		assert.Equal(t, "-", table.Rows[0][6])
+		assert.Equal(t, "reason=before upgrade, taken_by=alice", table.Rows[2][6])
+	})
+}
@@ -0,0 +1,202 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// reindexPollInterval is the time between progress checks of a reindex task
+const reindexPollInterval = 5 * time.Second
+
+type migratePrefixOptions struct {
+	From              string
+	To                string
+	RequestsPerSecond int
+	DryRun            bool
+}
+
+// prefixMigration copies the documents of the Source index to the Dest index
+type prefixMigration struct {
+	Source string
+	Dest   string
+}
+
+// reindexer copies indices in background tasks and reports their progress
+type reindexer interface {
+	StartReindex(source, dest string, requestsPerSecond int) (string, error)
+	GetReindexStatus(taskID string) (*elasticsearch.ReindexStatus, error)
+}
+
+func migratePrefixCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &migratePrefixOptions{}
+	cmd := &cobra.Command{
+		Use:   "migrate-prefix",
+		Short: "Copy indices to new names with another prefix",
+		Long: `Copy every index whose name starts with --from to an index with the same name, but starting with --to, using the
+reindex API. E.g. --from sts --to suse copies sts_topology to suse_topology. This makes indices restored from
+snapshots of an older release usable by a release that names its indices differently.
+
+Indices are copied one at a time, throttled to --requests-per-second, and the progress is logged. The source
+indices are left in place; remove them once the copies have been checked. The command refuses to run when one
+of the new index names is already taken.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runMigratePrefix(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.From, "from", "", "Prefix of the indices to copy")
+	cmd.Flags().StringVar(&opts.To, "to", "", "Prefix replacing --from in the names of the copies")
+	cmd.Flags().IntVar(&opts.RequestsPerSecond, "requests-per-second", -1, "Throttle the copy to this many requests per second (-1: unthrottled)")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show the indices that would be copied without copying them")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func runMigratePrefix(cliCtx *config.Context, opts *migratePrefixOptions) (err error) {
+	if opts.From == opts.To {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--from and --to are both '%s'", opts.From))
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if !opts.DryRun {
+		defer func() {
+			err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "migrate-prefix"}, err, env.Log)
+		}()
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	sources, err := esClient.ListIndices(opts.From + "*")
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	existing, err := esClient.ListIndices(opts.To + "*")
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	migrations, err := prefixMigrations(sources, existing, opts.From, opts.To)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintTable(prefixMigrationsTable(migrations)); err != nil {
+		return err
+	}
+	if opts.DryRun {
+		env.Log.Infof("Dry run: %d index(es) would be copied", len(migrations))
+		return nil
+	}
+
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := prompter.Confirm(fmt.Sprintf("Copy %d index(es) from prefix '%s' to '%s'?", len(migrations), opts.From, opts.To)); err != nil {
+		return fmt.Errorf("migration aborted: %w", err)
+	}
+
+	for i, migration := range migrations {
+		env.Log.Infof("[%d/%d] Copying '%s' to '%s'...", i+1, len(migrations), migration.Source, migration.Dest)
+		if err := reindexIndex(esClient, migration, opts.RequestsPerSecond, reindexPollInterval, env.Log); err != nil {
+			return err
+		}
+	}
+
+	env.Log.Successf("Copied %d index(es) from prefix '%s' to '%s'; the source indices are left in place", len(migrations), opts.From, opts.To)
+	return nil
+}
+
+// prefixMigrations returns the copies to make of the sources starting with from, sorted by source. Sources that
+// already start with to are skipped, for when to extends from. A copy whose name is in existing is a usage error.
+func prefixMigrations(sources, existing []string, from, to string) ([]prefixMigration, error) {
+	var migrations []prefixMigration
+	var taken []string
+	for _, source := range slices.Sorted(slices.Values(sources)) {
+		if !strings.HasPrefix(source, from) || (strings.HasPrefix(to, from) && strings.HasPrefix(source, to)) {
+			continue
+		}
+		dest := to + strings.TrimPrefix(source, from)
+		if slices.Contains(existing, dest) {
+			taken = append(taken, dest)
+		}
+		migrations = append(migrations, prefixMigration{Source: source, Dest: dest})
+	}
+
+	if len(migrations) == 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("no indices start with '%s'", from))
+	}
+	if len(taken) > 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("indices %s already exist; delete them or choose another prefix", strings.Join(taken, ", ")))
+	}
+	return migrations, nil
+}
+
+// reindexIndex copies one index and logs the progress every interval until the copy has finished
+func reindexIndex(client reindexer, migration prefixMigration, requestsPerSecond int, interval time.Duration, log *logger.Logger) error {
+	taskID, err := client.StartReindex(migration.Source, migration.Dest, requestsPerSecond)
+	if err != nil {
+		return fmt.Errorf("failed to start copying '%s': %w", migration.Source, err)
+	}
+	log.Debugf("Reindex task of '%s': %s", migration.Source, taskID)
+
+	for {
+		status, err := client.GetReindexStatus(taskID)
+		if err != nil {
+			return fmt.Errorf("failed to get the progress of copying '%s' (task %s): %w", migration.Source, taskID, err)
+		}
+		if status.Completed {
+			if len(status.Failures) > 0 {
+				return fmt.Errorf("copying '%s' to '%s' failed with %d failure(s), the first: %s",
+					migration.Source, migration.Dest, len(status.Failures), status.Failures[0])
+			}
+			log.Successf("Copied '%s' to '%s': %s", migration.Source, migration.Dest, reindexProgress(status))
+			return nil
+		}
+		log.Infof("Copying '%s': %s", migration.Source, reindexProgress(status))
+		time.Sleep(interval)
+	}
+}
+
+// reindexProgress describes how many documents of a reindex task have been copied
+func reindexProgress(status *elasticsearch.ReindexStatus) string {
+	copied := status.Created + status.Updated
+	if status.Total == 0 {
+		return fmt.Sprintf("%d document(s)", copied)
+	}
+	return fmt.Sprintf("%d of %d document(s) (%d%%)", copied, status.Total, copied*100/status.Total)
+}
+
+// prefixMigrationsTable lists the indices to copy with the names of their copies
+func prefixMigrationsTable(migrations []prefixMigration) output.Table {
+	table := output.Table{
+		Headers: []string{"SOURCE", "DESTINATION"},
+		Rows:    make([][]string, 0, len(migrations)),
+	}
+	for _, migration := range migrations {
+		table.Rows = append(table.Rows, []string{migration.Source, migration.Dest})
+	}
+	return table
+}
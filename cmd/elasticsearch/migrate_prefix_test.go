@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReindexer struct {
+	started  []prefixMigration
+	statuses []elasticsearch.ReindexStatus
+	startErr error
+}
+
+func (m *mockReindexer) StartReindex(source, dest string, _ int) (string, error) {
+	if m.startErr != nil {
+		return "", m.startErr
+	}
+	m.started = append(m.started, prefixMigration{Source: source, Dest: dest})
+	return "node-1:7", nil
+}
+
+func (m *mockReindexer) GetReindexStatus(_ string) (*elasticsearch.ReindexStatus, error) {
+	status := m.statuses[0]
+	m.statuses = m.statuses[1:]
+	return &status, nil
+}
+
+func TestMigratePrefixCmd_Unit(t *testing.T) {
+	cmd := migratePrefixCmd(config.NewContext())
+
+	assert.Equal(t, "migrate-prefix", cmd.Use)
+	assert.Equal(t, "-1", cmd.Flags().Lookup("requests-per-second").DefValue)
+	assert.Equal(t, "false", cmd.Flags().Lookup("dry-run").DefValue)
+	for _, flag := range []string{"from", "to"} {
+		assert.Equal(t, []string{"true"}, cmd.Flags().Lookup(flag).Annotations["cobra_annotation_bash_completion_one_required_flag"], flag)
+	}
+}
+
+func TestPrefixMigrations(t *testing.T) {
+	tests := []struct {
+		name          string
+		sources       []string
+		existing      []string
+		from          string
+		to            string
+		expected      []prefixMigration
+		expectedError string
+	}{
+		{
+			name:    "renames the prefix",
+			sources: []string{"sts_topology", "sts_metrics"},
+			from:    "sts",
+			to:      "suse",
+			expected: []prefixMigration{
+				{Source: "sts_metrics", Dest: "suse_metrics"},
+				{Source: "sts_topology", Dest: "suse_topology"},
+			},
+		},
+		{
+			name:     "skips indices that already have the new prefix",
+			sources:  []string{"sts_topology", "sts2_topology"},
+			from:     "sts",
+			to:       "sts2",
+			expected: []prefixMigration{{Source: "sts_topology", Dest: "sts2_topology"}},
+		},
+		{
+			name:          "no matching indices",
+			from:          "sts",
+			to:            "suse",
+			expectedError: "no indices start with 'sts'",
+		},
+		{
+			name:          "copy already exists",
+			sources:       []string{"sts_topology", "sts_metrics"},
+			existing:      []string{"suse_topology"},
+			from:          "sts",
+			to:            "suse",
+			expectedError: "indices suse_topology already exist",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := prefixMigrations(tt.sources, tt.existing, tt.from, tt.to)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, migrations)
+		})
+	}
+}
+
+func TestReindexIndex(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	migration := prefixMigration{Source: "sts_topology", Dest: "suse_topology"}
+
+	t.Run("waits until completed", func(t *testing.T) {
+		client := &mockReindexer{statuses: []elasticsearch.ReindexStatus{
+			{Total: 100, Created: 40},
+			{Completed: true, Total: 100, Created: 100},
+		}}
+		require.NoError(t, reindexIndex(client, migration, -1, 0, log))
+		assert.Equal(t, []prefixMigration{migration}, client.started)
+		assert.Empty(t, client.statuses)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		client := &mockReindexer{statuses: []elasticsearch.ReindexStatus{
+			{Completed: true, Total: 100, Created: 98, Failures: []string{"mapper_parsing_exception", "mapper_parsing_exception"}},
+		}}
+		err := reindexIndex(client, migration, -1, 0, log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed with 2 failure(s), the first: mapper_parsing_exception")
+	})
+
+	t.Run("start fails", func(t *testing.T) {
+		client := &mockReindexer{startErr: errors.New("boom")}
+		err := reindexIndex(client, migration, -1, 0, log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to start copying 'sts_topology': boom")
+	})
+}
+
+func TestReindexProgress(t *testing.T) {
+	assert.Equal(t, "40 of 200 document(s) (20%)", reindexProgress(&elasticsearch.ReindexStatus{Total: 200, Created: 30, Updated: 10}))
+	assert.Equal(t, "0 document(s)", reindexProgress(&elasticsearch.ReindexStatus{}))
+}
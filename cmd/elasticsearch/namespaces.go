@@ -0,0 +1,115 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	configcmd "github.com/stackvista/stackstate-backup-cli/cmd/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+)
+
+// namespacesOptions holds the flags running a read-only command against the installations in several namespaces
+type namespacesOptions struct {
+	Namespaces []string
+	// AllConfigured runs against every namespace holding the backup configuration, as found by config discover
+	AllConfigured bool
+}
+
+// Enabled reports whether the command runs against several namespaces instead of --namespace
+func (o *namespacesOptions) Enabled() bool {
+	return len(o.Namespaces) > 0 || o.AllConfigured
+}
+
+// addNamespacesFlags adds --namespaces and --all-configured-namespaces, which make --namespace optional
+func addNamespacesFlags(cmd *cobra.Command, opts *namespacesOptions) {
+	cmd.Flags().StringSliceVar(&opts.Namespaces, "namespaces", nil, "Run against the installations in these namespaces, e.g. ns1,ns2, aggregating the results")
+	cmd.Flags().BoolVar(&opts.AllConfigured, "all-configured-namespaces", false, "Run against every namespace holding the backup configuration (see config discover)")
+	cmd.MarkFlagsMutuallyExclusive("namespaces", "all-configured-namespaces")
+	// Required flags are validated after PreRun, so --namespace can still be made optional here
+	cmd.PreRun = func(cmd *cobra.Command, _ []string) {
+		if opts.Enabled() {
+			_ = cmd.Flags().SetAnnotation("namespace", cobra.BashCompOneRequiredFlag, []string{"false"})
+		}
+	}
+}
+
+// resolveNamespaces returns the namespaces to run against: those of --namespaces, or with --all-configured-namespaces
+// the namespaces whose backup ConfigMap holds the configuration. Combining them with --namespace is an exitcode.Usage
+// error, as is a --namespaces list without a namespace.
+func resolveNamespaces(cmd *cobra.Command, cliCtx *config.Context, opts *namespacesOptions, log *logger.Logger) ([]string, error) {
+	if cmd.Flags().Changed("namespace") {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--namespace cannot be combined with --namespaces or --all-configured-namespaces"))
+	}
+
+	if !opts.AllConfigured {
+		var namespaces []string
+		for _, namespace := range opts.Namespaces {
+			if namespace = strings.TrimSpace(namespace); namespace != "" && !slices.Contains(namespaces, namespace) {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+		if len(namespaces) == 0 {
+			return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--namespaces needs at least one namespace"))
+		}
+		return namespaces, nil
+	}
+
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+	all, err := k8sClient.ListNamespaces()
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	namespaces, skipped, err := configcmd.ConfiguredNamespaces(k8sClient.Clientset(), all, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	if len(skipped) > 0 {
+		log.Warningf("Skipped %d namespaces that are not accessible: %v", len(skipped), skipped)
+	}
+	if len(namespaces) == 0 {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("no namespace holds the backup configuration ConfigMap '%s'", cliCtx.Config.ConfigMapName))
+	}
+	log.Infof("Found the backup configuration in %d namespace(s): %s", len(namespaces), strings.Join(namespaces, ", "))
+	return namespaces, nil
+}
+
+// namespaceContext returns a copy of cliCtx targeting the installation in namespace, with the same run ID
+func namespaceContext(cliCtx *config.Context, namespace string) *config.Context {
+	cliConfig := *cliCtx.Config
+	cliConfig.Namespace = namespace
+	return &config.Context{Config: &cliConfig, RunID: cliCtx.RunID}
+}
+
+// namespaceErrors combines the failures of the namespaces a command ran against; the exit code is that of the first
+// failure
+func namespaceErrors(errs []error, total int) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d namespace(s) failed:\n%w", len(errs), total, errors.Join(errs...))
+}
+
+// namespaceTable returns table with a NAMESPACE column in front, for the rows of namespaceRows
+func namespaceTable(table output.Table) output.Table {
+	table.Headers = append([]string{"NAMESPACE"}, table.Headers...)
+	return table
+}
+
+// namespaceRows prepends namespace to every row
+func namespaceRows(namespace string, rows [][]string) [][]string {
+	prefixed := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		prefixed = append(prefixed, append([]string{namespace}, row...))
+	}
+	return prefixed
+}
@@ -0,0 +1,105 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namespacesTestCmd returns a command with --namespace, as the elasticsearch commands inherit it, and the namespaces flags
+func namespacesTestCmd(opts *namespacesOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("namespace", "", "")
+	addNamespacesFlags(cmd, opts)
+	return cmd
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		expected     []string
+		expectedCode int
+	}{
+		{
+			name:     "deduplicated and trimmed",
+			args:     []string{"--namespaces", "obs-a, obs-b,obs-a,"},
+			expected: []string{"obs-a", "obs-b"},
+		},
+		{
+			name:         "without namespace",
+			args:         []string{"--namespaces", ","},
+			expectedCode: exitcode.Usage,
+		},
+		{
+			name:         "combined with --namespace",
+			args:         []string{"--namespace", "obs-a", "--namespaces", "obs-b"},
+			expectedCode: exitcode.Usage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts namespacesOptions
+			cmd := namespacesTestCmd(&opts)
+			require.NoError(t, cmd.ParseFlags(tt.args))
+
+			namespaces, err := resolveNamespaces(cmd, config.NewContext(), &opts, logger.New(logger.LevelError, ""))
+			if tt.expectedCode != 0 {
+				assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, namespaces)
+		})
+	}
+}
+
+func TestAddNamespacesFlags_NamespaceOptional(t *testing.T) {
+	var opts namespacesOptions
+	cmd := namespacesTestCmd(&opts)
+	require.NoError(t, cmd.MarkFlagRequired("namespace"))
+	require.NoError(t, cmd.ParseFlags([]string{"--all-configured-namespaces"}))
+
+	cmd.PreRun(cmd, nil)
+	assert.True(t, opts.Enabled())
+	assert.NoError(t, cmd.ValidateRequiredFlags())
+}
+
+func TestNamespaceContext(t *testing.T) {
+	cliCtx := config.NewContext()
+	cliCtx.Config.Namespace = "obs-a"
+	cliCtx.RunID = "run-1"
+
+	nsCtx := namespaceContext(cliCtx, "obs-b")
+	assert.Equal(t, "obs-b", nsCtx.Config.Namespace)
+	assert.Equal(t, "run-1", nsCtx.RunID)
+	assert.Equal(t, "obs-a", cliCtx.Config.Namespace)
+}
+
+func TestNamespaceErrors(t *testing.T) {
+	assert.NoError(t, namespaceErrors(nil, 2))
+
+	err := namespaceErrors([]error{
+		fmt.Errorf("namespace 'obs-a': %w", exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("stale"))),
+		fmt.Errorf("namespace 'obs-b': %w", exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("unreachable"))),
+	}, 3)
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+	assert.ErrorContains(t, err, "2 of 3 namespace(s) failed")
+	assert.ErrorContains(t, err, "namespace 'obs-b': unreachable")
+}
+
+func TestNamespaceTable(t *testing.T) {
+	table := namespaceTable(output.Table{Headers: []string{"SNAPSHOT", "STATE"}, StateColumns: []string{"STATE"}})
+	table.Rows = append(table.Rows, namespaceRows("obs-a", [][]string{{"sts-backup-1", "SUCCESS"}})...)
+
+	assert.Equal(t, []string{"NAMESPACE", "SNAPSHOT", "STATE"}, table.Headers)
+	assert.Equal(t, []string{"STATE"}, table.StateColumns)
+	assert.Equal(t, [][]string{{"obs-a", "sts-backup-1", "SUCCESS"}}, table.Rows)
+}
@@ -0,0 +1,226 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// pipelinePutter creates or replaces ingest pipelines
+type pipelinePutter interface {
+	PutIngestPipeline(name string, definition json.RawMessage) error
+}
+
+func exportPipelinesCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "export-pipelines",
+		Short: "Export all ingest pipelines to the backup bucket",
+		Long: `Export the definitions of all ingest pipelines as a JSON artifact under exports/elasticsearch/pipelines/ in the
+backup bucket. Ingest pipelines are not part of index snapshots; without them log enrichment breaks after a recovery.
+
+The artifact is encrypted when archives.encryption is configured. Import it with 'import-pipelines' or
+'restore-snapshot --import-pipelines'.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runExportPipelines(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func importPipelinesCmd(cliCtx *config.Context) *cobra.Command {
+	var key string
+	cmd := &cobra.Command{
+		Use:   "import-pipelines",
+		Short: "Import ingest pipelines from the backup bucket",
+		Long: `Import ingest pipelines exported with 'export-pipelines', replacing existing pipelines of the same name.
+Pipelines that are not part of the export are left unchanged. The most recent export is imported unless --key is given.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runImportPipelines(cliCtx, key); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&key, "key", "", "Object key of the export to import (default: the most recent export)")
+	return cmd
+}
+
+func runExportPipelines(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	store, cleanup, err := target.OpenExportStore(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	esClient, closeES, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer closeES()
+
+	env.Log.Infof("Fetching ingest pipelines...")
+	pipelines, err := esClient.GetIngestPipelines()
+	if err != nil {
+		return err
+	}
+	if len(pipelines) == 0 {
+		env.Log.Warningf("No ingest pipelines found, nothing to export")
+		return nil
+	}
+
+	data, err := json.Marshal(pipelines)
+	if err != nil {
+		return fmt.Errorf("failed to encode ingest pipelines: %w", err)
+	}
+	key, err := store.Put(&export.Artifact{
+		Kind:       export.KindPipelines,
+		ExportedAt: time.Now(),
+		Namespace:  cliCtx.Config.Namespace,
+		RunID:      cliCtx.RunID,
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	env.Log.Successf("Exported %d ingest pipeline(s) to '%s'", len(pipelines), key)
+	return nil
+}
+
+func runImportPipelines(cliCtx *config.Context, key string) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "import-pipelines"}, err, env.Log)
+	}()
+
+	pipelines, err := loadPipelines(env.K8s, cliCtx, env.Config, key, env.Log)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintTable(pipelinesTable(pipelines)); err != nil {
+		return err
+	}
+
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := prompter.Confirm(fmt.Sprintf("Import %d ingest pipeline(s), replacing existing pipelines of the same name?", len(pipelines))); err != nil {
+		return fmt.Errorf("import aborted: %w", err)
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return importPipelines(esClient, pipelines, env.Log)
+}
+
+// loadPipelines reads the ingest pipelines of the export stored under key, or of the most recent export
+// when key is empty
+func loadPipelines(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, key string, log *logger.Logger) (map[string]json.RawMessage, error) {
+	store, cleanup, err := target.OpenExportStore(k8sClient, cliCtx, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	var artifact *export.Artifact
+	if key == "" {
+		log.Infof("Fetching most recent ingest pipeline export...")
+		key, artifact, err = store.Latest(export.KindPipelines)
+	} else {
+		log.Infof("Fetching ingest pipeline export '%s'...", key)
+		artifact, err = store.Get(key)
+	}
+	if errors.Is(err, export.ErrNotFound) {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%w: run 'export-pipelines' first", err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Kind != export.KindPipelines {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is an export of %s, not of ingest pipelines", key, artifact.Kind))
+	}
+
+	var pipelines map[string]json.RawMessage
+	if err := json.Unmarshal(artifact.Data, &pipelines); err != nil {
+		return nil, fmt.Errorf("failed to decode ingest pipelines of '%s': %w", key, err)
+	}
+	log.Infof("Export '%s' from %s contains %d ingest pipeline(s)", key, artifact.ExportedAt.Local().Format(time.RFC3339), len(pipelines))
+	return pipelines, nil
+}
+
+// importPipelines puts every pipeline in name order. A failed pipeline does not stop the others;
+// all failures are returned together.
+func importPipelines(esClient pipelinePutter, pipelines map[string]json.RawMessage, log *logger.Logger) error {
+	var failed []error
+	for _, name := range sortedPipelineNames(pipelines) {
+		log.Debugf("Importing ingest pipeline '%s'", name)
+		if err := esClient.PutIngestPipeline(name, pipelines[name]); err != nil {
+			failed = append(failed, fmt.Errorf("pipeline %s: %w", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to import %d of %d ingest pipeline(s): %w", len(failed), len(pipelines), errors.Join(failed...))
+	}
+
+	log.Successf("Imported %d ingest pipeline(s)", len(pipelines))
+	return nil
+}
+
+// pipelinesTable lists the pipelines of an export with their description
+func pipelinesTable(pipelines map[string]json.RawMessage) output.Table {
+	table := output.Table{
+		Headers: []string{"PIPELINE", "PROCESSORS", "DESCRIPTION"},
+		Rows:    make([][]string, 0, len(pipelines)),
+	}
+
+	for _, name := range sortedPipelineNames(pipelines) {
+		var definition struct {
+			Description string            `json:"description"`
+			Processors  []json.RawMessage `json:"processors"`
+		}
+		// Definitions that cannot be summarized are still imported as they are
+		_ = json.Unmarshal(pipelines[name], &definition)
+		table.Rows = append(table.Rows, []string{name, fmt.Sprintf("%d", len(definition.Processors)), definition.Description})
+	}
+
+	return table
+}
+
+func sortedPipelineNames(pipelines map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(pipelines))
+	for name := range pipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,81 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPipelinePutter struct {
+	imported []string
+	failOn   string
+}
+
+func (m *mockPipelinePutter) PutIngestPipeline(name string, _ json.RawMessage) error {
+	if name == m.failOn {
+		return errors.New("invalid processor")
+	}
+	m.imported = append(m.imported, name)
+	return nil
+}
+
+func testPipelines() map[string]json.RawMessage {
+	return map[string]json.RawMessage{
+		"sts-logs":   json.RawMessage(`{"description": "Enrich logs", "processors": [{"set": {}}, {"rename": {}}]}`),
+		"sts-events": json.RawMessage(`{"processors": []}`),
+		"custom":     json.RawMessage(`{"description": "Custom"}`),
+	}
+}
+
+// TestPipelinesCmd_Unit tests the command structure
+func TestPipelinesCmd_Unit(t *testing.T) {
+	exportCmd := exportPipelinesCmd(config.NewContext())
+	assert.Equal(t, "export-pipelines", exportCmd.Use)
+	assert.NotEmpty(t, exportCmd.Long)
+	assert.NotNil(t, exportCmd.Run)
+
+	importCmd := importPipelinesCmd(config.NewContext())
+	assert.Equal(t, "import-pipelines", importCmd.Use)
+	assert.NotNil(t, importCmd.Run)
+	assert.NotNil(t, importCmd.Flags().Lookup("key"))
+}
+
+func TestImportPipelines(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("imports all in name order", func(t *testing.T) {
+		putter := &mockPipelinePutter{}
+
+		err := importPipelines(putter, testPipelines(), log)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"custom", "sts-events", "sts-logs"}, putter.imported)
+	})
+
+	t.Run("continues after a failure", func(t *testing.T) {
+		putter := &mockPipelinePutter{failOn: "sts-events"}
+
+		err := importPipelines(putter, testPipelines(), log)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to import 1 of 3 ingest pipeline(s)")
+		assert.Contains(t, err.Error(), "pipeline sts-events")
+		assert.Equal(t, []string{"custom", "sts-logs"}, putter.imported)
+	})
+}
+
+func TestPipelinesTable(t *testing.T) {
+	table := pipelinesTable(testPipelines())
+
+	assert.Equal(t, []string{"PIPELINE", "PROCESSORS", "DESCRIPTION"}, table.Headers)
+	assert.Equal(t, [][]string{
+		{"custom", "0", "Custom"},
+		{"sts-events", "0", ""},
+		{"sts-logs", "2", "Enrich logs"},
+	}, table.Rows)
+}
@@ -0,0 +1,80 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// statefulSetHealthChecker reports the health of the StatefulSets running the pods of a service
+type statefulSetHealthChecker interface {
+	ServiceStatefulSetHealth(namespace, serviceName string) ([]k8s.StatefulSetHealth, error)
+}
+
+// addSkipHealthCheckFlag adds the --skip-health-check flag to a command gated by checkElasticsearchPods
+func addSkipHealthCheckFlag(cmd *cobra.Command, cliCtx *config.Context) {
+	cmd.Flags().BoolVar(&cliCtx.Config.SkipHealthCheck, "skip-health-check", false,
+		"Run even when Elasticsearch pods are not Ready or are crash looping")
+}
+
+// checkElasticsearchPods fails early when the Elasticsearch StatefulSets do not have all replicas Ready or have
+// pods in CrashLoopBackOff, with a report of what is wrong, instead of the operation timing out later through
+// the port-forward. Both service.name and service.masterName are checked.
+func checkElasticsearchPods(client statefulSetHealthChecker, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) error {
+	if cliCtx.Config.SkipHealthCheck {
+		log.Warningf("Skipping the Elasticsearch pod health check")
+		return nil
+	}
+
+	log.Infof("Checking Elasticsearch pods...")
+	service := cfg.Elasticsearch.Service
+	checked := make(map[string]bool)
+	var problems []string
+	for _, serviceName := range []string{service.Name, service.MasterName} {
+		if serviceName == "" {
+			continue
+		}
+		health, err := client.ServiceStatefulSetHealth(cliCtx.Config.Namespace, serviceName)
+		if err != nil {
+			return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to check Elasticsearch pods of service %s: %w", serviceName, err))
+		}
+		for _, sts := range health {
+			if checked[sts.Name] {
+				continue
+			}
+			checked[sts.Name] = true
+			if problem := statefulSetProblem(sts); problem != "" {
+				log.Errorf("%s", problem)
+				problems = append(problems, problem)
+			} else {
+				log.Debugf("StatefulSet %s has %d/%d replicas Ready", sts.Name, sts.ReadyReplicas, sts.Replicas)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return exitcode.Wrap(exitcode.ValidationFailed,
+			fmt.Errorf("elasticsearch is %w: %s (use --skip-health-check to run anyway)", k8s.ErrClusterUnhealthy, strings.Join(problems, "; ")))
+	}
+	if len(checked) == 0 {
+		log.Warningf("No Elasticsearch StatefulSet found behind service %s, pod health not checked", service.Name)
+	}
+	return nil
+}
+
+// statefulSetProblem describes what is unhealthy about a StatefulSet, or returns an empty string when it is healthy
+func statefulSetProblem(sts k8s.StatefulSetHealth) string {
+	if sts.Healthy() {
+		return ""
+	}
+	problem := fmt.Sprintf("StatefulSet %s has %d/%d replicas Ready", sts.Name, sts.ReadyReplicas, sts.Replicas)
+	if len(sts.CrashLoopingPods) > 0 {
+		problem += fmt.Sprintf(", pods in CrashLoopBackOff: %s", strings.Join(sts.CrashLoopingPods, ", "))
+	}
+	return problem
+}
@@ -0,0 +1,93 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHealthChecker struct {
+	health map[string][]k8s.StatefulSetHealth
+	err    error
+}
+
+func (m *mockHealthChecker) ServiceStatefulSetHealth(_, serviceName string) ([]k8s.StatefulSetHealth, error) {
+	return m.health[serviceName], m.err
+}
+
+func TestCheckElasticsearchPods(t *testing.T) {
+	healthy := k8s.StatefulSetHealth{Name: "es-master", Replicas: 3, ReadyReplicas: 3}
+
+	tests := []struct {
+		name            string
+		masterName      string
+		skip            bool
+		checker         *mockHealthChecker
+		expectedCode    int
+		expectedMessage string
+	}{
+		{
+			name:    "all replicas ready",
+			checker: &mockHealthChecker{health: map[string][]k8s.StatefulSetHealth{"es": {healthy}}},
+		},
+		{
+			name: "replicas not ready",
+			checker: &mockHealthChecker{health: map[string][]k8s.StatefulSetHealth{
+				"es": {{Name: "es-master", Replicas: 3, ReadyReplicas: 1}},
+			}},
+			expectedCode:    exitcode.ValidationFailed,
+			expectedMessage: "StatefulSet es-master has 1/3 replicas Ready",
+		},
+		{
+			name: "crash looping pods on the master service",
+			checker: &mockHealthChecker{health: map[string][]k8s.StatefulSetHealth{
+				"es":        {healthy},
+				"es-master": {{Name: "es-data", Replicas: 2, ReadyReplicas: 2, CrashLoopingPods: []string{"es-data-1"}}},
+			}},
+			masterName:      "es-master",
+			expectedCode:    exitcode.ValidationFailed,
+			expectedMessage: "pods in CrashLoopBackOff: es-data-1",
+		},
+		{
+			name: "skipped",
+			skip: true,
+			checker: &mockHealthChecker{health: map[string][]k8s.StatefulSetHealth{
+				"es": {{Name: "es-master", Replicas: 3, ReadyReplicas: 0}},
+			}},
+		},
+		{
+			name:    "no statefulset",
+			checker: &mockHealthChecker{},
+		},
+		{
+			name:            "service not found",
+			checker:         &mockHealthChecker{err: errors.New("service not found")},
+			expectedCode:    exitcode.ConnectivityError,
+			expectedMessage: "service not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliCtx := &config.Context{Config: &config.CLIConfig{Namespace: "test-ns", SkipHealthCheck: tt.skip}}
+			cfg := &config.Config{Elasticsearch: config.ElasticsearchConfig{Service: config.ServiceConfig{Name: "es", MasterName: tt.masterName}}}
+
+			err := checkElasticsearchPods(tt.checker, cliCtx, cfg, logger.New(logger.LevelError, ""))
+
+			if tt.expectedCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+			assert.Equal(t, tt.expectedCode == exitcode.ValidationFailed, errors.Is(err, k8s.ErrClusterUnhealthy))
+			assert.Contains(t, err.Error(), tt.expectedMessage)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// snapshotRecoveryType is the recovery type of a shard restored from a snapshot
+const snapshotRecoveryType = "snapshot"
+
+type recoveryStatusOptions struct {
+	All bool
+}
+
+func recoveryStatusCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &recoveryStatusOptions{}
+	cmd := &cobra.Command{
+		Use:   "recovery-status",
+		Short: "Show the shard recoveries in progress",
+		Long: `Show the shard recoveries Elasticsearch is running, with their stage, progress and source: the snapshot a shard is
+restored from, or the node it is copied from. This covers every recovery, including restores not started by this
+CLI, so it can be run repeatedly (e.g. with watch) to follow an ongoing restore.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRecoveryStatus(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Include completed recoveries")
+	return cmd
+}
+
+func runRecoveryStatus(cliCtx *config.Context, opts *recoveryStatusOptions) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env.Log.Infof("Fetching shard recoveries...")
+	recoveries, err := esClient.ListRecoveries(!opts.All)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list recoveries: %w", err))
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(recoveries) == 0 {
+		if opts.All {
+			formatter.PrintMessage("No recoveries found")
+		} else {
+			formatter.PrintMessage("No recoveries in progress")
+		}
+		return nil
+	}
+	return formatter.PrintTable(recoveryTable(recoveries))
+}
+
+// recoveryTable lists the recoveries sorted by index and shard
+func recoveryTable(recoveries []elasticsearch.RecoveryInfo) output.Table {
+	sorted := append([]elasticsearch.RecoveryInfo{}, recoveries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Index != sorted[j].Index {
+			return sorted[i].Index < sorted[j].Index
+		}
+		return shardNumber(sorted[i].Shard) < shardNumber(sorted[j].Shard)
+	})
+
+	table := output.Table{
+		Headers: []string{"INDEX", "SHARD", "TYPE", "STAGE", "PERCENT", "SIZE", "SOURCE", "TARGET", "TIME"},
+		Rows:    make([][]string, 0, len(sorted)),
+	}
+	for _, recovery := range sorted {
+		table.Rows = append(table.Rows, []string{
+			recovery.Index,
+			recovery.Shard,
+			recovery.Type,
+			recovery.Stage,
+			recovery.BytesPercent,
+			recovery.BytesTotal,
+			recoverySource(recovery),
+			recovery.TargetNode,
+			recovery.Time,
+		})
+	}
+	return table
+}
+
+// recoverySource describes where a shard is recovered from: repository/snapshot for a restore, else the source node
+func recoverySource(recovery elasticsearch.RecoveryInfo) string {
+	if recovery.Type == snapshotRecoveryType {
+		return recovery.Repository + "/" + recovery.Snapshot
+	}
+	return recovery.SourceNode
+}
+
+// shardNumber parses a shard number for sorting; shards that are not a number sort first
+func shardNumber(shard string) int {
+	n, err := strconv.Atoi(shard)
+	if err != nil {
+		return -1
+	}
+	return n
+}
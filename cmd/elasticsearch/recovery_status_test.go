@@ -0,0 +1,34 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryStatusCmd_Unit(t *testing.T) {
+	cmd := recoveryStatusCmd(config.NewContext())
+
+	assert.Equal(t, "recovery-status", cmd.Use)
+	assert.Equal(t, "false", cmd.Flags().Lookup("all").DefValue)
+	assert.NotNil(t, cmd.Run)
+}
+
+func TestRecoveryTable(t *testing.T) {
+	recoveries := []elasticsearch.RecoveryInfo{
+		{Index: "sts_topology", Shard: "10", Type: "peer", Stage: "translog", SourceNode: "es-data-0", TargetNode: "es-data-1", BytesPercent: "100.0%", BytesTotal: "2gb", Time: "3m"},
+		{Index: "sts_topology", Shard: "2", Type: "snapshot", Stage: "index", Repository: "sts-backup", Snapshot: "snap-1", TargetNode: "es-data-0", BytesPercent: "42.5%", BytesTotal: "1gb", Time: "1.2s"},
+		{Index: "sts_metrics", Shard: "0", Type: "existing_store", Stage: "done", SourceNode: "n/a", TargetNode: "es-data-0", BytesPercent: "0.0%", BytesTotal: "0b", Time: "5ms"},
+	}
+
+	table := recoveryTable(recoveries)
+
+	assert.Equal(t, [][]string{
+		{"sts_metrics", "0", "existing_store", "done", "0.0%", "0b", "n/a", "es-data-0", "5ms"},
+		{"sts_topology", "2", "snapshot", "index", "42.5%", "1gb", "sts-backup/snap-1", "es-data-0", "1.2s"},
+		{"sts_topology", "10", "peer", "translog", "100.0%", "2gb", "es-data-0", "es-data-1", "3m"},
+	}, table.Rows)
+	assert.Equal(t, "sts_topology", recoveries[0].Index, "input is not reordered")
+}
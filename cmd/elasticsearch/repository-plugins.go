@@ -0,0 +1,83 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+)
+
+// builtinRepositoryModulesMajor is the Elasticsearch major version since which the repository types are
+// built-in modules instead of plugins
+const builtinRepositoryModulesMajor = 8
+
+// repositoryPlugins maps snapshot repository types to the plugin providing them
+var repositoryPlugins = map[string]string{
+	"s3":    "repository-s3",
+	"gcs":   "repository-gcs",
+	"azure": "repository-azure",
+}
+
+// pluginInspector reports the Elasticsearch version and installed plugins
+type pluginInspector interface {
+	Version() (string, error)
+	ListPlugins() ([]elasticsearch.Plugin, error)
+}
+
+// explainRepositoryError replaces an error about a missing repository type with guidance on how to
+// make it available, based on the Elasticsearch version and the installed plugins. Other errors are
+// returned unchanged.
+func explainRepositoryError(client pluginInspector, err error) error {
+	var typeErr *elasticsearch.RepositoryTypeError
+	if !errors.As(err, &typeErr) {
+		return err
+	}
+
+	plugin := repositoryPlugins[typeErr.Type]
+	if plugin == "" {
+		plugin = "repository-" + typeErr.Type
+	}
+
+	var guidance string
+	version, versionErr := client.Version()
+	major, _, _ := strings.Cut(version, ".")
+	switch majorVersion, parseErr := strconv.Atoi(major); {
+	case versionErr != nil || parseErr != nil:
+		guidance = fmt.Sprintf("Install the %s plugin on every Elasticsearch node ('bin/elasticsearch-plugin install %s') and restart the nodes.", plugin, plugin)
+	case majorVersion >= builtinRepositoryModulesMajor:
+		guidance = fmt.Sprintf("Since Elasticsearch 8.0 %s is a built-in module, but Elasticsearch %s runs without it: "+
+			"use an Elasticsearch image that includes the %s module.", plugin, version, plugin)
+	default:
+		guidance = fmt.Sprintf("Install the %s plugin on every Elasticsearch node ('bin/elasticsearch-plugin install %s'; the plugin "+
+			"version must match Elasticsearch %s) and restart the nodes.", plugin, plugin, version)
+	}
+
+	return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("%w\n%s\n%s", typeErr, guidance, installedPlugins(client)))
+}
+
+// installedPlugins describes the plugins installed in the cluster
+func installedPlugins(client pluginInspector) string {
+	plugins, err := client.ListPlugins()
+	if err != nil {
+		return fmt.Sprintf("Installed plugins could not be listed: %v", err)
+	}
+
+	components := map[string]bool{}
+	for _, plugin := range plugins {
+		components[plugin.Component] = true
+	}
+	if len(components) == 0 {
+		return "No plugins are installed."
+	}
+
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "Installed plugins: " + strings.Join(names, ", ")
+}
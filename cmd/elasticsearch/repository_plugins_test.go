@@ -0,0 +1,90 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPluginInspector struct {
+	version    string
+	versionErr error
+	plugins    []elasticsearch.Plugin
+	pluginsErr error
+}
+
+func (m *mockPluginInspector) Version() (string, error) {
+	return m.version, m.versionErr
+}
+
+func (m *mockPluginInspector) ListPlugins() ([]elasticsearch.Plugin, error) {
+	return m.plugins, m.pluginsErr
+}
+
+func TestExplainRepositoryError(t *testing.T) {
+	typeErr := fmt.Errorf("failed to create repository: %w", &elasticsearch.RepositoryTypeError{Type: "s3", Reason: "repository type [s3] does not exist"})
+	icu := []elasticsearch.Plugin{
+		{Node: "es-0", Component: "analysis-icu", Version: "7.17.0"},
+		{Node: "es-1", Component: "analysis-icu", Version: "7.17.0"},
+	}
+
+	tests := []struct {
+		name     string
+		client   *mockPluginInspector
+		err      error
+		contains []string
+	}{
+		{
+			name:   "plugin missing before 8.0",
+			client: &mockPluginInspector{version: "7.17.0", plugins: icu},
+			err:    typeErr,
+			contains: []string{
+				"'bin/elasticsearch-plugin install repository-s3'",
+				"must match Elasticsearch 7.17.0",
+				"Installed plugins: analysis-icu",
+			},
+		},
+		{
+			name:   "module missing since 8.0",
+			client: &mockPluginInspector{version: "8.19.0"},
+			err:    typeErr,
+			contains: []string{
+				"repository-s3 is a built-in module, but Elasticsearch 8.19.0 runs without it",
+				"No plugins are installed.",
+			},
+		},
+		{
+			name:   "version and plugins unknown",
+			client: &mockPluginInspector{versionErr: errors.New("forbidden"), pluginsErr: errors.New("forbidden")},
+			err:    typeErr,
+			contains: []string{
+				"'bin/elasticsearch-plugin install repository-s3'",
+				"Installed plugins could not be listed: forbidden",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := explainRepositoryError(tt.client, tt.err)
+
+			require.Error(t, err)
+			assert.Equal(t, exitcode.ConfigError, exitcode.Of(err))
+			assert.Contains(t, err.Error(), "snapshot repository type s3 is not available")
+			for _, s := range tt.contains {
+				assert.Contains(t, err.Error(), s)
+			}
+		})
+	}
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		err := errors.New("elasticsearch returned error: [500 Internal Server Error] boom")
+
+		assert.Same(t, err, explainRepositoryError(&mockPluginInspector{}, err))
+	})
+}
@@ -0,0 +1,65 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+const (
+	// settingAllocationEnable controls which shards may be allocated; restored shards stay unassigned unless it is "all"
+	settingAllocationEnable = "cluster.routing.allocation.enable"
+	// settingRebalanceEnable controls which shards may be moved between nodes to balance the cluster
+	settingRebalanceEnable = "cluster.routing.rebalance.enable"
+)
+
+// clusterSettingsClient reads and updates cluster settings
+type clusterSettingsClient interface {
+	GetClusterSettings() (*elasticsearch.ClusterSettings, error)
+	PutClusterSettings(persistent map[string]interface{}) error
+}
+
+// checkShardAllocation warns when shard allocation is restricted, which keeps restored shards unassigned
+func checkShardAllocation(esClient clusterSettingsClient, log *logger.Logger) {
+	settings, err := esClient.GetClusterSettings()
+	if err != nil {
+		log.Warningf("Could not check shard allocation settings: %v", err)
+		return
+	}
+	if value, ok := settings.Get(settingAllocationEnable); ok && value != "all" {
+		log.Warningf("Shard allocation is restricted (%s: %v): restored shards may not be assigned until it is set to 'all'",
+			settingAllocationEnable, value)
+	}
+}
+
+// disableRebalance sets cluster.routing.rebalance.enable to none so the cluster does not move shards while they
+// are recovered. The returned function restores the previous persistent value, or resets the setting if unset.
+func disableRebalance(esClient clusterSettingsClient, log *logger.Logger) (func(), error) {
+	settings, err := esClient.GetClusterSettings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shard rebalancing setting: %w", err)
+	}
+	if value, ok := settings.Transient[settingRebalanceEnable]; ok {
+		log.Warningf("Transient %s is set to %v and overrides the persistent setting changed during the restore", settingRebalanceEnable, value)
+	}
+	previous, wasSet := settings.Persistent[settingRebalanceEnable]
+
+	log.Infof("Disabling shard rebalancing during restore...")
+	if err := esClient.PutClusterSettings(map[string]interface{}{settingRebalanceEnable: "none"}); err != nil {
+		return nil, fmt.Errorf("failed to disable shard rebalancing: %w", err)
+	}
+	log.Successf("Shard rebalancing disabled")
+
+	return func() {
+		var value interface{}
+		if wasSet {
+			value = previous
+		}
+		if err := esClient.PutClusterSettings(map[string]interface{}{settingRebalanceEnable: value}); err != nil {
+			log.Warningf("Failed to re-enable shard rebalancing, reset %s manually: %v", settingRebalanceEnable, err)
+			return
+		}
+		log.Successf("Shard rebalancing re-enabled")
+	}, nil
+}
@@ -0,0 +1,218 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/checkpoint"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+)
+
+// Phases of a restore recorded in its checkpoint. Scaling down is not a phase: a failed run scales the deployments
+// back up when it exits, so a resumed run always scales them down again.
+const (
+	phaseDeleteIndices   = "delete-indices"
+	phaseImportILM       = "import-ilm"
+	phaseRestore         = "restore"
+	phaseImportPipelines = "import-pipelines"
+)
+
+// checkpointStore loads, saves and removes checkpoints
+type checkpointStore interface {
+	Load(runID string) (*checkpoint.Checkpoint, error)
+	Save(checkpoint *checkpoint.Checkpoint) error
+	Delete(runID string) error
+}
+
+// restoreCheckpoint keeps the checkpoint of a restore up to date, so that 'restore-snapshot --resume' can skip the
+// phases it completed. Failing to write the checkpoint only warns, it does not fail the restore. A nil
+// restoreCheckpoint records nothing, e.g. for the restore of a rollback.
+type restoreCheckpoint struct {
+	store checkpointStore
+	state *checkpoint.Checkpoint
+	// resumed is set when the checkpoint was written by an earlier run
+	resumed bool
+	// saved is set once the checkpoint has been written, i.e. the restore changed something
+	saved bool
+	log   *logger.Logger
+}
+
+// newRestoreCheckpoint starts the checkpoint of a new restore run
+func newRestoreCheckpoint(store checkpointStore, runID string, opts *restoreOptions, log *logger.Logger) *restoreCheckpoint {
+	return &restoreCheckpoint{
+		store: store,
+		state: &checkpoint.Checkpoint{
+			RunID:          runID,
+			Operation:      "restore",
+			StartedAt:      time.Now(),
+			Snapshots:      restoreSnapshotNames(opts),
+			DropAllIndices: opts.DropAllIndices,
+		},
+		log: log,
+	}
+}
+
+// resumeRestoreCheckpoint continues the checkpoint of the interrupted run opts.Resume. The snapshots and
+// --drop-all-indices of that run are applied to opts; other snapshots than those of the interrupted run are refused.
+func resumeRestoreCheckpoint(store checkpointStore, opts *restoreOptions, log *logger.Logger) (*restoreCheckpoint, error) {
+	state, err := store.Load(opts.Resume)
+	if errors.Is(err, checkpoint.ErrNotFound) {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("cannot resume run %s: %w (the checkpoint of a restore is removed when it completes)", opts.Resume, err))
+	}
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("cannot resume run %s: %w", opts.Resume, err))
+	}
+	if state.Operation != "restore" {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("run %s is a %s, not a restore", state.RunID, state.Operation))
+	}
+	if len(opts.Snapshots) == 0 {
+		opts.Snapshots = state.Snapshots
+		if err := parseSnapshotNames(opts); err != nil {
+			return nil, err
+		}
+	} else if !slices.Equal(restoreSnapshotNames(opts), state.Snapshots) {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("run %s restored snapshot(s) %s, not %s",
+			state.RunID, strings.Join(state.Snapshots, ", "), strings.Join(restoreSnapshotNames(opts), ", ")))
+	}
+	if state.DropAllIndices && !opts.DropAllIndices {
+		log.Infof("Run %s deleted the existing indices (--drop-all-indices), the resumed run does as well", state.RunID)
+		opts.DropAllIndices = true
+	}
+
+	log.Infof("Resuming restore run %s of snapshot '%s'", state.RunID, opts.SnapshotName)
+	if len(state.Phases) > 0 {
+		log.Infof("Completed phases: %s", strings.Join(state.Phases, ", "))
+	}
+	return &restoreCheckpoint{store: store, state: state, resumed: true, saved: true, log: log}, nil
+}
+
+// restoreSnapshotNames returns the snapshots of opts as given on the command line
+func restoreSnapshotNames(opts *restoreOptions) []string {
+	if len(opts.Snapshots) > 0 {
+		return opts.Snapshots
+	}
+	return []string{opts.SnapshotName}
+}
+
+// completed reports whether the interrupted run completed phase
+func (c *restoreCheckpoint) completed(phase string) bool {
+	return c != nil && c.resumed && c.state.Completed(phase)
+}
+
+// complete marks phase as completed and saves the checkpoint
+func (c *restoreCheckpoint) complete(phase string) {
+	if c == nil {
+		return
+	}
+	c.state.Complete(phase)
+	c.save()
+}
+
+// skip logs that phase is skipped, since the interrupted run completed it
+func (c *restoreCheckpoint) skip(description string) {
+	c.log.Infof("Skipping %s, completed by run %s", description, c.state.RunID)
+}
+
+// scaledDeployments records the deployments scaled down for the restore
+func (c *restoreCheckpoint) scaledDeployments(scaled []k8s.DeploymentScale) {
+	if c == nil {
+		return
+	}
+	c.state.ScaledDeployments = scaled
+	c.save()
+}
+
+// scaledStatefulSets records the StatefulSets scaled down for the restore
+func (c *restoreCheckpoint) scaledStatefulSets(scaled []k8s.StatefulSetScale) {
+	if c == nil {
+		return
+	}
+	c.state.ScaledStatefulSets = scaled
+	c.save()
+}
+
+// deletedIndices records the indices deleted so far and the safety snapshot taken before deleting them
+func (c *restoreCheckpoint) deletedIndices(indices []string, safetySnapshot string) {
+	if c == nil {
+		return
+	}
+	c.state.IndicesDeleted = indices
+	c.state.SafetySnapshot = safetySnapshot
+	c.save()
+}
+
+// save writes the checkpoint, warning when that fails
+func (c *restoreCheckpoint) save() {
+	if c == nil {
+		return
+	}
+	if err := c.store.Save(c.state); err != nil {
+		c.log.Warningf("Failed to save the restore checkpoint, the restore cannot be resumed: %v", err)
+		return
+	}
+	c.saved = true
+}
+
+// finish removes the checkpoint after a successful run. After a failure it is kept and the command to resume the
+// restore is logged.
+func (c *restoreCheckpoint) finish(err error) {
+	if c == nil {
+		return
+	}
+	if !c.saved {
+		// Nothing has been changed, so there is nothing to remove or resume
+		return
+	}
+	if err == nil {
+		if deleteErr := c.store.Delete(c.state.RunID); deleteErr != nil {
+			c.log.Warningf("Failed to remove the checkpoint of run %s: %v", c.state.RunID, deleteErr)
+		}
+		return
+	}
+	c.log.Warningf("Resume the restore with the options of this run and --resume %s", c.state.RunID)
+}
+
+// indexCleaner lists and deletes indices
+type indexCleaner interface {
+	indexDeleter
+	ListIndices(pattern string) ([]string, error)
+}
+
+// deleteInterruptedRestore deletes the indices an interrupted restore left behind, so the snapshot can be restored
+// again. The interrupted run deleted the STS indices before it started the restore, with the writers scaled down,
+// so the STS indices that exist now were created by that restore. They are not recorded as deleted indices.
+func deleteInterruptedRestore(esClient indexCleaner, cfg *config.Config, opts *restoreOptions, record *restoreRecord, prompter *prompt.Prompter, log *logger.Logger) error {
+	allIndices, err := esClient.ListIndices("*")
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	leftovers := indicesToDrop(allIndices, cfg, opts)
+	if len(leftovers) == 0 {
+		return nil
+	}
+
+	log.Infof("Found %d index(es) of the interrupted restore", len(leftovers))
+	if err := prompter.Confirm("Delete the indices of the interrupted restore and restore them again?"); err != nil {
+		return fmt.Errorf("restore aborted: %w", err)
+	}
+	if _, err := deleteIndicesConcurrently(esClient, leftovers, opts.DeleteConcurrency, record.events, log); err != nil {
+		return err
+	}
+	log.Successf("Indices of the interrupted restore deleted")
+	return nil
+}
+
+// resumeFrom continues record with what the interrupted run changed, so the audit entry of the resumed run lists the
+// indices deleted and the safety snapshot taken by that run as well, e.g. for 'rollback-restore'
+func (r *restoreRecord) resumeFrom(c *restoreCheckpoint) {
+	r.checkpoint = c
+	r.deletedIndices = c.state.IndicesDeleted
+	r.safetySnapshot = c.state.SafetySnapshot
+}
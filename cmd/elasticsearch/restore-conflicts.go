@@ -0,0 +1,138 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+)
+
+// Strategies for snapshot indices that already exist in the cluster, see --on-conflict
+const (
+	conflictAbort  = "abort"
+	conflictSkip   = "skip"
+	conflictRename = "rename"
+)
+
+// defaultRenameSuffix is appended to the names of conflicting indices restored with --on-conflict rename
+const defaultRenameSuffix = "-restored"
+
+// conflictStrategies are the valid --on-conflict values
+var conflictStrategies = []string{conflictAbort, conflictSkip, conflictRename}
+
+// conflictChecker looks up the indices of a snapshot and of the cluster
+type conflictChecker interface {
+	GetSnapshot(repository, snapshotName string) (*elasticsearch.Snapshot, error)
+	ListIndices(pattern string) ([]string, error)
+}
+
+// validateConflictStrategy checks the --on-conflict value; empty means the strategy is asked for when needed
+func validateConflictStrategy(strategy string) error {
+	if strategy != "" && !slices.Contains(conflictStrategies, strategy) {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--on-conflict must be one of %s, got '%s'", strings.Join(conflictStrategies, ", "), strategy))
+	}
+	return nil
+}
+
+// resolveRestoreConflicts finds the indices of the snapshots to restore that already exist in the cluster, which
+// Elasticsearch refuses to restore over, and applies the conflict strategy of opts to them before anything is
+// changed: skip leaves them out of the restore, rename restores them with opts.RenameSuffix and abort fails.
+// Without a strategy it is asked for when ask is set, else the restore is aborted.
+func resolveRestoreConflicts(client conflictChecker, refs []snapshotRef, pattern string, opts *restoreOptions, prompter *prompt.Prompter, ask bool, log *logger.Logger) error {
+	existing, err := client.ListIndices("*")
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	var snapshotIndices []string
+	for _, ref := range refs {
+		snapshot, err := client.GetSnapshot(ref.Repository, ref.Name)
+		if err != nil {
+			return snapshotLookupError(err)
+		}
+		snapshotIndices = append(snapshotIndices, snapshot.Indices...)
+	}
+
+	conflicts := restoreConflicts(snapshotIndices, existing, pattern)
+	if len(conflicts) == 0 {
+		return nil
+	}
+	log.Warningf("%d index(es) of the snapshot already exist:", len(conflicts))
+	for _, index := range conflicts {
+		log.Warningf("  - %s", index)
+	}
+
+	strategy := opts.OnConflict
+	if strategy == "" && ask {
+		if strategy, err = askConflictStrategy(prompter); err != nil {
+			return err
+		}
+	}
+
+	switch strategy {
+	case conflictSkip:
+		log.Infof("Leaving the %d existing index(es) out of the restore", len(conflicts))
+		opts.ExcludeIndices = append(opts.ExcludeIndices, conflicts...)
+	case conflictRename:
+		renamed := renamedIndices(conflicts, opts.RenameSuffix)
+		if taken := slices.DeleteFunc(slices.Clone(renamed), func(index string) bool { return !slices.Contains(existing, index) }); len(taken) > 0 {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("indices %s already exist as well; choose another --rename-suffix", strings.Join(taken, ", ")))
+		}
+		log.Infof("Restoring the %d existing index(es) with suffix '%s', without aliases", len(conflicts), opts.RenameSuffix)
+		opts.RenamePattern, opts.RenameReplacement = renamePattern(conflicts, opts.RenameSuffix)
+	default:
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("%d index(es) of the snapshot already exist: %s. Delete them with --drop-all-indices, "+
+			"or leave them out or rename them with --on-conflict skip or rename", len(conflicts), strings.Join(conflicts, ", ")))
+	}
+	return nil
+}
+
+// restoreConflicts returns the sorted snapshot indices matching the restore pattern that exist already
+func restoreConflicts(snapshotIndices, existing []string, pattern string) []string {
+	var conflicts []string
+	for _, index := range snapshotIndices {
+		if matchesIndexPattern(index, pattern) && slices.Contains(existing, index) && !slices.Contains(conflicts, index) {
+			conflicts = append(conflicts, index)
+		}
+	}
+	slices.Sort(conflicts)
+	return conflicts
+}
+
+// askConflictStrategy asks how to handle the conflicting indices until a valid strategy is given
+func askConflictStrategy(prompter *prompt.Prompter) (string, error) {
+	question := fmt.Sprintf("Restore the existing index(es) how? [%s]:", strings.Join(conflictStrategies, "/"))
+	for attempt := 0; attempt < maxSelectionAttempts; attempt++ {
+		answer, err := prompter.Ask(question)
+		if err != nil {
+			return "", err
+		}
+		if answer = strings.ToLower(answer); slices.Contains(conflictStrategies, answer) {
+			return answer, nil
+		}
+	}
+	return conflictAbort, nil
+}
+
+// renamedIndices returns the names the indices are restored under with suffix
+func renamedIndices(indices []string, suffix string) []string {
+	renamed := make([]string, 0, len(indices))
+	for _, index := range indices {
+		renamed = append(renamed, index+suffix)
+	}
+	return renamed
+}
+
+// renamePattern returns the rename_pattern and rename_replacement of a restore that appends suffix to the names
+// of exactly the given indices
+func renamePattern(indices []string, suffix string) (string, string) {
+	quoted := make([]string, 0, len(indices))
+	for _, index := range indices {
+		quoted = append(quoted, regexp.QuoteMeta(index))
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$", "$1" + strings.ReplaceAll(suffix, "$", "\\$")
+}
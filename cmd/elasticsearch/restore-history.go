@@ -0,0 +1,161 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// restoreOperations are the audited operations that restore a snapshot
+var restoreOperations = []string{"restore", "rollback-restore"}
+
+// auditOutcomes are the outcomes restores can be filtered on
+var auditOutcomes = []string{audit.OutcomeSuccess, audit.OutcomeFailed, audit.OutcomeCancelled}
+
+// restoreHistoryOptions holds the flags of restore-history
+type restoreHistoryOptions struct {
+	Snapshot string
+	Outcome  string
+	Since    string
+	Until    string
+}
+
+// restoreHistoryFilter selects restores from the audit log; zero values match every restore
+type restoreHistoryFilter struct {
+	Snapshot string
+	Outcome  string
+	Since    time.Time
+	Until    time.Time
+}
+
+func restoreHistoryCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &restoreHistoryOptions{}
+	cmd := &cobra.Command{
+		Use:   "restore-history",
+		Short: "List past restores",
+		Long: `List the restores (and rollbacks of restores) recorded in the audit log, most recent first: when they ran, who
+ran them, which snapshot and indices they restored from which repository, how long they took and how they ended.
+
+--since and --until take a date (2025-03-04), an RFC3339 time or an age such as 30d.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRestoreHistory(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&opts.Snapshot, "snapshot-name", "s", "", "Only list restores of this snapshot")
+	cmd.Flags().StringVar(&opts.Outcome, "outcome", "", "Only list restores with this outcome ("+strings.Join(auditOutcomes, "|")+")")
+	cmd.Flags().StringVar(&opts.Since, "since", "", "Only list restores finished at or after this date, time or age")
+	cmd.Flags().StringVar(&opts.Until, "until", "", "Only list restores finished before this date, time or age")
+	return cmd
+}
+
+func runRestoreHistory(cliCtx *config.Context, opts *restoreHistoryOptions) error {
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	filter, err := newRestoreHistoryFilter(opts, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Create Kubernetes client
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	log.Infof("Fetching audit log from ConfigMap '%s'...", cliCtx.Config.AuditConfigMapName)
+	store := audit.NewStore(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.AuditConfigMapName)
+	entries, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	restores := filterRestores(entries, filter)
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(restores) == 0 {
+		formatter.PrintMessage("No restores found")
+		return nil
+	}
+	return formatter.PrintTable(restoreHistoryTable(restores))
+}
+
+// newRestoreHistoryFilter validates the filter flags and resolves ages relative to now
+func newRestoreHistoryFilter(opts *restoreHistoryOptions, now time.Time) (*restoreHistoryFilter, error) {
+	filter := &restoreHistoryFilter{Snapshot: opts.Snapshot, Outcome: strings.ToUpper(opts.Outcome)}
+	if filter.Outcome != "" && !slices.Contains(auditOutcomes, filter.Outcome) {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--outcome must be one of %s, got '%s'", strings.Join(auditOutcomes, ", "), opts.Outcome))
+	}
+
+	var err error
+	if filter.Since, err = parseTimeBound(opts.Since, now); err != nil {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --since: %w", err))
+	}
+	if filter.Until, err = parseTimeBound(opts.Until, now); err != nil {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --until: %w", err))
+	}
+	return filter, nil
+}
+
+// filterRestores returns the restores in the audit log passing the filter, most recent first
+func filterRestores(entries []audit.Entry, filter *restoreHistoryFilter) []audit.Entry {
+	var restores []audit.Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		switch {
+		case !slices.Contains(restoreOperations, entry.Operation),
+			filter.Snapshot != "" && entry.Snapshot != filter.Snapshot,
+			filter.Outcome != "" && entry.Outcome != filter.Outcome,
+			!filter.Since.IsZero() && entry.Timestamp.Before(filter.Since),
+			!filter.Until.IsZero() && !entry.Timestamp.Before(filter.Until):
+			continue
+		}
+		restores = append(restores, entry)
+	}
+	return restores
+}
+
+// restoreHistoryTable converts restores into a table. Entries written before the repository, indices and
+// duration were recorded leave those columns empty.
+func restoreHistoryTable(restores []audit.Entry) output.Table {
+	table := output.Table{
+		Headers:      []string{"TIME", "RUN ID", "USER", "OPERATION", "SNAPSHOT", "REPOSITORY", "INDICES", "INDICES DELETED", "DURATION", "OUTCOME"},
+		Rows:         make([][]string, 0, len(restores)),
+		StateColumns: []string{"OUTCOME"},
+	}
+
+	for _, entry := range restores {
+		duration := ""
+		if entry.DurationMillis > 0 {
+			duration = (time.Duration(entry.DurationMillis) * time.Millisecond).Round(time.Second).String()
+		}
+		table.Rows = append(table.Rows, []string{
+			entry.Timestamp.Local().Format(time.RFC3339),
+			entry.RunID,
+			entry.User,
+			entry.Operation,
+			entry.Snapshot,
+			entry.Repository,
+			entry.IndicesPattern,
+			strconv.Itoa(len(entry.IndicesDeleted)),
+			duration,
+			entry.Outcome,
+		})
+	}
+
+	return table
+}
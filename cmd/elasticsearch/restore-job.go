@@ -0,0 +1,116 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stackvista/stackstate-backup-cli/cmd/generate"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// restoreJobComponent is the component label of the Jobs started by restore-snapshot --as-job
+const restoreJobComponent = "restore"
+
+// jobLocalFlags are the flags of restore-snapshot that only apply to the invocation starting the Job, or to
+// reaching the cluster from outside, and are not passed to the restore in the Job. The installation is selected
+// by the flags generate.Job adds.
+var jobLocalFlags = []string{
+	"as-job", "job-image", "job-service-account",
+	"namespace", "configmap", "secret", "kubeconfig", "proxy", "as", "as-group",
+}
+
+// jobUnsupportedFlags cannot work without a terminal or outside of the pod of the Job
+var jobUnsupportedFlags = []string{"interactive", "target-context", "report", "output-file", "helm-values"}
+
+// restoreJobOptions holds the flags of restore-snapshot --as-job
+type restoreJobOptions struct {
+	AsJob          bool
+	Image          string
+	ServiceAccount string
+}
+
+// addRestoreJobFlags adds the flags running the restore as a Kubernetes Job
+func addRestoreJobFlags(cmd *cobra.Command, opts *restoreJobOptions) {
+	cmd.Flags().BoolVar(&opts.AsJob, "as-job", false, "Run the restore in-cluster as a Kubernetes Job with the same flags and stream its logs")
+	cmd.Flags().StringVar(&opts.Image, "job-image", "", "Container image containing the sts-backup binary, for --as-job")
+	cmd.Flags().StringVar(&opts.ServiceAccount, "job-service-account", generate.DefaultRestoreServiceAccount, "ServiceAccount the Job runs as with --as-job (see generate restore-rbac)")
+}
+
+// runRestoreJob starts restore-snapshot with the flags of cmd in a Job in the namespace of the installation and
+// follows its logs until it finishes. The restore does not depend on this process: after a disconnect it keeps
+// running in-cluster.
+func runRestoreJob(cliCtx *config.Context, cmd *cobra.Command, opts *restoreJobOptions) error {
+	args, err := restoreJobArgs(cmd, cliCtx, opts)
+	if err != nil {
+		return err
+	}
+
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	job := generate.Job(cliCtx.Config, generate.JobOptions{
+		Name:           "sts-backup-restore-" + strings.ToLower(cliCtx.RunID),
+		Component:      restoreJobComponent,
+		Image:          opts.Image,
+		ServiceAccount: opts.ServiceAccount,
+		RunID:          cliCtx.RunID,
+		Args:           args,
+	})
+	if _, err := k8sClient.CreateJob(cliCtx.Config.Namespace, job); err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	log.Infof("Started job %s in namespace '%s' running: sts-backup %s", job.Name, cliCtx.Config.Namespace, strings.Join(args, " "))
+	log.Infof("The restore keeps running when this command is interrupted; reattach with: sts-backup jobs attach --namespace %s %s", cliCtx.Config.Namespace, job.Name)
+
+	return generate.FollowJob(k8sClient, cliCtx.Config.Namespace, job.Name, os.Stdout, log)
+}
+
+// restoreJobArgs returns the arguments of the restore in the Job: the flags set on cmd, except those of
+// jobLocalFlags. Flags that cannot work in a Job are refused, and --yes is required since the Job has no terminal
+// to confirm the restore on.
+func restoreJobArgs(cmd *cobra.Command, cliCtx *config.Context, opts *restoreJobOptions) ([]string, error) {
+	if opts.Image == "" {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--as-job requires --job-image"))
+	}
+	if !cliCtx.Config.AssumeYes {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--as-job requires --yes: the restore cannot be confirmed in the job"))
+	}
+
+	args := []string{"elasticsearch", cmd.Name()}
+	var unsupported []string
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		switch {
+		case slices.Contains(jobLocalFlags, flag.Name):
+		case slices.Contains(jobUnsupportedFlags, flag.Name):
+			unsupported = append(unsupported, "--"+flag.Name)
+		default:
+			args = append(args, flagArgs(flag)...)
+		}
+	})
+	if len(unsupported) > 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("%s cannot be used with --as-job", strings.Join(unsupported, ", ")))
+	}
+	return args, nil
+}
+
+// flagArgs returns the arguments setting a flag to its current value, repeating the flag for every value of a list
+func flagArgs(flag *pflag.Flag) []string {
+	if list, ok := flag.Value.(pflag.SliceValue); ok {
+		args := make([]string, 0, len(list.GetSlice()))
+		for _, value := range list.GetSlice() {
+			args = append(args, fmt.Sprintf("--%s=%s", flag.Name, value))
+		}
+		return args
+	}
+	return []string{fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String())}
+}
@@ -0,0 +1,36 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// indexMaintainer refreshes and force merges indices
+type indexMaintainer interface {
+	RefreshIndices(pattern string) error
+	ForceMergeIndices(pattern string, maxNumSegments int) error
+}
+
+// finishRestoredIndices refreshes the restored indices, so searches return their documents immediately, and
+// force merges them down to maxNumSegments segments per shard when maxNumSegments is set. A failed refresh
+// only warns, since the restored data is complete and gets refreshed by Elasticsearch eventually.
+func finishRestoredIndices(client indexMaintainer, pattern string, maxNumSegments int, log *logger.Logger) error {
+	log.Infof("Refreshing restored indices...")
+	if err := client.RefreshIndices(pattern); err != nil {
+		log.Warningf("Failed to refresh restored indices: %v", err)
+	} else {
+		log.Successf("Restored indices refreshed")
+	}
+
+	if maxNumSegments == 0 {
+		return nil
+	}
+
+	log.Infof("Force merging restored indices to %d segment(s) per shard - this may take a long time...", maxNumSegments)
+	if err := client.ForceMergeIndices(pattern, maxNumSegments); err != nil {
+		return fmt.Errorf("restore completed but force merge failed: %w", err)
+	}
+	log.Successf("Restored indices force merged")
+	return nil
+}
@@ -0,0 +1,202 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/events"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// restoreProgressInterval is the time between progress updates while several snapshots are restored
+const restoreProgressInterval = 30 * time.Second
+
+// snapshotRef is a snapshot in a repository, given as <repository>/<name> or as <name> for the restore repository
+type snapshotRef struct {
+	Repository string
+	Name       string
+}
+
+func (r snapshotRef) String() string {
+	if r.Repository == "" {
+		return r.Name
+	}
+	return r.Repository + "/" + r.Name
+}
+
+// snapshotRestorer restores snapshots and reports the shard recoveries of the restores
+type snapshotRestorer interface {
+	RestoreSnapshotWithOptions(repository, snapshotName string, opts elasticsearch.RestoreOptions) error
+	ListRecoveries(activeOnly bool) ([]elasticsearch.RecoveryInfo, error)
+}
+
+// parseSnapshotRef parses a --snapshot-name value, <name> or <repository>/<name>
+func parseSnapshotRef(value string) (snapshotRef, error) {
+	repository, name, found := strings.Cut(value, "/")
+	if !found {
+		return snapshotRef{Name: value}, nil
+	}
+	if repository == "" || name == "" || strings.Contains(name, "/") {
+		return snapshotRef{}, exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid snapshot '%s': use <name> or <repository>/<name>", value))
+	}
+	return snapshotRef{Repository: repository, Name: name}, nil
+}
+
+// parseSnapshotNames sets the snapshot to restore, and the snapshots restored concurrently with it, from the
+// --snapshot-name values
+func parseSnapshotNames(opts *restoreOptions) error {
+	if len(opts.Snapshots) == 0 {
+		return nil
+	}
+	refs := make([]snapshotRef, 0, len(opts.Snapshots))
+	for _, value := range opts.Snapshots {
+		ref, err := parseSnapshotRef(value)
+		if err != nil {
+			return err
+		}
+		for _, other := range refs {
+			if other == ref {
+				return exitcode.Wrap(exitcode.Usage, fmt.Errorf("snapshot '%s' is given more than once", value))
+			}
+		}
+		refs = append(refs, ref)
+	}
+	opts.SnapshotName, opts.SnapshotRepository = refs[0].Name, refs[0].Repository
+	opts.AdditionalSnapshots = refs[1:]
+	return nil
+}
+
+// additionalSnapshotRefs returns the additional snapshots of opts with the restore repository filled in
+func additionalSnapshotRefs(opts *restoreOptions, repository string) []snapshotRef {
+	refs := make([]snapshotRef, 0, len(opts.AdditionalSnapshots))
+	for _, ref := range opts.AdditionalSnapshots {
+		if ref.Repository == "" {
+			ref.Repository = repository
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// additionalSnapshotNames returns the additional snapshots of opts as <repository>/<name>, for the audit log
+func additionalSnapshotNames(opts *restoreOptions, repository string) []string {
+	var names []string
+	for _, ref := range additionalSnapshotRefs(opts, repository) {
+		names = append(names, ref.String())
+	}
+	return names
+}
+
+// restoreSnapshots restores the snapshot, and the additional snapshots concurrently with it, and waits until all
+// restores have finished. Feature states are restored from the first snapshot only. While several snapshots are
+// restored, the progress of each is logged every interval; with an emitter it is emitted as well, also for a
+// single snapshot. The errors of all failed restores are returned together.
+func restoreSnapshots(client snapshotRestorer, refs []snapshotRef, restoreOpts elasticsearch.RestoreOptions, interval time.Duration, emitter *events.Emitter, log *logger.Logger) error {
+	if len(refs) > 1 || emitter != nil {
+		stop, stopped := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(stopped)
+			reportRestoreProgress(client, refs, interval, stop, emitter, log)
+		}()
+		// No progress is reported after the restores have finished
+		defer func() {
+			close(stop)
+			<-stopped
+		}()
+	}
+
+	if len(refs) == 1 {
+		return restoreError(refs[0], client.RestoreSnapshotWithOptions(refs[0].Repository, refs[0].Name, restoreOpts))
+	}
+
+	errs := make([]error, len(refs))
+	var wg sync.WaitGroup
+	for i, ref := range refs {
+		opts := restoreOpts
+		if i > 0 {
+			opts.FeatureStates = nil
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = restoreError(ref, client.RestoreSnapshotWithOptions(ref.Repository, ref.Name, opts))
+			if errs[i] == nil {
+				log.Successf("Restore of snapshot '%s' completed", ref)
+			} else {
+				log.Errorf("Restore of snapshot '%s' failed", ref)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// restoreError returns the error of the restore of ref, with the exit code of a partial restore or lost connection
+func restoreError(ref snapshotRef, err error) error {
+	if err == nil {
+		return nil
+	}
+	var partialErr *elasticsearch.PartialRestoreError
+	if errors.As(err, &partialErr) {
+		return exitcode.Wrap(exitcode.PartialRestore, fmt.Errorf("restore incomplete: %w", err))
+	}
+	if errors.Is(err, elasticsearch.ErrConnectionLost) {
+		return exitcode.Wrap(exitcode.ConnectivityError, restoreConnectionLostError(ref.String(), err))
+	}
+	return fmt.Errorf("failed to restore snapshot: %w", err)
+}
+
+// reportRestoreProgress emits the progress of the restores of refs every interval until stop is closed, and logs
+// it when there are several
+func reportRestoreProgress(client snapshotRestorer, refs []snapshotRef, interval time.Duration, stop <-chan struct{}, emitter *events.Emitter, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			recoveries, err := client.ListRecoveries(false)
+			if err != nil {
+				log.Debugf("Failed to get the restore progress: %v", err)
+				continue
+			}
+			for _, ref := range refs {
+				done, total := snapshotProgress(recoveries, ref)
+				emitter.RestoreProgress(ref.String(), done, total)
+			}
+			if len(refs) > 1 {
+				log.Infof("Restore progress: %s", restoreProgress(recoveries, refs))
+			}
+		}
+	}
+}
+
+// restoreProgress summarizes the shards recovered from each snapshot, e.g. "sts-backup/snap-1 12/40 shard(s)"
+func restoreProgress(recoveries []elasticsearch.RecoveryInfo, refs []snapshotRef) string {
+	parts := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		done, total := snapshotProgress(recoveries, ref)
+		parts = append(parts, fmt.Sprintf("%s %d/%d shard(s)", ref, done, total))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// snapshotProgress returns the number of shards restored from ref and the number of shards being restored from it
+func snapshotProgress(recoveries []elasticsearch.RecoveryInfo, ref snapshotRef) (done, total int) {
+	for _, recovery := range recoveries {
+		if recovery.Type != snapshotRecoveryType || recovery.Repository != ref.Repository || recovery.Snapshot != ref.Name {
+			continue
+		}
+		total++
+		if recovery.Stage == "done" {
+			done++
+		}
+	}
+	return done, total
+}
@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// maxUnmatchedIndicesShown limits the indices of the snapshot left out by the restore pattern that are logged
+const maxUnmatchedIndicesShown = 5
+
+// lintRestorePattern compares the restore pattern with the indices of the snapshots before anything is changed, since
+// a pattern that matches nothing, e.g. after the index prefix changed between releases, restores nothing without
+// failing. A pattern matching no index of the snapshots is an exitcode.ValidationFailed error. Each pattern matching
+// no index, and a pattern leaving out most of the indices while the restore is not narrowed on purpose (with --domain
+// or --exclude-indices), is warned about. Hidden indices, other than the backing indices of data streams, are ignored.
+func lintRestorePattern(snapshots []*elasticsearch.Snapshot, pattern string, narrowed bool, log *logger.Logger) error {
+	var indices, names []string
+	for _, snapshot := range snapshots {
+		indices = append(indices, snapshot.Indices...)
+		names = append(names, snapshot.Snapshot)
+	}
+	slices.Sort(indices)
+	indices = slices.Compact(indices)
+
+	for _, part := range strings.Split(pattern, ",") {
+		if part == "" || strings.HasPrefix(part, "-") {
+			continue
+		}
+		if !slices.ContainsFunc(indices, func(index string) bool { return matchesIndexPattern(index, part) }) {
+			log.Warningf("Restore pattern '%s' matches no index of snapshot(s) %s", part, strings.Join(names, ", "))
+		}
+	}
+
+	var matched, unmatched []string
+	for _, index := range indices {
+		switch {
+		case matchesIndexPattern(index, pattern):
+			matched = append(matched, index)
+		case !strings.HasPrefix(index, ".") || strings.HasPrefix(index, ".ds-"):
+			unmatched = append(unmatched, index)
+		}
+	}
+	if len(matched) == 0 {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("restore pattern '%s' matches none of the %d index(es) of snapshot(s) %s: "+
+			"check elasticsearch.restore.indicesPattern, e.g. after the index prefix changed", pattern, len(indices), strings.Join(names, ", ")))
+	}
+	if !narrowed && len(matched) < len(unmatched) {
+		shown := unmatched[:min(len(unmatched), maxUnmatchedIndicesShown)]
+		log.Warningf("Restore pattern '%s' matches only %d of the %d index(es) of the snapshot; not restored are e.g. %s",
+			pattern, len(matched), len(matched)+len(unmatched), strings.Join(shown, ", "))
+	}
+	log.Infof("%d index(es) of the snapshot match restore pattern '%s'", len(matched), pattern)
+	return nil
+}
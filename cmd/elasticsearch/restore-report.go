@@ -0,0 +1,233 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/report"
+)
+
+// restoreInspector looks up what the report of a restore shows beyond what the restore itself returned
+type restoreInspector interface {
+	SnapshotSize(repository, snapshotName string) (int64, error)
+	ListIndices(pattern string) ([]string, error)
+}
+
+// startStep emits the start of the step name and returns the start time to pass to timeStep
+func (r *restoreRecord) startStep(name string) time.Time {
+	r.events.PhaseStarted(name)
+	return time.Now()
+}
+
+// timeStep records that the step name took from start until now, for the report, the progress callback and the
+// progress events
+func (r *restoreRecord) timeStep(name string, start time.Time) {
+	step := report.Step{Name: name, Duration: time.Since(start)}
+	r.steps = append(r.steps, step)
+	if r.progress != nil {
+		r.progress(step.Name, step.Duration)
+	}
+	r.events.PhaseCompleted(step.Name, step.Duration)
+}
+
+// inspectRestore records the size of the restored snapshot and the indices matching pattern after the restore,
+// and validates them against the snapshot. Lookups that fail only warn, since the restore itself succeeded.
+func inspectRestore(client restoreInspector, repository, pattern string, record *restoreRecord, log *logger.Logger) {
+	snapshot := record.snapshot
+	if snapshot == nil {
+		return
+	}
+
+	record.snapshotSize = -1
+	if size, err := client.SnapshotSize(repository, snapshot.Snapshot); err != nil {
+		log.Warningf("Could not determine snapshot size for the report: %v", err)
+	} else {
+		record.snapshotSize = size
+	}
+
+	state := report.Check{Name: "Snapshot state", Status: report.StatusPass,
+		Details: fmt.Sprintf("%s, %d/%d shard(s) successful", snapshot.State, snapshot.Shards.Successful, snapshot.Shards.Total)}
+	if snapshot.State != "SUCCESS" || snapshot.Shards.Failed > 0 {
+		state.Status = report.StatusWarn
+	}
+	record.checks = append(record.checks, state)
+
+	restored, err := client.ListIndices(pattern)
+	if err != nil {
+		log.Warningf("Could not list the restored indices for the report: %v", err)
+		record.checks = append(record.checks, report.Check{Name: "Restored indices", Status: report.StatusWarn, Details: "could not list indices"})
+		return
+	}
+	slices.Sort(restored)
+	record.restoredIndices = restored
+
+	var expected, missing []string
+	for _, index := range snapshot.Indices {
+		if !matchesIndexPattern(index, pattern) {
+			continue
+		}
+		expected = append(expected, index)
+		if !slices.Contains(restored, index) {
+			missing = append(missing, index)
+		}
+	}
+	indices := report.Check{Name: "Restored indices", Status: report.StatusPass,
+		Details: fmt.Sprintf("%d of %d snapshot index(es) present", len(expected)-len(missing), len(expected))}
+	if len(missing) > 0 {
+		indices.Status = report.StatusFail
+		indices.Details += "; missing: " + strings.Join(missing, ", ")
+	}
+	record.checks = append(record.checks, indices)
+}
+
+// matchesIndexPattern reports whether index matches a comma-separated Elasticsearch index pattern, in which
+// patterns starting with '-' exclude the indices they match
+func matchesIndexPattern(index, pattern string) bool {
+	matched := false
+	for _, part := range strings.Split(pattern, ",") {
+		if exclude, ok := strings.CutPrefix(part, "-"); ok {
+			if m, _ := path.Match(exclude, index); m {
+				matched = false
+			}
+			continue
+		}
+		if m, _ := path.Match(part, index); m {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// report returns the summary report of a restore started at startedAt that ended with err
+func (r *restoreRecord) report(cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, startedAt time.Time, err error, warnings []string) *report.Report {
+	pattern := elasticsearch.RestoreOptions{Indices: cfg.Elasticsearch.Restore.IndicesPattern, ExcludeIndices: opts.ExcludeIndices}
+	rep := &report.Report{
+		Title:      fmt.Sprintf("Restore of snapshot %s", opts.SnapshotName),
+		RunID:      cliCtx.RunID,
+		Namespace:  cliCtx.Config.Namespace,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Details: []report.Detail{
+			{Name: "Snapshot", Value: opts.SnapshotName},
+			{Name: "Repository", Value: cfg.Elasticsearch.Restore.Repository},
+			{Name: "Indices pattern", Value: pattern.IndexPattern()},
+		},
+		Steps:    r.steps,
+		Indices:  r.restoredIndices,
+		Checks:   r.checks,
+		Warnings: warnings,
+	}
+	if err != nil {
+		rep.Error = redact.Error(err)
+	}
+
+	if additional := additionalSnapshotNames(opts, cfg.Elasticsearch.Restore.Repository); len(additional) > 0 {
+		rep.Details = append(rep.Details, report.Detail{Name: "Additional snapshots", Value: strings.Join(additional, ", ")})
+	}
+	if r.snapshot != nil {
+		rep.Details = append(rep.Details, report.Detail{Name: "Snapshot indices", Value: fmt.Sprintf("%d", len(r.snapshot.Indices))})
+	}
+	if r.snapshotSize > 0 {
+		rep.Details = append(rep.Details, report.Detail{Name: "Snapshot size", Value: output.FormatBytes(r.snapshotSize)})
+	}
+	if len(cfg.Elasticsearch.Restore.FeatureStates) > 0 {
+		rep.Details = append(rep.Details, report.Detail{Name: "Feature states", Value: strings.Join(cfg.Elasticsearch.Restore.FeatureStates, ", ")})
+	}
+	if opts.DropAllIndices {
+		rep.Details = append(rep.Details, report.Detail{Name: "Indices deleted", Value: fmt.Sprintf("%d", len(r.deletedIndices))})
+	}
+	if r.safetySnapshot != "" {
+		rep.Details = append(rep.Details, report.Detail{Name: "Safety snapshot", Value: r.safetySnapshot})
+	}
+	return rep
+}
+
+// writeRestoreReport writes the report of the restore to opts.ReportFile, if set. A failure only warns, since
+// the report must not change the outcome of the restore.
+func writeRestoreReport(cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, record *restoreRecord, startedAt time.Time, err error, log *logger.Logger) {
+	if opts.ReportFile == "" {
+		return
+	}
+	rep := record.report(cliCtx, cfg, opts, startedAt, err, log.Warnings())
+	if writeErr := report.Write(opts.ReportFile, rep); writeErr != nil {
+		log.Warningf("%v", writeErr)
+		return
+	}
+	log.Infof("Restore report written to %s", opts.ReportFile)
+}
+
+// restoreChecks returns the outcome of the restore, failed when err is set, followed by its post-restore validation
+func restoreChecks(record *restoreRecord, err error) []output.Check {
+	restore := output.Check{Name: "Restore", Status: report.StatusPass, Details: "completed"}
+	if err != nil {
+		restore = output.Check{Name: "Restore", Status: report.StatusFail, Details: redact.Error(err)}
+	}
+	checks := []output.Check{restore}
+	for _, check := range record.checks {
+		checks = append(checks, output.Check{Name: check.Name, Status: check.Status, Details: check.Details})
+	}
+	return checks
+}
+
+// printShardFailures lists the failed shards of a failed restore, which the error message only counts. For a
+// partial restore the shards that already failed in the snapshot are included.
+func printShardFailures(cliCtx *config.Context, record *restoreRecord, err error, log *logger.Logger) {
+	failures := elasticsearch.ShardFailures(err)
+	var partialErr *elasticsearch.PartialRestoreError
+	if errors.As(err, &partialErr) && record.snapshot != nil {
+		for _, failure := range record.snapshot.Failures {
+			failures = append(failures, failure.ShardFailure())
+		}
+	}
+	if len(failures) == 0 || output.Format(cliCtx.Config.OutputFormat) == output.FormatJUnit {
+		return
+	}
+	log.Errorf("%d shard(s) failed:", len(failures))
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if printErr := formatter.PrintTable(shardFailuresTable(failures)); printErr != nil {
+		log.Warningf("Failed to print the shard failures: %v", printErr)
+	}
+}
+
+// shardFailuresTable lists shard failures sorted by index and shard
+func shardFailuresTable(failures []elasticsearch.ShardFailure) output.Table {
+	sorted := slices.Clone(failures)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Index != sorted[j].Index {
+			return sorted[i].Index < sorted[j].Index
+		}
+		return sorted[i].Shard < sorted[j].Shard
+	})
+
+	table := output.Table{
+		Headers: []string{"INDEX", "SHARD", "NODE", "SOURCE", "REASON"},
+		Rows:    make([][]string, 0, len(sorted)),
+	}
+	for _, failure := range sorted {
+		table.Rows = append(table.Rows, []string{failure.Index, strconv.Itoa(failure.Shard), failure.Node, failure.Source, failure.Reason})
+	}
+	return table
+}
+
+// printRestoreValidation prints the outcome and the post-restore validation of the restore as JUnit XML with
+// -o junit. A failure only warns, like writing the report.
+func printRestoreValidation(cliCtx *config.Context, record *restoreRecord, err error, log *logger.Logger) {
+	if output.Format(cliCtx.Config.OutputFormat) != output.FormatJUnit {
+		return
+	}
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if printErr := formatter.PrintChecks("restore-snapshot", restoreChecks(record, err)); printErr != nil {
+		log.Warningf("Failed to print the validation results: %v", printErr)
+	}
+}
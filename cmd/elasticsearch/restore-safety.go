@@ -0,0 +1,54 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+const (
+	// safetySnapshotPrefix is the name prefix of the snapshots taken before indices are deleted for a restore
+	safetySnapshotPrefix = "pre-restore-"
+	// safetySnapshotTimeFormat is the timestamp in safety snapshot names; snapshot names must be lowercase
+	safetySnapshotTimeFormat = "20060102-150405"
+)
+
+// snapshotCreator takes snapshots
+type snapshotCreator interface {
+	CreateSnapshot(repository, snapshotName string, indices []string, metadata map[string]string) (*elasticsearch.Snapshot, error)
+}
+
+// safetySnapshotName returns the name of the safety snapshot taken at t
+func safetySnapshotName(t time.Time) string {
+	return safetySnapshotPrefix + t.UTC().Format(safetySnapshotTimeFormat)
+}
+
+// isSafetySnapshot reports whether a snapshot was taken as safety snapshot before a restore
+func isSafetySnapshot(name string) bool {
+	return strings.HasPrefix(name, safetySnapshotPrefix)
+}
+
+// takeSafetySnapshot snapshots the indices about to be deleted for a restore, so a failed restore can be
+// rolled back to them. Only a complete snapshot is accepted: without it the deletion is not undoable.
+func takeSafetySnapshot(client snapshotCreator, repository string, indices []string, now time.Time, log *logger.Logger) (string, error) {
+	name := safetySnapshotName(now)
+	log.Infof("Taking safety snapshot '%s' of %d index(es) in repository '%s' - this may take several minutes...", name, len(indices), repository)
+
+	snapshot, err := client.CreateSnapshot(repository, name, indices, map[string]string{
+		metadataTakenBy: "sts-backup",
+		metadataReason:  "safety snapshot before restore",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to take safety snapshot: %w", err)
+	}
+	if snapshot.State != "SUCCESS" {
+		return "", fmt.Errorf("safety snapshot %s finished with state %s (%d failed shard(s)): use --skip-safety-snapshot to delete the indices without it",
+			name, snapshot.State, snapshot.Shards.Failed)
+	}
+
+	log.Successf("Safety snapshot '%s' taken", name)
+	return name, nil
+}
@@ -1,18 +1,29 @@
 package elasticsearch
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/checkpoint"
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
 	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/events"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
 	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
 	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/report"
 )
 
 const (
@@ -20,118 +31,621 @@ const (
 	defaultMaxIndexDeleteAttempts = 30
 	// defaultIndexDeleteRetryInterval is the time to wait between index deletion verification attempts
 	defaultIndexDeleteRetryInterval = 1 * time.Second
+	// defaultDeleteConcurrency is the number of indices deleted in parallel
+	defaultDeleteConcurrency = 4
+	// defaultStatefulSetTimeout is the time a StatefulSet scaled for a restore has to reach its replica count
+	defaultStatefulSetTimeout = 10 * time.Minute
 )
 
-// Restore command flags
-var (
-	snapshotName     string
-	dropAllIndices   bool
-	skipConfirmation bool
-)
+// indexDeleter deletes indices and checks whether they still exist
+type indexDeleter interface {
+	DeleteIndex(index string) error
+	IndexExists(index string) (bool, error)
+}
+
+// restoreOptions holds the options of a single restore run
+type restoreOptions struct {
+	SnapshotName string
+	// SnapshotRepository overrides the repository SnapshotName is restored from
+	SnapshotRepository string
+	// AdditionalSnapshots are restored concurrently with SnapshotName
+	AdditionalSnapshots []snapshotRef
+	// Snapshots are the --snapshot-name values, parsed into the fields above
+	Snapshots      []string
+	DropAllIndices bool
+	Interactive    bool
+	// DisableRebalance disables shard rebalancing while the snapshot is restored
+	DisableRebalance bool
+	// ImportPipelines imports the most recent ingest pipeline export after the restore
+	ImportPipelines bool
+	// ImportILM imports the most recent ILM policy export before the snapshot is restored
+	ImportILM bool
+	// DeleteConcurrency is the number of indices deleted in parallel with --drop-all-indices
+	DeleteConcurrency int
+	// FeatureStates overrides the feature states to restore from the configuration
+	FeatureStates []string
+	// ExcludeIndices lists indices or patterns of the snapshot that are not restored
+	ExcludeIndices []string
+	// Domains limit the restore, and the indices deleted with --drop-all-indices, to the indices of these data
+	// domains of restore.domains
+	Domains []string
+	// IgnoreUnavailable skips indices of the restore pattern that are missing in the snapshot
+	IgnoreUnavailable bool
+	// SkipSafetySnapshot skips the pre-restore snapshot of the indices deleted with --drop-all-indices
+	SkipSafetySnapshot bool
+	// ForceMergeSegments force merges the restored indices to this number of segments per shard; 0 disables it
+	ForceMergeSegments int
+	// AllowPartial restores a snapshot with failed shards, leaving out the data of those shards
+	AllowPartial bool
+	// OnConflict is the strategy for snapshot indices that exist already, see resolveRestoreConflicts
+	OnConflict string
+	// RenameSuffix is appended to the names of existing indices restored with --on-conflict rename
+	RenameSuffix string
+	// RenamePattern and RenameReplacement rename the conflicting indices, set by resolveRestoreConflicts
+	RenamePattern     string
+	RenameReplacement string
+	// TargetNamespace and TargetContext select another installation to restore into
+	TargetNamespace string
+	TargetContext   string
+	// ReportFile is the file to write a summary report of the restore to, as Markdown or HTML by extension
+	ReportFile string
+	// ValidateRestore validates the restored indices against the snapshot, for the report and JUnit output
+	ValidateRestore bool
+	// KeepAliveInterval is the time between keepalive requests through the port-forward; 0 disables them
+	KeepAliveInterval time.Duration
+	// StatefulSetTimeout is the time each StatefulSet of restore.scaleDownStatefulSetSelectors has to scale
+	StatefulSetTimeout time.Duration
+	// Resume is the run ID of an interrupted restore to resume; the phases it completed are skipped
+	Resume string
+	// Events is the format of the progress events written to stdout, see internal/events; empty writes none
+	Events string
+	// Progress is called after every step of the restore, see RestoreRequest
+	Progress func(step string, duration time.Duration)
+}
 
 func restoreCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &restoreOptions{}
+	jobOpts := &restoreJobOptions{}
 	cmd := &cobra.Command{
 		Use:   "restore-snapshot",
 		Short: "Restore Elasticsearch from a snapshot",
-		Long:  `Restore Elasticsearch indices from a snapshot. Can optionally delete existing indices before restore.`,
-		Run: func(_ *cobra.Command, _ []string) {
-			if err := runRestore(cliCtx); err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "error: %v\n", err)
-				os.Exit(1)
+		Long: `Restore Elasticsearch indices from a snapshot. Can optionally delete existing indices before restore.
+
+With --interactive the most recent snapshots are listed to pick from, the restore plan is shown
+and the snapshot name has to be typed to confirm, instead of passing --snapshot-name. It cannot be combined with --yes.
+
+With --target-namespace (and optionally --target-context for another cluster) a snapshot of this installation
+is restored into another one, e.g. production data into staging. The snapshot repository is taken from the
+configuration in --namespace and registered read-only in the target; everything else, such as the Elasticsearch
+service and the deployments to scale down, from the configuration in the target namespace.
+
+Every restore records its progress in the ConfigMap suse-observability-backup-checkpoints until it completes.
+When a restore fails halfway, e.g. because the connection broke off, --resume <run-id> runs it again with the same
+snapshots but skips the phases it completed: deleting the indices (and the safety snapshot), importing and restoring.
+
+With --as-job the restore runs in-cluster as a Kubernetes Job with the same flags, and its logs are streamed until
+it finishes, so a restore of several hours does not depend on this machine staying connected. The Job runs the
+--job-image as the --job-service-account, whose RBAC 'generate restore-rbac' creates.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			run := func() error { return runRestore(cliCtx, opts) }
+			if jobOpts.AsJob {
+				run = func() error { return runRestoreJob(cliCtx, cmd, jobOpts) }
+			}
+			if err := run(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
 			}
 		}}
 
-	cmd.Flags().StringVarP(&snapshotName, "snapshot-name", "s", "", "Snapshot name to restore (required)")
-	cmd.Flags().BoolVarP(&dropAllIndices, "drop-all-indices", "r", false, "Delete all existing STS indices before restore")
-	cmd.Flags().BoolVar(&skipConfirmation, "yes", false, "Skip confirmation prompt")
-	_ = cmd.MarkFlagRequired("snapshot-name")
+	cmd.Flags().StringSliceVarP(&opts.Snapshots, "snapshot-name", "s", nil, "Snapshot to restore as <name> or <repository>/<name>; repeat to restore several snapshots concurrently (required unless --interactive)")
+	cmd.Flags().BoolVarP(&opts.DropAllIndices, "drop-all-indices", "r", false, "Delete all existing STS indices before restore")
+	cmd.Flags().BoolVar(&opts.SkipSafetySnapshot, "skip-safety-snapshot", false, "Do not snapshot the indices deleted with --drop-all-indices before deleting them")
+	cmd.Flags().IntVar(&opts.DeleteConcurrency, "delete-concurrency", defaultDeleteConcurrency, "Number of indices deleted in parallel with --drop-all-indices")
+	cmd.Flags().BoolVar(&opts.DisableRebalance, "disable-rebalance", false, "Disable shard rebalancing (cluster.routing.rebalance.enable: none) during the restore")
+	cmd.Flags().StringSliceVar(&opts.FeatureStates, "feature-states", nil, "Feature states to restore, e.g. kibana,security or none (default: elasticsearch.restore.featureStates)")
+	cmd.Flags().StringSliceVar(&opts.ExcludeIndices, "exclude-indices", nil, "Indices or patterns to leave out of the restore, e.g. sts_k8s_logs*")
+	cmd.Flags().StringSliceVar(&opts.Domains, "domain", nil, "Data domain to restore, repeatable: topology, metrics, logs, events or another domain of elasticsearch.restore.domains (default: all of elasticsearch.restore.indicesPattern)")
+	cmd.Flags().BoolVar(&opts.IgnoreUnavailable, "ignore-unavailable", false, "Skip indices of the restore pattern that are missing in the snapshot instead of failing")
+	cmd.Flags().IntVar(&opts.ForceMergeSegments, "force-merge-segments", 0, "Force merge the restored indices to this number of segments per shard (default: no force merge)")
+	cmd.Flags().BoolVar(&opts.AllowPartial, "allow-partial", false, "Restore a snapshot with failed shards; the indices of those shards are restored incomplete or not at all")
+	cmd.Flags().StringVar(&opts.OnConflict, "on-conflict", "", "What to do with snapshot indices that exist already: abort, skip or rename (default: ask, or abort without a terminal)")
+	cmd.Flags().StringVar(&opts.RenameSuffix, "rename-suffix", defaultRenameSuffix, "Suffix of the existing indices restored under another name with --on-conflict rename")
+	cmd.Flags().BoolVar(&opts.ImportPipelines, "import-pipelines", false, "Import the most recent ingest pipeline export (see export-pipelines) after the restore")
+	cmd.Flags().BoolVar(&opts.ImportILM, "import-ilm", false, "Import the most recent ILM policy export (see export-ilm) before the snapshot is restored")
+	cmd.Flags().BoolVarP(&opts.Interactive, "interactive", "i", false, "Select the snapshot interactively and confirm the restore plan")
+	cmd.Flags().StringVar(&opts.TargetNamespace, "target-namespace", "", "Namespace of the installation to restore into (default: --namespace)")
+	cmd.Flags().StringVar(&opts.TargetContext, "target-context", "", "Kubeconfig context of the cluster to restore into (default: current context)")
+	cmd.Flags().DurationVar(&opts.KeepAliveInterval, "keepalive-interval", portforward.DefaultKeepAliveInterval, "Time between keepalive requests through the port-forward while the restore runs (0 disables them)")
+	cmd.Flags().DurationVar(&opts.StatefulSetTimeout, "statefulset-timeout", defaultStatefulSetTimeout, "Time each StatefulSet of elasticsearch.restore.scaleDownStatefulSetSelectors has to scale down or become Ready")
+	cmd.Flags().StringVar(&opts.Events, "events", "", "Write progress events to stdout in this format (ndjson) for orchestration systems")
+	cmd.Flags().StringVar(&opts.ReportFile, "report", "", "Write a summary report of the restore to this file, as HTML for .html files and Markdown otherwise")
+	cmd.Flags().StringVar(&opts.Resume, "resume", "", "Run ID of a failed restore to resume, skipping the phases it completed (the snapshots are taken from that run)")
+	addRestoreJobFlags(cmd, jobOpts)
+	cmd.MarkFlagsOneRequired("snapshot-name", "interactive", "resume")
+	cmd.MarkFlagsMutuallyExclusive("snapshot-name", "interactive")
+	cmd.MarkFlagsMutuallyExclusive("resume", "interactive")
+	_ = cmd.RegisterFlagCompletionFunc("snapshot-name", completeSnapshotNames(cliCtx))
+	addConfirmNamespaceFlag(cmd, cliCtx)
+	addSkipHealthCheckFlag(cmd, cliCtx)
 	return cmd
 }
 
-func runRestore(cliCtx *config.Context) error {
-	// Create logger
-	log := logger.New(cliCtx.Config.Quiet, cliCtx.Config.Debug)
+// RestoreRequest is a restore run outside of the CLI, with the options of restore-snapshot that apply there
+type RestoreRequest struct {
+	SnapshotName string
+	// DropAllIndices deletes the existing STS indices first, after a safety snapshot unless SkipSafetySnapshot is set
+	DropAllIndices     bool
+	SkipSafetySnapshot bool
+	ExcludeIndices     []string
+	// Domains limit the restore to the indices of these data domains, see restore-snapshot --domain
+	Domains []string
+	// FeatureStates overrides the feature states to restore from the configuration
+	FeatureStates     []string
+	IgnoreUnavailable bool
+	AllowPartial      bool
+	// Progress, when set, is called after every step of the restore with the time it took
+	Progress func(step string, duration time.Duration)
+}
+
+// Restore restores a snapshot without prompting. It is used to run restores outside of the CLI, such as from
+// the HTTP API and pkg/backup.
+func Restore(cliCtx *config.Context, req RestoreRequest) error {
+	cliConfig := *cliCtx.Config
+	cliConfig.AssumeYes = true
+	return runRestore(&config.Context{Config: &cliConfig, RunID: cliCtx.RunID}, &restoreOptions{
+		SnapshotName:       req.SnapshotName,
+		DropAllIndices:     req.DropAllIndices,
+		SkipSafetySnapshot: req.SkipSafetySnapshot,
+		ExcludeIndices:     req.ExcludeIndices,
+		Domains:            req.Domains,
+		FeatureStates:      req.FeatureStates,
+		IgnoreUnavailable:  req.IgnoreUnavailable,
+		AllowPartial:       req.AllowPartial,
+		DeleteConcurrency:  defaultDeleteConcurrency,
+		RenameSuffix:       defaultRenameSuffix,
+		StatefulSetTimeout: defaultStatefulSetTimeout,
+		Progress:           req.Progress,
+	})
+}
 
-	// Create Kubernetes client
-	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.Debug)
+// validateRestoreOptions checks the option values that flag parsing cannot
+func validateRestoreOptions(opts *restoreOptions, assumeYes bool) error {
+	if err := parseSnapshotNames(opts); err != nil {
+		return err
+	}
+	// --yes would skip typing the snapshot name, the only confirmation of an interactive restore
+	if opts.Interactive && assumeYes {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--interactive cannot be combined with --yes: the snapshot name has to be typed to confirm the restore"))
+	}
+	if opts.DeleteConcurrency < 1 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--delete-concurrency must be at least 1, got %d", opts.DeleteConcurrency))
+	}
+	if opts.ForceMergeSegments < 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--force-merge-segments must not be negative, got %d", opts.ForceMergeSegments))
+	}
+	if err := validateConflictStrategy(opts.OnConflict); err != nil {
+		return err
+	}
+	if opts.OnConflict == conflictRename && opts.RenameSuffix == "" {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--rename-suffix must not be empty with --on-conflict rename"))
+	}
+	return nil
+}
+
+func runRestore(cliCtx *config.Context, opts *restoreOptions) (err error) {
+	if err := validateRestoreOptions(opts, cliCtx.Config.AssumeYes); err != nil {
+		return err
+	}
+
+	emitter, err := events.New(opts.Events, os.Stdout, cliCtx.RunID)
 	if err != nil {
-		return fmt.Errorf("failed to create Kubernetes client: %w", err)
+		return err
 	}
+	// The last event of the run, after the deployments have been scaled up again
+	defer func() {
+		emitter.Finished(err)
+	}()
 
-	// Load configuration
-	cfg, err := config.LoadConfig(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName)
+	env, err := target.Connect(cliCtx)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return err
 	}
+	log := env.Log
+	if opts.ReportFile != "" {
+		log.CollectWarnings()
+	}
+	opts.ValidateRestore = opts.ReportFile != "" || output.Format(cliCtx.Config.OutputFormat) == output.FormatJUnit
 
-	// Scale down deployments before restore
-	scaledDeployments, err := scaleDownDeployments(k8sClient, cliCtx.Config.Namespace, cfg.Elasticsearch.Restore.ScaleDownLabelSelector, log)
+	// From here on everything applies to the installation restored into
+	restoreTo, err := resolveRestoreTarget(cliCtx, opts, env.K8s, env.Config, log)
 	if err != nil {
 		return err
 	}
+	k8sClient, cliCtx, cfg := restoreTo.k8sClient, restoreTo.cliCtx, restoreTo.cfg
 
-	// Ensure deployments are scaled back up on exit (even if restore fails)
+	// A resumed restore continues the checkpoint of the interrupted run, which also selects the snapshots
+	startedAt := time.Now()
+	record := &restoreRecord{progress: opts.Progress, events: emitter}
+	checkpoints := checkpoint.NewStore(k8sClient.Clientset(), cliCtx.Config.Namespace, checkpoint.DefaultConfigMapName)
+	if err := resumeRestore(checkpoints, opts, record, log); err != nil {
+		return err
+	}
+	if err := applyRestoreOverrides(cfg, opts, log); err != nil {
+		return err
+	}
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := confirmClusterIdentity(k8sClient, cliCtx, prompter, log); err != nil {
+		return err
+	}
+	// Report the outcome once everything, including scaling up, is done
 	defer func() {
-		if len(scaledDeployments) > 0 {
-			log.Println()
-			log.Infof("Scaling up deployments back to original replica counts...")
-			if err := k8sClient.ScaleUpDeployments(cliCtx.Config.Namespace, scaledDeployments); err != nil {
-				log.Warningf("Failed to scale up deployments: %v", err)
-			} else {
-				log.Successf("Scaled up %d deployment(s) successfully:", len(scaledDeployments))
-				for _, dep := range scaledDeployments {
-					log.Infof("  - %s (replicas: 0 -> %d)", dep.Name, dep.Replicas)
-				}
-			}
-		}
+		err = reportRestore(k8sClient, cliCtx, cfg, opts, record, startedAt, err, log)
 	}()
 
-	// Setup port-forward to Elasticsearch
-	serviceName := cfg.Elasticsearch.Service.Name
-	localPort := cfg.Elasticsearch.Service.LocalPortForwardPort
-	remotePort := cfg.Elasticsearch.Service.Port
+	// Read the exports to import before anything is changed, so a missing export fails the restore early
+	imports, err := loadRestoreImports(k8sClient, cliCtx, cfg, opts, log)
+	if err != nil {
+		return err
+	}
 
-	pf, err := portforward.SetupPortForward(k8sClient, cliCtx.Config.Namespace, serviceName, localPort, remotePort, log)
+	esClient, cleanup, err := connectRestoreTarget(restoreTo, opts.KeepAliveInterval, log)
 	if err != nil {
 		return err
 	}
-	defer close(pf.StopChan)
+	defer cleanup()
 
-	// Create Elasticsearch client
-	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", pf.LocalPort))
+	if prompter, err = selectAndConfirmSnapshot(esClient, cliCtx, cfg, opts, prompter, log); err != nil {
+		return err
+	}
+
+	// Record the progress, so a failed restore can be resumed with --resume
+	if record.checkpoint == nil {
+		record.checkpoint = newRestoreCheckpoint(checkpoints, cliCtx.RunID, opts, log)
+	}
+	defer func() {
+		record.checkpoint.finish(err)
+	}()
+
+	if err := checkRestorable(esClient, cliCtx, cfg, opts, prompter, record, log); err != nil {
+		return err
+	}
+
+	restoreFinished := recordRestoreEvents(k8sClient, cliCtx, opts, log)
+	defer func() {
+		restoreFinished(err)
+	}()
+
+	// Scale down before the restore, and back up on exit (even if the restore fails)
+	scaleUp, err := scaleDownForRestore(k8sClient, cliCtx, cfg, opts, record, log)
+	defer scaleUp()
 	if err != nil {
-		return fmt.Errorf("failed to create Elasticsearch client: %w", err)
+		return err
+	}
+
+	return dropIndicesAndRestore(esClient, cfg, opts, imports, prompter, record, log)
+}
+
+// resumeRestore continues the checkpoint of the interrupted run given with --resume, which also selects the
+// snapshots, in record
+func resumeRestore(store checkpointStore, opts *restoreOptions, record *restoreRecord, log *logger.Logger) error {
+	if opts.Resume == "" {
+		return nil
+	}
+	resumed, err := resumeRestoreCheckpoint(store, opts, log)
+	if err != nil {
+		return err
+	}
+	record.resumeFrom(resumed)
+	return nil
+}
+
+// reportRestore notifies the configured targets about the outcome err of the restore, writes the summary report,
+// prints the validation results and records the restore, any deleted indices and the safety snapshot in the audit
+// log. It returns err, joined with the failure to write the audit log.
+func reportRestore(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, record *restoreRecord, startedAt time.Time, err error, log *logger.Logger) error {
+	sendNotification(cfg, cliCtx, "restore", startedAt, err, map[string]string{"snapshot": opts.SnapshotName}, log)
+	writeRestoreReport(cliCtx, cfg, opts, record, startedAt, err, log)
+	printShardFailures(cliCtx, record, err, log)
+	printRestoreValidation(cliCtx, record, err, log)
+	return target.RecordAudit(k8sClient, cliCtx, record.auditEntry(cfg, opts, startedAt), err, log)
+}
+
+// applyRestoreOverrides applies the feature states, repository and data domains given on the command line to cfg
+func applyRestoreOverrides(cfg *config.Config, opts *restoreOptions, log *logger.Logger) error {
+	if len(opts.FeatureStates) > 0 {
+		cfg.Elasticsearch.Restore.FeatureStates = opts.FeatureStates
+	}
+	if opts.SnapshotRepository != "" {
+		cfg.Elasticsearch.Restore.Repository = opts.SnapshotRepository
+	}
+	if len(opts.Domains) > 0 {
+		pattern, err := cfg.Elasticsearch.Restore.DomainPattern(opts.Domains)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		log.Infof("Restoring only the %s data: %s", strings.Join(opts.Domains, ", "), pattern)
+		cfg.Elasticsearch.Restore.IndicesPattern = pattern
+	}
+	return nil
+}
+
+// checkRestorable refuses a partial snapshot and handles the indices that exist already up front, instead of failing
+// the restore halfway, before anything is changed. With --drop-all-indices they are deleted first; after a completed
+// restore they are the restored indices.
+func checkRestorable(esClient *elasticsearch.Client, cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, prompter *prompt.Prompter, record *restoreRecord, log *logger.Logger) error {
+	if err := checkSnapshotComplete(esClient, cfg, opts, log); err != nil {
+		return err
+	}
+	if opts.DropAllIndices || record.checkpoint.completed(phaseRestore) {
+		return nil
 	}
 
 	repository := cfg.Elasticsearch.Restore.Repository
+	refs := append([]snapshotRef{{Repository: repository, Name: opts.SnapshotName}}, additionalSnapshotRefs(opts, repository)...)
+	pattern := elasticsearch.RestoreOptions{Indices: cfg.Elasticsearch.Restore.IndicesPattern, ExcludeIndices: opts.ExcludeIndices}
+	ask := !cliCtx.Config.AssumeYes && prompter.Interactive()
+	return resolveRestoreConflicts(esClient, refs, pattern.IndexPattern(), opts, prompter, ask, log)
+}
 
-	// Get all indices and filter for STS indices
-	log.Infof("Fetching current Elasticsearch indices...")
-	allIndices, err := esClient.ListIndices("*")
+// recordRestoreEvents records the start of the restore in a Kubernetes Event, so the run can be traced from the
+// cluster, and returns the function that records its outcome
+func recordRestoreEvents(k8sClient *k8s.Client, cliCtx *config.Context, opts *restoreOptions, log *logger.Logger) func(error) {
+	recordEvent(k8sClient, cliCtx, k8s.EventTypeNormal, "RestoreStarted", fmt.Sprintf("Restoring snapshot '%s'", opts.SnapshotName), log)
+	return func(err error) {
+		if err != nil {
+			recordEvent(k8sClient, cliCtx, k8s.EventTypeWarning, "RestoreFailed", fmt.Sprintf("Restore of snapshot '%s' failed: %v", opts.SnapshotName, err), log)
+			return
+		}
+		recordEvent(k8sClient, cliCtx, k8s.EventTypeNormal, "RestoreCompleted", fmt.Sprintf("Restore of snapshot '%s' completed", opts.SnapshotName), log)
+	}
+}
+
+// scaleDownForRestore enables maintenance mode, so the receivers stop accepting data, then scales down the deployments
+// writing to Elasticsearch and after them the ingest or coordinating-only nodes, so these flush what they buffered.
+// The returned function undoes this in reverse order. It is returned, and has to be called, also when scaling down
+// fails halfway.
+func scaleDownForRestore(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, record *restoreRecord, log *logger.Logger) (func(), error) {
+	namespace := cliCtx.Config.Namespace
+	var undo []func()
+	scaleUp := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	disableMaintenance, err := enableMaintenanceMode(k8sClient, namespace, cfg.Elasticsearch.Restore.Maintenance, log)
 	if err != nil {
-		return fmt.Errorf("failed to list indices: %w", err)
+		return scaleUp, err
 	}
+	undo = append(undo, disableMaintenance)
 
-	stsIndices := filterSTSIndices(allIndices, cfg.Elasticsearch.Restore.IndexPrefix, cfg.Elasticsearch.Restore.DatastreamIndexPrefix)
+	stepStart := record.startStep("Scale down deployments")
+	scaledDeployments, err := target.ScaleDownDeployments(k8sClient, namespace, cfg.Elasticsearch.Restore.ScaleDownLabelSelector, log)
+	if err != nil {
+		return scaleUp, err
+	}
+	record.checkpoint.scaledDeployments(scaledDeployments)
+	record.timeStep("Scale down deployments", stepStart)
+	undo = append(undo, func() {
+		stepStart := record.startStep("Scale up deployments")
+		target.ScaleUpDeployments(k8sClient, namespace, scaledDeployments, log)
+		record.timeStep("Scale up deployments", stepStart)
+	})
+
+	selectors := cfg.Elasticsearch.Restore.ScaleDownStatefulSetSelectors
+	if len(selectors) == 0 {
+		return scaleUp, nil
+	}
+	stepStart = record.startStep("Scale down statefulsets")
+	scaledStatefulSets, err := target.ScaleDownStatefulSets(k8sClient, namespace, selectors, opts.StatefulSetTimeout, log)
+	undo = append(undo, func() {
+		stepStart := record.startStep("Scale up statefulsets")
+		target.ScaleUpStatefulSets(k8sClient, namespace, scaledStatefulSets, opts.StatefulSetTimeout, log)
+		record.timeStep("Scale up statefulsets", stepStart)
+	})
+	if err != nil {
+		return scaleUp, err
+	}
+	record.checkpoint.scaledStatefulSets(scaledStatefulSets)
+	record.timeStep("Scale down statefulsets", stepStart)
+	return scaleUp, nil
+}
+
+// restoreImports holds the exported configuration that is imported as part of a restore
+type restoreImports struct {
+	// ilmPolicies are imported before the snapshot is restored, so the restored indices find their policy
+	ilmPolicies map[string]json.RawMessage
+	// pipelines are imported after the snapshot is restored
+	pipelines map[string]json.RawMessage
+}
+
+// loadRestoreImports reads the most recent exports the restore imports, as selected by opts
+func loadRestoreImports(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, log *logger.Logger) (restoreImports, error) {
+	var imports restoreImports
+	var err error
+	if opts.ImportPipelines {
+		if imports.pipelines, err = loadPipelines(k8sClient, cliCtx, cfg, "", log); err != nil {
+			return imports, err
+		}
+	}
+	if opts.ImportILM {
+		if imports.ilmPolicies, err = loadILMPolicies(k8sClient, cliCtx, cfg, "", log); err != nil {
+			return imports, err
+		}
+	}
+	return imports, nil
+}
+
+// restoreRecord collects what a restore changed, for the audit log, and how it went, for the report
+type restoreRecord struct {
+	deletedIndices []string
+	safetySnapshot string
+	// checkpoint records the progress of the restore for --resume; nil when the restore cannot be resumed
+	checkpoint *restoreCheckpoint
+
+	steps []report.Step
+	// progress is called with every step recorded, see RestoreRequest
+	progress func(step string, duration time.Duration)
+	// events receives the start and end of every step, the deleted indices and the restore progress
+	events   *events.Emitter
+	snapshot *elasticsearch.Snapshot
+	// snapshotSize is the total size of the snapshot in bytes; 0 when not looked up, -1 when unknown
+	snapshotSize    int64
+	restoredIndices []string
+	checks          []report.Check
+}
+
+// auditEntry returns the audit log entry of a restore started at startedAt; see 'restore-history'
+func (r *restoreRecord) auditEntry(cfg *config.Config, opts *restoreOptions, startedAt time.Time) audit.Entry {
+	pattern := elasticsearch.RestoreOptions{Indices: cfg.Elasticsearch.Restore.IndicesPattern, ExcludeIndices: opts.ExcludeIndices}
+	return audit.Entry{
+		Operation:  "restore",
+		Snapshot:   opts.SnapshotName,
+		Repository: cfg.Elasticsearch.Restore.Repository,
+		// Additional snapshots are recorded as <repository>/<name>
+		AdditionalSnapshots: additionalSnapshotNames(opts, cfg.Elasticsearch.Restore.Repository),
+		IndicesPattern:      pattern.IndexPattern(),
+		IndicesDeleted:      r.deletedIndices,
+		SafetySnapshot:      r.safetySnapshot,
+		DurationMillis:      time.Since(startedAt).Milliseconds(),
+	}
+}
+
+// dropIndicesAndRestore deletes the existing STS indices when requested and restores the snapshot, with shard
+// rebalancing disabled when requested, together with the imports, if any.
+// The deleted indices and the safety snapshot are recorded in record, also when the restore fails.
+func dropIndicesAndRestore(esClient *elasticsearch.Client, cfg *config.Config, opts *restoreOptions, imports restoreImports, prompter *prompt.Prompter, record *restoreRecord, log *logger.Logger) error {
+	checkShardAllocation(esClient, log)
+	if opts.DisableRebalance {
+		enableRebalance, err := disableRebalance(esClient, log)
+		if err != nil {
+			return err
+		}
+		defer enableRebalance()
+	}
+
+	switch {
+	case opts.DropAllIndices && record.checkpoint.completed(phaseDeleteIndices):
+		record.checkpoint.skip("the deletion of indices")
+		if !record.checkpoint.completed(phaseRestore) {
+			if err := deleteInterruptedRestore(esClient, cfg, opts, record, prompter, log); err != nil {
+				return err
+			}
+		}
+	case opts.DropAllIndices:
+		// Get all indices and filter for STS indices
+		log.Infof("Fetching current Elasticsearch indices...")
+		allIndices, err := esClient.ListIndices("*")
+		if err != nil {
+			return fmt.Errorf("failed to list indices: %w", err)
+		}
+
+		stsIndices := indicesToDrop(allIndices, cfg, opts)
 
-	if dropAllIndices {
 		log.Println()
-		if err := deleteIndices(esClient, stsIndices, cfg, log, skipConfirmation); err != nil {
+		stepStart := record.startStep("Delete indices")
+		if err := deleteIndices(esClient, stsIndices, cfg, opts, record, log, prompter); err != nil {
 			return err
 		}
+		record.timeStep("Delete indices", stepStart)
+		record.checkpoint.complete(phaseDeleteIndices)
 	}
 
-	// Restore snapshot
+	switch {
+	case imports.ilmPolicies != nil && record.checkpoint.completed(phaseImportILM):
+		record.checkpoint.skip("the import of ILM policies")
+	case imports.ilmPolicies != nil:
+		log.Infof("Importing %d ILM policy(ies)...", len(imports.ilmPolicies))
+		stepStart := record.startStep("Import ILM policies")
+		if err := importILMPolicies(esClient, imports.ilmPolicies, log); err != nil {
+			return err
+		}
+		record.timeStep("Import ILM policies", stepStart)
+		record.checkpoint.complete(phaseImportILM)
+	}
+
+	stepStart := record.startStep("Restore snapshot")
+	if err := restoreSnapshot(esClient, cfg, opts, record, log); err != nil {
+		return err
+	}
+	record.timeStep("Restore snapshot", stepStart)
+
+	switch {
+	case imports.pipelines != nil && record.checkpoint.completed(phaseImportPipelines):
+		record.checkpoint.skip("the import of ingest pipelines")
+	case imports.pipelines != nil:
+		log.Infof("Importing %d ingest pipeline(s)...", len(imports.pipelines))
+		stepStart := record.startStep("Import ingest pipelines")
+		if err := importPipelines(esClient, imports.pipelines, log); err != nil {
+			return err
+		}
+		record.timeStep("Import ingest pipelines", stepStart)
+		record.checkpoint.complete(phaseImportPipelines)
+	}
+	return nil
+}
+
+// connectRestoreTarget connects to Elasticsearch of the target and, for another installation, registers the
+// snapshot repository of the source. The port-forward is kept alive every keepAlive, so it survives restores that
+// take hours. The returned cleanup function closes the port-forward.
+func connectRestoreTarget(target *restoreTarget, keepAlive time.Duration, log *logger.Logger) (*elasticsearch.Client, func(), error) {
+	if err := checkElasticsearchPods(target.k8sClient, target.cliCtx, target.cfg, log); err != nil {
+		return nil, nil, err
+	}
+
+	// Setup port-forward to Elasticsearch
+	pf, err := portForwardElasticsearch(target.k8sClient, target.cliCtx, target.cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { close(pf.StopChan) }
+	pf.KeepAlive(keepAlive, log)
+
+	// Create Elasticsearch client
+	esClient, err := newESClient(target.k8sClient, target.cliCtx, target.cfg, pf.LocalPort, log)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	if target.remote {
+		if err := registerSourceRepository(esClient, target.cfg, log); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+	return esClient, cleanup, nil
+}
+
+// restoreSnapshot restores the snapshot from the configured repository, and the additional snapshots of opts
+// concurrently with it, and waits for completion. The snapshot is recorded in record and, with
+// opts.ValidateRestore, the restored indices are validated against it.
+func restoreSnapshot(esClient *elasticsearch.Client, cfg *config.Config, opts *restoreOptions, record *restoreRecord, log *logger.Logger) error {
+	repository := cfg.Elasticsearch.Restore.Repository
+	snapshotName := opts.SnapshotName
+
 	log.Println()
 	log.Infof("Restoring snapshot '%s' from repository '%s'", snapshotName, repository)
+	additional := additionalSnapshotRefs(opts, repository)
+	for _, ref := range additional {
+		log.Infof("Restoring snapshot '%s' from repository '%s' concurrently", ref.Name, ref.Repository)
+	}
 
 	// Get snapshot details to show indices
 	snapshot, err := esClient.GetSnapshot(repository, snapshotName)
 	if err != nil {
 		return fmt.Errorf("failed to get snapshot details: %w", err)
 	}
+	record.snapshot = snapshot
 
-	log.Debugf("Indices pattern: %s", cfg.Elasticsearch.Restore.IndicesPattern)
+	restoreCfg := cfg.Elasticsearch.Restore
+	log.Debugf("Indices pattern: %s", restoreCfg.IndicesPattern)
+	if len(opts.ExcludeIndices) > 0 {
+		log.Warningf("Excluding from the restore: %s", strings.Join(opts.ExcludeIndices, ", "))
+	}
+	if len(restoreCfg.FeatureStates) > 0 {
+		log.Infof("Restoring feature states: %s", strings.Join(restoreCfg.FeatureStates, ", "))
+	}
 
 	if len(snapshot.Indices) == 0 {
 		log.Warningf("Snapshot contains no indices")
@@ -142,14 +656,179 @@ func runRestore(cliCtx *config.Context) error {
 		}
 	}
 
-	log.Infof("Starting restore - this may take several minutes...")
+	restoreOpts := elasticsearch.RestoreOptions{
+		Indices:           restoreCfg.IndicesPattern,
+		FeatureStates:     restoreCfg.FeatureStates,
+		ExcludeIndices:    opts.ExcludeIndices,
+		IgnoreUnavailable: opts.IgnoreUnavailable,
+		Partial:           opts.AllowPartial,
+		RenamePattern:     opts.RenamePattern,
+		RenameReplacement: opts.RenameReplacement,
+		// The renamed copies would otherwise share the aliases, including write aliases, of the existing indices
+		ExcludeAliases:    opts.RenamePattern != "",
+		WaitForCompletion: true,
+	}
+	refs := append([]snapshotRef{{Repository: repository, Name: snapshotName}}, additional...)
+	if record.checkpoint.completed(phaseRestore) {
+		record.checkpoint.skip("the restore of the snapshot")
+	} else {
+		log.Infof("Starting restore - this may take several minutes...")
+		if err := restoreSnapshots(esClient, refs, restoreOpts, restoreProgressInterval, record.events, log); err != nil {
+			return err
+		}
+		record.checkpoint.complete(phaseRestore)
 
-	if err := esClient.RestoreSnapshot(repository, snapshotName, cfg.Elasticsearch.Restore.IndicesPattern, true); err != nil {
-		return fmt.Errorf("failed to restore snapshot: %w", err)
+		log.Println()
+		log.Successf("Restore completed successfully")
 	}
+	if err := finishRestoredIndices(esClient, restoreOpts.IndexPattern(), opts.ForceMergeSegments, log); err != nil {
+		return err
+	}
+	if opts.ValidateRestore {
+		inspectRestore(esClient, repository, restoreOpts.IndexPattern(), record, log)
+	}
+	return nil
+}
 
-	log.Println()
-	log.Successf("Restore completed successfully")
+// restoreConnectionLostError explains that the connection broke off while waiting for the restore, which then
+// continues in Elasticsearch, and how to follow it to the end
+func restoreConnectionLostError(snapshotName string, err error) error {
+	return fmt.Errorf("lost the connection to Elasticsearch while waiting for the restore of snapshot '%s' (%w). "+
+		"The restore continues in Elasticsearch: follow it with 'sts-backup elasticsearch recovery-status' until no "+
+		"snapshot recoveries are left. The steps after the restore, such as --force-merge-segments and "+
+		"--import-pipelines, did not run", snapshotName, err)
+}
+
+// checkSnapshotComplete fetches the snapshots to restore, checks them with checkPartialSnapshot and checks the restore
+// pattern against their indices with lintRestorePattern
+func checkSnapshotComplete(esClient *elasticsearch.Client, cfg *config.Config, opts *restoreOptions, log *logger.Logger) error {
+	repository := cfg.Elasticsearch.Restore.Repository
+	refs := append([]snapshotRef{{Repository: repository, Name: opts.SnapshotName}}, additionalSnapshotRefs(opts, repository)...)
+	snapshots := make([]*elasticsearch.Snapshot, 0, len(refs))
+	for _, ref := range refs {
+		snapshot, err := esClient.GetSnapshot(ref.Repository, ref.Name)
+		if err != nil {
+			return snapshotLookupError(err)
+		}
+		if err := checkPartialSnapshot(snapshot, opts.AllowPartial, log); err != nil {
+			return err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	pattern := elasticsearch.RestoreOptions{Indices: cfg.Elasticsearch.Restore.IndicesPattern, ExcludeIndices: opts.ExcludeIndices}
+	narrowed := len(opts.Domains) > 0 || len(opts.ExcludeIndices) > 0
+	return lintRestorePattern(snapshots, pattern.IndexPattern(), narrowed, log)
+}
+
+// checkPartialSnapshot refuses to restore a snapshot with failed shards unless allowPartial is set,
+// in which case it warns which indices will be incomplete or missing after the restore
+func checkPartialSnapshot(snapshot *elasticsearch.Snapshot, allowPartial bool, log *logger.Logger) error {
+	failed := snapshot.FailedIndices()
+	if snapshot.State != "PARTIAL" && len(failed) == 0 {
+		return nil
+	}
+
+	missing := "unknown index(es)"
+	if len(failed) > 0 {
+		missing = "index(es) " + strings.Join(failed, ", ")
+	}
+	if !allowPartial {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("snapshot %s is %s with %d failed shard(s) in %s: use --allow-partial to restore the healthy indices",
+			snapshot.Snapshot, snapshot.State, snapshot.Shards.Failed, missing))
+	}
+
+	log.Warningf("Snapshot '%s' is %s: %d failed shard(s) in %s will be missing after the restore", snapshot.Snapshot, snapshot.State, snapshot.Shards.Failed, missing)
+	for _, failure := range snapshot.Failures {
+		log.Warningf("  - %s shard %d: %s", failure.Index, failure.ShardID, failure.Reason)
+	}
+	return nil
+}
+
+// selectAndConfirmSnapshot lets the operator pick a snapshot with --interactive, shows the restore plan and
+// requires the snapshot name to be typed to confirm. On success opts.SnapshotName is set and the prompter for the
+// rest of the restore is returned, which asks nothing more since the plan, including index deletion, has been
+// confirmed explicitly. Without --interactive prompter is returned as is.
+func selectAndConfirmSnapshot(esClient *elasticsearch.Client, cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, prompter *prompt.Prompter, log *logger.Logger) (*prompt.Prompter, error) {
+	if !opts.Interactive {
+		return prompter, nil
+	}
+	if err := confirmSelectedSnapshot(esClient, cliCtx, cfg, opts, prompter, log); err != nil {
+		return nil, err
+	}
+	return prompt.New(true), nil
+}
+
+// confirmSelectedSnapshot lets the operator pick a snapshot, shows the restore plan and
+// requires the snapshot name to be typed to confirm. On success opts.SnapshotName is set.
+func confirmSelectedSnapshot(esClient *elasticsearch.Client, cliCtx *config.Context, cfg *config.Config, opts *restoreOptions, prompter *prompt.Prompter, log *logger.Logger) error {
+	if !prompter.Interactive() {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--interactive requires a terminal: use --snapshot-name instead"))
+	}
+
+	repository := cfg.Elasticsearch.Restore.Repository
+	log.Infof("Fetching snapshots from repository '%s'...", repository)
+	snapshots, err := esClient.ListSnapshots(repository)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	now := time.Now()
+	selected, err := selectSnapshot(snapshots, prompter, os.Stdout, now)
+	if err != nil {
+		return err
+	}
+
+	plan := restorePlan{
+		Snapshot:             *selected,
+		SizeInBytes:          -1,
+		Repository:           repository,
+		Namespace:            cliCtx.Config.Namespace,
+		ScaleDownSelector:    cfg.Elasticsearch.Restore.ScaleDownLabelSelector,
+		StatefulSetSelectors: cfg.Elasticsearch.Restore.ScaleDownStatefulSetSelectors,
+		IndicesPattern:       cfg.Elasticsearch.Restore.IndicesPattern,
+		DropAllIndices:       opts.DropAllIndices,
+		RenameSuffix:         opts.RenameSuffix,
+		DisableRebalance:     opts.DisableRebalance,
+		Maintenance:          cfg.Elasticsearch.Restore.Maintenance,
+	}
+	if opts.DropAllIndices && !opts.SkipSafetySnapshot {
+		plan.SafetySnapshotRepository = cfg.Elasticsearch.SLM.Repository
+	}
+
+	log.Infof("Fetching size of snapshot '%s'...", selected.Snapshot)
+	if size, err := esClient.SnapshotSize(repository, selected.Snapshot); err != nil {
+		log.Warningf("Could not determine snapshot size: %v", err)
+	} else {
+		plan.SizeInBytes = size
+	}
+
+	allIndices, err := esClient.ListIndices("*")
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	if opts.DropAllIndices {
+		plan.IndicesToDelete = indicesToDrop(allIndices, cfg, opts)
+	} else {
+		// The conflict strategy is part of the plan, so it is chosen before the plan is confirmed
+		pattern := elasticsearch.RestoreOptions{Indices: cfg.Elasticsearch.Restore.IndicesPattern, ExcludeIndices: opts.ExcludeIndices}
+		plan.Conflicts = restoreConflicts(selected.Indices, allIndices, pattern.IndexPattern())
+		if len(plan.Conflicts) > 0 && opts.OnConflict == "" && !cliCtx.Config.AssumeYes {
+			log.Warningf("%d index(es) of the snapshot already exist", len(plan.Conflicts))
+			if opts.OnConflict, err = askConflictStrategy(prompter); err != nil {
+				return err
+			}
+		}
+		plan.ConflictStrategy = opts.OnConflict
+	}
+
+	printRestorePlan(os.Stdout, plan, now)
+
+	if err := prompter.ConfirmValue("Type the snapshot name to confirm the restore:", selected.Snapshot); err != nil {
+		return err
+	}
+
+	opts.SnapshotName = selected.Snapshot
 	return nil
 }
 
@@ -164,19 +843,21 @@ func filterSTSIndices(allIndices []string, indexPrefix, datastreamPrefix string)
 	return stsIndices
 }
 
-// confirmDeletion prompts the user to confirm index deletion
-func confirmDeletion() error {
-	fmt.Print("\nAre you sure you want to delete these indices? (yes/no): ")
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return fmt.Errorf("failed to read confirmation: %w", err)
+// indicesToDrop returns the STS indices deleted with --drop-all-indices. With --domain only the indices of the
+// restore pattern, which holds the patterns of the domains, are deleted, so the other domains are left alone.
+func indicesToDrop(allIndices []string, cfg *config.Config, opts *restoreOptions) []string {
+	stsIndices := filterSTSIndices(allIndices, cfg.Elasticsearch.Restore.IndexPrefix, cfg.Elasticsearch.Restore.DatastreamIndexPrefix)
+	if len(opts.Domains) == 0 {
+		return stsIndices
 	}
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response != "yes" && response != "y" {
-		return fmt.Errorf("restore cancelled by user")
+
+	var indices []string
+	for _, index := range stsIndices {
+		if matchesIndexPattern(index, cfg.Elasticsearch.Restore.IndicesPattern) {
+			indices = append(indices, index)
+		}
 	}
-	return nil
+	return indices
 }
 
 // hasDatastreamIndices checks if any indices belong to a datastream
@@ -190,7 +871,7 @@ func hasDatastreamIndices(indices []string, datastreamPrefix string) bool {
 }
 
 // deleteIndexWithVerification deletes an index and verifies it's gone
-func deleteIndexWithVerification(esClient *elasticsearch.Client, index string, log *logger.Logger) error {
+func deleteIndexWithVerification(esClient indexDeleter, index string, log *logger.Logger) error {
 	log.Infof("  Deleting index: %s", index)
 	if err := esClient.DeleteIndex(index); err != nil {
 		return fmt.Errorf("failed to delete index %s: %w", index, err)
@@ -214,29 +895,35 @@ func deleteIndexWithVerification(esClient *elasticsearch.Client, index string, l
 	return nil
 }
 
-// scaleDownDeployments scales down deployments matching the label selector
-func scaleDownDeployments(k8sClient *k8s.Client, namespace, labelSelector string, log *logger.Logger) ([]k8s.DeploymentScale, error) {
-	log.Infof("Scaling down deployments (selector: %s)...", labelSelector)
+// enableMaintenanceMode sets the configured maintenance flag, so the receivers stop accepting data while the restore
+// runs. The returned function clears the flag again, only warning when that fails. Without a configured flag
+// nothing is changed.
+func enableMaintenanceMode(k8sClient *k8s.Client, namespace string, maintenance config.MaintenanceConfig, log *logger.Logger) (func(), error) {
+	if !maintenance.Enabled() {
+		return func() {}, nil
+	}
 
-	scaledDeployments, err := k8sClient.ScaleDownDeployments(namespace, labelSelector)
+	flag := k8s.MaintenanceFlag{ConfigMap: maintenance.ConfigMap, Key: maintenance.Key, Value: maintenance.Value}
+	log.Infof("Enabling maintenance mode (ConfigMap %s, %s=%s)...", flag.ConfigMap, flag.Key, flag.Value)
+	state, err := k8sClient.SetMaintenanceFlag(namespace, flag)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scale down deployments: %w", err)
+		return nil, fmt.Errorf("failed to enable maintenance mode: %w", err)
 	}
+	log.Successf("Maintenance mode enabled")
 
-	if len(scaledDeployments) == 0 {
-		log.Infof("No deployments found to scale down")
-	} else {
-		log.Successf("Scaled down %d deployment(s):", len(scaledDeployments))
-		for _, dep := range scaledDeployments {
-			log.Infof("  - %s (replicas: %d -> 0)", dep.Name, dep.Replicas)
+	return func() {
+		log.Infof("Disabling maintenance mode...")
+		if err := k8sClient.ClearMaintenanceFlag(namespace, flag, state); err != nil {
+			log.Warningf("Failed to disable maintenance mode, clear %s in ConfigMap %s manually: %v", flag.Key, flag.ConfigMap, err)
+			return
 		}
-	}
-
-	return scaledDeployments, nil
+		log.Successf("Maintenance mode disabled")
+	}, nil
 }
 
-// deleteIndices handles the deletion of all STS indices including datastream rollover
-func deleteIndices(esClient *elasticsearch.Client, stsIndices []string, cfg *config.Config, log *logger.Logger, skipConfirm bool) error {
+// deleteIndices handles the deletion of all STS indices including datastream rollover. The indices that were
+// deleted are recorded in record, also when a later deletion fails.
+func deleteIndices(esClient *elasticsearch.Client, stsIndices []string, cfg *config.Config, opts *restoreOptions, record *restoreRecord, log *logger.Logger, prompter *prompt.Prompter) error {
 	if len(stsIndices) == 0 {
 		log.Infof("No STS indices found to delete")
 		return nil
@@ -248,10 +935,26 @@ func deleteIndices(esClient *elasticsearch.Client, stsIndices []string, cfg *con
 	}
 
 	// Confirmation prompt
-	if !skipConfirm {
-		if err := confirmDeletion(); err != nil {
+	if err := prompter.Confirm("Are you sure you want to delete these indices?"); err != nil {
+		return fmt.Errorf("restore aborted: %w", err)
+	}
+
+	// Keep a copy of the indices to roll back to, before anything is deleted
+	switch {
+	case record.safetySnapshot != "":
+		// A resumed restore keeps the snapshot taken before the interrupted run deleted the first indices
+		log.Infof("Keeping safety snapshot '%s' of the interrupted run", record.safetySnapshot)
+	case opts.SkipSafetySnapshot:
+		log.Warningf("Skipping the safety snapshot: the deleted indices cannot be rolled back")
+	default:
+		stepStart := record.startStep("Safety snapshot")
+		name, err := takeSafetySnapshot(esClient, cfg.Elasticsearch.SLM.Repository, stsIndices, time.Now(), log)
+		if err != nil {
 			return err
 		}
+		record.safetySnapshot = name
+		record.checkpoint.deletedIndices(record.deletedIndices, name)
+		record.timeStep("Safety snapshot", stepStart)
 	}
 
 	// Check for datastream and rollover if needed
@@ -264,12 +967,54 @@ func deleteIndices(esClient *elasticsearch.Client, stsIndices []string, cfg *con
 	}
 
 	// Delete all indices
-	log.Infof("Deleting %d index(es)...", len(stsIndices))
-	for _, index := range stsIndices {
-		if err := deleteIndexWithVerification(esClient, index, log); err != nil {
-			return err
-		}
+	log.Infof("Deleting %d index(es) (%d in parallel)...", len(stsIndices), opts.DeleteConcurrency)
+	deleted, err := deleteIndicesConcurrently(esClient, stsIndices, opts.DeleteConcurrency, record.events, log)
+	record.deletedIndices = append(record.deletedIndices, deleted...)
+	record.checkpoint.deletedIndices(record.deletedIndices, record.safetySnapshot)
+	if err != nil {
+		return err
 	}
 	log.Successf("All indices deleted successfully")
 	return nil
 }
+
+// deleteIndicesConcurrently deletes and verifies the indices with at most concurrency deletions in flight.
+// A failed index does not stop the others; all failures are returned together with the indices that
+// were deleted, in their original order.
+func deleteIndicesConcurrently(esClient indexDeleter, indices []string, concurrency int, emitter *events.Emitter, log *logger.Logger) ([]string, error) {
+	errs := make([]error, len(indices))
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(indices)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				errs[i] = deleteIndexWithVerification(esClient, indices[i], log)
+				if errs[i] == nil {
+					emitter.IndexDeleted(indices[i])
+				}
+			}
+		}()
+	}
+	for i := range indices {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	deleted := make([]string, 0, len(indices))
+	var failed []error
+	for i, index := range indices {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			continue
+		}
+		deleted = append(deleted, index)
+	}
+	if len(failed) > 0 {
+		return deleted, fmt.Errorf("failed to delete %d of %d index(es): %w", len(failed), len(indices), errors.Join(failed...))
+	}
+	return deleted, nil
+}
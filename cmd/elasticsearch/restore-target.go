@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"fmt"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// restoreTarget is the installation a snapshot is restored into. It is the source installation
+// unless --target-namespace or --target-context is given.
+type restoreTarget struct {
+	k8sClient *k8s.Client
+	// cliCtx is the CLI context with the namespace of the target
+	cliCtx *config.Context
+	// cfg is the target configuration, restoring from the repository of the source
+	cfg *config.Config
+	// remote is set when the target is another installation than the source
+	remote bool
+}
+
+// resolveRestoreTarget returns the installation to restore into. For another installation its configuration
+// is loaded from the ConfigMap and Secret of the same name in the target namespace, and the snapshot
+// repository of the source is restored from.
+func resolveRestoreTarget(cliCtx *config.Context, opts *restoreOptions, k8sClient *k8s.Client, cfg *config.Config, log *logger.Logger) (*restoreTarget, error) {
+	sourceNamespace := cliCtx.Config.Namespace
+	if opts.TargetNamespace == "" && opts.TargetContext == "" {
+		return &restoreTarget{k8sClient: k8sClient, cliCtx: cliCtx, cfg: cfg}, nil
+	}
+
+	targetClient := k8sClient
+	if opts.TargetContext != "" {
+		var err error
+		targetClient, err = k8s.NewClientForContext(cliCtx.Config.Kubeconfig, opts.TargetContext, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client for target context: %w", err))
+		}
+	}
+
+	cliConfig := *cliCtx.Config
+	if opts.TargetNamespace != "" {
+		cliConfig.Namespace = opts.TargetNamespace
+	}
+	if opts.TargetContext == "" && cliConfig.Namespace == sourceNamespace {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("--target-namespace '%s' is the source namespace", opts.TargetNamespace))
+	}
+
+	targetCfg, err := config.LoadConfig(targetClient.Clientset(), cliConfig.Namespace, cliConfig.ConfigMapName, cliConfig.SecretName, config.WithHelmValues(cliConfig.HelmValues))
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("failed to load target configuration from namespace '%s': %w", cliConfig.Namespace, err))
+	}
+
+	merged := mergeRestoreTarget(cfg, targetCfg, sourceNamespace)
+	if _, ok := portforward.ParseServiceEndpoint(cfg.Elasticsearch.SnapshotRepository.Endpoint, sourceNamespace); ok && opts.TargetContext != "" {
+		log.Warningf("Snapshot repository endpoint '%s' is a service of the source cluster and may not be reachable from the target cluster",
+			cfg.Elasticsearch.SnapshotRepository.Endpoint)
+	}
+
+	log.Infof("Restoring into namespace '%s' from repository '%s' of namespace '%s'", cliConfig.Namespace, cfg.Elasticsearch.SnapshotRepository.Name, sourceNamespace)
+	return &restoreTarget{
+		k8sClient: targetClient,
+		cliCtx:    &config.Context{Config: &cliConfig, RunID: cliCtx.RunID},
+		cfg:       merged,
+		remote:    true,
+	}, nil
+}
+
+// mergeRestoreTarget returns the target configuration set up to restore from the snapshot repository of the source.
+// The repository is registered in the target under its own name, so it does not replace the target's own repository,
+// and an endpoint that is a service of the source namespace is qualified with that namespace.
+func mergeRestoreTarget(source, target *config.Config, sourceNamespace string) *config.Config {
+	merged := *target
+
+	repo := source.Elasticsearch.SnapshotRepository
+	repo.Name = fmt.Sprintf("%s-from-%s", repo.Name, sourceNamespace)
+	if svc, ok := portforward.ParseServiceEndpoint(repo.Endpoint, sourceNamespace); ok {
+		repo.Endpoint = svc.Address()
+	}
+
+	merged.Elasticsearch.SnapshotRepository = repo
+	merged.Elasticsearch.Restore.Repository = repo.Name
+	merged.Elasticsearch.Restore.IndicesPattern = source.Elasticsearch.Restore.IndicesPattern
+	return &merged
+}
+
+// registerSourceRepository registers the snapshot repository of the source in the target as read-only,
+// so the target cannot modify or clean up the snapshots of the source
+func registerSourceRepository(esClient *elasticsearch.Client, cfg *config.Config, log *logger.Logger) error {
+	repo := cfg.Elasticsearch.SnapshotRepository
+	log.Infof("Registering read-only snapshot repository '%s'...", repo.Name)
+	if err := esClient.RegisterReadOnlyRepository(repo.Name, repo.Bucket, repo.Endpoint, repo.BasePath, repo.AccessKey, repo.SecretKey, repositoryConnection(repo), repositoryTuning(repo)); err != nil {
+		return fmt.Errorf("failed to register source snapshot repository: %w", explainRepositoryError(esClient, err))
+	}
+	log.Successf("Snapshot repository registered")
+	return nil
+}
@@ -0,0 +1,88 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClusterSettings struct {
+	settings *elasticsearch.ClusterSettings
+	getErr   error
+	putErr   error
+	puts     []map[string]interface{}
+}
+
+func (m *mockClusterSettings) GetClusterSettings() (*elasticsearch.ClusterSettings, error) {
+	return m.settings, m.getErr
+}
+
+func (m *mockClusterSettings) PutClusterSettings(persistent map[string]interface{}) error {
+	if m.putErr != nil {
+		return m.putErr
+	}
+	m.puts = append(m.puts, persistent)
+	return nil
+}
+
+func TestDisableRebalance(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("restores previous value", func(t *testing.T) {
+		client := &mockClusterSettings{settings: &elasticsearch.ClusterSettings{
+			Persistent: map[string]interface{}{settingRebalanceEnable: "primaries"},
+		}}
+
+		enable, err := disableRebalance(client, log)
+		require.NoError(t, err)
+		enable()
+
+		require.Len(t, client.puts, 2)
+		assert.Equal(t, map[string]interface{}{settingRebalanceEnable: "none"}, client.puts[0])
+		assert.Equal(t, map[string]interface{}{settingRebalanceEnable: "primaries"}, client.puts[1])
+	})
+
+	t.Run("resets unset setting", func(t *testing.T) {
+		client := &mockClusterSettings{settings: &elasticsearch.ClusterSettings{}}
+
+		enable, err := disableRebalance(client, log)
+		require.NoError(t, err)
+		enable()
+
+		require.Len(t, client.puts, 2)
+		value, ok := client.puts[1][settingRebalanceEnable]
+		assert.True(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("fails when settings cannot be read", func(t *testing.T) {
+		client := &mockClusterSettings{getErr: errors.New("unavailable")}
+
+		_, err := disableRebalance(client, log)
+
+		require.Error(t, err)
+		assert.Empty(t, client.puts)
+	})
+
+	t.Run("fails when setting cannot be updated", func(t *testing.T) {
+		client := &mockClusterSettings{settings: &elasticsearch.ClusterSettings{}, putErr: errors.New("forbidden")}
+
+		_, err := disableRebalance(client, log)
+
+		assert.ErrorContains(t, err, "failed to disable shard rebalancing")
+	})
+}
+
+func TestCheckShardAllocation(t *testing.T) {
+	// checkShardAllocation only logs, it must not fail the restore
+	log := logger.New(logger.LevelError, "")
+
+	checkShardAllocation(&mockClusterSettings{getErr: errors.New("unavailable")}, log)
+	checkShardAllocation(&mockClusterSettings{settings: &elasticsearch.ClusterSettings{
+		Transient: map[string]interface{}{settingAllocationEnable: "none"},
+	}}, log)
+}
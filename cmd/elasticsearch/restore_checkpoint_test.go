@@ -0,0 +1,161 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/checkpoint"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCheckpointStore struct {
+	checkpoints map[string]*checkpoint.Checkpoint
+	saves       int
+	saveErr     error
+}
+
+func (m *mockCheckpointStore) Load(runID string) (*checkpoint.Checkpoint, error) {
+	state, ok := m.checkpoints[runID]
+	if !ok {
+		return nil, checkpoint.ErrNotFound
+	}
+	return state, nil
+}
+
+func (m *mockCheckpointStore) Save(state *checkpoint.Checkpoint) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	if m.checkpoints == nil {
+		m.checkpoints = map[string]*checkpoint.Checkpoint{}
+	}
+	m.checkpoints[state.RunID] = state
+	m.saves++
+	return nil
+}
+
+func (m *mockCheckpointStore) Delete(runID string) error {
+	delete(m.checkpoints, runID)
+	return nil
+}
+
+func TestResumeRestoreCheckpoint(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	interrupted := func() *mockCheckpointStore {
+		return &mockCheckpointStore{checkpoints: map[string]*checkpoint.Checkpoint{
+			"run-1": {
+				RunID: "run-1", Operation: "restore", Snapshots: []string{"snap-1", "other-repo/snap-2"}, DropAllIndices: true,
+				Phases: []string{phaseDeleteIndices}, IndicesDeleted: []string{"sts_topology"}, SafetySnapshot: "sts-pre-restore-1",
+			},
+		}}
+	}
+
+	t.Run("the snapshots and --drop-all-indices are taken from the interrupted run", func(t *testing.T) {
+		opts := &restoreOptions{Resume: "run-1"}
+
+		resumed, err := resumeRestoreCheckpoint(interrupted(), opts, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "snap-1", opts.SnapshotName)
+		assert.Equal(t, []snapshotRef{{Repository: "other-repo", Name: "snap-2"}}, opts.AdditionalSnapshots)
+		assert.True(t, opts.DropAllIndices)
+		assert.True(t, resumed.completed(phaseDeleteIndices))
+		assert.False(t, resumed.completed(phaseRestore))
+
+		record := &restoreRecord{}
+		record.resumeFrom(resumed)
+		assert.Equal(t, []string{"sts_topology"}, record.deletedIndices)
+		assert.Equal(t, "sts-pre-restore-1", record.safetySnapshot)
+	})
+
+	t.Run("other snapshots are refused", func(t *testing.T) {
+		opts := &restoreOptions{Resume: "run-1", Snapshots: []string{"snap-3"}}
+		require.NoError(t, parseSnapshotNames(opts))
+
+		_, err := resumeRestoreCheckpoint(interrupted(), opts, log)
+
+		assert.ErrorContains(t, err, "run run-1 restored snapshot(s) snap-1, other-repo/snap-2, not snap-3")
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	})
+
+	t.Run("a run without checkpoint cannot be resumed", func(t *testing.T) {
+		_, err := resumeRestoreCheckpoint(interrupted(), &restoreOptions{Resume: "run-2"}, log)
+
+		assert.ErrorIs(t, err, checkpoint.ErrNotFound)
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	})
+}
+
+func TestRestoreCheckpoint(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("a new run completes no phases but records them", func(t *testing.T) {
+		store := &mockCheckpointStore{}
+		progress := newRestoreCheckpoint(store, "run-1", &restoreOptions{SnapshotName: "snap-1"}, log)
+
+		progress.complete(phaseRestore)
+
+		assert.False(t, progress.completed(phaseRestore), "only the phases of an interrupted run are skipped")
+		assert.Equal(t, []string{"snap-1"}, store.checkpoints["run-1"].Snapshots)
+		assert.Equal(t, []string{phaseRestore}, store.checkpoints["run-1"].Phases)
+	})
+
+	t.Run("the checkpoint is removed when the restore succeeds", func(t *testing.T) {
+		store := &mockCheckpointStore{}
+		progress := newRestoreCheckpoint(store, "run-1", &restoreOptions{SnapshotName: "snap-1"}, log)
+		progress.deletedIndices([]string{"sts_topology"}, "sts-pre-restore-1")
+
+		progress.finish(nil)
+
+		assert.Empty(t, store.checkpoints)
+	})
+
+	t.Run("the checkpoint is kept when the restore fails", func(t *testing.T) {
+		store := &mockCheckpointStore{}
+		progress := newRestoreCheckpoint(store, "run-1", &restoreOptions{SnapshotName: "snap-1"}, log)
+		progress.deletedIndices([]string{"sts_topology"}, "sts-pre-restore-1")
+
+		progress.finish(errors.New("connection reset"))
+
+		require.Contains(t, store.checkpoints, "run-1")
+		assert.Equal(t, "sts-pre-restore-1", store.checkpoints["run-1"].SafetySnapshot)
+	})
+
+	t.Run("failing to save does not fail the restore", func(t *testing.T) {
+		store := &mockCheckpointStore{saveErr: errors.New("forbidden")}
+		progress := newRestoreCheckpoint(store, "run-1", &restoreOptions{SnapshotName: "snap-1"}, log)
+
+		progress.complete(phaseDeleteIndices)
+		progress.finish(errors.New("connection reset"))
+
+		assert.False(t, progress.saved)
+	})
+
+	t.Run("a nil checkpoint records nothing", func(t *testing.T) {
+		var progress *restoreCheckpoint
+
+		progress.complete(phaseRestore)
+		progress.deletedIndices([]string{"sts_topology"}, "")
+		progress.finish(nil)
+
+		assert.False(t, progress.completed(phaseRestore))
+	})
+}
+
+func TestDeleteInterruptedRestore(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Elasticsearch.Restore = config.RestoreConfig{IndexPrefix: "sts", DatastreamIndexPrefix: ".ds-sts_k8s_logs"}
+	client := &mockESClientForRestore{indices: []string{"sts_topology", "sts_multi_metrics", ".kibana"}}
+	record := &restoreRecord{deletedIndices: []string{"sts_topology", "sts_multi_metrics"}}
+
+	err := deleteInterruptedRestore(client, cfg, &restoreOptions{DeleteConcurrency: 1}, record, prompt.New(true), logger.New(logger.LevelError, ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sts_topology", "sts_multi_metrics"}, client.deletedIndices)
+	assert.Equal(t, []string{"sts_topology", "sts_multi_metrics"}, record.deletedIndices, "the indices of the interrupted restore are not recorded as deleted")
+}
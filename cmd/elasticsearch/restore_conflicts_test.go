@@ -0,0 +1,117 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConflictChecker struct {
+	snapshots map[string][]string
+	indices   []string
+}
+
+func (m *mockConflictChecker) GetSnapshot(repository, snapshotName string) (*elasticsearch.Snapshot, error) {
+	return &elasticsearch.Snapshot{Snapshot: snapshotName, Repository: repository, Indices: m.snapshots[snapshotName]}, nil
+}
+
+func (m *mockConflictChecker) ListIndices(_ string) ([]string, error) {
+	return m.indices, nil
+}
+
+func TestRestoreConflicts(t *testing.T) {
+	conflicts := restoreConflicts(
+		[]string{"sts_topology", "sts_metrics", "sts_events", ".kibana_1", "sts_topology"},
+		[]string{"sts_topology", "sts_events", ".kibana_1"},
+		"sts*,-sts_events")
+
+	assert.Equal(t, []string{"sts_topology"}, conflicts)
+}
+
+func TestResolveRestoreConflicts(t *testing.T) {
+	refs := []snapshotRef{{Repository: "sts-backup", Name: "sts-1"}}
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("no conflicts", func(t *testing.T) {
+		client := &mockConflictChecker{snapshots: map[string][]string{"sts-1": {"sts_metrics"}}, indices: []string{"sts_topology"}}
+		opts := &restoreOptions{}
+
+		require.NoError(t, resolveRestoreConflicts(client, refs, "sts*", opts, prompt.NewWithIO(nil, &bytes.Buffer{}, false, false), false, log))
+		assert.Empty(t, opts.ExcludeIndices)
+	})
+
+	t.Run("abort without strategy", func(t *testing.T) {
+		client := &mockConflictChecker{snapshots: map[string][]string{"sts-1": {"sts_topology"}}, indices: []string{"sts_topology"}}
+
+		err := resolveRestoreConflicts(client, refs, "sts*", &restoreOptions{}, prompt.NewWithIO(nil, &bytes.Buffer{}, false, false), false, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+		assert.Contains(t, err.Error(), "sts_topology")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		client := &mockConflictChecker{snapshots: map[string][]string{"sts-1": {"sts_topology", "sts_metrics"}}, indices: []string{"sts_topology"}}
+		opts := &restoreOptions{OnConflict: conflictSkip, ExcludeIndices: []string{"sts_k8s_logs*"}}
+
+		require.NoError(t, resolveRestoreConflicts(client, refs, "sts*", opts, nil, false, log))
+		assert.Equal(t, []string{"sts_k8s_logs*", "sts_topology"}, opts.ExcludeIndices)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		client := &mockConflictChecker{snapshots: map[string][]string{"sts-1": {"sts_topology", "sts_metrics"}}, indices: []string{"sts_topology"}}
+		opts := &restoreOptions{OnConflict: conflictRename, RenameSuffix: defaultRenameSuffix}
+
+		require.NoError(t, resolveRestoreConflicts(client, refs, "sts*", opts, nil, false, log))
+		assert.Equal(t, "^(sts_topology)$", opts.RenamePattern)
+		assert.Equal(t, "$1-restored", opts.RenameReplacement)
+	})
+
+	t.Run("rename onto existing index", func(t *testing.T) {
+		client := &mockConflictChecker{snapshots: map[string][]string{"sts-1": {"sts_events"}}, indices: []string{"sts_events", "sts_events-restored"}}
+		opts := &restoreOptions{OnConflict: conflictRename, RenameSuffix: defaultRenameSuffix}
+
+		err := resolveRestoreConflicts(client, refs, "sts*", opts, nil, false, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+		assert.Contains(t, err.Error(), "sts_events-restored")
+	})
+
+	t.Run("asks for the strategy", func(t *testing.T) {
+		client := &mockConflictChecker{snapshots: map[string][]string{"sts-1": {"sts_topology"}}, indices: []string{"sts_topology"}}
+		opts := &restoreOptions{}
+		prompter := prompt.NewWithIO(strings.NewReader("maybe\nskip\n"), &bytes.Buffer{}, false, true)
+
+		require.NoError(t, resolveRestoreConflicts(client, refs, "sts*", opts, prompter, true, log))
+		assert.Equal(t, []string{"sts_topology"}, opts.ExcludeIndices)
+	})
+}
+
+func TestRenamePattern(t *testing.T) {
+	pattern, replacement := renamePattern([]string{"sts_topology", ".ds-sts_k8s_logs-2025.01.01-000001"}, "-restored")
+
+	re := regexp.MustCompile(pattern)
+	// Go and Java use the same syntax for the replacement group
+	assert.Equal(t, "sts_topology-restored", re.ReplaceAllString("sts_topology", replacement))
+	assert.Equal(t, ".ds-sts_k8s_logs-2025.01.01-000001-restored", re.ReplaceAllString(".ds-sts_k8s_logs-2025.01.01-000001", replacement))
+	assert.Equal(t, "sts_topology_2", re.ReplaceAllString("sts_topology_2", replacement), "other indices keep their name")
+	assert.Equal(t, ".ds-sts_k8s_logs-2025x01.01-000001", re.ReplaceAllString(".ds-sts_k8s_logs-2025x01.01-000001", replacement))
+}
+
+func TestValidateConflictStrategy(t *testing.T) {
+	for _, strategy := range []string{"", conflictAbort, conflictSkip, conflictRename} {
+		assert.NoError(t, validateConflictStrategy(strategy))
+	}
+	err := validateConflictStrategy("overwrite")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
@@ -0,0 +1,74 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRestoreHistoryFilter(t *testing.T) {
+	now := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+
+	filter, err := newRestoreHistoryFilter(&restoreHistoryOptions{Outcome: "failed", Since: "7d"}, now)
+	require.NoError(t, err)
+	assert.Equal(t, audit.OutcomeFailed, filter.Outcome)
+	assert.Equal(t, now.Add(-7*24*time.Hour), filter.Since)
+
+	_, err = newRestoreHistoryFilter(&restoreHistoryOptions{Outcome: "partial"}, now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--outcome must be one of")
+
+	_, err = newRestoreHistoryFilter(&restoreHistoryOptions{Until: "yesterday"}, now)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --until")
+}
+
+func TestFilterRestores(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 12, 0, 0, 0, time.UTC) }
+	entries := []audit.Entry{
+		{RunID: "1", Timestamp: day(1), Operation: "restore", Snapshot: "snap-a", Outcome: audit.OutcomeSuccess},
+		{RunID: "2", Timestamp: day(2), Operation: "configure", Outcome: audit.OutcomeSuccess},
+		{RunID: "3", Timestamp: day(3), Operation: "restore", Snapshot: "snap-b", Outcome: audit.OutcomeFailed},
+		{RunID: "4", Timestamp: day(4), Operation: "rollback-restore", Snapshot: "pre-restore-1", Outcome: audit.OutcomeSuccess},
+	}
+	runIDs := func(entries []audit.Entry) []string {
+		ids := []string{}
+		for _, entry := range entries {
+			ids = append(ids, entry.RunID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name     string
+		filter   restoreHistoryFilter
+		expected []string
+	}{
+		{name: "all restores, most recent first", expected: []string{"4", "3", "1"}},
+		{name: "snapshot", filter: restoreHistoryFilter{Snapshot: "snap-a"}, expected: []string{"1"}},
+		{name: "outcome", filter: restoreHistoryFilter{Outcome: audit.OutcomeFailed}, expected: []string{"3"}},
+		{name: "time range", filter: restoreHistoryFilter{Since: day(2), Until: day(4)}, expected: []string{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, runIDs(filterRestores(entries, &tt.filter)))
+		})
+	}
+}
+
+func TestRestoreHistoryTable(t *testing.T) {
+	table := restoreHistoryTable([]audit.Entry{
+		{
+			RunID: "run-1", Timestamp: time.Now(), User: "admin", Operation: "restore", Snapshot: "snap-a", Repository: "sts-backup",
+			IndicesPattern: "sts*,-sts_k8s_logs*", IndicesDeleted: []string{"sts_a"}, DurationMillis: 95400, Outcome: audit.OutcomeSuccess,
+		},
+		{RunID: "run-0", Timestamp: time.Now(), Operation: "restore", Snapshot: "snap-old", Outcome: audit.OutcomeFailed},
+	})
+
+	assert.Equal(t, []string{"run-1", "admin", "restore", "snap-a", "sts-backup", "sts*,-sts_k8s_logs*", "1", "1m35s", "SUCCESS"}, table.Rows[0][1:])
+	assert.Equal(t, []string{"run-0", "", "restore", "snap-old", "", "", "0", "", "FAILED"}, table.Rows[1][1:])
+}
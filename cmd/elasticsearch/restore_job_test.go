@@ -0,0 +1,71 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreJobArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		assumeYes    bool
+		image        string
+		flags        []string
+		expectedArgs []string
+		expectedErr  string
+	}{
+		{
+			name:      "flags of the restore are passed on",
+			assumeYes: true,
+			image:     "sts-backup:1.0.0",
+			flags:     []string{"--as-job", "--job-image", "sts-backup:1.0.0", "-s", "sts-backup-1", "-r", "--domain", "topology", "--domain", "metrics"},
+			expectedArgs: []string{
+				"elasticsearch", "restore-snapshot",
+				"--domain=topology", "--domain=metrics", "--drop-all-indices=true", "--snapshot-name=sts-backup-1",
+			},
+		},
+		{
+			name:        "image is required",
+			assumeYes:   true,
+			flags:       []string{"--as-job", "-s", "sts-backup-1"},
+			expectedErr: "--as-job requires --job-image",
+		},
+		{
+			name:        "confirmation is required",
+			image:       "sts-backup:1.0.0",
+			flags:       []string{"--as-job", "--job-image", "sts-backup:1.0.0", "-s", "sts-backup-1"},
+			expectedErr: "--as-job requires --yes",
+		},
+		{
+			name:        "flags that cannot work in a job are refused",
+			assumeYes:   true,
+			image:       "sts-backup:1.0.0",
+			flags:       []string{"--as-job", "--job-image", "sts-backup:1.0.0", "-i", "--report", "restore.md"},
+			expectedErr: "--interactive, --report cannot be used with --as-job",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cliCtx := config.NewContext()
+			cliCtx.Config.AssumeYes = tt.assumeYes
+			cmd := restoreCmd(cliCtx)
+			require.NoError(t, cmd.ParseFlags(tt.flags))
+
+			args, err := restoreJobArgs(cmd, cliCtx, &restoreJobOptions{AsJob: true, Image: tt.image})
+
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIndexMaintainer struct {
+	refreshed      string
+	merged         string
+	maxNumSegments int
+	refreshErr     error
+	mergeErr       error
+}
+
+func (m *mockIndexMaintainer) RefreshIndices(pattern string) error {
+	m.refreshed = pattern
+	return m.refreshErr
+}
+
+func (m *mockIndexMaintainer) ForceMergeIndices(pattern string, maxNumSegments int) error {
+	m.merged = pattern
+	m.maxNumSegments = maxNumSegments
+	return m.mergeErr
+}
+
+func TestFinishRestoredIndices(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("refresh only", func(t *testing.T) {
+		client := &mockIndexMaintainer{}
+
+		require.NoError(t, finishRestoredIndices(client, "sts*,-sts_logs*", 0, log))
+		assert.Equal(t, "sts*,-sts_logs*", client.refreshed)
+		assert.Empty(t, client.merged)
+	})
+
+	t.Run("refresh and force merge", func(t *testing.T) {
+		client := &mockIndexMaintainer{}
+
+		require.NoError(t, finishRestoredIndices(client, "sts*", 1, log))
+		assert.Equal(t, "sts*", client.refreshed)
+		assert.Equal(t, "sts*", client.merged)
+		assert.Equal(t, 1, client.maxNumSegments)
+	})
+
+	t.Run("failed refresh only warns", func(t *testing.T) {
+		client := &mockIndexMaintainer{refreshErr: errors.New("timeout")}
+
+		require.NoError(t, finishRestoredIndices(client, "sts*", 1, log))
+		assert.Equal(t, "sts*", client.merged)
+	})
+
+	t.Run("failed force merge", func(t *testing.T) {
+		client := &mockIndexMaintainer{mergeErr: errors.New("timeout")}
+
+		err := finishRestoredIndices(client, "sts*", 1, log)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "force merge failed")
+	})
+}
@@ -0,0 +1,159 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/events"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSnapshotRestorer struct {
+	mu         sync.Mutex
+	restored   map[string]elasticsearch.RestoreOptions
+	errs       map[string]error
+	recoveries []elasticsearch.RecoveryInfo
+	// delay is the time a restore takes
+	delay time.Duration
+}
+
+func (m *mockSnapshotRestorer) RestoreSnapshotWithOptions(repository, snapshotName string, opts elasticsearch.RestoreOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ref := snapshotRef{Repository: repository, Name: snapshotName}.String()
+	if m.restored == nil {
+		m.restored = map[string]elasticsearch.RestoreOptions{}
+	}
+	m.restored[ref] = opts
+	err := m.errs[ref]
+	m.mu.Unlock()
+	time.Sleep(m.delay)
+	m.mu.Lock()
+	return err
+}
+
+func (m *mockSnapshotRestorer) ListRecoveries(_ bool) ([]elasticsearch.RecoveryInfo, error) {
+	return m.recoveries, nil
+}
+
+func TestParseSnapshotRef(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    snapshotRef
+		wantErr bool
+	}{
+		{value: "sts-backup-1", want: snapshotRef{Name: "sts-backup-1"}},
+		{value: "logs-backup/logs-1", want: snapshotRef{Repository: "logs-backup", Name: "logs-1"}},
+		{value: "/logs-1", wantErr: true},
+		{value: "logs-backup/", wantErr: true},
+		{value: "a/b/c", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ref, err := parseSnapshotRef(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ref)
+		})
+	}
+}
+
+func TestParseSnapshotNames(t *testing.T) {
+	opts := &restoreOptions{Snapshots: []string{"sts-backup-1", "logs-backup/logs-1", "events-1"}}
+
+	require.NoError(t, parseSnapshotNames(opts))
+
+	assert.Equal(t, "sts-backup-1", opts.SnapshotName)
+	assert.Empty(t, opts.SnapshotRepository)
+	assert.Equal(t, []snapshotRef{{Repository: "logs-backup", Name: "logs-1"}, {Name: "events-1"}}, opts.AdditionalSnapshots)
+	assert.Equal(t, []string{"logs-backup/logs-1", "sts-backup/events-1"}, additionalSnapshotNames(opts, "sts-backup"))
+}
+
+func TestParseSnapshotNames_Duplicate(t *testing.T) {
+	opts := &restoreOptions{Snapshots: []string{"logs-backup/logs-1", "logs-backup/logs-1"}}
+
+	err := parseSnapshotNames(opts)
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "more than once")
+}
+
+func TestRestoreSnapshots_Concurrent(t *testing.T) {
+	client := &mockSnapshotRestorer{}
+	refs := []snapshotRef{{Repository: "sts-backup", Name: "sts-1"}, {Repository: "logs-backup", Name: "logs-1"}}
+	opts := elasticsearch.RestoreOptions{Indices: "sts*", FeatureStates: []string{"security"}, WaitForCompletion: true}
+
+	err := restoreSnapshots(client, refs, opts, time.Hour, nil, logger.New(logger.LevelError, ""))
+
+	require.NoError(t, err)
+	require.Len(t, client.restored, 2)
+	assert.Equal(t, []string{"security"}, client.restored["sts-backup/sts-1"].FeatureStates)
+	assert.Nil(t, client.restored["logs-backup/logs-1"].FeatureStates, "feature states come from the first snapshot only")
+	assert.Equal(t, "sts*", client.restored["logs-backup/logs-1"].Indices)
+}
+
+func TestRestoreSnapshots_Errors(t *testing.T) {
+	client := &mockSnapshotRestorer{errs: map[string]error{
+		"logs-backup/logs-1": &elasticsearch.PartialRestoreError{Snapshot: "logs-1", Total: 4, Failed: 1},
+	}}
+	refs := []snapshotRef{{Repository: "sts-backup", Name: "sts-1"}, {Repository: "logs-backup", Name: "logs-1"}}
+
+	err := restoreSnapshots(client, refs, elasticsearch.RestoreOptions{}, time.Hour, nil, logger.New(logger.LevelError, ""))
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.PartialRestore, exitcode.Of(err))
+	assert.Len(t, client.restored, 2, "the other restore still runs")
+}
+
+func TestRestoreSnapshots_Single(t *testing.T) {
+	client := &mockSnapshotRestorer{errs: map[string]error{"sts-backup/sts-1": errors.New("boom")}}
+
+	err := restoreSnapshots(client, []snapshotRef{{Repository: "sts-backup", Name: "sts-1"}},
+		elasticsearch.RestoreOptions{FeatureStates: []string{"security"}}, time.Hour, nil, logger.New(logger.LevelError, ""))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to restore snapshot")
+	assert.Equal(t, []string{"security"}, client.restored["sts-backup/sts-1"].FeatureStates)
+}
+
+func TestRestoreSnapshots_Events(t *testing.T) {
+	client := &mockSnapshotRestorer{
+		delay:      100 * time.Millisecond,
+		recoveries: []elasticsearch.RecoveryInfo{{Type: snapshotRecoveryType, Repository: "sts-backup", Snapshot: "sts-1", Stage: "index"}},
+	}
+	var out bytes.Buffer
+	emitter, err := events.New(events.FormatNDJSON, &out, "")
+	require.NoError(t, err)
+
+	err = restoreSnapshots(client, []snapshotRef{{Repository: "sts-backup", Name: "sts-1"}},
+		elasticsearch.RestoreOptions{}, 10*time.Millisecond, emitter, logger.New(logger.LevelError, ""))
+
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `"type":"restore_progress","snapshot":"sts-backup/sts-1","percent":0,"shardsTotal":1`,
+		"progress is emitted for a single snapshot")
+}
+
+func TestRestoreProgress(t *testing.T) {
+	recoveries := []elasticsearch.RecoveryInfo{
+		{Type: snapshotRecoveryType, Repository: "sts-backup", Snapshot: "sts-1", Stage: "done"},
+		{Type: snapshotRecoveryType, Repository: "sts-backup", Snapshot: "sts-1", Stage: "index"},
+		{Type: snapshotRecoveryType, Repository: "logs-backup", Snapshot: "logs-1", Stage: "done"},
+		{Type: "peer", Repository: "logs-backup", Snapshot: "logs-1", Stage: "index"},
+	}
+	refs := []snapshotRef{{Repository: "sts-backup", Name: "sts-1"}, {Repository: "logs-backup", Name: "logs-1"}}
+
+	assert.Equal(t, "sts-backup/sts-1 1/2 shard(s), logs-backup/logs-1 1/1 shard(s)", restoreProgress(recoveries, refs))
+}
@@ -0,0 +1,75 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintRestorePattern(t *testing.T) {
+	snapshot := &elasticsearch.Snapshot{
+		Snapshot: "sts-backup-20250115-0300",
+		Indices:  []string{"sts_topology", "sts_multi_metrics-000001", ".ds-sts_k8s_logs-2025.01.15-000001", ".kibana_1"},
+	}
+	newLog := func() *logger.Logger {
+		log := logger.New(logger.LevelError, "")
+		log.CollectWarnings()
+		return log
+	}
+
+	t.Run("a pattern matching the snapshot is not warned about", func(t *testing.T) {
+		log := newLog()
+
+		err := lintRestorePattern([]*elasticsearch.Snapshot{snapshot}, "sts*,.ds-sts_k8s_logs*", false, log)
+
+		require.NoError(t, err)
+		assert.Empty(t, log.Warnings())
+	})
+
+	t.Run("a pattern matching nothing is refused", func(t *testing.T) {
+		err := lintRestorePattern([]*elasticsearch.Snapshot{snapshot}, "stackstate*", false, newLog())
+
+		assert.ErrorContains(t, err, "restore pattern 'stackstate*' matches none of the 4 index(es) of snapshot(s) sts-backup-20250115-0300")
+		assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+	})
+
+	t.Run("a part matching nothing is warned about", func(t *testing.T) {
+		log := newLog()
+
+		err := lintRestorePattern([]*elasticsearch.Snapshot{snapshot}, "sts*,.ds-stackstate_logs*", false, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Restore pattern '.ds-stackstate_logs*' matches no index of snapshot(s) sts-backup-20250115-0300"}, log.Warnings())
+	})
+
+	t.Run("a pattern leaving out most indices is warned about", func(t *testing.T) {
+		log := newLog()
+
+		err := lintRestorePattern([]*elasticsearch.Snapshot{snapshot}, "sts_topology", false, log)
+
+		require.NoError(t, err)
+		require.Len(t, log.Warnings(), 1)
+		assert.Contains(t, log.Warnings()[0], "matches only 1 of the 3 index(es) of the snapshot; not restored are e.g. .ds-sts_k8s_logs-2025.01.15-000001, sts_multi_metrics-000001")
+	})
+
+	t.Run("a restore narrowed on purpose is not warned about", func(t *testing.T) {
+		log := newLog()
+
+		err := lintRestorePattern([]*elasticsearch.Snapshot{snapshot}, "sts_topology", true, log)
+
+		require.NoError(t, err)
+		assert.Empty(t, log.Warnings())
+	})
+
+	t.Run("the indices of all snapshots are considered", func(t *testing.T) {
+		logs := &elasticsearch.Snapshot{Snapshot: "logs-20250115", Indices: []string{"logs-000001"}}
+
+		err := lintRestorePattern([]*elasticsearch.Snapshot{snapshot, logs}, "logs-*", true, newLog())
+
+		assert.NoError(t, err)
+	})
+}
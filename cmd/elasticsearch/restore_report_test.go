@@ -0,0 +1,194 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRestoreInspector returns a fixed snapshot size and index list
+type mockRestoreInspector struct {
+	size       int64
+	sizeErr    error
+	indices    []string
+	indicesErr error
+	pattern    string
+}
+
+func (m *mockRestoreInspector) SnapshotSize(_, _ string) (int64, error) {
+	return m.size, m.sizeErr
+}
+
+func (m *mockRestoreInspector) ListIndices(pattern string) ([]string, error) {
+	m.pattern = pattern
+	return m.indices, m.indicesErr
+}
+
+func TestMatchesIndexPattern(t *testing.T) {
+	tests := []struct {
+		index   string
+		pattern string
+		want    bool
+	}{
+		{index: "sts_topology", pattern: "sts*", want: true},
+		{index: ".kibana_1", pattern: "sts*", want: false},
+		{index: "sts_k8s_logs-000001", pattern: "sts*,-sts_k8s_logs*", want: false},
+		{index: "sts_topology", pattern: "sts*,-sts_k8s_logs*", want: true},
+		{index: "sts_metrics", pattern: "sts_topology,sts_metrics", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.index+" "+tt.pattern, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesIndexPattern(tt.index, tt.pattern))
+		})
+	}
+}
+
+func TestInspectRestore(t *testing.T) {
+	snapshot := &elasticsearch.Snapshot{Snapshot: "sts-backup-1", State: "SUCCESS", Indices: []string{"sts_topology", "sts_metrics", ".kibana_1"}}
+	snapshot.Shards.Total, snapshot.Shards.Successful = 2, 2
+
+	t.Run("all indices restored", func(t *testing.T) {
+		client := &mockRestoreInspector{size: 2048, indices: []string{"sts_topology", "sts_metrics"}}
+		record := &restoreRecord{snapshot: snapshot}
+
+		inspectRestore(client, "sts-backup", "sts*", record, logger.New(logger.LevelError, ""))
+
+		assert.Equal(t, "sts*", client.pattern)
+		assert.Equal(t, int64(2048), record.snapshotSize)
+		assert.Equal(t, []string{"sts_metrics", "sts_topology"}, record.restoredIndices)
+		assert.Equal(t, []report.Check{
+			{Name: "Snapshot state", Status: report.StatusPass, Details: "SUCCESS, 2/2 shard(s) successful"},
+			{Name: "Restored indices", Status: report.StatusPass, Details: "2 of 2 snapshot index(es) present"},
+		}, record.checks)
+	})
+
+	t.Run("missing index", func(t *testing.T) {
+		client := &mockRestoreInspector{sizeErr: errors.New("status unavailable"), indices: []string{"sts_topology"}}
+		record := &restoreRecord{snapshot: snapshot}
+		log := logger.New(logger.LevelError, "")
+		log.CollectWarnings()
+
+		inspectRestore(client, "sts-backup", "sts*", record, log)
+
+		assert.Equal(t, int64(-1), record.snapshotSize)
+		assert.Equal(t, report.StatusFail, record.checks[1].Status)
+		assert.Equal(t, "1 of 2 snapshot index(es) present; missing: sts_metrics", record.checks[1].Details)
+		assert.Len(t, log.Warnings(), 1)
+	})
+
+	t.Run("listing fails", func(t *testing.T) {
+		client := &mockRestoreInspector{indicesErr: errors.New("connection refused")}
+		record := &restoreRecord{snapshot: snapshot}
+
+		inspectRestore(client, "sts-backup", "sts*", record, logger.New(logger.LevelError, ""))
+
+		assert.Equal(t, report.Check{Name: "Restored indices", Status: report.StatusWarn, Details: "could not list indices"}, record.checks[1])
+	})
+}
+
+func TestRestoreRecord_Report(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Elasticsearch.Restore.Repository = "sts-backup"
+	cfg.Elasticsearch.Restore.IndicesPattern = "sts*"
+	cliCtx := &config.Context{Config: &config.CLIConfig{Namespace: "suse-observability"}, RunID: "run-1"}
+	opts := &restoreOptions{SnapshotName: "sts-backup-1", DropAllIndices: true}
+	record := &restoreRecord{
+		deletedIndices:  []string{"sts_topology"},
+		safetySnapshot:  "pre-restore-1",
+		snapshot:        &elasticsearch.Snapshot{Indices: []string{"sts_topology"}},
+		snapshotSize:    1536,
+		restoredIndices: []string{"sts_topology"},
+	}
+	record.timeStep("Restore snapshot", time.Now())
+
+	rep := record.report(cliCtx, cfg, opts, time.Now().Add(-time.Minute), errors.New("scale up failed"), []string{"careful"})
+
+	assert.Equal(t, "Restore of snapshot sts-backup-1", rep.Title)
+	assert.Equal(t, "run-1", rep.RunID)
+	assert.Equal(t, "suse-observability", rep.Namespace)
+	assert.Equal(t, "failed", rep.Outcome())
+	assert.Equal(t, "scale up failed", rep.Error)
+	assert.Contains(t, rep.Details, report.Detail{Name: "Snapshot size", Value: "1.5 KiB"})
+	assert.Contains(t, rep.Details, report.Detail{Name: "Indices deleted", Value: "1"})
+	assert.Contains(t, rep.Details, report.Detail{Name: "Safety snapshot", Value: "pre-restore-1"})
+	assert.Equal(t, "Restore snapshot", rep.Steps[0].Name)
+	assert.Equal(t, []string{"sts_topology"}, rep.Indices)
+	assert.Equal(t, []string{"careful"}, rep.Warnings)
+}
+
+func TestRestoreChecks(t *testing.T) {
+	record := &restoreRecord{checks: []report.Check{{Name: "Snapshot state", Status: report.StatusWarn, Details: "PARTIAL"}}}
+
+	assert.Equal(t, []output.Check{
+		{Name: "Restore", Status: "PASS", Details: "completed"},
+		{Name: "Snapshot state", Status: "WARN", Details: "PARTIAL"},
+	}, restoreChecks(record, nil))
+
+	checks := restoreChecks(&restoreRecord{}, errors.New("failed to restore snapshot"))
+	assert.Equal(t, []output.Check{{Name: "Restore", Status: "FAIL", Details: "failed to restore snapshot"}}, checks)
+}
+
+func TestRestoreRecord_TimeStepProgress(t *testing.T) {
+	var steps []string
+	record := &restoreRecord{progress: func(step string, duration time.Duration) {
+		assert.GreaterOrEqual(t, duration, time.Duration(0))
+		steps = append(steps, step)
+	}}
+
+	record.timeStep("Scale down deployments", time.Now())
+	record.timeStep("Restore snapshot", time.Now())
+
+	assert.Equal(t, []string{"Scale down deployments", "Restore snapshot"}, steps)
+	assert.Len(t, record.steps, 2)
+}
+
+func TestPrintShardFailures(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "failures.json")
+	cliCtx := config.NewContext()
+	cliCtx.Config.OutputFormat = "json"
+	cliCtx.Config.OutputFile = outputFile
+	record := &restoreRecord{snapshot: &elasticsearch.Snapshot{Failures: []elasticsearch.SnapshotShardFailure{
+		{Index: "sts_metrics", ShardID: 0, NodeID: "es-0", Reason: "node left"},
+	}}}
+	err := exitcode.Wrap(exitcode.PartialRestore, fmt.Errorf("restore incomplete: %w", &elasticsearch.PartialRestoreError{
+		Snapshot: "snap-1", Total: 4, Failed: 2,
+		Failures: []elasticsearch.ShardFailure{{Index: "sts_topology", Shard: 1, Reason: "NEW_INDEX_RESTORED", Source: elasticsearch.ShardFailureRestore}},
+	}))
+
+	printShardFailures(cliCtx, record, err, logger.New(logger.LevelError, ""))
+
+	data, readErr := os.ReadFile(outputFile)
+	require.NoError(t, readErr)
+	var envelope struct {
+		Items []map[string]string `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(data, &envelope))
+	rows := envelope.Items
+	require.Len(t, rows, 2)
+	assert.Equal(t, map[string]string{"INDEX": "sts_metrics", "SHARD": "0", "NODE": "es-0", "SOURCE": "snapshot", "REASON": "node left"}, rows[0])
+	assert.Equal(t, "sts_topology", rows[1]["INDEX"])
+	assert.Equal(t, "restore", rows[1]["SOURCE"])
+}
+
+func TestPrintShardFailures_None(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "failures.json")
+	cliCtx := config.NewContext()
+	cliCtx.Config.OutputFile = outputFile
+
+	printShardFailures(cliCtx, &restoreRecord{}, errors.New("boom"), logger.New(logger.LevelError, ""))
+
+	assert.NoFileExists(t, outputFile)
+}
@@ -0,0 +1,75 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSnapshotCreator struct {
+	repository string
+	name       string
+	indices    []string
+	metadata   map[string]string
+	state      string
+	err        error
+}
+
+func (m *mockSnapshotCreator) CreateSnapshot(repository, snapshotName string, indices []string, metadata map[string]string) (*elasticsearch.Snapshot, error) {
+	m.repository, m.name, m.indices, m.metadata = repository, snapshotName, indices, metadata
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &elasticsearch.Snapshot{Snapshot: snapshotName, State: m.state}, nil
+}
+
+func TestSafetySnapshotName(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600))
+
+	name := safetySnapshotName(now)
+
+	assert.Equal(t, "pre-restore-20250304-040607", name)
+	assert.True(t, isSafetySnapshot(name))
+	assert.False(t, isSafetySnapshot("sts-backup-20250304-0300"))
+}
+
+func TestTakeSafetySnapshot(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	indices := []string{"sts_topology", ".ds-sts_k8s_logs-000001"}
+
+	t.Run("complete snapshot", func(t *testing.T) {
+		client := &mockSnapshotCreator{state: "SUCCESS"}
+
+		name, err := takeSafetySnapshot(client, "sts-backup", indices, now, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "pre-restore-20250304-050607", name)
+		assert.Equal(t, "sts-backup", client.repository)
+		assert.Equal(t, indices, client.indices)
+		assert.Equal(t, "sts-backup", client.metadata[metadataTakenBy])
+	})
+
+	t.Run("partial snapshot is refused", func(t *testing.T) {
+		client := &mockSnapshotCreator{state: "PARTIAL"}
+
+		_, err := takeSafetySnapshot(client, "sts-backup", indices, now, log)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--skip-safety-snapshot")
+	})
+
+	t.Run("snapshot fails", func(t *testing.T) {
+		client := &mockSnapshotCreator{err: errors.New("repository is read-only")}
+
+		_, err := takeSafetySnapshot(client, "sts-backup", indices, now, log)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "repository is read-only")
+	})
+}
@@ -1,14 +1,25 @@
 package elasticsearch
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
 	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/events"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 // mockESClientForRestore is a mock for testing restore command
@@ -84,11 +95,11 @@ func (m *mockESClientForRestore) ListIndicesDetailed() ([]elasticsearch.IndexInf
 	return nil, fmt.Errorf("not implemented")
 }
 
-func (m *mockESClientForRestore) ConfigureSnapshotRepository(_, _, _, _, _, _ string) error {
+func (m *mockESClientForRestore) ConfigureSnapshotRepository(_, _, _, _, _, _ string, _ elasticsearch.S3Connection, _ elasticsearch.RepositoryTuning) error {
 	return fmt.Errorf("not implemented")
 }
 
-func (m *mockESClientForRestore) ConfigureSLMPolicy(_, _, _, _, _, _ string, _, _ int) error {
+func (m *mockESClientForRestore) ConfigureSLMPolicy(_, _, _, _, _, _ string, _, _ int, _ []string) error {
 	return fmt.Errorf("not implemented")
 }
 
@@ -112,8 +123,38 @@ func TestRestoreCmd_Unit(t *testing.T) {
 	require.NotNil(t, dropFlag)
 	assert.Equal(t, "r", dropFlag.Shorthand)
 
-	yesFlag := cmd.Flags().Lookup("yes")
-	require.NotNil(t, yesFlag)
+	interactiveFlag := cmd.Flags().Lookup("interactive")
+	require.NotNil(t, interactiveFlag)
+	assert.Equal(t, "i", interactiveFlag.Shorthand)
+
+	concurrencyFlag := cmd.Flags().Lookup("delete-concurrency")
+	require.NotNil(t, concurrencyFlag)
+	assert.Equal(t, "4", concurrencyFlag.DefValue)
+
+	assert.NotNil(t, cmd.Flags().Lookup("disable-rebalance"))
+	assert.NotNil(t, cmd.Flags().Lookup("import-pipelines"))
+	assert.NotNil(t, cmd.Flags().Lookup("feature-states"))
+	assert.NotNil(t, cmd.Flags().Lookup("allow-partial"))
+	assert.NotNil(t, cmd.Flags().Lookup("exclude-indices"))
+	assert.NotNil(t, cmd.Flags().Lookup("ignore-unavailable"))
+	assert.NotNil(t, cmd.Flags().Lookup("force-merge-segments"))
+	assert.NotNil(t, cmd.Flags().Lookup("skip-safety-snapshot"))
+	assert.NotNil(t, cmd.Flags().Lookup("target-namespace"))
+	assert.NotNil(t, cmd.Flags().Lookup("target-context"))
+	assert.Equal(t, "30s", cmd.Flags().Lookup("keepalive-interval").DefValue)
+
+	// --yes is a global flag on the root command
+	assert.Nil(t, cmd.Flags().Lookup("yes"))
+}
+
+func TestRestoreConnectionLostError(t *testing.T) {
+	cause := fmt.Errorf("failed to restore snapshot: %w", elasticsearch.ErrConnectionLost)
+
+	err := restoreConnectionLostError("sts-backup-20250101", cause)
+
+	assert.ErrorIs(t, err, elasticsearch.ErrConnectionLost)
+	assert.Contains(t, err.Error(), "while waiting for the restore of snapshot 'sts-backup-20250101'")
+	assert.Contains(t, err.Error(), "recovery-status")
 }
 
 // TestFilterSTSIndices tests the index filtering logic
@@ -188,6 +229,17 @@ func TestFilterSTSIndices(t *testing.T) {
 	}
 }
 
+func TestIndicesToDrop(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Elasticsearch.Restore = config.RestoreConfig{IndexPrefix: "sts", DatastreamIndexPrefix: ".ds-sts_k8s_logs",
+		IndicesPattern: "sts_topology*,sts_generic_events*", Domains: config.DefaultRestoreDomains}
+	allIndices := []string{"sts_topology_events", "sts_generic_events", "sts_multi_metrics", ".ds-sts_k8s_logs-2026.03.01-000001", ".kibana"}
+
+	assert.Equal(t, allIndices[:4], indicesToDrop(allIndices, cfg, &restoreOptions{}))
+	assert.Equal(t, []string{"sts_topology_events", "sts_generic_events"}, indicesToDrop(allIndices, cfg, &restoreOptions{Domains: []string{"topology", "events"}}),
+		"the log datastream and metrics are left alone")
+}
+
 // TestHasDatastreamIndices tests datastream detection
 func TestHasDatastreamIndices(t *testing.T) {
 	tests := []struct {
@@ -399,6 +451,89 @@ func TestDeleteIndexWithVerification(t *testing.T) {
 	}
 }
 
+// concurrentIndexDeleter is a thread-safe indexDeleter recording how many deletions ran at once
+type concurrentIndexDeleter struct {
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	deleted  map[string]bool
+	failOn   map[string]bool
+}
+
+func (d *concurrentIndexDeleter) DeleteIndex(index string) error {
+	d.mu.Lock()
+	d.inFlight++
+	d.maxSeen = max(d.maxSeen, d.inFlight)
+	d.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.inFlight--
+	if d.failOn[index] {
+		return fmt.Errorf("index %s is locked", index)
+	}
+	d.deleted[index] = true
+	return nil
+}
+
+func (d *concurrentIndexDeleter) IndexExists(index string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.deleted[index], nil
+}
+
+func TestDeleteIndicesConcurrently(t *testing.T) {
+	indices := make([]string, 0, 20)
+	for i := range 20 {
+		indices = append(indices, fmt.Sprintf("sts_index_%02d", i))
+	}
+	log := logger.New(logger.LevelError, "")
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		deleter := &concurrentIndexDeleter{deleted: map[string]bool{}}
+
+		deleted, err := deleteIndicesConcurrently(deleter, indices, 4, nil, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, indices, deleted)
+		assert.LessOrEqual(t, deleter.maxSeen, 4)
+		assert.Greater(t, deleter.maxSeen, 1)
+	})
+
+	t.Run("sequential", func(t *testing.T) {
+		deleter := &concurrentIndexDeleter{deleted: map[string]bool{}}
+
+		deleted, err := deleteIndicesConcurrently(deleter, indices, 1, nil, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, indices, deleted)
+		assert.Equal(t, 1, deleter.maxSeen)
+	})
+
+	t.Run("aggregates failures", func(t *testing.T) {
+		deleter := &concurrentIndexDeleter{
+			deleted: map[string]bool{},
+			failOn:  map[string]bool{"sts_index_03": true, "sts_index_11": true},
+		}
+		var out bytes.Buffer
+		emitter, err := events.New(events.FormatNDJSON, &out, "")
+		require.NoError(t, err)
+
+		deleted, err := deleteIndicesConcurrently(deleter, indices, 4, emitter, log)
+
+		assert.Equal(t, 18, strings.Count(out.String(), `"type":"index_deleted"`))
+		assert.NotContains(t, out.String(), "sts_index_03")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to delete 2 of 20 index(es)")
+		assert.Contains(t, err.Error(), "sts_index_03")
+		assert.Contains(t, err.Error(), "sts_index_11")
+		assert.Len(t, deleted, 18)
+		assert.NotContains(t, deleted, "sts_index_03")
+	})
+}
+
 // TestRestoreSnapshot_Integration tests snapshot info retrieval
 func TestRestoreSnapshot_Integration(t *testing.T) {
 	if testing.Short() {
@@ -424,8 +559,106 @@ func TestRestoreSnapshot_Integration(t *testing.T) {
 	assert.Equal(t, 3, len(snapshot.Indices))
 }
 
+func TestValidateRestoreOptions(t *testing.T) {
+	assert.NoError(t, validateRestoreOptions(&restoreOptions{DeleteConcurrency: 1}, false))
+	assert.NoError(t, validateRestoreOptions(&restoreOptions{DeleteConcurrency: 4, ForceMergeSegments: 1}, false))
+	assert.NoError(t, validateRestoreOptions(&restoreOptions{DeleteConcurrency: 1, Interactive: true}, false))
+
+	err := validateRestoreOptions(&restoreOptions{DeleteConcurrency: 0}, false)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+
+	err = validateRestoreOptions(&restoreOptions{DeleteConcurrency: 1, ForceMergeSegments: -1}, false)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+
+	// The snapshot name has to be typed to confirm an interactive restore
+	err = validateRestoreOptions(&restoreOptions{DeleteConcurrency: 1, Interactive: true}, true)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
+
+func TestCheckPartialSnapshot(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	partial := &elasticsearch.Snapshot{
+		Snapshot: "snap-1",
+		State:    "PARTIAL",
+		Failures: []elasticsearch.SnapshotShardFailure{
+			{Index: "sts_b", ShardID: 0, Reason: "node left"},
+			{Index: "sts_a", ShardID: 2, Reason: "node left"},
+		},
+	}
+	partial.Shards.Failed = 2
+
+	t.Run("complete snapshot", func(t *testing.T) {
+		assert.NoError(t, checkPartialSnapshot(&elasticsearch.Snapshot{Snapshot: "snap-1", State: "SUCCESS"}, false, log))
+	})
+
+	t.Run("partial snapshot is refused", func(t *testing.T) {
+		err := checkPartialSnapshot(partial, false, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+		assert.Contains(t, err.Error(), "2 failed shard(s) in index(es) sts_a, sts_b")
+		assert.Contains(t, err.Error(), "--allow-partial")
+	})
+
+	t.Run("partial snapshot is allowed", func(t *testing.T) {
+		assert.NoError(t, checkPartialSnapshot(partial, true, log))
+	})
+
+	t.Run("partial state without failure details", func(t *testing.T) {
+		err := checkPartialSnapshot(&elasticsearch.Snapshot{Snapshot: "snap-1", State: "PARTIAL"}, false, log)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown index(es)")
+	})
+}
+
 // TestRestoreConstants tests the restore command constants
 func TestRestoreConstants(t *testing.T) {
 	assert.Equal(t, 30, defaultMaxIndexDeleteAttempts)
 	assert.Equal(t, 1*time.Second, defaultIndexDeleteRetryInterval)
 }
+
+func TestRestoreRecord_AuditEntry(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Elasticsearch.Restore.Repository = "sts-backup"
+	cfg.Elasticsearch.Restore.IndicesPattern = "sts*"
+	opts := &restoreOptions{SnapshotName: "sts-backup-20250304-0300", ExcludeIndices: []string{"sts_k8s_logs*"}}
+	record := &restoreRecord{deletedIndices: []string{"sts_topology"}, safetySnapshot: "pre-restore-20250304-050607"}
+
+	entry := record.auditEntry(cfg, opts, time.Now().Add(-time.Minute))
+
+	assert.Equal(t, "restore", entry.Operation)
+	assert.Equal(t, "sts-backup-20250304-0300", entry.Snapshot)
+	assert.Equal(t, "sts-backup", entry.Repository)
+	assert.Equal(t, "sts*,-sts_k8s_logs*", entry.IndicesPattern)
+	assert.Equal(t, []string{"sts_topology"}, entry.IndicesDeleted)
+	assert.Equal(t, "pre-restore-20250304-050607", entry.SafetySnapshot)
+	assert.GreaterOrEqual(t, entry.DurationMillis, time.Minute.Milliseconds())
+}
+
+func TestEnableMaintenanceMode(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver-config", Namespace: "test-ns"},
+		Data:       map[string]string{"maintenance": "false"},
+	})
+	k8sClient := k8s.NewTestClient(fakeClient)
+	log := logger.New(logger.LevelError, "")
+	flagValue := func() string {
+		cm, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), "receiver-config", metav1.GetOptions{})
+		require.NoError(t, err)
+		return cm.Data["maintenance"]
+	}
+
+	disable, err := enableMaintenanceMode(k8sClient, "test-ns", config.MaintenanceConfig{ConfigMap: "receiver-config", Key: "maintenance", Value: "true"}, log)
+	require.NoError(t, err)
+	assert.Equal(t, "true", flagValue())
+
+	disable()
+	assert.Equal(t, "false", flagValue())
+
+	// Without a configured flag nothing is changed
+	disable, err = enableMaintenanceMode(k8sClient, "test-ns", config.MaintenanceConfig{}, log)
+	require.NoError(t, err)
+	disable()
+	assert.Equal(t, "false", flagValue())
+}
@@ -0,0 +1,137 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// targetConfigYAML returns a configuration with the given Elasticsearch service and snapshot repository
+func targetConfigYAML(service, repository, endpoint string) string {
+	return `
+elasticsearch:
+  service:
+    name: ` + service + `
+    port: 9200
+    localPortForwardPort: 9200
+  restore:
+    scaleDownLabelSelector: app=test
+    indexPrefix: sts_
+    datastreamIndexPrefix: sts_k8s_logs
+    datastreamName: sts_k8s_logs
+    indicesPattern: "sts_*"
+    repository: ` + repository + `
+  snapshotRepository:
+    name: ` + repository + `
+    bucket: backups
+    endpoint: ` + endpoint + `
+    basepath: snapshots
+    accessKey: key
+    secretKey: secret
+  slm:
+    name: daily
+    schedule: "0 1 * * *"
+    snapshotTemplateName: "<snap-{now/d}>"
+    repository: ` + repository + `
+    indices: "sts_*"
+    retentionExpireAfter: 30d
+    retentionMinCount: 5
+    retentionMaxCount: 50
+`
+}
+
+func TestMergeRestoreTarget(t *testing.T) {
+	source := &config.Config{}
+	source.Elasticsearch.SnapshotRepository = config.SnapshotRepositoryConfig{
+		Name: "sts-backup", Bucket: "sts-backup", Endpoint: "suse-observability-minio:9000", AccessKey: "prod-key", SecretKey: "prod-secret",
+	}
+	source.Elasticsearch.Restore.IndicesPattern = "sts*,.ds-sts*"
+
+	target := &config.Config{}
+	target.Elasticsearch.Service.Name = "staging-elasticsearch"
+	target.Elasticsearch.SnapshotRepository = config.SnapshotRepositoryConfig{Name: "sts-backup", Endpoint: "suse-observability-minio:9000"}
+	target.Elasticsearch.Restore = config.RestoreConfig{Repository: "sts-backup", IndicesPattern: "sts*", ScaleDownLabelSelector: "app=staging"}
+
+	merged := mergeRestoreTarget(source, target, "prod")
+
+	repo := merged.Elasticsearch.SnapshotRepository
+	assert.Equal(t, "sts-backup-from-prod", repo.Name)
+	assert.Equal(t, "suse-observability-minio.prod.svc:9000", repo.Endpoint)
+	assert.Equal(t, "prod-key", repo.AccessKey)
+	assert.Equal(t, "sts-backup-from-prod", merged.Elasticsearch.Restore.Repository)
+	assert.Equal(t, "sts*,.ds-sts*", merged.Elasticsearch.Restore.IndicesPattern)
+	assert.Equal(t, "app=staging", merged.Elasticsearch.Restore.ScaleDownLabelSelector)
+	assert.Equal(t, "staging-elasticsearch", merged.Elasticsearch.Service.Name)
+
+	// The target configuration is not modified
+	assert.Equal(t, "sts-backup", target.Elasticsearch.Restore.Repository)
+}
+
+func TestMergeRestoreTarget_ExternalEndpoint(t *testing.T) {
+	source := &config.Config{}
+	source.Elasticsearch.SnapshotRepository = config.SnapshotRepositoryConfig{Name: "sts-backup", Endpoint: "s3.eu-west-1.amazonaws.com:443"}
+
+	merged := mergeRestoreTarget(source, &config.Config{}, "prod")
+
+	assert.Equal(t, "s3.eu-west-1.amazonaws.com:443", merged.Elasticsearch.SnapshotRepository.Endpoint)
+}
+
+func TestResolveRestoreTarget(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: testConfigMapName, Namespace: "staging"},
+		Data:       map[string]string{"config": targetConfigYAML("staging-es", "staging-repo", "minio:9000")},
+	})
+	k8sClient := k8s.NewTestClient(clientset)
+	log := logger.New(logger.LevelError, "")
+
+	cliCtx := config.NewContext()
+	cliCtx.Config.Namespace = "prod"
+	cliCtx.Config.ConfigMapName = testConfigMapName
+
+	source := &config.Config{}
+	source.Elasticsearch.SnapshotRepository = config.SnapshotRepositoryConfig{Name: "prod-repo", Endpoint: "minio:9000"}
+	source.Elasticsearch.Restore.IndicesPattern = "sts_*"
+
+	t.Run("without target restores into the source", func(t *testing.T) {
+		target, err := resolveRestoreTarget(cliCtx, &restoreOptions{}, k8sClient, source, log)
+
+		require.NoError(t, err)
+		assert.False(t, target.remote)
+		assert.Same(t, source, target.cfg)
+		assert.Same(t, cliCtx, target.cliCtx)
+	})
+
+	t.Run("target namespace", func(t *testing.T) {
+		target, err := resolveRestoreTarget(cliCtx, &restoreOptions{TargetNamespace: "staging"}, k8sClient, source, log)
+
+		require.NoError(t, err)
+		assert.True(t, target.remote)
+		assert.Equal(t, "staging", target.cliCtx.Config.Namespace)
+		assert.Equal(t, "prod", cliCtx.Config.Namespace)
+		assert.Equal(t, "staging-es", target.cfg.Elasticsearch.Service.Name)
+		assert.Equal(t, "prod-repo-from-prod", target.cfg.Elasticsearch.Restore.Repository)
+		assert.Equal(t, "minio.prod.svc:9000", target.cfg.Elasticsearch.SnapshotRepository.Endpoint)
+	})
+
+	t.Run("target namespace without configuration", func(t *testing.T) {
+		_, err := resolveRestoreTarget(cliCtx, &restoreOptions{TargetNamespace: "other"}, k8sClient, source, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.ConfigError, exitcode.Of(err))
+	})
+
+	t.Run("target namespace is the source", func(t *testing.T) {
+		_, err := resolveRestoreTarget(cliCtx, &restoreOptions{TargetNamespace: "prod"}, k8sClient, source, log)
+
+		require.Error(t, err)
+		assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	})
+}
@@ -0,0 +1,163 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+func rollbackRestoreCmd(cliCtx *config.Context) *cobra.Command {
+	var runID string
+	cmd := &cobra.Command{
+		Use:   "rollback-restore",
+		Short: "Roll back a restore to the indices it deleted",
+		Long: `Roll back a restore that deleted the existing indices (--drop-all-indices) to the state before it ran.
+The restore is looked up in the audit log, which records the safety snapshot taken before the indices were deleted.
+The current STS indices are deleted and the safety snapshot is restored, with the deployments scaled down as
+during a restore. The most recent restore with a safety snapshot is rolled back unless --run-id is given.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRollbackRestore(cliCtx, runID); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&runID, "run-id", "", "Run ID of the restore to roll back, see 'history' (default: the most recent restore with a safety snapshot)")
+	addConfirmNamespaceFlag(cmd, cliCtx)
+	addSkipHealthCheckFlag(cmd, cliCtx)
+	return cmd
+}
+
+func runRollbackRestore(cliCtx *config.Context, runID string) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	store := audit.NewStore(env.K8s.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.AuditConfigMapName)
+	entries, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	manifest, err := findRollbackManifest(entries, runID)
+	if err != nil {
+		return err
+	}
+
+	env.Log.Infof("Restore %s by %s at %s (%s) deleted %d index(es); safety snapshot '%s' holds them",
+		manifest.RunID, manifest.User, manifest.Timestamp.Local().Format(time.RFC3339), manifest.Outcome,
+		len(manifest.IndicesDeleted), manifest.SafetySnapshot)
+	prompter := prompt.New(cliCtx.Config.AssumeYes)
+	if err := confirmClusterIdentity(env.K8s, cliCtx, prompter, env.Log); err != nil {
+		return err
+	}
+	if err := prompter.Confirm(fmt.Sprintf("Delete the current STS indices and restore safety snapshot '%s'?", manifest.SafetySnapshot)); err != nil {
+		return fmt.Errorf("rollback aborted: %w", err)
+	}
+
+	// Record the rollback and the deleted indices in the audit log
+	startedAt := time.Now()
+	record := &restoreRecord{}
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{
+			Operation:      "rollback-restore",
+			Snapshot:       manifest.SafetySnapshot,
+			Repository:     env.Config.Elasticsearch.SLM.Repository,
+			IndicesDeleted: record.deletedIndices,
+			DurationMillis: time.Since(startedAt).Milliseconds(),
+		}, err, env.Log)
+	}()
+
+	if err := checkElasticsearchPods(env.K8s, cliCtx, env.Config, env.Log); err != nil {
+		return err
+	}
+
+	// Setup port-forward to Elasticsearch
+	pf, err := portForwardElasticsearch(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer close(pf.StopChan)
+	pf.KeepAlive(portforward.DefaultKeepAliveInterval, env.Log)
+
+	// Create Elasticsearch client
+	esClient, err := newESClient(env.K8s, cliCtx, env.Config, pf.LocalPort, env.Log)
+	if err != nil {
+		return err
+	}
+
+	// Scale down deployments before the rollback, and back up on exit (even if the rollback fails)
+	scaledDeployments, err := target.ScaleDownDeployments(env.K8s, cliCtx.Config.Namespace, env.Config.Elasticsearch.Restore.ScaleDownLabelSelector, env.Log)
+	if err != nil {
+		return err
+	}
+	defer target.ScaleUpDeployments(env.K8s, cliCtx.Config.Namespace, scaledDeployments, env.Log)
+	scaledStatefulSets, err := target.ScaleDownStatefulSets(env.K8s, cliCtx.Config.Namespace, env.Config.Elasticsearch.Restore.ScaleDownStatefulSetSelectors, defaultStatefulSetTimeout, env.Log)
+	defer target.ScaleUpStatefulSets(env.K8s, cliCtx.Config.Namespace, scaledStatefulSets, defaultStatefulSetTimeout, env.Log)
+	if err != nil {
+		return err
+	}
+
+	return rollbackToSafetySnapshot(esClient, env.Config, manifest.SafetySnapshot, record, env.Log)
+}
+
+// findRollbackManifest returns the audit entry of the restore to roll back: the restore with the given run ID,
+// or the most recent restore that took a safety snapshot when runID is empty
+func findRollbackManifest(entries []audit.Entry, runID string) (*audit.Entry, error) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Operation != "restore" || (runID != "" && entry.RunID != runID) {
+			continue
+		}
+		if entry.SafetySnapshot == "" {
+			if runID == "" {
+				continue
+			}
+			return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("restore %s took no safety snapshot and cannot be rolled back", runID))
+		}
+		return &entry, nil
+	}
+
+	if runID != "" {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("no restore with run ID %s found in the audit log", runID))
+	}
+	return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("no restore with a safety snapshot found in the audit log"))
+}
+
+// rollbackToSafetySnapshot replaces the current STS indices with the indices in the safety snapshot. The safety
+// snapshot is restored from the SLM repository it was taken in, without feature states since it has none.
+func rollbackToSafetySnapshot(esClient *elasticsearch.Client, cfg *config.Config, safetySnapshot string, record *restoreRecord, log *logger.Logger) error {
+	repository := cfg.Elasticsearch.SLM.Repository
+	if _, err := esClient.GetSnapshot(repository, safetySnapshot); err != nil {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("safety snapshot %s is not available in repository '%s': %w", safetySnapshot, repository, err))
+	}
+
+	log.Infof("Fetching current Elasticsearch indices...")
+	allIndices, err := esClient.ListIndices("*")
+	if err != nil {
+		return fmt.Errorf("failed to list indices: %w", err)
+	}
+	stsIndices := filterSTSIndices(allIndices, cfg.Elasticsearch.Restore.IndexPrefix, cfg.Elasticsearch.Restore.DatastreamIndexPrefix)
+
+	// The rollback has been confirmed, and the indices replaced are those of the restore being undone
+	opts := &restoreOptions{SnapshotName: safetySnapshot, DeleteConcurrency: defaultDeleteConcurrency, SkipSafetySnapshot: true}
+	if err := deleteIndices(esClient, stsIndices, cfg, opts, record, log, prompt.New(true)); err != nil {
+		return err
+	}
+
+	rollbackCfg := *cfg
+	rollbackCfg.Elasticsearch.Restore.Repository = repository
+	rollbackCfg.Elasticsearch.Restore.FeatureStates = nil
+	return restoreSnapshot(esClient, &rollbackCfg, opts, record, log)
+}
@@ -0,0 +1,80 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRollbackRestoreCmd_Unit tests the command structure
+func TestRollbackRestoreCmd_Unit(t *testing.T) {
+	cmd := rollbackRestoreCmd(config.NewContext())
+
+	assert.Equal(t, "rollback-restore", cmd.Use)
+	assert.NotEmpty(t, cmd.Long)
+	assert.NotNil(t, cmd.Run)
+	assert.NotNil(t, cmd.Flags().Lookup("run-id"))
+}
+
+func TestFindRollbackManifest(t *testing.T) {
+	start := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	entries := []audit.Entry{
+		{RunID: "run-1", Timestamp: start, Operation: "restore", SafetySnapshot: "pre-restore-20250110-120000"},
+		{RunID: "run-2", Timestamp: start.Add(time.Hour), Operation: "restore", SafetySnapshot: "pre-restore-20250110-130000"},
+		{RunID: "run-3", Timestamp: start.Add(2 * time.Hour), Operation: "restore"},
+		{RunID: "run-4", Timestamp: start.Add(3 * time.Hour), Operation: "configure"},
+	}
+
+	tests := []struct {
+		name           string
+		runID          string
+		expectSnapshot string
+		expectError    string
+	}{
+		{
+			name:           "most recent restore with a safety snapshot",
+			expectSnapshot: "pre-restore-20250110-130000",
+		},
+		{
+			name:           "restore by run ID",
+			runID:          "run-1",
+			expectSnapshot: "pre-restore-20250110-120000",
+		},
+		{
+			name:        "restore without safety snapshot",
+			runID:       "run-3",
+			expectError: "took no safety snapshot",
+		},
+		{
+			name:        "run ID of another operation",
+			runID:       "run-4",
+			expectError: "no restore with run ID run-4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := findRollbackManifest(entries, tt.runID)
+
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSnapshot, manifest.SafetySnapshot)
+		})
+	}
+
+	t.Run("empty audit log", func(t *testing.T) {
+		_, err := findRollbackManifest(nil, "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no restore with a safety snapshot")
+	})
+}
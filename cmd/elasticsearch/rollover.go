@@ -0,0 +1,128 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+type rolloverOptions struct {
+	Datastream string
+	elasticsearch.RolloverOptions
+}
+
+func rolloverCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &rolloverOptions{}
+	cmd := &cobra.Command{
+		Use:   "rollover",
+		Short: "Roll a datastream over to a new backing index",
+		Long: `Roll a datastream over to a new backing index. With --max-age or --max-size the rollover only happens when one
+of the conditions is met; without conditions it always happens. --dry-run reports which conditions are met and
+the index the datastream would roll over to, without rolling over.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRollover(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Datastream, "datastream", "", "Datastream to roll over (default: elasticsearch.restore.datastreamName)")
+	cmd.Flags().StringVar(&opts.MaxAge, "max-age", "", "Only roll over when the write index is older than this, e.g. 7d")
+	cmd.Flags().StringVar(&opts.MaxSize, "max-size", "", "Only roll over when the primary shards of the write index are larger than this, e.g. 50gb")
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Check the conditions without rolling over")
+	return cmd
+}
+
+func runRollover(cliCtx *config.Context, opts *rolloverOptions) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	datastream := opts.Datastream
+	if datastream == "" {
+		datastream = env.Config.Elasticsearch.Restore.DatastreamName
+	}
+
+	if !opts.DryRun {
+		defer func() {
+			err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "rollover"}, err, env.Log)
+		}()
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env.Log.Infof("Rolling over datastream '%s'...", datastream)
+	result, err := esClient.RolloverDatastreamWithOptions(datastream, opts.RolloverOptions)
+	if err != nil {
+		return fmt.Errorf("failed to rollover datastream: %w", err)
+	}
+
+	switch {
+	case result.DryRun && rolloverConditionsMet(result):
+		env.Log.Infof("Dry run: datastream '%s' would roll over from %s to %s", datastream, result.OldIndex, result.NewIndex)
+	case result.DryRun:
+		env.Log.Infof("Dry run: datastream '%s' would not roll over, none of the conditions is met", datastream)
+	case result.RolledOver:
+		env.Log.Successf("Datastream '%s' rolled over from %s to %s", datastream, result.OldIndex, result.NewIndex)
+	default:
+		env.Log.Infof("Datastream '%s' not rolled over: none of the conditions is met", datastream)
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	return formatter.PrintDetail(result, rolloverTable(result))
+}
+
+// rolloverConditionsMet reports whether a rollover happens, or would happen in a dry run: always without
+// conditions, else when at least one condition is met
+func rolloverConditionsMet(result *elasticsearch.RolloverResult) bool {
+	if len(result.Conditions) == 0 {
+		return true
+	}
+	for _, met := range result.Conditions {
+		if met {
+			return true
+		}
+	}
+	return false
+}
+
+// rolloverTable lists the old and new index and the rollover conditions with whether they were met
+func rolloverTable(result *elasticsearch.RolloverResult) output.Table {
+	table := output.Table{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"Old index", result.OldIndex},
+			{"New index", result.NewIndex},
+			{"Rolled over", strconv.FormatBool(result.RolledOver)},
+			{"Dry run", strconv.FormatBool(result.DryRun)},
+		},
+	}
+
+	conditions := make([]string, 0, len(result.Conditions))
+	for condition := range result.Conditions {
+		conditions = append(conditions, condition)
+	}
+	sort.Strings(conditions)
+	for _, condition := range conditions {
+		met := "not met"
+		if result.Conditions[condition] {
+			met = "met"
+		}
+		table.Rows = append(table.Rows, []string{"Condition " + condition, met})
+	}
+	return table
+}
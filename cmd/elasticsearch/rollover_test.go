@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloverCmd_Unit(t *testing.T) {
+	cmd := rolloverCmd(config.NewContext())
+
+	assert.Equal(t, "rollover", cmd.Use)
+	for _, flag := range []string{"datastream", "max-age", "max-size"} {
+		assert.Equal(t, "", cmd.Flags().Lookup(flag).DefValue, flag)
+	}
+	assert.Equal(t, "false", cmd.Flags().Lookup("dry-run").DefValue)
+	assert.NotNil(t, cmd.Run)
+}
+
+func TestRolloverConditionsMet(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions map[string]bool
+		expected   bool
+	}{
+		{name: "no conditions", expected: true},
+		{name: "one condition met", conditions: map[string]bool{"[max_age: 7d]": false, "[max_size: 50gb]": true}, expected: true},
+		{name: "no condition met", conditions: map[string]bool{"[max_age: 7d]": false}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rolloverConditionsMet(&elasticsearch.RolloverResult{Conditions: tt.conditions}))
+		})
+	}
+}
+
+func TestRolloverTable(t *testing.T) {
+	result := &elasticsearch.RolloverResult{
+		OldIndex:   ".ds-sts_k8s_logs-000001",
+		NewIndex:   ".ds-sts_k8s_logs-000002",
+		DryRun:     true,
+		Conditions: map[string]bool{"[max_size: 50gb]": false, "[max_age: 7d]": true},
+	}
+
+	table := rolloverTable(result)
+
+	assert.Equal(t, [][]string{
+		{"Old index", ".ds-sts_k8s_logs-000001"},
+		{"New index", ".ds-sts_k8s_logs-000002"},
+		{"Rolled over", "false"},
+		{"Dry run", "true"},
+		{"Condition [max_age: 7d]", "met"},
+		{"Condition [max_size: 50gb]", "not met"},
+	}, table.Rows)
+}
@@ -0,0 +1,191 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// credentialRotator replaces the credentials of a repository and verifies it
+type credentialRotator interface {
+	repositoryVerifier
+	GetRepository(name string) (*elasticsearch.Repository, error)
+	PutS3Repository(name string, settings map[string]interface{}) error
+}
+
+// bucketHeader checks that credentials can access a bucket
+type bucketHeader interface {
+	HeadBucket(bucket string) error
+}
+
+// rotationResult is the outcome of a credential rotation
+type rotationResult struct {
+	Repository string `json:"repository"`
+	Bucket     string `json:"bucket"`
+	// AccessKey is the new access key, masked
+	AccessKey string `json:"accessKey"`
+	// Unchanged is set when the repository already used the new credentials
+	Unchanged  bool `json:"unchanged,omitempty"`
+	Verified   bool `json:"verified"`
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+func rotateCredentialsCmd(cliCtx *config.Context) *cobra.Command {
+	var precheck bool
+	cmd := &cobra.Command{
+		Use:   "rotate-credentials",
+		Short: "Put the credentials of the Secret into the snapshot repository and verify it",
+		Long: `Make a rotation of the object storage credentials a single command: the accessKey and secretKey are read from
+the Secret, checked against the bucket directly, and put into the snapshot repository while keeping its other
+settings. Afterwards the repository is verified by taking and deleting an empty test snapshot.
+
+'configure' cannot detect new credentials, since Elasticsearch does not return them, so run this command after
+rotating them, e.g. from the job that rotates the MinIO credentials. When the verification fails and the previous
+credentials are known, the repository is reset to them. Exits with code 5 when the new credentials do not work.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRotateCredentials(cliCtx, precheck); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&precheck, "precheck", true, "Check the new credentials against the bucket before putting them into the repository")
+	return cmd
+}
+
+func runRotateCredentials(cliCtx *config.Context, precheck bool) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	repo := env.Config.Elasticsearch.SnapshotRepository
+	if repo.AccessKey == "" || repo.SecretKey == "" {
+		return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("accessKey and secretKey are required in the secret configuration"))
+	}
+
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "rotate-credentials", Repository: repo.Name}, err, env.Log)
+	}()
+
+	if precheck {
+		bucket, cleanup, err := target.OpenBucket(env.K8s, cliCtx, env.Config, env.Log)
+		if err != nil {
+			return err
+		}
+		err = checkNewCredentials(bucket, repo.Bucket, env.Log)
+		cleanup()
+		if err != nil {
+			return err
+		}
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	result, err := rotateCredentials(esClient, repo, time.Now(), env.Log)
+	if result != nil {
+		formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+		if printErr := formatter.PrintDetail(result, rotationTable(result)); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+	return err
+}
+
+// checkNewCredentials checks that the new credentials can access the bucket, so a wrong key is found before the
+// repository is changed
+func checkNewCredentials(client bucketHeader, bucket string, log *logger.Logger) error {
+	log.Infof("Checking the new credentials against bucket '%s'...", bucket)
+	if err := client.HeadBucket(bucket); err != nil {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("the new credentials cannot access bucket '%s', the repository is left unchanged: %w", bucket, err))
+	}
+	return nil
+}
+
+// rotateCredentials puts the credentials of repo into the existing repository, keeping its other settings, and
+// verifies it. When the verification fails and the repository returned its previous credentials, they are put back.
+func rotateCredentials(client credentialRotator, repo config.SnapshotRepositoryConfig, now time.Time, log *logger.Logger) (*rotationResult, error) {
+	current, err := client.GetRepository(repo.Name)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("failed to get snapshot repository %s (run 'elasticsearch configure' to create it): %w", repo.Name, err))
+	}
+	if current.Type != "s3" {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("snapshot repository %s has type %s, credentials can only be rotated for s3 repositories", repo.Name, current.Type))
+	}
+
+	result := &rotationResult{Repository: repo.Name, Bucket: repo.Bucket, AccessKey: maskKey(repo.AccessKey)}
+	previous := maps.Clone(current.Settings)
+	if previous["access_key"] == repo.AccessKey && previous["secret_key"] == repo.SecretKey {
+		log.Infof("Snapshot repository '%s' already uses the credentials of the Secret", repo.Name)
+		result.Unchanged = true
+	} else {
+		settings := maps.Clone(current.Settings)
+		settings["access_key"] = repo.AccessKey
+		settings["secret_key"] = repo.SecretKey
+		log.Infof("Putting the new credentials into snapshot repository '%s'...", repo.Name)
+		if err := client.PutS3Repository(repo.Name, settings); err != nil {
+			return nil, fmt.Errorf("failed to update snapshot repository %s: %w", repo.Name, err)
+		}
+	}
+
+	if err := verifyRepository(client, repo.Name, now, log); err != nil {
+		if !result.Unchanged && previous["access_key"] != nil && previous["secret_key"] != nil {
+			log.Warningf("Putting the previous credentials back into snapshot repository '%s'...", repo.Name)
+			if rollbackErr := client.PutS3Repository(repo.Name, previous); rollbackErr != nil {
+				return result, fmt.Errorf("%w; restoring the previous credentials failed as well: %w", err, rollbackErr)
+			}
+			result.RolledBack = true
+		}
+		return result, err
+	}
+
+	result.Verified = true
+	log.Successf("Snapshot repository '%s' works with the new credentials", repo.Name)
+	return result, nil
+}
+
+// maskKey shows the first characters of an access key, enough to tell keys apart
+func maskKey(key string) string {
+	const visible = 4
+	if len(key) <= visible {
+		return "****"
+	}
+	return key[:visible] + "****"
+}
+
+func rotationTable(result *rotationResult) output.Table {
+	status := "rotated"
+	switch {
+	case result.RolledBack:
+		status = "rolled back"
+	case result.Unchanged:
+		status = "unchanged"
+	case !result.Verified:
+		status = "failed"
+	}
+	return output.Table{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"Repository", result.Repository},
+			{"Bucket", result.Bucket},
+			{"Access key", result.AccessKey},
+			{"Credentials", status},
+			{"Verified", strconv.FormatBool(result.Verified)},
+		},
+	}
+}
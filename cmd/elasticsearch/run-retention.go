@@ -0,0 +1,174 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+const (
+	// defaultRetentionTimeout is the time to wait for an SLM retention run to finish
+	defaultRetentionTimeout = 5 * time.Minute
+	// retentionPollInterval is the time between checks whether the retention run has finished
+	retentionPollInterval = 2 * time.Second
+)
+
+// slmRetentionClient runs SLM retention and reports its statistics
+type slmRetentionClient interface {
+	ExecuteSLMRetention() error
+	SLMStats() (*elasticsearch.SLMStats, error)
+}
+
+func runRetentionCmd(cliCtx *config.Context) *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:   "run-retention",
+		Short: "Run SLM retention now and report the deleted snapshots",
+		Long: `Run the retention of all SLM policies immediately instead of waiting for the nightly retention schedule,
+e.g. to free space in the snapshot repository. Retention deletes the snapshots that exceed the retention configured
+in the SLM policy (see 'configure'). The command waits until the run has finished and lists the deleted snapshots.
+
+Use 'enforce-retention' instead where SLM retention is disabled or not running.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRunRetention(cliCtx, timeout); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultRetentionTimeout, "Time to wait for the retention run to finish")
+	return cmd
+}
+
+func runRunRetention(cliCtx *config.Context, timeout time.Duration) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	// Record deleted snapshots in the audit log and notify configured targets
+	var deleted []string
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "run-retention", SnapshotsDeleted: deleted}, err, env.Log)
+	}()
+	startedAt := time.Now()
+	defer func() {
+		sendNotification(env.Config, cliCtx, "run-retention", startedAt, err, map[string]string{"deleted": strconv.Itoa(len(deleted))}, env.Log)
+	}()
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repository := env.Config.Elasticsearch.SLM.Repository
+	before, err := esClient.ListSnapshots(repository)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	if err := executeRetention(esClient, timeout, retentionPollInterval, env.Log); err != nil {
+		return err
+	}
+
+	after, err := esClient.ListSnapshots(repository)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	removed := removedSnapshots(before, after)
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(removed) == 0 {
+		formatter.PrintMessage(fmt.Sprintf("Retention deleted no snapshots from repository '%s'", repository))
+		return nil
+	}
+
+	for _, snapshot := range removed {
+		deleted = append(deleted, snapshot.Snapshot)
+	}
+	env.Log.Successf("Retention deleted %d snapshot(s) from repository '%s'", len(removed), repository)
+	return formatter.PrintTable(removedSnapshotsTable(removed, time.Now()))
+}
+
+// executeRetention starts SLM retention and waits until the run has finished. Retention is asynchronous,
+// so the run is detected by the number of finished runs in the SLM statistics going up.
+func executeRetention(client slmRetentionClient, timeout, interval time.Duration, log *logger.Logger) error {
+	initial, err := client.SLMStats()
+	if err != nil {
+		return fmt.Errorf("failed to get SLM stats: %w", err)
+	}
+
+	log.Infof("Running SLM retention...")
+	if err := client.ExecuteSLMRetention(); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		stats, err := client.SLMStats()
+		if err != nil {
+			return fmt.Errorf("failed to get SLM stats: %w", err)
+		}
+
+		if stats.RetentionRunsFinished() > initial.RetentionRunsFinished() {
+			if failures := stats.TotalSnapshotDeleteFailed - initial.TotalSnapshotDeleteFailed; failures > 0 {
+				log.Warningf("Retention failed to delete %d snapshot(s)", failures)
+			}
+			switch {
+			case stats.RetentionFailed > initial.RetentionFailed:
+				return errors.New("SLM retention run failed, see the Elasticsearch logs for details")
+			case stats.RetentionTimedOut > initial.RetentionTimedOut:
+				log.Warningf("SLM retention run timed out (slm.retention_duration) before all expired snapshots were deleted")
+			}
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout after %s waiting for SLM retention to finish", timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// removedSnapshots returns the snapshots of before that are no longer in after
+func removedSnapshots(before, after []elasticsearch.Snapshot) []elasticsearch.Snapshot {
+	remaining := make(map[string]bool, len(after))
+	for _, snapshot := range after {
+		remaining[snapshot.Snapshot] = true
+	}
+
+	var removed []elasticsearch.Snapshot
+	for _, snapshot := range before {
+		if !remaining[snapshot.Snapshot] {
+			removed = append(removed, snapshot)
+		}
+	}
+	return removed
+}
+
+// removedSnapshotsTable converts the snapshots deleted by retention into a table
+func removedSnapshotsTable(removed []elasticsearch.Snapshot, now time.Time) output.Table {
+	table := output.Table{
+		Headers: []string{"SNAPSHOT", "START TIME", "AGE"},
+		Rows:    make([][]string, 0, len(removed)),
+	}
+
+	for _, snapshot := range removed {
+		table.Rows = append(table.Rows, []string{snapshot.Snapshot, snapshot.StartTime, snapshotAge(snapshot, now)})
+	}
+
+	return table
+}
@@ -0,0 +1,120 @@
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRetentionClient returns the given stats in order, repeating the last one
+type mockRetentionClient struct {
+	stats      []elasticsearch.SLMStats
+	calls      int
+	executed   bool
+	executeErr error
+}
+
+func (m *mockRetentionClient) ExecuteSLMRetention() error {
+	m.executed = true
+	return m.executeErr
+}
+
+func (m *mockRetentionClient) SLMStats() (*elasticsearch.SLMStats, error) {
+	stats := m.stats[min(m.calls, len(m.stats)-1)]
+	m.calls++
+	return &stats, nil
+}
+
+// TestRunRetentionCmd_Unit tests the command structure
+func TestRunRetentionCmd_Unit(t *testing.T) {
+	cmd := runRetentionCmd(config.NewContext())
+
+	assert.Equal(t, "run-retention", cmd.Use)
+	assert.NotEmpty(t, cmd.Long)
+	assert.NotNil(t, cmd.Run)
+
+	timeoutFlag := cmd.Flags().Lookup("timeout")
+	require.NotNil(t, timeoutFlag)
+	assert.Equal(t, "5m0s", timeoutFlag.DefValue)
+}
+
+func TestExecuteRetention(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	initial := elasticsearch.SLMStats{RetentionRuns: 3, RetentionFailed: 1}
+
+	tests := []struct {
+		name        string
+		client      *mockRetentionClient
+		expectError string
+	}{
+		{
+			name: "waits until the run finished",
+			client: &mockRetentionClient{stats: []elasticsearch.SLMStats{
+				initial, initial, initial, {RetentionRuns: 4, RetentionFailed: 1},
+			}},
+		},
+		{
+			name: "timed out run is not an error",
+			client: &mockRetentionClient{stats: []elasticsearch.SLMStats{
+				initial, {RetentionRuns: 3, RetentionFailed: 1, RetentionTimedOut: 1},
+			}},
+		},
+		{
+			name: "failed run",
+			client: &mockRetentionClient{stats: []elasticsearch.SLMStats{
+				initial, {RetentionRuns: 3, RetentionFailed: 2},
+			}},
+			expectError: "SLM retention run failed",
+		},
+		{
+			name:        "run does not finish in time",
+			client:      &mockRetentionClient{stats: []elasticsearch.SLMStats{initial}},
+			expectError: "timeout",
+		},
+		{
+			name:        "execute fails",
+			client:      &mockRetentionClient{stats: []elasticsearch.SLMStats{initial}, executeErr: errors.New("forbidden")},
+			expectError: "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := executeRetention(tt.client, 20*time.Millisecond, time.Millisecond, log)
+
+			assert.True(t, tt.client.executed)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRemovedSnapshots(t *testing.T) {
+	before := []elasticsearch.Snapshot{{Snapshot: "snap-1"}, {Snapshot: "snap-2"}, {Snapshot: "snap-3"}}
+	after := []elasticsearch.Snapshot{{Snapshot: "snap-2"}, {Snapshot: "snap-4"}}
+
+	removed := removedSnapshots(before, after)
+
+	require.Len(t, removed, 2)
+	assert.Equal(t, "snap-1", removed[0].Snapshot)
+	assert.Equal(t, "snap-3", removed[1].Snapshot)
+	assert.Empty(t, removedSnapshots(after, after))
+}
+
+func TestRemovedSnapshotsTable(t *testing.T) {
+	table := removedSnapshotsTable([]elasticsearch.Snapshot{snapshotAged("snap-1", "SUCCESS", 40)}, retentionNow)
+
+	assert.Equal(t, []string{"SNAPSHOT", "START TIME", "AGE"}, table.Headers)
+	require.Len(t, table.Rows, 1)
+	assert.Equal(t, "snap-1", table.Rows[0][0])
+}
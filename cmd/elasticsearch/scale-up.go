@@ -0,0 +1,83 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+func scaleUpCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "scale-up",
+		Short: "Scale deployments back up after an interrupted restore",
+		Long: `Scale the deployments scaled down for a restore back up to their original replica counts. A restore scales the
+deployments back up itself, also when it fails; use this command when the restore process was killed before it could.
+The StatefulSets of elasticsearch.restore.scaleDownStatefulSetSelectors are scaled up first.
+
+The original replica count is read from the ` + k8s.OriginalReplicasAnnotation + ` annotation that a restore puts on
+every deployment and StatefulSet it scales down. Those without the annotation are left unchanged.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runScaleUp(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runScaleUp(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if err := scaleUpAnnotatedStatefulSets(env.K8s, cliCtx.Config.Namespace, env.Config.Elasticsearch.Restore.ScaleDownStatefulSetSelectors, env.Log); err != nil {
+		return err
+	}
+	return scaleUpAnnotatedDeployments(env.K8s, cliCtx.Config.Namespace, env.Config.Elasticsearch.Restore.ScaleDownLabelSelector, env.Log)
+}
+
+// scaleUpAnnotatedStatefulSets scales the StatefulSets left scaled down by an interrupted restore back up, in the
+// reverse order of the selectors
+func scaleUpAnnotatedStatefulSets(k8sClient k8s.Interface, namespace string, labelSelectors []string, log *logger.Logger) error {
+	for i := len(labelSelectors) - 1; i >= 0; i-- {
+		log.Infof("Scaling up statefulsets left scaled down (selector: %s)...", labelSelectors[i])
+		scaled, err := k8sClient.ScaleUpAnnotatedStatefulSets(namespace, labelSelectors[i])
+		for _, sts := range scaled {
+			log.Infof("  - %s (replicas: 0 -> %d)", sts.Name, sts.Replicas)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scale up statefulsets: %w", err)
+		}
+		if len(scaled) > 0 {
+			log.Successf("Scaled up %d statefulset(s)", len(scaled))
+		}
+	}
+	return nil
+}
+
+// scaleUpAnnotatedDeployments scales the deployments left scaled down by an interrupted restore back up
+func scaleUpAnnotatedDeployments(k8sClient k8s.Interface, namespace, labelSelector string, log *logger.Logger) error {
+	log.Infof("Scaling up deployments left scaled down (selector: %s)...", labelSelector)
+	scaled, err := k8sClient.ScaleUpAnnotatedDeployments(namespace, labelSelector)
+	for _, dep := range scaled {
+		log.Infof("  - %s (replicas: 0 -> %d)", dep.Name, dep.Replicas)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to scale up deployments: %w", err)
+	}
+
+	if len(scaled) == 0 {
+		log.Infof("No scaled down deployments found")
+		return nil
+	}
+	log.Successf("Scaled up %d deployment(s)", len(scaled))
+	return nil
+}
@@ -0,0 +1,58 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleUpAnnotatedDeployments(t *testing.T) {
+	replicas := int32(0)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "suse-observability-server",
+			Namespace:   testNamespace,
+			Labels:      map[string]string{"observability.suse.com/scalable-during-es-restore": "true"},
+			Annotations: map[string]string{k8s.OriginalReplicasAnnotation: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	fakeClient := fake.NewSimpleClientset(deployment)
+
+	err := scaleUpAnnotatedDeployments(k8s.NewTestClient(fakeClient), testNamespace,
+		"observability.suse.com/scalable-during-es-restore=true", logger.New(logger.LevelError, ""))
+
+	require.NoError(t, err)
+	updated, err := fakeClient.AppsV1().Deployments(testNamespace).Get(context.Background(), "suse-observability-server", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *updated.Spec.Replicas)
+}
+
+func TestScaleUpAnnotatedStatefulSets(t *testing.T) {
+	replicas := int32(0)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "suse-observability-elasticsearch-ingest",
+			Namespace:   testNamespace,
+			Labels:      map[string]string{"role": "ingest"},
+			Annotations: map[string]string{k8s.OriginalReplicasAnnotation: "2"},
+		},
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+	}
+	fakeClient := fake.NewSimpleClientset(statefulSet)
+
+	err := scaleUpAnnotatedStatefulSets(k8s.NewTestClient(fakeClient), testNamespace, []string{"role=coordinating", "role=ingest"},
+		logger.New(logger.LevelError, ""))
+
+	require.NoError(t, err)
+	updated, err := fakeClient.AppsV1().StatefulSets(testNamespace).Get(context.Background(), "suse-observability-elasticsearch-ingest", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *updated.Spec.Replicas)
+}
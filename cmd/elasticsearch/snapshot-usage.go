@@ -0,0 +1,247 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// snapshotStatsBatchSize is the number of snapshots requested per snapshot status call,
+	// which reads shard-level metadata from the repository and gets slow for many snapshots
+	snapshotStatsBatchSize = 20
+	// percent converts a fraction to a percentage
+	percent = 100
+	// day is the unit of growth rates and retention periods
+	day = 24 * time.Hour
+)
+
+// snapshotUsage is the storage used by a single snapshot
+type snapshotUsage struct {
+	Snapshot elasticsearch.Snapshot
+	Stats    elasticsearch.SnapshotStats
+	// Growth is the change of the total size compared to the previous snapshot
+	Growth int64
+}
+
+// usageReport summarizes repository usage and growth over the snapshots in the repository
+type usageReport struct {
+	Snapshots []snapshotUsage
+	// RepositoryBytes is the sum of incremental sizes, i.e. what the snapshots added to the repository
+	RepositoryBytes int64
+	// LatestTotalBytes is the size of the most recent snapshot, i.e. of a full restore
+	LatestTotalBytes int64
+	// Span is the time between the oldest and the most recent snapshot
+	Span time.Duration
+	// IncrementalPerDay is the average amount of data added to the repository per day
+	IncrementalPerDay float64
+	// TotalGrowthPerDay is the average growth of the snapshot size per day
+	TotalGrowthPerDay float64
+}
+
+func snapshotUsageCmd(cliCtx *config.Context) *cobra.Command {
+	var capacity string
+	cmd := &cobra.Command{
+		Use:   "snapshot-usage",
+		Short: "Show snapshot sizes, repository usage and growth",
+		Long: `Show the incremental and total size of every completed snapshot, the storage used by the repository
+and how fast it grows, to plan the capacity of the object storage (e.g. the MinIO PVC).
+
+The incremental size is what a snapshot added to the repository when it was taken; repository usage is the sum
+of incremental sizes. With --capacity the remaining capacity and the time until it is full are estimated.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runSnapshotUsage(cliCtx, capacity); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&capacity, "capacity", "", "Capacity of the object storage (e.g. 100Gi) to estimate when it is full")
+	return cmd
+}
+
+func runSnapshotUsage(cliCtx *config.Context, capacity string) error {
+	var capacityBytes int64
+	if capacity != "" {
+		quantity, err := resource.ParseQuantity(capacity)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("invalid --capacity '%s': %w", capacity, err))
+		}
+		capacityBytes = quantity.Value()
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repository := env.Config.Elasticsearch.Restore.Repository
+	env.Log.Infof("Fetching snapshots from repository '%s'...", repository)
+	snapshots, err := esClient.ListSnapshots(repository)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	completed := completedSnapshots(snapshots)
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(completed) == 0 {
+		formatter.PrintMessage("No snapshots found")
+		return nil
+	}
+
+	env.Log.Infof("Fetching statistics of %d snapshot(s)...", len(completed))
+	stats, err := fetchSnapshotStats(esClient, repository, completed)
+	if err != nil {
+		return err
+	}
+
+	report := buildUsageReport(completed, stats)
+	if err := formatter.PrintTable(usageTable(report)); err != nil {
+		return err
+	}
+	for _, line := range usageSummary(report, capacityBytes) {
+		formatter.PrintMessage(line)
+	}
+	return nil
+}
+
+// completedSnapshots returns the snapshots that are not in progress, oldest first
+func completedSnapshots(snapshots []elasticsearch.Snapshot) []elasticsearch.Snapshot {
+	completed := make([]elasticsearch.Snapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.State != "IN_PROGRESS" {
+			completed = append(completed, snapshot)
+		}
+	}
+	sort.SliceStable(completed, func(i, j int) bool {
+		return completed[i].StartTimeMillis < completed[j].StartTimeMillis
+	})
+	return completed
+}
+
+// fetchSnapshotStats fetches the statistics of the snapshots in batches, keyed by snapshot name
+func fetchSnapshotStats(esClient *elasticsearch.Client, repository string, snapshots []elasticsearch.Snapshot) (map[string]elasticsearch.SnapshotStats, error) {
+	stats := make(map[string]elasticsearch.SnapshotStats, len(snapshots))
+	for start := 0; start < len(snapshots); start += snapshotStatsBatchSize {
+		end := min(start+snapshotStatsBatchSize, len(snapshots))
+		names := make([]string, 0, end-start)
+		for _, snapshot := range snapshots[start:end] {
+			names = append(names, snapshot.Snapshot)
+		}
+
+		batch, err := esClient.SnapshotStats(repository, names)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get snapshot statistics: %w", err)
+		}
+		for _, s := range batch {
+			stats[s.Snapshot] = s
+		}
+	}
+	return stats, nil
+}
+
+// buildUsageReport computes usage and growth from snapshots ordered oldest first
+func buildUsageReport(snapshots []elasticsearch.Snapshot, stats map[string]elasticsearch.SnapshotStats) usageReport {
+	report := usageReport{Snapshots: make([]snapshotUsage, 0, len(snapshots))}
+
+	var previousTotal int64
+	for i, snapshot := range snapshots {
+		s := stats[snapshot.Snapshot]
+		usage := snapshotUsage{Snapshot: snapshot, Stats: s}
+		if i > 0 {
+			usage.Growth = s.TotalSizeInBytes - previousTotal
+		}
+		previousTotal = s.TotalSizeInBytes
+		report.RepositoryBytes += s.IncrementalSizeInBytes
+		report.Snapshots = append(report.Snapshots, usage)
+	}
+
+	oldest := report.Snapshots[0]
+	latest := report.Snapshots[len(report.Snapshots)-1]
+	report.LatestTotalBytes = latest.Stats.TotalSizeInBytes
+	report.Span = time.UnixMilli(latest.Snapshot.StartTimeMillis).Sub(time.UnixMilli(oldest.Snapshot.StartTimeMillis))
+
+	if days := float64(report.Span) / float64(day); days > 0 {
+		// The oldest snapshot holds the initial full copy, so only later snapshots count as growth
+		report.IncrementalPerDay = float64(report.RepositoryBytes-oldest.Stats.IncrementalSizeInBytes) / days
+		report.TotalGrowthPerDay = float64(latest.Stats.TotalSizeInBytes-oldest.Stats.TotalSizeInBytes) / days
+	}
+	return report
+}
+
+// usageTable converts the per-snapshot usage into a table
+func usageTable(report usageReport) output.Table {
+	table := output.Table{
+		Headers:      []string{"SNAPSHOT", "STATE", "START TIME", "INCREMENTAL", "TOTAL", "GROWTH"},
+		Rows:         make([][]string, 0, len(report.Snapshots)),
+		StateColumns: []string{"STATE"},
+	}
+
+	for i, usage := range report.Snapshots {
+		growth := "-"
+		if i > 0 {
+			growth = formatSignedBytes(usage.Growth)
+		}
+		table.Rows = append(table.Rows, []string{
+			usage.Snapshot.Snapshot,
+			usage.Snapshot.State,
+			usage.Snapshot.StartTime,
+			output.FormatBytes(usage.Stats.IncrementalSizeInBytes),
+			output.FormatBytes(usage.Stats.TotalSizeInBytes),
+			growth,
+		})
+	}
+
+	return table
+}
+
+// usageSummary returns the repository usage and growth trend lines printed below the table
+func usageSummary(report usageReport, capacityBytes int64) []string {
+	lines := []string{
+		"",
+		fmt.Sprintf("Repository usage:      %s across %d snapshot(s)", output.FormatBytes(report.RepositoryBytes), len(report.Snapshots)),
+		fmt.Sprintf("Latest snapshot size:  %s", output.FormatBytes(report.LatestTotalBytes)),
+	}
+
+	if report.Span > 0 {
+		lines = append(lines,
+			fmt.Sprintf("Repository growth:     %s per day (over %s)", output.FormatBytes(int64(report.IncrementalPerDay)), output.FormatAge(report.Span)),
+			fmt.Sprintf("Snapshot size growth:  %s per day", formatSignedBytes(int64(report.TotalGrowthPerDay))),
+		)
+	}
+
+	if capacityBytes > 0 {
+		used := float64(report.RepositoryBytes) / float64(capacityBytes) * percent
+		line := fmt.Sprintf("Capacity:              %s of %s used (%.1f%%)", output.FormatBytes(report.RepositoryBytes), output.FormatBytes(capacityBytes), used)
+		if remaining := capacityBytes - report.RepositoryBytes; remaining > 0 && report.IncrementalPerDay > 0 {
+			days := float64(remaining) / report.IncrementalPerDay
+			line += fmt.Sprintf(", full in about %.0f day(s) at the current growth if no snapshots are deleted", days)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatSignedBytes formats a size difference with an explicit sign
+func formatSignedBytes(delta int64) string {
+	if delta < 0 {
+		return "-" + output.FormatBytes(-delta)
+	}
+	return "+" + output.FormatBytes(delta)
+}
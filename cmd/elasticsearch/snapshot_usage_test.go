@@ -0,0 +1,115 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mib = 1024 * 1024
+
+var usageStart = time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+
+func usageSnapshots() []elasticsearch.Snapshot {
+	return []elasticsearch.Snapshot{
+		{Snapshot: "snap-3", State: "SUCCESS", StartTimeMillis: usageStart.Add(2 * day).UnixMilli()},
+		{Snapshot: "snap-1", State: "SUCCESS", StartTimeMillis: usageStart.UnixMilli()},
+		{Snapshot: "snap-running", State: "IN_PROGRESS", StartTimeMillis: usageStart.Add(3 * day).UnixMilli()},
+		{Snapshot: "snap-2", State: "PARTIAL", StartTimeMillis: usageStart.Add(day).UnixMilli()},
+	}
+}
+
+func usageStats() map[string]elasticsearch.SnapshotStats {
+	return map[string]elasticsearch.SnapshotStats{
+		"snap-1": {Snapshot: "snap-1", IncrementalSizeInBytes: 100 * mib, TotalSizeInBytes: 100 * mib},
+		"snap-2": {Snapshot: "snap-2", IncrementalSizeInBytes: 10 * mib, TotalSizeInBytes: 106 * mib},
+		"snap-3": {Snapshot: "snap-3", IncrementalSizeInBytes: 10 * mib, TotalSizeInBytes: 104 * mib},
+	}
+}
+
+// TestSnapshotUsageCmd_Unit tests the command structure
+func TestSnapshotUsageCmd_Unit(t *testing.T) {
+	cmd := snapshotUsageCmd(config.NewContext())
+
+	assert.Equal(t, "snapshot-usage", cmd.Use)
+	assert.NotEmpty(t, cmd.Long)
+	assert.NotNil(t, cmd.Run)
+	assert.NotNil(t, cmd.Flags().Lookup("capacity"))
+}
+
+func TestRunSnapshotUsage_InvalidCapacity(t *testing.T) {
+	err := runSnapshotUsage(config.NewContext(), "lots")
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
+
+func TestCompletedSnapshots(t *testing.T) {
+	completed := completedSnapshots(usageSnapshots())
+
+	names := make([]string, 0, len(completed))
+	for _, snapshot := range completed {
+		names = append(names, snapshot.Snapshot)
+	}
+	assert.Equal(t, []string{"snap-1", "snap-2", "snap-3"}, names)
+}
+
+func TestBuildUsageReport(t *testing.T) {
+	report := buildUsageReport(completedSnapshots(usageSnapshots()), usageStats())
+
+	require.Len(t, report.Snapshots, 3)
+	assert.Equal(t, int64(120*mib), report.RepositoryBytes)
+	assert.Equal(t, int64(104*mib), report.LatestTotalBytes)
+	assert.Equal(t, 2*day, report.Span)
+	// 20 MiB added by the two snapshots after the initial full copy, over two days
+	assert.InDelta(t, float64(10*mib), report.IncrementalPerDay, 1)
+	assert.InDelta(t, float64(2*mib), report.TotalGrowthPerDay, 1)
+
+	assert.Equal(t, int64(0), report.Snapshots[0].Growth)
+	assert.Equal(t, int64(6*mib), report.Snapshots[1].Growth)
+	assert.Equal(t, int64(-2*mib), report.Snapshots[2].Growth)
+}
+
+func TestBuildUsageReport_SingleSnapshot(t *testing.T) {
+	snapshots := []elasticsearch.Snapshot{{Snapshot: "snap-1", State: "SUCCESS", StartTimeMillis: usageStart.UnixMilli()}}
+
+	report := buildUsageReport(snapshots, usageStats())
+
+	assert.Equal(t, int64(100*mib), report.RepositoryBytes)
+	assert.Zero(t, report.Span)
+	assert.Zero(t, report.IncrementalPerDay)
+}
+
+func TestUsageTable(t *testing.T) {
+	table := usageTable(buildUsageReport(completedSnapshots(usageSnapshots()), usageStats()))
+
+	require.Len(t, table.Rows, 3)
+	assert.Equal(t, []string{"STATE"}, table.StateColumns)
+	assert.Equal(t, []string{"snap-1", "SUCCESS", "", "100.0 MiB", "100.0 MiB", "-"}, table.Rows[0])
+	assert.Equal(t, "+6.0 MiB", table.Rows[1][5])
+	assert.Equal(t, "-2.0 MiB", table.Rows[2][5])
+}
+
+func TestUsageSummary(t *testing.T) {
+	report := buildUsageReport(completedSnapshots(usageSnapshots()), usageStats())
+
+	t.Run("without capacity", func(t *testing.T) {
+		lines := usageSummary(report, 0)
+
+		assert.Contains(t, lines, "Repository usage:      120.0 MiB across 3 snapshot(s)")
+		assert.Contains(t, lines, "Latest snapshot size:  104.0 MiB")
+		assert.Contains(t, lines, "Repository growth:     10.0 MiB per day (over 2d0h)")
+		assert.Contains(t, lines, "Snapshot size growth:  +2.0 MiB per day")
+	})
+
+	t.Run("with capacity", func(t *testing.T) {
+		lines := usageSummary(report, 220*mib)
+
+		assert.Contains(t, lines, "Capacity:              120.0 MiB of 220.0 MiB used (54.5%), full in about 10 day(s) at the current growth if no snapshots are deleted")
+	})
+}
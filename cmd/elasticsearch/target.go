@@ -0,0 +1,99 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+)
+
+// Target returns Elasticsearch as a backup target: backups are snapshots taken by the SLM policy and restores
+// run like restore-snapshot --yes
+func Target() target.BackupTarget {
+	return esTarget{}
+}
+
+type esTarget struct{}
+
+func (esTarget) Name() string {
+	return "elasticsearch"
+}
+
+// Configure configures and verifies the snapshot repository and the SLM policies, like configure
+func (esTarget) Configure(env *target.Env) error {
+	return configure(env, true)
+}
+
+// Backup runs the SLM policy now and returns the name of the snapshot it takes
+func (esTarget) Backup(env *target.Env) (string, error) {
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	name, err := esClient.ExecuteSLMPolicy(env.Config.Elasticsearch.SLM.Name)
+	if err != nil {
+		return "", exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to execute SLM policy: %w", err))
+	}
+	return name, nil
+}
+
+// ListBackups returns the snapshots in the restore repository
+func (esTarget) ListBackups(env *target.Env) ([]target.Backup, error) {
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	snapshots, err := esClient.ListSnapshots(env.Config.Elasticsearch.Restore.Repository)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list snapshots: %w", err))
+	}
+	backups := make([]target.Backup, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		backups = append(backups, target.Backup{Name: snapshot.Snapshot, State: snapshot.State, StartTime: time.UnixMilli(snapshot.StartTimeMillis)})
+	}
+	return backups, nil
+}
+
+// Restore restores the snapshot like restore-snapshot --yes, which connects on its own since it can restore
+// into another installation
+func (esTarget) Restore(env *target.Env, name string) error {
+	return Restore(env.CLI, RestoreRequest{SnapshotName: name})
+}
+
+// Verify restores a sample of the snapshot next to the live indices and compares them, like verify-restore
+func (esTarget) Verify(env *target.Env, name string) error {
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, checks, err := verifySnapshot(env, esClient, &verifyRestoreOptions{SnapshotName: name, Sample: defaultVerifySampleIndices, Documents: defaultVerifySampleDocuments})
+	if err != nil {
+		return err
+	}
+	return checksError(checks)
+}
+
+// connectElasticsearch sets up the port-forward to Elasticsearch of env and creates a client for it. The returned
+// cleanup function closes the port-forward.
+func connectElasticsearch(env *target.Env) (*elasticsearch.Client, func(), error) {
+	pf, err := portForwardElasticsearch(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { close(pf.StopChan) }
+
+	esClient, err := newESClient(env.K8s, env.CLI, env.Config, pf.LocalPort, env.Log)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return esClient, cleanup, nil
+}
@@ -0,0 +1,17 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget(t *testing.T) {
+	registry := target.NewRegistry(Target())
+
+	found, err := registry.Get("elasticsearch")
+	require.NoError(t, err)
+	assert.Equal(t, "elasticsearch", found.Name())
+}
@@ -0,0 +1,177 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// backupTaskActions are the task actions of the operations the tasks command manages: taking, restoring and
+// deleting snapshots, and delete-by-query
+var backupTaskActions = []string{"cluster:admin/snapshot/*", "indices:data/write/delete/byquery"}
+
+// taskCanceller lists and cancels tasks
+type taskCanceller interface {
+	ListTasks(actions []string) ([]elasticsearch.Task, error)
+	CancelTask(taskID string) error
+}
+
+func tasksCmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Inspect and cancel snapshot, restore and delete-by-query tasks",
+		Long: `Inspect and cancel the snapshot, restore and delete-by-query tasks running in Elasticsearch, whether started
+by this CLI, by SLM or by anything else.`,
+	}
+	cmd.AddCommand(listTasksCmd(cliCtx))
+	cmd.AddCommand(cancelTaskCmd(cliCtx))
+	return cmd
+}
+
+func listTasksCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the running snapshot, restore and delete-by-query tasks",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runListTasks(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func cancelTaskCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <task-id>",
+		Short: "Cancel a running snapshot, restore or delete-by-query task",
+		Long: `Cancel a task listed by 'tasks list', given its <node>:<id> task ID. Only snapshot, restore and
+delete-by-query tasks can be cancelled with this command. Cancelling a snapshot leaves no snapshot behind;
+cancelling a restore leaves the indices restored so far incomplete.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if err := runCancelTask(cliCtx, args[0]); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runListTasks(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	env.Log.Infof("Fetching snapshot, restore and delete-by-query tasks...")
+	tasks, err := esClient.ListTasks(backupTaskActions)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list tasks: %w", err))
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(tasks) == 0 {
+		formatter.PrintMessage("No snapshot, restore or delete-by-query tasks running")
+		return nil
+	}
+	return formatter.PrintTable(tasksTable(tasks))
+}
+
+func runCancelTask(cliCtx *config.Context, taskID string) (err error) {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = target.RecordAudit(env.K8s, cliCtx, audit.Entry{Operation: "cancel-task"}, err, env.Log)
+	}()
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return cancelTask(esClient, taskID, prompt.New(cliCtx.Config.AssumeYes), env.Log)
+}
+
+// cancelTask cancels taskID after confirmation. Tasks other than the running snapshot, restore and delete-by-query
+// tasks are refused.
+func cancelTask(client taskCanceller, taskID string, prompter *prompt.Prompter, log *logger.Logger) error {
+	tasks, err := client.ListTasks(backupTaskActions)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list tasks: %w", err))
+	}
+
+	var task *elasticsearch.Task
+	for i := range tasks {
+		if tasks[i].ID == taskID {
+			task = &tasks[i]
+			break
+		}
+	}
+	if task == nil {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("task %s is not a running snapshot, restore or delete-by-query task (see 'tasks list')", taskID))
+	}
+	if !task.Cancellable {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("task %s (%s) cannot be cancelled", taskID, task.Action))
+	}
+
+	if err := prompter.Confirm(fmt.Sprintf("Cancel task %s: %s (%s)?", taskID, task.Action, task.Description)); err != nil {
+		return fmt.Errorf("cancel aborted: %w", err)
+	}
+	if err := client.CancelTask(taskID); err != nil {
+		return fmt.Errorf("failed to cancel task %s: %w", taskID, err)
+	}
+	log.Successf("Cancelled task %s", taskID)
+	return nil
+}
+
+// tasksTable lists the tasks, longest running first
+func tasksTable(tasks []elasticsearch.Task) output.Table {
+	sorted := append([]elasticsearch.Task{}, tasks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RunningTimeNanos > sorted[j].RunningTimeNanos
+	})
+
+	table := output.Table{
+		Headers: []string{"TASK ID", "ACTION", "DESCRIPTION", "STARTED", "RUNNING", "CANCELLABLE"},
+		Rows:    make([][]string, 0, len(sorted)),
+	}
+	for _, task := range sorted {
+		cancellable := strconv.FormatBool(task.Cancellable)
+		if task.Cancelled {
+			cancellable = "cancelling"
+		}
+		table.Rows = append(table.Rows, []string{
+			task.ID,
+			task.Action,
+			task.Description,
+			time.UnixMilli(task.StartTimeMillis).Local().Format(time.RFC3339),
+			time.Duration(task.RunningTimeNanos).Round(time.Second).String(),
+			cancellable,
+		})
+	}
+	return table
+}
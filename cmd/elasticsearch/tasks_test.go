@@ -0,0 +1,114 @@
+package elasticsearch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTaskCanceller struct {
+	tasks     []elasticsearch.Task
+	cancelled []string
+	cancelErr error
+}
+
+func (m *mockTaskCanceller) ListTasks(actions []string) ([]elasticsearch.Task, error) {
+	if strings.Join(actions, ",") != strings.Join(backupTaskActions, ",") {
+		return nil, errors.New("unexpected actions")
+	}
+	return m.tasks, nil
+}
+
+func (m *mockTaskCanceller) CancelTask(taskID string) error {
+	if m.cancelErr != nil {
+		return m.cancelErr
+	}
+	m.cancelled = append(m.cancelled, taskID)
+	return nil
+}
+
+func testTasks() []elasticsearch.Task {
+	return []elasticsearch.Task{
+		{ID: "node-1:7", Action: "cluster:admin/snapshot/create", Description: "snapshot [sts-backup:snap-1]", RunningTimeNanos: int64(90 * time.Second), Cancellable: true},
+		{ID: "node-2:12", Action: "indices:data/write/delete/byquery", Description: "delete-by-query [sts_topology]", RunningTimeNanos: int64(10*time.Minute + 300*time.Millisecond), Cancellable: true, Cancelled: true},
+		{ID: "node-1:9", Action: "cluster:admin/snapshot/status", RunningTimeNanos: int64(time.Second)},
+	}
+}
+
+func TestTasksCmd_Unit(t *testing.T) {
+	cmd := tasksCmd(config.NewContext())
+
+	assert.Equal(t, "tasks", cmd.Use)
+	list, _, err := cmd.Find([]string{"list"})
+	require.NoError(t, err)
+	assert.Equal(t, "list", list.Use)
+	cancel, _, err := cmd.Find([]string{"cancel"})
+	require.NoError(t, err)
+	assert.Error(t, cancel.Args(cancel, nil), "a task ID is required")
+}
+
+func TestCancelTask(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+
+	tests := []struct {
+		name          string
+		taskID        string
+		cancelErr     error
+		expectedCode  int
+		expectedError string
+	}{
+		{name: "cancels a snapshot task", taskID: "node-1:7"},
+		{name: "unknown task", taskID: "node-3:1", expectedCode: exitcode.Usage, expectedError: "is not a running snapshot, restore or delete-by-query task"},
+		{name: "not cancellable", taskID: "node-1:9", expectedCode: exitcode.Usage, expectedError: "cannot be cancelled"},
+		{name: "cancel fails", taskID: "node-1:7", cancelErr: errors.New("boom"), expectedCode: 1, expectedError: "failed to cancel task node-1:7: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockTaskCanceller{tasks: testTasks(), cancelErr: tt.cancelErr}
+
+			err := cancelTask(client, tt.taskID, prompt.NewWithIO(strings.NewReader(""), &strings.Builder{}, true, false), log)
+
+			if tt.expectedError == "" {
+				require.NoError(t, err)
+				assert.Equal(t, []string{tt.taskID}, client.cancelled)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Empty(t, client.cancelled)
+		})
+	}
+}
+
+func TestCancelTask_Declined(t *testing.T) {
+	client := &mockTaskCanceller{tasks: testTasks()}
+
+	err := cancelTask(client, "node-1:7", prompt.NewWithIO(strings.NewReader("no\n"), &strings.Builder{}, false, true), logger.New(logger.LevelError, ""))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cancel aborted")
+	assert.Empty(t, client.cancelled)
+}
+
+func TestTasksTable(t *testing.T) {
+	table := tasksTable(testTasks())
+
+	require.Len(t, table.Rows, 3)
+	assert.Equal(t, []string{"node-2:12", "node-1:7", "node-1:9"}, []string{table.Rows[0][0], table.Rows[1][0], table.Rows[2][0]})
+	assert.Equal(t, "10m0s", table.Rows[0][4])
+	assert.Equal(t, "cancelling", table.Rows[0][5])
+	assert.Equal(t, "1m30s", table.Rows[1][4])
+	assert.Equal(t, "true", table.Rows[1][5])
+	assert.Equal(t, "false", table.Rows[2][5])
+}
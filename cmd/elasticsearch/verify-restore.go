@@ -0,0 +1,352 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/report"
+)
+
+const (
+	defaultVerifySampleIndices   = 3
+	defaultVerifySampleDocuments = 100
+	// verifyCopyPrefix is prepended to the names of the restored copies. It must not match the indices pattern of
+	// the SLM policy, so a copy left behind by an interrupted run never ends up in a snapshot.
+	verifyCopyPrefix = "verify-restore-"
+	// checksumLength is the number of hex digits of a checksum shown in the details of a check
+	checksumLength = 12
+)
+
+// restoreVerifier is the part of the Elasticsearch client verify-restore uses
+type restoreVerifier interface {
+	RestoreSnapshotWithOptions(repository, snapshotName string, opts elasticsearch.RestoreOptions) error
+	DeleteIndex(index string) error
+	IndexExists(index string) (bool, error)
+	CountDocuments(index string) (int64, error)
+	SampleDocuments(index string, size int) (map[string]json.RawMessage, error)
+	GetDocuments(index string, ids []string) (map[string]json.RawMessage, error)
+}
+
+type verifyRestoreOptions struct {
+	SnapshotName string
+	Sample       int
+	Documents    int
+}
+
+func verifyRestoreCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &verifyRestoreOptions{}
+	cmd := &cobra.Command{
+		Use:   "verify-restore",
+		Short: "Prove that a snapshot restores by restoring a sample of its indices under temporary names",
+		Long: `Restore a random sample of indices from a snapshot (by default the latest successful snapshot in the restore
+repository) next to the live indices, under temporary names starting with "verify-restore-", and compare each
+copy with its live index: the number of documents and a checksum of the sampled documents. The copies are
+deleted afterwards. Nothing is scaled down and the live indices are only read.
+
+A sampled document that differs from the live index fails the check (exit code 5); a different number of
+documents, or documents deleted since the snapshot, only warn, since the live indices keep changing.
+
+The snapshot is also checked to hold the indices of every data domain of elasticsearch.restore.domains, e.g. the
+topology and metrics indices and the logs data stream: a domain without indices in the snapshot fails the check,
+which catches an SLM policy whose indices (elasticsearch.slm.indices) leave out part of the data.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runVerifyRestore(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&opts.SnapshotName, "snapshot-name", "s", "", "Snapshot to verify (default: the latest successful snapshot)")
+	cmd.Flags().IntVar(&opts.Sample, "sample", defaultVerifySampleIndices, "Number of indices to restore and compare")
+	cmd.Flags().IntVar(&opts.Documents, "docs", defaultVerifySampleDocuments, "Number of documents per index to compare")
+	_ = cmd.RegisterFlagCompletionFunc("snapshot-name", completeSnapshotNames(cliCtx))
+	return cmd
+}
+
+func runVerifyRestore(cliCtx *config.Context, opts *verifyRestoreOptions) (err error) {
+	if opts.Sample <= 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--sample must be positive, got %d", opts.Sample))
+	}
+	if opts.Documents <= 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--docs must be positive, got %d", opts.Documents))
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	// Notify configured targets about the outcome of the verification, so a scheduled run does not fail silently
+	startedAt := time.Now()
+	snapshotName := opts.SnapshotName
+	defer func() {
+		sendNotification(env.Config, cliCtx, "verify-restore", startedAt, err, map[string]string{"snapshot": snapshotName}, env.Log)
+	}()
+
+	esClient, cleanup, err := connectElasticsearch(env)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	verified, checks, err := verifySnapshot(env, esClient, opts)
+	if verified != "" {
+		snapshotName = verified
+	}
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintChecks("verify-restore", checks); err != nil {
+		return err
+	}
+
+	if err := checksError(checks); err != nil {
+		return err
+	}
+	env.Log.Successf("Snapshot '%s' verified", snapshotName)
+	return nil
+}
+
+// verifySnapshot checks the data domains of the snapshot to verify, restores a sample of it and compares that with
+// the live indices. It returns the name of the verified snapshot, a check per data domain and one per restored index.
+func verifySnapshot(env *target.Env, esClient *elasticsearch.Client, opts *verifyRestoreOptions) (string, []output.Check, error) {
+	restoreCfg := env.Config.Elasticsearch.Restore
+	snapshot, err := snapshotToVerify(esClient, restoreCfg.Repository, opts.SnapshotName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	families := indexFamilyChecks(snapshot, restoreCfg.Domains)
+	sample := sampleIndices(snapshot.Indices, restoreCfg.IndexPrefix, opts.Sample, rand.New(rand.NewSource(time.Now().UnixNano()))) //nolint:gosec // sampling, not security sensitive
+	if len(sample) == 0 {
+		return "", nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("snapshot '%s' has no indices starting with '%s' to verify", snapshot.Snapshot, restoreCfg.IndexPrefix))
+	}
+
+	env.Log.Infof("Verifying snapshot '%s' by restoring %d index(es): %s", snapshot.Snapshot, len(sample), strings.Join(sample, ", "))
+	checks, err := verifyRestore(esClient, restoreCfg.Repository, snapshot.Snapshot, sample, opts.Documents, time.Now(), env.Log)
+	return snapshot.Snapshot, append(families, checks...), err
+}
+
+// indexFamilyChecks checks that the snapshot holds indices of every data domain, so an SLM policy whose indices leave
+// out e.g. the logs data stream is caught before a restore needs them. The domains are checked in name order.
+func indexFamilyChecks(snapshot *elasticsearch.Snapshot, domains map[string]string) []output.Check {
+	checks := make([]output.Check, 0, len(domains))
+	for _, domain := range slices.Sorted(maps.Keys(domains)) {
+		pattern := domains[domain]
+		matching := 0
+		for _, index := range snapshot.Indices {
+			if matchesIndexPattern(index, pattern) {
+				matching++
+			}
+		}
+
+		check := output.Check{Name: "domain " + domain, Status: report.StatusPass, Details: fmt.Sprintf("%d index(es) matching '%s'", matching, pattern)}
+		if matching == 0 {
+			check.Status = report.StatusFail
+			check.Details = fmt.Sprintf("no index matching '%s' in the snapshot: check elasticsearch.slm.indices", pattern)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checksError returns an exitcode.ValidationFailed error when any of the checks failed
+func checksError(checks []output.Check) error {
+	if failed := failedChecks(checks); failed > 0 {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%d of %d check(s) of the snapshot failed", failed, len(checks)))
+	}
+	return nil
+}
+
+// snapshotToVerify returns the snapshot name, or the latest successful snapshot in the repository when name is empty
+func snapshotToVerify(client elasticsearch.Interface, repository, name string) (*elasticsearch.Snapshot, error) {
+	if name != "" {
+		snapshot, err := client.GetSnapshot(repository, name)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to get snapshot: %w", err))
+		}
+		return snapshot, nil
+	}
+
+	snapshots, err := client.ListSnapshots(repository)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list snapshots: %w", err))
+	}
+	latest := elasticsearch.LatestSuccessful(snapshots)
+	if latest == nil {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("repository '%s' has no successful snapshot", repository))
+	}
+	return latest, nil
+}
+
+// sampleIndices picks up to n random indices starting with indexPrefix. Datastream backing indices are left out,
+// since they cannot be restored under another name without their datastream.
+func sampleIndices(indices []string, indexPrefix string, n int, rnd *rand.Rand) []string {
+	var candidates []string
+	for _, index := range indices {
+		if strings.HasPrefix(index, indexPrefix) {
+			candidates = append(candidates, index)
+		}
+	}
+	rnd.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	sample := candidates[:min(n, len(candidates))]
+	slices.Sort(sample)
+	return sample
+}
+
+// verifyRestore restores the sample of indices from the snapshot under temporary names, compares every copy with
+// its live index and deletes the copies again. A failed restore is reported as a failed check for every index.
+func verifyRestore(client restoreVerifier, repository, snapshotName string, sample []string, documents int, now time.Time, log *logger.Logger) ([]output.Check, error) {
+	copyPrefix := verifyCopyPrefix + now.UTC().Format(safetySnapshotTimeFormat) + "-"
+
+	// The copies are deleted even when the restore fails part way
+	defer func() {
+		for _, index := range sample {
+			copyName := copyPrefix + index
+			if exists, err := client.IndexExists(copyName); err != nil || !exists {
+				continue
+			}
+			if err := client.DeleteIndex(copyName); err != nil {
+				log.Warningf("Failed to delete restored copy '%s', delete it manually: %v", copyName, err)
+			}
+		}
+	}()
+
+	log.Infof("Restoring sample as '%s*'...", copyPrefix)
+	err := client.RestoreSnapshotWithOptions(repository, snapshotName, elasticsearch.RestoreOptions{
+		Indices:           strings.Join(sample, ","),
+		WaitForCompletion: true,
+		RenamePattern:     "(.+)",
+		RenameReplacement: copyPrefix + "$1",
+		ExcludeAliases:    true,
+		// The copies are short-lived, so replicas would only load the cluster
+		IndexSettings: map[string]interface{}{"index.number_of_replicas": 0},
+	})
+	if err != nil {
+		checks := make([]output.Check, 0, len(sample))
+		for _, index := range sample {
+			checks = append(checks, output.Check{Name: index, Status: report.StatusFail, Details: "restore failed: " + redact.Error(err)})
+		}
+		return checks, nil
+	}
+
+	checks := make([]output.Check, 0, len(sample))
+	for _, index := range sample {
+		log.Debugf("Comparing '%s' with its restored copy", index)
+		check, err := compareRestoredIndex(client, index, copyPrefix+index, documents)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// compareRestoredIndex compares the restored copy of index with the live index: the number of documents and up
+// to documents sampled documents, which are looked up in the live index by ID
+func compareRestoredIndex(client restoreVerifier, index, copyName string, documents int) (output.Check, error) {
+	check := output.Check{Name: index, Status: report.StatusPass}
+
+	restoredCount, err := client.CountDocuments(copyName)
+	if err != nil {
+		return check, fmt.Errorf("failed to count documents of '%s': %w", copyName, err)
+	}
+	restored, err := client.SampleDocuments(copyName, documents)
+	if err != nil {
+		return check, fmt.Errorf("failed to sample documents of '%s': %w", copyName, err)
+	}
+
+	exists, err := client.IndexExists(index)
+	if err != nil {
+		return check, fmt.Errorf("failed to check index '%s': %w", index, err)
+	}
+	if !exists {
+		check.Status = report.StatusWarn
+		check.Details = fmt.Sprintf("restored %d document(s), but the live index no longer exists", restoredCount)
+		return check, nil
+	}
+
+	liveCount, err := client.CountDocuments(index)
+	if err != nil {
+		return check, fmt.Errorf("failed to count documents of '%s': %w", index, err)
+	}
+	ids := make([]string, 0, len(restored))
+	for id := range restored {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	live, err := client.GetDocuments(index, ids)
+	if err != nil {
+		return check, fmt.Errorf("failed to get documents of '%s': %w", index, err)
+	}
+
+	var differ, missing int
+	for _, id := range ids {
+		source, ok := live[id]
+		switch {
+		case !ok:
+			missing++
+		case !bytes.Equal(source, restored[id]):
+			differ++
+		}
+	}
+
+	details := []string{fmt.Sprintf("%d document(s) restored, %d live", restoredCount, liveCount)}
+	switch {
+	case differ > 0:
+		check.Status = report.StatusFail
+		details = append(details, fmt.Sprintf("%d of %d sampled document(s) differ", differ, len(ids)))
+	case missing > 0:
+		check.Status = report.StatusWarn
+		details = append(details, fmt.Sprintf("%d of %d sampled document(s) deleted since the snapshot", missing, len(ids)))
+	default:
+		details = append(details, fmt.Sprintf("%d sampled document(s) match (checksum %s)", len(ids), documentsChecksum(ids, restored)))
+	}
+	if check.Status == report.StatusPass && restoredCount != liveCount {
+		check.Status = report.StatusWarn
+	}
+	check.Details = strings.Join(details, ", ")
+	return check, nil
+}
+
+// documentsChecksum returns a checksum over the IDs and sources of the documents, in the order of ids
+func documentsChecksum(ids []string, documents map[string]json.RawMessage) string {
+	hash := sha256.New()
+	for _, id := range ids {
+		hash.Write([]byte(id))
+		hash.Write([]byte{0})
+		hash.Write(documents[id])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:checksumLength]
+}
+
+// failedChecks returns the number of failed checks
+func failedChecks(checks []output.Check) int {
+	failed := 0
+	for _, check := range checks {
+		if check.Status == report.StatusFail {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,185 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRestoreVerifier keeps indices as documents keyed by ID; a restore copies them under the replacement name
+type mockRestoreVerifier struct {
+	snapshot   map[string]map[string]json.RawMessage
+	indices    map[string]map[string]json.RawMessage
+	restoreErr error
+	restored   elasticsearch.RestoreOptions
+	deleted    []string
+}
+
+func (m *mockRestoreVerifier) RestoreSnapshotWithOptions(_, _ string, opts elasticsearch.RestoreOptions) error {
+	m.restored = opts
+	if m.restoreErr != nil {
+		return m.restoreErr
+	}
+	for _, index := range strings.Split(opts.Indices, ",") {
+		m.indices[strings.Replace(opts.RenameReplacement, "$1", index, 1)] = m.snapshot[index]
+	}
+	return nil
+}
+
+func (m *mockRestoreVerifier) DeleteIndex(index string) error {
+	m.deleted = append(m.deleted, index)
+	delete(m.indices, index)
+	return nil
+}
+
+func (m *mockRestoreVerifier) IndexExists(index string) (bool, error) {
+	_, ok := m.indices[index]
+	return ok, nil
+}
+
+func (m *mockRestoreVerifier) CountDocuments(index string) (int64, error) {
+	return int64(len(m.indices[index])), nil
+}
+
+func (m *mockRestoreVerifier) SampleDocuments(index string, size int) (map[string]json.RawMessage, error) {
+	sample := map[string]json.RawMessage{}
+	for id, source := range m.indices[index] {
+		if len(sample) == size {
+			break
+		}
+		sample[id] = source
+	}
+	return sample, nil
+}
+
+func (m *mockRestoreVerifier) GetDocuments(index string, ids []string) (map[string]json.RawMessage, error) {
+	documents := map[string]json.RawMessage{}
+	for _, id := range ids {
+		if source, ok := m.indices[index][id]; ok {
+			documents[id] = source
+		}
+	}
+	return documents, nil
+}
+
+func TestVerifyRestoreCmd_Unit(t *testing.T) {
+	cmd := verifyRestoreCmd(config.NewContext())
+
+	assert.Equal(t, "verify-restore", cmd.Use)
+	assert.Equal(t, "3", cmd.Flags().Lookup("sample").DefValue)
+	assert.Equal(t, "100", cmd.Flags().Lookup("docs").DefValue)
+	assert.NotNil(t, cmd.Flags().Lookup("snapshot-name"))
+}
+
+func TestSampleIndices(t *testing.T) {
+	indices := []string{"sts_topology", ".ds-sts_k8s_logs-2025.03.01-000001", "sts_metrics", ".kibana", "sts_events"}
+
+	sample := sampleIndices(indices, "sts", 2, rand.New(rand.NewSource(1))) //nolint:gosec // deterministic test
+	assert.Len(t, sample, 2)
+	assert.IsIncreasing(t, sample)
+	for _, index := range sample {
+		assert.Contains(t, []string{"sts_topology", "sts_metrics", "sts_events"}, index)
+	}
+
+	all := sampleIndices(indices, "sts", 10, rand.New(rand.NewSource(1))) //nolint:gosec // deterministic test
+	assert.Equal(t, []string{"sts_events", "sts_metrics", "sts_topology"}, all)
+}
+
+func TestVerifyRestore(t *testing.T) {
+	docs := func(sources ...string) map[string]json.RawMessage {
+		documents := map[string]json.RawMessage{}
+		for i, source := range sources {
+			documents[string(rune('a'+i))] = json.RawMessage(source)
+		}
+		return documents
+	}
+	client := &mockRestoreVerifier{
+		snapshot: map[string]map[string]json.RawMessage{
+			"sts_same":    docs(`{"v":1}`, `{"v":2}`),
+			"sts_grown":   docs(`{"v":1}`),
+			"sts_changed": docs(`{"v":1}`, `{"v":2}`),
+			"sts_gone":    docs(`{"v":1}`),
+		},
+		indices: map[string]map[string]json.RawMessage{
+			"sts_same":    docs(`{"v":1}`, `{"v":2}`),
+			"sts_grown":   docs(`{"v":1}`, `{"v":2}`),
+			"sts_changed": docs(`{"v":1}`, `{"v":3}`),
+		},
+	}
+	now := time.Date(2025, 3, 5, 6, 0, 0, 0, time.UTC)
+	sample := []string{"sts_changed", "sts_gone", "sts_grown", "sts_same"}
+
+	checks, err := verifyRestore(client, "sts-backup", "sts-backup-1", sample, 10, now, logger.New(logger.LevelError, ""))
+	require.NoError(t, err)
+
+	assert.Equal(t, "verify-restore-20250305-060000-$1", client.restored.RenameReplacement)
+	assert.True(t, client.restored.ExcludeAliases)
+	assert.True(t, client.restored.WaitForCompletion)
+
+	require.Len(t, checks, 4)
+	assert.Equal(t, report.StatusFail, checks[0].Status)
+	assert.Contains(t, checks[0].Details, "1 of 2 sampled document(s) differ")
+	assert.Equal(t, report.StatusWarn, checks[1].Status)
+	assert.Contains(t, checks[1].Details, "live index no longer exists")
+	assert.Equal(t, report.StatusWarn, checks[2].Status)
+	assert.Contains(t, checks[2].Details, "1 document(s) restored, 2 live")
+	assert.Equal(t, report.StatusPass, checks[3].Status)
+	assert.Contains(t, checks[3].Details, "2 sampled document(s) match (checksum ")
+	assert.Equal(t, 1, failedChecks(checks))
+
+	// All copies are deleted, the live indices are untouched
+	assert.Len(t, client.deleted, 4)
+	for index := range client.indices {
+		assert.False(t, strings.HasPrefix(index, verifyCopyPrefix), index)
+	}
+	assert.Len(t, client.indices, 3)
+}
+
+func TestVerifyRestore_RestoreFails(t *testing.T) {
+	client := &mockRestoreVerifier{indices: map[string]map[string]json.RawMessage{}, restoreErr: errors.New("snapshot missing")}
+
+	checks, err := verifyRestore(client, "sts-backup", "sts-backup-1", []string{"sts_a", "sts_b"}, 10, time.Now(), logger.New(logger.LevelError, ""))
+	require.NoError(t, err)
+
+	require.Len(t, checks, 2)
+	for _, check := range checks {
+		assert.Equal(t, report.StatusFail, check.Status)
+		assert.Contains(t, check.Details, "snapshot missing")
+	}
+	assert.Empty(t, client.deleted)
+}
+
+func TestIndexFamilyChecks(t *testing.T) {
+	snapshot := &elasticsearch.Snapshot{Indices: []string{"sts_topology", "sts_multi_metrics-000001", "sts_multi_metrics-000002"}}
+	domains := map[string]string{"topology": "sts_topology*", "metrics": "sts_multi_metrics*", "logs": ".ds-sts_k8s_logs*"}
+
+	checks := indexFamilyChecks(snapshot, domains)
+
+	assert.Equal(t, []output.Check{
+		{Name: "domain logs", Status: report.StatusFail, Details: "no index matching '.ds-sts_k8s_logs*' in the snapshot: check elasticsearch.slm.indices"},
+		{Name: "domain metrics", Status: report.StatusPass, Details: "2 index(es) matching 'sts_multi_metrics*'"},
+		{Name: "domain topology", Status: report.StatusPass, Details: "1 index(es) matching 'sts_topology*'"},
+	}, checks)
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(checksError(checks)))
+}
+
+func TestDocumentsChecksum(t *testing.T) {
+	a := map[string]json.RawMessage{"1": json.RawMessage(`{"v":1}`), "2": json.RawMessage(`{"v":2}`)}
+	b := map[string]json.RawMessage{"1": json.RawMessage(`{"v":1}`), "2": json.RawMessage(`{"v":3}`)}
+
+	assert.Len(t, documentsChecksum([]string{"1", "2"}, a), checksumLength)
+	assert.Equal(t, documentsChecksum([]string{"1", "2"}, a), documentsChecksum([]string{"1", "2"}, a))
+	assert.NotEqual(t, documentsChecksum([]string{"1", "2"}, a), documentsChecksum([]string{"1", "2"}, b))
+}
@@ -0,0 +1,276 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// managedByLabel marks the generated resources
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "sts-backup"
+	componentLabel = "app.kubernetes.io/component"
+
+	// ContainerName is the name of the sts-backup container of the generated jobs
+	ContainerName = "sts-backup"
+
+	// jobHistoryLimit is the number of finished jobs kept for inspection
+	jobHistoryLimit = 3
+)
+
+// task is a CLI invocation that can be scheduled as a CronJob
+type task struct {
+	Description string
+	Schedule    string
+	Args        []string
+}
+
+// tasks lists the schedulable tasks by name
+var tasks = map[string]task{
+	"verify-backups": {
+		Description: "Verify Elasticsearch, the snapshot repository, S3 credentials and SLM health",
+		Schedule:    "0 6 * * *",
+		Args:        []string{"doctor"},
+	},
+	"enforce-retention": {
+		Description: "Delete snapshots outside the configured retention",
+		Schedule:    "30 3 * * *",
+		Args:        []string{"elasticsearch", "enforce-retention", "--yes"},
+	},
+}
+
+// cronJobOptions holds the flags of the cronjob command
+type cronJobOptions struct {
+	Task           string
+	Name           string
+	Schedule       string
+	Image          string
+	ServiceAccount string
+}
+
+func cronJobCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &cronJobOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "cronjob",
+		Short: "Generate a CronJob running a backup task on a schedule",
+		Long: fmt.Sprintf(`Generate ready-to-apply Kubernetes manifests (ServiceAccount, Role, RoleBinding and CronJob)
+that run sts-backup in-cluster on a schedule.
+
+Available tasks:
+%s
+
+Example:
+  sts-backup generate cronjob --namespace suse-observability --task verify-backups --image <image> | kubectl apply -f -`, taskHelp()),
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runCronJob(cliCtx, opts, os.Stdout); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Task, "task", "", fmt.Sprintf("Task to schedule (%s)", strings.Join(taskNames(), ", ")))
+	cmd.Flags().StringVar(&opts.Name, "name", "", "Name of the CronJob (default: sts-backup-<task>)")
+	cmd.Flags().StringVar(&opts.Schedule, "schedule", "", "Cron schedule (default depends on the task)")
+	cmd.Flags().StringVar(&opts.Image, "image", "", "Container image containing the sts-backup binary (required)")
+	cmd.Flags().StringVar(&opts.ServiceAccount, "service-account", "sts-backup", "ServiceAccount the CronJob runs as (created with the required RBAC)")
+	_ = cmd.MarkFlagRequired("task")
+	_ = cmd.MarkFlagRequired("image")
+	_ = cmd.RegisterFlagCompletionFunc("task", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return taskNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func runCronJob(cliCtx *config.Context, opts *cronJobOptions, w io.Writer) error {
+	t, ok := tasks[opts.Task]
+	if !ok {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("unknown task '%s' (expected one of: %s)", opts.Task, strings.Join(taskNames(), ", ")))
+	}
+	if opts.Name == "" {
+		opts.Name = "sts-backup-" + opts.Task
+	}
+	if opts.Schedule == "" {
+		opts.Schedule = t.Schedule
+	}
+
+	return writeManifests(w, buildManifests(cliCtx.Config, opts, t))
+}
+
+// buildManifests creates the ServiceAccount, RBAC and CronJob for a task
+func buildManifests(cfg *config.CLIConfig, opts *cronJobOptions, t task) []runtime.Object {
+	labels := map[string]string{
+		managedByLabel: managedByValue,
+		componentLabel: opts.Task,
+	}
+
+	historyLimit := int32(jobHistoryLimit)
+	cronJob := &batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: cfg.Namespace, Labels: labels},
+		Spec: batchv1.CronJobSpec{
+			Schedule:                   opts.Schedule,
+			ConcurrencyPolicy:          batchv1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &historyLimit,
+			FailedJobsHistoryLimit:     &historyLimit,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       jobSpec(cfg, labels, opts.Image, opts.ServiceAccount, t.Args),
+			},
+		},
+	}
+
+	return append(rbacManifests(cfg, opts.ServiceAccount, rbacRules()), cronJob)
+}
+
+// rbacManifests creates a ServiceAccount with a Role granting rules and the RoleBinding between them, all named
+// serviceAccount
+func rbacManifests(cfg *config.CLIConfig, serviceAccount string, rules []rbacv1.PolicyRule) []runtime.Object {
+	// The ServiceAccount and RBAC are shared by all tasks, so only the workloads carry the task label
+	meta := metav1.ObjectMeta{Name: serviceAccount, Namespace: cfg.Namespace, Labels: map[string]string{managedByLabel: managedByValue}}
+
+	return []runtime.Object{
+		&corev1.ServiceAccount{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+			ObjectMeta: meta,
+		},
+		&rbacv1.Role{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "Role"},
+			ObjectMeta: meta,
+			Rules:      rules,
+		},
+		&rbacv1.RoleBinding{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "RoleBinding"},
+			ObjectMeta: meta,
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "Role",
+				Name:     serviceAccount,
+			},
+			Subjects: []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount,
+				Namespace: cfg.Namespace,
+			}},
+		},
+	}
+}
+
+// jobSpec returns the spec of a Job running sts-backup once with args against the configured installation
+func jobSpec(cfg *config.CLIConfig, labels map[string]string, image, serviceAccount string, args []string) batchv1.JobSpec {
+	args = append(append([]string{}, args...),
+		"--namespace", cfg.Namespace,
+		"--configmap", cfg.ConfigMapName,
+		"--secret", cfg.SecretName,
+	)
+	backoffLimit := int32(0)
+	allowPrivilegeEscalation := false
+
+	return batchv1.JobSpec{
+		BackoffLimit: &backoffLimit,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec: corev1.PodSpec{
+				ServiceAccountName: serviceAccount,
+				RestartPolicy:      corev1.RestartPolicyNever,
+				Containers: []corev1.Container{{
+					Name:    ContainerName,
+					Image:   image,
+					Command: []string{"sts-backup"},
+					Args:    args,
+					SecurityContext: &corev1.SecurityContext{
+						AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+					},
+				}},
+			},
+		},
+	}
+}
+
+// rbacRules returns the namespaced permissions the CLI needs to run its tasks
+func rbacRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			// Backup configuration, credentials and the audit log
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"get", "list", "create", "update"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+			Verbs:     []string{"get"},
+		},
+		{
+			// Port-forwarding to Elasticsearch and object storage
+			APIGroups: []string{""},
+			Resources: []string{"services", "pods"},
+			Verbs:     []string{"get", "list"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods/portforward"},
+			Verbs:     []string{"create"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"events"},
+			Verbs:     []string{"create"},
+		},
+	}
+}
+
+// writeManifests writes objects as a multi-document YAML stream
+func writeManifests(w io.Writer, objects []runtime.Object) error {
+	for i, obj := range objects {
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to encode manifest: %w", err)
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(w, "---"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskNames returns the sorted names of the schedulable tasks
+func taskNames() []string {
+	names := make([]string, 0, len(tasks))
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// taskHelp describes the tasks for the command help
+func taskHelp() string {
+	var b strings.Builder
+	for _, name := range taskNames() {
+		t := tasks[name]
+		fmt.Fprintf(&b, "  %-18s %s (default schedule: %s)\n", name, t.Description, t.Schedule)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
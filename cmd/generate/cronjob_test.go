@@ -0,0 +1,114 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func testContext() *config.Context {
+	cliCtx := config.NewContext()
+	cliCtx.Config.Namespace = "suse-observability"
+	cliCtx.Config.ConfigMapName = "backup-config"
+	cliCtx.Config.SecretName = "backup-secret"
+	return cliCtx
+}
+
+// TestCronJobCmd_Unit tests the command structure
+func TestCronJobCmd_Unit(t *testing.T) {
+	cmd := cronJobCmd(config.NewContext())
+
+	assert.Equal(t, "cronjob", cmd.Use)
+	assert.Contains(t, cmd.Long, "verify-backups")
+	assert.Contains(t, cmd.Long, "enforce-retention")
+	for _, flag := range []string{"task", "name", "schedule", "image", "service-account"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), flag)
+	}
+}
+
+func TestRunCronJob(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             cronJobOptions
+		expectedName     string
+		expectedSchedule string
+		expectedArgs     []string
+	}{
+		{
+			name:             "verify backups with defaults",
+			opts:             cronJobOptions{Task: "verify-backups", Image: "registry.example.com/sts-backup:1.0.0", ServiceAccount: "sts-backup"},
+			expectedName:     "sts-backup-verify-backups",
+			expectedSchedule: "0 6 * * *",
+			expectedArgs:     []string{"doctor", "--namespace", "suse-observability", "--configmap", "backup-config", "--secret", "backup-secret"},
+		},
+		{
+			name: "enforce retention with overrides",
+			opts: cronJobOptions{
+				Task:           "enforce-retention",
+				Name:           "retention",
+				Schedule:       "0 1 * * 0",
+				Image:          "registry.example.com/sts-backup:1.0.0",
+				ServiceAccount: "backup-runner",
+			},
+			expectedName:     "retention",
+			expectedSchedule: "0 1 * * 0",
+			expectedArgs:     []string{"elasticsearch", "enforce-retention", "--yes", "--namespace", "suse-observability", "--configmap", "backup-config", "--secret", "backup-secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			opts := tt.opts
+
+			require.NoError(t, runCronJob(testContext(), &opts, buf))
+
+			docs := strings.Split(buf.String(), "\n---\n")
+			require.Len(t, docs, 4)
+			assert.Contains(t, docs[0], "kind: ServiceAccount")
+
+			var role rbacv1.Role
+			require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &role))
+			assert.Equal(t, "Role", role.Kind)
+			assert.Equal(t, tt.opts.ServiceAccount, role.Name)
+			assert.NotEmpty(t, role.Rules)
+
+			var binding rbacv1.RoleBinding
+			require.NoError(t, yaml.Unmarshal([]byte(docs[2]), &binding))
+			assert.Equal(t, tt.opts.ServiceAccount, binding.Subjects[0].Name)
+			assert.Equal(t, "suse-observability", binding.Subjects[0].Namespace)
+
+			var cronJob batchv1.CronJob
+			require.NoError(t, yaml.Unmarshal([]byte(docs[3]), &cronJob))
+			assert.Equal(t, "CronJob", cronJob.Kind)
+			assert.Equal(t, tt.expectedName, cronJob.Name)
+			assert.Equal(t, "suse-observability", cronJob.Namespace)
+			assert.Equal(t, tt.expectedSchedule, cronJob.Spec.Schedule)
+			assert.Equal(t, batchv1.ForbidConcurrent, cronJob.Spec.ConcurrencyPolicy)
+
+			podSpec := cronJob.Spec.JobTemplate.Spec.Template.Spec
+			assert.Equal(t, tt.opts.ServiceAccount, podSpec.ServiceAccountName)
+			require.Len(t, podSpec.Containers, 1)
+			assert.Equal(t, tt.opts.Image, podSpec.Containers[0].Image)
+			assert.Equal(t, tt.expectedArgs, podSpec.Containers[0].Args)
+		})
+	}
+}
+
+func TestRunCronJob_UnknownTask(t *testing.T) {
+	opts := cronJobOptions{Task: "make-coffee", Image: "image"}
+
+	err := runCronJob(testContext(), &opts, &bytes.Buffer{})
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "enforce-retention, verify-backups")
+}
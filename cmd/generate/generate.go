@@ -0,0 +1,18 @@
+package generate
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate Kubernetes manifests for running sts-backup in-cluster",
+	}
+
+	cmd.AddCommand(cronJobCmd(cliCtx))
+	cmd.AddCommand(restoreRBACCmd(cliCtx))
+
+	return cmd
+}
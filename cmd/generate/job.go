@@ -0,0 +1,152 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	batchv1 "k8s.io/api/batch/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultRestoreServiceAccount is the ServiceAccount restores run as in-cluster, see restore-snapshot --as-job
+	DefaultRestoreServiceAccount = "sts-backup-restore"
+
+	// JobSelector selects the Jobs running sts-backup in-cluster: those of --as-job and of the generated CronJobs
+	JobSelector = managedByLabel + "=" + managedByValue
+
+	// jobPodTimeout is the time the pod of a Job has to start, e.g. to pull the image
+	jobPodTimeout = 10 * time.Minute
+	// jobFinishTimeout is the time a Job has to be marked finished after its logs ended
+	jobFinishTimeout = time.Minute
+	// jobPollInterval is the time between checks of the pod and status of a Job
+	jobPollInterval = 2 * time.Second
+
+	// finishedJobTTLSeconds is the time a finished Job and its pod, with the logs, are kept
+	finishedJobTTLSeconds = 7 * 24 * 60 * 60
+)
+
+// JobOptions describe a Job running a single sts-backup command in-cluster
+type JobOptions struct {
+	Name           string
+	Component      string
+	Image          string
+	ServiceAccount string
+	// RunID is the run ID of the CLI invocation that created the Job, to find it again
+	RunID string
+	// Args are the arguments of the command, without the flags selecting the installation, which are added
+	Args []string
+}
+
+// Job returns a Job running sts-backup once in-cluster against the configured installation. The pod is not
+// restarted and the Job is not retried, since the commands it runs are not safe to run twice unattended.
+func Job(cfg *config.CLIConfig, opts JobOptions) *batchv1.Job {
+	labels := map[string]string{
+		managedByLabel: managedByValue,
+		componentLabel: opts.Component,
+		k8s.RunIDLabel: opts.RunID,
+	}
+	ttl := int32(finishedJobTTLSeconds)
+
+	spec := jobSpec(cfg, labels, opts.Image, opts.ServiceAccount, opts.Args)
+	spec.TTLSecondsAfterFinished = &ttl
+	return &batchv1.Job{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{Name: opts.Name, Namespace: cfg.Namespace, Labels: labels},
+		Spec:       spec,
+	}
+}
+
+// FollowJob streams the logs of the pod of a Job created by Job to w until it finishes and returns an error with
+// the exit code of the command when the Job failed. When the log stream breaks off, e.g. because the connection
+// was lost, the Job keeps running in-cluster and the error says so.
+func FollowJob(k8sClient k8s.Interface, namespace, name string, w io.Writer, log *logger.Logger) error {
+	pod, err := k8sClient.WaitForJobPod(namespace, name, jobPodTimeout, jobPollInterval)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+
+	log.Infof("Following the logs of pod %s of job %s", pod, name)
+	if err := k8sClient.StreamPodLogs(namespace, pod, ContainerName, w); err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("%w; job %s keeps running in-cluster, reattach with 'sts-backup jobs attach %s'", err, name, name))
+	}
+
+	result, err := k8sClient.WaitForJob(namespace, name, jobFinishTimeout, jobPollInterval)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	switch result {
+	case k8s.JobSucceeded:
+		log.Successf("Job %s completed", name)
+		return nil
+	case k8s.JobFailed:
+		code, terminated, err := k8sClient.ContainerExitCode(namespace, pod, ContainerName)
+		if err != nil || !terminated || code == exitcode.Success {
+			code = exitcode.Failure
+		}
+		return exitcode.Wrap(code, fmt.Errorf("job %s failed", name))
+	default:
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("the logs of job %s ended but it is still running", name))
+	}
+}
+
+// restoreRBACOptions holds the flags of the restore-rbac command
+type restoreRBACOptions struct {
+	ServiceAccount string
+}
+
+func restoreRBACCmd(cliCtx *config.Context) *cobra.Command {
+	opts := &restoreRBACOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "restore-rbac",
+		Short: "Generate the ServiceAccount and RBAC for restores running in-cluster",
+		Long: `Generate ready-to-apply Kubernetes manifests (ServiceAccount, Role and RoleBinding) for the Jobs started
+by 'elasticsearch restore-snapshot --as-job'. Besides what the scheduled tasks need, a restore scales deployments
+and StatefulSets down and up again and records its checkpoints.
+
+Example:
+  sts-backup generate restore-rbac --namespace suse-observability | kubectl apply -f -`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRestoreRBAC(cliCtx, opts, os.Stdout); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.ServiceAccount, "service-account", DefaultRestoreServiceAccount, "ServiceAccount the restore Jobs run as")
+
+	return cmd
+}
+
+func runRestoreRBAC(cliCtx *config.Context, opts *restoreRBACOptions, w io.Writer) error {
+	return writeManifests(w, rbacManifests(cliCtx.Config, opts.ServiceAccount, restoreRBACRules()))
+}
+
+// restoreRBACRules returns the namespaced permissions a restore needs on top of rbacRules
+func restoreRBACRules() []rbacv1.PolicyRule {
+	return append(rbacRules(),
+		rbacv1.PolicyRule{
+			// Scaling down the workloads writing to Elasticsearch, and the ingest nodes, during the restore
+			APIGroups: []string{"apps"},
+			Resources: []string{"deployments", "statefulsets"},
+			Verbs:     []string{"get", "list", "update"},
+		},
+		rbacv1.PolicyRule{
+			// Maintenance mode and restore checkpoints
+			APIGroups: []string{""},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"delete"},
+		},
+	)
+}
@@ -0,0 +1,105 @@
+package generate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestJob(t *testing.T) {
+	job := Job(testContext().Config, JobOptions{
+		Name:           "sts-backup-restore-20250115t030000-a1b2c3",
+		Component:      "restore",
+		Image:          "registry.example.com/sts-backup:1.0.0",
+		ServiceAccount: DefaultRestoreServiceAccount,
+		RunID:          "20250115T030000-a1b2c3",
+		Args:           []string{"elasticsearch", "restore-snapshot", "--snapshot-name", "sts-backup-1", "--yes"},
+	})
+
+	assert.Equal(t, "Job", job.Kind)
+	assert.Equal(t, "suse-observability", job.Namespace)
+	assert.Equal(t, "20250115T030000-a1b2c3", job.Labels[k8s.RunIDLabel])
+	assert.Equal(t, "restore", job.Labels[componentLabel])
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+	assert.NotNil(t, job.Spec.TTLSecondsAfterFinished)
+
+	podSpec := job.Spec.Template.Spec
+	assert.Equal(t, DefaultRestoreServiceAccount, podSpec.ServiceAccountName)
+	require.Len(t, podSpec.Containers, 1)
+	assert.Equal(t, ContainerName, podSpec.Containers[0].Name)
+	assert.Equal(t, []string{
+		"elasticsearch", "restore-snapshot", "--snapshot-name", "sts-backup-1", "--yes",
+		"--namespace", "suse-observability", "--configmap", "backup-config", "--secret", "backup-secret",
+	}, podSpec.Containers[0].Args)
+}
+
+func TestRunRestoreRBAC(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	require.NoError(t, runRestoreRBAC(testContext(), &restoreRBACOptions{ServiceAccount: DefaultRestoreServiceAccount}, buf))
+
+	docs := strings.Split(buf.String(), "\n---\n")
+	require.Len(t, docs, 3)
+	var role rbacv1.Role
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &role))
+	assert.Equal(t, DefaultRestoreServiceAccount, role.Name)
+	assert.Contains(t, role.Rules, rbacv1.PolicyRule{
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments", "statefulsets"},
+		Verbs:     []string{"get", "list", "update"},
+	})
+}
+
+// finishedJob returns a Job with the given final condition and its pod, whose container exited with exitCode
+func finishedJob(condition batchv1.JobConditionType, exitCode int32) (*batchv1.Job, *corev1.Pod) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts-backup-restore", Namespace: "suse-observability"},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "sts-backup-restore-x7k2p", Namespace: "suse-observability", Labels: map[string]string{"job-name": job.Name}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  ContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+			}},
+		},
+	}
+	return job, pod
+}
+
+func TestFollowJob(t *testing.T) {
+	job, pod := finishedJob(batchv1.JobComplete, 0)
+	client := k8s.NewTestClient(fake.NewSimpleClientset(job, pod))
+	var logs bytes.Buffer
+
+	err := FollowJob(client, "suse-observability", job.Name, &logs, logger.New(logger.LevelError, ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, "fake logs", logs.String())
+}
+
+func TestFollowJob_Failed(t *testing.T) {
+	job, pod := finishedJob(batchv1.JobFailed, exitcode.PartialRestore)
+	pod.Status.Phase = corev1.PodFailed
+	client := k8s.NewTestClient(fake.NewSimpleClientset(job, pod))
+
+	err := FollowJob(client, "suse-observability", job.Name, &bytes.Buffer{}, logger.New(logger.LevelError, ""))
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.PartialRestore, exitcode.Of(err), "the exit code of the command in the job")
+	assert.Contains(t, err.Error(), "job sts-backup-restore failed")
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history",
+		Short: "Show the audit log of destructive operations",
+		Long:  `Show the audit log of destructive operations (restores, index deletions, configuration changes) recorded in the namespace.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runHistory(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runHistory(cliCtx *config.Context) error {
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	// Create Kubernetes client
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	log.Infof("Fetching audit log from ConfigMap '%s'...", cliCtx.Config.AuditConfigMapName)
+
+	store := audit.NewStore(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.AuditConfigMapName)
+	entries, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+
+	if len(entries) == 0 {
+		formatter.PrintMessage("No audit entries found")
+		return nil
+	}
+
+	return formatter.PrintTable(entriesTable(entries))
+}
+
+// entriesTable converts audit entries into a table
+func entriesTable(entries []audit.Entry) output.Table {
+	table := output.Table{
+		Headers:      []string{"TIME", "RUN ID", "USER", "OPERATION", "SNAPSHOT", "INDICES DELETED", "SNAPSHOTS DELETED", "OUTCOME", "ERROR", "SAFETY SNAPSHOT"},
+		Rows:         make([][]string, 0, len(entries)),
+		StateColumns: []string{"OUTCOME"},
+	}
+
+	for _, entry := range entries {
+		row := []string{
+			entry.Timestamp.Local().Format(time.RFC3339),
+			entry.RunID,
+			entry.User,
+			entry.Operation,
+			entry.Snapshot,
+			fmt.Sprintf("%d", len(entry.IndicesDeleted)),
+			fmt.Sprintf("%d", len(entry.SnapshotsDeleted)),
+			entry.Outcome,
+			strings.ReplaceAll(entry.Error, "\n", " "),
+			entry.SafetySnapshot,
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
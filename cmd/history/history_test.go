@@ -0,0 +1,56 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHistoryCmd_Unit tests the command structure
+func TestHistoryCmd_Unit(t *testing.T) {
+	cliCtx := config.NewContext()
+	cmd := Cmd(cliCtx)
+
+	assert.Equal(t, "history", cmd.Use)
+	assert.Equal(t, "Show the audit log of destructive operations", cmd.Short)
+	assert.NotEmpty(t, cmd.Long)
+	assert.NotNil(t, cmd.Run)
+}
+
+func TestEntriesTable(t *testing.T) {
+	entries := []audit.Entry{
+		{
+			RunID:          "run-1",
+			Timestamp:      time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC),
+			User:           "admin",
+			Operation:      "restore",
+			Snapshot:       "snap-1",
+			IndicesDeleted: []string{"sts_topology", "sts_metrics"},
+			SafetySnapshot: "pre-restore-20250101-120000",
+			Outcome:        audit.OutcomeSuccess,
+		},
+		{
+			RunID:     "run-2",
+			Timestamp: time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC),
+			User:      "admin",
+			Operation: "configure",
+			Outcome:   audit.OutcomeFailed,
+			Error:     "line1\nline2",
+		},
+	}
+
+	table := entriesTable(entries)
+
+	require.Len(t, table.Rows, 2)
+	assert.Equal(t, []string{"OUTCOME"}, table.StateColumns)
+	assert.Equal(t, "run-1", table.Rows[0][1])
+	assert.Equal(t, "2", table.Rows[0][5])
+	assert.Equal(t, "0", table.Rows[0][6])
+	assert.Equal(t, audit.OutcomeSuccess, table.Rows[0][7])
+	assert.Equal(t, "line1 line2", table.Rows[1][8])
+	assert.Equal(t, "pre-restore-20250101-120000", table.Rows[0][9])
+}
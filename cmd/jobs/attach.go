@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/generate"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func attachCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "attach <name>",
+		Short: "Stream the logs of a Job running sts-backup in-cluster until it finishes",
+		Long: `Stream the logs of a Job running sts-backup in-cluster, such as a restore started with
+'elasticsearch restore-snapshot --as-job' or a run of a generated CronJob, and report how it finished. The Job is
+selected by its name or by the run ID it was started with.
+
+Use it to reattach to a restore after the connection of the command that started it broke off. The command exits
+with the exit code of the command in the Job; a Job that finished already has its logs printed and its outcome
+reported.
+
+Example:
+  sts-backup jobs attach --namespace suse-observability sts-backup-restore-20250115t030000-a1b2c3`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return jobNames(cliCtx), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(_ *cobra.Command, args []string) {
+			if err := runAttach(cliCtx, args[0]); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runAttach(cliCtx *config.Context, name string) error {
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	// Create Kubernetes client
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	return attach(k8sClient, cliCtx.Config.Namespace, name, log)
+}
+
+// attach follows the Job of sts-backup named name, or started with run ID name, until it finishes
+func attach(k8sClient k8s.Interface, namespace, name string, log *logger.Logger) error {
+	jobs, err := k8sClient.ListJobs(namespace, generate.JobSelector)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	job := findJob(jobs, name)
+	if job == nil {
+		if len(jobs) == 0 {
+			return exitcode.Wrap(exitcode.Usage, fmt.Errorf("no job '%s': there are no sts-backup jobs in namespace '%s'", name, namespace))
+		}
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("no job '%s' in namespace '%s' (sts-backup jobs: %s)", name, namespace, strings.Join(names(jobs), ", ")))
+	}
+
+	log.Infof("Attaching to job %s, started %s", job.Name, job.CreationTimestamp.Local().Format("2006-01-02 15:04:05"))
+	return generate.FollowJob(k8sClient, namespace, job.Name, os.Stdout, log)
+}
+
+// findJob returns the Job named name or started with run ID name, or nil
+func findJob(jobs []batchv1.Job, name string) *batchv1.Job {
+	for i := range jobs {
+		if jobs[i].Name == name || jobs[i].Labels[k8s.RunIDLabel] == name {
+			return &jobs[i]
+		}
+	}
+	return nil
+}
+
+// names returns the sorted names of jobs
+func names(jobs []batchv1.Job) []string {
+	result := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		result = append(result, job.Name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// jobNames lists the names of the sts-backup Jobs for shell completion
+func jobNames(cliCtx *config.Context) []string {
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, false, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		cobra.CompDebugln("failed to complete job names: "+err.Error(), true)
+		return nil
+	}
+	jobs, err := k8sClient.ListJobs(cliCtx.Config.Namespace, generate.JobSelector)
+	if err != nil {
+		cobra.CompDebugln("failed to complete job names: "+err.Error(), true)
+		return nil
+	}
+	return names(jobs)
+}
@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/generate"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "suse-observability"
+
+// restoreJob returns a finished restore Job started by restore-snapshot --as-job, and its pod
+func restoreJob(condition batchv1.JobConditionType, exitCode int32) (*batchv1.Job, *corev1.Pod) {
+	cfg := &config.CLIConfig{Namespace: testNamespace}
+	job := generate.Job(cfg, generate.JobOptions{
+		Name:      "sts-backup-restore-20250115t030000-a1b2c3",
+		Component: "restore",
+		RunID:     "20250115T030000-a1b2c3",
+	})
+	job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: job.Name + "-x7k2p", Namespace: testNamespace, Labels: map[string]string{"job-name": job.Name}},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  generate.ContainerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}},
+			}},
+		},
+	}
+	return job, pod
+}
+
+func TestAttach(t *testing.T) {
+	tests := []struct {
+		name         string
+		condition    batchv1.JobConditionType
+		exitCode     int32
+		attachTo     string
+		expectedCode int
+	}{
+		{name: "by job name", condition: batchv1.JobComplete, attachTo: "sts-backup-restore-20250115t030000-a1b2c3", expectedCode: exitcode.Success},
+		{name: "by run ID", condition: batchv1.JobComplete, attachTo: "20250115T030000-a1b2c3", expectedCode: exitcode.Success},
+		{name: "failed job", condition: batchv1.JobFailed, exitCode: exitcode.PartialRestore, attachTo: "20250115T030000-a1b2c3", expectedCode: exitcode.PartialRestore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, pod := restoreJob(tt.condition, tt.exitCode)
+			client := k8s.NewTestClient(fake.NewSimpleClientset(job, pod))
+
+			err := attach(client, testNamespace, tt.attachTo, logger.New(logger.LevelError, ""))
+
+			assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+		})
+	}
+}
+
+func TestAttach_UnknownJob(t *testing.T) {
+	job, pod := restoreJob(batchv1.JobComplete, 0)
+	unmanaged := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: testNamespace}}
+	client := k8s.NewTestClient(fake.NewSimpleClientset(job, pod, unmanaged))
+
+	err := attach(client, testNamespace, "other", logger.New(logger.LevelError, ""))
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "sts-backup jobs: sts-backup-restore-20250115t030000-a1b2c3")
+
+	err = attach(k8s.NewTestClient(fake.NewSimpleClientset()), testNamespace, "other", logger.New(logger.LevelError, ""))
+	assert.Contains(t, err.Error(), "there are no sts-backup jobs")
+}
@@ -0,0 +1,17 @@
+package jobs
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Follow the Jobs running sts-backup in-cluster",
+	}
+
+	cmd.AddCommand(attachCmd(cliCtx))
+
+	return cmd
+}
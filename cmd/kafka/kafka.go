@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/kafka"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kafka",
+		Short: "Kafka backup and restore operations",
+		Long: `Operations on the Kafka brokers of the installation, located with the kafka section of the configuration. The
+Kafka command line tools are run in a Ready broker pod, like kubectl exec.`,
+	}
+
+	cmd.AddCommand(metadataCmd(cliCtx))
+	return cmd
+}
+
+// podRunner runs the Kafka command line tools in a container of a broker pod
+type podRunner struct {
+	k8sClient k8s.Interface
+	namespace string
+	pod       string
+	container string
+}
+
+// Run implements kafka.Runner
+func (r *podRunner) Run(command []string) (string, error) {
+	return r.k8sClient.ExecPod(r.namespace, r.pod, r.container, command)
+}
+
+// connectKafka returns a client running the Kafka tools in a Ready broker pod
+func connectKafka(env *target.Env) (*kafka.Client, error) {
+	cfg := env.Config.Kafka
+	pod, err := env.K8s.ReadyPod(env.CLI.Config.Namespace, cfg.PodSelector)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to find a Kafka broker: %w", err))
+	}
+	env.Log.Debugf("Running the Kafka tools in pod %s", pod)
+
+	runner := &podRunner{k8sClient: env.K8s, namespace: env.CLI.Config.Namespace, pod: pod, container: cfg.Container}
+	return kafka.NewClient(runner, cfg.BootstrapServer, cfg.ZookeeperConnect), nil
+}
@@ -0,0 +1,287 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/kafka"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// metadataReader reads the metadata of a Kafka cluster
+type metadataReader interface {
+	Metadata() (*kafka.Metadata, error)
+}
+
+// metadataRestorer reads and changes the metadata of a Kafka cluster
+type metadataRestorer interface {
+	metadataReader
+	Apply(plan *kafka.Plan) error
+}
+
+func metadataCmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metadata",
+		Short: "Snapshot and restore the Kafka topics and ACLs",
+		Long: `Snapshot the topic configurations, the ACLs and the cluster identity (the KRaft quorum status, or the brokers
+registered in Zookeeper) to the backup bucket, and recreate the topics and ACLs from a snapshot, so the messaging
+layer can be rebuilt consistently after a full platform disaster recovery.`,
+	}
+
+	cmd.AddCommand(snapshotMetadataCmd(cliCtx))
+	cmd.AddCommand(restoreMetadataCmd(cliCtx))
+	return cmd
+}
+
+func snapshotMetadataCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "snapshot",
+		Short: "Snapshot the Kafka topics and ACLs to the backup bucket",
+		Long: `Snapshot the topics with their partition count, replication factor and the configuration set on them, the
+ACLs and the cluster identity as a JSON artifact under exports/kafka/metadata/ in the backup bucket. Internal topics
+are left out. ACLs are empty when the brokers have no authorizer.
+
+The artifact is encrypted when archives.encryption is configured. Restore it with 'kafka metadata restore'.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runSnapshotMetadata(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func restoreMetadataCmd(cliCtx *config.Context) *cobra.Command {
+	var key string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Recreate the Kafka topics and ACLs from a snapshot",
+		Long: `Recreate the topics and ACLs of a metadata snapshot taken with 'kafka metadata snapshot'. Missing topics are
+created, the configuration of existing topics is set to the values of the snapshot and their partition count is
+raised to that of the snapshot. Missing ACLs are added. Restoring only adds: topics, configuration and ACLs that are
+not in the snapshot are left in place. Differences that cannot be restored, such as a lower partition count or
+another replication factor, are reported as warnings.
+
+The most recent snapshot is restored unless --key is given. The changes are listed and confirmed before they are
+made; --dry-run only lists them.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRestoreMetadata(cliCtx, key, dryRun); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&key, "key", "", "Object key of the snapshot to restore (default: the most recent snapshot)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the changes without making them")
+	return cmd
+}
+
+func runSnapshotMetadata(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	_, err = takeMetadataSnapshot(env)
+	return err
+}
+
+func runRestoreMetadata(cliCtx *config.Context, key string, dryRun bool) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	return restoreMetadataSnapshot(env, key, prompt.New(cliCtx.Config.AssumeYes), dryRun)
+}
+
+// takeMetadataSnapshot snapshots the metadata of the Kafka cluster of env to the backup bucket, returning its key
+func takeMetadataSnapshot(env *target.Env) (string, error) {
+	store, cleanup, err := target.OpenExportStore(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	client, err := connectKafka(env)
+	if err != nil {
+		return "", err
+	}
+	return snapshotMetadata(client, store, env.CLI, env.Log)
+}
+
+// restoreMetadataSnapshot restores the metadata snapshot stored under key, or the most recent one when key is empty,
+// into the Kafka cluster of env. Restores that are not dry runs are recorded in the audit log.
+func restoreMetadataSnapshot(env *target.Env, key string, prompter *prompt.Prompter, dryRun bool) (err error) {
+	if !dryRun {
+		defer func() {
+			err = target.RecordAudit(env.K8s, env.CLI, audit.Entry{Operation: "kafka-metadata-restore"}, err, env.Log)
+		}()
+	}
+
+	store, cleanup, err := target.OpenExportStore(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	snapshot, err := loadMetadataSnapshot(store, key, env.Log)
+	cleanup()
+	if err != nil {
+		return err
+	}
+
+	client, err := connectKafka(env)
+	if err != nil {
+		return err
+	}
+
+	formatter := output.NewFormatter(env.CLI.Config.OutputFormat, env.CLI.RunID, output.WithOutputFile(env.CLI.Config.OutputFile))
+	return restoreMetadata(client, snapshot, formatter, prompter, dryRun, env.Log)
+}
+
+// snapshotMetadata reads the metadata of the cluster and stores it in the bucket, returning its key
+func snapshotMetadata(client metadataReader, store *export.Store, cliCtx *config.Context, log *logger.Logger) (string, error) {
+	log.Infof("Reading the Kafka topics and ACLs...")
+	metadata, err := client.Metadata()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode Kafka metadata: %w", err)
+	}
+	key, err := store.Put(&export.Artifact{
+		Kind:       export.KindKafkaMetadata,
+		ExportedAt: time.Now(),
+		Namespace:  cliCtx.Config.Namespace,
+		RunID:      cliCtx.RunID,
+		Data:       data,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	log.Successf("Snapshotted %d topic(s) and %d ACL(s) of %s cluster '%s' to '%s'",
+		len(metadata.Topics), len(metadata.ACLs), metadata.Cluster.Mode, metadata.Cluster.ID, key)
+	return key, nil
+}
+
+// loadMetadataSnapshot reads the metadata snapshot stored under key, or the most recent snapshot when key is empty
+func loadMetadataSnapshot(store *export.Store, key string, log *logger.Logger) (*kafka.Metadata, error) {
+	var artifact *export.Artifact
+	var err error
+	if key == "" {
+		log.Infof("Fetching most recent Kafka metadata snapshot...")
+		key, artifact, err = store.Latest(export.KindKafkaMetadata)
+	} else {
+		log.Infof("Fetching Kafka metadata snapshot '%s'...", key)
+		artifact, err = store.Get(key)
+	}
+	if errors.Is(err, export.ErrNotFound) {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%w: run 'kafka metadata snapshot' first", err))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if artifact.Kind != export.KindKafkaMetadata {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is an export of %s, not of Kafka metadata", key, artifact.Kind))
+	}
+
+	var metadata kafka.Metadata
+	if err := json.Unmarshal(artifact.Data, &metadata); err != nil {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("failed to decode Kafka metadata of '%s': %w", key, err))
+	}
+	log.Infof("Snapshot '%s' from %s contains %d topic(s) and %d ACL(s)", key, artifact.ExportedAt.Local().Format(time.RFC3339),
+		len(metadata.Topics), len(metadata.ACLs))
+	return &metadata, nil
+}
+
+// restoreMetadata compares the snapshot with the cluster, lists the changes and makes them after confirmation
+func restoreMetadata(client metadataRestorer, snapshot *kafka.Metadata, formatter *output.Formatter, prompter *prompt.Prompter, dryRun bool, log *logger.Logger) error {
+	log.Infof("Reading the current Kafka topics and ACLs...")
+	current, err := client.Metadata()
+	if err != nil {
+		return err
+	}
+	if snapshot.Cluster.ID != current.Cluster.ID {
+		log.Infof("The snapshot is of cluster '%s', restoring into cluster '%s'", snapshot.Cluster.ID, current.Cluster.ID)
+	}
+
+	plan := kafka.NewPlan(snapshot, current)
+	for _, warning := range plan.Warnings {
+		log.Warningf("%s", warning)
+	}
+	if plan.Empty() {
+		log.Successf("The topics and ACLs match the snapshot, nothing to restore")
+		return nil
+	}
+
+	table := planTable(plan)
+	if err := formatter.PrintTable(table); err != nil {
+		return err
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := prompter.Confirm(fmt.Sprintf("Make these %d change(s)?", len(table.Rows))); err != nil {
+		return fmt.Errorf("restore aborted: %w", err)
+	}
+	if err := client.Apply(plan); err != nil {
+		return err
+	}
+
+	log.Successf("Created %d topic(s), changed %d topic(s) and added %d ACL(s)", len(plan.CreateTopics), len(plan.AlterTopics), len(plan.AddACLs))
+	return nil
+}
+
+// planTable lists the changes of a plan, one per row
+func planTable(plan *kafka.Plan) output.Table {
+	table := output.Table{Headers: []string{"ACTION", "RESOURCE", "DETAILS"}}
+	for _, topic := range plan.CreateTopics {
+		details := fmt.Sprintf("partitions=%d replication-factor=%d", topic.Partitions, topic.ReplicationFactor)
+		if len(topic.Configs) > 0 {
+			details += " " + formatConfigs(topic.Configs)
+		}
+		table.Rows = append(table.Rows, []string{"create", "topic " + topic.Name, details})
+	}
+	for _, change := range plan.AlterTopics {
+		var details []string
+		if change.Partitions > 0 {
+			details = append(details, "partitions="+strconv.Itoa(change.Partitions))
+		}
+		if len(change.Configs) > 0 {
+			details = append(details, formatConfigs(change.Configs))
+		}
+		table.Rows = append(table.Rows, []string{"alter", "topic " + change.Name, strings.Join(details, " ")})
+	}
+	for _, acl := range plan.AddACLs {
+		table.Rows = append(table.Rows, []string{"add", "acl", acl.String()})
+	}
+	return table
+}
+
+// formatConfigs formats configuration as space-separated key=value pairs in key order
+func formatConfigs(configs map[string]string) string {
+	parts := make([]string, 0, len(configs))
+	for _, key := range slices.Sorted(maps.Keys(configs)) {
+		parts = append(parts, key+"="+configs[key])
+	}
+	return strings.Join(parts, " ")
+}
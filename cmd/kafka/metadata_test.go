@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/archive"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/kafka"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryObjects is an in-memory export.ObjectStore
+type memoryObjects map[string][]byte
+
+func (m memoryObjects) PutObject(_, key string, body []byte) error {
+	m[key] = body
+	return nil
+}
+
+func (m memoryObjects) GetObject(_, key string) ([]byte, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, &s3.Error{StatusCode: 404, Code: "NoSuchKey"}
+	}
+	return data, nil
+}
+
+func (m memoryObjects) ListObjects(_, prefix string) ([]s3.Object, error) {
+	var objects []s3.Object
+	for key := range m {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, s3.Object{Key: key})
+		}
+	}
+	return objects, nil
+}
+
+// fakeCluster is a Kafka cluster with fixed metadata that records the plan applied to it
+type fakeCluster struct {
+	metadata *kafka.Metadata
+	applied  *kafka.Plan
+}
+
+func (f *fakeCluster) Metadata() (*kafka.Metadata, error) {
+	return f.metadata, nil
+}
+
+func (f *fakeCluster) Apply(plan *kafka.Plan) error {
+	f.applied = plan
+	return nil
+}
+
+func testMetadata() *kafka.Metadata {
+	return &kafka.Metadata{
+		Cluster: kafka.Cluster{ID: "cluster-a", Mode: kafka.ModeKRaft},
+		Topics:  []kafka.Topic{{Name: "sts_events", Partitions: 3, ReplicationFactor: 1, Configs: map[string]string{"retention.ms": "1000"}}},
+		ACLs:    []kafka.ACL{{ResourceType: "TOPIC", ResourceName: "sts_", PatternType: "PREFIXED", Principal: "User:writer", Host: "*", Operation: "WRITE", Permission: "ALLOW"}},
+	}
+}
+
+func TestSnapshotAndLoadMetadata(t *testing.T) {
+	store := export.New(memoryObjects{}, "backups", archive.NewSealer(nil))
+	cliCtx := &config.Context{Config: &config.CLIConfig{Namespace: "test-ns"}, RunID: "run-1"}
+	log := logger.New(logger.LevelError, "")
+
+	key, err := snapshotMetadata(&fakeCluster{metadata: testMetadata()}, store, cliCtx, log)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "exports/kafka/metadata/"))
+
+	loaded, err := loadMetadataSnapshot(store, "", log)
+	require.NoError(t, err)
+	assert.Equal(t, testMetadata(), loaded)
+}
+
+func TestLoadMetadataSnapshot_Errors(t *testing.T) {
+	objects := memoryObjects{}
+	store := export.New(objects, "backups", archive.NewSealer(nil))
+	log := logger.New(logger.LevelError, "")
+
+	_, err := loadMetadataSnapshot(store, "", log)
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+
+	key, err := store.Put(&export.Artifact{Kind: export.KindPipelines, Data: []byte(`{}`)})
+	require.NoError(t, err)
+	_, err = loadMetadataSnapshot(store, key, log)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
+
+func TestRestoreMetadata(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	empty := &kafka.Metadata{Cluster: kafka.Cluster{ID: "cluster-b", Mode: kafka.ModeKRaft}}
+
+	t.Run("dry run", func(t *testing.T) {
+		cluster := &fakeCluster{metadata: empty}
+		outputFile := filepath.Join(t.TempDir(), "plan.txt")
+		formatter := output.NewFormatter("table", "", output.WithOutputFile(outputFile))
+
+		require.NoError(t, restoreMetadata(cluster, testMetadata(), formatter, prompt.NewWithIO(nil, &bytes.Buffer{}, false, false), true, log))
+
+		assert.Nil(t, cluster.applied)
+		data, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "topic sts_events")
+		assert.Contains(t, string(data), "partitions=3 replication-factor=1 retention.ms=1000")
+		assert.Contains(t, string(data), "ALLOW User:writer WRITE on prefixed TOPIC:sts_ from *")
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		cluster := &fakeCluster{metadata: empty}
+		formatter := output.NewFormatter("table", "", output.WithOutputFile(filepath.Join(t.TempDir(), "plan.txt")))
+
+		require.NoError(t, restoreMetadata(cluster, testMetadata(), formatter, prompt.NewWithIO(nil, &bytes.Buffer{}, true, false), false, log))
+
+		require.NotNil(t, cluster.applied)
+		assert.Len(t, cluster.applied.CreateTopics, 1)
+		assert.Len(t, cluster.applied.AddACLs, 1)
+	})
+
+	t.Run("nothing to restore", func(t *testing.T) {
+		cluster := &fakeCluster{metadata: testMetadata()}
+
+		require.NoError(t, restoreMetadata(cluster, testMetadata(), nil, nil, false, log))
+		assert.Nil(t, cluster.applied)
+	})
+}
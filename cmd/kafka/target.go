@@ -0,0 +1,103 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/kafka"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+)
+
+// Target returns Kafka as a backup target: backups are metadata snapshots and restores run like
+// kafka metadata restore --yes
+func Target() target.BackupTarget {
+	return kafkaTarget{}
+}
+
+type kafkaTarget struct{}
+
+func (kafkaTarget) Name() string {
+	return "kafka"
+}
+
+// Configure checks that the metadata of the brokers can be read; metadata snapshots need no further setup
+func (kafkaTarget) Configure(env *target.Env) error {
+	client, err := connectKafka(env)
+	if err != nil {
+		return err
+	}
+	metadata, err := client.Metadata()
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to read Kafka metadata: %w", err))
+	}
+	env.Log.Successf("Kafka %s cluster '%s' has %d topic(s) and %d ACL(s)", metadata.Cluster.Mode, metadata.Cluster.ID,
+		len(metadata.Topics), len(metadata.ACLs))
+	return nil
+}
+
+// Backup snapshots the topics and ACLs and returns the key of the snapshot
+func (kafkaTarget) Backup(env *target.Env) (string, error) {
+	return takeMetadataSnapshot(env)
+}
+
+// ListBackups returns the metadata snapshots in the backup bucket
+func (kafkaTarget) ListBackups(env *target.Env) ([]target.Backup, error) {
+	store, cleanup, err := target.OpenExportStore(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	keys, err := store.Keys(export.KindKafkaMetadata)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	backups := make([]target.Backup, 0, len(keys))
+	for _, key := range keys {
+		backup := target.Backup{Name: key, State: "SUCCESS"}
+		if _, exportedAt, ok := export.ParseKey(key); ok {
+			backup.StartTime = exportedAt
+		}
+		backups = append(backups, backup)
+	}
+	return backups, nil
+}
+
+// Restore restores the metadata snapshot like kafka metadata restore --yes
+func (kafkaTarget) Restore(env *target.Env, name string) error {
+	return restoreMetadataSnapshot(env, name, prompt.New(true), false)
+}
+
+// Verify reads the snapshot and compares it with the cluster; differences that a restore cannot make, such as a
+// lower partition count, fail the check
+func (kafkaTarget) Verify(env *target.Env, name string) error {
+	store, cleanup, err := target.OpenExportStore(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	snapshot, err := loadMetadataSnapshot(store, name, env.Log)
+	cleanup()
+	if err != nil {
+		return err
+	}
+
+	client, err := connectKafka(env)
+	if err != nil {
+		return err
+	}
+	current, err := client.Metadata()
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to read Kafka metadata: %w", err))
+	}
+
+	plan := kafka.NewPlan(snapshot, current)
+	if len(plan.Warnings) > 0 {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("snapshot '%s' cannot be fully restored: %s", name, strings.Join(plan.Warnings, "; ")))
+	}
+	env.Log.Successf("Snapshot '%s' restores %d topic change(s) and %d ACL(s)", name,
+		len(plan.CreateTopics)+len(plan.AlterTopics), len(plan.AddACLs))
+	return nil
+}
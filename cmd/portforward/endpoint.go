@@ -0,0 +1,63 @@
+package portforward
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ServiceEndpoint is a Kubernetes service referenced by an in-cluster endpoint such as
+// "suse-observability-minio:9000" or "minio.backup.svc.cluster.local:9000"
+type ServiceEndpoint struct {
+	Name      string
+	Namespace string
+	Port      int
+}
+
+// ParseServiceEndpoint resolves a host:port endpoint to a Kubernetes service.
+// It returns false for endpoints that are not in-cluster service names (e.g. s3.amazonaws.com),
+// which can be reached directly without a port-forward.
+func ParseServiceEndpoint(endpoint, defaultNamespace string) (*ServiceEndpoint, bool) {
+	if i := strings.Index(endpoint, "://"); i >= 0 {
+		endpoint = endpoint[i+3:]
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	host, portStr, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return nil, false
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, false
+	}
+
+	labels := strings.Split(host, ".")
+	switch {
+	case len(labels) == 1:
+		return &ServiceEndpoint{Name: host, Namespace: defaultNamespace, Port: port}, true
+	case len(labels) == 2,
+		len(labels) == 3 && labels[2] == "svc",
+		len(labels) == 5 && strings.Join(labels[2:], ".") == "svc.cluster.local":
+		return &ServiceEndpoint{Name: labels[0], Namespace: labels[1], Port: port}, true
+	default:
+		return nil, false
+	}
+}
+
+// FreeLocalPort returns a local TCP port that is currently unused
+func FreeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free local port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Address returns the namespace-qualified address of the service (e.g. minio.backup.svc:9000),
+// which resolves from any namespace of the cluster
+func (s *ServiceEndpoint) Address() string {
+	return fmt.Sprintf("%s.%s.svc:%d", s.Name, s.Namespace, s.Port)
+}
@@ -0,0 +1,69 @@
+package portforward
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServiceEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		expected *ServiceEndpoint
+	}{
+		{
+			name:     "service name",
+			endpoint: "suse-observability-minio:9000",
+			expected: &ServiceEndpoint{Name: "suse-observability-minio", Namespace: "default-ns", Port: 9000},
+		},
+		{
+			name:     "service with scheme",
+			endpoint: "http://minio:9000/",
+			expected: &ServiceEndpoint{Name: "minio", Namespace: "default-ns", Port: 9000},
+		},
+		{
+			name:     "service and namespace",
+			endpoint: "minio.backup:9000",
+			expected: &ServiceEndpoint{Name: "minio", Namespace: "backup", Port: 9000},
+		},
+		{
+			name:     "svc suffix",
+			endpoint: "minio.backup.svc:9000",
+			expected: &ServiceEndpoint{Name: "minio", Namespace: "backup", Port: 9000},
+		},
+		{
+			name:     "fully qualified",
+			endpoint: "minio.backup.svc.cluster.local:9000",
+			expected: &ServiceEndpoint{Name: "minio", Namespace: "backup", Port: 9000},
+		},
+		{name: "external host", endpoint: "s3.eu-west-1.amazonaws.com:443"},
+		{name: "missing port", endpoint: "minio"},
+		{name: "invalid port", endpoint: "minio:http"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, ok := ParseServiceEndpoint(tt.endpoint, "default-ns")
+			if tt.expected == nil {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, svc)
+		})
+	}
+}
+
+func TestServiceEndpoint_Address(t *testing.T) {
+	svc := &ServiceEndpoint{Name: "minio", Namespace: "backup", Port: 9000}
+
+	assert.Equal(t, "minio.backup.svc:9000", svc.Address())
+}
+
+func TestFreeLocalPort(t *testing.T) {
+	port, err := FreeLocalPort()
+	require.NoError(t, err)
+	assert.Positive(t, port)
+}
@@ -0,0 +1,60 @@
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// DefaultKeepAliveInterval is the time between keepalive requests through a port-forward. It is well below the
+// idle timeouts of the load balancers commonly in front of the Kubernetes API server (60 seconds and up) and
+// of the kubelet streaming connections (4 hours by default).
+const DefaultKeepAliveInterval = 30 * time.Second
+
+// pingTimeout is the time a keepalive request may take
+const pingTimeout = 10 * time.Second
+
+// KeepAlive sends a request through the port-forward every interval until StopChan is closed, so the tunnel does
+// not time out while a long request, such as a restore waiting for completion, sends no data over it. A zero or
+// negative interval disables it. Failed requests are only logged; the request that needs the tunnel reports it.
+func (c *Conn) KeepAlive(interval time.Duration, log *logger.Logger) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.StopChan:
+				return
+			case <-ticker.C:
+				if err := c.Ping(); err != nil {
+					log.Debugf("Port-forward keepalive failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Ping sends a request through the port-forward. Any response, also an error status, shows the tunnel works;
+// without a response the error matches k8s.ErrPortForwardFailed.
+func (c *Conn) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("http://localhost:%d/", c.LocalPort), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create keepalive request: %w", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: no response through localhost:%d: %w", k8s.ErrPortForwardFailed, c.LocalPort, err)
+	}
+	_ = res.Body.Close()
+	return nil
+}
@@ -0,0 +1,72 @@
+package portforward
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// testConn returns a Conn for the local port of server
+func testConn(t *testing.T, server *httptest.Server) *Conn {
+	t.Helper()
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Conn{StopChan: make(chan struct{}), LocalPort: port}
+}
+
+func TestConn_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	conn := testConn(t, server)
+
+	if err := conn.Ping(); err != nil {
+		t.Errorf("expected an error status to count as a response, got %v", err)
+	}
+
+	server.Close()
+	err := conn.Ping()
+	if !errors.Is(err, k8s.ErrPortForwardFailed) {
+		t.Errorf("expected ErrPortForwardFailed once the tunnel is gone, got %v", err)
+	}
+}
+
+func TestConn_KeepAlive(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+	conn := testConn(t, server)
+
+	conn.KeepAlive(10*time.Millisecond, logger.New(logger.LevelError, ""))
+	deadline := time.Now().Add(5 * time.Second)
+	for requests.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if requests.Load() < 2 {
+		t.Fatalf("expected keepalive requests, got %d", requests.Load())
+	}
+
+	close(conn.StopChan)
+	time.Sleep(50 * time.Millisecond)
+	stopped := requests.Load()
+	time.Sleep(50 * time.Millisecond)
+	if requests.Load() != stopped {
+		t.Error("expected no keepalive requests after StopChan is closed")
+	}
+}
@@ -0,0 +1,124 @@
+package portforward
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// Manager keeps several port-forwards open at the same time, e.g. to Elasticsearch, MinIO and VictoriaMetrics for
+// a command working on the whole platform. Every forward is registered under a name and can be stopped on its own;
+// Close stops the ones still open. A Manager is safe for concurrent use.
+type Manager struct {
+	setup func(namespace, serviceName string, localPort, remotePort int) (*Conn, error)
+	log   *logger.Logger
+
+	mu       sync.Mutex
+	forwards map[string]*Conn
+}
+
+// NewManager returns a Manager establishing port-forwards with k8sClient
+func NewManager(k8sClient *k8s.Client, log *logger.Logger) *Manager {
+	return newManager(func(namespace, serviceName string, localPort, remotePort int) (*Conn, error) {
+		return SetupPortForward(k8sClient, namespace, serviceName, localPort, remotePort, log)
+	}, log)
+}
+
+func newManager(setup func(namespace, serviceName string, localPort, remotePort int) (*Conn, error), log *logger.Logger) *Manager {
+	return &Manager{setup: setup, log: log, forwards: map[string]*Conn{}}
+}
+
+// Forward establishes a port-forward to a service and registers it under name. A localPort of 0 picks a free
+// local port. A name can only be registered once until its forward is stopped.
+func (m *Manager) Forward(name, namespace, serviceName string, localPort, remotePort int) (*Conn, error) {
+	m.mu.Lock()
+	_, exists := m.forwards[name]
+	if !exists {
+		// Reserve the name while the forward is set up, without holding the lock
+		m.forwards[name] = nil
+	}
+	m.mu.Unlock()
+	if exists {
+		return nil, fmt.Errorf("port-forward %s is already open", name)
+	}
+
+	conn, err := m.connect(namespace, serviceName, localPort, remotePort)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		delete(m.forwards, name)
+		return nil, err
+	}
+	m.forwards[name] = conn
+	return conn, nil
+}
+
+func (m *Manager) connect(namespace, serviceName string, localPort, remotePort int) (*Conn, error) {
+	if localPort == 0 {
+		port, err := FreeLocalPort()
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
+		}
+		localPort = port
+	}
+	return m.setup(namespace, serviceName, localPort, remotePort)
+}
+
+// ForwardEndpoint is ServiceEndpointAddress for a managed forward: it returns an address for reaching endpoint,
+// port-forwarding it under name when it is an in-cluster service and this process runs outside the cluster
+func (m *Manager) ForwardEndpoint(name, endpoint, namespace string) (string, error) {
+	svc, ok := ParseServiceEndpoint(endpoint, namespace)
+	if !ok || k8s.InCluster() {
+		return endpoint, nil
+	}
+	conn, err := m.Forward(name, svc.Namespace, svc.Name, 0, svc.Port)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("localhost:%d", conn.LocalPort), nil
+}
+
+// Get returns the open port-forward registered under name
+func (m *Manager) Get(name string) (*Conn, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	conn := m.forwards[name]
+	return conn, conn != nil
+}
+
+// Names returns the names of the open port-forwards, sorted
+func (m *Manager) Names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.forwards))
+	for name, conn := range m.forwards {
+		if conn != nil {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Stop closes the port-forward registered under name, if it is open
+func (m *Manager) Stop(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if conn := m.forwards[name]; conn != nil {
+		close(conn.StopChan)
+		delete(m.forwards, name)
+		m.log.Debugf("Port-forward %s stopped", name)
+	}
+}
+
+// Close stops all open port-forwards
+func (m *Manager) Close() {
+	for _, name := range m.Names() {
+		m.Stop(name)
+	}
+}
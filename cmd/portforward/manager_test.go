@@ -0,0 +1,89 @@
+package portforward
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// fakeSetup records the port-forwards a Manager sets up
+type fakeSetup struct {
+	mu       sync.Mutex
+	services []string
+	fail     string
+}
+
+func (f *fakeSetup) setup(namespace, serviceName string, localPort, remotePort int) (*Conn, error) {
+	if serviceName == f.fail {
+		return nil, errors.New("service not found")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.services = append(f.services, fmt.Sprintf("%s/%s:%d", namespace, serviceName, remotePort))
+	return &Conn{StopChan: make(chan struct{}), LocalPort: localPort}, nil
+}
+
+func TestManager(t *testing.T) {
+	setup := &fakeSetup{fail: "victoria-metrics"}
+	manager := newManager(setup.setup, logger.New(logger.LevelError, ""))
+
+	var wg sync.WaitGroup
+	conns := map[string]*Conn{}
+	var mu sync.Mutex
+	for name, service := range map[string]string{"elasticsearch": "es-master", "minio": "minio"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := manager.Forward(name, "sts", service, 0, 9000)
+			if err != nil {
+				t.Errorf("forward %s: %v", name, err)
+				return
+			}
+			mu.Lock()
+			conns[name] = conn
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if got := manager.Names(); !slices.Equal(got, []string{"elasticsearch", "minio"}) {
+		t.Fatalf("expected both forwards to be open, got %v", got)
+	}
+	if conns["elasticsearch"].LocalPort == 0 {
+		t.Error("expected a free local port to be picked")
+	}
+	if _, err := manager.Forward("minio", "sts", "minio", 0, 9000); err == nil {
+		t.Error("expected a second forward under the same name to fail")
+	}
+	if _, err := manager.Forward("metrics", "sts", "victoria-metrics", 0, 8428); err == nil {
+		t.Error("expected the failing forward to return its error")
+	}
+	if _, ok := manager.Get("metrics"); ok {
+		t.Error("expected a failed forward not to be registered")
+	}
+
+	manager.Stop("minio")
+	select {
+	case <-conns["minio"].StopChan:
+	default:
+		t.Error("expected the stopped forward to be closed")
+	}
+	select {
+	case <-conns["elasticsearch"].StopChan:
+		t.Error("expected stopping one forward to leave the others open")
+	default:
+	}
+	manager.Stop("minio")
+
+	manager.Close()
+	if _, ok := manager.Get("elasticsearch"); ok {
+		t.Error("expected Close to stop all forwards")
+	}
+	if len(manager.Names()) != 0 {
+		t.Errorf("expected no open forwards, got %v", manager.Names())
+	}
+}
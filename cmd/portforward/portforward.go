@@ -1,10 +1,14 @@
 package portforward
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
 	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
 	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Conn contains the channels needed to manage a port-forward connection
@@ -29,10 +33,33 @@ func SetupPortForward(
 
 	stopChan, readyChan, err := k8sClient.PortForwardService(namespace, serviceName, localPort, remotePort)
 	if err != nil {
-		return nil, fmt.Errorf("failed to setup port-forward: %w", err)
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
 	}
+	return waitForPortForward(stopChan, readyChan, localPort, log), nil
+}
+
+// SetupPodPortForward establishes a port-forward to a specific pod, e.g. one chosen with --pod instead of
+// the pod selected from a service, and waits for it to be ready. The pod has to be running.
+func SetupPodPortForward(k8sClient *k8s.Client, namespace, podName string, localPort, remotePort int, log *logger.Logger) (*Conn, error) {
+	log.Infof("Setting up port-forward to pod %s:%d in namespace %s...", podName, remotePort, namespace)
+
+	pod, err := k8sClient.Clientset().CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("%w: failed to get pod: %w", k8s.ErrPortForwardFailed, err))
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("%w: pod %s is %s, not Running", k8s.ErrPortForwardFailed, podName, pod.Status.Phase))
+	}
+
+	stopChan, readyChan, err := k8sClient.PortForwardPod(namespace, podName, localPort, remotePort)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	return waitForPortForward(stopChan, readyChan, localPort, log), nil
+}
 
-	// Wait for port-forward to be ready
+// waitForPortForward waits until the port-forward is ready
+func waitForPortForward(stopChan, readyChan chan struct{}, localPort int, log *logger.Logger) *Conn {
 	<-readyChan
 
 	log.Successf("Port-forward established successfully")
@@ -41,5 +68,25 @@ func SetupPortForward(
 		StopChan:  stopChan,
 		ReadyChan: readyChan,
 		LocalPort: localPort,
-	}, nil
+	}
+}
+
+// ServiceEndpointAddress returns an address for reaching endpoint from this process. Endpoints that refer to an
+// in-cluster service are port-forwarded on a free local port when running outside the cluster; other
+// endpoints are returned unchanged. The returned cleanup function closes the port-forward, if any.
+func ServiceEndpointAddress(k8sClient *k8s.Client, endpoint, namespace string, log *logger.Logger) (string, func(), error) {
+	svc, ok := ParseServiceEndpoint(endpoint, namespace)
+	if !ok || k8s.InCluster() {
+		return endpoint, func() {}, nil
+	}
+
+	localPort, err := FreeLocalPort()
+	if err != nil {
+		return "", nil, err
+	}
+	pf, err := SetupPortForward(k8sClient, svc.Namespace, svc.Name, localPort, svc.Port, log)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("localhost:%d", pf.LocalPort), func() { close(pf.StopChan) }, nil
 }
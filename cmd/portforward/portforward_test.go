@@ -14,7 +14,7 @@ import (
 func TestSetupPortForward_ServiceNotFound(t *testing.T) {
 	fakeClientset := fake.NewSimpleClientset()
 	client := k8s.NewTestClient(fakeClientset)
-	log := logger.New(true, false)
+	log := logger.New(logger.LevelError, "")
 
 	_, err := SetupPortForward(client, "default", "nonexistent-service", 8080, 9200, log)
 	if err == nil {
@@ -37,7 +37,7 @@ func TestSetupPortForward_NoPodsFound(t *testing.T) {
 		},
 	)
 	client := k8s.NewTestClient(fakeClientset)
-	log := logger.New(true, false)
+	log := logger.New(logger.LevelError, "")
 
 	_, err := SetupPortForward(client, "default", "test-service", 8080, 9200, log)
 	if err == nil {
@@ -72,7 +72,7 @@ func TestSetupPortForward_NoRunningPods(t *testing.T) {
 		},
 	)
 	client := k8s.NewTestClient(fakeClientset)
-	log := logger.New(true, false)
+	log := logger.New(logger.LevelError, "")
 
 	_, err := SetupPortForward(client, "default", "test-service", 8080, 9200, log)
 	if err == nil {
@@ -80,6 +80,37 @@ func TestSetupPortForward_NoRunningPods(t *testing.T) {
 	}
 }
 
+func TestSetupPodPortForward_PodNotFound(t *testing.T) {
+	client := k8s.NewTestClient(fake.NewSimpleClientset())
+	log := logger.New(logger.LevelError, "")
+
+	_, err := SetupPodPortForward(client, "default", "nonexistent-pod", 8080, 9200, log)
+	if err == nil {
+		t.Fatal("expected error for nonexistent pod, got nil")
+	}
+}
+
+func TestSetupPodPortForward_PodNotRunning(t *testing.T) {
+	fakeClientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "elasticsearch-master-0",
+				Namespace: "default",
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+			},
+		},
+	)
+	client := k8s.NewTestClient(fakeClientset)
+	log := logger.New(logger.LevelError, "")
+
+	_, err := SetupPodPortForward(client, "default", "elasticsearch-master-0", 8080, 9200, log)
+	if err == nil {
+		t.Fatal("expected error for pending pod, got nil")
+	}
+}
+
 func TestConn_Structure(t *testing.T) {
 	stopChan := make(chan struct{})
 	readyChan := make(chan struct{})
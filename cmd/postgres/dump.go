@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/postgres"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// dumper dumps a database
+type dumper interface {
+	Dump(database string, w io.Writer) error
+}
+
+// dump is a dump stored in the bucket
+type dump struct {
+	Key       string
+	Database  string
+	CreatedAt time.Time
+	Size      int64
+}
+
+func dumpCmd(cliCtx *config.Context) *cobra.Command {
+	var databases []string
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump PostgreSQL databases to the backup bucket",
+		Long: `Dump each database with pg_dump as plain SQL, compress it with gzip and stream it to the backup bucket under
+dumps/postgres/<database>/, so the dump is never held in memory or written to disk. The databases configured in
+postgres.databases are dumped unless --database is given.
+
+Dumps are not encrypted with archives.encryption. Restore a dump with 'postgres restore'.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runDump(cliCtx, databases); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&databases, "database", nil, "Database to dump, repeatable (default: postgres.databases)")
+	return cmd
+}
+
+func listCmd(cliCtx *config.Context) *cobra.Command {
+	var database string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the PostgreSQL dumps in the backup bucket",
+		Long:  `List the dumps taken with 'postgres dump', newest first.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runList(cliCtx, database); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&database, "database", "", "Only list the dumps of this database")
+	return cmd
+}
+
+func runDump(cliCtx *config.Context, databases []string) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	_, err = dumpDatabases(env, databases, time.Now())
+	return err
+}
+
+// dumpDatabases dumps databases, or the configured databases when none are given, to the backup bucket and returns
+// the keys of the dumps
+func dumpDatabases(env *target.Env, databases []string, createdAt time.Time) ([]string, error) {
+	if len(databases) == 0 {
+		databases = env.Config.Postgres.Databases
+	}
+	if len(databases) == 0 {
+		return nil, exitcode.Wrap(exitcode.Usage, errors.New("no databases to dump: pass --database or configure postgres.databases"))
+	}
+
+	client, err := connectPostgres(env)
+	if err != nil {
+		return nil, err
+	}
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	bucketName := env.Config.Elasticsearch.SnapshotRepository.Bucket
+	keys := make([]string, 0, len(databases))
+	for _, database := range databases {
+		key, err := dumpDatabase(client, bucket, bucketName, database, createdAt, env.Log)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func runList(cliCtx *config.Context, database string) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	bucket, cleanup, err := target.OpenBucket(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	dumps, err := listDumps(bucket, env.Config.Elasticsearch.SnapshotRepository.Bucket, database)
+	if err != nil {
+		return err
+	}
+	if len(dumps) == 0 {
+		env.Log.Infof("No PostgreSQL dumps found")
+		return nil
+	}
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	return formatter.PrintTable(dumpsTable(dumps))
+}
+
+// dumpDatabase streams a dump of database to the bucket while pg_dump runs, returning its key
+func dumpDatabase(client dumper, bucket dumpBucket, bucketName, database string, createdAt time.Time, log *logger.Logger) (string, error) {
+	key := postgres.DumpKey(database, createdAt)
+	log.Infof("Dumping database %s to '%s'...", database, key)
+
+	reader, writer := io.Pipe()
+	dumped := make(chan error, 1)
+	go func() {
+		err := client.Dump(database, writer)
+		_ = writer.CloseWithError(err)
+		dumped <- err
+	}()
+
+	size, err := bucket.PutObjectStream(bucketName, key, reader)
+	if err != nil {
+		// Stop the dump, which is blocked writing to the pipe, and report it when it is why the upload failed
+		_ = reader.CloseWithError(err)
+		if dumpErr := <-dumped; dumpErr != nil && errors.Is(err, dumpErr) {
+			return "", dumpErr
+		}
+		return "", fmt.Errorf("failed to store dump of database %s: %w", database, err)
+	}
+	if err := <-dumped; err != nil {
+		return "", err
+	}
+
+	log.Successf("Dumped database %s to '%s' (%s compressed)", database, key, output.FormatBytes(size))
+	return key, nil
+}
+
+// listDumps returns the dumps in the bucket, of a single database unless database is empty, newest first
+func listDumps(bucket dumpBucket, bucketName, database string) ([]dump, error) {
+	prefix := postgres.Prefix
+	if database != "" {
+		prefix += database + "/"
+	}
+	objects, err := bucket.ListObjects(bucketName, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var dumps []dump
+	for _, object := range objects {
+		objectDatabase, createdAt, ok := postgres.ParseDumpKey(object.Key)
+		if !ok {
+			continue
+		}
+		dumps = append(dumps, dump{Key: object.Key, Database: objectDatabase, CreatedAt: createdAt, Size: object.Size})
+	}
+	slices.SortFunc(dumps, func(a, b dump) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return dumps, nil
+}
+
+// dumpsTable lists dumps, one per row
+func dumpsTable(dumps []dump) output.Table {
+	table := output.Table{Headers: []string{"DATABASE", "CREATED", "SIZE", "KEY"}}
+	for _, d := range dumps {
+		table.Rows = append(table.Rows, []string{d.Database, d.CreatedAt.Local().Format(time.RFC3339), output.FormatBytes(d.Size), d.Key})
+	}
+	return table
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/postgres"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "postgres",
+		Short: "PostgreSQL backup and restore operations",
+		Long: `Operations on the PostgreSQL server of the installation, such as the settings database, located with the
+postgres section of the configuration. pg_dump and psql are run in a Ready server pod, like kubectl exec, and dumps
+are streamed to and from the backup bucket under dumps/postgres/.`,
+	}
+
+	cmd.AddCommand(dumpCmd(cliCtx))
+	cmd.AddCommand(listCmd(cliCtx))
+	cmd.AddCommand(restoreCmd(cliCtx))
+	return cmd
+}
+
+// dumpBucket stores and reads dumps in the backup bucket
+type dumpBucket interface {
+	PutObjectStream(bucket, key string, r io.Reader) (int64, error)
+	OpenObject(bucket, key string) (io.Reader, error)
+	ListObjects(bucket, prefix string) ([]s3.Object, error)
+}
+
+// podRunner runs the PostgreSQL tools in a container of a server pod
+type podRunner struct {
+	k8sClient k8s.Interface
+	namespace string
+	pod       string
+	container string
+}
+
+// Stream implements postgres.Runner
+func (r *podRunner) Stream(command []string, stdin io.Reader, stdout io.Writer) error {
+	return r.k8sClient.ExecPodStream(r.namespace, r.pod, r.container, command, stdin, stdout)
+}
+
+// connectPostgres returns a client running the PostgreSQL tools in a Ready server pod
+func connectPostgres(env *target.Env) (*postgres.Client, error) {
+	cfg := env.Config.Postgres
+	pod, err := env.K8s.ReadyPod(env.CLI.Config.Namespace, cfg.PodSelector)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to find a PostgreSQL server: %w", err))
+	}
+	env.Log.Debugf("Running the PostgreSQL tools in pod %s", pod)
+
+	runner := &podRunner{k8sClient: env.K8s, namespace: env.CLI.Config.Namespace, pod: pod, container: cfg.Container}
+	client, err := postgres.NewClient(runner, cfg.User, cfg.PasswordEnv)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, err)
+	}
+	return client, nil
+}
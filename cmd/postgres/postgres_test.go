@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/postgres"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBucket is an in-memory dumpBucket
+type memoryBucket map[string][]byte
+
+func (m memoryBucket) PutObjectStream(_, key string, r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	m[key] = data
+	return int64(len(data)), nil
+}
+
+func (m memoryBucket) OpenObject(_, key string) (io.Reader, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, &s3.Error{StatusCode: 404, Code: "NoSuchKey"}
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (m memoryBucket) ListObjects(_, prefix string) ([]s3.Object, error) {
+	var objects []s3.Object
+	for key, data := range m {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, s3.Object{Key: key, Size: int64(len(data))})
+		}
+	}
+	return objects, nil
+}
+
+// fakeDatabase is a PostgreSQL server with a single database whose sessions end after a number of checks
+type fakeDatabase struct {
+	dump     string
+	dumpErr  error
+	restored string
+	limit    int
+	limits   []int
+	// sessions is the number of sessions per check, the last one repeats
+	sessions   []int
+	terminated bool
+}
+
+func (f *fakeDatabase) Dump(_ string, w io.Writer) error {
+	if f.dumpErr != nil {
+		return f.dumpErr
+	}
+	_, err := io.WriteString(w, f.dump)
+	return err
+}
+
+func (f *fakeDatabase) Restore(_ string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	f.restored = string(data)
+	return err
+}
+
+func (f *fakeDatabase) ConnectionLimit(database string) (int, error) {
+	if database != "settings" {
+		return 0, fmt.Errorf("%w: %s", postgres.ErrDatabaseNotFound, database)
+	}
+	return f.limit, nil
+}
+
+func (f *fakeDatabase) SetConnectionLimit(_ string, limit int) error {
+	f.limits = append(f.limits, limit)
+	return nil
+}
+
+func (f *fakeDatabase) Connections(string) (int, error) {
+	count := f.sessions[0]
+	if len(f.sessions) > 1 {
+		f.sessions = f.sessions[1:]
+	}
+	return count, nil
+}
+
+func (f *fakeDatabase) TerminateConnections(string) (int, error) {
+	f.terminated = true
+	return f.sessions[0], nil
+}
+
+func TestDumpDatabase(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	createdAt := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	t.Run("stored", func(t *testing.T) {
+		bucket := memoryBucket{}
+
+		key, err := dumpDatabase(&fakeDatabase{dump: "compressed dump"}, bucket, "backups", "settings", createdAt, log)
+
+		require.NoError(t, err)
+		assert.Equal(t, "dumps/postgres/settings/20260301T123000Z.sql.gz", key)
+		assert.Equal(t, "compressed dump", string(bucket[key]))
+	})
+
+	t.Run("dump fails", func(t *testing.T) {
+		bucket := memoryBucket{}
+
+		_, err := dumpDatabase(&fakeDatabase{dumpErr: errors.New("pg_dump: connection refused")}, bucket, "backups", "settings", createdAt, log)
+
+		require.EqualError(t, err, "pg_dump: connection refused")
+		assert.Empty(t, bucket)
+	})
+}
+
+func TestListDumps(t *testing.T) {
+	bucket := memoryBucket{
+		"dumps/postgres/settings/20260301T123000Z.sql.gz": []byte("a"),
+		"dumps/postgres/settings/20260302T123000Z.sql.gz": []byte("bb"),
+		"dumps/postgres/audit/20260303T123000Z.sql.gz":    []byte("ccc"),
+		"dumps/postgres/settings/notes.txt":               []byte("dddd"),
+	}
+
+	dumps, err := listDumps(bucket, "backups", "")
+	require.NoError(t, err)
+	keys := make([]string, 0, len(dumps))
+	for _, d := range dumps {
+		keys = append(keys, d.Key)
+	}
+	assert.Equal(t, []string{
+		"dumps/postgres/audit/20260303T123000Z.sql.gz",
+		"dumps/postgres/settings/20260302T123000Z.sql.gz",
+		"dumps/postgres/settings/20260301T123000Z.sql.gz",
+	}, keys, "newest first, other objects are left out")
+	assert.Equal(t, dump{Key: keys[1], Database: "settings", CreatedAt: time.Date(2026, 3, 2, 12, 30, 0, 0, time.UTC), Size: 2}, dumps[1])
+
+	dumps, err = listDumps(bucket, "backups", "audit")
+	require.NoError(t, err)
+	assert.Len(t, dumps, 1)
+}
+
+func TestSelectDump(t *testing.T) {
+	bucket := memoryBucket{
+		"dumps/postgres/settings/20260301T123000Z.sql.gz": nil,
+		"dumps/postgres/settings/20260302T123000Z.sql.gz": nil,
+	}
+
+	key, database, err := selectDump(bucket, "backups", "", "settings")
+	require.NoError(t, err)
+	assert.Equal(t, "dumps/postgres/settings/20260302T123000Z.sql.gz", key)
+	assert.Equal(t, "settings", database)
+
+	key, database, err = selectDump(bucket, "backups", "dumps/postgres/settings/20260301T123000Z.sql.gz", "settings_copy")
+	require.NoError(t, err)
+	assert.Equal(t, "dumps/postgres/settings/20260301T123000Z.sql.gz", key)
+	assert.Equal(t, "settings_copy", database, "a dump can be restored into another database")
+
+	_, _, err = selectDump(bucket, "backups", "", "")
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	_, _, err = selectDump(bucket, "backups", "exports/pipelines/20260301T123000Z.json", "")
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	_, _, err = selectDump(bucket, "backups", "", "audit")
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+}
+
+func TestRestoreDump(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	key := "dumps/postgres/settings/20260301T123000Z.sql.gz"
+	bucket := memoryBucket{key: []byte("compressed dump")}
+
+	t.Run("sessions end", func(t *testing.T) {
+		database := &fakeDatabase{limit: -1, sessions: []int{2, 1, 0}}
+
+		require.NoError(t, restoreDump(database, bucket, "backups", key, "settings", time.Minute, time.Millisecond, log))
+
+		assert.Equal(t, "compressed dump", database.restored)
+		assert.False(t, database.terminated)
+		assert.Equal(t, []int{0, -1}, database.limits, "new connections are blocked and allowed again afterwards")
+	})
+
+	t.Run("sessions terminated", func(t *testing.T) {
+		database := &fakeDatabase{limit: 50, sessions: []int{3}}
+
+		require.NoError(t, restoreDump(database, bucket, "backups", key, "settings", 5*time.Millisecond, time.Millisecond, log))
+
+		assert.True(t, database.terminated)
+		assert.Equal(t, "compressed dump", database.restored)
+		assert.Equal(t, []int{0, 50}, database.limits)
+	})
+
+	t.Run("missing database", func(t *testing.T) {
+		database := &fakeDatabase{sessions: []int{0}}
+
+		err := restoreDump(database, bucket, "backups", key, "unknown", time.Minute, time.Millisecond, log)
+
+		assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+		assert.Empty(t, database.limits)
+	})
+
+	t.Run("missing dump", func(t *testing.T) {
+		database := &fakeDatabase{sessions: []int{0}}
+
+		err := restoreDump(database, bucket, "backups", "dumps/postgres/settings/20200101T000000Z.sql.gz", "settings", time.Minute, time.Millisecond, log)
+
+		assert.ErrorContains(t, err, "NoSuchKey")
+		assert.Empty(t, database.limits, "connections are not drained without a dump to restore")
+	})
+}
+
+func TestVerifyDump(t *testing.T) {
+	key := "dumps/postgres/settings/20260301T123000Z.sql.gz"
+	bucket := memoryBucket{
+		key: []byte("compressed dump"),
+		"dumps/postgres/settings/20260302T123000Z.sql.gz": nil,
+		"dumps/postgres/audit/20260301T123000Z.sql.gz":    []byte("compressed dump"),
+	}
+	database := &fakeDatabase{}
+
+	require.NoError(t, verifyDump(database, bucket, "backups", key))
+
+	for _, invalid := range []string{
+		"dumps/postgres/settings/20200101T000000Z.sql.gz",
+		"dumps/postgres/settings/20260302T123000Z.sql.gz",
+		"dumps/postgres/audit/20260301T123000Z.sql.gz",
+	} {
+		err := verifyDump(database, bucket, "backups", invalid)
+		assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err), invalid)
+	}
+	err := verifyDump(database, bucket, "backups", "exports/pipelines/20260301T123000Z.json")
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
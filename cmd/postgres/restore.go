@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/postgres"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+const (
+	// defaultDrainTimeout is how long the sessions of a database get to end before they are terminated
+	defaultDrainTimeout = 2 * time.Minute
+	// drainPollInterval is the time between checks whether the sessions of a database ended
+	drainPollInterval = 2 * time.Second
+)
+
+// restorer restores a dump into a database after draining its connections
+type restorer interface {
+	Restore(database string, r io.Reader) error
+	ConnectionLimit(database string) (int, error)
+	SetConnectionLimit(database string, limit int) error
+	Connections(database string) (int, error)
+	TerminateConnections(database string) (int, error)
+}
+
+// restoreOptions are the flags of 'postgres restore'
+type restoreOptions struct {
+	Key          string
+	Database     string
+	DrainTimeout time.Duration
+}
+
+func restoreCmd(cliCtx *config.Context) *cobra.Command {
+	opts := restoreOptions{}
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore a PostgreSQL database from a dump",
+		Long: `Restore a database from a dump taken with 'postgres dump'. The dump is given with --key, or the most recent
+dump of --database is restored. A dump is restored into the database it was taken of unless --database is given.
+
+Before the restore, the Deployments selected by postgres.scaleDownLabelSelector are scaled down and the connections
+to the database are drained: new connections of users other than superusers are blocked by a connection limit of 0,
+and sessions that did not end within --drain-timeout are terminated. The dump is streamed from the backup bucket to
+psql and runs in a single transaction, so a failing restore leaves the database as it was. Afterwards the connection
+limit and the Deployments are restored.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRestore(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Key, "key", "", "Object key of the dump to restore (see 'postgres list')")
+	cmd.Flags().StringVar(&opts.Database, "database", "", "Database to restore (default: the database the dump was taken of)")
+	cmd.Flags().DurationVar(&opts.DrainTimeout, "drain-timeout", defaultDrainTimeout, "How long sessions of the database get to end before they are terminated")
+	return cmd
+}
+
+func runRestore(cliCtx *config.Context, opts restoreOptions) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	bucket, cleanup, err := target.OpenBucket(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	bucketName := env.Config.Elasticsearch.SnapshotRepository.Bucket
+	key, database, err := selectDump(bucket, bucketName, opts.Key, opts.Database)
+	if err != nil {
+		return err
+	}
+
+	client, err := connectPostgres(env)
+	if err != nil {
+		return err
+	}
+
+	if err := prompt.New(cliCtx.Config.AssumeYes).Confirm(fmt.Sprintf("Replace the contents of database %s with dump '%s'?", database, key)); err != nil {
+		return fmt.Errorf("restore aborted: %w", err)
+	}
+
+	return restoreDatabase(env, client, bucket, key, database, opts.DrainTimeout)
+}
+
+// restoreDatabase restores the dump stored under key into database while the Deployments writing to it are scaled
+// down, and records the restore in the audit log
+func restoreDatabase(env *target.Env, client restorer, bucket dumpBucket, key, database string, drainTimeout time.Duration) (err error) {
+	startedAt := time.Now()
+	defer func() {
+		err = target.RecordAudit(env.K8s, env.CLI, audit.Entry{
+			Operation:      "postgres-restore",
+			Snapshot:       key,
+			DurationMillis: time.Since(startedAt).Milliseconds(),
+		}, err, env.Log)
+	}()
+
+	namespace := env.CLI.Config.Namespace
+	if selector := env.Config.Postgres.ScaleDownLabelSelector; selector != "" {
+		scaledDeployments, err := target.ScaleDownDeployments(env.K8s, namespace, selector, env.Log)
+		if err != nil {
+			return err
+		}
+		defer target.ScaleUpDeployments(env.K8s, namespace, scaledDeployments, env.Log)
+	}
+
+	return restoreDump(client, bucket, env.Config.Elasticsearch.SnapshotRepository.Bucket, key, database, drainTimeout, drainPollInterval, env.Log)
+}
+
+// selectDump returns the key of the dump to restore and the database to restore it into: the dump stored under key,
+// or the most recent dump of database when key is empty
+func selectDump(bucket dumpBucket, bucketName, key, database string) (string, string, error) {
+	if key != "" {
+		dumpDatabase, _, ok := postgres.ParseDumpKey(key)
+		if !ok {
+			return "", "", exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is not the key of a PostgreSQL dump (see 'postgres list')", key))
+		}
+		if database == "" {
+			database = dumpDatabase
+		}
+		return key, database, nil
+	}
+
+	if database == "" {
+		return "", "", exitcode.Wrap(exitcode.Usage, errors.New("pass --key or --database to select the dump to restore"))
+	}
+	dumps, err := listDumps(bucket, bucketName, database)
+	if err != nil {
+		return "", "", err
+	}
+	if len(dumps) == 0 {
+		return "", "", exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("no dumps of database %s found: run 'postgres dump' first", database))
+	}
+	return dumps[0].Key, database, nil
+}
+
+// restoreDump drains the connections to database and restores the dump stored under key into it
+func restoreDump(client restorer, bucket dumpBucket, bucketName, key, database string, drainTimeout, interval time.Duration, log *logger.Logger) error {
+	reader, err := bucket.OpenObject(bucketName, key)
+	if err != nil {
+		return fmt.Errorf("failed to read dump '%s': %w", key, err)
+	}
+
+	restoreLimit, err := drainConnections(client, database, drainTimeout, interval, log)
+	if err != nil {
+		return err
+	}
+	defer restoreLimit()
+
+	log.Infof("Restoring database %s from '%s'...", database, key)
+	if err := client.Restore(database, reader); err != nil {
+		return err
+	}
+	log.Successf("Restored database %s from '%s'", database, key)
+	return nil
+}
+
+// drainConnections blocks new connections to database and waits up to timeout for its sessions to end, terminating
+// the sessions that remain. It returns a function restoring the connection limit of the database.
+func drainConnections(client restorer, database string, timeout, interval time.Duration, log *logger.Logger) (func(), error) {
+	limit, err := client.ConnectionLimit(database)
+	if errors.Is(err, postgres.ErrDatabaseNotFound) {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SetConnectionLimit(database, 0); err != nil {
+		return nil, fmt.Errorf("failed to block new connections to database %s: %w", database, err)
+	}
+	restoreLimit := func() {
+		if err := client.SetConnectionLimit(database, limit); err != nil {
+			log.Warningf("Failed to restore the connection limit of database %s to %d: %v", database, limit, err)
+		}
+	}
+
+	log.Infof("Blocked new connections to database %s, waiting up to %s for its sessions to end...", database, timeout)
+	deadline := time.Now().Add(timeout)
+	for {
+		count, err := client.Connections(database)
+		if err != nil {
+			restoreLimit()
+			return nil, err
+		}
+		if count == 0 {
+			log.Successf("All sessions of database %s ended", database)
+			return restoreLimit, nil
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+		log.Debugf("%d session(s) of database %s remaining", count, database)
+		time.Sleep(interval)
+	}
+
+	terminated, err := client.TerminateConnections(database)
+	if err != nil {
+		restoreLimit()
+		return nil, fmt.Errorf("failed to terminate the sessions of database %s: %w", database, err)
+	}
+	log.Warningf("Terminated %d session(s) of database %s that did not end within %s", terminated, database, timeout)
+	return restoreLimit, nil
+}
@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/postgres"
+)
+
+// backupKeySeparator separates the dump keys in the name of a backup of several databases
+const backupKeySeparator = ","
+
+// Target returns PostgreSQL as a backup target: a backup is a dump of each configured database, named by the
+// comma-separated keys of its dumps, and restores run like postgres restore --yes for each dump
+func Target() target.BackupTarget {
+	return postgresTarget{}
+}
+
+type postgresTarget struct{}
+
+func (postgresTarget) Name() string {
+	return "postgres"
+}
+
+// Configure checks that the configured databases exist; dumps need no further setup
+func (postgresTarget) Configure(env *target.Env) error {
+	databases := env.Config.Postgres.Databases
+	if len(databases) == 0 {
+		return exitcode.Wrap(exitcode.ConfigError, errors.New("no databases to back up: configure postgres.databases"))
+	}
+
+	client, err := connectPostgres(env)
+	if err != nil {
+		return err
+	}
+	for _, database := range databases {
+		if err := checkDatabase(client, database, exitcode.ConfigError); err != nil {
+			return err
+		}
+	}
+	env.Log.Successf("PostgreSQL databases %s can be dumped", strings.Join(databases, ", "))
+	return nil
+}
+
+// Backup dumps the configured databases and returns the keys of the dumps
+func (postgresTarget) Backup(env *target.Env) (string, error) {
+	keys, err := dumpDatabases(env, nil, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(keys, backupKeySeparator), nil
+}
+
+// ListBackups returns the dumps in the backup bucket, newest first
+func (postgresTarget) ListBackups(env *target.Env) ([]target.Backup, error) {
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	dumps, err := listDumps(bucket, env.Config.Elasticsearch.SnapshotRepository.Bucket, "")
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list dumps: %w", err))
+	}
+	backups := make([]target.Backup, 0, len(dumps))
+	for _, d := range dumps {
+		backups = append(backups, target.Backup{Name: d.Key, State: "SUCCESS", StartTime: d.CreatedAt})
+	}
+	return backups, nil
+}
+
+// Restore restores each dump of the backup into the database it was taken of, like postgres restore --yes
+func (postgresTarget) Restore(env *target.Env, name string) error {
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	client, err := connectPostgres(env)
+	if err != nil {
+		return err
+	}
+	bucketName := env.Config.Elasticsearch.SnapshotRepository.Bucket
+	for _, key := range strings.Split(name, backupKeySeparator) {
+		key, database, err := selectDump(bucket, bucketName, key, "")
+		if err != nil {
+			return err
+		}
+		if err := restoreDatabase(env, client, bucket, key, database, defaultDrainTimeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify checks that each dump of the backup is in the bucket and not empty, and that the database it restores into
+// exists
+func (postgresTarget) Verify(env *target.Env, name string) error {
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	client, err := connectPostgres(env)
+	if err != nil {
+		return err
+	}
+	bucketName := env.Config.Elasticsearch.SnapshotRepository.Bucket
+	for _, key := range strings.Split(name, backupKeySeparator) {
+		if err := verifyDump(client, bucket, bucketName, key); err != nil {
+			return err
+		}
+		env.Log.Successf("Dump '%s' can be restored", key)
+	}
+	return nil
+}
+
+// verifyDump checks that the dump stored under key is in the bucket and not empty, and that its database exists
+func verifyDump(client restorer, bucket dumpBucket, bucketName, key string) error {
+	database, _, ok := postgres.ParseDumpKey(key)
+	if !ok {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("'%s' is not the key of a PostgreSQL dump (see 'postgres list')", key))
+	}
+	dumps, err := listDumps(bucket, bucketName, database)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list dumps: %w", err))
+	}
+
+	var found *dump
+	for i := range dumps {
+		if dumps[i].Key == key {
+			found = &dumps[i]
+		}
+	}
+	switch {
+	case found == nil:
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("dump '%s' not found", key))
+	case found.Size == 0:
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("dump '%s' is empty", key))
+	}
+
+	return checkDatabase(client, database, exitcode.ValidationFailed)
+}
+
+// checkDatabase returns an error with exit code notFound when database does not exist
+func checkDatabase(client restorer, database string, notFound int) error {
+	_, err := client.ConnectionLimit(database)
+	if errors.Is(err, postgres.ErrDatabaseNotFound) {
+		return exitcode.Wrap(notFound, err)
+	}
+	return err
+}
@@ -1,51 +1,159 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/archive"
+	"github.com/stackvista/stackstate-backup-cli/cmd/catalog"
+	"github.com/stackvista/stackstate-backup-cli/cmd/completion"
+	configcmd "github.com/stackvista/stackstate-backup-cli/cmd/config"
+	"github.com/stackvista/stackstate-backup-cli/cmd/docs"
+	"github.com/stackvista/stackstate-backup-cli/cmd/doctor"
+	"github.com/stackvista/stackstate-backup-cli/cmd/dr"
 	"github.com/stackvista/stackstate-backup-cli/cmd/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/cmd/generate"
+	"github.com/stackvista/stackstate-backup-cli/cmd/history"
+	"github.com/stackvista/stackstate-backup-cli/cmd/jobs"
+	"github.com/stackvista/stackstate-backup-cli/cmd/kafka"
+	"github.com/stackvista/stackstate-backup-cli/cmd/postgres"
+	"github.com/stackvista/stackstate-backup-cli/cmd/s3"
+	"github.com/stackvista/stackstate-backup-cli/cmd/serve"
+	"github.com/stackvista/stackstate-backup-cli/cmd/stackgraph"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
 	"github.com/stackvista/stackstate-backup-cli/cmd/version"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/color"
 	"github.com/stackvista/stackstate-backup-cli/internal/config"
-)
-
-var (
-	cliCtx *config.Context
+	es "github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/proxy"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 )
 
 // addBackupConfigFlags adds configuration flags needed for backup/restore operations
 // to commands that interact with data services (Elasticsearch, etc.)
-func addBackupConfigFlags(cmd *cobra.Command) {
+func addBackupConfigFlags(cmd *cobra.Command, cliCtx *config.Context) {
 	cmd.PersistentFlags().StringVar(&cliCtx.Config.Namespace, "namespace", "", "Kubernetes namespace (required)")
-	cmd.PersistentFlags().StringVar(&cliCtx.Config.Kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: ~/.kube/config)")
-	cmd.PersistentFlags().BoolVar(&cliCtx.Config.Debug, "debug", false, "Enable debug output")
-	cmd.PersistentFlags().BoolVarP(&cliCtx.Config.Quiet, "quiet", "q", false, "Suppress operational messages (only show errors and data output)")
-	cmd.PersistentFlags().StringVar(&cliCtx.Config.ConfigMapName, "configmap", "suse-observability-backup-config", "ConfigMap name containing backup configuration")
-	cmd.PersistentFlags().StringVar(&cliCtx.Config.SecretName, "secret", "suse-observability-backup-config", "Secret name containing backup configuration")
-	cmd.PersistentFlags().StringVarP(&cliCtx.Config.OutputFormat, "output", "o", "table", "Output format (table, json)")
+	addClusterFlags(cmd, cliCtx)
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.HelmValues, "helm-values", "", "SUSE Observability Helm values file to read the configuration from (the ConfigMap and Secret override it)")
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.AuditConfigMapName, "audit-configmap", audit.DefaultConfigMapName, "ConfigMap name holding the audit log of destructive operations")
+	cmd.PersistentFlags().Float64Var(&cliCtx.Config.MaxRequestsPerSecond, "max-requests-per-second", es.DefaultMaxRequestsPerSecond, "Maximum Elasticsearch requests per second (0 for no limit)")
 	_ = cmd.MarkPersistentFlagRequired("namespace")
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completion.Namespaces(cliCtx))
 }
 
-func init() {
-	cliCtx = config.NewContext()
+// addClusterFlags adds the flags to connect to the cluster and find the backup configuration, without a namespace,
+// to commands that look across namespaces
+func addClusterFlags(cmd *cobra.Command, cliCtx *config.Context) {
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.Kubeconfig, "kubeconfig", "", "Path to kubeconfig file (default: $KUBECONFIG or ~/.kube/config)")
+	cmd.PersistentFlags().Var(&cliCtx.Config.LogLevel, "log-level", "Log level (error, warn, info, debug, trace)")
+	cmd.PersistentFlags().BoolVar(&cliCtx.Config.Debug, "debug", false, "Enable debug output (alias for --log-level=debug)")
+	cmd.PersistentFlags().BoolVarP(&cliCtx.Config.Quiet, "quiet", "q", false, "Suppress operational messages (alias for --log-level=error)")
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.ConfigMapName, "configmap", config.DefaultConfigMapName, "ConfigMap name containing backup configuration")
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.SecretName, "secret", config.DefaultSecretName, "Secret name containing backup configuration")
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.As, "as", "", "Username to impersonate for Kubernetes operations")
+	cmd.PersistentFlags().StringArrayVar(&cliCtx.Config.AsGroups, "as-group", nil, "Group to impersonate for Kubernetes operations, can be repeated (requires --as)")
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.Proxy, "proxy", "", "Proxy URL for the Kubernetes API server and Elasticsearch (default: HTTPS_PROXY/HTTP_PROXY, except NO_PROXY)")
+	cmd.PersistentFlags().StringVarP(&cliCtx.Config.OutputFormat, "output", "o", "table", "Output format (table, json, csv, yaml, junit)")
+	cmd.PersistentFlags().StringVar(&cliCtx.Config.OutputFile, "output-file", "", "Write the output to this file instead of stdout, replacing it atomically")
+}
+
+// NewRootCmd returns the sts-backup command tree. Every call returns a tree with its own context, so the commands
+// can be embedded in other binaries or tests, and run more than once in a process.
+func NewRootCmd() *cobra.Command {
+	cliCtx := config.NewContext()
+
+	rootCmd := &cobra.Command{
+		Use:   "sts-backup",
+		Short: "Backup and restore tool for SUSE Observability platform",
+		Long:  `A CLI tool for managing backups and restores for SUSE Observability platform running on Kubernetes.`,
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			if cliCtx.Config.NoColor {
+				color.Disable()
+			}
+			resolveLogLevel(cmd, cliCtx)
+			validateConnectionFlags(cliCtx)
+		},
+	}
+
+	// Replaced by our own completion command, which documents the dynamic completions
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.PersistentFlags().BoolVar(&cliCtx.Config.AssumeYes, "yes", false, "Skip confirmation prompts (required for destructive operations when stdin is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&cliCtx.Config.ReadOnly, "read-only", false, "Refuse every operation that changes the cluster, Elasticsearch or the backup bucket, for safe exploration")
+	rootCmd.PersistentFlags().BoolVar(&cliCtx.Config.NoColor, "no-color", false, "Disable colored output (also disabled when NO_COLOR is set or output is not a terminal)")
 
 	// Add backup config flags to commands that need them
-	esCmd := elasticsearch.Cmd(cliCtx)
-	addBackupConfigFlags(esCmd)
-	rootCmd.AddCommand(esCmd)
+	for _, cmd := range []*cobra.Command{
+		elasticsearch.Cmd(cliCtx),
+		doctor.Cmd(cliCtx),
+		generate.Cmd(cliCtx),
+		serve.Cmd(cliCtx),
+		history.Cmd(cliCtx),
+		jobs.Cmd(cliCtx),
+		catalog.Cmd(cliCtx),
+		archive.Cmd(cliCtx),
+		s3.Cmd(cliCtx),
+		kafka.Cmd(cliCtx),
+		postgres.Cmd(cliCtx),
+		stackgraph.Cmd(cliCtx),
+		dr.Cmd(cliCtx),
+	} {
+		addBackupConfigFlags(cmd, cliCtx)
+		rootCmd.AddCommand(cmd)
+	}
+
+	// Add commands that look across namespaces
+	configCmd := configcmd.Cmd(cliCtx)
+	addClusterFlags(configCmd, cliCtx)
+	rootCmd.AddCommand(configCmd)
 
 	// Add commands that don't need backup config flags
+	rootCmd.AddCommand(target.Cmd(target.NewRegistry(elasticsearch.Target(), kafka.Target(), postgres.Target(), stackgraph.Target())))
 	rootCmd.AddCommand(version.Cmd())
+	rootCmd.AddCommand(completion.Cmd())
+	rootCmd.AddCommand(docs.Cmd())
+
+	return rootCmd
 }
 
-var rootCmd = &cobra.Command{
-	Use:   "sts-backup",
-	Short: "Backup and restore tool for SUSE Observability platform",
-	Long:  `A CLI tool for managing backups and restores for SUSE Observability platform running on Kubernetes.`,
+// validateConnectionFlags exits with a usage error when --proxy is not a valid proxy URL or --as-group is given
+// without --as, reporting both when both are wrong, before any connection is made
+func validateConnectionFlags(cliCtx *config.Context) {
+	var errs []error
+	if cliCtx.Config.Proxy != "" {
+		errs = append(errs, proxy.Validate(cliCtx.Config.Proxy))
+	}
+	if len(cliCtx.Config.AsGroups) > 0 && cliCtx.Config.As == "" {
+		errs = append(errs, fmt.Errorf("--as-group requires --as"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+		os.Exit(exitcode.Usage)
+	}
+}
+
+// resolveLogLevel applies the --debug and --quiet aliases unless --log-level was given explicitly
+func resolveLogLevel(cmd *cobra.Command, cliCtx *config.Context) {
+	if cmd.Flags().Changed("log-level") {
+		return
+	}
+	switch {
+	case cliCtx.Config.Debug:
+		cliCtx.Config.LogLevel = logger.LevelDebug
+	case cliCtx.Config.Quiet:
+		cliCtx.Config.LogLevel = logger.LevelError
+	}
 }
 
+// Execute runs the root command. Commands exit with their own exit code (see internal/exitcode),
+// so errors returned here come from cobra itself, i.e. invalid flags or arguments.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	if err := NewRootCmd().Execute(); err != nil {
+		os.Exit(exitcode.Usage)
 	}
 }
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRootCmd_Independent(t *testing.T) {
+	first, second := NewRootCmd(), NewRootCmd()
+
+	firstRestore, _, err := first.Find([]string{"elasticsearch", "restore-snapshot"})
+	require.NoError(t, err)
+	secondRestore, _, err := second.Find([]string{"elasticsearch", "restore-snapshot"})
+	require.NoError(t, err)
+
+	require.NoError(t, firstRestore.ParseFlags([]string{"--namespace", "first", "--snapshot-name", "snap-1", "--drop-all-indices"}))
+
+	assert.Equal(t, "first", firstRestore.Flag("namespace").Value.String())
+	firstSnapshots, err := firstRestore.Flags().GetStringSlice("snapshot-name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snap-1"}, firstSnapshots)
+	assert.Empty(t, secondRestore.Flag("namespace").Value.String())
+	secondSnapshots, err := secondRestore.Flags().GetStringSlice("snapshot-name")
+	require.NoError(t, err)
+	assert.Empty(t, secondSnapshots)
+	assert.Equal(t, "false", secondRestore.Flag("drop-all-indices").Value.String())
+}
+
+func TestNewRootCmd_Targets(t *testing.T) {
+	root := NewRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"targets"})
+
+	require.NoError(t, root.Execute())
+	assert.Equal(t, "elasticsearch\nkafka\npostgres\nstackgraph\n", out.String())
+}
@@ -0,0 +1,319 @@
+package s3
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+// Step results
+const (
+	StatusPass = "PASS"
+	StatusFail = "FAIL"
+	StatusSkip = "SKIP"
+)
+
+// Steps of the connectivity check, in the order they run
+const (
+	stepHeadBucket   = "HeadBucket"
+	stepPutObject    = "PutObject"
+	stepDeleteObject = "DeleteObject"
+)
+
+// checkKeyPrefix is the prefix of the objects written by the connectivity check
+const checkKeyPrefix = "sts-backup-check/"
+
+// indicesDir holds the shard data of a snapshot repository, one directory per index
+const indicesDir = "indices/"
+
+// Kinds of the objects at the root of a snapshot repository
+var repositoryObjectKinds = []struct {
+	pattern *regexp.Regexp
+	kind    string
+}{
+	{regexp.MustCompile(`^index-\d+$`), "repository generation"},
+	{regexp.MustCompile(`^index\.latest$`), "latest generation"},
+	{regexp.MustCompile(`^snap-.+\.dat$`), "snapshot info"},
+	{regexp.MustCompile(`^meta-.+\.dat$`), "cluster metadata"},
+	{regexp.MustCompile(`^tests-`), "repository verification"},
+}
+
+// bucketChecker performs the requests of the connectivity check
+type bucketChecker interface {
+	HeadBucket(bucket string) error
+	PutObject(bucket, key string, body []byte) error
+	DeleteObject(bucket, key string) error
+}
+
+// checkStep is the outcome of a single request of the connectivity check
+type checkStep struct {
+	Name    string
+	Status  string
+	Latency time.Duration
+	Details string
+}
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "s3",
+		Short: "Inspect the S3 bucket backing the snapshot repository",
+		Long: `Access the S3 compatible object storage (e.g. MinIO) backing the snapshot repository directly, with the endpoint,
+bucket and credentials configured in elasticsearch.snapshotRepository. In-cluster object storage is reached through
+a port-forward when running outside the cluster.`,
+	}
+
+	cmd.AddCommand(checkCmd(cliCtx))
+	cmd.AddCommand(lsCmd(cliCtx))
+	cmd.AddCommand(serverInfoCmd(cliCtx))
+	return cmd
+}
+
+func checkCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Check S3 credentials and connectivity",
+		Long: `Check the configured S3 endpoint and credentials independently of Elasticsearch: the bucket is checked with
+HeadBucket, and a small object is written and deleted again. Every request is reported with its latency.
+
+This separates a misconfigured Elasticsearch snapshot repository from broken object storage during incident triage.
+Exits with code 5 when any request fails.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runCheck(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func lsCmd(cliCtx *config.Context) *cobra.Command {
+	var prefix string
+	var recursive bool
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List snapshot repository objects",
+		Long: `List the objects of the snapshot repository with their size and last modification time: the repository
+generations (index-N, index.latest), snapshot info (snap-*.dat) and cluster metadata (meta-*.dat) files.
+Shard data under indices/ is only listed with --recursive.
+
+Use it to diagnose repository corruption, e.g. a missing index-N generation or snap-*.dat file, or to compare
+the repository against an offsite replica.`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if err := runLs(cliCtx, prefix, cmd.Flags().Changed("prefix"), recursive); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&prefix, "prefix", "", "Key prefix of the repository (default: elasticsearch.snapshotRepository.basepath)")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Also list the shard data under indices/")
+	return cmd
+}
+
+func runCheck(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := openClient(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repo := env.Config.Elasticsearch.SnapshotRepository
+	env.Log.Infof("Checking bucket '%s' at %s...", repo.Bucket, repo.Endpoint)
+	steps := checkBucket(client, repo.Bucket, checkKeyPrefix+cliCtx.RunID)
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintTable(stepsTable(steps)); err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if step.Status == StatusFail {
+			return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("%s on bucket '%s' at %s failed: %s", step.Name, repo.Bucket, repo.Endpoint, step.Details))
+		}
+	}
+	env.Log.Successf("Bucket '%s' is reachable and writable", repo.Bucket)
+	return nil
+}
+
+func runLs(cliCtx *config.Context, prefix string, prefixSet, recursive bool) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := openClient(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	repo := env.Config.Elasticsearch.SnapshotRepository
+	if !prefixSet {
+		prefix = repo.BasePath
+	}
+	prefix = repositoryPrefix(prefix)
+
+	env.Log.Infof("Listing objects in bucket '%s' under '%s'...", repo.Bucket, prefix)
+	objects, err := client.ListObjects(repo.Bucket, prefix)
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list objects in bucket '%s': %w", repo.Bucket, err))
+	}
+	objects = repositoryObjects(objects, prefix, recursive)
+
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if len(objects) == 0 {
+		formatter.PrintMessage(fmt.Sprintf("No objects found in bucket '%s' under '%s'", repo.Bucket, prefix))
+		return nil
+	}
+
+	var totalBytes int64
+	for _, object := range objects {
+		totalBytes += object.Size
+	}
+	env.Log.Infof("%d object(s), %s in total", len(objects), output.FormatBytes(totalBytes))
+	return formatter.PrintTable(objectsTable(objects, prefix))
+}
+
+// openClient returns an S3 client for the snapshot repository bucket. In-cluster object storage is reached through
+// a port-forward when running outside the cluster, which is closed by the returned cleanup function.
+func openClient(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) (*s3.Client, func(), error) {
+	repo := cfg.Elasticsearch.SnapshotRepository
+
+	endpoint, cleanup, err := portforward.ServiceEndpointAddress(k8sClient, repo.Endpoint, cliCtx.Config.Namespace, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := s3.NewClient(s3.EndpointURL(repo.S3Protocol(), endpoint), repo.AccessKey, repo.SecretKey, repo.S3Region(), s3.WithReadOnly(cliCtx.Config.ReadOnly))
+	if err != nil {
+		cleanup()
+		return nil, nil, exitcode.Wrap(exitcode.ConfigError, err)
+	}
+	return client, cleanup, nil
+}
+
+// checkBucket checks that the bucket exists and a test object can be written under key and deleted again.
+// Steps that depend on a failed step are skipped.
+func checkBucket(client bucketChecker, bucket, key string) []checkStep {
+	head := timeStep(stepHeadBucket, fmt.Sprintf("bucket '%s' exists and is accessible", bucket), func() error {
+		return client.HeadBucket(bucket)
+	})
+	if head.Status == StatusFail {
+		return []checkStep{head, skipStep(stepPutObject, stepHeadBucket), skipStep(stepDeleteObject, stepHeadBucket)}
+	}
+
+	put := timeStep(stepPutObject, fmt.Sprintf("wrote '%s'", key), func() error {
+		return client.PutObject(bucket, key, []byte("sts-backup connectivity check\n"))
+	})
+	if put.Status == StatusFail {
+		return []checkStep{head, put, skipStep(stepDeleteObject, stepPutObject)}
+	}
+
+	del := timeStep(stepDeleteObject, fmt.Sprintf("deleted '%s'", key), func() error {
+		return client.DeleteObject(bucket, key)
+	})
+	return []checkStep{head, put, del}
+}
+
+// timeStep runs a request and records its outcome and latency
+func timeStep(name, success string, request func() error) checkStep {
+	start := time.Now()
+	err := request()
+	step := checkStep{Name: name, Status: StatusPass, Latency: time.Since(start), Details: success}
+	if err != nil {
+		step.Status = StatusFail
+		step.Details = err.Error()
+	}
+	return step
+}
+
+func skipStep(name, failed string) checkStep {
+	return checkStep{Name: name, Status: StatusSkip, Details: failed + " failed"}
+}
+
+// repositoryPrefix turns a repository base path into a key prefix, with a trailing slash unless it is the bucket root
+func repositoryPrefix(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return basePath + "/"
+}
+
+// repositoryObjects returns the objects under prefix, leaving out the shard data under indices/ unless recursive
+func repositoryObjects(objects []s3.Object, prefix string, recursive bool) []s3.Object {
+	result := make([]s3.Object, 0, len(objects))
+	for _, object := range objects {
+		if !recursive && strings.HasPrefix(strings.TrimPrefix(object.Key, prefix), indicesDir) {
+			continue
+		}
+		result = append(result, object)
+	}
+	return result
+}
+
+// objectKind describes what a repository object is, based on its name, or returns an empty string
+func objectKind(key, prefix string) string {
+	relative := strings.TrimPrefix(key, prefix)
+	if strings.HasPrefix(relative, indicesDir) {
+		return "index data"
+	}
+	for _, k := range repositoryObjectKinds {
+		if k.pattern.MatchString(relative) {
+			return k.kind
+		}
+	}
+	return ""
+}
+
+// objectsTable converts repository objects into a table
+func objectsTable(objects []s3.Object, prefix string) output.Table {
+	table := output.Table{
+		Headers: []string{"KEY", "KIND", "SIZE", "LAST MODIFIED"},
+		Rows:    make([][]string, 0, len(objects)),
+	}
+	for _, object := range objects {
+		table.Rows = append(table.Rows, []string{
+			object.Key,
+			objectKind(object.Key, prefix),
+			output.FormatBytes(object.Size),
+			object.LastModified.Local().Format(time.RFC3339),
+		})
+	}
+	return table
+}
+
+// stepsTable converts the check steps into a table
+func stepsTable(steps []checkStep) output.Table {
+	table := output.Table{
+		Headers:      []string{"STEP", "STATUS", "LATENCY", "DETAILS"},
+		Rows:         make([][]string, 0, len(steps)),
+		StateColumns: []string{"STATUS"},
+	}
+	for _, step := range steps {
+		latency := ""
+		if step.Status != StatusSkip {
+			latency = step.Latency.Round(time.Millisecond).String()
+		}
+		table.Rows = append(table.Rows, []string{step.Name, step.Status, latency, step.Details})
+	}
+	return table
+}
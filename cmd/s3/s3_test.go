@@ -0,0 +1,174 @@
+package s3
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockBucket struct {
+	headErr   error
+	putErr    error
+	deleteErr error
+	puts      []string
+	deletes   []string
+}
+
+func (m *mockBucket) HeadBucket(_ string) error {
+	return m.headErr
+}
+
+func (m *mockBucket) PutObject(_, key string, _ []byte) error {
+	m.puts = append(m.puts, key)
+	return m.putErr
+}
+
+func (m *mockBucket) DeleteObject(_, key string) error {
+	m.deletes = append(m.deletes, key)
+	return m.deleteErr
+}
+
+func TestCheckBucket(t *testing.T) {
+	tests := []struct {
+		name            string
+		bucket          *mockBucket
+		expectedStatus  []string
+		expectedPuts    int
+		expectedDeletes int
+	}{
+		{
+			name:            "all requests succeed",
+			bucket:          &mockBucket{},
+			expectedStatus:  []string{StatusPass, StatusPass, StatusPass},
+			expectedPuts:    1,
+			expectedDeletes: 1,
+		},
+		{
+			name:           "bucket not accessible skips the round-trip",
+			bucket:         &mockBucket{headErr: errors.New("HEAD /backups returned 403 Forbidden")},
+			expectedStatus: []string{StatusFail, StatusSkip, StatusSkip},
+		},
+		{
+			name:           "write denied skips the delete",
+			bucket:         &mockBucket{putErr: errors.New("AccessDenied")},
+			expectedStatus: []string{StatusPass, StatusFail, StatusSkip},
+			expectedPuts:   1,
+		},
+		{
+			name:            "delete denied",
+			bucket:          &mockBucket{deleteErr: errors.New("AccessDenied")},
+			expectedStatus:  []string{StatusPass, StatusPass, StatusFail},
+			expectedPuts:    1,
+			expectedDeletes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := checkBucket(tt.bucket, "backups", checkKeyPrefix+"run-1")
+
+			status := make([]string, 0, len(steps))
+			for _, step := range steps {
+				status = append(status, step.Status)
+			}
+			assert.Equal(t, tt.expectedStatus, status)
+			assert.Len(t, tt.bucket.puts, tt.expectedPuts)
+			assert.Len(t, tt.bucket.deletes, tt.expectedDeletes)
+			for _, key := range append(tt.bucket.puts, tt.bucket.deletes...) {
+				assert.Equal(t, "sts-backup-check/run-1", key)
+			}
+		})
+	}
+}
+
+func TestStepsTable(t *testing.T) {
+	steps := []checkStep{
+		{Name: stepHeadBucket, Status: StatusFail, Latency: 1234567, Details: "403 Forbidden"},
+		skipStep(stepPutObject, stepHeadBucket),
+	}
+
+	table := stepsTable(steps)
+
+	assert.Equal(t, []string{"STEP", "STATUS", "LATENCY", "DETAILS"}, table.Headers)
+	assert.Equal(t, [][]string{
+		{stepHeadBucket, StatusFail, "1ms", "403 Forbidden"},
+		{stepPutObject, StatusSkip, "", "HeadBucket failed"},
+	}, table.Rows)
+}
+
+func TestRepositoryPrefix(t *testing.T) {
+	tests := []struct {
+		basePath string
+		expected string
+	}{
+		{basePath: "", expected: ""},
+		{basePath: "/", expected: ""},
+		{basePath: "elasticsearch", expected: "elasticsearch/"},
+		{basePath: "/backups/elasticsearch/", expected: "backups/elasticsearch/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.basePath, func(t *testing.T) {
+			assert.Equal(t, tt.expected, repositoryPrefix(tt.basePath))
+		})
+	}
+}
+
+func TestRepositoryObjects(t *testing.T) {
+	objects := []s3.Object{
+		{Key: "es/index-12"},
+		{Key: "es/index.latest"},
+		{Key: "es/indices/Zk3x/0/__a1"},
+		{Key: "es/meta-abc.dat"},
+		{Key: "es/snap-abc.dat"},
+	}
+
+	t.Run("shard data left out", func(t *testing.T) {
+		result := repositoryObjects(objects, "es/", false)
+
+		keys := make([]string, 0, len(result))
+		for _, object := range result {
+			keys = append(keys, object.Key)
+		}
+		assert.Equal(t, []string{"es/index-12", "es/index.latest", "es/meta-abc.dat", "es/snap-abc.dat"}, keys)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		assert.Equal(t, objects, repositoryObjects(objects, "es/", true))
+	})
+}
+
+func TestObjectKind(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{key: "es/index-3", expected: "repository generation"},
+		{key: "es/index.latest", expected: "latest generation"},
+		{key: "es/snap-Qx1yZ.dat", expected: "snapshot info"},
+		{key: "es/meta-Qx1yZ.dat", expected: "cluster metadata"},
+		{key: "es/indices/Zk3x/meta-abc.dat", expected: "index data"},
+		{key: "es/tests-Fo0bA/master.dat", expected: "repository verification"},
+		{key: "es/index-3.tmp", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, objectKind(tt.key, "es/"))
+		})
+	}
+}
+
+func TestObjectsTable(t *testing.T) {
+	modified := time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)
+	objects := []s3.Object{{Key: "snap-abc.dat", Size: 2048, LastModified: modified}}
+
+	table := objectsTable(objects, "")
+
+	assert.Equal(t, []string{"KEY", "KIND", "SIZE", "LAST MODIFIED"}, table.Headers)
+	assert.Equal(t, [][]string{{"snap-abc.dat", "snapshot info", output.FormatBytes(2048), modified.Local().Format(time.RFC3339)}}, table.Rows)
+}
@@ -0,0 +1,191 @@
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+// serverInfoReport is the health of the MinIO deployment backing the snapshot repository
+type serverInfoReport struct {
+	Endpoint    string           `json:"endpoint"`
+	Healthy     bool             `json:"healthy"`
+	Mode        string           `json:"mode"`
+	Backend     string           `json:"backend"`
+	UsedBytes   int64            `json:"usedBytes"`
+	TotalBytes  int64            `json:"totalBytes"`
+	Objects     uint64           `json:"objects"`
+	ErasureSets []s3.ErasureSet  `json:"erasureSets"`
+	Servers     []s3.MinIOServer `json:"servers"`
+	// Problems are the findings that make the storage unhealthy
+	Problems []string `json:"problems,omitempty"`
+}
+
+func serverInfoCmd(cliCtx *config.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "server-info",
+		Short: "Show the health of the MinIO backing the snapshot repository",
+		Long: `Report the health of the object storage itself through the MinIO admin API: the state of every server and
+drive, disk usage, drives that are healing, and whether every erasure set still has its read and write quorum.
+A degraded MinIO keeps accepting snapshots until an erasure set loses its write quorum, so check it before relying
+on the backups.
+
+The credentials of elasticsearch.snapshotRepository need the admin:ServerInfo permission. Only MinIO endpoints are
+supported. Exits with code 5 when a server or drive is offline, a drive is healing or an erasure set is degraded.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runServerInfo(cliCtx); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+}
+
+func runServerInfo(cliCtx *config.Context) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := openClient(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	endpoint := env.Config.Elasticsearch.SnapshotRepository.Endpoint
+	env.Log.Infof("Requesting server info from %s...", endpoint)
+	info, err := client.ServerInfo()
+	switch {
+	case errors.Is(err, s3.ErrNotMinIO):
+		return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("%s: %w; server-info needs the MinIO admin API", endpoint, err))
+	case err != nil:
+		return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to get server info from %s: %w", endpoint, err))
+	}
+
+	report := newServerInfoReport(endpoint, info)
+	formatter := output.NewFormatter(cliCtx.Config.OutputFormat, cliCtx.RunID, output.WithOutputFile(cliCtx.Config.OutputFile))
+	if err := formatter.PrintDetail(report, serverInfoSummaryTable(report), erasureSetsTable(report.ErasureSets), drivesTable(report.Servers)); err != nil {
+		return err
+	}
+
+	if !report.Healthy {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("backup storage at %s is unhealthy: %d problem(s)", endpoint, len(report.Problems)))
+	}
+	env.Log.Successf("Backup storage at %s is healthy", endpoint)
+	return nil
+}
+
+// newServerInfoReport summarizes the server info and collects the problems
+func newServerInfoReport(endpoint string, info *s3.ServerInfo) *serverInfoReport {
+	report := &serverInfoReport{
+		Endpoint:    endpoint,
+		Mode:        info.Mode,
+		Backend:     info.Backend.Type,
+		Objects:     info.Objects.Count,
+		ErasureSets: info.ErasureSets(),
+		Servers:     info.Servers,
+	}
+	for _, server := range info.Servers {
+		if server.State != "online" {
+			report.Problems = append(report.Problems, fmt.Sprintf("server %s is %s", server.Endpoint, server.State))
+		}
+		for _, drive := range server.Drives {
+			report.UsedBytes += drive.UsedSpace
+			report.TotalBytes += drive.TotalSpace
+			switch {
+			case drive.State != s3.DriveStateOK:
+				report.Problems = append(report.Problems, fmt.Sprintf("drive %s is %s", drive.Endpoint, drive.State))
+			case drive.Healing:
+				report.Problems = append(report.Problems, fmt.Sprintf("drive %s is healing", drive.Endpoint))
+			}
+		}
+	}
+	for _, set := range report.ErasureSets {
+		if set.Status != s3.SetStatusOK {
+			report.Problems = append(report.Problems, fmt.Sprintf("erasure set %d of pool %d is %s (%d of %d drives online)",
+				set.Set, set.Pool, set.Status, set.Online, set.Drives))
+		}
+	}
+	report.Healthy = len(report.Problems) == 0
+	return report
+}
+
+func serverInfoSummaryTable(report *serverInfoReport) output.Table {
+	usage := output.FormatBytes(report.UsedBytes)
+	if report.TotalBytes > 0 {
+		usage = fmt.Sprintf("%s of %s (%.0f%%)", usage, output.FormatBytes(report.TotalBytes),
+			float64(report.UsedBytes)/float64(report.TotalBytes)*100)
+	}
+	health := "healthy"
+	if !report.Healthy {
+		health = "unhealthy"
+	}
+	rows := [][]string{
+		{"Endpoint", report.Endpoint},
+		{"Health", health},
+		{"Mode", report.Mode},
+		{"Backend", report.Backend},
+		{"Servers", strconv.Itoa(len(report.Servers))},
+		{"Disk usage", usage},
+		{"Objects", strconv.FormatUint(report.Objects, 10)},
+	}
+	for _, problem := range report.Problems {
+		rows = append(rows, []string{"Problem", problem})
+	}
+	return output.Table{Headers: []string{"FIELD", "VALUE"}, Rows: rows}
+}
+
+func erasureSetsTable(sets []s3.ErasureSet) output.Table {
+	table := output.Table{
+		Headers:      []string{"POOL", "SET", "DRIVES", "ONLINE", "HEALING", "PARITY", "STATUS"},
+		Rows:         make([][]string, 0, len(sets)),
+		StateColumns: []string{"STATUS"},
+	}
+	for _, set := range sets {
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(set.Pool),
+			strconv.Itoa(set.Set),
+			strconv.Itoa(set.Drives),
+			strconv.Itoa(set.Online),
+			strconv.Itoa(set.Healing),
+			strconv.Itoa(set.Parity),
+			set.Status,
+		})
+	}
+	return table
+}
+
+func drivesTable(servers []s3.MinIOServer) output.Table {
+	table := output.Table{
+		Headers:      []string{"SERVER", "UPTIME", "DRIVE", "POOL", "SET", "STATE", "HEALING", "USED", "AVAILABLE"},
+		StateColumns: []string{"STATE"},
+	}
+	for _, server := range servers {
+		uptime := (time.Duration(server.Uptime) * time.Second).String()
+		for _, drive := range server.Drives {
+			table.Rows = append(table.Rows, []string{
+				server.Endpoint,
+				uptime,
+				drive.Endpoint,
+				strconv.Itoa(drive.PoolIndex),
+				strconv.Itoa(drive.SetIndex),
+				drive.State,
+				strconv.FormatBool(drive.Healing),
+				output.FormatBytes(drive.UsedSpace),
+				output.FormatBytes(drive.AvailSpace),
+			})
+		}
+	}
+	return table
+}
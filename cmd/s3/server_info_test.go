@@ -0,0 +1,51 @@
+package s3
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewServerInfoReport(t *testing.T) {
+	info := &s3.ServerInfo{Mode: "online", Backend: s3.MinIOBackend{Type: "Erasure", StandardSCParity: 1}}
+	info.Servers = []s3.MinIOServer{
+		{State: "online", Endpoint: "minio-0:9000", Drives: []s3.MinIODrive{
+			{Endpoint: "/data0", State: "ok", TotalSpace: 1000, UsedSpace: 250},
+			{Endpoint: "/data1", State: "ok", TotalSpace: 1000, UsedSpace: 250},
+		}},
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		report := newServerInfoReport("minio:9000", info)
+
+		assert.True(t, report.Healthy)
+		assert.Empty(t, report.Problems)
+		assert.Equal(t, int64(500), report.UsedBytes)
+		assert.Equal(t, int64(2000), report.TotalBytes)
+		assert.Contains(t, serverInfoSummaryTable(report).Rows, []string{"Disk usage", "500 B of 2.0 KiB (25%)"})
+	})
+
+	t.Run("healing drive", func(t *testing.T) {
+		info.Servers[0].Drives[1].Healing = true
+
+		report := newServerInfoReport("minio:9000", info)
+
+		assert.False(t, report.Healthy)
+		assert.Equal(t, []string{"drive /data1 is healing", "erasure set 0 of pool 0 is degraded (2 of 2 drives online)"}, report.Problems)
+	})
+
+	t.Run("offline server and drive", func(t *testing.T) {
+		info.Servers[0].State = "offline"
+		info.Servers[0].Drives[1] = s3.MinIODrive{Endpoint: "/data1", State: "offline"}
+
+		report := newServerInfoReport("minio:9000", info)
+
+		assert.Equal(t, []string{
+			"server minio-0:9000 is offline",
+			"drive /data1 is offline",
+			"erasure set 0 of pool 0 is read-only (1 of 2 drives online)",
+		}, report.Problems)
+		assert.Equal(t, []string{"0", "0", "2", "1", "0", "1", "read-only"}, erasureSetsTable(report.ErasureSets).Rows[0])
+	})
+}
@@ -0,0 +1,328 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	catalogcmd "github.com/stackvista/stackstate-backup-cli/cmd/catalog"
+	escmd "github.com/stackvista/stackstate-backup-cli/cmd/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/api"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/monitor"
+	"github.com/stackvista/stackstate-backup-cli/internal/notify"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+const (
+	// staleCheckIntervals is the number of missed check intervals after which /healthz fails
+	staleCheckIntervals = 3
+	// readHeaderTimeout bounds reading request headers of the HTTP server
+	readHeaderTimeout = 10 * time.Second
+	// shutdownTimeout bounds the graceful shutdown of the HTTP server
+	shutdownTimeout = 5 * time.Second
+	// apiTokenEnvVar is the environment variable holding the API bearer token when no token file is given
+	apiTokenEnvVar = "STS_BACKUP_API_TOKEN"
+)
+
+// serveOptions holds the flags of the serve command
+type serveOptions struct {
+	Listen         string
+	Interval       time.Duration
+	MaxSnapshotAge time.Duration
+	API            bool
+	APITokenFile   string
+	Catalog        bool
+}
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	opts := &serveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Continuously monitor backup health",
+		Long: `Run as a long-lived process (typically in-cluster) that periodically checks the age of the last
+successful snapshot, SLM policy failures and snapshot repository health.
+
+Results are exposed as Prometheus metrics on /metrics; /healthz reports whether checks are still running.
+Configured notifications are sent when a threshold is breached and when backup health recovers.
+After every check, manifests of new snapshots are written to the backup catalog (see 'catalog list').
+
+With --api an HTTP API is served on the same address to list snapshots, trigger a backup and run restores.
+Every API request must carry the token from --api-token-file (or ` + apiTokenEnvVar + `) as bearer token.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runServe(cliCtx, opts); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Listen, "listen", ":9090", "Address to serve /metrics and /healthz on")
+	cmd.Flags().DurationVar(&opts.Interval, "interval", 5*time.Minute, "Interval between health checks")
+	cmd.Flags().DurationVar(&opts.MaxSnapshotAge, "max-snapshot-age", 25*time.Hour, "Maximum age of the last successful snapshot before alerting")
+	cmd.Flags().BoolVar(&opts.Catalog, "catalog", true, "Record new snapshots in the backup catalog after every check")
+	cmd.Flags().BoolVar(&opts.API, "api", false, "Serve the HTTP API for listing snapshots, triggering backups and restores under /api/v1")
+	cmd.Flags().StringVar(&opts.APITokenFile, "api-token-file", "", "File containing the API bearer token (default: $"+apiTokenEnvVar+")")
+
+	return cmd
+}
+
+func runServe(cliCtx *config.Context, opts *serveOptions) error {
+	if opts.Interval <= 0 {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("--interval must be positive"))
+	}
+
+	var apiToken string
+	if opts.API {
+		token, err := readAPIToken(opts.APITokenFile)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+		apiToken = token
+	}
+
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	esClient, cleanup, err := connectElasticsearch(env.K8s, cliCtx, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	syncCatalog, closeCatalog := func() {}, func() {}
+	if opts.Catalog {
+		syncCatalog, closeCatalog = catalogSync(env.K8s, cliCtx, env.Config, esClient, env.Log)
+	}
+	defer closeCatalog()
+
+	mon := monitor.New(esClient,
+		monitor.Target{Repository: env.Config.Elasticsearch.SLM.Repository, SLMPolicy: env.Config.Elasticsearch.SLM.Name},
+		monitor.Thresholds{MaxSnapshotAge: opts.MaxSnapshotAge},
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", mon.Handler(staleCheckIntervals*opts.Interval))
+	var apiServer *api.Server
+	if opts.API {
+		apiServer = api.NewServer(&apiBackend{esClient: esClient, cfg: env.Config, cliCtx: cliCtx}, apiToken, config.NewRunID)
+		mux.Handle("/api/", apiServer.Handler())
+	}
+
+	server := &http.Server{
+		Addr:              opts.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	env.Log.Infof("Serving /metrics and /healthz on %s (checking every %s)", opts.Listen, opts.Interval)
+	if opts.API {
+		env.Log.Infof("Serving the HTTP API on %s/api/v1", opts.Listen)
+	}
+
+	alerts := &alertState{}
+	runCheck := func() {
+		result := mon.Check(time.Now())
+		logResult(result, env.Log)
+		if alerts.update(result.Healthy()) {
+			notifyTransition(env.Config, cliCtx, result, env.Log)
+		}
+		syncCatalog()
+	}
+
+	runCheck()
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			runCheck()
+		case err := <-serverErr:
+			return exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("HTTP server failed: %w", err))
+		case <-ctx.Done():
+			return shutdown(server, apiServer, env.Log)
+		}
+	}
+}
+
+// shutdown stops the HTTP server and waits for a restore started through the API to finish,
+// so deployments are scaled back up before the process exits
+func shutdown(server *http.Server, apiServer *api.Server, log *logger.Logger) error {
+	log.Infof("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down HTTP server: %w", err)
+	}
+
+	if apiServer != nil {
+		if id := apiServer.Running(); id != "" {
+			log.Infof("Waiting for restore %s to finish...", id)
+		}
+		apiServer.Wait()
+	}
+	return nil
+}
+
+// connectElasticsearch creates an Elasticsearch client. In-cluster the service is reached directly,
+// otherwise through a port-forward that is closed by the returned cleanup function. The port-forward
+// uses a free local port, leaving the configured one to restores started through the API.
+func connectElasticsearch(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) (*elasticsearch.Client, func(), error) {
+	namespace := cliCtx.Config.Namespace
+	esOpts := target.ElasticsearchOptions(k8sClient, cliCtx, cfg, log)
+
+	service := cfg.Elasticsearch.Service
+	if k8s.InCluster() {
+		esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://%s.%s.svc:%d", service.SnapshotServiceName(), namespace, service.Port), esOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create Elasticsearch client: %w", err)
+		}
+		return esClient, func() {}, nil
+	}
+
+	localPort, err := portforward.FreeLocalPort()
+	if err != nil {
+		return nil, nil, err
+	}
+	pf, err := portforward.SetupPortForward(k8sClient, namespace, service.SnapshotServiceName(), localPort, service.Port, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	esClient, err := elasticsearch.NewClient(fmt.Sprintf("http://localhost:%d", pf.LocalPort), esOpts...)
+	if err != nil {
+		close(pf.StopChan)
+		return nil, nil, fmt.Errorf("failed to create Elasticsearch client: %w", err)
+	}
+	return esClient, func() { close(pf.StopChan) }, nil
+}
+
+// catalogSync returns a function that records new snapshots in the backup catalog, and a function
+// closing the connection to the object storage. When the catalog cannot be opened, syncing is disabled.
+func catalogSync(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, esClient *elasticsearch.Client, log *logger.Logger) (func(), func()) {
+	cat, cleanup, err := catalogcmd.Open(k8sClient, cliCtx, cfg, log)
+	if err != nil {
+		log.Warningf("Backup catalog disabled: %v", err)
+		return func() {}, func() {}
+	}
+
+	meta := catalogcmd.Metadata(k8sClient, cliCtx)
+	return func() {
+		added, err := cat.SyncElasticsearch(esClient, cfg.Elasticsearch.SLM.Repository, meta, time.Now())
+		for _, name := range added {
+			log.Infof("Recorded snapshot '%s' in the backup catalog", name)
+		}
+		if err != nil {
+			log.Warningf("Failed to update backup catalog: %v", err)
+		}
+	}, cleanup
+}
+
+// logResult logs the outcome of a health check
+func logResult(result *monitor.Result, log *logger.Logger) {
+	if result.Healthy() {
+		log.Debugf("Backup health check passed")
+		return
+	}
+	for _, problem := range result.Problems() {
+		log.Warningf("%s", problem)
+	}
+}
+
+// alertState tracks backup health between checks so notifications are only sent on changes
+type alertState struct {
+	observed bool
+	healthy  bool
+}
+
+// update records the latest health and reports whether a notification should be sent:
+// when health changes, or when the very first check is already unhealthy
+func (a *alertState) update(healthy bool) bool {
+	changed := (a.observed && a.healthy != healthy) || (!a.observed && !healthy)
+	a.observed = true
+	a.healthy = healthy
+	return changed
+}
+
+// notifyTransition notifies the configured targets that backup health was breached or recovered
+func notifyTransition(cfg *config.Config, cliCtx *config.Context, result *monitor.Result, log *logger.Logger) {
+	notifier := notify.New(cfg.Notifications)
+	if !notifier.Enabled() {
+		return
+	}
+
+	var problem error
+	if !result.Healthy() {
+		problem = errors.New(strings.Join(result.Problems(), "; "))
+	}
+	payload := notify.NewPayload(cliCtx.RunID, "backup-health", cliCtx.Config.Namespace, result.CheckedAt, problem, nil)
+	if err := notifier.Send(payload); err != nil {
+		log.Warningf("Failed to send backup health notification: %v", err)
+	}
+}
+
+// readAPIToken reads the API bearer token from tokenFile, or from the environment when no file is given
+func readAPIToken(tokenFile string) (string, error) {
+	token := os.Getenv(apiTokenEnvVar)
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read API token: %w", err)
+		}
+		token = string(data)
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("--api requires a token: use --api-token-file or set %s", apiTokenEnvVar)
+	}
+	return token, nil
+}
+
+// apiBackend performs API operations against the configured repository and SLM policy
+type apiBackend struct {
+	esClient *elasticsearch.Client
+	cfg      *config.Config
+	cliCtx   *config.Context
+}
+
+func (b *apiBackend) ListSnapshots() ([]elasticsearch.Snapshot, error) {
+	return b.esClient.ListSnapshots(b.cfg.Elasticsearch.Restore.Repository)
+}
+
+// TriggerBackup executes the SLM policy, inside of the backup window unless force is set
+func (b *apiBackend) TriggerBackup(force bool) (string, error) {
+	if !force {
+		if err := b.cfg.Elasticsearch.BackupWindow.Check(time.Now()); err != nil {
+			return "", err
+		}
+	}
+	return b.esClient.ExecuteSLMPolicy(b.cfg.Elasticsearch.SLM.Name)
+}
+
+// Restore runs the same restore as the restore-snapshot command, with runID as its run ID
+func (b *apiBackend) Restore(runID, snapshotName string, dropAllIndices bool) error {
+	return escmd.Restore(&config.Context{Config: b.cliCtx.Config, RunID: runID}, escmd.RestoreRequest{SnapshotName: snapshotName, DropAllIndices: dropAllIndices})
+}
@@ -0,0 +1,95 @@
+package serve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServeCmd_Unit tests the command structure
+func TestServeCmd_Unit(t *testing.T) {
+	cmd := Cmd(config.NewContext())
+
+	assert.Equal(t, "serve", cmd.Use)
+	assert.Equal(t, "Continuously monitor backup health", cmd.Short)
+	assert.NotNil(t, cmd.Run)
+	for _, flag := range []string{"listen", "interval", "max-snapshot-age", "catalog", "api", "api-token-file"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), flag)
+	}
+}
+
+func TestRunServe_InvalidInterval(t *testing.T) {
+	err := runServe(config.NewContext(), &serveOptions{Interval: 0})
+
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
+
+func TestRunServe_APIWithoutToken(t *testing.T) {
+	t.Setenv(apiTokenEnvVar, "")
+
+	err := runServe(config.NewContext(), &serveOptions{Interval: time.Minute, API: true})
+
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	assert.Contains(t, err.Error(), "--api requires a token")
+}
+
+func TestReadAPIToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("from-file\n"), 0o600))
+
+	tests := []struct {
+		name          string
+		env           string
+		tokenFile     string
+		expectedToken string
+		expectError   bool
+	}{
+		{name: "from environment", env: "from-env", expectedToken: "from-env"},
+		{name: "file takes precedence", env: "from-env", tokenFile: tokenFile, expectedToken: "from-file"},
+		{name: "missing file", tokenFile: filepath.Join(t.TempDir(), "missing"), expectError: true},
+		{name: "no token", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(apiTokenEnvVar, tt.env)
+
+			token, err := readAPIToken(tt.tokenFile)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedToken, token)
+		})
+	}
+}
+
+func TestAlertState_Update(t *testing.T) {
+	tests := []struct {
+		name     string
+		health   []bool
+		expected []bool
+	}{
+		{name: "healthy from the start", health: []bool{true, true}, expected: []bool{false, false}},
+		{name: "unhealthy from the start", health: []bool{false, false}, expected: []bool{true, false}},
+		{name: "breach and recovery", health: []bool{true, false, false, true, true}, expected: []bool{false, true, false, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &alertState{}
+			for i, healthy := range tt.health {
+				assert.Equal(t, tt.expected[i], state.update(healthy), "check %d", i)
+			}
+		})
+	}
+}
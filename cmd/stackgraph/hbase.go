@@ -0,0 +1,391 @@
+package stackgraph
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/hbase"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+const (
+	// defaultMappers is the number of parallel copies of an export or import
+	defaultMappers = 4
+	// defaultStatefulSetTimeout bounds waiting for a StatefulSet to scale down or up
+	defaultStatefulSetTimeout = 10 * time.Minute
+)
+
+// hbaseCluster takes, exports and restores the snapshots of an HBase cluster
+type hbaseCluster interface {
+	Tables() ([]string, error)
+	Snapshots() ([]hbase.Snapshot, error)
+	CreateSnapshots(snapshots []hbase.Snapshot) error
+	ExportSnapshot(name string, to hbase.S3Location, mappers int) error
+	RootDir() (string, error)
+	ImportSnapshot(name string, from hbase.S3Location, rootDir string, mappers int) error
+	RestoreSnapshots(snapshots []hbase.Snapshot) error
+}
+
+// objectLister lists the objects in the backup bucket
+type objectLister interface {
+	ListObjects(bucket, prefix string) ([]s3.Object, error)
+}
+
+func hbaseCmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hbase",
+		Short: "Snapshot, export and restore the HBase tables of StackGraph",
+		Long: `Take HBase snapshots of the StackGraph tables, export them to the backup bucket with ExportSnapshot and restore
+them. The snapshots of the tables taken together form a set, identified by the time it was taken (e.g.
+20260301T113000Z), that is exported and restored as a whole. The HBase shell and ExportSnapshot are run in a Ready
+HBase pod, like kubectl exec.`,
+	}
+
+	cmd.AddCommand(snapshotCmd(cliCtx))
+	cmd.AddCommand(exportCmd(cliCtx))
+	cmd.AddCommand(restoreCmd(cliCtx))
+	return cmd
+}
+
+func snapshotCmd(cliCtx *config.Context) *cobra.Command {
+	var tables []string
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Take a set of HBase snapshots of the StackGraph tables",
+		Long: `Take a snapshot of each table in the cluster: the tables of stackgraph.tables, or all tables outside the hbase
+namespace when none are configured. Snapshots are named sts-backup-<set>-<table>. Export them to the backup bucket
+with 'stackgraph hbase export'.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runSnapshot(cliCtx, tables); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringSliceVar(&tables, "table", nil, "Table to snapshot, repeatable (default: stackgraph.tables or all tables)")
+	return cmd
+}
+
+func exportCmd(cliCtx *config.Context) *cobra.Command {
+	var setID string
+	var mappers int
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a set of HBase snapshots to the backup bucket",
+		Long: `Copy the snapshots of a set and the files they reference to the backup bucket, under stackgraph.exportPrefix,
+with ExportSnapshot. The most recent set in the cluster is exported unless --set is given. The bucket is reached
+through the endpoint of the snapshot repository from inside the HBase pod; the credentials are passed to
+ExportSnapshot in its environment.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runExport(cliCtx, setID, mappers); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&setID, "set", "", "Set of snapshots to export (default: the most recent set)")
+	cmd.Flags().IntVar(&mappers, "mappers", defaultMappers, "Number of files copied in parallel")
+	return cmd
+}
+
+func restoreCmd(cliCtx *config.Context) *cobra.Command {
+	var setID string
+	var mappers int
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore the StackGraph tables from a set of exported HBase snapshots",
+		Long: `Restore the tables from a set of snapshots exported to the backup bucket. The most recent exported set is
+restored unless --set is given. Snapshots of the set that are not in the cluster are imported from the bucket first.
+
+The region servers are quiesced before the tables are replaced: the Deployments selected by
+stackgraph.scaleDownLabelSelector and then the StatefulSets selected by stackgraph.scaleDownStatefulSetSelectors are
+scaled down. Each table is disabled, restored from its snapshot and enabled again; missing tables are created. The
+Deployments and StatefulSets are scaled back up afterwards, also when the restore fails.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := runRestore(cliCtx, setID, mappers); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "error: %s\n", redact.Error(err))
+				os.Exit(exitcode.Of(err))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&setID, "set", "", "Set of snapshots to restore (default: the most recent exported set)")
+	cmd.Flags().IntVar(&mappers, "mappers", defaultMappers, "Number of files copied in parallel when importing snapshots")
+	return cmd
+}
+
+func runSnapshot(cliCtx *config.Context, tables []string) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	client, err := connectHBase(env)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		tables = env.Config.StackGraph.Tables
+	}
+
+	_, err = takeSnapshots(client, tables, hbase.NewSetID(time.Now()), env.Log)
+	return err
+}
+
+func runExport(cliCtx *config.Context, setID string, mappers int) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	client, err := connectHBase(env)
+	if err != nil {
+		return err
+	}
+
+	return exportSet(client, setID, exportLocation(env.Config), mappers, env.Log)
+}
+
+func runRestore(cliCtx *config.Context, setID string, mappers int) error {
+	env, err := target.Connect(cliCtx)
+	if err != nil {
+		return err
+	}
+	return restoreExportedSet(env, setID, mappers, prompt.New(cliCtx.Config.AssumeYes))
+}
+
+// restoreExportedSet imports the exported set setID, or the most recent exported set when setID is empty, and
+// restores it after confirmation while the installation is scaled down. The restore is recorded in the audit log.
+func restoreExportedSet(env *target.Env, setID string, mappers int, prompter *prompt.Prompter) (err error) {
+	location := exportLocation(env.Config)
+
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	setID, names, err := selectExportedSet(bucket, location, setID)
+	cleanup()
+	if err != nil {
+		return err
+	}
+
+	client, err := connectHBase(env)
+	if err != nil {
+		return err
+	}
+
+	if err := prompter.Confirm(fmt.Sprintf("Replace the StackGraph tables with the %d snapshot(s) of set %s?", len(names), setID)); err != nil {
+		return fmt.Errorf("restore aborted: %w", err)
+	}
+
+	startedAt := time.Now()
+	defer func() {
+		err = target.RecordAudit(env.K8s, env.CLI, audit.Entry{
+			Operation:      "stackgraph-restore",
+			Snapshot:       setID,
+			Repository:     location.URI(),
+			DurationMillis: time.Since(startedAt).Milliseconds(),
+		}, err, env.Log)
+	}()
+
+	// Import before quiescing, so the installation is only down for the restore itself
+	snapshots, err := importSet(client, names, location, mappers, env.Log)
+	if err != nil {
+		return err
+	}
+
+	cfg := env.Config.StackGraph
+	namespace := env.CLI.Config.Namespace
+	scaledDeployments, err := target.ScaleDownDeployments(env.K8s, namespace, cfg.ScaleDownLabelSelector, env.Log)
+	if err != nil {
+		return err
+	}
+	defer target.ScaleUpDeployments(env.K8s, namespace, scaledDeployments, env.Log)
+	scaledStatefulSets, err := target.ScaleDownStatefulSets(env.K8s, namespace, cfg.ScaleDownStatefulSetSelectors, defaultStatefulSetTimeout, env.Log)
+	defer target.ScaleUpStatefulSets(env.K8s, namespace, scaledStatefulSets, defaultStatefulSetTimeout, env.Log)
+	if err != nil {
+		return err
+	}
+
+	return restoreSet(client, setID, snapshots, env.Log)
+}
+
+// takeSnapshots takes a snapshot of each table, or of all tables when none are given, as set setID
+func takeSnapshots(cluster hbaseCluster, tables []string, setID string, log *logger.Logger) ([]hbase.Snapshot, error) {
+	if len(tables) == 0 {
+		var err error
+		if tables, err = cluster.Tables(); err != nil {
+			return nil, err
+		}
+	}
+	if len(tables) == 0 {
+		return nil, exitcode.Wrap(exitcode.ValidationFailed, errors.New("no tables found to snapshot"))
+	}
+
+	snapshots := make([]hbase.Snapshot, 0, len(tables))
+	for _, table := range tables {
+		snapshots = append(snapshots, hbase.Snapshot{Name: hbase.SnapshotName(setID, table), Table: table})
+	}
+
+	log.Infof("Taking %d snapshot(s) as set %s...", len(snapshots), setID)
+	if err := cluster.CreateSnapshots(snapshots); err != nil {
+		return nil, err
+	}
+	log.Successf("Took set %s:", setID)
+	for _, snapshot := range snapshots {
+		log.Infof("  - %s (table %s)", snapshot.Name, snapshot.Table)
+	}
+	return snapshots, nil
+}
+
+// exportSet exports the snapshots of set setID, or of the most recent set when setID is empty, to the location
+func exportSet(cluster hbaseCluster, setID string, location hbase.S3Location, mappers int, log *logger.Logger) error {
+	snapshots, err := cluster.Snapshots()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	setID, names, err = selectSet(names, setID, "'stackgraph hbase snapshot'")
+	if err != nil {
+		return err
+	}
+
+	for i, name := range names {
+		log.Infof("Exporting snapshot %s to %s (%d/%d)...", name, location.URI(), i+1, len(names))
+		if err := cluster.ExportSnapshot(name, location, mappers); err != nil {
+			return err
+		}
+	}
+	log.Successf("Exported the %d snapshot(s) of set %s to %s", len(names), setID, location.URI())
+	return nil
+}
+
+// selectExportedSet returns the ID and snapshot names of exported set setID, or of the most recent exported set when
+// setID is empty
+func selectExportedSet(bucket objectLister, location hbase.S3Location, setID string) (string, []string, error) {
+	names, err := exportedSnapshots(bucket, location)
+	if err != nil {
+		return "", nil, err
+	}
+	return selectSet(names, setID, "'stackgraph hbase export'")
+}
+
+// exportedSnapshots returns the names of the snapshots exported to the location
+func exportedSnapshots(bucket objectLister, location hbase.S3Location) ([]string, error) {
+	objects, err := bucket.ListObjects(location.Bucket, location.SnapshotInfoPrefix())
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, object := range objects {
+		if name, ok := location.ExportedSnapshot(object.Key); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// selectSet returns the ID and the sorted snapshot names of set setID, or of the most recent set when setID is empty.
+// create names the command creating sets, for the error when there are none.
+func selectSet(names []string, setID, create string) (string, []string, error) {
+	sets := map[string][]string{}
+	for _, name := range names {
+		if id, ok := hbase.SetID(name); ok {
+			sets[id] = append(sets[id], name)
+		}
+	}
+	if len(sets) == 0 {
+		return "", nil, exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("no snapshot sets found: run %s first", create))
+	}
+
+	ids := slices.Sorted(maps.Keys(sets))
+	if setID == "" {
+		setID = ids[len(ids)-1]
+	}
+	setNames, ok := sets[setID]
+	if !ok {
+		return "", nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("snapshot set %s not found, available: %s", setID, strings.Join(ids, ", ")))
+	}
+	slices.Sort(setNames)
+	return setID, setNames, nil
+}
+
+// importSet imports the snapshots that are not in the cluster from the location and returns the snapshots with
+// their tables
+func importSet(cluster hbaseCluster, names []string, location hbase.S3Location, mappers int, log *logger.Logger) ([]hbase.Snapshot, error) {
+	present, err := snapshotsByName(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := present[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		rootDir, err := cluster.RootDir()
+		if err != nil {
+			return nil, err
+		}
+		for i, name := range missing {
+			log.Infof("Importing snapshot %s from %s (%d/%d)...", name, location.URI(), i+1, len(missing))
+			if err := cluster.ImportSnapshot(name, location, rootDir, mappers); err != nil {
+				return nil, err
+			}
+		}
+		if present, err = snapshotsByName(cluster); err != nil {
+			return nil, err
+		}
+	}
+
+	snapshots := make([]hbase.Snapshot, 0, len(names))
+	for _, name := range names {
+		snapshot, ok := present[name]
+		if !ok {
+			return nil, fmt.Errorf("snapshot %s is not in the cluster after importing it", name)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// snapshotsByName returns the snapshots taken by the CLI in the cluster by name
+func snapshotsByName(cluster hbaseCluster) (map[string]hbase.Snapshot, error) {
+	snapshots, err := cluster.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]hbase.Snapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byName[snapshot.Name] = snapshot
+	}
+	return byName, nil
+}
+
+// restoreSet replaces the tables with the contents of the snapshots of a set
+func restoreSet(cluster hbaseCluster, setID string, snapshots []hbase.Snapshot, log *logger.Logger) error {
+	log.Infof("Restoring %d table(s) from set %s...", len(snapshots), setID)
+	if err := cluster.RestoreSnapshots(snapshots); err != nil {
+		return err
+	}
+	log.Successf("Restored set %s:", setID)
+	for _, snapshot := range snapshots {
+		log.Infof("  - %s (from %s)", snapshot.Table, snapshot.Name)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package stackgraph
+
+import (
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/hbase"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster is an HBase cluster that keeps its snapshots in memory and records what was exported and imported
+type fakeCluster struct {
+	tables    []string
+	snapshots []hbase.Snapshot
+	// exported holds the tables of the snapshots in the bucket, by name
+	exported map[string]string
+	imported []string
+	restored []hbase.Snapshot
+}
+
+func (f *fakeCluster) Tables() ([]string, error) {
+	return f.tables, nil
+}
+
+func (f *fakeCluster) Snapshots() ([]hbase.Snapshot, error) {
+	return f.snapshots, nil
+}
+
+func (f *fakeCluster) CreateSnapshots(snapshots []hbase.Snapshot) error {
+	f.snapshots = append(f.snapshots, snapshots...)
+	return nil
+}
+
+func (f *fakeCluster) ExportSnapshot(name string, _ hbase.S3Location, _ int) error {
+	for _, snapshot := range f.snapshots {
+		if snapshot.Name == name {
+			f.exported[name] = snapshot.Table
+		}
+	}
+	return nil
+}
+
+func (f *fakeCluster) RootDir() (string, error) {
+	return "hdfs://hdfs-nn:9000/hbase", nil
+}
+
+func (f *fakeCluster) ImportSnapshot(name string, _ hbase.S3Location, _ string, _ int) error {
+	f.imported = append(f.imported, name)
+	f.snapshots = append(f.snapshots, hbase.Snapshot{Name: name, Table: f.exported[name]})
+	return nil
+}
+
+func (f *fakeCluster) RestoreSnapshots(snapshots []hbase.Snapshot) error {
+	f.restored = snapshots
+	return nil
+}
+
+// exportedObjects lists the snapshots exported from a fakeCluster like S3 does
+type exportedObjects struct {
+	cluster  *fakeCluster
+	location hbase.S3Location
+}
+
+func (e exportedObjects) ListObjects(_, _ string) ([]s3.Object, error) {
+	objects := []s3.Object{{Key: e.location.SnapshotInfoPrefix() + ".tmp/sts-backup-20260302T000000Z-stackgraph/.snapshotinfo"}}
+	for name := range e.cluster.exported {
+		objects = append(objects,
+			s3.Object{Key: e.location.SnapshotInfoPrefix() + name + "/.snapshotinfo"},
+			s3.Object{Key: e.location.SnapshotInfoPrefix() + name + "/data.manifest"})
+	}
+	return objects, nil
+}
+
+func TestSnapshotExportAndRestore(t *testing.T) {
+	log := logger.New(logger.LevelError, "")
+	location := hbase.S3Location{Bucket: "sts-backup", Prefix: "hbase"}
+	cluster := &fakeCluster{tables: []string{"stackgraph", "tephra"}, exported: map[string]string{}}
+
+	_, err := takeSnapshots(cluster, nil, "20260301T000000Z", log)
+	require.NoError(t, err)
+	snapshots, err := takeSnapshots(cluster, []string{"stackgraph"}, "20260301T120000Z", log)
+	require.NoError(t, err)
+	assert.Equal(t, []hbase.Snapshot{{Name: "sts-backup-20260301T120000Z-stackgraph", Table: "stackgraph"}}, snapshots)
+
+	require.NoError(t, exportSet(cluster, "20260301T000000Z", location, 4, log))
+	assert.Len(t, cluster.exported, 2)
+	require.NoError(t, exportSet(cluster, "", location, 4, log))
+	assert.Contains(t, cluster.exported, "sts-backup-20260301T120000Z-stackgraph", "the most recent set is exported by default")
+
+	setID, names, err := selectExportedSet(exportedObjects{cluster: cluster, location: location}, location, "20260301T000000Z")
+	require.NoError(t, err)
+	assert.Equal(t, "20260301T000000Z", setID)
+	assert.Equal(t, []string{"sts-backup-20260301T000000Z-stackgraph", "sts-backup-20260301T000000Z-tephra"}, names)
+
+	// Restore into a cluster that only has one of the snapshots
+	restoreCluster := &fakeCluster{snapshots: []hbase.Snapshot{cluster.snapshots[0]}, exported: cluster.exported}
+	restoreSnapshots, err := importSet(restoreCluster, names, location, 4, log)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sts-backup-20260301T000000Z-tephra"}, restoreCluster.imported, "snapshots in the cluster are not imported")
+	require.NoError(t, restoreSet(restoreCluster, setID, restoreSnapshots, log))
+	assert.Equal(t, []hbase.Snapshot{
+		{Name: "sts-backup-20260301T000000Z-stackgraph", Table: "stackgraph"},
+		{Name: "sts-backup-20260301T000000Z-tephra", Table: "tephra"},
+	}, restoreCluster.restored)
+}
+
+func TestSelectSet_Errors(t *testing.T) {
+	_, _, err := selectSet([]string{"manual"}, "", "'stackgraph hbase snapshot'")
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+
+	_, _, err = selectSet([]string{"sts-backup-20260301T000000Z-stackgraph"}, "20200101T000000Z", "'stackgraph hbase snapshot'")
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	assert.ErrorContains(t, err, "available: 20260301T000000Z")
+
+	_, err = takeSnapshots(&fakeCluster{}, nil, "20260301T000000Z", logger.New(logger.LevelError, ""))
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+}
+
+func TestVerifyExportedSet(t *testing.T) {
+	location := hbase.S3Location{Bucket: "sts-backup", Prefix: "hbase"}
+	cluster := &fakeCluster{exported: map[string]string{
+		"sts-backup-20260301T000000Z-stackgraph": "stackgraph",
+		"sts-backup-20260301T000000Z-tephra":     "tephra",
+		"sts-backup-20260302T000000Z-stackgraph": "stackgraph",
+	}}
+	bucket := exportedObjects{cluster: cluster, location: location}
+
+	require.NoError(t, verifyExportedSet(bucket, location, "20260301T000000Z", []string{"stackgraph", "tephra"}))
+	require.NoError(t, verifyExportedSet(bucket, location, "20260302T000000Z", nil), "without configured tables any exported set passes")
+
+	err := verifyExportedSet(bucket, location, "20260302T000000Z", []string{"stackgraph", "tephra"})
+	assert.Equal(t, exitcode.ValidationFailed, exitcode.Of(err))
+	assert.ErrorContains(t, err, "table(s) tephra")
+
+	err = verifyExportedSet(bucket, location, "20200101T000000Z", nil)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
@@ -0,0 +1,66 @@
+package stackgraph
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/hbase"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+func Cmd(cliCtx *config.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stackgraph",
+		Short: "StackGraph backup and restore operations",
+		Long: `Operations on StackGraph, the topology store of the installation, located with the stackgraph section of the
+configuration.`,
+	}
+
+	cmd.AddCommand(hbaseCmd(cliCtx))
+	return cmd
+}
+
+// podRunner runs the HBase tools in a container of an HBase pod
+type podRunner struct {
+	k8sClient k8s.Interface
+	namespace string
+	pod       string
+	container string
+}
+
+// Stream implements hbase.Runner
+func (r *podRunner) Stream(command []string, stdin io.Reader, stdout io.Writer) error {
+	return r.k8sClient.ExecPodStream(r.namespace, r.pod, r.container, command, stdin, stdout)
+}
+
+// connectHBase returns a client running the HBase tools in a Ready HBase pod
+func connectHBase(env *target.Env) (*hbase.Client, error) {
+	cfg := env.Config.StackGraph
+	pod, err := env.K8s.ReadyPod(env.CLI.Config.Namespace, cfg.PodSelector)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to find an HBase pod: %w", err))
+	}
+	env.Log.Debugf("Running the HBase tools in pod %s", pod)
+
+	runner := &podRunner{k8sClient: env.K8s, namespace: env.CLI.Config.Namespace, pod: pod, container: cfg.Container}
+	return hbase.NewClient(runner), nil
+}
+
+// exportLocation returns the location in the backup bucket snapshots are exported to, as reached from the HBase pod
+// through the in-cluster endpoint of the snapshot repository
+func exportLocation(cfg *config.Config) hbase.S3Location {
+	repo := cfg.Elasticsearch.SnapshotRepository
+	return hbase.S3Location{
+		Endpoint:  s3.EndpointURL(repo.S3Protocol(), repo.Endpoint),
+		Region:    repo.S3Region(),
+		Bucket:    repo.Bucket,
+		Prefix:    cfg.StackGraph.ExportPrefix,
+		AccessKey: repo.AccessKey,
+		SecretKey: repo.SecretKey,
+	}
+}
@@ -0,0 +1,130 @@
+package stackgraph
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/cmd/target"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/hbase"
+	"github.com/stackvista/stackstate-backup-cli/internal/prompt"
+)
+
+// Target returns StackGraph as a backup target: backups are snapshot sets exported to the backup bucket, named by
+// their set ID, and restores run like stackgraph hbase restore --yes
+func Target() target.BackupTarget {
+	return stackgraphTarget{}
+}
+
+type stackgraphTarget struct{}
+
+func (stackgraphTarget) Name() string {
+	return "stackgraph"
+}
+
+// Configure checks that the configured tables exist; snapshots need no further setup
+func (stackgraphTarget) Configure(env *target.Env) error {
+	client, err := connectHBase(env)
+	if err != nil {
+		return err
+	}
+	tables, err := client.Tables()
+	if err != nil {
+		return exitcode.Wrap(exitcode.ConnectivityError, err)
+	}
+	for _, table := range env.Config.StackGraph.Tables {
+		if !slices.Contains(tables, table) {
+			return exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("table %s of stackgraph.tables does not exist", table))
+		}
+	}
+	env.Log.Successf("StackGraph has %d table(s) to snapshot", len(tables))
+	return nil
+}
+
+// Backup takes a snapshot set of the configured tables, exports it to the backup bucket and returns its set ID
+func (stackgraphTarget) Backup(env *target.Env) (string, error) {
+	client, err := connectHBase(env)
+	if err != nil {
+		return "", err
+	}
+
+	setID := hbase.NewSetID(time.Now())
+	if _, err := takeSnapshots(client, env.Config.StackGraph.Tables, setID, env.Log); err != nil {
+		return "", err
+	}
+	if err := exportSet(client, setID, exportLocation(env.Config), defaultMappers, env.Log); err != nil {
+		return "", err
+	}
+	return setID, nil
+}
+
+// ListBackups returns the snapshot sets exported to the backup bucket, newest first
+func (stackgraphTarget) ListBackups(env *target.Env) ([]target.Backup, error) {
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	names, err := exportedSnapshots(bucket, exportLocation(env.Config))
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to list exported snapshots: %w", err))
+	}
+	sets := map[string]bool{}
+	for _, name := range names {
+		if setID, ok := hbase.SetID(name); ok {
+			sets[setID] = true
+		}
+	}
+
+	setIDs := slices.Sorted(maps.Keys(sets))
+	slices.Reverse(setIDs)
+	backups := make([]target.Backup, 0, len(setIDs))
+	for _, setID := range setIDs {
+		takenAt, _ := hbase.SetTime(setID)
+		backups = append(backups, target.Backup{Name: setID, State: "SUCCESS", StartTime: takenAt})
+	}
+	return backups, nil
+}
+
+// Restore imports and restores the snapshot set like stackgraph hbase restore --yes
+func (stackgraphTarget) Restore(env *target.Env, name string) error {
+	return restoreExportedSet(env, name, defaultMappers, prompt.New(true))
+}
+
+// Verify checks that the snapshot set is exported with a snapshot of each configured table
+func (stackgraphTarget) Verify(env *target.Env, name string) error {
+	bucket, cleanup, err := target.OpenBucket(env.K8s, env.CLI, env.Config, env.Log)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := verifyExportedSet(bucket, exportLocation(env.Config), name, env.Config.StackGraph.Tables); err != nil {
+		return err
+	}
+	env.Log.Successf("Snapshot set %s is exported", name)
+	return nil
+}
+
+// verifyExportedSet checks that set setID is exported to the location with a snapshot of each of tables
+func verifyExportedSet(bucket objectLister, location hbase.S3Location, setID string, tables []string) error {
+	_, names, err := selectExportedSet(bucket, location, setID)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, table := range tables {
+		if !slices.Contains(names, hbase.SnapshotName(setID, table)) {
+			missing = append(missing, table)
+		}
+	}
+	if len(missing) > 0 {
+		return exitcode.Wrap(exitcode.ValidationFailed, fmt.Errorf("snapshot set %s has no snapshot of table(s) %s", setID, strings.Join(missing, ", ")))
+	}
+	return nil
+}
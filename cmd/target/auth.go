@@ -0,0 +1,33 @@
+package target
+
+import (
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// ElasticsearchOptions returns the options of an Elasticsearch client of the installation: the proxy, rate limit and
+// read-only mode of the global flags, the authentication of elasticsearch.auth and request tracing at trace level
+func ElasticsearchOptions(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) []elasticsearch.Option {
+	opts := []elasticsearch.Option{
+		elasticsearch.WithProxy(cliCtx.Config.Proxy),
+		elasticsearch.WithRateLimit(cliCtx.Config.MaxRequestsPerSecond),
+		elasticsearch.WithReadOnly(cliCtx.Config.ReadOnly),
+		ElasticsearchAuth(k8sClient, cliCtx.Config.Namespace, cfg.Elasticsearch.Auth),
+	}
+	if log.Enabled(logger.LevelTrace) {
+		opts = append(opts, elasticsearch.WithTrace(log.Tracef))
+	}
+	return opts
+}
+
+// ElasticsearchAuth returns the option authenticating an Elasticsearch client of the installation in namespace as
+// configured in elasticsearch.auth. With a ServiceAccount token no static credentials are used: in-cluster the token
+// of the pod is sent, otherwise a token requested for elasticsearch.auth.serviceAccount.
+func ElasticsearchAuth(k8sClient *k8s.Client, namespace string, auth config.AuthConfig) elasticsearch.Option {
+	if !auth.ServiceAccountToken() {
+		return elasticsearch.WithBearerToken(nil)
+	}
+	return elasticsearch.WithBearerToken(k8sClient.ServiceAccountTokenSource(namespace, auth.ServiceAccount, auth.TokenFile, auth.Audiences))
+}
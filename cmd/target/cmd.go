@@ -0,0 +1,21 @@
+package target
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Cmd returns the targets command, which lists the data stores the CLI can back up and restore
+func Cmd(registry *Registry) *cobra.Command {
+	return &cobra.Command{
+		Use:   "targets",
+		Short: "List the data stores that can be backed up and restored",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			for _, name := range registry.Names() {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), name)
+			}
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package target
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// ScaleDownDeployments scales down deployments matching the label selector
+func ScaleDownDeployments(k8sClient *k8s.Client, namespace, labelSelector string, log *logger.Logger) ([]k8s.DeploymentScale, error) {
+	log.Infof("Scaling down deployments (selector: %s)...", labelSelector)
+
+	scaledDeployments, err := k8sClient.ScaleDownDeployments(namespace, labelSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale down deployments: %w", err)
+	}
+
+	if len(scaledDeployments) == 0 {
+		log.Infof("No deployments found to scale down")
+	} else {
+		log.Successf("Scaled down %d deployment(s):", len(scaledDeployments))
+		for _, dep := range scaledDeployments {
+			log.Infof("  - %s (replicas: %d -> 0)", dep.Name, dep.Replicas)
+		}
+	}
+
+	return scaledDeployments, nil
+}
+
+// ScaleUpDeployments restores deployments to the replica counts they had before ScaleDownDeployments
+func ScaleUpDeployments(k8sClient *k8s.Client, namespace string, scaledDeployments []k8s.DeploymentScale, log *logger.Logger) {
+	if len(scaledDeployments) == 0 {
+		return
+	}
+
+	log.Println()
+	log.Infof("Scaling up deployments back to original replica counts...")
+	if err := k8sClient.ScaleUpDeployments(namespace, scaledDeployments); err != nil {
+		log.Warningf("Failed to scale up deployments: %v", err)
+		return
+	}
+	log.Successf("Scaled up %d deployment(s) successfully:", len(scaledDeployments))
+	for _, dep := range scaledDeployments {
+		log.Infof("  - %s (replicas: 0 -> %d)", dep.Name, dep.Replicas)
+	}
+}
+
+// StatefulSetPollInterval is the time between checks whether a StatefulSet reached its replica count
+const StatefulSetPollInterval = 5 * time.Second
+
+// ScaleDownStatefulSets scales down the StatefulSets matching each of the label selectors, one selector after the
+// other, waiting up to timeout per StatefulSet for its pods to terminate before moving on. Returns the scaled
+// StatefulSets in that order, also when a later one fails, so ScaleUpStatefulSets can restore them.
+func ScaleDownStatefulSets(k8sClient *k8s.Client, namespace string, labelSelectors []string, timeout time.Duration, log *logger.Logger) ([]k8s.StatefulSetScale, error) {
+	var scaled []k8s.StatefulSetScale
+	for _, selector := range labelSelectors {
+		log.Infof("Scaling down statefulsets (selector: %s)...", selector)
+		statefulSets, err := k8sClient.ScaleDownStatefulSets(namespace, selector)
+		scaled = append(scaled, statefulSets...)
+		if err != nil {
+			return scaled, fmt.Errorf("failed to scale down statefulsets: %w", err)
+		}
+		if len(statefulSets) == 0 {
+			log.Warningf("No statefulsets found to scale down (selector: %s)", selector)
+			continue
+		}
+
+		for _, sts := range statefulSets {
+			log.Infof("  - %s (replicas: %d -> 0), waiting for its pods to terminate...", sts.Name, sts.Replicas)
+			if err := k8sClient.WaitForStatefulSetScale(namespace, sts.Name, 0, timeout, StatefulSetPollInterval); err != nil {
+				return scaled, fmt.Errorf("failed to scale down statefulsets: %w", err)
+			}
+		}
+		log.Successf("Scaled down %d statefulset(s)", len(statefulSets))
+	}
+	return scaled, nil
+}
+
+// ScaleUpStatefulSets restores StatefulSets scaled down by ScaleDownStatefulSets in reverse order, waiting up to
+// timeout for the pods of each to be Ready before scaling up the next. Failures are logged, so the remaining
+// StatefulSets and the deployments are still scaled up.
+func ScaleUpStatefulSets(k8sClient *k8s.Client, namespace string, scaled []k8s.StatefulSetScale, timeout time.Duration, log *logger.Logger) {
+	if len(scaled) == 0 {
+		return
+	}
+
+	log.Println()
+	log.Infof("Scaling up statefulsets back to original replica counts...")
+	failed := 0
+	for i := len(scaled) - 1; i >= 0; i-- {
+		sts := scaled[i]
+		log.Infof("  - %s (replicas: 0 -> %d)", sts.Name, sts.Replicas)
+		if err := k8sClient.ScaleUpStatefulSet(namespace, sts); err != nil {
+			log.Warningf("Failed to scale up statefulset: %v", err)
+			failed++
+			continue
+		}
+		if err := k8sClient.WaitForStatefulSetScale(namespace, sts.Name, sts.Replicas, timeout, StatefulSetPollInterval); err != nil {
+			log.Warningf("Statefulset %s is not ready: %v", sts.Name, err)
+		}
+	}
+	if failed > 0 {
+		log.Warningf("Failed to scale up %d statefulset(s), scale them up manually", failed)
+		return
+	}
+	log.Successf("Scaled up %d statefulset(s) successfully", len(scaled))
+}
@@ -0,0 +1,75 @@
+package target
+
+import (
+	"errors"
+	"fmt"
+
+	archivecmd "github.com/stackvista/stackstate-backup-cli/cmd/archive"
+	"github.com/stackvista/stackstate-backup-cli/cmd/portforward"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/export"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+// OpenExportStore returns the store of exported artifacts in the backup bucket, sealed with the configured archive
+// encryption. In-cluster object storage is reached through a port-forward when running outside the cluster, which
+// is closed by the returned cleanup function.
+func OpenExportStore(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) (*export.Store, func(), error) {
+	sealer, err := archivecmd.Sealer(k8sClient, cliCtx.Config.Namespace, cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, cleanup, err := OpenBucket(k8sClient, cliCtx, cfg, log)
+	if err != nil {
+		return nil, nil, err
+	}
+	return export.New(client, cfg.Elasticsearch.SnapshotRepository.Bucket, sealer), cleanup, nil
+}
+
+// OpenBucket returns a client of the object storage holding the backup bucket, reached through a port-forward when
+// running outside the cluster, which is closed by the returned cleanup function
+func OpenBucket(k8sClient *k8s.Client, cliCtx *config.Context, cfg *config.Config, log *logger.Logger) (*s3.Client, func(), error) {
+	repo := cfg.Elasticsearch.SnapshotRepository
+	endpoint, cleanup, err := portforward.ServiceEndpointAddress(k8sClient, repo.Endpoint, cliCtx.Config.Namespace, log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := s3.NewClient(s3.EndpointURL(repo.S3Protocol(), endpoint), repo.AccessKey, repo.SecretKey, repo.S3Region(), s3.WithReadOnly(cliCtx.Config.ReadOnly))
+	if err != nil {
+		cleanup()
+		return nil, nil, exitcode.Wrap(exitcode.ConfigError, err)
+	}
+	return client, cleanup, nil
+}
+
+// RecordAudit appends a destructive operation to the in-cluster audit log (see `sts-backup history`) and returns
+// opErr, the error of the operation the outcome is derived from. A failure to write the entry is joined to it, so an
+// operation that could not be audited does not exit with success unnoticed.
+func RecordAudit(k8sClient *k8s.Client, cliCtx *config.Context, entry audit.Entry, opErr error, log *logger.Logger) error {
+	entry.RunID = cliCtx.RunID
+	entry.User = k8sClient.CurrentUser()
+
+	switch {
+	case opErr == nil:
+		entry.Outcome = audit.OutcomeSuccess
+	case exitcode.Of(opErr) == exitcode.Cancelled:
+		entry.Outcome = audit.OutcomeCancelled
+	default:
+		entry.Outcome = audit.OutcomeFailed
+		entry.Error = redact.Error(opErr)
+	}
+
+	store := audit.NewStore(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.AuditConfigMapName)
+	if err := store.Append(entry); err != nil {
+		log.Errorf("Failed to record the %s in the audit log: %v", entry.Operation, err)
+		return errors.Join(opErr, fmt.Errorf("failed to record audit entry: %w", err))
+	}
+	return opErr
+}
@@ -0,0 +1,111 @@
+// Package target defines the data stores of SUSE Observability that can be backed up and restored, and the
+// plumbing every operation on one of them starts with: the logger, the Kubernetes client, the backup
+// configuration and scaling down the deployments writing to the data store.
+package target
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// BackupTarget is a data store that can be backed up and restored, such as Elasticsearch
+type BackupTarget interface {
+	// Name identifies the data store, e.g. elasticsearch
+	Name() string
+	// Configure sets up the backups of the data store, e.g. the repository and schedule
+	Configure(env *Env) error
+	// Backup takes a backup now and returns its name
+	Backup(env *Env) (string, error)
+	// ListBackups returns the backups of the data store
+	ListBackups(env *Env) ([]Backup, error)
+	// Restore restores the backup with the given name
+	Restore(env *Env, name string) error
+	// Verify checks that the backup with the given name restores; a failed check is an exitcode.ValidationFailed error
+	Verify(env *Env, name string) error
+}
+
+// Backup is a single backup of a data store
+type Backup struct {
+	Name      string
+	State     string
+	StartTime time.Time
+}
+
+// Env is what every operation on a backup target runs with
+type Env struct {
+	CLI    *config.Context
+	Log    *logger.Logger
+	K8s    *k8s.Client
+	Config *config.Config
+}
+
+// Connect creates the logger and the Kubernetes client for cliCtx and loads the backup configuration
+func Connect(cliCtx *config.Context) (*Env, error) {
+	// Create logger
+	log := logger.New(cliCtx.Config.LogLevel, cliCtx.RunID)
+
+	// Create Kubernetes client
+	k8sClient, err := k8s.NewClient(cliCtx.Config.Kubeconfig, cliCtx.Config.LogLevel >= logger.LevelDebug, cliCtx.Config.K8sClientOptions()...)
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConnectivityError, fmt.Errorf("failed to create Kubernetes client: %w", err))
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig(k8sClient.Clientset(), cliCtx.Config.Namespace, cliCtx.Config.ConfigMapName, cliCtx.Config.SecretName, config.WithHelmValues(cliCtx.Config.HelmValues))
+	if err != nil {
+		return nil, exitcode.Wrap(exitcode.ConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+
+	return &Env{CLI: cliCtx, Log: log, K8s: k8sClient, Config: cfg}, nil
+}
+
+// Registry holds the backup targets by name
+type Registry struct {
+	targets map[string]BackupTarget
+}
+
+// NewRegistry returns a registry of the given targets. It panics when two targets have the same name, which is a
+// programming error.
+func NewRegistry(targets ...BackupTarget) *Registry {
+	r := &Registry{targets: make(map[string]BackupTarget, len(targets))}
+	for _, t := range targets {
+		if err := r.Register(t); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds a target, which must have a name no other target has
+func (r *Registry) Register(t BackupTarget) error {
+	if _, ok := r.targets[t.Name()]; ok {
+		return fmt.Errorf("backup target '%s' is already registered", t.Name())
+	}
+	r.targets[t.Name()] = t
+	return nil
+}
+
+// Get returns the target with the given name
+func (r *Registry) Get(name string) (BackupTarget, error) {
+	t, ok := r.targets[name]
+	if !ok {
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("unknown backup target '%s' (expected one of: %v)", name, r.Names()))
+	}
+	return t, nil
+}
+
+// Names returns the names of the registered targets, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.targets))
+	for name := range r.targets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,103 @@
+package target
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// namedTarget is a backup target that only has a name
+type namedTarget struct {
+	BackupTarget
+	name string
+}
+
+func (t namedTarget) Name() string {
+	return t.name
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(namedTarget{name: "elasticsearch"}, namedTarget{name: "clickhouse"})
+
+	assert.Equal(t, []string{"clickhouse", "elasticsearch"}, registry.Names())
+
+	found, err := registry.Get("clickhouse")
+	require.NoError(t, err)
+	assert.Equal(t, "clickhouse", found.Name())
+
+	_, err = registry.Get("stackgraph")
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+	assert.ErrorContains(t, err, "unknown backup target 'stackgraph' (expected one of: [clickhouse elasticsearch])")
+
+	assert.ErrorContains(t, registry.Register(namedTarget{name: "elasticsearch"}), "already registered")
+	assert.Panics(t, func() { NewRegistry(namedTarget{name: "vm"}, namedTarget{name: "vm"}) })
+}
+
+func TestCmd(t *testing.T) {
+	var out bytes.Buffer
+	cmd := Cmd(NewRegistry(namedTarget{name: "elasticsearch"}, namedTarget{name: "clickhouse"}))
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "clickhouse\nelasticsearch\n", out.String())
+}
+
+func TestScaleDownAndUpDeployments(t *testing.T) {
+	replicas := int32(2)
+	clientset := fake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "receiver", Namespace: "test-ns", Labels: map[string]string{"scale": "true"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	})
+	client := k8s.NewTestClient(clientset)
+	log := logger.New(logger.LevelError, "")
+
+	scaled, err := ScaleDownDeployments(client, "test-ns", "scale=true", log)
+	require.NoError(t, err)
+	require.Len(t, scaled, 1)
+	assert.Equal(t, "receiver", scaled[0].Name)
+
+	deployment, err := clientset.AppsV1().Deployments("test-ns").Get(context.Background(), "receiver", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+
+	ScaleUpDeployments(client, "test-ns", scaled, log)
+	deployment, err = clientset.AppsV1().Deployments("test-ns").Get(context.Background(), "receiver", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *deployment.Spec.Replicas)
+}
+
+func TestScaleDownAndUpStatefulSets(t *testing.T) {
+	statefulSet := func(name, role string, replicas int32) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: map[string]string{"role": role}},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		}
+	}
+	clientset := fake.NewSimpleClientset(statefulSet("es-coordinating", "coordinating", 1), statefulSet("es-ingest", "ingest", 2))
+	client := k8s.NewTestClient(clientset)
+	log := logger.New(logger.LevelError, "")
+
+	scaled, err := ScaleDownStatefulSets(client, "test-ns", []string{"role=ingest", "role=coordinating"}, time.Second, log)
+	require.NoError(t, err)
+	assert.Equal(t, []k8s.StatefulSetScale{{Name: "es-ingest", Replicas: 2}, {Name: "es-coordinating", Replicas: 1}}, scaled,
+		"statefulsets are scaled down in the order of the selectors")
+
+	// The fake clientset never starts pods, so waiting for them to be Ready times out and is only logged
+	ScaleUpStatefulSets(client, "test-ns", scaled, 0, log)
+	for name, replicas := range map[string]int32{"es-ingest": 2, "es-coordinating": 1} {
+		sts, err := clientset.AppsV1().StatefulSets("test-ns").Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, replicas, *sts.Spec.Replicas)
+	}
+}
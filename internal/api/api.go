@@ -0,0 +1,264 @@
+// Package api implements the HTTP API of the serve command, which lets tooling list snapshots,
+// trigger backups and run restores remotely instead of shelling out to the CLI.
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// maxRestoreJobs is the number of restore jobs kept for status queries; the oldest finished ones are dropped first
+const maxRestoreJobs = 50
+
+// maxRequestBytes bounds the body of a request; a restore request is a few dozen bytes
+const maxRequestBytes = 1 << 16
+
+// Backend performs the operations exposed by the API
+type Backend interface {
+	ListSnapshots() ([]elasticsearch.Snapshot, error)
+	// TriggerBackup takes a snapshot with the SLM policy; force takes it outside of the backup window
+	TriggerBackup(force bool) (string, error)
+	Restore(runID, snapshotName string, dropAllIndices bool) error
+}
+
+// RestoreStatus is the state of a restore job
+type RestoreStatus string
+
+const (
+	RestoreRunning   RestoreStatus = "RUNNING"
+	RestoreSucceeded RestoreStatus = "SUCCESS"
+	RestoreFailed    RestoreStatus = "FAILED"
+)
+
+// RestoreRequest is the body of a restore request
+type RestoreRequest struct {
+	Snapshot       string `json:"snapshot"`
+	DropAllIndices bool   `json:"dropAllIndices"`
+}
+
+// RestoreJob describes a restore started through the API. Its ID is the run ID of the restore,
+// so it can be found back in logs, Kubernetes Events and the audit log.
+type RestoreJob struct {
+	ID             string        `json:"id"`
+	Snapshot       string        `json:"snapshot"`
+	DropAllIndices bool          `json:"dropAllIndices"`
+	Status         RestoreStatus `json:"status"`
+	Error          string        `json:"error,omitempty"`
+	StartedAt      time.Time     `json:"startedAt"`
+	FinishedAt     *time.Time    `json:"finishedAt,omitempty"`
+}
+
+// Server serves the API. At most one restore runs at a time.
+type Server struct {
+	backend Backend
+	token   string
+	newID   func() string
+	now     func() time.Time
+
+	mu      sync.Mutex
+	jobs    map[string]*RestoreJob
+	order   []string
+	running string
+	wg      sync.WaitGroup
+}
+
+// NewServer creates an API server that requires token as bearer token on every request.
+// newID generates the IDs of restore jobs.
+func NewServer(backend Backend, token string, newID func() string) *Server {
+	return &Server{
+		backend: backend,
+		token:   token,
+		newID:   newID,
+		now:     time.Now,
+		jobs:    make(map[string]*RestoreJob),
+	}
+}
+
+// Handler returns the HTTP handler for the /api/v1 endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/snapshots", s.listSnapshots)
+	mux.HandleFunc("POST /api/v1/backups", s.triggerBackup)
+	mux.HandleFunc("POST /api/v1/restores", s.startRestore)
+	mux.HandleFunc("GET /api/v1/restores", s.listRestores)
+	mux.HandleFunc("GET /api/v1/restores/{id}", s.getRestore)
+	return s.authenticate(mux)
+}
+
+// Wait blocks until running restores have finished, so deployments are scaled back up before exiting
+func (s *Server) Wait() {
+	s.wg.Wait()
+}
+
+// Running returns the ID of the running restore, or an empty string
+func (s *Server) Running() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// authenticate rejects requests that do not carry the configured bearer token
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sts-backup"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listSnapshots(w http.ResponseWriter, _ *http.Request) {
+	snapshots, err := s.backend.ListSnapshots()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to list snapshots: %w", err))
+		return
+	}
+	if snapshots == nil {
+		snapshots = []elasticsearch.Snapshot{}
+	}
+	writeJSON(w, http.StatusOK, snapshots)
+}
+
+// triggerBackup takes a snapshot; outside of the backup window it is refused with 409 Conflict, unless the
+// request has ?force=true
+func (s *Server) triggerBackup(w http.ResponseWriter, r *http.Request) {
+	name, err := s.backend.TriggerBackup(r.URL.Query().Get("force") == "true")
+	if errors.Is(err, config.ErrOutsideBackupWindow) {
+		writeError(w, http.StatusConflict, fmt.Errorf("backup refused: %w; use ?force=true to take it anyway", err))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to trigger backup: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"snapshot": name})
+}
+
+func (s *Server) startRestore(w http.ResponseWriter, r *http.Request) {
+	var req RestoreRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.Snapshot == "" {
+		writeError(w, http.StatusBadRequest, errors.New("snapshot is required"))
+		return
+	}
+
+	job, err := s.start(req)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v1/restores/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) listRestores(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	jobs := make([]RestoreJob, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, *s.jobs[id])
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) getRestore(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	job, ok := s.jobs[r.PathValue("id")]
+	var found RestoreJob
+	if ok {
+		found = *job
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("restore %s not found", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, found)
+}
+
+// start registers a restore job and runs it in the background
+func (s *Server) start(req RestoreRequest) (RestoreJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running != "" {
+		return RestoreJob{}, fmt.Errorf("restore %s is still running", s.running)
+	}
+
+	job := &RestoreJob{
+		ID:             s.newID(),
+		Snapshot:       req.Snapshot,
+		DropAllIndices: req.DropAllIndices,
+		Status:         RestoreRunning,
+		StartedAt:      s.now(),
+	}
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+	s.running = job.ID
+	s.prune()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.backend.Restore(job.ID, job.Snapshot, job.DropAllIndices)
+		s.finish(job.ID, err)
+	}()
+	return *job, nil
+}
+
+// finish records the outcome of a restore job
+func (s *Server) finish(id string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job := s.jobs[id]
+	finishedAt := s.now()
+	job.FinishedAt = &finishedAt
+	job.Status = RestoreSucceeded
+	if err != nil {
+		job.Status = RestoreFailed
+		job.Error = redact.Error(err)
+	}
+	s.running = ""
+}
+
+// prune drops the oldest finished jobs once more than maxRestoreJobs are kept
+func (s *Server) prune() {
+	for len(s.order) > maxRestoreJobs {
+		oldest := s.order[0]
+		if oldest == s.running {
+			return
+		}
+		delete(s.jobs, oldest)
+		s.order = s.order[1:]
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": redact.Error(err)})
+}
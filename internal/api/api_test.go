@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "s3cret"
+
+// fakeBackend records calls and blocks restores until release is closed
+type fakeBackend struct {
+	snapshots  []elasticsearch.Snapshot
+	listErr    error
+	backupName string
+	backupErr  error
+	forced     bool
+	restoreErr error
+	release    chan struct{}
+	restored   []string
+}
+
+func (f *fakeBackend) ListSnapshots() ([]elasticsearch.Snapshot, error) {
+	return f.snapshots, f.listErr
+}
+
+func (f *fakeBackend) TriggerBackup(force bool) (string, error) {
+	f.forced = force
+	return f.backupName, f.backupErr
+}
+
+func (f *fakeBackend) Restore(_, snapshotName string, _ bool) error {
+	if f.release != nil {
+		<-f.release
+	}
+	f.restored = append(f.restored, snapshotName)
+	return f.restoreErr
+}
+
+func newTestServer(backend Backend) *Server {
+	id := 0
+	return NewServer(backend, testToken, func() string {
+		id++
+		return fmt.Sprintf("run-%d", id)
+	})
+}
+
+func do(t *testing.T, handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_Authentication(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+	}{
+		{name: "missing token", token: "", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "wrong", expectedStatus: http.StatusUnauthorized},
+		{name: "valid token", token: testToken, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestServer(&fakeBackend{}).Handler()
+			rec := do(t, handler, http.MethodGet, "/api/v1/snapshots", tt.token, "")
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestServer_ListSnapshots(t *testing.T) {
+	tests := []struct {
+		name           string
+		backend        *fakeBackend
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "snapshots",
+			backend:        &fakeBackend{snapshots: []elasticsearch.Snapshot{{Snapshot: "snap-1", State: "SUCCESS"}}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"snapshot":"snap-1"`,
+		},
+		{
+			name:           "empty repository",
+			backend:        &fakeBackend{},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name:           "backend error",
+			backend:        &fakeBackend{listErr: errors.New("connection refused")},
+			expectedStatus: http.StatusBadGateway,
+			expectedBody:   "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(t, newTestServer(tt.backend).Handler(), http.MethodGet, "/api/v1/snapshots", testToken, "")
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
+
+func TestServer_TriggerBackup(t *testing.T) {
+	rec := do(t, newTestServer(&fakeBackend{backupName: "sts-backup-1"}).Handler(), http.MethodPost, "/api/v1/backups", testToken, "")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{"snapshot":"sts-backup-1"}`, rec.Body.String())
+
+	rec = do(t, newTestServer(&fakeBackend{backupErr: errors.New("policy not found")}).Handler(), http.MethodPost, "/api/v1/backups", testToken, "")
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
+
+func TestServer_TriggerBackup_OutsideBackupWindow(t *testing.T) {
+	backend := &fakeBackend{backupErr: fmt.Errorf("%w 01:00-05:00 UTC (it is 14:30)", config.ErrOutsideBackupWindow)}
+	handler := newTestServer(backend).Handler()
+
+	rec := do(t, handler, http.MethodPost, "/api/v1/backups", testToken, "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "use ?force=true")
+	assert.False(t, backend.forced)
+
+	backend.backupErr = nil
+	rec = do(t, handler, http.MethodPost, "/api/v1/backups?force=true", testToken, "")
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.True(t, backend.forced)
+}
+
+func TestServer_StartRestore_InvalidRequest(t *testing.T) {
+	handler := newTestServer(&fakeBackend{}).Handler()
+
+	rec := do(t, handler, http.MethodPost, "/api/v1/restores", testToken, "not json")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(t, handler, http.MethodPost, "/api/v1/restores", testToken, `{"dropAllIndices": true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "snapshot is required")
+
+	rec = do(t, handler, http.MethodPost, "/api/v1/restores", testToken, `{"snapshot": "snap-1", "dropAllIndexes": true}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a misspelled field is rejected instead of ignored")
+	assert.Contains(t, rec.Body.String(), "unknown field")
+
+	rec = do(t, handler, http.MethodPost, "/api/v1/restores", testToken, `{"snapshot": "`+strings.Repeat("a", maxRequestBytes)+`"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServer_RestoreLifecycle(t *testing.T) {
+	backend := &fakeBackend{release: make(chan struct{}), restoreErr: errors.New("restore incomplete")}
+	server := newTestServer(backend)
+	handler := server.Handler()
+
+	rec := do(t, handler, http.MethodPost, "/api/v1/restores", testToken, `{"snapshot": "snap-1", "dropAllIndices": true}`)
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "/api/v1/restores/run-1", rec.Header().Get("Location"))
+
+	var job RestoreJob
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	assert.Equal(t, "run-1", job.ID)
+	assert.Equal(t, RestoreRunning, job.Status)
+	assert.True(t, job.DropAllIndices)
+
+	// A second restore is rejected while the first one runs
+	rec = do(t, handler, http.MethodPost, "/api/v1/restores", testToken, `{"snapshot": "snap-2"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "run-1", server.Running())
+
+	close(backend.release)
+	server.Wait()
+
+	rec = do(t, handler, http.MethodGet, "/api/v1/restores/run-1", testToken, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	assert.Equal(t, RestoreFailed, job.Status)
+	assert.Equal(t, "restore incomplete", job.Error)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Equal(t, []string{"snap-1"}, backend.restored)
+	assert.Empty(t, server.Running())
+
+	rec = do(t, handler, http.MethodGet, "/api/v1/restores", testToken, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":"run-1"`)
+
+	rec = do(t, handler, http.MethodGet, "/api/v1/restores/unknown", testToken, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_PrunesFinishedJobs(t *testing.T) {
+	server := newTestServer(&fakeBackend{})
+
+	for i := 0; i < maxRestoreJobs+5; i++ {
+		_, err := server.start(RestoreRequest{Snapshot: "snap"})
+		require.NoError(t, err)
+		server.Wait()
+	}
+
+	assert.Len(t, server.jobs, maxRestoreJobs)
+	assert.NotContains(t, server.jobs, "run-1")
+	assert.Contains(t, server.jobs, fmt.Sprintf("run-%d", maxRestoreJobs+5))
+}
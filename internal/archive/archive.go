@@ -0,0 +1,168 @@
+// Package archive encrypts backup artifacts that leave the cluster, such as exported settings or
+// snapshot archives written to local files or an offsite bucket, so portable copies are never plaintext.
+//
+// Archives are encrypted with AES-256-GCM using a key kept in a Kubernetes Secret. An encrypted archive
+// starts with a fixed header followed by the nonce and the sealed data, so it can be recognized and
+// decrypted later with the same key.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// KeySize is the size of an AES-256 key in bytes
+	KeySize = 32
+
+	// DefaultSecretKey is the key in the Secret holding the encryption key when none is configured
+	DefaultSecretKey = "key"
+)
+
+// header marks an encrypted archive and its format version
+var header = []byte("STSBACKUP-AES256GCM-1\n")
+
+// ErrNoKey is returned when opening an encrypted archive without an encryption key
+var ErrNoKey = errors.New("archive is encrypted but no encryption key is configured")
+
+// ParseKey decodes a base64 encoded 256-bit key, as generated with 'openssl rand -base64 32'
+func ParseKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not base64 encoded: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// LoadKey reads the base64 encoded encryption key from a Secret
+func LoadKey(clientset kubernetes.Interface, namespace, secretName, secretKey string) ([]byte, error) {
+	if secretKey == "" {
+		secretKey = DefaultSecretKey
+	}
+
+	secret, err := clientset.CoreV1().Secrets(namespace).Get(context.Background(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key Secret '%s': %w", secretName, err)
+	}
+	value, ok := secret.Data[secretKey]
+	if !ok {
+		return nil, fmt.Errorf("encryption key Secret '%s' does not contain key '%s'", secretName, secretKey)
+	}
+
+	key, err := ParseKey(string(value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key in Secret '%s': %w", secretName, err)
+	}
+	return key, nil
+}
+
+// IsEncrypted reports whether data is an encrypted archive
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, header)
+}
+
+// Encrypt seals plaintext with key
+func Encrypt(plaintext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	// The header is authenticated as additional data, so it cannot be swapped for another format version
+	return gcm.Seal(out, nonce, plaintext, header), nil
+}
+
+// Decrypt opens an archive sealed by Encrypt with the same key
+func Decrypt(data, key []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, errors.New("data is not an encrypted archive")
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := data[len(header):]
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("encrypted archive is truncated")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, errors.New("failed to decrypt archive: wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return gcm, nil
+}
+
+// Sealer encrypts archives before they are written when an encryption key is configured,
+// and passes them through unchanged otherwise. It is what exports use to write artifacts.
+type Sealer struct {
+	key []byte
+}
+
+// NewSealer returns a Sealer using key; a nil key disables encryption
+func NewSealer(key []byte) *Sealer {
+	return &Sealer{key: key}
+}
+
+// Enabled reports whether archives are encrypted
+func (s *Sealer) Enabled() bool {
+	return s.key != nil
+}
+
+// Seal encrypts data when encryption is enabled
+func (s *Sealer) Seal(data []byte) ([]byte, error) {
+	if !s.Enabled() {
+		return data, nil
+	}
+	return Encrypt(data, s.key)
+}
+
+// Open decrypts data when it is an encrypted archive, and returns plaintext archives unchanged
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	if !s.Enabled() {
+		return nil, ErrNoKey
+	}
+	return Decrypt(data, s.key)
+}
@@ -0,0 +1,134 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testKey = bytes.Repeat([]byte{0x42}, KeySize)
+
+func TestEncryptDecrypt(t *testing.T) {
+	plaintext := []byte(`{"pipelines": {"sts-logs": {}}}`)
+
+	encrypted, err := Encrypt(plaintext, testKey)
+	require.NoError(t, err)
+
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "pipelines")
+
+	decrypted, err := Decrypt(encrypted, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Every encryption uses a fresh nonce
+	again, err := Encrypt(plaintext, testKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again)
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	encrypted, err := Encrypt([]byte("data"), testKey)
+	require.NoError(t, err)
+
+	tampered := bytes.Clone(encrypted)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name string
+		data []byte
+		key  []byte
+	}{
+		{name: "wrong key", data: encrypted, key: bytes.Repeat([]byte{0x01}, KeySize)},
+		{name: "tampered", data: tampered, key: testKey},
+		{name: "truncated", data: encrypted[:len(header)+4], key: testKey},
+		{name: "not encrypted", data: []byte("data"), key: testKey},
+		{name: "invalid key size", data: encrypted, key: []byte("short")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decrypt(tt.data, tt.key)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(testKey) + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	_, err = ParseKey("not base64!")
+	assert.Error(t, err)
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.Error(t, err)
+}
+
+func TestLoadKey(t *testing.T) {
+	clientset := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-encryption", Namespace: "test-ns"},
+		Data: map[string][]byte{
+			DefaultSecretKey: []byte(base64.StdEncoding.EncodeToString(testKey)),
+			"invalid":        []byte("c2hvcnQ="),
+		},
+	})
+
+	key, err := LoadKey(clientset, "test-ns", "backup-encryption", "")
+	require.NoError(t, err)
+	assert.Equal(t, testKey, key)
+
+	_, err = LoadKey(clientset, "test-ns", "backup-encryption", "missing")
+	assert.ErrorContains(t, err, "does not contain key 'missing'")
+
+	_, err = LoadKey(clientset, "test-ns", "backup-encryption", "invalid")
+	assert.ErrorContains(t, err, "invalid encryption key")
+
+	_, err = LoadKey(clientset, "test-ns", "missing", "")
+	assert.Error(t, err)
+}
+
+func TestSealer(t *testing.T) {
+	plaintext := []byte("data")
+
+	t.Run("disabled", func(t *testing.T) {
+		sealer := NewSealer(nil)
+
+		sealed, err := sealer.Seal(plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, sealed)
+
+		opened, err := sealer.Open(plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, opened)
+
+		encrypted, err := Encrypt(plaintext, testKey)
+		require.NoError(t, err)
+		_, err = sealer.Open(encrypted)
+		assert.ErrorIs(t, err, ErrNoKey)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		sealer := NewSealer(testKey)
+
+		sealed, err := sealer.Seal(plaintext)
+		require.NoError(t, err)
+		assert.True(t, IsEncrypted(sealed))
+
+		opened, err := sealer.Open(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, opened)
+
+		// Plaintext archives written before encryption was enabled can still be read
+		opened, err = sealer.Open(plaintext)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, opened)
+	})
+}
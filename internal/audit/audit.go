@@ -0,0 +1,227 @@
+// Package audit records destructive operations (restores, deletions, configuration changes)
+// in an append-only ConfigMap in the namespace, so there is an in-cluster trail of who did what and when.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// DefaultConfigMapName is the default name of the ConfigMap holding the audit log
+	DefaultConfigMapName = "suse-observability-backup-audit"
+
+	// MaxDataBytes bounds the size of the entries in one ConfigMap, below the 1MiB limit of a ConfigMap. When the next
+	// entry does not fit, the entries are moved to an archive ConfigMap and the log starts over, so none is dropped.
+	MaxDataBytes = 900 * 1024
+
+	// archiveTimeFormat suffixes the names of archive ConfigMaps with the time of their newest entry
+	archiveTimeFormat = "20060102-150405.000000000"
+
+	// entryTimeFormat prefixes entry keys so they sort chronologically
+	entryTimeFormat = "20060102T150405.000000000Z"
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "sts-backup"
+	// logLabel holds the name of the audit log on its ConfigMap and on its archives
+	logLabel = "sts-backup/audit-log"
+)
+
+// Outcomes of an audited operation
+const (
+	OutcomeSuccess   = "SUCCESS"
+	OutcomeFailed    = "FAILED"
+	OutcomeCancelled = "CANCELLED"
+)
+
+// Entry is a single audited operation
+type Entry struct {
+	RunID      string    `json:"runId"`
+	Timestamp  time.Time `json:"timestamp"`
+	User       string    `json:"user"`
+	Operation  string    `json:"operation"`
+	Snapshot   string    `json:"snapshot,omitempty"`
+	Repository string    `json:"repository,omitempty"`
+	// AdditionalSnapshots are the snapshots restored together with Snapshot, as <repository>/<name>
+	AdditionalSnapshots []string `json:"additionalSnapshots,omitempty"`
+	IndicesPattern      string   `json:"indicesPattern,omitempty"`
+	IndicesDeleted      []string `json:"indicesDeleted,omitempty"`
+	SnapshotsDeleted    []string `json:"snapshotsDeleted,omitempty"`
+	SafetySnapshot      string   `json:"safetySnapshot,omitempty"`
+	DurationMillis      int64    `json:"durationMillis,omitempty"`
+	Outcome             string   `json:"outcome"`
+	Error               string   `json:"error,omitempty"`
+}
+
+// Store reads and appends audit entries in a ConfigMap
+type Store struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+	// maxDataBytes is MaxDataBytes, lowered in tests
+	maxDataBytes int
+}
+
+// NewStore creates an audit store backed by the named ConfigMap
+func NewStore(clientset kubernetes.Interface, namespace, name string) *Store {
+	return &Store{
+		clientset:    clientset,
+		namespace:    namespace,
+		name:         name,
+		maxDataBytes: MaxDataBytes,
+	}
+}
+
+// Append adds an entry to the audit log, creating the ConfigMap if needed. Existing entries are never modified or
+// dropped: when the entry does not fit within MaxDataBytes, the entries so far are moved to an archive ConfigMap.
+func (s *Store) Append(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	key := entryKey(entry)
+	if len(key)+len(data) > s.maxDataBytes {
+		return fmt.Errorf("audit entry of %d bytes exceeds the limit of %d bytes", len(key)+len(data), s.maxDataBytes)
+	}
+
+	ctx := context.Background()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = s.configMap(s.name, map[string]string{key: string(data)})
+			_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// Created concurrently, retry as an update
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get audit ConfigMap '%s': %w", s.name, err)
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		if dataSize(cm.Data)+len(key)+len(data) > s.maxDataBytes {
+			if err := s.archive(ctx, cm.Data); err != nil {
+				return err
+			}
+			cm.Data = map[string]string{}
+		}
+		cm.Data[key] = string(data)
+		if cm.Labels == nil {
+			cm.Labels = map[string]string{}
+		}
+		cm.Labels[managedByLabel] = managedByValue
+		cm.Labels[logLabel] = s.name
+
+		_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// archive copies the entries of a full audit ConfigMap to an archive ConfigMap named after its newest entry. An
+// archive that exists already was created by a concurrent Append, which is retried as a conflict.
+func (s *Store) archive(ctx context.Context, data map[string]string) error {
+	newest := ""
+	for key := range data {
+		newest = max(newest, key)
+	}
+	timestamp, err := time.Parse(entryTimeFormat, strings.SplitN(newest, "_", 2)[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse audit entry key '%s': %w", newest, err)
+	}
+
+	name := s.name + "-" + timestamp.UTC().Format(archiveTimeFormat)
+	_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Create(ctx, s.configMap(name, data), metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create audit archive ConfigMap '%s': %w", name, err)
+	}
+	return nil
+}
+
+// configMap returns a ConfigMap of the audit log holding data
+func (s *Store) configMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.namespace,
+			Labels:    map[string]string{managedByLabel: managedByValue, logLabel: s.name},
+		},
+		Data: data,
+	}
+}
+
+// List returns all audit entries, including the archived ones, oldest first. A missing ConfigMap yields no entries.
+func (s *Store) List() ([]Entry, error) {
+	ctx := context.Background()
+	list, err := s.clientset.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: logLabel + "=" + s.name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit ConfigMaps '%s': %w", s.name, err)
+	}
+	// The log ConfigMap is read by name as well, since it lacks the label when written by an older version
+	cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return nil, fmt.Errorf("failed to get audit ConfigMap '%s': %w", s.name, err)
+	default:
+		list.Items = append(list.Items, *cm)
+	}
+
+	// An entry is in two ConfigMaps when an Append was retried after archiving, so entries are collected by key
+	values := map[string]string{}
+	for _, item := range list.Items {
+		for key, value := range item.Data {
+			values[key] = value
+		}
+	}
+	entries := make([]Entry, 0, len(values))
+	for key, value := range values {
+		var entry Entry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit entry '%s': %w", key, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// entryKey builds a ConfigMap key that sorts chronologically and is unique per run and operation
+func entryKey(entry Entry) string {
+	key := entry.Timestamp.UTC().Format(entryTimeFormat) + "_" + entry.Operation
+	if entry.RunID != "" {
+		key += "_" + entry.RunID
+	}
+	return key
+}
+
+// dataSize returns the number of bytes of the keys and values of data
+func dataSize(data map[string]string) int {
+	size := 0
+	for key, value := range data {
+		size += len(key) + len(value)
+	}
+	return size
+}
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStore_AppendAndList(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	store := NewStore(fakeClient, "test-ns", DefaultConfigMapName)
+
+	first := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	// Append out of order to verify List sorts chronologically
+	require.NoError(t, store.Append(Entry{
+		RunID:     "run-2",
+		Timestamp: second,
+		User:      "admin",
+		Operation: "configure",
+		Outcome:   OutcomeFailed,
+		Error:     "boom",
+	}))
+	require.NoError(t, store.Append(Entry{
+		RunID:          "run-1",
+		Timestamp:      first,
+		User:           "admin",
+		Operation:      "restore",
+		Snapshot:       "snap-1",
+		IndicesDeleted: []string{"sts_topology"},
+		Outcome:        OutcomeSuccess,
+	}))
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "run-1", entries[0].RunID)
+	assert.Equal(t, "snap-1", entries[0].Snapshot)
+	assert.Equal(t, []string{"sts_topology"}, entries[0].IndicesDeleted)
+	assert.Equal(t, "run-2", entries[1].RunID)
+	assert.Equal(t, "boom", entries[1].Error)
+
+	cm, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), DefaultConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, managedByValue, cm.Labels[managedByLabel])
+}
+
+func TestStore_ListMissingConfigMap(t *testing.T) {
+	store := NewStore(fake.NewSimpleClientset(), "test-ns", DefaultConfigMapName)
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestStore_AppendSetsTimestamp(t *testing.T) {
+	store := NewStore(fake.NewSimpleClientset(), "test-ns", DefaultConfigMapName)
+
+	require.NoError(t, store.Append(Entry{Operation: "restore", Outcome: OutcomeSuccess}))
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.False(t, entries[0].Timestamp.IsZero())
+}
+
+func TestStore_AppendArchivesFullLog(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	store := NewStore(fakeClient, "test-ns", DefaultConfigMapName)
+	store.maxDataBytes = 600
+
+	start := time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.Append(Entry{
+			RunID:          fmt.Sprintf("run-%d", i),
+			Timestamp:      start.Add(time.Duration(i) * time.Hour),
+			Operation:      "restore",
+			IndicesDeleted: []string{"sts_topology", "sts_metrics"},
+			Outcome:        OutcomeSuccess,
+		}))
+	}
+
+	// No entry is dropped: the full log was moved to archives
+	entries, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	for i, entry := range entries {
+		assert.Equal(t, fmt.Sprintf("run-%d", i), entry.RunID)
+	}
+
+	list, err := fakeClient.CoreV1().ConfigMaps("test-ns").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Greater(t, len(list.Items), 1)
+	for _, cm := range list.Items {
+		assert.LessOrEqual(t, dataSize(cm.Data), store.maxDataBytes)
+		assert.Equal(t, DefaultConfigMapName, cm.Labels[logLabel])
+	}
+}
+
+func TestStore_AppendRejectsOversizedEntry(t *testing.T) {
+	store := NewStore(fake.NewSimpleClientset(), "test-ns", DefaultConfigMapName)
+	store.maxDataBytes = 100
+
+	err := store.Append(Entry{Operation: "restore", IndicesDeleted: []string{strings.Repeat("sts_topology", 10)}})
+	assert.ErrorContains(t, err, "exceeds the limit of 100 bytes")
+}
@@ -0,0 +1,84 @@
+// Package cache provides a small file-based cache with expiry, used to keep
+// repeated lookups (such as shell completion of snapshot names) fast.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// unsafeKeyChars matches characters that are replaced when turning a key into a file name
+var unsafeKeyChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// Store is a directory of JSON cache entries that expire after a fixed TTL
+type Store struct {
+	dir string
+	ttl time.Duration
+}
+
+// entry is the on-disk representation of a cached value
+type entry struct {
+	StoredAt time.Time       `json:"storedAt"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// New creates a cache store in the user's cache directory (e.g. ~/.cache/sts-backup/<name>)
+func New(name string, ttl time.Duration) (*Store, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return NewInDir(filepath.Join(base, "sts-backup", name), ttl), nil
+}
+
+// NewInDir creates a cache store in the given directory
+func NewInDir(dir string, ttl time.Duration) *Store {
+	return &Store{dir: dir, ttl: ttl}
+}
+
+// Get loads the value stored under key into v.
+// It returns false if there is no entry, the entry expired or it cannot be decoded.
+func (s *Store) Get(key string, v interface{}) bool {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if time.Since(e.StoredAt) > s.ttl {
+		return false
+	}
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Put stores v under key
+func (s *Store) Put(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value: %w", err)
+	}
+	data, err := json.Marshal(entry{StoredAt: time.Now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.WriteFile(s.path(key), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the file path of the entry for key
+func (s *Store) path(key string) string {
+	return filepath.Join(s.dir, unsafeKeyChars.ReplaceAllString(key, "_")+".json")
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_PutGet(t *testing.T) {
+	store := NewInDir(t.TempDir(), time.Minute)
+
+	require.NoError(t, store.Put("ns/repo", []string{"snap-1", "snap-2"}))
+
+	var names []string
+	assert.True(t, store.Get("ns/repo", &names))
+	assert.Equal(t, []string{"snap-1", "snap-2"}, names)
+}
+
+func TestStore_GetMissing(t *testing.T) {
+	store := NewInDir(t.TempDir(), time.Minute)
+
+	var names []string
+	assert.False(t, store.Get("missing", &names))
+	assert.Nil(t, names)
+}
+
+func TestStore_GetExpired(t *testing.T) {
+	store := NewInDir(t.TempDir(), -time.Second)
+
+	require.NoError(t, store.Put("key", []string{"value"}))
+
+	var names []string
+	assert.False(t, store.Get("key", &names))
+}
+
+func TestStore_GetCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	store := NewInDir(dir, time.Minute)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key.json"), []byte("not json"), 0o600))
+
+	var names []string
+	assert.False(t, store.Get("key", &names))
+}
+
+func TestStore_KeyIsSanitized(t *testing.T) {
+	dir := t.TempDir()
+	store := NewInDir(dir, time.Minute)
+
+	require.NoError(t, store.Put("../ns/repo", "value"))
+
+	assert.FileExists(t, filepath.Join(dir, ".._ns_repo.json"))
+}
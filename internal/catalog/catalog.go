@@ -0,0 +1,129 @@
+// Package catalog maintains an inventory of backups as JSON manifests in the backup bucket.
+// The catalog is independent of any single datastore, so backups of every component can be
+// listed from one place even when the datastore that took them is unavailable.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+const (
+	// Prefix is the key prefix under which manifests are stored in the bucket
+	Prefix = "catalog/"
+
+	// ComponentElasticsearch identifies Elasticsearch snapshots
+	ComponentElasticsearch = "elasticsearch"
+
+	manifestExtension = ".json"
+)
+
+// ObjectStore is the subset of the S3 client used by the catalog
+type ObjectStore interface {
+	PutObject(bucket, key string, body []byte) error
+	GetObject(bucket, key string) ([]byte, error)
+	ListObjects(bucket, prefix string) ([]s3.Object, error)
+}
+
+// Manifest describes a single backup of a component
+type Manifest struct {
+	Component    string    `json:"component"`
+	Snapshot     string    `json:"snapshot"`
+	Repository   string    `json:"repository,omitempty"`
+	State        string    `json:"state"`
+	Indices      []string  `json:"indices,omitempty"`
+	SizeInBytes  int64     `json:"sizeInBytes"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	Cluster      string    `json:"cluster,omitempty"`
+	Namespace    string    `json:"namespace,omitempty"`
+	ChartVersion string    `json:"chartVersion,omitempty"`
+	RecordedAt   time.Time `json:"recordedAt"`
+	RunID        string    `json:"runId,omitempty"`
+}
+
+// Catalog reads and writes manifests in a bucket
+type Catalog struct {
+	store  ObjectStore
+	bucket string
+}
+
+// New creates a catalog stored in bucket
+func New(store ObjectStore, bucket string) *Catalog {
+	return &Catalog{store: store, bucket: bucket}
+}
+
+// Key returns the object key of the manifest of a backup
+func Key(component, snapshot string) string {
+	return Prefix + path.Join(component, snapshot) + manifestExtension
+}
+
+// Put writes the manifest of a backup, replacing any existing manifest of the same backup
+func (c *Catalog) Put(manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	key := Key(manifest.Component, manifest.Snapshot)
+	if err := c.store.PutObject(c.bucket, key, data); err != nil {
+		return fmt.Errorf("failed to write manifest %s: %w", key, err)
+	}
+	return nil
+}
+
+// Recorded returns the names of the snapshots of a component that have a manifest
+func (c *Catalog) Recorded(component string) (map[string]bool, error) {
+	prefix := Prefix + component + "/"
+	objects, err := c.store.ListObjects(c.bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list catalog: %w", err)
+	}
+
+	recorded := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, prefix)
+		if strings.HasSuffix(name, manifestExtension) && !strings.Contains(name, "/") {
+			recorded[strings.TrimSuffix(name, manifestExtension)] = true
+		}
+	}
+	return recorded, nil
+}
+
+// List returns all manifests, optionally limited to one component, newest first
+func (c *Catalog) List(component string) ([]Manifest, error) {
+	prefix := Prefix
+	if component != "" {
+		prefix += component + "/"
+	}
+	objects, err := c.store.ListObjects(c.bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list catalog: %w", err)
+	}
+
+	manifests := make([]Manifest, 0, len(objects))
+	for _, object := range objects {
+		if !strings.HasSuffix(object.Key, manifestExtension) {
+			continue
+		}
+		data, err := c.store.GetObject(c.bucket, object.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", object.Key, err)
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest %s: %w", object.Key, err)
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifests[i].StartTime.After(manifests[j].StartTime)
+	})
+	return manifests, nil
+}
@@ -0,0 +1,113 @@
+package catalog
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory ObjectStore
+type memoryStore struct {
+	objects map[string][]byte
+	listErr error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (m *memoryStore) PutObject(bucket, key string, body []byte) error {
+	m.objects[bucket+"/"+key] = body
+	return nil
+}
+
+func (m *memoryStore) GetObject(bucket, key string) ([]byte, error) {
+	data, ok := m.objects[bucket+"/"+key]
+	if !ok {
+		return nil, &s3.Error{StatusCode: 404, Code: "NoSuchKey"}
+	}
+	return data, nil
+}
+
+func (m *memoryStore) ListObjects(bucket, prefix string) ([]s3.Object, error) {
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	var objects []s3.Object
+	for key := range m.objects {
+		if name, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(name, prefix) {
+			objects = append(objects, s3.Object{Key: name})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "catalog/elasticsearch/sts-backup-20250110.json", Key(ComponentElasticsearch, "sts-backup-20250110"))
+}
+
+func TestCatalog_PutAndList(t *testing.T) {
+	store := newMemoryStore()
+	cat := New(store, "sts-backup")
+	older := time.Date(2025, 1, 9, 3, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 1, 10, 3, 0, 0, 0, time.UTC)
+
+	require.NoError(t, cat.Put(&Manifest{Component: ComponentElasticsearch, Snapshot: "snap-old", State: "SUCCESS", StartTime: older}))
+	require.NoError(t, cat.Put(&Manifest{Component: ComponentElasticsearch, Snapshot: "snap-new", State: "SUCCESS", StartTime: newer, Indices: []string{"sts_a"}}))
+	require.NoError(t, cat.Put(&Manifest{Component: "kafka", Snapshot: "topics-1", State: "SUCCESS", StartTime: older}))
+	// Objects that are not manifests are ignored
+	store.objects["sts-backup/catalog/README"] = []byte("not a manifest")
+
+	all, err := cat.List("")
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, "snap-new", all[0].Snapshot)
+	assert.Equal(t, []string{"sts_a"}, all[0].Indices)
+
+	es, err := cat.List(ComponentElasticsearch)
+	require.NoError(t, err)
+	require.Len(t, es, 2)
+	assert.Equal(t, "snap-new", es[0].Snapshot)
+	assert.Equal(t, "snap-old", es[1].Snapshot)
+}
+
+func TestCatalog_List_InvalidManifest(t *testing.T) {
+	store := newMemoryStore()
+	store.objects["sts-backup/catalog/elasticsearch/broken.json"] = []byte("{")
+
+	_, err := New(store, "sts-backup").List("")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "catalog/elasticsearch/broken.json")
+}
+
+func TestCatalog_Recorded(t *testing.T) {
+	store := newMemoryStore()
+	cat := New(store, "sts-backup")
+	require.NoError(t, cat.Put(&Manifest{Component: ComponentElasticsearch, Snapshot: "snap-1"}))
+	require.NoError(t, cat.Put(&Manifest{Component: "kafka", Snapshot: "snap-2"}))
+
+	recorded, err := cat.Recorded(ComponentElasticsearch)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"snap-1": true}, recorded)
+}
+
+func TestCatalog_ListError(t *testing.T) {
+	store := newMemoryStore()
+	store.listErr = errors.New("access denied")
+	cat := New(store, "sts-backup")
+
+	_, err := cat.List("")
+	assert.ErrorContains(t, err, "access denied")
+
+	_, err = cat.Recorded(ComponentElasticsearch)
+	assert.ErrorContains(t, err, "access denied")
+}
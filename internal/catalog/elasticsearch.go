@@ -0,0 +1,81 @@
+package catalog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+)
+
+// SnapshotSource is the subset of the Elasticsearch client used to record snapshots
+type SnapshotSource interface {
+	ListSnapshots(repository string) ([]elasticsearch.Snapshot, error)
+	SnapshotSize(repository, snapshotName string) (int64, error)
+	ClusterHealth() (*elasticsearch.ClusterHealth, error)
+}
+
+// Metadata is recorded in every manifest written by a sync
+type Metadata struct {
+	Namespace    string
+	ChartVersion string
+	RunID        string
+}
+
+// SyncElasticsearch writes a manifest for every completed snapshot in the repository that is not
+// in the catalog yet and returns the names of the recorded snapshots. Snapshots that are still
+// running or failed are skipped, so they are picked up by a later sync once they complete.
+func (c *Catalog) SyncElasticsearch(source SnapshotSource, repository string, meta Metadata, now time.Time) ([]string, error) {
+	snapshots, err := source.ListSnapshots(repository)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	recorded, err := c.Recorded(ComponentElasticsearch)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []elasticsearch.Snapshot
+	for _, snapshot := range snapshots {
+		if (snapshot.State == "SUCCESS" || snapshot.State == "PARTIAL") && !recorded[snapshot.Snapshot] {
+			pending = append(pending, snapshot)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	health, err := source.ClusterHealth()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster name: %w", err)
+	}
+
+	added := make([]string, 0, len(pending))
+	for _, snapshot := range pending {
+		size, err := source.SnapshotSize(repository, snapshot.Snapshot)
+		if err != nil {
+			return added, fmt.Errorf("failed to get size of snapshot %s: %w", snapshot.Snapshot, err)
+		}
+
+		manifest := &Manifest{
+			Component:    ComponentElasticsearch,
+			Snapshot:     snapshot.Snapshot,
+			Repository:   repository,
+			State:        snapshot.State,
+			Indices:      snapshot.Indices,
+			SizeInBytes:  size,
+			StartTime:    time.UnixMilli(snapshot.StartTimeMillis).UTC(),
+			EndTime:      time.UnixMilli(snapshot.EndTimeMillis).UTC(),
+			Cluster:      health.ClusterName,
+			Namespace:    meta.Namespace,
+			ChartVersion: meta.ChartVersion,
+			RecordedAt:   now.UTC(),
+			RunID:        meta.RunID,
+		}
+		if err := c.Put(manifest); err != nil {
+			return added, err
+		}
+		added = append(added, snapshot.Snapshot)
+	}
+	return added, nil
+}
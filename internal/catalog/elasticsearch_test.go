@@ -0,0 +1,90 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSnapshotSource struct {
+	snapshots []elasticsearch.Snapshot
+	sizes     map[string]int64
+	sizeErr   error
+}
+
+func (f *fakeSnapshotSource) ListSnapshots(_ string) ([]elasticsearch.Snapshot, error) {
+	return f.snapshots, nil
+}
+
+func (f *fakeSnapshotSource) SnapshotSize(_, snapshotName string) (int64, error) {
+	return f.sizes[snapshotName], f.sizeErr
+}
+
+func (f *fakeSnapshotSource) ClusterHealth() (*elasticsearch.ClusterHealth, error) {
+	return &elasticsearch.ClusterHealth{ClusterName: "sts-es"}, nil
+}
+
+func TestCatalog_SyncElasticsearch(t *testing.T) {
+	store := newMemoryStore()
+	cat := New(store, "sts-backup")
+	require.NoError(t, cat.Put(&Manifest{Component: ComponentElasticsearch, Snapshot: "snap-recorded"}))
+
+	taken := time.Date(2025, 1, 10, 3, 0, 0, 0, time.UTC)
+	now := taken.Add(time.Hour)
+	source := &fakeSnapshotSource{
+		snapshots: []elasticsearch.Snapshot{
+			{Snapshot: "snap-recorded", State: "SUCCESS"},
+			{Snapshot: "snap-new", State: "SUCCESS", Indices: []string{"sts_a"}, StartTimeMillis: taken.UnixMilli(), EndTimeMillis: taken.Add(time.Minute).UnixMilli()},
+			{Snapshot: "snap-partial", State: "PARTIAL"},
+			{Snapshot: "snap-running", State: "IN_PROGRESS"},
+			{Snapshot: "snap-failed", State: "FAILED"},
+		},
+		sizes: map[string]int64{"snap-new": 2048},
+	}
+
+	added, err := cat.SyncElasticsearch(source, "sts-backup", Metadata{Namespace: "suse-observability", ChartVersion: "suse-observability-2.3.0", RunID: "run-1"}, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snap-new", "snap-partial"}, added)
+
+	var manifest Manifest
+	require.NoError(t, json.Unmarshal(store.objects["sts-backup/"+Key(ComponentElasticsearch, "snap-new")], &manifest))
+	assert.Equal(t, Manifest{
+		Component:    ComponentElasticsearch,
+		Snapshot:     "snap-new",
+		Repository:   "sts-backup",
+		State:        "SUCCESS",
+		Indices:      []string{"sts_a"},
+		SizeInBytes:  2048,
+		StartTime:    taken,
+		EndTime:      taken.Add(time.Minute),
+		Cluster:      "sts-es",
+		Namespace:    "suse-observability",
+		ChartVersion: "suse-observability-2.3.0",
+		RecordedAt:   now,
+		RunID:        "run-1",
+	}, manifest)
+
+	// A second sync has nothing left to record
+	added, err = cat.SyncElasticsearch(source, "sts-backup", Metadata{}, now)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+}
+
+func TestCatalog_SyncElasticsearch_SizeError(t *testing.T) {
+	cat := New(newMemoryStore(), "sts-backup")
+	source := &fakeSnapshotSource{
+		snapshots: []elasticsearch.Snapshot{{Snapshot: "snap-1", State: "SUCCESS"}},
+		sizeErr:   errors.New("snapshot missing"),
+	}
+
+	added, err := cat.SyncElasticsearch(source, "sts-backup", Metadata{}, time.Now())
+
+	require.Error(t, err)
+	assert.Empty(t, added)
+}
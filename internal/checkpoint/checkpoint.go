@@ -0,0 +1,180 @@
+// Package checkpoint records the progress of multi-step operations, such as a restore, in a ConfigMap in the
+// namespace, so an interrupted run can be resumed where it stopped instead of starting over.
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// DefaultConfigMapName is the name of the ConfigMap holding the checkpoints
+	DefaultConfigMapName = "suse-observability-backup-checkpoints"
+
+	// MaxCheckpoints bounds the number of checkpoints kept, since a ConfigMap is limited to 1MiB. Checkpoints are
+	// removed when their run completes; beyond this the oldest are dropped.
+	MaxCheckpoints = 20
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "sts-backup"
+)
+
+// ErrNotFound is returned by Load when there is no checkpoint for a run
+var ErrNotFound = errors.New("checkpoint not found")
+
+// Checkpoint is the progress of a run: the phases it completed and what it changed on the way
+type Checkpoint struct {
+	// RunID identifies the run that started the operation; resumed runs keep updating its checkpoint
+	RunID     string    `json:"runId"`
+	Operation string    `json:"operation"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Snapshots are the snapshots of the operation, as given on the command line
+	Snapshots      []string `json:"snapshots,omitempty"`
+	DropAllIndices bool     `json:"dropAllIndices,omitempty"`
+	// Phases are the completed phases, in the order they completed
+	Phases             []string               `json:"phases,omitempty"`
+	IndicesDeleted     []string               `json:"indicesDeleted,omitempty"`
+	SafetySnapshot     string                 `json:"safetySnapshot,omitempty"`
+	ScaledDeployments  []k8s.DeploymentScale  `json:"scaledDeployments,omitempty"`
+	ScaledStatefulSets []k8s.StatefulSetScale `json:"scaledStatefulSets,omitempty"`
+}
+
+// Completed reports whether phase has been completed
+func (c *Checkpoint) Completed(phase string) bool {
+	return slices.Contains(c.Phases, phase)
+}
+
+// Complete marks phase as completed
+func (c *Checkpoint) Complete(phase string) {
+	if !c.Completed(phase) {
+		c.Phases = append(c.Phases, phase)
+	}
+}
+
+// Store reads and writes checkpoints in a ConfigMap, one key per run
+type Store struct {
+	clientset kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewStore creates a checkpoint store backed by the named ConfigMap
+func NewStore(clientset kubernetes.Interface, namespace, name string) *Store {
+	return &Store{
+		clientset: clientset,
+		namespace: namespace,
+		name:      name,
+	}
+}
+
+// Save writes the checkpoint, replacing the previous one of its run and creating the ConfigMap if needed
+func (s *Store) Save(checkpoint *Checkpoint) error {
+	checkpoint.UpdatedAt = time.Now()
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	ctx := context.Background()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      s.name,
+					Namespace: s.namespace,
+					Labels:    map[string]string{managedByLabel: managedByValue},
+				},
+				Data: map[string]string{checkpoint.RunID: string(data)},
+			}
+			_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Create(ctx, cm, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) {
+				// Created concurrently, retry as an update
+				return apierrors.NewConflict(corev1.Resource("configmaps"), s.name, err)
+			}
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get checkpoint ConfigMap '%s': %w", s.name, err)
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[checkpoint.RunID] = string(data)
+		trimOldest(cm.Data, MaxCheckpoints)
+
+		_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// Load returns the checkpoint of a run, or ErrNotFound when there is none
+func (s *Store) Load(runID string) (*Checkpoint, error) {
+	cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(context.Background(), s.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w for run %s", ErrNotFound, runID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checkpoint ConfigMap '%s': %w", s.name, err)
+	}
+
+	value, ok := cm.Data[runID]
+	if !ok {
+		return nil, fmt.Errorf("%w for run %s", ErrNotFound, runID)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal([]byte(value), &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint of run %s: %w", runID, err)
+	}
+	return &checkpoint, nil
+}
+
+// Delete removes the checkpoint of a run, if any
+func (s *Store) Delete(runID string) error {
+	ctx := context.Background()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get checkpoint ConfigMap '%s': %w", s.name, err)
+		}
+		if _, ok := cm.Data[runID]; !ok {
+			return nil
+		}
+
+		delete(cm.Data, runID)
+		_, err = s.clientset.CoreV1().ConfigMaps(s.namespace).Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// trimOldest removes the oldest keys until at most maxEntries remain; run IDs sort chronologically
+func trimOldest(data map[string]string, maxEntries int) {
+	if len(data) <= maxEntries {
+		return
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys[:len(keys)-maxEntries] {
+		delete(data, key)
+	}
+}
@@ -0,0 +1,88 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStore_SaveLoadDelete(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	store := NewStore(fakeClient, "test-ns", DefaultConfigMapName)
+
+	checkpoint := &Checkpoint{
+		RunID:             "20250115T030000-a1b2c3",
+		Operation:         "restore",
+		StartedAt:         time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC),
+		Snapshots:         []string{"sts-backup-20250114-0300"},
+		DropAllIndices:    true,
+		ScaledDeployments: []k8s.DeploymentScale{{Name: "receiver", Replicas: 3}},
+	}
+	require.NoError(t, store.Save(checkpoint))
+
+	checkpoint.Complete("delete-indices")
+	checkpoint.IndicesDeleted = []string{"sts_topology"}
+	checkpoint.SafetySnapshot = "sts-pre-restore-20250115-030000"
+	require.NoError(t, store.Save(checkpoint))
+
+	cm, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), DefaultConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "sts-backup", cm.Labels[managedByLabel])
+	assert.Len(t, cm.Data, 1)
+
+	loaded, err := store.Load("20250115T030000-a1b2c3")
+	require.NoError(t, err)
+	assert.True(t, loaded.Completed("delete-indices"))
+	assert.False(t, loaded.Completed("restore"))
+	assert.Equal(t, []string{"sts_topology"}, loaded.IndicesDeleted)
+	assert.Equal(t, "sts-pre-restore-20250115-030000", loaded.SafetySnapshot)
+	assert.Equal(t, []k8s.DeploymentScale{{Name: "receiver", Replicas: 3}}, loaded.ScaledDeployments)
+	assert.True(t, loaded.DropAllIndices)
+	assert.False(t, loaded.UpdatedAt.IsZero())
+
+	require.NoError(t, store.Delete("20250115T030000-a1b2c3"))
+	_, err = store.Load("20250115T030000-a1b2c3")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_LoadWithoutConfigMap(t *testing.T) {
+	store := NewStore(fake.NewSimpleClientset(), "test-ns", DefaultConfigMapName)
+
+	_, err := store.Load("20250115T030000-a1b2c3")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete("20250115T030000-a1b2c3"))
+}
+
+func TestStore_DropsOldestCheckpoints(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	store := NewStore(fakeClient, "test-ns", DefaultConfigMapName)
+
+	for i := range MaxCheckpoints + 2 {
+		require.NoError(t, store.Save(&Checkpoint{RunID: fmt.Sprintf("20250115T0300%02d-a1b2c3", i), Operation: "restore"}))
+	}
+
+	cm, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), DefaultConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, cm.Data, MaxCheckpoints)
+	assert.NotContains(t, cm.Data, "20250115T030000-a1b2c3")
+	assert.NotContains(t, cm.Data, "20250115T030001-a1b2c3")
+	assert.Contains(t, cm.Data, "20250115T030021-a1b2c3")
+}
+
+func TestCheckpoint_Complete(t *testing.T) {
+	checkpoint := &Checkpoint{}
+
+	checkpoint.Complete("delete-indices")
+	checkpoint.Complete("restore")
+	checkpoint.Complete("delete-indices")
+
+	assert.Equal(t, []string{"delete-indices", "restore"}, checkpoint.Phases)
+}
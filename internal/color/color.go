@@ -0,0 +1,74 @@
+// Package color provides ANSI terminal coloring with automatic TTY detection.
+// Colors are only emitted when the target writer is a terminal, and can be
+// disabled globally with the --no-color flag or the NO_COLOR environment variable.
+package color
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Code represents an ANSI SGR color code
+type Code string
+
+// All codes have the same length so colored table cells stay aligned
+const (
+	Red     Code = "31"
+	Green   Code = "32"
+	Yellow  Code = "33"
+	Default Code = "39"
+
+	reset = "\x1b[0m"
+)
+
+var (
+	// disabled turns off colors for every writer (NO_COLOR convention, see https://no-color.org)
+	disabled = os.Getenv("NO_COLOR") != ""
+
+	// isTerminal reports whether a writer is attached to a terminal (overridable in tests)
+	isTerminal = func(w io.Writer) bool {
+		f, ok := w.(*os.File)
+		return ok && term.IsTerminal(int(f.Fd()))
+	}
+)
+
+// Disable turns off colored output globally
+func Disable() {
+	disabled = true
+}
+
+// Enabled reports whether colors should be written to w
+func Enabled(w io.Writer) bool {
+	return !disabled && isTerminal(w)
+}
+
+// Sprint wraps s in the given color if colors are enabled for w
+func Sprint(w io.Writer, code Code, s string) string {
+	if !Enabled(w) {
+		return s
+	}
+	return "\x1b[" + string(code) + "m" + s + reset
+}
+
+// ForState returns the color associated with a state value
+// (snapshot states SUCCESS, PARTIAL, FAILED, index health green, yellow, red or check results PASS, WARN, FAIL)
+func ForState(state string) Code {
+	switch strings.ToUpper(state) {
+	case "SUCCESS", "GREEN", "PASS", "OK":
+		return Green
+	case "PARTIAL", "IN_PROGRESS", "CANCELLED", "YELLOW", "WARN", "DEGRADED":
+		return Yellow
+	case "FAILED", "INCOMPATIBLE", "RED", "FAIL", "READ-ONLY", "UNAVAILABLE", "OFFLINE":
+		return Red
+	default:
+		return Default
+	}
+}
+
+// State colors a state value for w according to ForState
+func State(w io.Writer, state string) string {
+	return Sprint(w, ForState(state), state)
+}
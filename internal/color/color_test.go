@@ -0,0 +1,90 @@
+package color
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// forceTerminal makes every writer look like a terminal for the duration of a test
+func forceTerminal(t *testing.T, enabled bool) {
+	t.Helper()
+	origTerminal := isTerminal
+	origDisabled := disabled
+	isTerminal = func(_ io.Writer) bool { return true }
+	disabled = !enabled
+	t.Cleanup(func() {
+		isTerminal = origTerminal
+		disabled = origDisabled
+	})
+}
+
+func TestSprint_NonTerminal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.False(t, Enabled(buf))
+	assert.Equal(t, "text", Sprint(buf, Green, "text"))
+}
+
+func TestSprint_Terminal(t *testing.T) {
+	forceTerminal(t, true)
+
+	buf := &bytes.Buffer{}
+	assert.True(t, Enabled(buf))
+	assert.Equal(t, "\x1b[32mtext\x1b[0m", Sprint(buf, Green, "text"))
+}
+
+func TestDisable(t *testing.T) {
+	forceTerminal(t, true)
+
+	Disable()
+
+	buf := &bytes.Buffer{}
+	assert.False(t, Enabled(buf))
+	assert.Equal(t, "text", Sprint(buf, Red, "text"))
+}
+
+func TestForState(t *testing.T) {
+	tests := []struct {
+		state    string
+		expected Code
+	}{
+		{state: "SUCCESS", expected: Green},
+		{state: "success", expected: Green},
+		{state: "PARTIAL", expected: Yellow},
+		{state: "IN_PROGRESS", expected: Yellow},
+		{state: "FAILED", expected: Red},
+		{state: "INCOMPATIBLE", expected: Red},
+		{state: "green", expected: Green},
+		{state: "yellow", expected: Yellow},
+		{state: "red", expected: Red},
+		{state: "PASS", expected: Green},
+		{state: "WARN", expected: Yellow},
+		{state: "FAIL", expected: Red},
+		{state: "ok", expected: Green},
+		{state: "degraded", expected: Yellow},
+		{state: "read-only", expected: Red},
+		{state: "offline", expected: Red},
+		{state: "SKIP", expected: Default},
+		{state: "UNKNOWN", expected: Default},
+		{state: "", expected: Default},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ForState(tt.state))
+		})
+	}
+}
+
+func TestState_KeepsWidthConsistent(t *testing.T) {
+	forceTerminal(t, true)
+
+	buf := &bytes.Buffer{}
+	success := State(buf, "SUCCESS")
+	unknown := State(buf, "UNKNOWN")
+
+	// Every colored cell carries the same number of escape bytes so tabwriter columns stay aligned
+	assert.Equal(t, len(success)-len("SUCCESS"), len(unknown)-len("UNKNOWN"))
+}
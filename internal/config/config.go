@@ -5,18 +5,128 @@ package config
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"dario.cat/mergo"
-	"github.com/go-playground/validator/v10"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
+const (
+	// DefaultConfigMapName is the default name of the ConfigMap holding the backup configuration
+	DefaultConfigMapName = "suse-observability-backup-config"
+	// DefaultSecretName is the default name of the Secret holding the backup credentials
+	DefaultSecretName = "suse-observability-backup-config"
+
+	// runIDTimeFormat is the timestamp part of a run ID
+	runIDTimeFormat = "20060102T150405"
+	// runIDRandomBytes is the number of random bytes appended to a run ID
+	runIDRandomBytes = 3
+)
+
 // Config represents the merged configuration from ConfigMap and Secret
 type Config struct {
 	Elasticsearch ElasticsearchConfig `yaml:"elasticsearch" validate:"required"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Archives      ArchivesConfig      `yaml:"archives"`
+	Kafka         KafkaConfig         `yaml:"kafka"`
+	Postgres      PostgresConfig      `yaml:"postgres"`
+	StackGraph    StackGraphConfig    `yaml:"stackgraph"`
+}
+
+// KafkaConfig locates the Kafka brokers whose metadata is snapshotted with 'kafka metadata'. The Kafka command
+// line tools are run in a broker pod.
+type KafkaConfig struct {
+	PodSelector     string `yaml:"podSelector"`     // Label selector of the broker pods
+	Container       string `yaml:"container"`       // Container of the broker pods with the Kafka tools
+	BootstrapServer string `yaml:"bootstrapServer"` // Address of the brokers from inside a broker pod
+	// ZookeeperConnect is the Zookeeper connection string of a Zookeeper-based cluster, empty for KRaft
+	ZookeeperConnect string `yaml:"zookeeperConnect"`
+}
+
+// PostgresConfig locates the PostgreSQL server whose databases are dumped with 'postgres'. pg_dump and psql are run
+// in the server pod.
+type PostgresConfig struct {
+	PodSelector string `yaml:"podSelector"` // Label selector of the server pods
+	Container   string `yaml:"container"`   // Container of the server pods with pg_dump and psql
+	User        string `yaml:"user"`        // User the tools connect as, a superuser to drain connections
+	// PasswordEnv is the environment variable of the container holding the password of the user
+	PasswordEnv string   `yaml:"passwordEnv"`
+	Databases   []string `yaml:"databases" validate:"omitempty,dive,required"` // Databases dumped by default
+	// ScaleDownLabelSelector selects the Deployments using the databases, scaled down while a dump is restored
+	ScaleDownLabelSelector string `yaml:"scaleDownLabelSelector"`
+}
+
+// StackGraphConfig locates the HBase cluster of StackGraph whose tables are snapshotted with 'stackgraph hbase'. The
+// HBase shell and ExportSnapshot are run in an HBase pod.
+type StackGraphConfig struct {
+	PodSelector string `yaml:"podSelector"` // Label selector of the pods with the HBase tools, e.g. the masters
+	Container   string `yaml:"container"`   // Container of the pods with the HBase tools
+	// Tables are the tables snapshotted, all tables outside the hbase namespace when empty
+	Tables       []string `yaml:"tables" validate:"omitempty,dive,required"`
+	ExportPrefix string   `yaml:"exportPrefix"` // Key prefix in the backup bucket snapshots are exported under
+	// ScaleDownLabelSelector selects the Deployments writing to StackGraph, scaled down while snapshots are restored
+	ScaleDownLabelSelector string `yaml:"scaleDownLabelSelector"`
+	// ScaleDownStatefulSetSelectors select StatefulSets scaled down after the Deployments, e.g. the transaction server
+	ScaleDownStatefulSetSelectors []string `yaml:"scaleDownStatefulSetSelectors" validate:"omitempty,dive,required"`
+}
+
+// ArchivesConfig holds settings of backup artifacts exported from the cluster, e.g. to local files or an offsite bucket
+type ArchivesConfig struct {
+	Encryption ArchiveEncryptionConfig `yaml:"encryption"`
+}
+
+// ArchiveEncryptionConfig references the Secret holding the key exported archives are encrypted with.
+// Archives are written unencrypted when no Secret is configured.
+type ArchiveEncryptionConfig struct {
+	SecretName string `yaml:"secretName"`
+	SecretKey  string `yaml:"secretKey"` // Key in the Secret, default "key"
+}
+
+// NotificationsConfig holds the optional targets notified when an operation completes or fails
+type NotificationsConfig struct {
+	Webhook       WebhookConfig       `yaml:"webhook"`
+	Slack         SlackConfig         `yaml:"slack"`
+	Observability ObservabilityConfig `yaml:"observability"`
+	OnlyOnFailure bool                `yaml:"onlyOnFailure"`
+}
+
+// WebhookConfig holds a generic webhook receiving the JSON notification payload
+type WebhookConfig struct {
+	URL     string            `yaml:"url" validate:"omitempty,url"`
+	Headers map[string]string `yaml:"headers"` // e.g. Authorization, usually from secret
+}
+
+// SlackConfig holds a Slack incoming webhook
+type SlackConfig struct {
+	WebhookURL string `yaml:"webhookUrl" validate:"omitempty,url"` // Usually from secret
+}
+
+// ObservabilityConfig holds the receiver of a SUSE Observability instance that gets the outcome of operations as a
+// health state on a topology component, so backup failures show up on the dashboards operators already watch
+type ObservabilityConfig struct {
+	Endpoint string `yaml:"endpoint" validate:"omitempty,url"`       // Receiver URL, e.g. https://observability.example.com/receiver
+	Token    string `yaml:"token" validate:"required_with=Endpoint"` // API key of the receiver, usually from secret
+	// ComponentIdentifier is the identifier of the topology component the health state is shown on
+	ComponentIdentifier string `yaml:"componentIdentifier" validate:"required_with=Endpoint"`
+	// StreamURN identifies the health stream, default DefaultHealthStreamURN
+	StreamURN string `yaml:"streamUrn"`
+}
+
+// Enabled reports whether the outcome of operations is sent to SUSE Observability
+func (o ObservabilityConfig) Enabled() bool {
+	return o.Endpoint != ""
 }
 
 // ElasticsearchConfig holds Elasticsearch-specific configuration
@@ -25,6 +135,39 @@ type ElasticsearchConfig struct {
 	Restore            RestoreConfig            `yaml:"restore" validate:"required"`
 	SnapshotRepository SnapshotRepositoryConfig `yaml:"snapshotRepository" validate:"required"`
 	SLM                SLMConfig                `yaml:"slm" validate:"required"`
+	// SLMPolicies are additional SLM policies, e.g. hourly snapshots with a short retention next to the daily slm policy
+	SLMPolicies []SLMConfig `yaml:"slmPolicies" validate:"omitempty,dive"`
+	Auth        AuthConfig  `yaml:"auth"`
+	// BackupWindow is the time of day snapshots are taken and retention is enforced, checked by create-snapshot,
+	// enforce-retention and configure
+	BackupWindow BackupWindowConfig `yaml:"backupWindow"`
+}
+
+// AuthTypeServiceAccountToken authenticates to Elasticsearch with a Kubernetes ServiceAccount token
+const AuthTypeServiceAccountToken = "serviceAccountToken"
+
+// AuthConfig selects how the CLI authenticates to Elasticsearch. The requests are not authenticated unless a type is
+// configured.
+type AuthConfig struct {
+	// Type is none or serviceAccountToken, for Elasticsearch security integrated with Kubernetes ServiceAccount
+	// token authentication: the token is sent as a bearer token, so no static credentials are needed
+	Type string `yaml:"type" validate:"omitempty,oneof=none serviceAccountToken"`
+	// TokenFile is the token mounted in the pod, read when the CLI runs in-cluster
+	TokenFile string `yaml:"tokenFile"`
+	// ServiceAccount is the ServiceAccount a token is requested for with the TokenRequest API outside of the cluster
+	ServiceAccount string `yaml:"serviceAccount"`
+	// Audiences are the audiences of the requested token, the audience of the API server when empty
+	Audiences []string `yaml:"audiences"`
+}
+
+// ServiceAccountToken reports whether the requests are authenticated with a ServiceAccount token
+func (a AuthConfig) ServiceAccountToken() bool {
+	return a.Type == AuthTypeServiceAccountToken
+}
+
+// Policies returns all SLM policies to configure: the slm policy followed by the slmPolicies
+func (e *ElasticsearchConfig) Policies() []SLMConfig {
+	return append([]SLMConfig{e.SLM}, e.SLMPolicies...)
 }
 
 // RestoreConfig holds restore-specific configuration
@@ -35,6 +178,44 @@ type RestoreConfig struct {
 	DatastreamName         string `yaml:"datastreamName" validate:"required"`
 	IndicesPattern         string `yaml:"indicesPattern" validate:"required"`
 	Repository             string `yaml:"repository" validate:"required"`
+	// ScaleDownStatefulSetSelectors are optional label selectors of Elasticsearch StatefulSets, such as
+	// coordinating-only or ingest nodes, scaled down after the deployments in this order and back up in reverse
+	ScaleDownStatefulSetSelectors []string `yaml:"scaleDownStatefulSetSelectors" validate:"omitempty,dive,required"`
+	// FeatureStates lists the feature states to restore, e.g. kibana or security; "none" restores none
+	FeatureStates []string `yaml:"featureStates"`
+	// Maintenance is an optional flag that stops the receivers from accepting data during the restore
+	Maintenance MaintenanceConfig `yaml:"maintenance"`
+	// Domains map data domains, such as topology or logs, to the comma-separated index patterns of their indices,
+	// so a restore can be limited to some domains with --domain
+	Domains map[string]string `yaml:"domains" validate:"omitempty,dive,required"`
+}
+
+// DomainPattern returns the index pattern of the indices of the given data domains
+func (r *RestoreConfig) DomainPattern(domains []string) (string, error) {
+	patterns := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		pattern, ok := r.Domains[domain]
+		if !ok {
+			return "", fmt.Errorf("unknown data domain '%s' (expected one of: %s)", domain, strings.Join(slices.Sorted(maps.Keys(r.Domains)), ", "))
+		}
+		if !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return strings.Join(patterns, ","), nil
+}
+
+// MaintenanceConfig is a ConfigMap key that is set while a restore runs, so the receivers stop accepting data at the
+// ingestion edge while the writers are scaled down. It is disabled when no ConfigMap is configured.
+type MaintenanceConfig struct {
+	ConfigMap string `yaml:"configMap"`
+	Key       string `yaml:"key" validate:"required_with=ConfigMap"`
+	Value     string `yaml:"value"` // default "true"
+}
+
+// Enabled reports whether a maintenance flag is configured
+func (m MaintenanceConfig) Enabled() bool {
+	return m.ConfigMap != ""
 }
 
 // SnapshotRepositoryConfig holds snapshot repository configuration
@@ -45,6 +226,15 @@ type SnapshotRepositoryConfig struct {
 	BasePath  string `yaml:"basepath"`
 	AccessKey string `yaml:"accessKey" validate:"required"` // From secret
 	SecretKey string `yaml:"secretKey" validate:"required"` // From secret
+	// Optional S3 connection settings, defaulting to an in-cluster MinIO (see the S3 methods)
+	Protocol        string `yaml:"protocol" validate:"omitempty,oneof=http https"`
+	Region          string `yaml:"region"`
+	PathStyleAccess *bool  `yaml:"pathStyleAccess"`
+	// Optional tuning, Elasticsearch defaults apply when unset
+	ChunkSize              string `yaml:"chunkSize"`              // e.g. 1gb
+	Compress               *bool  `yaml:"compress"`               // compress metadata files
+	MaxSnapshotBytesPerSec string `yaml:"maxSnapshotBytesPerSec"` // per node, e.g. 200mb
+	MaxRestoreBytesPerSec  string `yaml:"maxRestoreBytesPerSec"`  // per node, e.g. 500mb
 }
 
 // SLMConfig holds Snapshot Lifecycle Management configuration
@@ -57,35 +247,85 @@ type SLMConfig struct {
 	RetentionExpireAfter string `yaml:"retentionExpireAfter" validate:"required"`
 	RetentionMinCount    int    `yaml:"retentionMinCount" validate:"required,min=1"`
 	RetentionMaxCount    int    `yaml:"retentionMaxCount" validate:"required,min=1"`
+	// FeatureStates lists the feature states to include in snapshots, e.g. kibana or security
+	FeatureStates []string `yaml:"featureStates"`
 }
 
 // ServiceConfig holds service connection details
 type ServiceConfig struct {
-	Name                 string `yaml:"name" validate:"required"`
+	Name string `yaml:"name" validate:"required"`
+	// MasterName is the service of the master-eligible nodes, which coordinate snapshots and restores (optional)
+	MasterName           string `yaml:"masterName"`
 	Port                 int    `yaml:"port" validate:"required,min=1,max=65535"`
 	LocalPortForwardPort int    `yaml:"localPortForwardPort" validate:"required,min=1,max=65535"`
 }
 
+// SnapshotServiceName returns the service snapshot operations go through: the master-eligible service when
+// configured, so they do not add to the load of the data nodes, and the Elasticsearch service otherwise
+func (s ServiceConfig) SnapshotServiceName() string {
+	if s.MasterName != "" {
+		return s.MasterName
+	}
+	return s.Name
+}
+
+// Defaults of the S3 connection settings of the snapshot repository, matching an in-cluster MinIO
+const (
+	DefaultS3Protocol = "http"
+	DefaultS3Region   = "minio"
+)
+
+// S3Protocol returns the protocol of the S3 endpoint, http unless configured
+func (r SnapshotRepositoryConfig) S3Protocol() string {
+	if r.Protocol != "" {
+		return r.Protocol
+	}
+	return DefaultS3Protocol
+}
+
+// S3Region returns the region of the bucket, "minio" unless configured (MinIO accepts any region)
+func (r SnapshotRepositoryConfig) S3Region() string {
+	if r.Region != "" {
+		return r.Region
+	}
+	return DefaultS3Region
+}
+
+// S3PathStyleAccess reports whether the bucket is addressed in the path instead of the host name, which MinIO and
+// Ceph RGW need unless wildcard DNS is set up. Enabled unless configured.
+func (r SnapshotRepositoryConfig) S3PathStyleAccess() bool {
+	return r.PathStyleAccess == nil || *r.PathStyleAccess
+}
+
 // LoadConfig loads and merges configuration from ConfigMap and Secret
-// ConfigMap provides base configuration, Secret overrides it
-// All required fields must be present after merging, validated with validator
-func LoadConfig(clientset kubernetes.Interface, namespace, configMapName, secretName string) (*Config, error) {
+// ConfigMap provides base configuration, Secret overrides it. With WithHelmValues the Helm values are read first and
+// the ConfigMap overrides them.
+// Fields left empty are set to their defaults (see applyDefaults) before the merged configuration is validated
+func LoadConfig(clientset kubernetes.Interface, namespace, configMapName, secretName string, opts ...LoadOption) (*Config, error) {
 	ctx := context.Background()
+	options := &loadOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	config := &Config{}
+	if options.helmValues != "" {
+		helmConfig, err := LoadHelmValues(options.helmValues)
+		if err != nil {
+			return nil, err
+		}
+		config = helmConfig
+	}
 
-	// Load ConfigMap if it exists
+	// Load ConfigMap if it exists, it is optional when Helm values are given
 	if configMapName != "" {
 		cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
 		if err != nil {
-			return nil, fmt.Errorf("failed to get ConfigMap '%s': %w", configMapName, err)
-		}
-
-		if configData, ok := cm.Data["config"]; ok {
-			if err := yaml.Unmarshal([]byte(configData), config); err != nil {
-				return nil, fmt.Errorf("failed to parse ConfigMap config: %w", err)
+			if options.helmValues == "" || !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to get ConfigMap '%s': %w", configMapName, err)
 			}
-		} else {
-			return nil, fmt.Errorf("ConfigMap '%s' does not contain 'config' key", configMapName)
+		} else if err := unmarshalConfigMap(cm.Data, configMapName, config); err != nil {
+			return nil, err
 		}
 	}
 
@@ -109,31 +349,122 @@ func LoadConfig(clientset kubernetes.Interface, namespace, configMapName, secret
 		}
 	}
 
+	applyDefaults(config)
+
 	// Validate the merged configuration
-	validate := validator.New()
-	if err := validate.Struct(config); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	if err := validateConfig(config); err != nil {
+		return nil, err
 	}
 
+	redact.Register(config.secretValues()...)
+
 	return config, nil
 }
 
+// secretValues returns the credentials in the configuration, which must never be printed
+func (c *Config) secretValues() []string {
+	values := []string{
+		c.Elasticsearch.SnapshotRepository.AccessKey,
+		c.Elasticsearch.SnapshotRepository.SecretKey,
+		c.Notifications.Webhook.URL,
+		c.Notifications.Slack.WebhookURL,
+		c.Notifications.Observability.Token,
+	}
+	for _, value := range c.Notifications.Webhook.Headers {
+		values = append(values, value)
+	}
+	return values
+}
+
+// LoadOption configures where LoadConfig reads the configuration from
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	helmValues string
+}
+
+// WithHelmValues reads the configuration from a SUSE Observability Helm values file first (see LoadHelmValues),
+// below the ConfigMap and Secret. An empty path is ignored.
+func WithHelmValues(path string) LoadOption {
+	return func(o *loadOptions) {
+		o.helmValues = path
+	}
+}
+
+// unmarshalConfigMap parses the config key of a ConfigMap over config, keeping the settings it does not set
+func unmarshalConfigMap(data map[string]string, configMapName string, config *Config) error {
+	configData, ok := data["config"]
+	if !ok {
+		return fmt.Errorf("ConfigMap '%s' does not contain 'config' key", configMapName)
+	}
+	if err := yaml.Unmarshal([]byte(configData), config); err != nil {
+		return fmt.Errorf("failed to parse ConfigMap config: %w", err)
+	}
+	return nil
+}
+
 type Context struct {
 	Config *CLIConfig
+	// RunID identifies this invocation in logs, JSON output and Kubernetes Events
+	RunID string
 }
 
 type CLIConfig struct {
 	Namespace     string
 	Kubeconfig    string
-	Debug         bool
-	Quiet         bool
+	LogLevel      logger.Level
+	Debug         bool // alias for --log-level=debug
+	Quiet         bool // alias for --log-level=error
 	ConfigMapName string
 	SecretName    string
-	OutputFormat  string // table, json
+	// HelmValues is a SUSE Observability Helm values file the configuration is read from, below the ConfigMap
+	HelmValues string
+	// AuditConfigMapName is the ConfigMap holding the audit log of destructive operations
+	AuditConfigMapName string
+	OutputFormat       string // table, json, csv, yaml
+	// OutputFile receives the output of list and report commands instead of stdout
+	OutputFile string
+	NoColor    bool
+	// As and AsGroups impersonate a user and groups for all Kubernetes requests (kubectl --as, --as-group)
+	As       string
+	AsGroups []string
+	// Proxy is the proxy URL for the Kubernetes API server and Elasticsearch (default: from the environment)
+	Proxy string
+	// MaxRequestsPerSecond limits the request rate to Elasticsearch (0 disables the limit)
+	MaxRequestsPerSecond float64
+	// AssumeYes answers yes to every confirmation prompt (required when stdin is not a terminal)
+	AssumeYes bool
+	// ElasticsearchPod is the Elasticsearch pod to port-forward to instead of selecting a pod of the service
+	ElasticsearchPod string
+	// SkipHealthCheck runs restores and configure even when Elasticsearch pods are unhealthy
+	SkipHealthCheck bool
+	// ConfirmNamespace confirms the target cluster of a destructive operation without a prompt
+	ConfirmNamespace string
+	// IgnoreBackupWindow takes snapshots and enforces retention outside of the configured backup window
+	IgnoreBackupWindow bool
+	// ReadOnly refuses every request that changes state in the Kubernetes, Elasticsearch and S3 clients
+	ReadOnly bool
+}
+
+// K8sClientOptions returns the options of the Kubernetes client for the global flags: --proxy, --as and --as-group,
+// and --read-only
+func (c *CLIConfig) K8sClientOptions() []k8s.Option {
+	return []k8s.Option{k8s.WithProxy(c.Proxy), k8s.WithImpersonation(c.As, c.AsGroups), k8s.WithReadOnly(c.ReadOnly)}
 }
 
 func NewContext() *Context {
 	return &Context{
 		Config: &CLIConfig{},
+		RunID:  NewRunID(),
+	}
+}
+
+// NewRunID generates a unique, sortable identifier for a CLI invocation (e.g. 20250115T030000-a1b2c3)
+func NewRunID() string {
+	suffix := make([]byte, runIDRandomBytes)
+	if _, err := rand.Read(suffix); err != nil {
+		// crypto/rand never fails on supported platforms; fall back to the timestamp alone
+		return time.Now().UTC().Format(runIDTimeFormat)
 	}
+	return time.Now().UTC().Format(runIDTimeFormat) + "-" + hex.EncodeToString(suffix)
 }
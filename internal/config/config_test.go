@@ -4,14 +4,17 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 const invalidConfigYAML = `
@@ -55,6 +58,7 @@ func TestLoadConfig_FromConfigMapOnly(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, config)
 	assert.Equal(t, "suse-observability-elasticsearch-master-headless", config.Elasticsearch.Service.Name)
+	assert.Equal(t, "suse-observability-elasticsearch-master-headless", config.Elasticsearch.Service.SnapshotServiceName())
 	assert.Equal(t, 9200, config.Elasticsearch.Service.Port)
 	assert.Equal(t, "sts-backup", config.Elasticsearch.SnapshotRepository.Name)
 	assert.Equal(t, "configmap-access-key", config.Elasticsearch.SnapshotRepository.AccessKey)
@@ -105,6 +109,7 @@ func TestLoadConfig_CompleteConfiguration(t *testing.T) {
 
 	// Service config
 	assert.Equal(t, "suse-observability-elasticsearch-master-headless", config.Elasticsearch.Service.Name)
+	assert.Equal(t, "suse-observability-elasticsearch-master", config.Elasticsearch.Service.SnapshotServiceName())
 	assert.Equal(t, 9200, config.Elasticsearch.Service.Port)
 	assert.Equal(t, 9200, config.Elasticsearch.Service.LocalPortForwardPort)
 
@@ -464,6 +469,45 @@ func TestConfig_StructValidation(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "invalid S3 protocol",
+			config: &Config{
+				Elasticsearch: ElasticsearchConfig{
+					Service: ServiceConfig{
+						Name:                 "es-master",
+						Port:                 9200,
+						LocalPortForwardPort: 9200,
+					},
+					Restore: RestoreConfig{
+						ScaleDownLabelSelector: "app=test",
+						IndexPrefix:            "sts_",
+						DatastreamIndexPrefix:  "sts_k8s",
+						DatastreamName:         "sts_k8s",
+						IndicesPattern:         "*",
+						Repository:             "repo",
+					},
+					SnapshotRepository: SnapshotRepositoryConfig{
+						Name:      "repo",
+						Bucket:    "bucket",
+						Endpoint:  "endpoint",
+						AccessKey: "key",
+						SecretKey: "secret",
+						Protocol:  "ftp", // Invalid - must be http or https
+					},
+					SLM: SLMConfig{
+						Name:                 "slm",
+						Schedule:             "0 0 * * *",
+						SnapshotTemplateName: "snap",
+						Repository:           "repo",
+						Indices:              "*",
+						RetentionExpireAfter: "30d",
+						RetentionMinCount:    1,
+						RetentionMaxCount:    10,
+					},
+				},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -480,3 +524,327 @@ func TestConfig_StructValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestNewContext_GeneratesRunID(t *testing.T) {
+	first := NewContext()
+	second := NewContext()
+
+	assert.Regexp(t, `^\d{8}T\d{6}-[0-9a-f]{6}$`, first.RunID)
+	assert.NotEqual(t, first.RunID, second.RunID)
+}
+
+func TestLoadConfig_Notifications(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	validConfigYAML := loadTestData(t, "validConfigMapOnly.yaml")
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-config",
+			Namespace: "test-ns",
+		},
+		Data: map[string]string{
+			"config": validConfigYAML,
+		},
+	}
+	_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	// Notification endpoints usually carry tokens, so they come from the Secret
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-secret",
+			Namespace: "test-ns",
+		},
+		Data: map[string][]byte{
+			"config": []byte(`
+notifications:
+  onlyOnFailure: true
+  webhook:
+    url: https://hooks.example.com/backup
+    headers:
+      Authorization: Bearer token
+  slack:
+    webhookUrl: https://hooks.slack.com/services/T000/B000/XXXX
+  observability:
+    endpoint: https://observability.example.com/receiver
+    token: api-key
+    componentIdentifier: urn:kubernetes:/cluster:suse-observability:statefulset/elasticsearch
+`),
+		},
+	}
+	_, err = fakeClient.CoreV1().Secrets("test-ns").Create(context.Background(), secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "backup-secret")
+	require.NoError(t, err)
+	assert.True(t, config.Notifications.OnlyOnFailure)
+	assert.Equal(t, "https://hooks.example.com/backup", config.Notifications.Webhook.URL)
+	assert.Equal(t, "Bearer token", config.Notifications.Webhook.Headers["Authorization"])
+	assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", config.Notifications.Slack.WebhookURL)
+	assert.Equal(t, "api-key", config.Notifications.Observability.Token)
+	assert.Equal(t, DefaultHealthStreamURN, config.Notifications.Observability.StreamURN)
+}
+
+func TestLoadConfig_ArchiveEncryption(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-config",
+			Namespace: "test-ns",
+		},
+		Data: map[string]string{
+			"config": loadTestData(t, "validConfigMapOnly.yaml") + `
+archives:
+  encryption:
+    secretName: backup-encryption-key
+`,
+		},
+	}
+	_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "")
+	require.NoError(t, err)
+	assert.Equal(t, "backup-encryption-key", config.Archives.Encryption.SecretName)
+	assert.Empty(t, config.Archives.Encryption.SecretKey)
+}
+
+func TestLoadConfig_FeatureStates(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-config",
+			Namespace: "test-ns",
+		},
+		Data: map[string]string{
+			"config": loadTestData(t, "validConfigMapOnly.yaml"),
+		},
+	}
+	_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-secret",
+			Namespace: "test-ns",
+		},
+		Data: map[string][]byte{
+			"config": []byte(`
+elasticsearch:
+  slm:
+    featureStates: [kibana, security]
+  restore:
+    featureStates: [none]
+`),
+		},
+	}
+	_, err = fakeClient.CoreV1().Secrets("test-ns").Create(context.Background(), secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "backup-secret")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kibana", "security"}, config.Elasticsearch.SLM.FeatureStates)
+	assert.Equal(t, []string{"none"}, config.Elasticsearch.Restore.FeatureStates)
+	// Merging the Secret keeps the other SLM settings of the ConfigMap
+	assert.Equal(t, "auto-sts-backup", config.Elasticsearch.SLM.Name)
+}
+
+func TestLoadConfig_SLMPolicies(t *testing.T) {
+	tests := []struct {
+		name          string
+		policies      string
+		expectError   string
+		expectedNames []string
+	}{
+		{
+			name: "additional policies",
+			policies: `
+  slmPolicies:
+    - name: hourly-sts-backup
+      schedule: "0 0 * * * ?"
+      snapshotTemplateName: "<sts-hourly-{now{yyyyMMdd-HHmm}}>"
+      repository: sts-backup
+      indices: "sts*"
+      retentionExpireAfter: 1d
+      retentionMinCount: 1
+      retentionMaxCount: 24
+`,
+			expectedNames: []string{"auto-sts-backup", "hourly-sts-backup"},
+		},
+		{
+			name:          "no additional policies",
+			expectedNames: []string{"auto-sts-backup"},
+		},
+		{
+			name: "incomplete policy",
+			policies: `
+  slmPolicies:
+    - name: hourly-sts-backup
+      schedule: "0 0 * * * ?"
+`,
+			expectError: "configuration validation failed",
+		},
+		{
+			name: "duplicate policy name",
+			policies: `
+  slmPolicies:
+    - name: auto-sts-backup
+      schedule: "0 0 * * * ?"
+      snapshotTemplateName: "<sts-hourly-{now{yyyyMMdd-HHmm}}>"
+      repository: sts-backup
+      indices: "sts*"
+      retentionExpireAfter: 1d
+      retentionMinCount: 1
+      retentionMaxCount: 24
+`,
+			expectError: "SLM policy name 'auto-sts-backup' is used more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset()
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "backup-config",
+					Namespace: "test-ns",
+				},
+				Data: map[string]string{
+					"config": loadTestData(t, "validConfigMapOnly.yaml") + tt.policies,
+				},
+			}
+			_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Create(context.Background(), cm, metav1.CreateOptions{})
+			require.NoError(t, err)
+
+			config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "")
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+
+			var names []string
+			for _, policy := range config.Elasticsearch.Policies() {
+				names = append(names, policy.Name)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+}
+
+func TestLoadConfig_RepositoryTuning(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-config",
+			Namespace: "test-ns",
+		},
+		Data: map[string]string{
+			"config": loadTestData(t, "validConfigMapOnly.yaml"),
+		},
+	}
+	_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backup-secret",
+			Namespace: "test-ns",
+		},
+		Data: map[string][]byte{
+			"config": []byte(`
+elasticsearch:
+  snapshotRepository:
+    chunkSize: 1gb
+    compress: false
+    maxSnapshotBytesPerSec: 200mb
+    maxRestoreBytesPerSec: 500mb
+`),
+		},
+	}
+	_, err = fakeClient.CoreV1().Secrets("test-ns").Create(context.Background(), secret, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "backup-secret")
+	require.NoError(t, err)
+
+	repo := config.Elasticsearch.SnapshotRepository
+	assert.Equal(t, "1gb", repo.ChunkSize)
+	require.NotNil(t, repo.Compress)
+	assert.False(t, *repo.Compress)
+	assert.Equal(t, "200mb", repo.MaxSnapshotBytesPerSec)
+	assert.Equal(t, "500mb", repo.MaxRestoreBytesPerSec)
+	// The other repository settings of the ConfigMap are kept
+	assert.Equal(t, "sts-backup", repo.Name)
+}
+
+func TestSnapshotRepositoryConfig_S3Settings(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name              string
+		repo              SnapshotRepositoryConfig
+		expectedProtocol  string
+		expectedRegion    string
+		expectedPathStyle bool
+	}{
+		{
+			name:              "defaults match MinIO",
+			repo:              SnapshotRepositoryConfig{},
+			expectedProtocol:  "http",
+			expectedRegion:    "minio",
+			expectedPathStyle: true,
+		},
+		{
+			name:              "AWS S3",
+			repo:              SnapshotRepositoryConfig{Protocol: "https", Region: "eu-west-1", PathStyleAccess: &disabled},
+			expectedProtocol:  "https",
+			expectedRegion:    "eu-west-1",
+			expectedPathStyle: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedProtocol, tt.repo.S3Protocol())
+			assert.Equal(t, tt.expectedRegion, tt.repo.S3Region())
+			assert.Equal(t, tt.expectedPathStyle, tt.repo.S3PathStyleAccess())
+		})
+	}
+}
+
+func TestLoadConfig_RegistersSecretsForRedaction(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-config", Namespace: "test-ns"},
+		Data: map[string]string{"config": strings.ReplaceAll(minimalConfigYAML,
+			"secretKey: secret", "secretKey: redaction-test-secret-key")},
+	})
+
+	_, err := LoadConfig(fakeClient, "test-ns", "backup-config", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "secret_key=****", redact.String("secret_key=redaction-test-secret-key"))
+}
+
+func TestRestoreConfig_DomainPattern(t *testing.T) {
+	restore := RestoreConfig{Domains: map[string]string{"topology": "sts_topology*", "logs": ".ds-sts_k8s_logs*", "all-logs": ".ds-sts_k8s_logs*"}}
+
+	pattern, err := restore.DomainPattern([]string{"topology", "logs", "all-logs"})
+	require.NoError(t, err)
+	assert.Equal(t, "sts_topology*,.ds-sts_k8s_logs*", pattern)
+
+	_, err = restore.DomainPattern([]string{"traces"})
+	assert.EqualError(t, err, "unknown data domain 'traces' (expected one of: all-logs, logs, topology)")
+}
+
+func TestCLIConfig_K8sClientOptions(t *testing.T) {
+	cliConfig := &CLIConfig{As: "jane", AsGroups: []string{"ops"}, ReadOnly: true}
+
+	restConfig := &rest.Config{}
+	for _, opt := range cliConfig.K8sClientOptions() {
+		require.NoError(t, opt(restConfig))
+	}
+	assert.Equal(t, rest.ImpersonationConfig{UserName: "jane", Groups: []string{"ops"}}, restConfig.Impersonate)
+	assert.NotNil(t, restConfig.WrapTransport)
+}
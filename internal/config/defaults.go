@@ -0,0 +1,139 @@
+package config
+
+// Defaults of the SUSE Observability installation, applied to fields left empty in the ConfigMap and Secret
+const (
+	DefaultElasticsearchPort      = 9200
+	DefaultRepositoryName         = "sts-backup"
+	DefaultScaleDownLabelSelector = "observability.suse.com/scalable-during-es-restore=true"
+	DefaultIndexPrefix            = "sts"
+	DefaultDatastreamIndexPrefix  = ".ds-sts_k8s_logs"
+	DefaultDatastreamName         = "sts_k8s_logs"
+	DefaultIndicesPattern         = "sts*,.ds-sts_k8s_logs*"
+	DefaultMaintenanceValue       = "true"
+	DefaultHealthStreamURN        = "urn:health:sts-backup:operations"
+	DefaultKafkaPodSelector       = "app.kubernetes.io/name=kafka"
+	DefaultKafkaContainer         = "kafka"
+	DefaultKafkaBootstrapServer   = "localhost:9092"
+	DefaultPostgresPodSelector    = "app.kubernetes.io/name=postgresql"
+	DefaultPostgresContainer      = "postgresql"
+	DefaultPostgresUser           = "postgres"
+	DefaultPostgresPasswordEnv    = "POSTGRES_PASSWORD"
+	DefaultStackGraphPodSelector  = "app.kubernetes.io/component=hbase-master"
+	DefaultStackGraphContainer    = "master"
+	DefaultStackGraphExportPrefix = "hbase"
+	// DefaultStackGraphScaleDownLabelSelector selects the Deployments writing to StackGraph
+	DefaultStackGraphScaleDownLabelSelector = "observability.suse.com/scalable-during-stackgraph-restore=true"
+	// DefaultServiceAccountTokenFile is where Kubernetes mounts the token of the ServiceAccount of a pod
+	DefaultServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// DefaultBackupWindowTimezone is the time zone SLM evaluates its schedules in
+	DefaultBackupWindowTimezone = "UTC"
+
+	DefaultSLMName                 = "auto-sts-backup"
+	DefaultSLMSchedule             = "0 0 3 * * ?"
+	DefaultSLMSnapshotTemplateName = "<sts-backup-{now{yyyyMMdd-HHmm}}>"
+	DefaultSLMIndices              = "sts*"
+	DefaultSLMRetentionExpireAfter = "30d"
+	DefaultSLMRetentionMinCount    = 5
+	DefaultSLMRetentionMaxCount    = 30
+)
+
+// DefaultRestoreDomains are the data domains of the SUSE Observability indices, see RestoreConfig.Domains
+var DefaultRestoreDomains = map[string]string{
+	"topology": "sts_topology*",
+	"metrics":  "sts_multi_metrics*",
+	"logs":     ".ds-sts_k8s_logs*",
+	"events":   "sts_generic_events*,sts_state_events*,sts_internal_events*",
+}
+
+// applyDefaults fills in the fields that are the same for every SUSE Observability installation, so only the
+// environment-specific values (service name, bucket, endpoint and credentials) have to be configured.
+// The snapshot repository name is reused as the repository of the restore and SLM sections.
+func applyDefaults(config *Config) {
+	es := &config.Elasticsearch
+
+	service := &es.Service
+	if service.Port == 0 {
+		service.Port = DefaultElasticsearchPort
+	}
+	if service.LocalPortForwardPort == 0 {
+		service.LocalPortForwardPort = service.Port
+	}
+
+	repository := &es.SnapshotRepository
+	defaultString(&repository.Name, DefaultRepositoryName)
+
+	restore := &es.Restore
+	defaultString(&restore.Repository, repository.Name)
+	defaultString(&restore.ScaleDownLabelSelector, DefaultScaleDownLabelSelector)
+	defaultString(&restore.IndexPrefix, DefaultIndexPrefix)
+	defaultString(&restore.DatastreamIndexPrefix, DefaultDatastreamIndexPrefix)
+	defaultString(&restore.DatastreamName, DefaultDatastreamName)
+	defaultString(&restore.IndicesPattern, DefaultIndicesPattern)
+	// Configured domains replace the default domain of the same name, the other default domains are kept
+	if restore.Domains == nil {
+		restore.Domains = make(map[string]string, len(DefaultRestoreDomains))
+	}
+	for domain, pattern := range DefaultRestoreDomains {
+		if _, ok := restore.Domains[domain]; !ok {
+			restore.Domains[domain] = pattern
+		}
+	}
+	if restore.Maintenance.Enabled() {
+		defaultString(&restore.Maintenance.Value, DefaultMaintenanceValue)
+	}
+
+	if es.Auth.ServiceAccountToken() {
+		defaultString(&es.Auth.TokenFile, DefaultServiceAccountTokenFile)
+	}
+
+	if es.BackupWindow.Enabled() {
+		defaultString(&es.BackupWindow.Timezone, DefaultBackupWindowTimezone)
+	}
+
+	slm := &es.SLM
+	defaultString(&slm.Name, DefaultSLMName)
+	defaultString(&slm.Schedule, DefaultSLMSchedule)
+	defaultString(&slm.SnapshotTemplateName, DefaultSLMSnapshotTemplateName)
+	defaultString(&slm.Repository, repository.Name)
+	defaultString(&slm.Indices, DefaultSLMIndices)
+	defaultString(&slm.RetentionExpireAfter, DefaultSLMRetentionExpireAfter)
+	if slm.RetentionMinCount == 0 {
+		slm.RetentionMinCount = DefaultSLMRetentionMinCount
+	}
+	if slm.RetentionMaxCount == 0 {
+		slm.RetentionMaxCount = DefaultSLMRetentionMaxCount
+	}
+
+	// Additional policies differ from the slm policy by definition, only the repository is shared
+	for i := range es.SLMPolicies {
+		defaultString(&es.SLMPolicies[i].Repository, repository.Name)
+	}
+
+	if observability := &config.Notifications.Observability; observability.Enabled() {
+		defaultString(&observability.StreamURN, DefaultHealthStreamURN)
+	}
+
+	kafka := &config.Kafka
+	defaultString(&kafka.PodSelector, DefaultKafkaPodSelector)
+	defaultString(&kafka.Container, DefaultKafkaContainer)
+	defaultString(&kafka.BootstrapServer, DefaultKafkaBootstrapServer)
+
+	postgres := &config.Postgres
+	defaultString(&postgres.PodSelector, DefaultPostgresPodSelector)
+	defaultString(&postgres.Container, DefaultPostgresContainer)
+	defaultString(&postgres.User, DefaultPostgresUser)
+	defaultString(&postgres.PasswordEnv, DefaultPostgresPasswordEnv)
+
+	stackGraph := &config.StackGraph
+	defaultString(&stackGraph.PodSelector, DefaultStackGraphPodSelector)
+	defaultString(&stackGraph.Container, DefaultStackGraphContainer)
+	defaultString(&stackGraph.ExportPrefix, DefaultStackGraphExportPrefix)
+	defaultString(&stackGraph.ScaleDownLabelSelector, DefaultStackGraphScaleDownLabelSelector)
+}
+
+// defaultString sets value to def when it is empty
+func defaultString(value *string, def string) {
+	if *value == "" {
+		*value = def
+	}
+}
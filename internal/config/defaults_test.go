@@ -0,0 +1,140 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const minimalConfigYAML = `
+elasticsearch:
+  service:
+    name: suse-observability-elasticsearch-master-headless
+  snapshotRepository:
+    bucket: sts-elasticsearch-backup
+    endpoint: suse-observability-minio:9000
+    accessKey: key
+    secretKey: secret
+`
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-config", Namespace: "test-ns"},
+		Data:       map[string]string{"config": minimalConfigYAML},
+	})
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "")
+	require.NoError(t, err)
+
+	es := config.Elasticsearch
+	assert.Equal(t, 9200, es.Service.Port)
+	assert.Equal(t, 9200, es.Service.LocalPortForwardPort)
+	assert.Equal(t, "sts-backup", es.SnapshotRepository.Name)
+	assert.Equal(t, "sts-backup", es.Restore.Repository)
+	assert.Equal(t, "sts-backup", es.SLM.Repository)
+	assert.Equal(t, "sts", es.Restore.IndexPrefix)
+	assert.Equal(t, ".ds-sts_k8s_logs", es.Restore.DatastreamIndexPrefix)
+	assert.Equal(t, "sts_k8s_logs", es.Restore.DatastreamName)
+	assert.Equal(t, "sts*,.ds-sts_k8s_logs*", es.Restore.IndicesPattern)
+	assert.Equal(t, DefaultRestoreDomains, es.Restore.Domains)
+	assert.Equal(t, "auto-sts-backup", es.SLM.Name)
+	assert.Equal(t, 5, es.SLM.RetentionMinCount)
+	assert.Equal(t, 30, es.SLM.RetentionMaxCount)
+	assert.False(t, es.Restore.Maintenance.Enabled())
+	assert.Empty(t, es.Restore.Maintenance.Value)
+	assert.Equal(t, KafkaConfig{PodSelector: "app.kubernetes.io/name=kafka", Container: "kafka", BootstrapServer: "localhost:9092"}, config.Kafka)
+	assert.Equal(t, PostgresConfig{PodSelector: "app.kubernetes.io/name=postgresql", Container: "postgresql", User: "postgres",
+		PasswordEnv: "POSTGRES_PASSWORD"}, config.Postgres)
+	assert.Equal(t, StackGraphConfig{PodSelector: "app.kubernetes.io/component=hbase-master", Container: "master", ExportPrefix: "hbase",
+		ScaleDownLabelSelector: "observability.suse.com/scalable-during-stackgraph-restore=true"}, config.StackGraph)
+}
+
+func TestApplyDefaults(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		check  func(t *testing.T, es ElasticsearchConfig)
+	}{
+		{
+			name: "local port follows the service port",
+			config: Config{Elasticsearch: ElasticsearchConfig{
+				Service: ServiceConfig{Port: 9300},
+			}},
+			check: func(t *testing.T, es ElasticsearchConfig) {
+				assert.Equal(t, 9300, es.Service.Port)
+				assert.Equal(t, 9300, es.Service.LocalPortForwardPort)
+			},
+		},
+		{
+			name: "repository name is reused across sections",
+			config: Config{Elasticsearch: ElasticsearchConfig{
+				SnapshotRepository: SnapshotRepositoryConfig{Name: "backups"},
+				SLMPolicies:        []SLMConfig{{Name: "hourly"}},
+			}},
+			check: func(t *testing.T, es ElasticsearchConfig) {
+				assert.Equal(t, "backups", es.Restore.Repository)
+				assert.Equal(t, "backups", es.SLM.Repository)
+				assert.Equal(t, "backups", es.SLMPolicies[0].Repository)
+				assert.Empty(t, es.SLMPolicies[0].Schedule)
+			},
+		},
+		{
+			name: "maintenance flag value defaults when a ConfigMap is configured",
+			config: Config{Elasticsearch: ElasticsearchConfig{
+				Restore: RestoreConfig{Maintenance: MaintenanceConfig{ConfigMap: "receiver-config", Key: "maintenance"}},
+			}},
+			check: func(t *testing.T, es ElasticsearchConfig) {
+				assert.True(t, es.Restore.Maintenance.Enabled())
+				assert.Equal(t, "true", es.Restore.Maintenance.Value)
+			},
+		},
+		{
+			name: "token file defaults for ServiceAccount token authentication",
+			config: Config{Elasticsearch: ElasticsearchConfig{
+				Auth: AuthConfig{Type: AuthTypeServiceAccountToken},
+			}},
+			check: func(t *testing.T, es ElasticsearchConfig) {
+				assert.True(t, es.Auth.ServiceAccountToken())
+				assert.Equal(t, DefaultServiceAccountTokenFile, es.Auth.TokenFile)
+			},
+		},
+		{
+			name: "backup window timezone defaults to UTC",
+			config: Config{Elasticsearch: ElasticsearchConfig{
+				BackupWindow: BackupWindowConfig{Start: "01:00", End: "05:00"},
+			}},
+			check: func(t *testing.T, es ElasticsearchConfig) {
+				assert.Equal(t, DefaultBackupWindowTimezone, es.BackupWindow.Timezone)
+			},
+		},
+		{
+			name: "configured values are kept",
+			config: Config{Elasticsearch: ElasticsearchConfig{
+				Service: ServiceConfig{Port: 9200, LocalPortForwardPort: 19200},
+				Restore: RestoreConfig{Repository: "other", IndexPrefix: "custom", Domains: map[string]string{"topology": "custom_topology*"}},
+				SLM:     SLMConfig{RetentionMinCount: 1, RetentionMaxCount: 3},
+			}},
+			check: func(t *testing.T, es ElasticsearchConfig) {
+				assert.Equal(t, 19200, es.Service.LocalPortForwardPort)
+				assert.Equal(t, "other", es.Restore.Repository)
+				assert.Equal(t, "custom", es.Restore.IndexPrefix)
+				assert.Equal(t, "custom_topology*", es.Restore.Domains["topology"])
+				assert.Equal(t, ".ds-sts_k8s_logs*", es.Restore.Domains["logs"], "the other default domains are kept")
+				assert.Equal(t, 1, es.SLM.RetentionMinCount)
+				assert.Equal(t, 3, es.SLM.RetentionMaxCount)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			applyDefaults(&config)
+			tt.check(t, config.Elasticsearch)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// helmDefaultNodeGroup is the node group of the Elasticsearch chart, part of its service names
+	helmDefaultNodeGroup = "master"
+	// helmMinioPort is the S3 port of the MinIO service of the chart
+	helmMinioPort = 9000
+)
+
+// helmValues is the part of the SUSE Observability Helm values the backup configuration is derived from
+type helmValues struct {
+	Backup struct {
+		Elasticsearch struct {
+			BucketName             string `yaml:"bucketName"`
+			S3Prefix               string `yaml:"s3Prefix"`
+			SnapshotRepositoryName string `yaml:"snapshotRepositoryName"`
+			Scheduled              struct {
+				Schedule                     string `yaml:"schedule"`
+				SnapshotPolicyName           string `yaml:"snapshotPolicyName"`
+				SnapshotNameTemplate         string `yaml:"snapshotNameTemplate"`
+				Indices                      string `yaml:"indices"`
+				SnapshotRetentionExpireAfter string `yaml:"snapshotRetentionExpireAfter"`
+				SnapshotRetentionMinCount    int    `yaml:"snapshotRetentionMinCount"`
+				SnapshotRetentionMaxCount    int    `yaml:"snapshotRetentionMaxCount"`
+			} `yaml:"scheduled"`
+		} `yaml:"elasticsearch"`
+	} `yaml:"backup"`
+	Elasticsearch struct {
+		ClusterName string `yaml:"clusterName"`
+		NodeGroup   string `yaml:"nodeGroup"`
+	} `yaml:"elasticsearch"`
+	Minio struct {
+		FullnameOverride string `yaml:"fullnameOverride"`
+		AccessKey        string `yaml:"accessKey"`
+		SecretKey        string `yaml:"secretKey"`
+	} `yaml:"minio"`
+}
+
+// LoadHelmValues reads the backup settings from a SUSE Observability Helm values file: the backup.elasticsearch
+// subtree, the Elasticsearch service from elasticsearch.clusterName and the MinIO endpoint and credentials from
+// minio. Settings missing from the values file are left empty.
+func LoadHelmValues(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Helm values: %w", err)
+	}
+	var values helmValues
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse Helm values '%s': %w", path, err)
+	}
+	return values.config(), nil
+}
+
+// config maps the Helm values onto the backup configuration
+func (v *helmValues) config() *Config {
+	backup := v.Backup.Elasticsearch
+	scheduled := backup.Scheduled
+
+	config := &Config{}
+	es := &config.Elasticsearch
+	if v.Elasticsearch.ClusterName != "" {
+		nodeGroup := v.Elasticsearch.NodeGroup
+		if nodeGroup == "" {
+			nodeGroup = helmDefaultNodeGroup
+		}
+		es.Service.Name = fmt.Sprintf("%s-%s-headless", v.Elasticsearch.ClusterName, nodeGroup)
+		es.Service.MasterName = fmt.Sprintf("%s-%s", v.Elasticsearch.ClusterName, nodeGroup)
+	}
+
+	es.SnapshotRepository = SnapshotRepositoryConfig{
+		Name:      backup.SnapshotRepositoryName,
+		Bucket:    backup.BucketName,
+		BasePath:  backup.S3Prefix,
+		AccessKey: v.Minio.AccessKey,
+		SecretKey: v.Minio.SecretKey,
+	}
+	if v.Minio.FullnameOverride != "" {
+		es.SnapshotRepository.Endpoint = fmt.Sprintf("%s:%d", v.Minio.FullnameOverride, helmMinioPort)
+	}
+
+	es.SLM = SLMConfig{
+		Name:                 scheduled.SnapshotPolicyName,
+		Schedule:             scheduled.Schedule,
+		SnapshotTemplateName: scheduled.SnapshotNameTemplate,
+		Indices:              scheduled.Indices,
+		RetentionExpireAfter: scheduled.SnapshotRetentionExpireAfter,
+		RetentionMinCount:    scheduled.SnapshotRetentionMinCount,
+		RetentionMaxCount:    scheduled.SnapshotRetentionMaxCount,
+	}
+	return config
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLoadHelmValues(t *testing.T) {
+	config, err := LoadHelmValues(filepath.Join("testdata", "helmValues.yaml"))
+	require.NoError(t, err)
+
+	es := config.Elasticsearch
+	assert.Equal(t, "suse-observability-elasticsearch-master-headless", es.Service.Name)
+	assert.Equal(t, "suse-observability-elasticsearch-master", es.Service.MasterName)
+	assert.Equal(t, "sts-backup", es.SnapshotRepository.Name)
+	assert.Equal(t, "sts-elasticsearch-backup", es.SnapshotRepository.Bucket)
+	assert.Equal(t, "suse-observability-minio:9000", es.SnapshotRepository.Endpoint)
+	assert.Equal(t, "helm-access-key", es.SnapshotRepository.AccessKey)
+	assert.Equal(t, "helm-secret-key", es.SnapshotRepository.SecretKey)
+	assert.Equal(t, "auto-sts-backup", es.SLM.Name)
+	assert.Equal(t, "0 0 3 * * ?", es.SLM.Schedule)
+	assert.Equal(t, "30d", es.SLM.RetentionExpireAfter)
+	assert.Equal(t, 30, es.SLM.RetentionMaxCount)
+}
+
+func TestLoadHelmValues_MissingFile(t *testing.T) {
+	_, err := LoadHelmValues(filepath.Join("testdata", "nonexistent.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read Helm values")
+}
+
+func TestLoadConfig_HelmValuesOnly(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "",
+		WithHelmValues(filepath.Join("testdata", "helmValues.yaml")))
+	require.NoError(t, err)
+
+	es := config.Elasticsearch
+	assert.Equal(t, "suse-observability-elasticsearch-master-headless", es.Service.Name)
+	assert.Equal(t, 9200, es.Service.Port)
+	assert.Equal(t, "sts-backup", es.Restore.Repository)
+}
+
+func TestLoadConfig_ConfigMapOverridesHelmValues(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-config", Namespace: "test-ns"},
+		Data: map[string]string{"config": `
+elasticsearch:
+  snapshotRepository:
+    bucket: other-bucket
+`},
+	})
+
+	config, err := LoadConfig(fakeClient, "test-ns", "backup-config", "",
+		WithHelmValues(filepath.Join("testdata", "helmValues.yaml")))
+	require.NoError(t, err)
+
+	repo := config.Elasticsearch.SnapshotRepository
+	assert.Equal(t, "other-bucket", repo.Bucket)
+	assert.Equal(t, "suse-observability-minio:9000", repo.Endpoint)
+	assert.Equal(t, "helm-access-key", repo.AccessKey)
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a validation failure of a single configuration field
+type FieldError struct {
+	// Path is the YAML path of the field, e.g. elasticsearch.slm.retentionMinCount
+	Path    string
+	Message string
+}
+
+func (e FieldError) String() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every invalid field of a configuration, so all of them can be fixed in one go
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error implements the error interface, with one line per invalid field
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Fields)+1)
+	lines = append(lines, "configuration validation failed:")
+	for _, field := range e.Fields {
+		lines = append(lines, "  "+field.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validateConfig validates the struct tags, the SLM policy names and the backup window of config, returning a
+// ValidationError naming every invalid field by its YAML path
+func validateConfig(config *Config) error {
+	var fields []FieldError
+
+	var validationErrors validator.ValidationErrors
+	if err := newValidator().Struct(config); err != nil {
+		if !errors.As(err, &validationErrors) {
+			return fmt.Errorf("configuration validation failed: %w", err)
+		}
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, FieldError{Path: yamlPath(fieldErr), Message: constraintMessage(fieldErr)})
+		}
+	}
+	fields = append(fields, validateSLMPolicyNames(config.Elasticsearch.Policies())...)
+	fields = append(fields, validateBackupWindow(config.Elasticsearch.BackupWindow)...)
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// newValidator returns a validator reporting fields by their YAML name instead of the Go field name
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
+}
+
+// yamlPath returns the YAML path of the field, without the name of the root Config struct
+func yamlPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// constraintMessage describes the constraint a field violates
+func constraintMessage(fieldErr validator.FieldError) string {
+	numeric := false
+	switch fieldErr.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		numeric = true
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if numeric {
+			return "must be >= " + fieldErr.Param()
+		}
+		return "must have at least " + fieldErr.Param() + " characters"
+	case "max":
+		if numeric {
+			return "must be <= " + fieldErr.Param()
+		}
+		return "must have at most " + fieldErr.Param() + " characters"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	case "required_with":
+		return "is required when " + lowerFirst(fieldErr.Param()) + " is set"
+	case "url":
+		return "must be a valid URL"
+	default:
+		return fmt.Sprintf("fails the '%s' constraint", fieldErr.Tag())
+	}
+}
+
+// validateSLMPolicyNames rejects policies sharing a name, since each would overwrite the other in Elasticsearch.
+// policies starts with the slm policy, followed by the slmPolicies.
+func validateSLMPolicyNames(policies []SLMConfig) []FieldError {
+	var fields []FieldError
+	seen := make(map[string]bool, len(policies))
+	for i, policy := range policies {
+		if seen[policy.Name] {
+			fields = append(fields, FieldError{
+				Path:    fmt.Sprintf("elasticsearch.slmPolicies[%d].name", i-1),
+				Message: fmt.Sprintf("SLM policy name '%s' is used more than once", policy.Name),
+			})
+		}
+		seen[policy.Name] = true
+	}
+	return fields
+}
+
+// validateBackupWindow rejects a backup window whose times or time zone cannot be parsed, or that starts when it ends.
+// A missing start or end is reported by its required_with constraint.
+func validateBackupWindow(window BackupWindowConfig) []FieldError {
+	if window.Start == "" || window.End == "" {
+		return nil
+	}
+	if _, _, _, err := window.parse(); err != nil {
+		return []FieldError{{Path: "elasticsearch.backupWindow", Message: err.Error()}}
+	}
+	return nil
+}
+
+// lowerFirst returns the YAML name of a Go field name, e.g. configMap for ConfigMap
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
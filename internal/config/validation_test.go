@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLoadConfig_ValidationErrorsNameYAMLPaths(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup-config", Namespace: "test-ns"},
+		Data: map[string]string{"config": `
+elasticsearch:
+  service:
+    name: suse-observability-elasticsearch-master-headless
+    port: 70000
+  snapshotRepository:
+    endpoint: suse-observability-minio:9000
+    protocol: ftp
+  slm:
+    retentionMinCount: -1
+  slmPolicies:
+    - name: hourly
+  restore:
+    scaleDownStatefulSetSelectors: ["role=ingest", ""]
+    maintenance:
+      configMap: receiver-config
+notifications:
+  webhook:
+    url: not-a-url
+  observability:
+    endpoint: https://observability.example.com/receiver
+`},
+	}
+	_, err := fakeClient.CoreV1().ConfigMaps("test-ns").Create(context.Background(), cm, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = LoadConfig(fakeClient, "test-ns", "backup-config", "")
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	messages := make([]string, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		messages = append(messages, field.String())
+	}
+	assert.Contains(t, messages, "elasticsearch.service.port: must be <= 65535")
+	assert.Contains(t, messages, "elasticsearch.snapshotRepository.bucket: is required")
+	assert.Contains(t, messages, "elasticsearch.snapshotRepository.accessKey: is required")
+	assert.Contains(t, messages, "elasticsearch.snapshotRepository.protocol: must be one of: http, https")
+	assert.Contains(t, messages, "elasticsearch.slm.retentionMinCount: must be >= 1")
+	assert.Contains(t, messages, "elasticsearch.slmPolicies[0].schedule: is required")
+	assert.Contains(t, messages, "notifications.webhook.url: must be a valid URL")
+	assert.Contains(t, messages, "elasticsearch.restore.maintenance.key: is required when configMap is set")
+	assert.Contains(t, messages, "elasticsearch.restore.scaleDownStatefulSetSelectors[1]: is required")
+	assert.Contains(t, messages, "notifications.observability.token: is required when endpoint is set")
+
+	assert.Contains(t, err.Error(), "configuration validation failed:\n  ")
+}
+
+func TestValidateSLMPolicyNames(t *testing.T) {
+	fields := validateSLMPolicyNames([]SLMConfig{{Name: "daily"}, {Name: "hourly"}, {Name: "daily"}})
+
+	require.Len(t, fields, 1)
+	assert.Equal(t, "elasticsearch.slmPolicies[1].name", fields[0].Path)
+	assert.Equal(t, "SLM policy name 'daily' is used more than once", fields[0].Message)
+}
+
+func TestValidateBackupWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   BackupWindowConfig
+		expected string
+	}{
+		{name: "not configured"},
+		{name: "valid", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "Europe/Amsterdam"}},
+		{name: "past midnight", window: BackupWindowConfig{Start: "22:00", End: "04:00"}},
+		{name: "invalid start", window: BackupWindowConfig{Start: "1am", End: "05:00"}, expected: "invalid backup window start: '1am' is not a time of day like 01:00"},
+		{name: "invalid end", window: BackupWindowConfig{Start: "01:00", End: "24:00"}, expected: "invalid backup window end: '24:00' is not a time of day like 01:00"},
+		{name: "no duration", window: BackupWindowConfig{Start: "01:00", End: "01:00", Timezone: "UTC"}, expected: "backup window 01:00-01:00 UTC has no duration"},
+		{name: "unknown time zone", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "Mars/Olympus"}, expected: "invalid backup window timezone 'Mars/Olympus'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := validateBackupWindow(tt.window)
+
+			if tt.expected == "" {
+				assert.Empty(t, fields)
+				return
+			}
+			require.Len(t, fields, 1)
+			assert.Equal(t, "elasticsearch.backupWindow", fields[0].Path)
+			assert.Contains(t, fields[0].Message, tt.expected)
+		})
+	}
+}
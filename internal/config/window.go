@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOutsideBackupWindow is returned by BackupWindowConfig.Check for a time outside of the backup window
+var ErrOutsideBackupWindow = errors.New("outside of the backup window")
+
+// backupWindowTimeFormat is the format of the start and end of the backup window
+const backupWindowTimeFormat = "15:04"
+
+// BackupWindowConfig is the time of day snapshots may be taken and retention may be enforced, e.g. 01:00-05:00, so
+// heavy snapshots stay out of the peak ingest hours. A window that ends before it starts runs past midnight.
+// It is disabled when no start is configured.
+type BackupWindowConfig struct {
+	Start string `yaml:"start" validate:"required_with=End"` // HH:MM, e.g. 01:00
+	End   string `yaml:"end" validate:"required_with=Start"` // HH:MM, e.g. 05:00
+	// Timezone of start and end, e.g. Europe/Amsterdam, default UTC, in which SLM evaluates its schedules
+	Timezone string `yaml:"timezone"`
+}
+
+// Enabled reports whether a backup window is configured
+func (w BackupWindowConfig) Enabled() bool {
+	return w.Start != ""
+}
+
+// String returns the window as start-end and its time zone, e.g. 01:00-05:00 UTC
+func (w BackupWindowConfig) String() string {
+	return fmt.Sprintf("%s-%s %s", w.Start, w.End, w.Timezone)
+}
+
+// Contains reports whether t falls inside the window; every time is inside a window that is not enabled
+func (w BackupWindowConfig) Contains(t time.Time) (bool, error) {
+	if !w.Enabled() {
+		return true, nil
+	}
+	start, end, location, err := w.parse()
+	if err != nil {
+		return false, err
+	}
+
+	local := t.In(location)
+	timeOfDay := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	if start < end {
+		return timeOfDay >= start && timeOfDay < end, nil
+	}
+	return timeOfDay >= start || timeOfDay < end, nil
+}
+
+// Check returns an error wrapping ErrOutsideBackupWindow when now is outside of the window
+func (w BackupWindowConfig) Check(now time.Time) error {
+	inside, err := w.Contains(now)
+	if err != nil {
+		return err
+	}
+	if !inside {
+		location, _ := w.location()
+		return fmt.Errorf("%w %s (it is %s)", ErrOutsideBackupWindow, w, now.In(location).Format(backupWindowTimeFormat))
+	}
+	return nil
+}
+
+// parse returns the start and end of the window as offsets from midnight, and its time zone
+func (w BackupWindowConfig) parse() (time.Duration, time.Duration, *time.Location, error) {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid backup window start: %w", err)
+	}
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid backup window end: %w", err)
+	}
+	if start == end {
+		return 0, 0, nil, fmt.Errorf("backup window %s has no duration", w)
+	}
+	location, err := w.location()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return start, end, location, nil
+}
+
+// location returns the time zone of the window, UTC when none is configured
+func (w BackupWindowConfig) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return time.UTC, fmt.Errorf("invalid backup window timezone '%s': %w", w.Timezone, err)
+	}
+	return location, nil
+}
+
+// parseTimeOfDay parses HH:MM as an offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse(backupWindowTimeFormat, value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a time of day like 01:00", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupWindowConfig_Contains(t *testing.T) {
+	tests := []struct {
+		name     string
+		window   BackupWindowConfig
+		time     time.Time
+		expected bool
+	}{
+		{name: "not configured", time: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), expected: true},
+		{name: "inside", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}, time: time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC), expected: true},
+		{name: "at the start", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}, time: time.Date(2025, 1, 15, 1, 0, 0, 0, time.UTC), expected: true},
+		{name: "at the end", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}, time: time.Date(2025, 1, 15, 5, 0, 0, 0, time.UTC), expected: false},
+		{name: "outside", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}, time: time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC), expected: false},
+		{name: "past midnight, before midnight", window: BackupWindowConfig{Start: "22:00", End: "04:00"}, time: time.Date(2025, 1, 15, 23, 0, 0, 0, time.UTC), expected: true},
+		{name: "past midnight, after midnight", window: BackupWindowConfig{Start: "22:00", End: "04:00"}, time: time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC), expected: true},
+		{name: "past midnight, outside", window: BackupWindowConfig{Start: "22:00", End: "04:00"}, time: time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC), expected: false},
+		{name: "in the time zone of the window", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "Europe/Amsterdam"}, time: time.Date(2025, 1, 15, 3, 30, 0, 0, time.UTC), expected: true},
+		{name: "outside in the time zone of the window", window: BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "Europe/Amsterdam"}, time: time.Date(2025, 1, 15, 4, 30, 0, 0, time.UTC), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inside, err := tt.window.Contains(tt.time)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, inside)
+		})
+	}
+}
+
+func TestBackupWindowConfig_Check(t *testing.T) {
+	window := BackupWindowConfig{Start: "01:00", End: "05:00", Timezone: "UTC"}
+
+	require.NoError(t, window.Check(time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)))
+
+	err := window.Check(time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC))
+	require.ErrorIs(t, err, ErrOutsideBackupWindow)
+	assert.Equal(t, "outside of the backup window 01:00-05:00 UTC (it is 14:30)", err.Error())
+
+	_, err = BackupWindowConfig{Start: "1am", End: "05:00"}.Contains(time.Now())
+	assert.Error(t, err)
+}
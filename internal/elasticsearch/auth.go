@@ -0,0 +1,42 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+// TokenSource returns the bearer token to authenticate a request with. It is called for every request, so it has
+// to cache tokens that are expensive to get.
+type TokenSource func() (string, error)
+
+// bearerTokenTransport is an http.RoundTripper that authenticates requests with a bearer token
+type bearerTokenTransport struct {
+	next   http.RoundTripper
+	source TokenSource
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *bearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token for Elasticsearch: %w", err)
+	}
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.next.RoundTrip(req)
+}
+
+// WithBearerToken authenticates every request with a bearer token from source, such as a Kubernetes ServiceAccount
+// token that is rotated while the client is used. A nil source disables it. It must come after WithProxy, which sets
+// the base transport.
+func WithBearerToken(source TokenSource) Option {
+	return func(cfg *elasticsearch.Config) {
+		if source == nil {
+			return
+		}
+		cfg.Transport = &bearerTokenTransport{next: transportOrDefault(cfg.Transport), source: source}
+	}
+}
@@ -0,0 +1,55 @@
+package elasticsearch
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithBearerToken(t *testing.T) {
+	var authorization []string
+	server := mockESServer(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"count": 3}`))
+	})
+	defer server.Close()
+
+	tokens := []string{"first", "rotated"}
+	client, err := NewClient(server.URL, WithBearerToken(func() (string, error) {
+		token := tokens[0]
+		tokens = tokens[1:]
+		return token, nil
+	}))
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = client.CountDocuments("sts_topology")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"Bearer first", "Bearer rotated"}, authorization, "every request gets the current token")
+
+	client, err = NewClient(server.URL, WithBearerToken(func() (string, error) { return "", errors.New("token file missing") }))
+	require.NoError(t, err)
+	_, err = client.CountDocuments("sts_topology")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token file missing")
+	assert.Len(t, authorization, 2, "requests without a token are not sent")
+}
+
+func TestClient_WithBearerToken_Disabled(t *testing.T) {
+	server := mockESServer(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"count": 3}`))
+	})
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithBearerToken(nil))
+	require.NoError(t, err)
+	_, err = client.CountDocuments("sts_topology")
+	require.NoError(t, err)
+}
@@ -5,11 +5,16 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
 )
 
 // Client represents an Elasticsearch client
@@ -32,26 +37,208 @@ type IndexInfo struct {
 	DatasetSize  string `json:"dataset.size"`
 }
 
+// RecoveryInfo represents the recovery of a shard, from a snapshot, a peer or the local store, as listed by the
+// cat recovery API
+type RecoveryInfo struct {
+	Index        string `json:"index"`
+	Shard        string `json:"shard"`
+	Time         string `json:"time"`
+	Type         string `json:"type"`
+	Stage        string `json:"stage"`
+	SourceNode   string `json:"source_node"`
+	TargetNode   string `json:"target_node"`
+	Repository   string `json:"repository"`
+	Snapshot     string `json:"snapshot"`
+	FilesPercent string `json:"files_percent"`
+	BytesPercent string `json:"bytes_percent"`
+	BytesTotal   string `json:"bytes_total"`
+}
+
+// ShardInfo represents a copy of a shard as listed by the cat shards API
+type ShardInfo struct {
+	Index  string `json:"index"`
+	Shard  string `json:"shard"`
+	Prirep string `json:"prirep"`
+	State  string `json:"state"`
+	Node   string `json:"node"`
+	// UnassignedReason is why the shard became unassigned, e.g. NEW_INDEX_RESTORED, empty while it is assigned
+	UnassignedReason  string `json:"unassigned.reason"`
+	UnassignedDetails string `json:"unassigned.details"`
+}
+
+// Primary reports whether the shard copy is the primary
+func (s ShardInfo) Primary() bool {
+	return s.Prirep == "p"
+}
+
+// AllocationExplanation is the response of the cluster allocation explain API: why a shard is unassigned, or why it
+// stays on its node
+type AllocationExplanation struct {
+	Index                  string          `json:"index"`
+	Shard                  int             `json:"shard"`
+	Primary                bool            `json:"primary"`
+	CurrentState           string          `json:"current_state"`
+	CurrentNode            *AllocationNode `json:"current_node,omitempty"`
+	UnassignedInfo         *UnassignedInfo `json:"unassigned_info,omitempty"`
+	CanAllocate            string          `json:"can_allocate,omitempty"`
+	AllocateExplanation    string          `json:"allocate_explanation,omitempty"`
+	CanRemainOnCurrentNode string          `json:"can_remain_on_current_node,omitempty"`
+	// NodeDecisions are the decisions per node, with the deciders that prevent allocating the shard there
+	NodeDecisions []NodeAllocationDecision `json:"node_allocation_decisions,omitempty"`
+}
+
+// UnassignedInfo describes when and why a shard became unassigned
+type UnassignedInfo struct {
+	Reason               string `json:"reason"`
+	At                   string `json:"at"`
+	LastAllocationStatus string `json:"last_allocation_status"`
+	Details              string `json:"details"`
+}
+
+// AllocationNode is the node a shard is allocated to
+type AllocationNode struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NodeAllocationDecision is the decision whether a shard can be allocated to a node
+type NodeAllocationDecision struct {
+	NodeID   string              `json:"node_id"`
+	NodeName string              `json:"node_name"`
+	Decision string              `json:"node_decision"`
+	Deciders []AllocationDecider `json:"deciders,omitempty"`
+}
+
+// AllocationDecider is the outcome of one allocation decider, e.g. disk_threshold or awareness, for a node
+type AllocationDecider struct {
+	Decider     string `json:"decider"`
+	Decision    string `json:"decision"`
+	Explanation string `json:"explanation"`
+}
+
+// Task represents a task running in the cluster, as listed by the tasks API
+type Task struct {
+	// ID identifies the task as <node>:<id>
+	ID               string `json:"-"`
+	Node             string `json:"node"`
+	TaskNumber       int64  `json:"id"`
+	Action           string `json:"action"`
+	Description      string `json:"description"`
+	StartTimeMillis  int64  `json:"start_time_in_millis"`
+	RunningTimeNanos int64  `json:"running_time_in_nanos"`
+	Cancellable      bool   `json:"cancellable"`
+	Cancelled        bool   `json:"cancelled"`
+	ParentTaskID     string `json:"parent_task_id"`
+}
+
+// ReindexStatus is the progress of a reindex task
+type ReindexStatus struct {
+	Completed bool
+	Total     int64
+	Created   int64
+	Updated   int64
+	// Failures holds the reasons of the documents that could not be copied, or of the task failing
+	Failures []string
+}
+
+// Alias represents an index alias, as listed by the cat aliases API
+type Alias struct {
+	Alias string `json:"alias"`
+	Index string `json:"index"`
+	// IsWriteIndex is "true" or "false" when set explicitly, else "-"
+	IsWriteIndex string `json:"is_write_index"`
+}
+
+// Alias action types of UpdateAliases
+const (
+	AliasActionAdd    = "add"
+	AliasActionRemove = "remove"
+)
+
+// AliasAction adds an alias to or removes an alias from an index
+type AliasAction struct {
+	// Type is AliasActionAdd or AliasActionRemove
+	Type  string
+	Index string
+	Alias string
+}
+
 // Snapshot represents an Elasticsearch snapshot
 type Snapshot struct {
-	Snapshot         string   `json:"snapshot"`
-	UUID             string   `json:"uuid"`
-	Repository       string   `json:"repository"`
-	State            string   `json:"state"`
-	StartTime        string   `json:"start_time"`
-	StartTimeMillis  int64    `json:"start_time_in_millis"`
-	EndTime          string   `json:"end_time"`
-	EndTimeMillis    int64    `json:"end_time_in_millis"`
-	DurationInMillis int64    `json:"duration_in_millis"`
-	Indices          []string `json:"indices"`
-	Failures         []string `json:"failures"`
-	Shards           struct {
+	Snapshot           string                 `json:"snapshot"`
+	UUID               string                 `json:"uuid"`
+	Repository         string                 `json:"repository"`
+	State              string                 `json:"state"`
+	StartTime          string                 `json:"start_time"`
+	StartTimeMillis    int64                  `json:"start_time_in_millis"`
+	EndTime            string                 `json:"end_time"`
+	EndTimeMillis      int64                  `json:"end_time_in_millis"`
+	DurationInMillis   int64                  `json:"duration_in_millis"`
+	Indices            []string               `json:"indices"`
+	DataStreams        []string               `json:"data_streams"`
+	IncludeGlobalState bool                   `json:"include_global_state"`
+	FeatureStates      []SnapshotFeatureState `json:"feature_states"`
+	Version            string                 `json:"version"`
+	Failures           []SnapshotShardFailure `json:"failures"`
+	// Metadata is attached when the snapshot is taken, e.g. the policy of SLM snapshots or the reason of a manual one
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Shards   struct {
 		Total      int `json:"total"`
 		Failed     int `json:"failed"`
 		Successful int `json:"successful"`
 	} `json:"shards"`
 }
 
+// SnapshotFeatureState is a feature state included in a snapshot, with the system indices holding it
+type SnapshotFeatureState struct {
+	FeatureName string   `json:"feature_name"`
+	Indices     []string `json:"indices"`
+}
+
+// SnapshotShardFailure describes a shard that failed to be snapshotted
+type SnapshotShardFailure struct {
+	Index   string `json:"index"`
+	ShardID int    `json:"shard_id"`
+	NodeID  string `json:"node_id"`
+	Reason  string `json:"reason"`
+	Status  string `json:"status"`
+}
+
+// ShardFailure returns the failure as a ShardFailure with source ShardFailureSnapshot
+func (f SnapshotShardFailure) ShardFailure() ShardFailure {
+	return ShardFailure{Index: f.Index, Shard: f.ShardID, Node: f.NodeID, Reason: f.Reason, Source: ShardFailureSnapshot}
+}
+
+// FailedIndices returns the sorted names of the indices with at least one failed shard. The data of these
+// indices is incomplete in the snapshot, so they can only be restored with a partial restore.
+func (s *Snapshot) FailedIndices() []string {
+	seen := make(map[string]bool, len(s.Failures))
+	indices := make([]string, 0, len(s.Failures))
+	for _, failure := range s.Failures {
+		if !seen[failure.Index] {
+			seen[failure.Index] = true
+			indices = append(indices, failure.Index)
+		}
+	}
+	sort.Strings(indices)
+	return indices
+}
+
+// LatestSuccessful returns the successful snapshot that started last, or nil when none of the snapshots succeeded
+func LatestSuccessful(snapshots []Snapshot) *Snapshot {
+	var latest *Snapshot
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		if snapshot.State != "SUCCESS" {
+			continue
+		}
+		if latest == nil || snapshot.StartTimeMillis > latest.StartTimeMillis {
+			latest = snapshot
+		}
+	}
+	return latest
+}
+
 // SnapshotsResponse represents the response from Elasticsearch snapshots API
 type SnapshotsResponse struct {
 	Snapshots []Snapshot `json:"snapshots"`
@@ -59,11 +246,213 @@ type SnapshotsResponse struct {
 	Remaining int        `json:"remaining"`
 }
 
+// SnapshotStats holds the file statistics of a snapshot
+type SnapshotStats struct {
+	Snapshot               string `json:"snapshot"`
+	IncrementalFileCount   int    `json:"incrementalFileCount"`
+	IncrementalSizeInBytes int64  `json:"incrementalSizeInBytes"`
+	TotalFileCount         int    `json:"totalFileCount"`
+	TotalSizeInBytes       int64  `json:"totalSizeInBytes"`
+}
+
+// ClusterSettings holds the persistent and transient cluster settings, keyed by flat setting name
+// (e.g. cluster.routing.rebalance.enable)
+type ClusterSettings struct {
+	Persistent map[string]interface{} `json:"persistent"`
+	Transient  map[string]interface{} `json:"transient"`
+}
+
+// Get returns the explicitly set value of a setting; transient settings take precedence over persistent ones
+func (s *ClusterSettings) Get(key string) (interface{}, bool) {
+	if value, ok := s.Transient[key]; ok {
+		return value, true
+	}
+	value, ok := s.Persistent[key]
+	return value, ok
+}
+
+// ClusterHealth represents the response of the cluster health API
+type ClusterHealth struct {
+	ClusterName         string  `json:"cluster_name"`
+	Status              string  `json:"status"`
+	NumberOfNodes       int     `json:"number_of_nodes"`
+	ActiveShards        int     `json:"active_shards"`
+	UnassignedShards    int     `json:"unassigned_shards"`
+	ActiveShardsPercent float64 `json:"active_shards_percent_as_number"`
+}
+
+// Plugin is a plugin installed on an Elasticsearch node
+type Plugin struct {
+	Node      string `json:"name"`
+	Component string `json:"component"`
+	Version   string `json:"version"`
+}
+
+// RepositoryTypeError is returned when a snapshot repository cannot be created because its type
+// is not available, i.e. the repository plugin (e.g. repository-s3) is not installed
+type RepositoryTypeError struct {
+	Type   string
+	Reason string
+}
+
+// Error implements the error interface
+func (e *RepositoryTypeError) Error() string {
+	return fmt.Sprintf("snapshot repository type %s is not available: %s", e.Type, e.Reason)
+}
+
+// SLMPolicy represents the state of a Snapshot Lifecycle Management policy
+type SLMPolicy struct {
+	Name                string               `json:"-"`
+	Version             int                  `json:"version"`
+	Policy              *SLMPolicyDefinition `json:"policy"`
+	LastSuccess         *SLMExecution        `json:"last_success"`
+	LastFailure         *SLMExecution        `json:"last_failure"`
+	NextExecutionMillis int64                `json:"next_execution_millis"`
+}
+
+// SLMPolicyDefinition is the definition of an SLM policy, as put and returned by Elasticsearch
+type SLMPolicyDefinition struct {
+	Name       string                 `json:"name"`
+	Schedule   string                 `json:"schedule"`
+	Repository string                 `json:"repository"`
+	Config     map[string]interface{} `json:"config"`
+	Retention  map[string]interface{} `json:"retention"`
+}
+
+// Repository is the definition of a snapshot repository
+type Repository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// SLMExecution describes a single run of an SLM policy
+type SLMExecution struct {
+	SnapshotName string `json:"snapshot_name"`
+	Time         int64  `json:"time"`
+	Details      string `json:"details"`
+}
+
+// SLMStats holds the cluster-wide retention statistics of Snapshot Lifecycle Management
+type SLMStats struct {
+	RetentionRuns             int64 `json:"retention_runs"`
+	RetentionFailed           int64 `json:"retention_failed"`
+	RetentionTimedOut         int64 `json:"retention_timed_out"`
+	TotalSnapshotsDeleted     int64 `json:"total_snapshots_deleted"`
+	TotalSnapshotDeleteFailed int64 `json:"total_snapshot_deletion_failures"`
+}
+
+// RetentionRunsFinished returns the number of retention runs that ended, successfully or not
+func (s *SLMStats) RetentionRunsFinished() int64 {
+	return s.RetentionRuns + s.RetentionFailed + s.RetentionTimedOut
+}
+
+// Option configures optional Client behavior
+type Option func(*elasticsearch.Config)
+
+// WithTrace dumps every HTTP request and response to tracef (used for trace-level logging)
+func WithTrace(tracef func(format string, args ...interface{})) Option {
+	return func(cfg *elasticsearch.Config) {
+		cfg.Transport = &traceTransport{next: transportOrDefault(cfg.Transport), tracef: tracef}
+	}
+}
+
+// S3Connection holds how Elasticsearch connects to the S3 endpoint of a snapshot repository
+type S3Connection struct {
+	// Protocol is http or https
+	Protocol string
+	// Region is the region of the bucket, MinIO accepts any region
+	Region string
+	// PathStyleAccess addresses the bucket in the path instead of the host name (MinIO, Ceph RGW)
+	PathStyleAccess bool
+}
+
+// RepositoryTuning holds optional throughput and storage settings of a snapshot repository.
+// Settings left empty keep the Elasticsearch defaults.
+type RepositoryTuning struct {
+	// ChunkSize splits large files into chunks of this size, e.g. 1gb
+	ChunkSize string
+	// Compress compresses the index metadata files
+	Compress *bool
+	// MaxSnapshotBytesPerSec limits the snapshot rate per node, e.g. 200mb
+	MaxSnapshotBytesPerSec string
+	// MaxRestoreBytesPerSec limits the restore rate per node, e.g. 500mb
+	MaxRestoreBytesPerSec string
+}
+
+// RestoreOptions holds the options of a snapshot restore
+type RestoreOptions struct {
+	// Indices is the pattern of the indices to restore
+	Indices string
+	// FeatureStates lists the feature states to restore (e.g. kibana, security); "none" restores none
+	FeatureStates []string
+	// ExcludeIndices lists index names or patterns left out of the restore
+	ExcludeIndices []string
+	// IgnoreUnavailable skips indices of the pattern that are missing in the snapshot instead of failing
+	IgnoreUnavailable bool
+	// Partial restores the available shards of indices with failed shards in the snapshot, instead of failing
+	Partial bool
+	// WaitForCompletion waits until the restore finished and reports failed shards as PartialRestoreError
+	WaitForCompletion bool
+	// RenamePattern and RenameReplacement restore the indices under other names, e.g. (.+) and copy-$1
+	RenamePattern     string
+	RenameReplacement string
+	// ExcludeAliases leaves the aliases of the indices out of the restore
+	ExcludeAliases bool
+	// IndexSettings overrides settings of the restored indices, e.g. index.number_of_replicas
+	IndexSettings map[string]interface{}
+}
+
+// IndexPattern returns the index pattern to restore, with the excluded indices as negated patterns (-name)
+func (o RestoreOptions) IndexPattern() string {
+	patterns := []string{o.Indices}
+	for _, exclude := range o.ExcludeIndices {
+		patterns = append(patterns, "-"+strings.TrimPrefix(exclude, "-"))
+	}
+	return strings.Join(patterns, ",")
+}
+
+// RolloverOptions holds the options of a data stream rollover
+type RolloverOptions struct {
+	// MaxAge and MaxSize are conditions, e.g. 7d and 50gb: the rollover only happens when one of the given
+	// conditions is met. Without conditions the rollover always happens.
+	MaxAge  string
+	MaxSize string
+	// DryRun checks the conditions without rolling over
+	DryRun bool
+}
+
+// RolloverResult is the outcome of a rollover
+type RolloverResult struct {
+	OldIndex   string `json:"old_index"`
+	NewIndex   string `json:"new_index"`
+	RolledOver bool   `json:"rolled_over"`
+	DryRun     bool   `json:"dry_run"`
+	// Conditions reports for every condition, e.g. [max_age: 7d], whether it was met
+	Conditions map[string]bool `json:"conditions"`
+}
+
+// PartialRestoreError is returned when a restore finished but some shards failed to restore
+type PartialRestoreError struct {
+	Snapshot string
+	Total    int
+	Failed   int
+	// Failures are the primary shards that could not be restored, as far as they could be looked up
+	Failures []ShardFailure
+}
+
+// Error implements the error interface
+func (e *PartialRestoreError) Error() string {
+	return fmt.Sprintf("restore of snapshot %s finished with %d of %d shard(s) failed", e.Snapshot, e.Failed, e.Total)
+}
+
 // NewClient creates a new Elasticsearch client
-func NewClient(baseURL string) (*Client, error) {
+func NewClient(baseURL string, opts ...Option) (*Client, error) {
 	cfg := elasticsearch.Config{
 		Addresses: []string{baseURL},
 	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
 	es, err := elasticsearch.NewClient(cfg)
 	if err != nil {
@@ -77,10 +466,53 @@ func NewClient(baseURL string) (*Client, error) {
 
 // ListSnapshots retrieves all snapshots from a repository
 func (c *Client) ListSnapshots(repository string) ([]Snapshot, error) {
+	return c.ListSnapshotsMatching(repository, nil)
+}
+
+// ListSnapshotsMatching retrieves the snapshots of a repository for which keep returns true, or all snapshots
+// when keep is nil. The response is decoded one snapshot at a time and filtered while it is read, so memory stays
+// flat on repositories with hundreds of snapshots.
+func (c *Client) ListSnapshotsMatching(repository string, keep func(Snapshot) bool) ([]Snapshot, error) {
+	res, err := c.es.Snapshot.Get(
+		repository,
+		[]string{"_all"},
+		c.es.Snapshot.Get.WithContext(context.Background()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshots: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	snapshots := []Snapshot{}
+	err = decodeArrayField(res.Body, "snapshots", func(dec *json.Decoder) error {
+		var snapshot Snapshot
+		if err := dec.Decode(&snapshot); err != nil {
+			return err
+		}
+		if keep == nil || keep(snapshot) {
+			snapshots = append(snapshots, snapshot)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// ListSnapshotNames retrieves only the snapshot names of a repository.
+// It skips the per-snapshot details, which makes it much faster than ListSnapshots on large repositories.
+func (c *Client) ListSnapshotNames(repository string) ([]string, error) {
 	res, err := c.es.Snapshot.Get(
 		repository,
 		[]string{"_all"},
 		c.es.Snapshot.Get.WithContext(context.Background()),
+		c.es.Snapshot.Get.WithVerbose(false),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get snapshots: %w", err)
@@ -88,7 +520,7 @@ func (c *Client) ListSnapshots(repository string) ([]Snapshot, error) {
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
 	var snapshotsResp SnapshotsResponse
@@ -96,10 +528,15 @@ func (c *Client) ListSnapshots(repository string) ([]Snapshot, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return snapshotsResp.Snapshots, nil
+	names := make([]string, 0, len(snapshotsResp.Snapshots))
+	for _, snapshot := range snapshotsResp.Snapshots {
+		names = append(names, snapshot.Snapshot)
+	}
+	return names, nil
 }
 
-// GetSnapshot retrieves details of a specific snapshot including its indices
+// GetSnapshot retrieves details of a specific snapshot including its indices, or ErrSnapshotNotFound when it does
+// not exist
 func (c *Client) GetSnapshot(repository, snapshotName string) (*Snapshot, error) {
 	res, err := c.es.Snapshot.Get(
 		repository,
@@ -112,7 +549,7 @@ func (c *Client) GetSnapshot(repository, snapshotName string) (*Snapshot, error)
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
 	var snapshotsResp SnapshotsResponse
@@ -121,12 +558,138 @@ func (c *Client) GetSnapshot(repository, snapshotName string) (*Snapshot, error)
 	}
 
 	if len(snapshotsResp.Snapshots) == 0 {
-		return nil, fmt.Errorf("snapshot %s not found", snapshotName)
+		return nil, withKind(ErrSnapshotNotFound, fmt.Errorf("snapshot %s not found", snapshotName))
 	}
 
 	return &snapshotsResp.Snapshots[0], nil
 }
 
+// CreateSnapshot takes a snapshot of the given indices, without the global cluster state, with the given metadata
+// attached, and waits until it has finished. A snapshot that finished with failed shards is returned with state
+// PARTIAL.
+func (c *Client) CreateSnapshot(repository, snapshotName string, indices []string, metadata map[string]string) (*Snapshot, error) {
+	body := map[string]interface{}{
+		"indices":              strings.Join(indices, ","),
+		"ignore_unavailable":   false,
+		"include_global_state": false,
+	}
+	if len(metadata) > 0 {
+		body["metadata"] = metadata
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Snapshot.Create(
+		repository,
+		snapshotName,
+		c.es.Snapshot.Create.WithContext(context.Background()),
+		c.es.Snapshot.Create.WithBody(strings.NewReader(string(bodyJSON))),
+		c.es.Snapshot.Create.WithWaitForCompletion(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var createResp struct {
+		Snapshot Snapshot `json:"snapshot"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&createResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &createResp.Snapshot, nil
+}
+
+// DeleteSnapshot deletes a snapshot from a repository
+func (c *Client) DeleteSnapshot(repository, snapshotName string) error {
+	res, err := c.es.Snapshot.Delete(
+		repository,
+		[]string{snapshotName},
+		c.es.Snapshot.Delete.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// SnapshotSize returns the total size in bytes of a snapshot's files (via the snapshot status API).
+// The status API reads shard-level metadata from the repository, so it is considerably slower than GetSnapshot.
+func (c *Client) SnapshotSize(repository, snapshotName string) (int64, error) {
+	stats, err := c.SnapshotStats(repository, []string{snapshotName})
+	if err != nil {
+		return 0, err
+	}
+	if len(stats) == 0 {
+		return 0, withKind(ErrSnapshotNotFound, fmt.Errorf("snapshot %s not found", snapshotName))
+	}
+	return stats[0].TotalSizeInBytes, nil
+}
+
+// SnapshotStats returns the file statistics of snapshots (via the snapshot status API, see SnapshotSize).
+// The incremental size is what a snapshot added to the repository when it was taken, the total size is
+// everything it references.
+func (c *Client) SnapshotStats(repository string, snapshotNames []string) ([]SnapshotStats, error) {
+	res, err := c.es.Snapshot.Status(
+		c.es.Snapshot.Status.WithContext(context.Background()),
+		c.es.Snapshot.Status.WithRepository(repository),
+		c.es.Snapshot.Status.WithSnapshot(snapshotNames...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot status: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	type fileStats struct {
+		FileCount   int   `json:"file_count"`
+		SizeInBytes int64 `json:"size_in_bytes"`
+	}
+	// The status of every snapshot lists all of its shards, so the snapshots are decoded one at a time
+	stats := make([]SnapshotStats, 0, len(snapshotNames))
+	err = decodeArrayField(res.Body, "snapshots", func(dec *json.Decoder) error {
+		var snapshot struct {
+			Snapshot string `json:"snapshot"`
+			Stats    struct {
+				Incremental fileStats `json:"incremental"`
+				Total       fileStats `json:"total"`
+			} `json:"stats"`
+		}
+		if err := dec.Decode(&snapshot); err != nil {
+			return err
+		}
+		stats = append(stats, SnapshotStats{
+			Snapshot:               snapshot.Snapshot,
+			IncrementalFileCount:   snapshot.Stats.Incremental.FileCount,
+			IncrementalSizeInBytes: snapshot.Stats.Incremental.SizeInBytes,
+			TotalFileCount:         snapshot.Stats.Total.FileCount,
+			TotalSizeInBytes:       snapshot.Stats.Total.SizeInBytes,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return stats, nil
+}
+
 // ListIndices retrieves all indices matching a pattern
 func (c *Client) ListIndices(pattern string) ([]string, error) {
 	res, err := c.es.Cat.Indices(
@@ -141,7 +704,7 @@ func (c *Client) ListIndices(pattern string) ([]string, error) {
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
 	var indices []struct {
@@ -172,7 +735,7 @@ func (c *Client) ListIndicesDetailed() ([]IndexInfo, error) {
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
 	var indices []IndexInfo
@@ -183,168 +746,1214 @@ func (c *Client) ListIndicesDetailed() ([]IndexInfo, error) {
 	return indices, nil
 }
 
-// DeleteIndex deletes a specific index
-func (c *Client) DeleteIndex(index string) error {
-	res, err := c.es.Indices.Delete(
-		[]string{index},
-		c.es.Indices.Delete.WithContext(context.Background()),
+// ListRecoveries retrieves the shard recoveries of all indices. With activeOnly only the recoveries that are still
+// running are returned.
+func (c *Client) ListRecoveries(activeOnly bool) ([]RecoveryInfo, error) {
+	res, err := c.es.Cat.Recovery(
+		c.es.Cat.Recovery.WithContext(context.Background()),
+		c.es.Cat.Recovery.WithH("index,shard,time,type,stage,source_node,target_node,repository,snapshot,files_percent,bytes_percent,bytes_total"),
+		c.es.Cat.Recovery.WithActiveOnly(activeOnly),
+		c.es.Cat.Recovery.WithFormat("json"),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to delete index: %w", err)
+		return nil, fmt.Errorf("failed to list recoveries: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
-	return nil
+	var recoveries []RecoveryInfo
+	if err := json.NewDecoder(res.Body).Decode(&recoveries); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return recoveries, nil
 }
 
-// IndexExists checks if an index exists
-func (c *Client) IndexExists(index string) (bool, error) {
-	res, err := c.es.Indices.Exists(
-		[]string{index},
-		c.es.Indices.Exists.WithContext(context.Background()),
-	)
+// ListShards retrieves the shard copies of the indices matching pattern; of all indices when pattern is empty
+func (c *Client) ListShards(pattern string) ([]ShardInfo, error) {
+	opts := []func(*esapi.CatShardsRequest){
+		c.es.Cat.Shards.WithContext(context.Background()),
+		c.es.Cat.Shards.WithH("index", "shard", "prirep", "state", "node", "unassigned.reason", "unassigned.details"),
+		c.es.Cat.Shards.WithFormat("json"),
+	}
+	if pattern != "" {
+		opts = append(opts, c.es.Cat.Shards.WithIndex(pattern))
+	}
+
+	res, err := c.es.Cat.Shards(opts...)
 	if err != nil {
-		return false, fmt.Errorf("failed to check index existence: %w", err)
+		return nil, fmt.Errorf("failed to list shards: %w", err)
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusNotFound {
-		return false, nil
+	if res.IsError() {
+		return nil, responseError(res)
 	}
 
-	if res.IsError() {
-		return false, fmt.Errorf("elasticsearch returned error: %s", res.String())
+	var shards []ShardInfo
+	if err := json.NewDecoder(res.Body).Decode(&shards); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return true, nil
+	return shards, nil
 }
 
-// RolloverDatastream performs a rollover on a datastream
-func (c *Client) RolloverDatastream(datastreamName string) error {
-	res, err := c.es.Indices.Rollover(
-		datastreamName,
-		c.es.Indices.Rollover.WithContext(context.Background()),
+// ExplainAllocation asks the cluster why a shard copy is unassigned, or why it is allocated where it is
+func (c *Client) ExplainAllocation(index string, shard int, primary bool) (*AllocationExplanation, error) {
+	bodyJSON, err := json.Marshal(map[string]interface{}{"index": index, "shard": shard, "primary": primary})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Cluster.AllocationExplain(
+		c.es.Cluster.AllocationExplain.WithContext(context.Background()),
+		c.es.Cluster.AllocationExplain.WithBody(strings.NewReader(string(bodyJSON))),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to rollover datastream: %w", err)
+		return nil, fmt.Errorf("failed to explain allocation: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
-	return nil
+	var explanation AllocationExplanation
+	if err := json.NewDecoder(res.Body).Decode(&explanation); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &explanation, nil
 }
 
-// ConfigureSnapshotRepository configures an S3 snapshot repository
-func (c *Client) ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string) error {
-	body := map[string]interface{}{
-		"type": "s3",
-		"settings": map[string]interface{}{
-			"bucket":            bucket,
-			"region":            "minio",
-			"endpoint":          endpoint,
-			"base_path":         basePath,
-			"protocol":          "http",
-			"access_key":        accessKey,
-			"secret_key":        secretKey,
-			"path_style_access": "true",
-		},
+// ListAliases retrieves the aliases matching pattern with the indices they point to; all aliases when pattern is empty
+func (c *Client) ListAliases(pattern string) ([]Alias, error) {
+	opts := []func(*esapi.CatAliasesRequest){
+		c.es.Cat.Aliases.WithContext(context.Background()),
+		c.es.Cat.Aliases.WithH("alias,index,is_write_index"),
+		c.es.Cat.Aliases.WithFormat("json"),
 	}
-
-	bodyJSON, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+	if pattern != "" {
+		opts = append(opts, c.es.Cat.Aliases.WithName(pattern))
 	}
 
-	res, err := c.es.Snapshot.CreateRepository(
-		name,
-		strings.NewReader(string(bodyJSON)),
-		c.es.Snapshot.CreateRepository.WithContext(context.Background()),
-	)
+	res, err := c.es.Cat.Aliases(opts...)
 	if err != nil {
-		return fmt.Errorf("failed to create snapshot repository: %w", err)
+		return nil, fmt.Errorf("failed to list aliases: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return nil, responseError(res)
 	}
 
-	return nil
+	var aliases []Alias
+	if err := json.NewDecoder(res.Body).Decode(&aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return aliases, nil
 }
 
-// ConfigureSLMPolicy configures a Snapshot Lifecycle Management policy
-func (c *Client) ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int) error {
-	body := map[string]interface{}{
-		"schedule":   schedule,
-		"name":       snapshotName,
-		"repository": repository,
-		"config": map[string]interface{}{
-			"indices":              indices,
-			"ignore_unavailable":   false,
-			"include_global_state": false,
-		},
-		"retention": map[string]interface{}{
-			"expire_after": expireAfter,
-			"min_count":    minCount,
-			"max_count":    maxCount,
-		},
+// UpdateAliases applies the alias actions atomically: either all of them take effect or none
+func (c *Client) UpdateAliases(actions []AliasAction) error {
+	body := make([]map[string]map[string]string, 0, len(actions))
+	for _, action := range actions {
+		body = append(body, map[string]map[string]string{
+			action.Type: {"index": action.Index, "alias": action.Alias},
+		})
 	}
-
-	bodyJSON, err := json.Marshal(body)
+	bodyJSON, err := json.Marshal(map[string]interface{}{"actions": body})
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	res, err := c.es.SlmPutLifecycle(
-		name,
-		c.es.SlmPutLifecycle.WithContext(context.Background()),
-		c.es.SlmPutLifecycle.WithBody(strings.NewReader(string(bodyJSON))),
+	res, err := c.es.Indices.UpdateAliases(
+		strings.NewReader(string(bodyJSON)),
+		c.es.Indices.UpdateAliases.WithContext(context.Background()),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to create SLM policy: %w", err)
+		return fmt.Errorf("failed to update aliases: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return responseError(res)
 	}
 
 	return nil
 }
 
-// RestoreSnapshot restores a snapshot from a repository
-func (c *Client) RestoreSnapshot(repository, snapshotName, indicesPattern string, waitForCompletion bool) error {
-	body := map[string]interface{}{
-		"indices": indicesPattern,
-	}
-
-	bodyJSON, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
-	}
-
-	res, err := c.es.Snapshot.Restore(
-		repository,
-		snapshotName,
-		c.es.Snapshot.Restore.WithContext(context.Background()),
-		c.es.Snapshot.Restore.WithBody(strings.NewReader(string(bodyJSON))),
-		c.es.Snapshot.Restore.WithWaitForCompletion(waitForCompletion),
+// DeleteIndex deletes a specific index
+func (c *Client) DeleteIndex(index string) error {
+	res, err := c.es.Indices.Delete(
+		[]string{index},
+		c.es.Indices.Delete.WithContext(context.Background()),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to restore snapshot: %w", err)
+		return fmt.Errorf("failed to delete index: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned error: %s", res.String())
+		return responseError(res)
 	}
 
 	return nil
 }
+
+// RefreshIndices refreshes the indices matching pattern, making all their documents searchable
+func (c *Client) RefreshIndices(pattern string) error {
+	res, err := c.es.Indices.Refresh(
+		c.es.Indices.Refresh.WithContext(context.Background()),
+		c.es.Indices.Refresh.WithIndex(pattern),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to refresh indices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// ForceMergeIndices merges the segments of the indices matching pattern down to maxNumSegments per shard.
+// It blocks until the merge has finished, which can take a long time for large indices.
+func (c *Client) ForceMergeIndices(pattern string, maxNumSegments int) error {
+	res, err := c.es.Indices.Forcemerge(
+		c.es.Indices.Forcemerge.WithContext(context.Background()),
+		c.es.Indices.Forcemerge.WithIndex(pattern),
+		c.es.Indices.Forcemerge.WithMaxNumSegments(maxNumSegments),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to force merge indices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// IndexExists checks if an index exists
+func (c *Client) IndexExists(index string) (bool, error) {
+	res, err := c.es.Indices.Exists(
+		[]string{index},
+		c.es.Indices.Exists.WithContext(context.Background()),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to check index existence: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if res.IsError() {
+		return false, responseError(res)
+	}
+
+	return true, nil
+}
+
+// CountDocuments returns the number of documents in an index
+func (c *Client) CountDocuments(index string) (int64, error) {
+	res, err := c.es.Count(
+		c.es.Count.WithContext(context.Background()),
+		c.es.Count.WithIndex(index),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, responseError(res)
+	}
+
+	var countResp struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&countResp); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return countResp.Count, nil
+}
+
+// SampleDocuments returns the source of up to size documents of an index, keyed by document ID. The documents are
+// taken in index order, which is cheap but not random.
+func (c *Client) SampleDocuments(index string, size int) (map[string]json.RawMessage, error) {
+	res, err := c.es.Search(
+		c.es.Search.WithContext(context.Background()),
+		c.es.Search.WithIndex(index),
+		c.es.Search.WithSize(size),
+		c.es.Search.WithSort("_doc"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var searchResp struct {
+		Hits struct {
+			Hits []struct {
+				ID     string          `json:"_id"`
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&searchResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	documents := make(map[string]json.RawMessage, len(searchResp.Hits.Hits))
+	for _, hit := range searchResp.Hits.Hits {
+		documents[hit.ID] = hit.Source
+	}
+	return documents, nil
+}
+
+// GetDocuments returns the source of the documents with the given IDs in an index, keyed by document ID.
+// Documents that do not exist are left out.
+func (c *Client) GetDocuments(index string, ids []string) (map[string]json.RawMessage, error) {
+	body, err := json.Marshal(map[string]interface{}{"ids": ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Mget(
+		strings.NewReader(string(body)),
+		c.es.Mget.WithContext(context.Background()),
+		c.es.Mget.WithIndex(index),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var mgetResp struct {
+		Docs []struct {
+			ID     string          `json:"_id"`
+			Found  bool            `json:"found"`
+			Source json.RawMessage `json:"_source"`
+		} `json:"docs"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&mgetResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	documents := make(map[string]json.RawMessage, len(mgetResp.Docs))
+	for _, doc := range mgetResp.Docs {
+		if doc.Found {
+			documents[doc.ID] = doc.Source
+		}
+	}
+	return documents, nil
+}
+
+// RolloverDatastream performs a rollover on a datastream
+func (c *Client) RolloverDatastream(datastreamName string) error {
+	_, err := c.RolloverDatastreamWithOptions(datastreamName, RolloverOptions{})
+	return err
+}
+
+// RolloverDatastreamWithOptions rolls a datastream over when one of the conditions of opts is met, or
+// unconditionally without conditions
+func (c *Client) RolloverDatastreamWithOptions(datastreamName string, opts RolloverOptions) (*RolloverResult, error) {
+	requestOpts := []func(*esapi.IndicesRolloverRequest){c.es.Indices.Rollover.WithContext(context.Background())}
+	if opts.DryRun {
+		requestOpts = append(requestOpts, c.es.Indices.Rollover.WithDryRun(true))
+	}
+
+	conditions := map[string]string{}
+	if opts.MaxAge != "" {
+		conditions["max_age"] = opts.MaxAge
+	}
+	if opts.MaxSize != "" {
+		conditions["max_size"] = opts.MaxSize
+	}
+	if len(conditions) > 0 {
+		bodyJSON, err := json.Marshal(map[string]interface{}{"conditions": conditions})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		requestOpts = append(requestOpts, c.es.Indices.Rollover.WithBody(strings.NewReader(string(bodyJSON))))
+	}
+
+	res, err := c.es.Indices.Rollover(datastreamName, requestOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rollover datastream: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var result RolloverResult
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ConfigureSnapshotRepository configures an S3 snapshot repository
+func (c *Client) ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string, conn S3Connection, tuning RepositoryTuning) error {
+	return c.putS3Repository(name, S3RepositorySettings(bucket, endpoint, basePath, accessKey, secretKey, conn, tuning))
+}
+
+// RegisterReadOnlyRepository registers an S3 snapshot repository that can only be restored from,
+// e.g. another environment's repository, so this cluster never writes to or cleans up its snapshots
+func (c *Client) RegisterReadOnlyRepository(name, bucket, endpoint, basePath, accessKey, secretKey string, conn S3Connection, tuning RepositoryTuning) error {
+	settings := S3RepositorySettings(bucket, endpoint, basePath, accessKey, secretKey, conn, tuning)
+	settings["readonly"] = "true"
+	return c.putS3Repository(name, settings)
+}
+
+// PutS3Repository creates or replaces an S3 snapshot repository with the given settings, e.g. the settings of the
+// existing repository with other credentials
+func (c *Client) PutS3Repository(name string, settings map[string]interface{}) error {
+	return c.putS3Repository(name, settings)
+}
+
+// S3RepositorySettings returns the settings of an S3 repository, including the tuning settings that are set
+func S3RepositorySettings(bucket, endpoint, basePath, accessKey, secretKey string, conn S3Connection, tuning RepositoryTuning) map[string]interface{} {
+	settings := map[string]interface{}{
+		"bucket":            bucket,
+		"region":            conn.Region,
+		"endpoint":          endpoint,
+		"base_path":         basePath,
+		"protocol":          conn.Protocol,
+		"access_key":        accessKey,
+		"secret_key":        secretKey,
+		"path_style_access": strconv.FormatBool(conn.PathStyleAccess),
+	}
+	tuning.apply(settings)
+	return settings
+}
+
+// apply adds the tuning settings that are set to the repository settings
+func (t RepositoryTuning) apply(settings map[string]interface{}) {
+	if t.ChunkSize != "" {
+		settings["chunk_size"] = t.ChunkSize
+	}
+	if t.Compress != nil {
+		settings["compress"] = *t.Compress
+	}
+	if t.MaxSnapshotBytesPerSec != "" {
+		settings["max_snapshot_bytes_per_sec"] = t.MaxSnapshotBytesPerSec
+	}
+	if t.MaxRestoreBytesPerSec != "" {
+		settings["max_restore_bytes_per_sec"] = t.MaxRestoreBytesPerSec
+	}
+}
+
+// putS3Repository creates or updates an S3 snapshot repository
+func (c *Client) putS3Repository(name string, settings map[string]interface{}) error {
+	body := map[string]interface{}{
+		"type":     "s3",
+		"settings": settings,
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Snapshot.CreateRepository(
+		name,
+		strings.NewReader(string(bodyJSON)),
+		c.es.Snapshot.CreateRepository.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot repository: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		errBody, _ := io.ReadAll(res.Body)
+		if typeErr := parseRepositoryTypeError("s3", errBody); typeErr != nil {
+			return typeErr
+		}
+		return fmt.Errorf("elasticsearch returned error: [%s] %s", res.Status(), errBody)
+	}
+
+	return nil
+}
+
+// parseRepositoryTypeError returns a RepositoryTypeError when an error response reports that
+// the repository type does not exist, and nil for any other error
+func parseRepositoryTypeError(repoType string, body []byte) *RepositoryTypeError {
+	var errResp struct {
+		Error struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return nil
+	}
+
+	reason := errResp.Error.Reason
+	// Elasticsearch 7 and 8 report "repository type [s3] does not exist"; older versions "Unknown [repository] type [s3]"
+	if strings.Contains(reason, fmt.Sprintf("repository type [%s] does not exist", repoType)) ||
+		strings.Contains(reason, fmt.Sprintf("Unknown [repository] type [%s]", repoType)) {
+		return &RepositoryTypeError{Type: repoType, Reason: reason}
+	}
+	return nil
+}
+
+// ConfigureSLMPolicy configures a Snapshot Lifecycle Management policy
+func (c *Client) ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int, featureStates []string) error {
+	body := NewSLMPolicyDefinition(schedule, snapshotName, repository, indices, expireAfter, minCount, maxCount, featureStates)
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.SlmPutLifecycle(
+		name,
+		c.es.SlmPutLifecycle.WithContext(context.Background()),
+		c.es.SlmPutLifecycle.WithBody(strings.NewReader(string(bodyJSON))),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create SLM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// NewSLMPolicyDefinition returns the definition of an SLM policy snapshotting indices without the global state
+func NewSLMPolicyDefinition(schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int, featureStates []string) SLMPolicyDefinition {
+	snapshotConfig := map[string]interface{}{
+		"indices":              indices,
+		"ignore_unavailable":   false,
+		"include_global_state": false,
+	}
+	// Without the global state no feature states are included, unless listed explicitly
+	if len(featureStates) > 0 {
+		snapshotConfig["feature_states"] = featureStates
+	}
+
+	return SLMPolicyDefinition{
+		Name:       snapshotName,
+		Schedule:   schedule,
+		Repository: repository,
+		Config:     snapshotConfig,
+		Retention: map[string]interface{}{
+			"expire_after": expireAfter,
+			"min_count":    minCount,
+			"max_count":    maxCount,
+		},
+	}
+}
+
+// GetRepository returns the definition of a snapshot repository, or ErrRepositoryMissing when it does not exist
+func (c *Client) GetRepository(name string) (*Repository, error) {
+	res, err := c.es.Snapshot.GetRepository(
+		c.es.Snapshot.GetRepository.WithContext(context.Background()),
+		c.es.Snapshot.GetRepository.WithRepository(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot repository: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, withKind(ErrRepositoryMissing, fmt.Errorf("snapshot repository %s not found", name))
+	}
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var repositories map[string]Repository
+	if err := json.NewDecoder(res.Body).Decode(&repositories); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	repository, ok := repositories[name]
+	if !ok {
+		return nil, withKind(ErrRepositoryMissing, fmt.Errorf("snapshot repository %s not found", name))
+	}
+	return &repository, nil
+}
+
+// ClusterHealth retrieves the cluster health
+func (c *Client) ClusterHealth() (*ClusterHealth, error) {
+	res, err := c.es.Cluster.Health(
+		c.es.Cluster.Health.WithContext(context.Background()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster health: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var health ClusterHealth
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &health, nil
+}
+
+// Version returns the Elasticsearch version number, e.g. 8.19.0
+func (c *Client) Version() (string, error) {
+	res, err := c.es.Info(
+		c.es.Info.WithContext(context.Background()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get cluster info: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", responseError(res)
+	}
+
+	var info struct {
+		Version struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return info.Version.Number, nil
+}
+
+// ListPlugins returns the plugins installed on every node, one entry per node and plugin
+func (c *Client) ListPlugins() ([]Plugin, error) {
+	res, err := c.es.Cat.Plugins(
+		c.es.Cat.Plugins.WithContext(context.Background()),
+		c.es.Cat.Plugins.WithFormat("json"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plugins: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var plugins []Plugin
+	if err := json.NewDecoder(res.Body).Decode(&plugins); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return plugins, nil
+}
+
+// GetClusterSettings retrieves the explicitly set persistent and transient cluster settings
+func (c *Client) GetClusterSettings() (*ClusterSettings, error) {
+	res, err := c.es.Cluster.GetSettings(
+		c.es.Cluster.GetSettings.WithContext(context.Background()),
+		c.es.Cluster.GetSettings.WithFlatSettings(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster settings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var settings ClusterSettings
+	if err := json.NewDecoder(res.Body).Decode(&settings); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// PutClusterSettings updates persistent cluster settings. A nil value resets a setting to its default.
+func (c *Client) PutClusterSettings(persistent map[string]interface{}) error {
+	bodyJSON, err := json.Marshal(map[string]interface{}{"persistent": persistent})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Cluster.PutSettings(
+		strings.NewReader(string(bodyJSON)),
+		c.es.Cluster.PutSettings.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update cluster settings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// GetIngestPipelines retrieves the definitions of all ingest pipelines, keyed by pipeline name
+func (c *Client) GetIngestPipelines() (map[string]json.RawMessage, error) {
+	res, err := c.es.Ingest.GetPipeline(
+		c.es.Ingest.GetPipeline.WithContext(context.Background()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingest pipelines: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Elasticsearch responds with 404 when no pipelines exist
+	if res.StatusCode == http.StatusNotFound {
+		return map[string]json.RawMessage{}, nil
+	}
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	pipelines := map[string]json.RawMessage{}
+	if err := json.NewDecoder(res.Body).Decode(&pipelines); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return pipelines, nil
+}
+
+// PutIngestPipeline creates or replaces an ingest pipeline with the given definition
+func (c *Client) PutIngestPipeline(name string, definition json.RawMessage) error {
+	res, err := c.es.Ingest.PutPipeline(
+		name,
+		strings.NewReader(string(definition)),
+		c.es.Ingest.PutPipeline.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to put ingest pipeline: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// GetILMPolicies retrieves all ILM policies, keyed by name. Only the policy definition is returned, without
+// the version, modification date and usage Elasticsearch reports with it.
+func (c *Client) GetILMPolicies() (map[string]json.RawMessage, error) {
+	res, err := c.es.ILM.GetLifecycle(
+		c.es.ILM.GetLifecycle.WithContext(context.Background()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ILM policies: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var policiesResp map[string]struct {
+		Policy json.RawMessage `json:"policy"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&policiesResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	policies := make(map[string]json.RawMessage, len(policiesResp))
+	for name, policy := range policiesResp {
+		policies[name] = policy.Policy
+	}
+	return policies, nil
+}
+
+// PutILMPolicy creates or replaces an ILM policy with the given definition
+func (c *Client) PutILMPolicy(name string, policy json.RawMessage) error {
+	bodyJSON, err := json.Marshal(map[string]json.RawMessage{"policy": policy})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.ILM.PutLifecycle(
+		name,
+		c.es.ILM.PutLifecycle.WithContext(context.Background()),
+		c.es.ILM.PutLifecycle.WithBody(strings.NewReader(string(bodyJSON))),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to put ILM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// IndexLifecyclePolicies returns the ILM policy of every index matching pattern that has one, keyed by index
+func (c *Client) IndexLifecyclePolicies(pattern string) (map[string]string, error) {
+	res, err := c.es.Indices.GetSettings(
+		c.es.Indices.GetSettings.WithContext(context.Background()),
+		c.es.Indices.GetSettings.WithIndex(pattern),
+		c.es.Indices.GetSettings.WithName("index.lifecycle.name"),
+		c.es.Indices.GetSettings.WithFlatSettings(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index settings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var settingsResp map[string]struct {
+		Settings map[string]string `json:"settings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&settingsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	policies := make(map[string]string)
+	for index, settings := range settingsResp {
+		if policy := settings.Settings["index.lifecycle.name"]; policy != "" {
+			policies[index] = policy
+		}
+	}
+	return policies, nil
+}
+
+// ListTasks retrieves the running tasks whose action matches one of actions, e.g. cluster:admin/snapshot/*
+func (c *Client) ListTasks(actions []string) ([]Task, error) {
+	res, err := c.es.Tasks.List(
+		c.es.Tasks.List.WithContext(context.Background()),
+		c.es.Tasks.List.WithActions(actions...),
+		c.es.Tasks.List.WithDetailed(true),
+		c.es.Tasks.List.WithGroupBy("none"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var tasksResp struct {
+		Tasks []Task `json:"tasks"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tasksResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for i := range tasksResp.Tasks {
+		task := &tasksResp.Tasks[i]
+		task.ID = fmt.Sprintf("%s:%d", task.Node, task.TaskNumber)
+	}
+	return tasksResp.Tasks, nil
+}
+
+// CancelTask cancels the task with the given <node>:<id> ID
+func (c *Client) CancelTask(taskID string) error {
+	res, err := c.es.Tasks.Cancel(
+		c.es.Tasks.Cancel.WithContext(context.Background()),
+		c.es.Tasks.Cancel.WithTaskID(taskID),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel task: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	// Failures to cancel on a node are reported in the body of a successful response
+	var cancelResp struct {
+		NodeFailures []struct {
+			CausedBy struct {
+				Reason string `json:"reason"`
+			} `json:"caused_by"`
+		} `json:"node_failures"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&cancelResp); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(cancelResp.NodeFailures) > 0 {
+		return fmt.Errorf("failed to cancel task %s: %s", taskID, cancelResp.NodeFailures[0].CausedBy.Reason)
+	}
+
+	return nil
+}
+
+// StartReindex starts copying all documents of source to dest in a background task and returns its <node>:<id>
+// task ID. requestsPerSecond throttles the copy; -1 disables throttling.
+func (c *Client) StartReindex(source, dest string, requestsPerSecond int) (string, error) {
+	body := map[string]interface{}{
+		"source": map[string]string{"index": source},
+		"dest":   map[string]string{"index": dest},
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Reindex(
+		strings.NewReader(string(bodyJSON)),
+		c.es.Reindex.WithContext(context.Background()),
+		c.es.Reindex.WithRequestsPerSecond(requestsPerSecond),
+		c.es.Reindex.WithWaitForCompletion(false),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to start reindex: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", responseError(res)
+	}
+
+	var reindexResp struct {
+		Task string `json:"task"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&reindexResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return reindexResp.Task, nil
+}
+
+// GetReindexStatus retrieves the progress of the reindex task with the given ID
+func (c *Client) GetReindexStatus(taskID string) (*ReindexStatus, error) {
+	res, err := c.es.Tasks.Get(
+		taskID,
+		c.es.Tasks.Get.WithContext(context.Background()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var taskResp struct {
+		Completed bool `json:"completed"`
+		Task      struct {
+			Status struct {
+				Total   int64 `json:"total"`
+				Created int64 `json:"created"`
+				Updated int64 `json:"updated"`
+			} `json:"status"`
+		} `json:"task"`
+		Response struct {
+			Failures []struct {
+				Cause struct {
+					Reason string `json:"reason"`
+				} `json:"cause"`
+			} `json:"failures"`
+		} `json:"response"`
+		Error *struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&taskResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	status := &ReindexStatus{
+		Completed: taskResp.Completed,
+		Total:     taskResp.Task.Status.Total,
+		Created:   taskResp.Task.Status.Created,
+		Updated:   taskResp.Task.Status.Updated,
+	}
+	for _, failure := range taskResp.Response.Failures {
+		status.Failures = append(status.Failures, failure.Cause.Reason)
+	}
+	if taskResp.Error != nil {
+		status.Failures = append(status.Failures, taskResp.Error.Reason)
+	}
+	return status, nil
+}
+
+// VerifyRepository verifies that all nodes can access a snapshot repository
+// and returns the number of nodes that verified it
+func (c *Client) VerifyRepository(name string) (int, error) {
+	res, err := c.es.Snapshot.VerifyRepository(
+		name,
+		c.es.Snapshot.VerifyRepository.WithContext(context.Background()),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify snapshot repository: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return 0, responseError(res)
+	}
+
+	var verifyResp struct {
+		Nodes map[string]struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&verifyResp); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return len(verifyResp.Nodes), nil
+}
+
+// GetSLMPolicy retrieves an SLM policy including its last success and failure
+func (c *Client) GetSLMPolicy(name string) (*SLMPolicy, error) {
+	res, err := c.es.SlmGetLifecycle(
+		c.es.SlmGetLifecycle.WithContext(context.Background()),
+		c.es.SlmGetLifecycle.WithPolicyID(name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("SLM policy %s %w", name, ErrNotFound)
+	}
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var policies map[string]SLMPolicy
+	if err := json.NewDecoder(res.Body).Decode(&policies); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	policy, ok := policies[name]
+	if !ok {
+		return nil, fmt.Errorf("SLM policy %s %w", name, ErrNotFound)
+	}
+	policy.Name = name
+	return &policy, nil
+}
+
+// ExecuteSLMPolicy immediately takes a snapshot according to an SLM policy and returns the snapshot name
+func (c *Client) ExecuteSLMPolicy(name string) (string, error) {
+	res, err := c.es.SlmExecuteLifecycle(
+		name,
+		c.es.SlmExecuteLifecycle.WithContext(context.Background()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute SLM policy: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", responseError(res)
+	}
+
+	var executeResp struct {
+		SnapshotName string `json:"snapshot_name"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&executeResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return executeResp.SnapshotName, nil
+}
+
+// ExecuteSLMRetention starts SLM retention for all policies immediately. Retention runs in the background;
+// use SLMStats to see when it has finished.
+func (c *Client) ExecuteSLMRetention() error {
+	res, err := c.es.SlmExecuteRetention(
+		c.es.SlmExecuteRetention.WithContext(context.Background()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to execute SLM retention: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	return nil
+}
+
+// SLMStats returns the retention statistics of Snapshot Lifecycle Management
+func (c *Client) SLMStats() (*SLMStats, error) {
+	res, err := c.es.SlmGetStats(
+		c.es.SlmGetStats.WithContext(context.Background()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SLM stats: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, responseError(res)
+	}
+
+	var stats SLMStats
+	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// SLMStatus returns the operation mode of Snapshot Lifecycle Management (RUNNING, STOPPING or STOPPED)
+func (c *Client) SLMStatus() (string, error) {
+	res, err := c.es.SlmGetStatus(
+		c.es.SlmGetStatus.WithContext(context.Background()),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to get SLM status: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", responseError(res)
+	}
+
+	var statusResp struct {
+		OperationMode string `json:"operation_mode"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&statusResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return statusResp.OperationMode, nil
+}
+
+// RestoreSnapshot restores a snapshot from a repository
+func (c *Client) RestoreSnapshot(repository, snapshotName, indicesPattern string, waitForCompletion bool) error {
+	return c.RestoreSnapshotWithOptions(repository, snapshotName, RestoreOptions{Indices: indicesPattern, WaitForCompletion: waitForCompletion})
+}
+
+// RestoreSnapshotWithOptions restores a snapshot with the given options
+func (c *Client) RestoreSnapshotWithOptions(repository, snapshotName string, opts RestoreOptions) error {
+	body := map[string]interface{}{
+		"indices": opts.IndexPattern(),
+	}
+	if opts.IgnoreUnavailable {
+		body["ignore_unavailable"] = true
+	}
+	if len(opts.FeatureStates) > 0 {
+		body["feature_states"] = opts.FeatureStates
+	}
+	if opts.Partial {
+		body["partial"] = true
+	}
+	if opts.RenamePattern != "" {
+		body["rename_pattern"] = opts.RenamePattern
+		body["rename_replacement"] = opts.RenameReplacement
+	}
+	if opts.ExcludeAliases {
+		body["include_aliases"] = false
+	}
+	if len(opts.IndexSettings) > 0 {
+		body["index_settings"] = opts.IndexSettings
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	res, err := c.es.Snapshot.Restore(
+		repository,
+		snapshotName,
+		c.es.Snapshot.Restore.WithContext(context.Background()),
+		c.es.Snapshot.Restore.WithBody(strings.NewReader(string(bodyJSON))),
+		c.es.Snapshot.Restore.WithWaitForCompletion(opts.WaitForCompletion),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", transportError(err))
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return responseError(res)
+	}
+
+	if !opts.WaitForCompletion {
+		return nil
+	}
+
+	// When waiting for completion, the response reports the shard outcome of the restore
+	var restoreResp struct {
+		Snapshot struct {
+			Indices []string `json:"indices"`
+			Shards  struct {
+				Total  int `json:"total"`
+				Failed int `json:"failed"`
+			} `json:"shards"`
+		} `json:"snapshot"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&restoreResp); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if shards := restoreResp.Snapshot.Shards; shards.Failed > 0 {
+		// The response only has the number of failed shards; the failures are looked up on the best effort
+		failures, _ := c.restoreShardFailures(restoreResp.Snapshot.Indices)
+		return &PartialRestoreError{Snapshot: snapshotName, Total: shards.Total, Failed: shards.Failed, Failures: failures}
+	}
+
+	return nil
+}
+
+// restoreShardFailures returns the unassigned primary shards of the restored indices, with the reason they
+// could not be allocated, which for a restore is the reason the shard could not be restored
+func (c *Client) restoreShardFailures(indices []string) ([]ShardFailure, error) {
+	if len(indices) == 0 {
+		return nil, nil
+	}
+	shards, err := c.ListShards(strings.Join(indices, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []ShardFailure
+	for _, shard := range shards {
+		if !shard.Primary() || shard.State != "UNASSIGNED" {
+			continue
+		}
+		reason := shard.UnassignedReason
+		if shard.UnassignedDetails != "" {
+			reason += ": " + shard.UnassignedDetails
+		}
+		number, _ := strconv.Atoi(shard.Shard)
+		failures = append(failures, ShardFailure{Index: shard.Index, Shard: number, Node: shard.Node, Reason: reason, Source: ShardFailureRestore})
+	}
+	return failures, nil
+}
@@ -1,8 +1,12 @@
 package elasticsearch
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -113,6 +117,25 @@ func TestClient_ListSnapshots(t *testing.T) {
 	}
 }
 
+func TestClient_ListSnapshotNames(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_snapshot/test-repo/_all", r.URL.Path)
+		assert.Equal(t, "false", r.URL.Query().Get("verbose"))
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshots": [{"snapshot": "snap-1"}, {"snapshot": "snap-2"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	names, err := client.ListSnapshotNames("test-repo")
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"snap-1", "snap-2"}, names)
+}
+
 func TestClient_GetSnapshot(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -185,6 +208,58 @@ func TestClient_GetSnapshot(t *testing.T) {
 	}
 }
 
+func TestClient_SnapshotSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedSize   int64
+		expectError    bool
+	}{
+		{
+			name:           "snapshot with stats",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"snapshots": [{"snapshot": "snap-1", "stats": {"total": {"file_count": 10, "size_in_bytes": 1048576}}}]}`,
+			expectedSize:   1048576,
+		},
+		{
+			name:           "snapshot not in response",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"snapshots": []}`,
+			expectError:    true,
+		},
+		{
+			name:           "elasticsearch returns error",
+			responseStatus: http.StatusNotFound,
+			responseBody:   `{"error": "snapshot missing"}`,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_snapshot/test-repo/snap-1/_status", r.URL.Path)
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			size, err := client.SnapshotSize("test-repo", "snap-1")
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSize, size)
+		})
+	}
+}
+
 func TestClient_ListIndices(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -422,3 +497,1409 @@ func TestNewClient(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, client)
 }
+
+func TestClient_ConfigureSLMPolicy_FeatureStates(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_slm/policy/daily", r.URL.Path)
+		assert.Equal(t, http.MethodPut, r.Method)
+
+		var body struct {
+			Config map[string]interface{} `json:"config"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, false, body.Config["include_global_state"])
+		assert.Equal(t, []interface{}{"kibana"}, body.Config["feature_states"])
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.ConfigureSLMPolicy("daily", "0 0 3 * * ?", "<snap-{now/d}>", "repo", "sts*", "30d", 5, 30, []string{"kibana"})
+	assert.NoError(t, err)
+}
+
+func TestClient_CreateSnapshot(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_snapshot/test-repo/pre-restore-1", r.URL.Path)
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "true", r.URL.Query().Get("wait_for_completion"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "sts_a,sts_b", body["indices"])
+		assert.Equal(t, false, body["include_global_state"])
+		assert.Equal(t, map[string]interface{}{"reason": "pre-upgrade"}, body["metadata"])
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshot": {"snapshot": "pre-restore-1", "state": "SUCCESS", "indices": ["sts_a", "sts_b"],
+			"metadata": {"reason": "pre-upgrade"}, "shards": {"total": 2, "failed": 0, "successful": 2}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	snapshot, err := client.CreateSnapshot("test-repo", "pre-restore-1", []string{"sts_a", "sts_b"}, map[string]string{"reason": "pre-upgrade"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "SUCCESS", snapshot.State)
+	assert.Equal(t, 2, snapshot.Shards.Successful)
+	assert.Equal(t, map[string]interface{}{"reason": "pre-upgrade"}, snapshot.Metadata)
+}
+
+func TestClient_RefreshIndices(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts*,-sts_logs*/_refresh", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"_shards": {"total": 2, "successful": 2, "failed": 0}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	assert.NoError(t, client.RefreshIndices("sts*,-sts_logs*"))
+}
+
+func TestClient_ForceMergeIndices(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts*/_forcemerge", r.URL.Path)
+		assert.Equal(t, "1", r.URL.Query().Get("max_num_segments"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"_shards": {"total": 2, "successful": 2, "failed": 0}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	assert.NoError(t, client.ForceMergeIndices("sts*", 1))
+}
+
+func TestClient_GetSnapshot_Failures(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshots": [{
+			"snapshot": "snap-1",
+			"state": "PARTIAL",
+			"indices": ["sts_a", "sts_b", "sts_c"],
+			"failures": [
+				{"index": "sts_c", "index_uuid": "u1", "shard_id": 1, "reason": "IndexShardSnapshotFailedException", "node_id": "n1", "status": "INTERNAL_SERVER_ERROR"},
+				{"index": "sts_a", "index_uuid": "u2", "shard_id": 0, "reason": "node left", "node_id": "n2", "status": "INTERNAL_SERVER_ERROR"},
+				{"index": "sts_c", "index_uuid": "u1", "shard_id": 0, "reason": "node left", "node_id": "n2", "status": "INTERNAL_SERVER_ERROR"}
+			],
+			"shards": {"total": 6, "failed": 3, "successful": 3}
+		}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	snapshot, err := client.GetSnapshot("test-repo", "snap-1")
+
+	require.NoError(t, err)
+	require.Len(t, snapshot.Failures, 3)
+	assert.Equal(t, "sts_c", snapshot.Failures[0].Index)
+	assert.Equal(t, 1, snapshot.Failures[0].ShardID)
+	assert.Equal(t, []string{"sts_a", "sts_c"}, snapshot.FailedIndices())
+}
+
+func TestClient_RestoreSnapshotWithOptions(t *testing.T) {
+	tests := []struct {
+		name               string
+		opts               RestoreOptions
+		expectFeatureState bool
+		expectPartial      bool
+		expectIndices      string
+		expectIgnore       bool
+	}{
+		{
+			name:               "with feature states",
+			opts:               RestoreOptions{Indices: "sts*", FeatureStates: []string{"kibana", "security"}, WaitForCompletion: true},
+			expectFeatureState: true,
+		},
+		{
+			name:          "exclude indices and ignore unavailable",
+			opts:          RestoreOptions{Indices: "sts*", ExcludeIndices: []string{"sts_k8s_logs*", "-sts_big"}, IgnoreUnavailable: true},
+			expectIndices: "sts*,-sts_k8s_logs*,-sts_big",
+			expectIgnore:  true,
+		},
+		{
+			name:          "partial",
+			opts:          RestoreOptions{Indices: "sts*", Partial: true},
+			expectPartial: true,
+		},
+		{
+			name: "without feature states",
+			opts: RestoreOptions{Indices: "sts*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_snapshot/test-repo/snap-1/_restore", r.URL.Path)
+
+				var body map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				expectIndices := tt.expectIndices
+				if expectIndices == "" {
+					expectIndices = "sts*"
+				}
+				assert.Equal(t, expectIndices, body["indices"])
+				if tt.expectIgnore {
+					assert.Equal(t, true, body["ignore_unavailable"])
+				} else {
+					assert.NotContains(t, body, "ignore_unavailable")
+				}
+				if tt.expectFeatureState {
+					assert.Equal(t, []interface{}{"kibana", "security"}, body["feature_states"])
+				} else {
+					assert.NotContains(t, body, "feature_states")
+				}
+				if tt.expectPartial {
+					assert.Equal(t, true, body["partial"])
+				} else {
+					assert.NotContains(t, body, "partial")
+				}
+
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"snapshot": {"snapshot": "snap-1", "shards": {"total": 1, "failed": 0, "successful": 1}}}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			err = client.RestoreSnapshotWithOptions("test-repo", "snap-1", tt.opts)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_RestoreSnapshot_PartialFailure(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshot": {"snapshot": "snap-1", "shards": {"total": 10, "failed": 2, "successful": 8}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.RestoreSnapshot("test-repo", "snap-1", "*", true)
+	require.Error(t, err)
+
+	var partialErr *PartialRestoreError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, 10, partialErr.Total)
+	assert.Equal(t, 2, partialErr.Failed)
+}
+
+func TestClient_RestoreSnapshot_PartialFailureShards(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if strings.HasPrefix(r.URL.Path, "/_cat/shards/") {
+			assert.Equal(t, "/_cat/shards/sts_topology,sts_metrics", r.URL.Path)
+			_, _ = w.Write([]byte(`[
+				{"index": "sts_topology", "shard": "0", "prirep": "p", "state": "STARTED", "node": "es-0"},
+				{"index": "sts_topology", "shard": "1", "prirep": "p", "state": "UNASSIGNED", "unassigned.reason": "NEW_INDEX_RESTORED", "unassigned.details": "restore failed: corrupt file"},
+				{"index": "sts_metrics", "shard": "0", "prirep": "r", "state": "UNASSIGNED", "unassigned.reason": "NEW_INDEX_RESTORED"}
+			]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"snapshot": {"snapshot": "snap-1", "indices": ["sts_topology", "sts_metrics"], "shards": {"total": 3, "failed": 1, "successful": 2}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.RestoreSnapshot("test-repo", "snap-1", "*", true)
+
+	var partialErr *PartialRestoreError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, []ShardFailure{
+		{Index: "sts_topology", Shard: 1, Reason: "NEW_INDEX_RESTORED: restore failed: corrupt file", Source: ShardFailureRestore},
+	}, partialErr.Failures)
+}
+
+func TestClient_ClusterHealth(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cluster/health", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"cluster_name": "sts", "status": "yellow", "number_of_nodes": 3, "unassigned_shards": 2, "active_shards_percent_as_number": 95.5}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	health, err := client.ClusterHealth()
+
+	require.NoError(t, err)
+	assert.Equal(t, "yellow", health.Status)
+	assert.Equal(t, 3, health.NumberOfNodes)
+	assert.Equal(t, 2, health.UnassignedShards)
+	assert.InDelta(t, 95.5, health.ActiveShardsPercent, 0.001)
+}
+
+func TestClient_GetClusterSettings(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cluster/settings", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("flat_settings"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"persistent": {"cluster.routing.rebalance.enable": "all", "cluster.routing.allocation.enable": "primaries"},
+			"transient": {"cluster.routing.allocation.enable": "none"}
+		}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	settings, err := client.GetClusterSettings()
+	require.NoError(t, err)
+
+	value, ok := settings.Get("cluster.routing.rebalance.enable")
+	assert.True(t, ok)
+	assert.Equal(t, "all", value)
+
+	// Transient settings take precedence
+	value, ok = settings.Get("cluster.routing.allocation.enable")
+	assert.True(t, ok)
+	assert.Equal(t, "none", value)
+
+	_, ok = settings.Get("indices.recovery.max_bytes_per_sec")
+	assert.False(t, ok)
+}
+
+func TestClient_PutClusterSettings(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cluster/settings", r.URL.Path)
+		assert.Equal(t, http.MethodPut, r.Method)
+
+		var body map[string]map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "none", body["persistent"]["cluster.routing.rebalance.enable"])
+		// Reset settings are sent as null
+		value, ok := body["persistent"]["cluster.routing.allocation.enable"]
+		assert.True(t, ok)
+		assert.Nil(t, value)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.PutClusterSettings(map[string]interface{}{
+		"cluster.routing.rebalance.enable":  "none",
+		"cluster.routing.allocation.enable": nil,
+	})
+	assert.NoError(t, err)
+}
+
+func TestClient_GetIngestPipelines(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expected       []string
+		expectError    bool
+	}{
+		{
+			name:           "pipelines",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"sts-logs": {"processors": [{"set": {"field": "a", "value": "b"}}]}, "sts-events": {"processors": []}}`,
+			expected:       []string{"sts-events", "sts-logs"},
+		},
+		{
+			name:           "no pipelines",
+			responseStatus: http.StatusNotFound,
+			responseBody:   `{}`,
+			expected:       []string{},
+		},
+		{
+			name:           "error",
+			responseStatus: http.StatusInternalServerError,
+			responseBody:   `{"error": "boom"}`,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_ingest/pipeline", r.URL.Path)
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			pipelines, err := client.GetIngestPipelines()
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(pipelines))
+			for name := range pipelines {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
+
+func TestClient_PutIngestPipeline(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_ingest/pipeline/sts-logs", r.URL.Path)
+		assert.Equal(t, http.MethodPut, r.Method)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Contains(t, body, "processors")
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.PutIngestPipeline("sts-logs", json.RawMessage(`{"processors": []}`))
+	assert.NoError(t, err)
+}
+
+func TestClient_VerifyRepository(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		responseBody   string
+		expectedNodes  int
+		expectError    bool
+	}{
+		{
+			name:           "verified on all nodes",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"nodes": {"a": {"name": "es-0"}, "b": {"name": "es-1"}}}`,
+			expectedNodes:  2,
+		},
+		{
+			name:           "verification failed",
+			responseStatus: http.StatusInternalServerError,
+			responseBody:   `{"error": {"type": "repository_verification_exception"}}`,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_snapshot/sts-backup/_verify", r.URL.Path)
+				assert.Equal(t, http.MethodPost, r.Method)
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			nodes, err := client.VerifyRepository("sts-backup")
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedNodes, nodes)
+		})
+	}
+}
+
+func TestClient_GetSLMPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		expectError  bool
+	}{
+		{
+			name: "policy with history",
+			responseBody: `{"auto-sts-backup": {
+				"version": 3,
+				"last_success": {"snapshot_name": "sts-backup-2", "time": 1700000000000},
+				"last_failure": {"snapshot_name": "sts-backup-1", "time": 1600000000000, "details": "boom"},
+				"next_execution_millis": 1700086400000
+			}}`,
+		},
+		{
+			name:         "policy missing from response",
+			responseBody: `{}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_slm/policy/auto-sts-backup", r.URL.Path)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			policy, err := client.GetSLMPolicy("auto-sts-backup")
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "auto-sts-backup", policy.Name)
+			require.NotNil(t, policy.LastSuccess)
+			assert.Equal(t, "sts-backup-2", policy.LastSuccess.SnapshotName)
+			require.NotNil(t, policy.LastFailure)
+			assert.Equal(t, "boom", policy.LastFailure.Details)
+			assert.Equal(t, int64(1700086400000), policy.NextExecutionMillis)
+		})
+	}
+}
+
+func TestClient_SLMStatus(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_slm/status", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"operation_mode": "RUNNING"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	status, err := client.SLMStatus()
+
+	require.NoError(t, err)
+	assert.Equal(t, "RUNNING", status)
+}
+
+func TestClient_ExecuteSLMRetention(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_slm/_execute_retention", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	assert.NoError(t, client.ExecuteSLMRetention())
+}
+
+func TestClient_SLMStats(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_slm/stats", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"retention_runs": 12, "retention_failed": 1, "retention_timed_out": 2,
+			"total_snapshots_deleted": 40, "total_snapshot_deletion_failures": 3, "policy_stats": []}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	stats, err := client.SLMStats()
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), stats.RetentionRuns)
+	assert.Equal(t, int64(40), stats.TotalSnapshotsDeleted)
+	assert.Equal(t, int64(3), stats.TotalSnapshotDeleteFailed)
+	assert.Equal(t, int64(15), stats.RetentionRunsFinished())
+}
+
+func TestClient_ExecuteSLMPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		response     string
+		expectedName string
+		expectError  bool
+	}{
+		{
+			name:         "successful execution",
+			statusCode:   http.StatusOK,
+			response:     `{"snapshot_name": "sts-backup-20250110-abcd"}`,
+			expectedName: "sts-backup-20250110-abcd",
+		},
+		{
+			name:        "policy not found",
+			statusCode:  http.StatusNotFound,
+			response:    `{"error": {"type": "resource_not_found_exception"}}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.Equal(t, "/_slm/policy/auto-sts-backup/_execute", r.URL.Path)
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			name, err := client.ExecuteSLMPolicy("auto-sts-backup")
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedName, name)
+		})
+	}
+}
+
+func TestClient_DeleteSnapshot(t *testing.T) {
+	tests := []struct {
+		name           string
+		responseStatus int
+		expectError    bool
+	}{
+		{
+			name:           "successful delete",
+			responseStatus: http.StatusOK,
+			expectError:    false,
+		},
+		{
+			name:           "snapshot not found",
+			responseStatus: http.StatusNotFound,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_snapshot/backup-repo/snap-1", r.URL.Path)
+				assert.Equal(t, http.MethodDelete, r.Method)
+
+				w.WriteHeader(tt.responseStatus)
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			err = client.DeleteSnapshot("backup-repo", "snap-1")
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_SnapshotStats(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_snapshot/test-repo/snap-1,snap-2/_status", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshots": [
+			{"snapshot": "snap-1", "stats": {"incremental": {"file_count": 10, "size_in_bytes": 1000}, "total": {"file_count": 10, "size_in_bytes": 1000}}},
+			{"snapshot": "snap-2", "stats": {"incremental": {"file_count": 2, "size_in_bytes": 200}, "total": {"file_count": 11, "size_in_bytes": 1100}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	stats, err := client.SnapshotStats("test-repo", []string{"snap-1", "snap-2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []SnapshotStats{
+		{Snapshot: "snap-1", IncrementalFileCount: 10, IncrementalSizeInBytes: 1000, TotalFileCount: 10, TotalSizeInBytes: 1000},
+		{Snapshot: "snap-2", IncrementalFileCount: 2, IncrementalSizeInBytes: 200, TotalFileCount: 11, TotalSizeInBytes: 1100},
+	}, stats)
+}
+
+func TestClient_ConfigureSnapshotRepository_Tuning(t *testing.T) {
+	compress := true
+	tests := []struct {
+		name     string
+		tuning   RepositoryTuning
+		expected map[string]interface{}
+	}{
+		{
+			name: "all tuning settings",
+			tuning: RepositoryTuning{
+				ChunkSize:              "1gb",
+				Compress:               &compress,
+				MaxSnapshotBytesPerSec: "200mb",
+				MaxRestoreBytesPerSec:  "500mb",
+			},
+			expected: map[string]interface{}{
+				"chunk_size":                 "1gb",
+				"compress":                   true,
+				"max_snapshot_bytes_per_sec": "200mb",
+				"max_restore_bytes_per_sec":  "500mb",
+			},
+		},
+		{
+			name:     "elasticsearch defaults",
+			expected: map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_snapshot/sts-backup", r.URL.Path)
+
+				var body struct {
+					Settings map[string]interface{} `json:"settings"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "sts-backup", body.Settings["bucket"])
+				for _, key := range []string{"chunk_size", "compress", "max_snapshot_bytes_per_sec", "max_restore_bytes_per_sec"} {
+					expected, ok := tt.expected[key]
+					if !ok {
+						assert.NotContains(t, body.Settings, key)
+						continue
+					}
+					assert.Equal(t, expected, body.Settings[key])
+				}
+
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"acknowledged": true}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			err = client.ConfigureSnapshotRepository("sts-backup", "sts-backup", "minio:9000", "", "key", "secret", S3Connection{}, tt.tuning)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_ConfigureSnapshotRepository_S3Connection(t *testing.T) {
+	tests := []struct {
+		name     string
+		conn     S3Connection
+		expected map[string]string
+	}{
+		{
+			name:     "in-cluster MinIO",
+			conn:     S3Connection{Protocol: "http", Region: "minio", PathStyleAccess: true},
+			expected: map[string]string{"protocol": "http", "region": "minio", "path_style_access": "true"},
+		},
+		{
+			name:     "AWS S3",
+			conn:     S3Connection{Protocol: "https", Region: "eu-west-1"},
+			expected: map[string]string{"protocol": "https", "region": "eu-west-1", "path_style_access": "false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Settings map[string]string `json:"settings"`
+				}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				for key, expected := range tt.expected {
+					assert.Equal(t, expected, body.Settings[key], key)
+				}
+
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"acknowledged": true}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			err = client.ConfigureSnapshotRepository("sts-backup", "sts-backup", "s3.example.com", "", "key", "secret", tt.conn, RepositoryTuning{})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_RegisterReadOnlyRepository(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_snapshot/sts-backup-from-prod", r.URL.Path)
+		assert.Equal(t, http.MethodPut, r.Method)
+
+		var body struct {
+			Type     string            `json:"type"`
+			Settings map[string]string `json:"settings"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "s3", body.Type)
+		assert.Equal(t, "true", body.Settings["readonly"])
+		assert.Equal(t, "sts-backup", body.Settings["bucket"])
+		assert.Equal(t, "minio.prod.svc:9000", body.Settings["endpoint"])
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.RegisterReadOnlyRepository("sts-backup-from-prod", "sts-backup", "minio.prod.svc:9000", "elasticsearch", "key", "secret", S3Connection{}, RepositoryTuning{})
+	assert.NoError(t, err)
+}
+
+func TestClient_ConfigureSnapshotRepository_MissingType(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expectType  bool
+		errContains string
+	}{
+		{
+			name:       "repository type does not exist",
+			status:     http.StatusInternalServerError,
+			body:       `{"error": {"type": "repository_exception", "reason": "[sts-backup] repository type [s3] does not exist"}, "status": 500}`,
+			expectType: true,
+		},
+		{
+			name:       "unknown repository type",
+			status:     http.StatusBadRequest,
+			body:       `{"error": {"type": "x_content_parse_exception", "reason": "Unknown [repository] type [s3]"}, "status": 400}`,
+			expectType: true,
+		},
+		{
+			name:        "other error",
+			status:      http.StatusInternalServerError,
+			body:        `{"error": {"type": "repository_verification_exception", "reason": "[sts-backup] path is not accessible"}, "status": 500}`,
+			errContains: "path is not accessible",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			err = client.ConfigureSnapshotRepository("sts-backup", "sts-backup", "minio:9000", "", "key", "secret", S3Connection{}, RepositoryTuning{})
+
+			require.Error(t, err)
+			var typeErr *RepositoryTypeError
+			assert.Equal(t, tt.expectType, errors.As(err, &typeErr))
+			if tt.expectType {
+				assert.Equal(t, "s3", typeErr.Type)
+			}
+			if tt.errContains != "" {
+				assert.Contains(t, err.Error(), tt.errContains)
+			}
+		})
+	}
+}
+
+func TestClient_Version(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"name": "es-0", "version": {"number": "8.19.0"}, "tagline": "You Know, for Search"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	version, err := client.Version()
+
+	require.NoError(t, err)
+	assert.Equal(t, "8.19.0", version)
+}
+
+func TestClient_ListPlugins(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/plugins", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"name": "es-0", "component": "analysis-icu", "version": "8.19.0"},
+			{"name": "es-1", "component": "analysis-icu", "version": "8.19.0"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	plugins, err := client.ListPlugins()
+
+	require.NoError(t, err)
+	require.Len(t, plugins, 2)
+	assert.Equal(t, Plugin{Node: "es-0", Component: "analysis-icu", Version: "8.19.0"}, plugins[0])
+}
+
+func TestClient_GetRepository(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		expectFound bool
+	}{
+		{
+			name:        "existing repository",
+			status:      http.StatusOK,
+			body:        `{"sts-backup": {"type": "s3", "settings": {"bucket": "sts-backup", "compress": "true"}}}`,
+			expectFound: true,
+		},
+		{
+			name:   "missing repository",
+			status: http.StatusNotFound,
+			body:   `{"error": {"type": "repository_missing_exception", "reason": "[sts-backup] missing"}, "status": 404}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_snapshot/sts-backup", r.URL.Path)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			repository, err := client.GetRepository("sts-backup")
+
+			if !tt.expectFound {
+				assert.ErrorIs(t, err, ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "s3", repository.Type)
+			assert.Equal(t, "true", repository.Settings["compress"])
+		})
+	}
+}
+
+func TestClient_GetSLMPolicy_Definition(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"daily": {"version": 2, "policy": {"name": "<sts-backup-{now/d}>", "schedule": "0 0 3 * * ?",
+			"repository": "sts-backup", "config": {"indices": "sts*"}, "retention": {"expire_after": "30d", "min_count": 5}}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	policy, err := client.GetSLMPolicy("daily")
+
+	require.NoError(t, err)
+	require.NotNil(t, policy.Policy)
+	assert.Equal(t, "0 0 3 * * ?", policy.Policy.Schedule)
+	assert.Equal(t, "sts*", policy.Policy.Config["indices"])
+	assert.Equal(t, float64(5), policy.Policy.Retention["min_count"])
+}
+
+func TestClient_GetSLMPolicy_NotFound(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": {"type": "resource_not_found_exception", "reason": "snapshot lifecycle policy or policies [daily] not found"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	_, err = client.GetSLMPolicy("daily")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "SLM policy daily not found")
+}
+
+func TestLatestSuccessful(t *testing.T) {
+	snapshots := []Snapshot{
+		{Snapshot: "old", State: "SUCCESS", StartTimeMillis: 1000},
+		{Snapshot: "newest-failed", State: "FAILED", StartTimeMillis: 3000},
+		{Snapshot: "latest", State: "SUCCESS", StartTimeMillis: 2000},
+		{Snapshot: "partial", State: "PARTIAL", StartTimeMillis: 2500},
+	}
+
+	latest := LatestSuccessful(snapshots)
+	require.NotNil(t, latest)
+	assert.Equal(t, "latest", latest.Snapshot)
+
+	assert.Nil(t, LatestSuccessful(snapshots[1:2]))
+	assert.Nil(t, LatestSuccessful(nil))
+}
+
+func TestClient_RestoreSnapshotWithOptions_Rename(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "(.+)", body["rename_pattern"])
+		assert.Equal(t, "copy-$1", body["rename_replacement"])
+		assert.Equal(t, false, body["include_aliases"])
+		assert.Equal(t, map[string]interface{}{"index.number_of_replicas": float64(0)}, body["index_settings"])
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshot": {"snapshot": "snap-1", "shards": {"total": 1, "failed": 0, "successful": 1}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.RestoreSnapshotWithOptions("test-repo", "snap-1", RestoreOptions{
+		Indices:           "sts_topology",
+		RenamePattern:     "(.+)",
+		RenameReplacement: "copy-$1",
+		ExcludeAliases:    true,
+		IndexSettings:     map[string]interface{}{"index.number_of_replicas": 0},
+		WaitForCompletion: true,
+	})
+	assert.NoError(t, err)
+}
+
+func TestClient_CountDocuments(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts_topology/_count", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"count": 1234}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	count, err := client.CountDocuments("sts_topology")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), count)
+}
+
+func TestClient_SampleDocuments(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts_topology/_search", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("size"))
+		assert.Equal(t, "_doc", r.URL.Query().Get("sort"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"hits": {"hits": [{"_id": "a", "_source": {"n": 1}}, {"_id": "b", "_source": {"n": 2}}]}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	documents, err := client.SampleDocuments("sts_topology", 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"a": json.RawMessage(`{"n": 1}`), "b": json.RawMessage(`{"n": 2}`)}, documents)
+}
+
+func TestClient_GetDocuments(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts_topology/_mget", r.URL.Path)
+		var body map[string][]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []string{"a", "missing"}, body["ids"])
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"docs": [{"_id": "a", "found": true, "_source": {"n": 1}}, {"_id": "missing", "found": false}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	documents, err := client.GetDocuments("sts_topology", []string{"a", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{"a": json.RawMessage(`{"n": 1}`)}, documents)
+}
+
+func TestClient_ListRecoveries(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/recovery", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("active_only"))
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"index": "sts_topology", "shard": "0", "time": "1.2s", "type": "snapshot", "stage": "index",
+			"source_node": "n/a", "target_node": "es-master-0", "repository": "sts-backup", "snapshot": "snap-1",
+			"files_percent": "50.0%", "bytes_percent": "42.5%", "bytes_total": "1gb"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	recoveries, err := client.ListRecoveries(true)
+	require.NoError(t, err)
+	require.Len(t, recoveries, 1)
+	assert.Equal(t, "sts_topology", recoveries[0].Index)
+	assert.Equal(t, "snapshot", recoveries[0].Type)
+	assert.Equal(t, "index", recoveries[0].Stage)
+	assert.Equal(t, "42.5%", recoveries[0].BytesPercent)
+	assert.Equal(t, "snap-1", recoveries[0].Snapshot)
+}
+
+func TestClient_ListShards(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/shards/sts_topology", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[
+			{"index": "sts_topology", "shard": "0", "prirep": "p", "state": "STARTED", "node": "es-master-0"},
+			{"index": "sts_topology", "shard": "0", "prirep": "r", "state": "UNASSIGNED", "node": null,
+			 "unassigned.reason": "NEW_INDEX_RESTORED", "unassigned.details": null}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	shards, err := client.ListShards("sts_topology")
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+	assert.True(t, shards[0].Primary())
+	assert.Equal(t, "es-master-0", shards[0].Node)
+	assert.False(t, shards[1].Primary())
+	assert.Equal(t, "UNASSIGNED", shards[1].State)
+	assert.Equal(t, "NEW_INDEX_RESTORED", shards[1].UnassignedReason)
+}
+
+func TestClient_ExplainAllocation(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/_cluster/allocation/explain", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"index": "sts_topology", "shard": 1, "primary": true}`, string(body))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"index": "sts_topology", "shard": 1, "primary": true, "current_state": "unassigned",
+			"unassigned_info": {"reason": "NEW_INDEX_RESTORED", "at": "2025-01-15T10:00:00.000Z", "last_allocation_status": "no"},
+			"can_allocate": "no", "allocate_explanation": "Elasticsearch isn't allowed to allocate this shard to any of the nodes in the cluster.",
+			"node_allocation_decisions": [{"node_id": "abc", "node_name": "es-master-0", "node_decision": "no",
+				"deciders": [{"decider": "disk_threshold", "decision": "NO", "explanation": "the node is above the low watermark"}]}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	explanation, err := client.ExplainAllocation("sts_topology", 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, "unassigned", explanation.CurrentState)
+	require.NotNil(t, explanation.UnassignedInfo)
+	assert.Equal(t, "NEW_INDEX_RESTORED", explanation.UnassignedInfo.Reason)
+	assert.Equal(t, "no", explanation.CanAllocate)
+	require.Len(t, explanation.NodeDecisions, 1)
+	assert.Equal(t, "es-master-0", explanation.NodeDecisions[0].NodeName)
+	assert.Equal(t, []AllocationDecider{{Decider: "disk_threshold", Decision: "NO", Explanation: "the node is above the low watermark"}},
+		explanation.NodeDecisions[0].Deciders)
+}
+
+func TestClient_ListAliases(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/aliases/sts_*", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[{"alias": "sts_topology", "index": "restored-sts_topology", "is_write_index": "-"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	aliases, err := client.ListAliases("sts_*")
+	require.NoError(t, err)
+	assert.Equal(t, []Alias{{Alias: "sts_topology", Index: "restored-sts_topology", IsWriteIndex: "-"}}, aliases)
+}
+
+func TestClient_UpdateAliases(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/_aliases", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"actions": [
+			{"remove": {"index": "sts_topology", "alias": "topology"}},
+			{"add": {"index": "restored-sts_topology", "alias": "topology"}}
+		]}`, string(body))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.UpdateAliases([]AliasAction{
+		{Type: AliasActionRemove, Index: "sts_topology", Alias: "topology"},
+		{Type: AliasActionAdd, Index: "restored-sts_topology", Alias: "topology"},
+	})
+	require.NoError(t, err)
+}
+
+func TestClient_StartReindex(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/_reindex", r.URL.Path)
+		assert.Equal(t, "false", r.URL.Query().Get("wait_for_completion"))
+		assert.Equal(t, "500", r.URL.Query().Get("requests_per_second"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"source": {"index": "sts_topology"}, "dest": {"index": "suse_topology"}}`, string(body))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"task": "oTUltX4IQMOUUVeiohTt8A:12345"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	taskID, err := client.StartReindex("sts_topology", "suse_topology", 500)
+	require.NoError(t, err)
+	assert.Equal(t, "oTUltX4IQMOUUVeiohTt8A:12345", taskID)
+}
+
+func TestClient_GetReindexStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected ReindexStatus
+	}{
+		{
+			name:     "running",
+			response: `{"completed": false, "task": {"status": {"total": 1000, "created": 400, "updated": 0}}}`,
+			expected: ReindexStatus{Total: 1000, Created: 400},
+		},
+		{
+			name: "completed with failures",
+			response: `{"completed": true, "task": {"status": {"total": 1000, "created": 998, "updated": 0}},
+				"response": {"failures": [{"index": "suse_topology", "cause": {"reason": "mapper_parsing_exception"}}]}}`,
+			expected: ReindexStatus{Completed: true, Total: 1000, Created: 998, Failures: []string{"mapper_parsing_exception"}},
+		},
+		{
+			name:     "failed",
+			response: `{"completed": true, "task": {"status": {"total": 0}}, "error": {"reason": "no such index [sts_topology]"}}`,
+			expected: ReindexStatus{Completed: true, Failures: []string{"no such index [sts_topology]"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_tasks/node-1:7", r.URL.Path)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			status, err := client.GetReindexStatus("node-1:7")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, *status)
+		})
+	}
+}
+
+func TestClient_GetILMPolicies(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_ilm/policy", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"sts-logs": {"version": 3, "modified_date": "2025-03-04T03:00:00.000Z",
+			"policy": {"phases": {"delete": {"min_age": "7d", "actions": {"delete": {}}}}}, "in_use_by": {"indices": ["sts_logs-1"]}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	policies, err := client.GetILMPolicies()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"phases": {"delete": {"min_age": "7d", "actions": {"delete": {}}}}}`, string(policies["sts-logs"]))
+}
+
+func TestClient_PutILMPolicy(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/_ilm/policy/sts-logs", r.URL.Path)
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.JSONEq(t, `{"phases": {}}`, string(body["policy"]))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"acknowledged": true}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, client.PutILMPolicy("sts-logs", json.RawMessage(`{"phases": {}}`)))
+}
+
+func TestClient_IndexLifecyclePolicies(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sts*,.ds-sts_k8s_logs*/_settings/index.lifecycle.name", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("flat_settings"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"sts_topology": {"settings": {}},
+			".ds-sts_k8s_logs-2025.03.04-000001": {"settings": {"index.lifecycle.name": "sts-logs"}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	policies, err := client.IndexLifecyclePolicies("sts*,.ds-sts_k8s_logs*")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{".ds-sts_k8s_logs-2025.03.04-000001": "sts-logs"}, policies)
+}
+
+func TestClient_ListTasks(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_tasks", r.URL.Path)
+		assert.Equal(t, "cluster:admin/snapshot/*,indices:data/write/delete/byquery", r.URL.Query().Get("actions"))
+		assert.Equal(t, "none", r.URL.Query().Get("group_by"))
+		assert.Equal(t, "true", r.URL.Query().Get("detailed"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"tasks": [{"node": "oTUltX4IQMOUUVeiohTt8A", "id": 464, "type": "transport",
+			"action": "cluster:admin/snapshot/create", "description": "snapshot [sts-backup:snap-1]",
+			"start_time_in_millis": 1741057200000, "running_time_in_nanos": 90000000000, "cancellable": true, "cancelled": false}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	tasks, err := client.ListTasks([]string{"cluster:admin/snapshot/*", "indices:data/write/delete/byquery"})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "oTUltX4IQMOUUVeiohTt8A:464", tasks[0].ID)
+	assert.Equal(t, "cluster:admin/snapshot/create", tasks[0].Action)
+	assert.Equal(t, int64(90000000000), tasks[0].RunningTimeNanos)
+	assert.True(t, tasks[0].Cancellable)
+}
+
+func TestClient_CancelTask(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseBody string
+		expectError  string
+	}{
+		{
+			name:         "cancelled",
+			responseBody: `{"nodes": {}}`,
+		},
+		{
+			name:         "node failure",
+			responseBody: `{"node_failures": [{"type": "failed_node_exception", "caused_by": {"type": "x", "reason": "task is not cancellable"}}]}`,
+			expectError:  "failed to cancel task node-1:7: task is not cancellable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/_tasks/node-1:7/_cancel", r.URL.Path)
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			err = client.CancelTask("node-1:7")
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClient_RolloverDatastreamWithOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           RolloverOptions
+		expectedBody   string
+		expectedDryRun string
+	}{
+		{
+			name:         "unconditional",
+			expectedBody: "",
+		},
+		{
+			name:           "conditions, dry run",
+			opts:           RolloverOptions{MaxAge: "7d", MaxSize: "50gb", DryRun: true},
+			expectedBody:   `{"conditions": {"max_age": "7d", "max_size": "50gb"}}`,
+			expectedDryRun: "true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/sts_k8s_logs/_rollover", r.URL.Path)
+				assert.Equal(t, tt.expectedDryRun, r.URL.Query().Get("dry_run"))
+				body, _ := io.ReadAll(r.Body)
+				if tt.expectedBody == "" {
+					assert.Empty(t, body)
+				} else {
+					assert.JSONEq(t, tt.expectedBody, string(body))
+				}
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"acknowledged": true, "old_index": ".ds-sts_k8s_logs-2025.03.04-000001",
+					"new_index": ".ds-sts_k8s_logs-2025.03.05-000002", "rolled_over": false, "dry_run": true,
+					"conditions": {"[max_age: 7d]": false, "[max_size: 50gb]": true}}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			result, err := client.RolloverDatastreamWithOptions("sts_k8s_logs", tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, ".ds-sts_k8s_logs-2025.03.05-000002", result.NewIndex)
+			assert.Equal(t, map[string]bool{"[max_age: 7d]": false, "[max_size: 50gb]": true}, result.Conditions)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScheduleTimesOfDay returns the times of day an SLM cron schedule, e.g. 0 30 1 * * ?, fires at, as offsets from
+// midnight in ascending order. Elasticsearch cron expressions have the fields seconds, minutes, hours, day of month,
+// month, day of week and an optional year; only the seconds, minutes and hours are interpreted, so the times are
+// those of every day the schedule fires on.
+//
+// Supported in these fields are *, ?, values, ranges (1-5), increments (*/15, 0/15, 1-5/2) and lists of them.
+func ScheduleTimesOfDay(schedule string) ([]time.Duration, error) {
+	fields := strings.Fields(schedule)
+	if len(fields) != 6 && len(fields) != 7 {
+		return nil, fmt.Errorf("schedule '%s' is not a cron expression with the fields seconds, minutes, hours, day of month, month, day of week and an optional year", schedule)
+	}
+
+	seconds, err := parseCronField(fields[0], 59)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seconds in schedule '%s': %w", schedule, err)
+	}
+	minutes, err := parseCronField(fields[1], 59)
+	if err != nil {
+		return nil, fmt.Errorf("invalid minutes in schedule '%s': %w", schedule, err)
+	}
+	hours, err := parseCronField(fields[2], 23)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hours in schedule '%s': %w", schedule, err)
+	}
+
+	times := make([]time.Duration, 0, len(hours)*len(minutes)*len(seconds))
+	for _, hour := range hours {
+		for _, minute := range minutes {
+			for _, second := range seconds {
+				times = append(times, time.Duration(hour)*time.Hour+time.Duration(minute)*time.Minute+time.Duration(second)*time.Second)
+			}
+		}
+	}
+	return times, nil
+}
+
+// parseCronField returns the values from 0 to maxValue that a comma-separated cron field selects, in ascending order
+func parseCronField(field string, maxValue int) ([]int, error) {
+	selected := make([]bool, maxValue+1)
+	for _, part := range strings.Split(field, ",") {
+		first, last, step, err := parseCronRange(part, maxValue)
+		if err != nil {
+			return nil, err
+		}
+		for value := first; value <= last; value += step {
+			selected[value] = true
+		}
+	}
+
+	values := make([]int, 0, len(selected))
+	for value, ok := range selected {
+		if ok {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+// parseCronRange parses *, ?, a value, a range a-b, each with an optional increment /n
+func parseCronRange(part string, maxValue int) (int, int, int, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+	step := 1
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+			return 0, 0, 0, fmt.Errorf("'%s' has an invalid increment", part)
+		}
+	}
+
+	if rangePart == "*" || rangePart == "?" {
+		return 0, maxValue, step, nil
+	}
+	firstPart, lastPart, isRange := strings.Cut(rangePart, "-")
+	first, err := cronValue(firstPart, maxValue)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	last := first
+	switch {
+	case isRange:
+		if last, err = cronValue(lastPart, maxValue); err != nil {
+			return 0, 0, 0, err
+		}
+		if last < first {
+			return 0, 0, 0, fmt.Errorf("range '%s' ends before it starts", rangePart)
+		}
+	case hasStep:
+		// a/n starts at a and repeats until the end of the range of the field
+		last = maxValue
+	}
+	return first, last, step, nil
+}
+
+// cronValue parses a value of a cron field from 0 to maxValue
+func cronValue(value string, maxValue int) (int, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 || number > maxValue {
+		return 0, fmt.Errorf("'%s' is not a value from 0 to %d", value, maxValue)
+	}
+	return number, nil
+}
@@ -0,0 +1,56 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleTimesOfDay(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		expected []time.Duration
+	}{
+		{name: "SLM default", schedule: "0 0 3 * * ?", expected: []time.Duration{3 * time.Hour}},
+		{name: "with year", schedule: "0 30 1 ? * MON-FRI 2025", expected: []time.Duration{time.Hour + 30*time.Minute}},
+		{name: "list of hours", schedule: "0 15 1,4 * * ?", expected: []time.Duration{time.Hour + 15*time.Minute, 4*time.Hour + 15*time.Minute}},
+		{name: "range with increment", schedule: "0 0 1-5/2 * * ?", expected: []time.Duration{time.Hour, 3 * time.Hour, 5 * time.Hour}},
+		{name: "increment from a value", schedule: "0 0 20/2 * * ?", expected: []time.Duration{20 * time.Hour, 22 * time.Hour}},
+		{name: "every 30 minutes", schedule: "0 */30 2 * * ?", expected: []time.Duration{2 * time.Hour, 2*time.Hour + 30*time.Minute}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			times, err := ScheduleTimesOfDay(tt.schedule)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, times)
+		})
+	}
+
+	times, err := ScheduleTimesOfDay("0 0 * * * ?")
+	require.NoError(t, err)
+	assert.Len(t, times, 24, "every hour")
+}
+
+func TestScheduleTimesOfDay_Errors(t *testing.T) {
+	for schedule, expected := range map[string]string{
+		"1h":              "is not a cron expression",
+		"0 0 3 * *":       "is not a cron expression",
+		"0 0 24 * * ?":    "invalid hours",
+		"0 60 3 * * ?":    "invalid minutes",
+		"0 0 5-1 * * ?":   "ends before it starts",
+		"0 0 */0 * * ?":   "invalid increment",
+		"x 0 3 * * ?":     "invalid seconds",
+		"0 0 3,x * * ?":   "'x' is not a value from 0 to 23",
+		"0 0 1-x/2 * * ?": "'x' is not a value from 0 to 23",
+	} {
+		t.Run(schedule, func(t *testing.T) {
+			_, err := ScheduleTimesOfDay(schedule)
+
+			assert.ErrorContains(t, err, expected)
+		})
+	}
+}
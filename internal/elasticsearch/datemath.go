@@ -0,0 +1,265 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultDateMathFormat is the format of a date math expression without one, as in Elasticsearch
+const defaultDateMathFormat = "yyyy.MM.dd"
+
+// ResolveDateMathName resolves a name with date math, as used for the snapshot names of SLM policies, e.g.
+// <sts-backup-{now{yyyyMMdd-HHmm}}>, the way Elasticsearch resolves it at now. A name that is not enclosed in
+// angle brackets is returned as it is.
+//
+// Supported are the expressions now, now/d, now-1d/d and the like, with a format of the date letters y, M, d, H, m,
+// s and S and an optional time zone, e.g. {now/d{yyyy.MM.dd|Europe/Amsterdam}}. Braces are escaped with a backslash.
+func ResolveDateMathName(name string, now time.Time) (string, error) {
+	if !strings.HasPrefix(name, "<") || !strings.HasSuffix(name, ">") {
+		return name, nil
+	}
+	template := name[1 : len(name)-1]
+
+	var resolved strings.Builder
+	for i := 0; i < len(template); i++ {
+		switch c := template[i]; c {
+		case '\\':
+			if i+1 < len(template) {
+				i++
+				resolved.WriteByte(template[i])
+			}
+		case '{':
+			end, err := closingBrace(template, i)
+			if err != nil {
+				return "", fmt.Errorf("invalid date math name %s: %w", name, err)
+			}
+			value, err := resolveDateMathExpression(template[i+1:end], now)
+			if err != nil {
+				return "", fmt.Errorf("invalid date math name %s: %w", name, err)
+			}
+			resolved.WriteString(value)
+			i = end
+		case '}':
+			return "", fmt.Errorf("invalid date math name %s: unexpected '}' at position %d", name, i+1)
+		default:
+			resolved.WriteByte(c)
+		}
+	}
+	return resolved.String(), nil
+}
+
+// closingBrace returns the index of the brace closing the one at start, allowing one nested pair for the format
+func closingBrace(template string, start int) (int, error) {
+	depth := 0
+	for i := start; i < len(template); i++ {
+		switch template[i] {
+		case '{':
+			depth++
+			if depth > 2 {
+				return 0, fmt.Errorf("too many nested braces at position %d", i+1)
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("missing '}' for '{' at position %d", start+1)
+}
+
+// resolveDateMathExpression resolves math{format|zone}
+func resolveDateMathExpression(expression string, now time.Time) (string, error) {
+	math, format := expression, defaultDateMathFormat
+	location := time.UTC
+	if open := strings.IndexByte(expression, '{'); open >= 0 {
+		if !strings.HasSuffix(expression, "}") {
+			return "", fmt.Errorf("text after the format of '%s'", expression)
+		}
+		math, format = expression[:open], expression[open+1:len(expression)-1]
+		if pattern, zone, found := strings.Cut(format, "|"); found {
+			var err error
+			if location, err = parseTimeZone(zone); err != nil {
+				return "", err
+			}
+			format = pattern
+		}
+	}
+
+	t, err := applyDateMath(math, now.In(location))
+	if err != nil {
+		return "", err
+	}
+	return formatJavaDate(format, t)
+}
+
+// applyDateMath applies the additions, subtractions and roundings of an expression starting with now
+func applyDateMath(math string, now time.Time) (time.Time, error) {
+	if !strings.HasPrefix(math, "now") {
+		return time.Time{}, fmt.Errorf("date math '%s' must start with now", math)
+	}
+	t := now
+	rest := math[len("now"):]
+	for rest != "" {
+		op := rest[0]
+		rest = rest[1:]
+		switch op {
+		case '+', '-':
+			digits := 0
+			for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+				digits++
+			}
+			if digits == 0 || digits == len(rest) {
+				return time.Time{}, fmt.Errorf("date math '%s' needs a number and a unit after '%c'", math, op)
+			}
+			amount, err := strconv.Atoi(rest[:digits])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("invalid amount in date math '%s': %w", math, err)
+			}
+			if op == '-' {
+				amount = -amount
+			}
+			if t, err = addDateUnit(t, rest[digits], amount); err != nil {
+				return time.Time{}, fmt.Errorf("date math '%s': %w", math, err)
+			}
+			rest = rest[digits+1:]
+		case '/':
+			if rest == "" {
+				return time.Time{}, fmt.Errorf("date math '%s' needs a unit after '/'", math)
+			}
+			var err error
+			if t, err = roundDateUnit(t, rest[0]); err != nil {
+				return time.Time{}, fmt.Errorf("date math '%s': %w", math, err)
+			}
+			rest = rest[1:]
+		default:
+			return time.Time{}, fmt.Errorf("unexpected '%c' in date math '%s'", op, math)
+		}
+	}
+	return t, nil
+}
+
+func addDateUnit(t time.Time, unit byte, amount int) (time.Time, error) {
+	switch unit {
+	case 'y':
+		return t.AddDate(amount, 0, 0), nil
+	case 'M':
+		return t.AddDate(0, amount, 0), nil
+	case 'w':
+		return t.AddDate(0, 0, 7*amount), nil
+	case 'd':
+		return t.AddDate(0, 0, amount), nil
+	case 'h', 'H':
+		return t.Add(time.Duration(amount) * time.Hour), nil
+	case 'm':
+		return t.Add(time.Duration(amount) * time.Minute), nil
+	case 's':
+		return t.Add(time.Duration(amount) * time.Second), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown unit '%c'", unit)
+	}
+}
+
+func roundDateUnit(t time.Time, unit byte) (time.Time, error) {
+	year, month, day := t.Date()
+	switch unit {
+	case 'y':
+		return time.Date(year, time.January, 1, 0, 0, 0, 0, t.Location()), nil
+	case 'M':
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location()), nil
+	case 'w':
+		// Weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location()), nil
+	case 'd':
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location()), nil
+	case 'h', 'H':
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location()), nil
+	case 'm':
+		return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, t.Location()), nil
+	case 's':
+		return time.Date(year, month, day, t.Hour(), t.Minute(), t.Second(), 0, t.Location()), nil
+	default:
+		return time.Time{}, fmt.Errorf("unknown unit '%c'", unit)
+	}
+}
+
+// parseTimeZone parses a time zone ID, e.g. Europe/Amsterdam, or an offset, e.g. +01:00
+func parseTimeZone(zone string) (*time.Location, error) {
+	if strings.HasPrefix(zone, "+") || strings.HasPrefix(zone, "-") {
+		offset, err := time.Parse("-07:00", zone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone offset '%s'", zone)
+		}
+		_, seconds := offset.Zone()
+		return time.FixedZone(zone, seconds), nil
+	}
+	location, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s': %w", zone, err)
+	}
+	return location, nil
+}
+
+// formatJavaDate formats t with the date letters of a Java date format, which Elasticsearch uses; other letters are
+// refused, other characters are kept, and text in single quotes is kept as it is
+func formatJavaDate(format string, t time.Time) (string, error) {
+	var formatted strings.Builder
+	for i := 0; i < len(format); {
+		c := format[i]
+		if c == '\'' {
+			end := strings.IndexByte(format[i+1:], '\'')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated quote in date format '%s'", format)
+			}
+			formatted.WriteString(format[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		if !isLetter(c) {
+			formatted.WriteByte(c)
+			i++
+			continue
+		}
+
+		count := 1
+		for i+count < len(format) && format[i+count] == c {
+			count++
+		}
+		i += count
+
+		var value int
+		switch c {
+		case 'y', 'u':
+			value = t.Year()
+			if count == 2 {
+				value %= 100
+			}
+		case 'M':
+			value = int(t.Month())
+		case 'd':
+			value = t.Day()
+		case 'H':
+			value = t.Hour()
+		case 'm':
+			value = t.Minute()
+		case 's':
+			value = t.Second()
+		case 'S':
+			// Fractions of a second, to as many digits as letters
+			fraction := fmt.Sprintf("%09d", t.Nanosecond())
+			formatted.WriteString(fraction[:min(count, len(fraction))])
+			continue
+		default:
+			return "", fmt.Errorf("unsupported letter '%c' in date format '%s'", c, format)
+		}
+		formatted.WriteString(fmt.Sprintf("%0*d", count, value))
+	}
+	return formatted.String(), nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
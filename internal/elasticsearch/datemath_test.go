@@ -0,0 +1,59 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDateMathName(t *testing.T) {
+	now := time.Date(2025, 1, 15, 3, 4, 5, 678000000, time.UTC)
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "SLM default", template: "<sts-backup-{now{yyyyMMdd-HHmm}}>", expected: "sts-backup-20250115-0304"},
+		{name: "plain name", template: "manual-snapshot", expected: "manual-snapshot"},
+		{name: "default format", template: "<snap-{now}>", expected: "snap-2025.01.15"},
+		{name: "subtract and round", template: "<snap-{now-1d/d{yyyy.MM.dd.HH}}>", expected: "snap-2025.01.14.00"},
+		{name: "add months and round to the month", template: "<snap-{now+1M/M{yyMMdd}}>", expected: "snap-250201"},
+		{name: "round to the week", template: "<snap-{now/w{yyyy-MM-dd}}>", expected: "snap-2025-01-13"},
+		{name: "fractions and seconds", template: "<snap-{now{HHmmss.SSS}}>", expected: "snap-030405.678"},
+		{name: "time zone", template: "<snap-{now{yyyyMMdd-HH|Asia/Tokyo}}>", expected: "snap-20250115-12"},
+		{name: "time zone offset", template: "<snap-{now{HHmm|-02:30}}>", expected: "snap-0034"},
+		{name: "quoted text", template: "<snap-{now{yyyy'w'MM}}>", expected: "snap-2025w01"},
+		{name: "escaped braces", template: `<snap-\{x\}-{now{yyyy}}>`, expected: "snap-{x}-2025"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := ResolveDateMathName(tt.template, now)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
+
+func TestResolveDateMathName_Errors(t *testing.T) {
+	now := time.Date(2025, 1, 15, 3, 4, 5, 0, time.UTC)
+
+	for template, expected := range map[string]string{
+		"<snap-{now{yyyyMMdd}>":        "missing '}'",
+		"<snap-}>":                     "unexpected '}'",
+		"<snap-{today}>":               "must start with now",
+		"<snap-{now-1q}>":              "unknown unit 'q'",
+		"<snap-{now-d}>":               "needs a number and a unit",
+		"<snap-{now{yyyy-EEE}}>":       "unsupported letter 'E'",
+		"<snap-{now{yyyy|Mars/Base}}>": "invalid time zone 'Mars/Base'",
+	} {
+		t.Run(template, func(t *testing.T) {
+			_, err := ResolveDateMathName(template, now)
+
+			assert.ErrorContains(t, err, expected)
+		})
+	}
+}
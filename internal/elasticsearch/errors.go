@@ -0,0 +1,176 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// ErrNotFound is returned when a requested snapshot, repository or SLM policy does not exist
+var ErrNotFound = errors.New("not found")
+
+var (
+	// ErrSnapshotNotFound is returned when a snapshot does not exist in the repository. It matches ErrNotFound.
+	ErrSnapshotNotFound = fmt.Errorf("snapshot %w", ErrNotFound)
+	// ErrRepositoryMissing is returned when a snapshot repository is not registered. It matches ErrNotFound.
+	ErrRepositoryMissing = fmt.Errorf("snapshot repository %w", ErrNotFound)
+)
+
+// ErrConnectionLost is returned when the connection to Elasticsearch broke off before a response was received,
+// e.g. because the port-forward it goes through died
+var ErrConnectionLost = errors.New("connection to Elasticsearch lost")
+
+// errorKinds maps the error types in Elasticsearch error responses to the errors returned for them
+var errorKinds = map[string]error{
+	"snapshot_missing_exception":   ErrSnapshotNotFound,
+	"repository_missing_exception": ErrRepositoryMissing,
+}
+
+// kindError is an error that also matches a sentinel error with errors.Is, without the sentinel in its message
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.err, e.kind}
+}
+
+// withKind returns err, which errors.Is also matches against kind
+func withKind(kind, err error) error {
+	return &kindError{err: err, kind: kind}
+}
+
+// maxErrorBodyLength is the length of a response body beyond which it is cut off in error messages. Error
+// responses listing the failures of many shards can be megabytes; their details are in ResponseError.
+const maxErrorBodyLength = 2048
+
+// Sources of shard failures, see ShardFailure
+const (
+	// ShardFailureRequest is a shard failure reported in the error response of a request
+	ShardFailureRequest = "request"
+	// ShardFailureRestore is a primary shard that could not be restored
+	ShardFailureRestore = "restore"
+	// ShardFailureSnapshot is a shard that failed when the snapshot was taken, so its data is not in the snapshot
+	ShardFailureSnapshot = "snapshot"
+)
+
+// ShardFailure is the failure of one shard of an index
+type ShardFailure struct {
+	Index  string `json:"index"`
+	Shard  int    `json:"shard"`
+	Node   string `json:"node,omitempty"`
+	Reason string `json:"reason"`
+	// Source is where the failure was found: ShardFailureRequest, ShardFailureRestore or ShardFailureSnapshot
+	Source string `json:"source"`
+}
+
+// ResponseError is an Elasticsearch error response, with the error type and reason and the shard failures it lists
+type ResponseError struct {
+	StatusCode    int
+	Type          string
+	Reason        string
+	ShardFailures []ShardFailure
+	// body is the response body, cut off at maxErrorBodyLength
+	body string
+}
+
+// Error returns the status and the body of the response
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("elasticsearch returned error: [%d %s] %s", e.StatusCode, http.StatusText(e.StatusCode), e.body)
+}
+
+// errorResponse is the body of an Elasticsearch error response
+type errorResponse struct {
+	Error struct {
+		Type         string `json:"type"`
+		Reason       string `json:"reason"`
+		FailedShards []struct {
+			Index  string      `json:"index"`
+			Shard  int         `json:"shard"`
+			Node   string      `json:"node"`
+			Reason errorReason `json:"reason"`
+		} `json:"failed_shards"`
+	} `json:"error"`
+}
+
+// errorReason is the cause of an error in an Elasticsearch response
+type errorReason struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (r errorReason) String() string {
+	if r.Type == "" {
+		return r.Reason
+	}
+	return r.Type + ": " + r.Reason
+}
+
+// responseError returns the error for an Elasticsearch error response, a *ResponseError. Its message has the
+// status and body of the response; errors of a known type, such as a missing snapshot, also match the
+// corresponding sentinel error.
+func responseError(res *esapi.Response) error {
+	body, _ := io.ReadAll(res.Body)
+	respErr := &ResponseError{StatusCode: res.StatusCode, body: strings.TrimSpace(string(body))}
+	if len(respErr.body) > maxErrorBodyLength {
+		respErr.body = fmt.Sprintf("%s... (%d bytes cut off)", respErr.body[:maxErrorBodyLength], len(respErr.body)-maxErrorBodyLength)
+	}
+
+	var errResp errorResponse
+	if json.Unmarshal(body, &errResp) != nil {
+		return respErr
+	}
+	respErr.Type, respErr.Reason = errResp.Error.Type, errResp.Error.Reason
+	for _, failed := range errResp.Error.FailedShards {
+		respErr.ShardFailures = append(respErr.ShardFailures, ShardFailure{
+			Index: failed.Index, Shard: failed.Shard, Node: failed.Node, Reason: failed.Reason.String(), Source: ShardFailureRequest,
+		})
+	}
+	if kind, ok := errorKinds[respErr.Type]; ok {
+		return withKind(kind, respErr)
+	}
+	return respErr
+}
+
+// ShardFailures returns the shard failures of the error responses and partial restores in the tree of err, e.g.
+// of all restores in an error joined with errors.Join
+func ShardFailures(err error) []ShardFailure {
+	switch e := err.(type) {
+	case *ResponseError:
+		return e.ShardFailures
+	case *PartialRestoreError:
+		return e.Failures
+	case interface{ Unwrap() error }:
+		return ShardFailures(e.Unwrap())
+	case interface{ Unwrap() []error }:
+		var failures []ShardFailure
+		for _, wrapped := range e.Unwrap() {
+			failures = append(failures, ShardFailures(wrapped)...)
+		}
+		return failures
+	}
+	return nil
+}
+
+// transportError returns err, the error of a request that got no response, matching ErrConnectionLost when the
+// connection was closed, reset or refused rather than failing for another reason
+func transportError(err error) error {
+	var opErr *net.OpError
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.As(err, &opErr) {
+		return withKind(ErrConnectionLost, err)
+	}
+	return err
+}
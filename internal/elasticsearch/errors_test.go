@@ -0,0 +1,186 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_GetSnapshot_ErrorKinds(t *testing.T) {
+	tests := []struct {
+		name            string
+		responseStatus  int
+		responseBody    string
+		expectedErr     error
+		expectedMessage string
+	}{
+		{
+			name:            "snapshot missing",
+			responseStatus:  http.StatusNotFound,
+			responseBody:    `{"error": {"type": "snapshot_missing_exception", "reason": "[sts-backup:snap-1] is missing"}, "status": 404}`,
+			expectedErr:     ErrSnapshotNotFound,
+			expectedMessage: `elasticsearch returned error: [404 Not Found] {"error": {"type": "snapshot_missing_exception"`,
+		},
+		{
+			name:            "empty response",
+			responseStatus:  http.StatusOK,
+			responseBody:    `{"snapshots": []}`,
+			expectedErr:     ErrSnapshotNotFound,
+			expectedMessage: "snapshot snap-1 not found",
+		},
+		{
+			name:            "repository missing",
+			responseStatus:  http.StatusNotFound,
+			responseBody:    `{"error": {"type": "repository_missing_exception", "reason": "[sts-backup] missing"}, "status": 404}`,
+			expectedErr:     ErrRepositoryMissing,
+			expectedMessage: "[404 Not Found]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.responseStatus)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL)
+			require.NoError(t, err)
+
+			_, err = client.GetSnapshot("sts-backup", "snap-1")
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.Contains(t, err.Error(), tt.expectedMessage)
+		})
+	}
+}
+
+func TestClient_ResponseError_UnknownType(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": {"type": "illegal_state_exception", "reason": "boom"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.DeleteSnapshot("sts-backup", "snap-1")
+
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+	assert.Equal(t, `elasticsearch returned error: [500 Internal Server Error] {"error": {"type": "illegal_state_exception", "reason": "boom"}}`, err.Error())
+}
+
+func TestClient_ResponseError_ShardFailures(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error": {"type": "snapshot_restore_exception", "reason": "restore failed", "failed_shards": [
+			{"index": "sts_topology", "shard": 2, "node": "es-1", "reason": {"type": "corrupt_index_exception", "reason": "checksum failed"}}
+		]}, "status": 500}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.RestoreSnapshot("sts-backup", "snap-1", "*", true)
+
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, http.StatusInternalServerError, respErr.StatusCode)
+	assert.Equal(t, "snapshot_restore_exception", respErr.Type)
+	assert.Equal(t, "restore failed", respErr.Reason)
+	assert.Equal(t, []ShardFailure{
+		{Index: "sts_topology", Shard: 2, Node: "es-1", Reason: "corrupt_index_exception: checksum failed", Source: ShardFailureRequest},
+	}, ShardFailures(err))
+}
+
+func TestClient_ResponseError_LargeBody(t *testing.T) {
+	body := `{"error": {"type": "illegal_state_exception", "reason": "` + strings.Repeat("x", 3*maxErrorBodyLength) + `"}}`
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.DeleteSnapshot("sts-backup", "snap-1")
+
+	require.Error(t, err)
+	assert.Less(t, len(err.Error()), maxErrorBodyLength+200)
+	assert.Contains(t, err.Error(), fmt.Sprintf("(%d bytes cut off)", len(body)-maxErrorBodyLength))
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, "illegal_state_exception", respErr.Type, "the type is parsed from the whole body")
+}
+
+func TestShardFailures_Joined(t *testing.T) {
+	restoreFailure := ShardFailure{Index: "sts_topology", Shard: 1, Source: ShardFailureRestore}
+	requestFailure := ShardFailure{Index: "sts_metrics", Shard: 0, Source: ShardFailureRequest}
+	err := errors.Join(
+		fmt.Errorf("restore incomplete: %w", &PartialRestoreError{Snapshot: "snap-1", Failures: []ShardFailure{restoreFailure}}),
+		withKind(ErrSnapshotNotFound, &ResponseError{ShardFailures: []ShardFailure{requestFailure}}),
+		errors.New("boom"),
+	)
+
+	assert.Equal(t, []ShardFailure{restoreFailure, requestFailure}, ShardFailures(err))
+	assert.Empty(t, ShardFailures(errors.New("boom")))
+}
+
+func TestClient_GetRepository_Missing(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": {"type": "repository_missing_exception"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	_, err = client.GetRepository("sts-backup")
+
+	assert.ErrorIs(t, err, ErrRepositoryMissing)
+	assert.NotErrorIs(t, err, ErrSnapshotNotFound)
+	assert.EqualError(t, err, "snapshot repository sts-backup not found")
+}
+
+func TestClient_RestoreSnapshot_ConnectionLost(t *testing.T) {
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// Close the connection without a response, like a port-forward that died
+		conn, _, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	err = client.RestoreSnapshotWithOptions("sts-backup", "snap-1", RestoreOptions{Indices: "sts_*", WaitForCompletion: true})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrConnectionLost)
+	assert.Contains(t, err.Error(), "failed to restore snapshot: ")
+	assert.NotContains(t, err.Error(), ErrConnectionLost.Error())
+}
+
+func TestTransportError(t *testing.T) {
+	assert.ErrorIs(t, transportError(io.EOF), ErrConnectionLost)
+	assert.ErrorIs(t, transportError(fmt.Errorf("read: %w", syscall.ECONNRESET)), ErrConnectionLost)
+	assert.ErrorIs(t, transportError(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), ErrConnectionLost)
+	assert.NotErrorIs(t, transportError(errors.New("invalid URL")), ErrConnectionLost)
+}
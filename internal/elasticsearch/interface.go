@@ -1,25 +1,82 @@
 package elasticsearch
 
+import "encoding/json"
+
 // Interface defines the contract for Elasticsearch client operations
 // This interface allows for easy mocking in tests
 type Interface interface {
 	// Snapshot operations
 	ListSnapshots(repository string) ([]Snapshot, error)
+	ListSnapshotsMatching(repository string, keep func(Snapshot) bool) ([]Snapshot, error)
+	ListSnapshotNames(repository string) ([]string, error)
 	GetSnapshot(repository, snapshotName string) (*Snapshot, error)
+	SnapshotSize(repository, snapshotName string) (int64, error)
+	SnapshotStats(repository string, snapshotNames []string) ([]SnapshotStats, error)
+	CreateSnapshot(repository, snapshotName string, indices []string, metadata map[string]string) (*Snapshot, error)
+	DeleteSnapshot(repository, snapshotName string) error
 	RestoreSnapshot(repository, snapshotName, indicesPattern string, waitForCompletion bool) error
+	RestoreSnapshotWithOptions(repository, snapshotName string, opts RestoreOptions) error
 
 	// Index operations
 	ListIndices(pattern string) ([]string, error)
 	ListIndicesDetailed() ([]IndexInfo, error)
 	DeleteIndex(index string) error
 	IndexExists(index string) (bool, error)
+	RefreshIndices(pattern string) error
+	ForceMergeIndices(pattern string, maxNumSegments int) error
+	IndexLifecyclePolicies(pattern string) (map[string]string, error)
+	ListRecoveries(activeOnly bool) ([]RecoveryInfo, error)
+	ListShards(pattern string) ([]ShardInfo, error)
+
+	// Alias operations
+	ListAliases(pattern string) ([]Alias, error)
+	UpdateAliases(actions []AliasAction) error
+
+	// Document operations
+	CountDocuments(index string) (int64, error)
+	SampleDocuments(index string, size int) (map[string]json.RawMessage, error)
+	GetDocuments(index string, ids []string) (map[string]json.RawMessage, error)
 
 	// Datastream operations
 	RolloverDatastream(datastreamName string) error
+	RolloverDatastreamWithOptions(datastreamName string, opts RolloverOptions) (*RolloverResult, error)
+
+	// Cluster operations
+	ClusterHealth() (*ClusterHealth, error)
+	Version() (string, error)
+	ListPlugins() ([]Plugin, error)
+	GetClusterSettings() (*ClusterSettings, error)
+	PutClusterSettings(persistent map[string]interface{}) error
+	ExplainAllocation(index string, shard int, primary bool) (*AllocationExplanation, error)
+
+	// Task operations
+	ListTasks(actions []string) ([]Task, error)
+	CancelTask(taskID string) error
+
+	// Reindex operations
+	StartReindex(source, dest string, requestsPerSecond int) (string, error)
+	GetReindexStatus(taskID string) (*ReindexStatus, error)
+
+	// Ingest pipeline operations
+	GetIngestPipelines() (map[string]json.RawMessage, error)
+	PutIngestPipeline(name string, definition json.RawMessage) error
+
+	// ILM operations
+	GetILMPolicies() (map[string]json.RawMessage, error)
+	PutILMPolicy(name string, policy json.RawMessage) error
 
 	// Repository and SLM operations
-	ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string) error
-	ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int) error
+	ConfigureSnapshotRepository(name, bucket, endpoint, basePath, accessKey, secretKey string, conn S3Connection, tuning RepositoryTuning) error
+	GetRepository(name string) (*Repository, error)
+	PutS3Repository(name string, settings map[string]interface{}) error
+	RegisterReadOnlyRepository(name, bucket, endpoint, basePath, accessKey, secretKey string, conn S3Connection, tuning RepositoryTuning) error
+	VerifyRepository(name string) (int, error)
+	ConfigureSLMPolicy(name, schedule, snapshotName, repository, indices, expireAfter string, minCount, maxCount int, featureStates []string) error
+	GetSLMPolicy(name string) (*SLMPolicy, error)
+	ExecuteSLMPolicy(name string) (string, error)
+	SLMStatus() (string, error)
+	ExecuteSLMRetention() error
+	SLMStats() (*SLMStats, error)
 }
 
 // Ensure *Client implements Interface
@@ -0,0 +1,28 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stackvista/stackstate-backup-cli/internal/proxy"
+)
+
+// WithProxy sends requests through proxyURL instead of the proxy from the environment (HTTP_PROXY, HTTPS_PROXY,
+// NO_PROXY), which is used when proxyURL is empty. Requests to localhost, i.e. through a port-forward, are never
+// proxied. It sets the base transport, so it must come before the options wrapping it.
+func WithProxy(proxyURL string) Option {
+	return func(cfg *elasticsearch.Config) {
+		if proxyURL == "" {
+			return
+		}
+		proxyFunc, err := proxy.Func(proxyURL)
+		if err != nil {
+			// Every request fails with the reason the proxy cannot be used, instead of bypassing it
+			proxyFunc = func(*http.Request) (*url.URL, error) { return nil, err }
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = proxyFunc
+		cfg.Transport = transport
+	}
+}
@@ -0,0 +1,38 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProxy(t *testing.T) {
+	var proxiedHost string
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the target
+		proxiedHost = r.URL.Host
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"snapshots": []}`))
+	}))
+	defer proxyServer.Close()
+
+	client, err := NewClient("http://elasticsearch.example.com:9200", WithProxy(proxyServer.URL), WithRateLimit(DefaultMaxRequestsPerSecond))
+	require.NoError(t, err)
+
+	_, err = client.ListSnapshots("backup")
+	require.NoError(t, err)
+	assert.Equal(t, "elasticsearch.example.com:9200", proxiedHost)
+}
+
+func TestWithProxy_InvalidURL(t *testing.T) {
+	client, err := NewClient("http://elasticsearch.example.com:9200", WithProxy("proxy:3128"))
+	require.NoError(t, err)
+
+	_, err = client.ListSnapshots("backup")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid proxy URL")
+}
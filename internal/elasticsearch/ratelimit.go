@@ -0,0 +1,42 @@
+package elasticsearch
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"golang.org/x/time/rate"
+)
+
+// DefaultMaxRequestsPerSecond is the default request rate, low enough for a small three-node cluster
+// reached through a single port-forward while bulk operations such as index deletion run in parallel
+const DefaultMaxRequestsPerSecond = 20
+
+// rateLimitTransport is an http.RoundTripper that delays requests to stay within a request rate
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// WithRateLimit limits the client to requestsPerSecond, allowing bursts of up to one second's worth of
+// requests. A rate of zero or less disables the limit.
+func WithRateLimit(requestsPerSecond float64) Option {
+	return func(cfg *elasticsearch.Config) {
+		if requestsPerSecond <= 0 {
+			return
+		}
+		burst := max(1, int(math.Ceil(requestsPerSecond)))
+		cfg.Transport = &rateLimitTransport{
+			next:    transportOrDefault(cfg.Transport),
+			limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithRateLimit(t *testing.T) {
+	server := mockESServer(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		requestsPerSecond float64
+		minDuration       time.Duration
+		maxDuration       time.Duration
+	}{
+		// A burst of 10 is allowed, the remaining 5 requests are spread over half a second
+		{name: "limited", requestsPerSecond: 10, minDuration: 400 * time.Millisecond, maxDuration: 2 * time.Second},
+		{name: "disabled", requestsPerSecond: 0, maxDuration: 400 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(server.URL, WithRateLimit(tt.requestsPerSecond))
+			require.NoError(t, err)
+
+			start := time.Now()
+			for range 15 {
+				require.NoError(t, client.DeleteIndex("sts_test"))
+			}
+			elapsed := time.Since(start)
+
+			assert.GreaterOrEqual(t, elapsed, tt.minDuration)
+			assert.Less(t, elapsed, tt.maxDuration)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/stackvista/stackstate-backup-cli/internal/readonly"
+)
+
+// readOnlyEndpoints are the endpoints that only read, although they are sent as POST when they have a body
+var readOnlyEndpoints = map[string]bool{
+	"_search":     true,
+	"_count":      true,
+	"_mget":       true,
+	"_msearch":    true,
+	"_field_caps": true,
+}
+
+// readOnlyPaths are the read-only POST endpoints that cannot be recognized by their last path element
+var readOnlyPaths = map[string]bool{
+	"/_cluster/allocation/explain": true,
+}
+
+// WithReadOnly refuses every request that could change the cluster, such as deleting indices, restoring snapshots or
+// putting repositories, with an error matching readonly.ErrReadOnly. It is a no-op unless enabled. It must come
+// after WithProxy, which sets the base transport.
+func WithReadOnly(enabled bool) Option {
+	return func(cfg *elasticsearch.Config) {
+		if !enabled {
+			return
+		}
+		cfg.Transport = &readonly.Transport{Next: transportOrDefault(cfg.Transport), Allow: readOnlyRequest}
+	}
+}
+
+// readOnlyRequest reports whether a POST request only reads
+func readOnlyRequest(req *http.Request) bool {
+	return req.Method == http.MethodPost && (readOnlyEndpoints[path.Base(req.URL.Path)] || readOnlyPaths[req.URL.Path])
+}
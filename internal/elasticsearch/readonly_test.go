@@ -0,0 +1,51 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/readonly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithReadOnly(t *testing.T) {
+	var received []string
+	server := mockESServer(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"count": 3, "acknowledged": true}`))
+	})
+	defer server.Close()
+
+	client, err := NewClient(server.URL, WithReadOnly(true))
+	require.NoError(t, err)
+
+	count, err := client.CountDocuments("sts_topology")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	err = client.DeleteIndex("sts_topology")
+	assert.ErrorIs(t, err, readonly.ErrReadOnly)
+	err = client.PutS3Repository("sts-backup", map[string]interface{}{"bucket": "sts-backup"})
+	assert.ErrorIs(t, err, readonly.ErrReadOnly)
+	assert.Len(t, received, 1, "refused requests never reach Elasticsearch")
+
+	client, err = NewClient(server.URL, WithReadOnly(false))
+	require.NoError(t, err)
+	require.NoError(t, client.DeleteIndex("sts_topology"))
+}
+
+func TestReadOnlyRequest(t *testing.T) {
+	post := func(path string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "http://localhost:9200"+path, nil)
+		return req
+	}
+
+	assert.True(t, readOnlyRequest(post("/sts_topology/_search")))
+	assert.True(t, readOnlyRequest(post("/_mget")))
+	assert.True(t, readOnlyRequest(post("/_cluster/allocation/explain")))
+	assert.False(t, readOnlyRequest(post("/_snapshot/sts-backup/snap-1/_restore")))
+	assert.False(t, readOnlyRequest(post("/_snapshot/sts-backup/_verify")))
+	assert.False(t, readOnlyRequest(post("/_tasks/node:1/_cancel")))
+}
@@ -0,0 +1,64 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// decodeArrayField streams the elements of the array in field of the JSON object read from r, calling decode
+// for every element with the decoder positioned at it. Only one element is held in memory at a time, so responses
+// listing hundreds of snapshots, with all their indices and shards, are decoded in flat memory. The other fields
+// of the object are skipped.
+func decodeArrayField(r io.Reader, field string, decode func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := token.(string); key != field {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The field may be null, e.g. when there is nothing to list
+		token, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("expected an array in field %s, got %v", field, token)
+		}
+		for dec.More() {
+			if err := decode(dec); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// expectDelim reads the next token, which must be delim
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeArrayField(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name:     "skips other fields",
+			body:     `{"total": 2, "shards": {"snapshots": ["nested"]}, "snapshots": [{"snapshot": "a"}, {"snapshot": "b"}], "remaining": 0}`,
+			expected: []string{"a", "b"},
+		},
+		{name: "empty array", body: `{"snapshots": []}`},
+		{name: "null", body: `{"snapshots": null}`},
+		{name: "missing field", body: `{"total": 0}`},
+		{name: "not an object", body: `[]`, wantErr: true},
+		{name: "not an array", body: `{"snapshots": {"snapshot": "a"}}`, wantErr: true},
+		{name: "truncated", body: `{"snapshots": [{"snapshot": "a"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			err := decodeArrayField(strings.NewReader(tt.body), "snapshots", func(dec *json.Decoder) error {
+				var snapshot Snapshot
+				if err := dec.Decode(&snapshot); err != nil {
+					return err
+				}
+				names = append(names, snapshot.Snapshot)
+				return nil
+			})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestClient_ListSnapshotsMatching(t *testing.T) {
+	var body strings.Builder
+	body.WriteString(`{"snapshots": [`)
+	for i := range 500 {
+		if i > 0 {
+			body.WriteString(",")
+		}
+		state := "SUCCESS"
+		if i%100 == 0 {
+			state = "FAILED"
+		}
+		fmt.Fprintf(&body, `{"snapshot": "snap-%03d", "state": %q, "indices": ["sts_topology", "sts_metrics"]}`, i, state)
+	}
+	body.WriteString(`], "total": 500, "remaining": 0}`)
+
+	server := mockESServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body.String()))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL)
+	require.NoError(t, err)
+
+	failed, err := client.ListSnapshotsMatching("sts-backup", func(s Snapshot) bool { return s.State == "FAILED" })
+	require.NoError(t, err)
+	require.Len(t, failed, 5)
+	assert.Equal(t, "snap-100", failed[1].Snapshot)
+	assert.Equal(t, []string{"sts_topology", "sts_metrics"}, failed[1].Indices)
+
+	all, err := client.ListSnapshots("sts-backup")
+	require.NoError(t, err)
+	assert.Len(t, all, 500)
+}
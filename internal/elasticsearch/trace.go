@@ -0,0 +1,43 @@
+package elasticsearch
+
+import (
+	"net/http"
+	"net/http/httputil"
+)
+
+// traceTransport is an http.RoundTripper that dumps requests and responses, including bodies
+type traceTransport struct {
+	next   http.RoundTripper
+	tracef func(format string, args ...interface{})
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		t.tracef("HTTP request:\n%s", dump)
+	} else {
+		t.tracef("HTTP request %s %s (dump failed: %v)", req.Method, req.URL, err)
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		t.tracef("HTTP request %s %s failed: %v", req.Method, req.URL, err)
+		return nil, err
+	}
+
+	if dump, err := httputil.DumpResponse(res, true); err == nil {
+		t.tracef("HTTP response:\n%s", dump)
+	} else {
+		t.tracef("HTTP response %s (dump failed: %v)", res.Status, err)
+	}
+
+	return res, nil
+}
+
+// transportOrDefault returns rt, or http.DefaultTransport when rt is nil
+func transportOrDefault(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
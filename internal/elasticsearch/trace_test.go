@@ -0,0 +1,39 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_WithTrace(t *testing.T) {
+	server := mockESServer(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"snapshots": [{"snapshot": "snap-1"}]}`))
+	})
+	defer server.Close()
+
+	var traces []string
+	tracef := func(format string, args ...interface{}) {
+		traces = append(traces, fmt.Sprintf(format, args...))
+	}
+
+	client, err := NewClient(server.URL, WithTrace(tracef))
+	require.NoError(t, err)
+
+	snapshots, err := client.ListSnapshots("test-repo")
+	require.NoError(t, err)
+
+	// Response body must still be readable after being dumped
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "snap-1", snapshots[0].Snapshot)
+
+	output := strings.Join(traces, "\n")
+	assert.Contains(t, output, "GET /_snapshot/test-repo/_all")
+	assert.Contains(t, output, "HTTP/1.1 200 OK")
+	assert.Contains(t, output, `"snapshot": "snap-1"`)
+}
@@ -0,0 +1,122 @@
+// Package events writes structured progress events of long-running commands, one JSON object per line, so
+// orchestration systems can follow a run in real time instead of parsing the log lines on stderr.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// FormatNDJSON writes every event as a JSON object on its own line
+const FormatNDJSON = "ndjson"
+
+// Event types
+const (
+	// TypePhaseStarted and TypePhaseCompleted mark the start and end of a phase, such as deleting the indices
+	TypePhaseStarted   = "phase_started"
+	TypePhaseCompleted = "phase_completed"
+	// TypeIndexDeleted is emitted for every index deleted
+	TypeIndexDeleted = "index_deleted"
+	// TypeRestoreProgress reports the share of the shards of a snapshot that have been restored
+	TypeRestoreProgress = "restore_progress"
+	// TypeFinished is the last event of a run, with the error and exit code when it failed
+	TypeFinished = "finished"
+)
+
+// Event is a progress event. Only the fields of its type are set.
+type Event struct {
+	Time           time.Time `json:"time"`
+	RunID          string    `json:"runId,omitempty"`
+	Type           string    `json:"type"`
+	Phase          string    `json:"phase,omitempty"`
+	DurationMillis int64     `json:"durationMillis,omitempty"`
+	Index          string    `json:"index,omitempty"`
+	Snapshot       string    `json:"snapshot,omitempty"`
+	// Percent is the share of the shards of Snapshot restored; a pointer so that 0% is not left out
+	Percent     *int   `json:"percent,omitempty"`
+	ShardsDone  int    `json:"shardsDone,omitempty"`
+	ShardsTotal int    `json:"shardsTotal,omitempty"`
+	Success     *bool  `json:"success,omitempty"`
+	ExitCode    int    `json:"exitCode,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Emitter writes events. A nil Emitter, for runs without --events, discards them. It is safe for concurrent use.
+type Emitter struct {
+	mu    sync.Mutex
+	out   io.Writer
+	runID string
+	now   func() time.Time
+}
+
+// New returns an Emitter writing events in format to out, or nil when format is empty. An unknown format is a
+// usage error.
+func New(format string, out io.Writer, runID string) (*Emitter, error) {
+	switch format {
+	case "":
+		return nil, nil
+	case FormatNDJSON:
+		return &Emitter{out: out, runID: runID, now: time.Now}, nil
+	default:
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("unsupported events format '%s': use %s", format, FormatNDJSON))
+	}
+}
+
+// Emit writes event, stamped with the time and run ID, with secrets masked. Events that cannot be written are
+// dropped, so a closed stdout does not fail the command.
+func (e *Emitter) Emit(event Event) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event.Time = e.now().UTC()
+	event.RunID = e.runID
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	_, _ = io.WriteString(e.out, redact.String(string(data))+"\n")
+}
+
+// PhaseStarted emits the start of phase
+func (e *Emitter) PhaseStarted(phase string) {
+	e.Emit(Event{Type: TypePhaseStarted, Phase: phase})
+}
+
+// PhaseCompleted emits the end of phase, which took duration
+func (e *Emitter) PhaseCompleted(phase string, duration time.Duration) {
+	e.Emit(Event{Type: TypePhaseCompleted, Phase: phase, DurationMillis: duration.Milliseconds()})
+}
+
+// IndexDeleted emits the deletion of index
+func (e *Emitter) IndexDeleted(index string) {
+	e.Emit(Event{Type: TypeIndexDeleted, Index: index})
+}
+
+// RestoreProgress emits that done of the total shards of snapshot have been restored
+func (e *Emitter) RestoreProgress(snapshot string, done, total int) {
+	percent := 0
+	if total > 0 {
+		percent = done * 100 / total
+	}
+	e.Emit(Event{Type: TypeRestoreProgress, Snapshot: snapshot, Percent: &percent, ShardsDone: done, ShardsTotal: total})
+}
+
+// Finished emits the end of the run, which failed with err unless it is nil
+func (e *Emitter) Finished(err error) {
+	success := err == nil
+	event := Event{Type: TypeFinished, Success: &success}
+	if err != nil {
+		event.Error = err.Error()
+		event.ExitCode = exitcode.Of(err)
+	}
+	e.Emit(event)
+}
@@ -0,0 +1,81 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEmitter(t *testing.T) (*Emitter, *bytes.Buffer) {
+	t.Helper()
+	var out bytes.Buffer
+	emitter, err := New(FormatNDJSON, &out, "run-1")
+	require.NoError(t, err)
+	emitter.now = func() time.Time { return time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC) }
+	return emitter, &out
+}
+
+func TestNew(t *testing.T) {
+	emitter, err := New("", &bytes.Buffer{}, "run-1")
+	require.NoError(t, err)
+	assert.Nil(t, emitter)
+
+	_, err = New("json", &bytes.Buffer{}, "run-1")
+	require.Error(t, err)
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
+
+func TestEmitter_NDJSON(t *testing.T) {
+	emitter, out := newTestEmitter(t)
+
+	emitter.PhaseStarted("Delete indices")
+	emitter.IndexDeleted("sts_topology")
+	emitter.PhaseCompleted("Delete indices", 1500*time.Millisecond)
+	emitter.RestoreProgress("sts-backup/snap-1", 0, 40)
+	emitter.RestoreProgress("sts-backup/snap-1", 10, 40)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, `{"time":"2025-01-15T03:00:00Z","runId":"run-1","type":"phase_started","phase":"Delete indices"}`, lines[0])
+	assert.Equal(t, `{"time":"2025-01-15T03:00:00Z","runId":"run-1","type":"index_deleted","index":"sts_topology"}`, lines[1])
+	assert.Equal(t, `{"time":"2025-01-15T03:00:00Z","runId":"run-1","type":"phase_completed","phase":"Delete indices","durationMillis":1500}`, lines[2])
+	assert.Contains(t, lines[3], `"percent":0,"shardsTotal":40`)
+	assert.Contains(t, lines[4], `"percent":25,"shardsDone":10,"shardsTotal":40`)
+}
+
+func TestEmitter_Finished(t *testing.T) {
+	emitter, out := newTestEmitter(t)
+	redact.Register("s3cr3t")
+
+	emitter.Finished(nil)
+	emitter.Finished(exitcode.Wrap(exitcode.PartialRestore, fmt.Errorf("restore incomplete: key s3cr3t")))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	var success, failure Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &success))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &failure))
+	assert.Equal(t, TypeFinished, success.Type)
+	assert.True(t, *success.Success)
+	assert.False(t, *failure.Success)
+	assert.Equal(t, exitcode.PartialRestore, failure.ExitCode)
+	assert.NotContains(t, failure.Error, "s3cr3t")
+}
+
+func TestEmitter_Nil(t *testing.T) {
+	var emitter *Emitter
+
+	assert.NotPanics(t, func() {
+		emitter.PhaseStarted("Restore snapshot")
+		emitter.Finished(errors.New("boom"))
+	})
+}
@@ -0,0 +1,62 @@
+// Package exitcode defines the process exit codes of the CLI so wrapping
+// automation can branch on the kind of failure instead of parsing messages.
+package exitcode
+
+import "errors"
+
+// Exit codes returned by the CLI
+const (
+	// Success means the command completed successfully
+	Success = 0
+	// Failure is a generic or unexpected failure
+	Failure = 1
+	// Usage means invalid flags or arguments were given
+	Usage = 2
+	// ConfigError means the configuration could not be loaded or is invalid
+	ConfigError = 3
+	// ConnectivityError means the Kubernetes API, port-forward or a service could not be reached
+	ConnectivityError = 4
+	// ValidationFailed means a check or verification did not pass
+	ValidationFailed = 5
+	// PartialRestore means a restore completed but some shards failed
+	PartialRestore = 6
+	// Cancelled means the operation was cancelled by the user
+	Cancelled = 7
+)
+
+// Error is an error carrying the exit code the process should terminate with
+type Error struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap attaches an exit code to err. A nil err returns nil.
+func Wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of returns the exit code for err: Success for nil, the code of the outermost
+// Error in the chain, or Failure for errors without a code.
+func Of(err error) int {
+	if err == nil {
+		return Success
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return Failure
+}
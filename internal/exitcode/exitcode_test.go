@@ -0,0 +1,43 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap_Nil(t *testing.T) {
+	assert.NoError(t, Wrap(ConfigError, nil))
+}
+
+func TestOf(t *testing.T) {
+	base := errors.New("boom")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{name: "nil error", err: nil, expected: Success},
+		{name: "plain error", err: base, expected: Failure},
+		{name: "coded error", err: Wrap(ConfigError, base), expected: ConfigError},
+		{name: "wrapped coded error", err: fmt.Errorf("context: %w", Wrap(ConnectivityError, base)), expected: ConnectivityError},
+		{name: "outermost code wins", err: Wrap(Cancelled, Wrap(ConfigError, base)), expected: Cancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Of(tt.err))
+		})
+	}
+}
+
+func TestError_PreservesChain(t *testing.T) {
+	base := errors.New("boom")
+	err := Wrap(ValidationFailed, base)
+
+	assert.Equal(t, "boom", err.Error())
+	assert.ErrorIs(t, err, base)
+}
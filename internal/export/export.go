@@ -0,0 +1,159 @@
+// Package export stores configuration exported from the datastores, such as Elasticsearch ingest pipelines,
+// as JSON artifacts in the backup bucket. This configuration is not part of snapshots and has to be
+// re-imported after a restore. Artifacts are encrypted when archive encryption is configured.
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/archive"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+)
+
+const (
+	// Prefix is the key prefix under which artifacts are stored in the bucket
+	Prefix = "exports/"
+
+	// KindPipelines identifies exports of Elasticsearch ingest pipelines
+	KindPipelines = "elasticsearch/pipelines"
+	// KindClusterSettings identifies exports of Elasticsearch persistent and transient cluster settings
+	KindClusterSettings = "elasticsearch/cluster-settings"
+	// KindILMPolicies identifies exports of the Elasticsearch ILM policies used by the STS indices
+	KindILMPolicies = "elasticsearch/ilm-policies"
+	// KindKafkaMetadata identifies snapshots of the Kafka topics, ACLs and cluster identity
+	KindKafkaMetadata = "kafka/metadata"
+
+	// keyTimeFormat makes artifact keys of one kind sort chronologically
+	keyTimeFormat = "20060102T150405Z"
+
+	artifactExtension = ".json"
+)
+
+// ErrNotFound is returned when no artifact of a kind exists
+var ErrNotFound = errors.New("no export found")
+
+// ObjectStore is the subset of the S3 client used to store artifacts
+type ObjectStore interface {
+	PutObject(bucket, key string, body []byte) error
+	GetObject(bucket, key string) ([]byte, error)
+	ListObjects(bucket, prefix string) ([]s3.Object, error)
+}
+
+// Artifact is the envelope of an exported configuration
+type Artifact struct {
+	Kind       string          `json:"kind"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Namespace  string          `json:"namespace,omitempty"`
+	RunID      string          `json:"runId,omitempty"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Store reads and writes artifacts in a bucket
+type Store struct {
+	objects ObjectStore
+	bucket  string
+	sealer  *archive.Sealer
+}
+
+// New creates a store in bucket that seals artifacts with sealer
+func New(objects ObjectStore, bucket string, sealer *archive.Sealer) *Store {
+	return &Store{objects: objects, bucket: bucket, sealer: sealer}
+}
+
+// Key returns the object key of an artifact of kind exported at exportedAt
+func Key(kind string, exportedAt time.Time) string {
+	return Prefix + path.Join(kind, exportedAt.UTC().Format(keyTimeFormat)) + artifactExtension
+}
+
+// ParseKey returns the kind and export time of the artifact stored under key
+func ParseKey(key string) (string, time.Time, bool) {
+	rest, found := strings.CutPrefix(key, Prefix)
+	if !found {
+		return "", time.Time{}, false
+	}
+	rest, found = strings.CutSuffix(rest, artifactExtension)
+	if !found {
+		return "", time.Time{}, false
+	}
+	kind, timestamp := path.Split(rest)
+	exportedAt, err := time.Parse(keyTimeFormat, timestamp)
+	if err != nil || kind == "" {
+		return "", time.Time{}, false
+	}
+	return strings.TrimSuffix(kind, "/"), exportedAt, true
+}
+
+// Put writes an artifact and returns its key
+func (s *Store) Put(artifact *Artifact) (string, error) {
+	data, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode export: %w", err)
+	}
+	sealed, err := s.sealer.Seal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt export: %w", err)
+	}
+
+	key := Key(artifact.Kind, artifact.ExportedAt)
+	if err := s.objects.PutObject(s.bucket, key, sealed); err != nil {
+		return "", fmt.Errorf("failed to write export %s: %w", key, err)
+	}
+	return key, nil
+}
+
+// Get reads the artifact stored under key
+func (s *Store) Get(key string) (*Artifact, error) {
+	data, err := s.objects.GetObject(s.bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export %s: %w", key, err)
+	}
+	data, err = s.sealer.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt export %s: %w", key, err)
+	}
+
+	var artifact Artifact
+	if err := json.Unmarshal(data, &artifact); err != nil {
+		return nil, fmt.Errorf("failed to decode export %s: %w", key, err)
+	}
+	return &artifact, nil
+}
+
+// Keys returns the keys of all artifacts of kind, newest first
+func (s *Store) Keys(kind string) ([]string, error) {
+	objects, err := s.objects.ListObjects(s.bucket, Prefix+kind+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		if strings.HasSuffix(object.Key, artifactExtension) {
+			keys = append(keys, object.Key)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	return keys, nil
+}
+
+// Latest returns the most recent artifact of kind, or ErrNotFound
+func (s *Store) Latest(kind string) (string, *Artifact, error) {
+	keys, err := s.Keys(kind)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("%w for %s", ErrNotFound, kind)
+	}
+	artifact, err := s.Get(keys[0])
+	if err != nil {
+		return "", nil, err
+	}
+	return keys[0], artifact, nil
+}
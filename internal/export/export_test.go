@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/archive"
+	"github.com/stackvista/stackstate-backup-cli/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory ObjectStore
+type memoryStore struct {
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (m *memoryStore) PutObject(bucket, key string, body []byte) error {
+	m.objects[bucket+"/"+key] = body
+	return nil
+}
+
+func (m *memoryStore) GetObject(bucket, key string) ([]byte, error) {
+	data, ok := m.objects[bucket+"/"+key]
+	if !ok {
+		return nil, &s3.Error{StatusCode: 404, Code: "NoSuchKey"}
+	}
+	return data, nil
+}
+
+func (m *memoryStore) ListObjects(bucket, prefix string) ([]s3.Object, error) {
+	var objects []s3.Object
+	for key := range m.objects {
+		if name, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(name, prefix) {
+			objects = append(objects, s3.Object{Key: name})
+		}
+	}
+	return objects, nil
+}
+
+func pipelinesArtifact(exportedAt time.Time, pipelines string) *Artifact {
+	return &Artifact{Kind: KindPipelines, ExportedAt: exportedAt, Namespace: "test-ns", Data: json.RawMessage(pipelines)}
+}
+
+func TestKey(t *testing.T) {
+	exportedAt := time.Date(2025, 1, 15, 3, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	assert.Equal(t, "exports/elasticsearch/pipelines/20250115T020000Z.json", Key(KindPipelines, exportedAt))
+}
+
+func TestParseKey(t *testing.T) {
+	kind, exportedAt, ok := ParseKey("exports/kafka/metadata/20250115T020000Z.json")
+	assert.True(t, ok)
+	assert.Equal(t, KindKafkaMetadata, kind)
+	assert.Equal(t, time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC), exportedAt)
+
+	for _, invalid := range []string{"dumps/postgres/settings/20250115T020000Z.sql.gz", "exports/kafka/metadata/latest.json", "exports/20250115T020000Z.json"} {
+		_, _, ok := ParseKey(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestStore_PutGet(t *testing.T) {
+	objects := newMemoryStore()
+	store := New(objects, "backups", archive.NewSealer(nil))
+	exportedAt := time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)
+
+	key, err := store.Put(pipelinesArtifact(exportedAt, `{"sts-logs":{"processors":[]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, Key(KindPipelines, exportedAt), key)
+
+	artifact, err := store.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, KindPipelines, artifact.Kind)
+	assert.Equal(t, "test-ns", artifact.Namespace)
+	assert.JSONEq(t, `{"sts-logs":{"processors":[]}}`, string(artifact.Data))
+
+	_, err = store.Get("exports/missing.json")
+	assert.Error(t, err)
+}
+
+func TestStore_Encrypted(t *testing.T) {
+	objects := newMemoryStore()
+	key := bytes.Repeat([]byte{0x42}, archive.KeySize)
+	store := New(objects, "backups", archive.NewSealer(key))
+
+	objectKey, err := store.Put(pipelinesArtifact(time.Now(), `{"sts-logs":{}}`))
+	require.NoError(t, err)
+
+	stored := objects.objects["backups/"+objectKey]
+	assert.True(t, archive.IsEncrypted(stored))
+	assert.NotContains(t, string(stored), "sts-logs")
+
+	artifact, err := store.Get(objectKey)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sts-logs":{}}`, string(artifact.Data))
+
+	// Without the key the artifact cannot be read
+	_, err = New(objects, "backups", archive.NewSealer(nil)).Get(objectKey)
+	assert.ErrorIs(t, err, archive.ErrNoKey)
+}
+
+func TestStore_Latest(t *testing.T) {
+	objects := newMemoryStore()
+	store := New(objects, "backups", archive.NewSealer(nil))
+
+	_, _, err := store.Latest(KindPipelines)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	older := time.Date(2025, 1, 14, 3, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)
+	_, err = store.Put(pipelinesArtifact(newer, `{"new":{}}`))
+	require.NoError(t, err)
+	_, err = store.Put(pipelinesArtifact(older, `{"old":{}}`))
+	require.NoError(t, err)
+	// Artifacts of other kinds are ignored
+	_, err = store.Put(&Artifact{Kind: "elasticsearch/other", ExportedAt: newer.Add(time.Hour), Data: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+
+	keys, err := store.Keys(KindPipelines)
+	require.NoError(t, err)
+	assert.Equal(t, []string{Key(KindPipelines, newer), Key(KindPipelines, older)}, keys)
+
+	key, artifact, err := store.Latest(KindPipelines)
+	require.NoError(t, err)
+	assert.Equal(t, Key(KindPipelines, newer), key)
+	assert.JSONEq(t, `{"new":{}}`, string(artifact.Data))
+}
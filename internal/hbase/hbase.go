@@ -0,0 +1,253 @@
+// Package hbase takes, exports and restores HBase table snapshots of StackGraph. The HBase shell and the
+// ExportSnapshot tool are run in an HBase pod, so no HBase client or network access to the cluster is needed.
+// Snapshots of the tables taken together form a set, identified by the time it was taken, that is exported to and
+// restored from an S3 bucket as a whole.
+package hbase
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// snapshotPrefix starts the names of the snapshots taken by the CLI
+	snapshotPrefix = "sts-backup-"
+	// setIDFormat makes set IDs sort chronologically
+	setIDFormat = "20060102T150405Z"
+
+	exportSnapshotClass = "org.apache.hadoop.hbase.snapshot.ExportSnapshot"
+	confToolClass       = "org.apache.hadoop.hbase.util.HBaseConfTool"
+
+	// snapshotInfoDir and snapshotInfoFile locate the description of a snapshot under the root directory
+	snapshotInfoDir  = ".hbase-snapshot"
+	snapshotInfoFile = ".snapshotinfo"
+
+	// tablePrefix marks the table names printed by the script of Tables
+	tablePrefix = "table\t"
+)
+
+// snapshotNamePattern matches the names of the snapshots taken by the CLI, capturing the set ID
+var snapshotNamePattern = regexp.MustCompile(`^` + snapshotPrefix + `(\d{8}T\d{6}Z)-`)
+
+// Runner runs a command with the HBase tools, streaming stdin to it when it is not nil and what it writes to
+// stdout to stdout
+type Runner interface {
+	Stream(command []string, stdin io.Reader, stdout io.Writer) error
+}
+
+// Snapshot is a snapshot of a table
+type Snapshot struct {
+	Name  string `json:"name"`
+	Table string `json:"table"`
+}
+
+// S3Location is a location in a bucket, as reached from the HBase pod, that snapshots are exported to
+type S3Location struct {
+	Endpoint  string // URL of the S3 endpoint
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+}
+
+// URI returns the Hadoop S3A URI of the location
+func (l S3Location) URI() string {
+	return "s3a://" + strings.TrimSuffix(l.Bucket+"/"+strings.Trim(l.Prefix, "/"), "/")
+}
+
+// SnapshotInfoPrefix returns the key prefix under which the snapshots exported to the location are described
+func (l S3Location) SnapshotInfoPrefix() string {
+	prefix := strings.Trim(l.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return prefix + snapshotInfoDir + "/"
+}
+
+// ExportedSnapshot returns the name of the snapshot described by an object under SnapshotInfoPrefix. Snapshots
+// still being exported are not described yet.
+func (l S3Location) ExportedSnapshot(key string) (string, bool) {
+	name, file, found := strings.Cut(strings.TrimPrefix(key, l.SnapshotInfoPrefix()), "/")
+	if !found || file != snapshotInfoFile || !snapshotNamePattern.MatchString(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// options returns the Hadoop options reaching the location with path-style requests. The credentials are not part of
+// them, they are passed in the environment (see Client.exportSnapshot).
+func (l S3Location) options() []string {
+	return []string{
+		"-Dfs.s3a.endpoint=" + l.Endpoint,
+		"-Dfs.s3a.endpoint.region=" + l.Region,
+		"-Dfs.s3a.path.style.access=true",
+		fmt.Sprintf("-Dfs.s3a.connection.ssl.enabled=%t", strings.HasPrefix(l.Endpoint, "https://")),
+	}
+}
+
+// Client runs the HBase tools
+type Client struct {
+	runner Runner
+}
+
+// NewClient creates a client running the HBase tools with runner
+func NewClient(runner Runner) *Client {
+	return &Client{runner: runner}
+}
+
+// NewSetID returns the ID of a set of snapshots taken at takenAt
+func NewSetID(takenAt time.Time) string {
+	return takenAt.UTC().Format(setIDFormat)
+}
+
+// SetTime returns the time the set with ID setID was taken at
+func SetTime(setID string) (time.Time, bool) {
+	takenAt, err := time.Parse(setIDFormat, setID)
+	return takenAt, err == nil
+}
+
+// SnapshotName returns the name of the snapshot of table in a set. The namespace separator, which snapshot names
+// cannot hold, is replaced.
+func SnapshotName(setID, table string) string {
+	return snapshotPrefix + setID + "-" + strings.ReplaceAll(table, ":", "_")
+}
+
+// SetID returns the ID of the set a snapshot taken by the CLI belongs to
+func SetID(snapshotName string) (string, bool) {
+	match := snapshotNamePattern.FindStringSubmatch(snapshotName)
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// Tables returns the tables outside the hbase system namespace
+func (c *Client) Tables() ([]string, error) {
+	out, err := c.shell(fmt.Sprintf(`list.each { |t| puts %s + t }`, quote(tablePrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []string
+	for _, line := range strings.Split(out, "\n") {
+		table, found := strings.CutPrefix(line, tablePrefix)
+		if found && !strings.HasPrefix(table, "hbase:") {
+			tables = append(tables, strings.TrimSpace(table))
+		}
+	}
+	slices.Sort(tables)
+	return tables, nil
+}
+
+// Snapshots returns the snapshots taken by the CLI in the cluster
+func (c *Client) Snapshots() ([]Snapshot, error) {
+	out, err := c.shell(fmt.Sprintf("list_snapshots %s", quote(snapshotPrefix+".*")))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	return parseSnapshots(out), nil
+}
+
+// parseSnapshots parses the rows of list_snapshots, e.g. " <name>   <table> (<creation time>)"
+func parseSnapshots(out string) []Snapshot {
+	var snapshots []Snapshot
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !snapshotNamePattern.MatchString(fields[0]) {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Name: fields[0], Table: fields[1]})
+	}
+	return snapshots
+}
+
+// CreateSnapshots takes the snapshots, stopping at the first that fails
+func (c *Client) CreateSnapshots(snapshots []Snapshot) error {
+	var script strings.Builder
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(&script, "snapshot %s, %s\n", quote(snapshot.Table), quote(snapshot.Name))
+	}
+	if _, err := c.shell(script.String()); err != nil {
+		return fmt.Errorf("failed to take snapshots: %w", err)
+	}
+	return nil
+}
+
+// RestoreSnapshots replaces the tables with the contents of their snapshots. Existing tables are disabled for the
+// restore, missing tables are created. The tables are enabled afterwards.
+func (c *Client) RestoreSnapshots(snapshots []Snapshot) error {
+	var script strings.Builder
+	for _, snapshot := range snapshots {
+		table := quote(snapshot.Table)
+		fmt.Fprintf(&script, "disable %s if exists(%s) && is_enabled(%s)\n", table, table, table)
+		fmt.Fprintf(&script, "restore_snapshot %s\n", quote(snapshot.Name))
+		fmt.Fprintf(&script, "enable %s unless is_enabled(%s)\n", table, table)
+	}
+	if _, err := c.shell(script.String()); err != nil {
+		return fmt.Errorf("failed to restore snapshots: %w", err)
+	}
+	return nil
+}
+
+// RootDir returns the HBase root directory, e.g. hdfs://hdfs-nn:9000/hbase
+func (c *Client) RootDir() (string, error) {
+	var stdout bytes.Buffer
+	if err := c.runner.Stream([]string{"hbase", confToolClass, "hbase.rootdir"}, nil, &stdout); err != nil {
+		return "", fmt.Errorf("failed to read hbase.rootdir: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	rootDir := strings.TrimSpace(lines[len(lines)-1])
+	if rootDir == "" {
+		return "", fmt.Errorf("failed to read hbase.rootdir: no value")
+	}
+	return rootDir, nil
+}
+
+// ExportSnapshot copies a snapshot and the files it references from the cluster to the location with mappers
+// parallel copies
+func (c *Client) ExportSnapshot(name string, to S3Location, mappers int) error {
+	args := append(to.options(), "-snapshot", name, "-copy-to", to.URI(), "-mappers", strconv.Itoa(mappers))
+	if err := c.exportSnapshot(args, to); err != nil {
+		return fmt.Errorf("failed to export snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// ImportSnapshot copies a snapshot exported to the location back into the cluster with root directory rootDir
+func (c *Client) ImportSnapshot(name string, from S3Location, rootDir string, mappers int) error {
+	args := append(from.options(), "-snapshot", name, "-copy-from", from.URI(), "-copy-to", rootDir, "-mappers", strconv.Itoa(mappers))
+	if err := c.exportSnapshot(args, from); err != nil {
+		return fmt.Errorf("failed to import snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// exportSnapshot runs ExportSnapshot. The credentials of the location are read from stdin into the environment, where
+// S3A picks them up, so they never appear in a command line.
+func (c *Client) exportSnapshot(args []string, location S3Location) error {
+	const script = `read -r AWS_ACCESS_KEY_ID && read -r AWS_SECRET_ACCESS_KEY && export AWS_ACCESS_KEY_ID AWS_SECRET_ACCESS_KEY && exec "$0" "$@"`
+	command := append([]string{"sh", "-c", script, "hbase", exportSnapshotClass}, args...)
+	credentials := strings.NewReader(location.AccessKey + "\n" + location.SecretKey + "\n")
+	return c.runner.Stream(command, credentials, io.Discard)
+}
+
+// shell runs a script with the HBase shell, which exits at the first failing command
+func (c *Client) shell(script string) (string, error) {
+	var stdout bytes.Buffer
+	if err := c.runner.Stream([]string{"hbase", "shell", "-n"}, strings.NewReader(script), &stdout); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// quote quotes a Ruby string literal
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,147 @@
+package hbase
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner returns canned output per command, records the commands it ran and what was streamed to them
+type fakeRunner struct {
+	outputs  map[string]string
+	errs     map[string]error
+	commands [][]string
+	stdins   []string
+}
+
+func (f *fakeRunner) Stream(command []string, stdin io.Reader, stdout io.Writer) error {
+	f.commands = append(f.commands, command)
+	input := ""
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		input = string(data)
+	}
+	f.stdins = append(f.stdins, input)
+
+	name := command[0]
+	if len(command) > 1 {
+		name += " " + command[1]
+	}
+	if _, err := io.WriteString(stdout, f.outputs[name]); err != nil {
+		return err
+	}
+	return f.errs[name]
+}
+
+func TestSnapshotNames(t *testing.T) {
+	setID := NewSetID(time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600)))
+	assert.Equal(t, "20260301T113000Z", setID)
+	takenAt, ok := SetTime(setID)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2026, 3, 1, 11, 30, 0, 0, time.UTC), takenAt)
+
+	name := SnapshotName(setID, "stackgraph:vertices")
+	assert.Equal(t, "sts-backup-20260301T113000Z-stackgraph_vertices", name)
+	id, ok := SetID(name)
+	assert.True(t, ok)
+	assert.Equal(t, setID, id)
+
+	_, ok = SetID("manual-snapshot")
+	assert.False(t, ok)
+	_, ok = SetTime("latest")
+	assert.False(t, ok)
+}
+
+func TestS3Location(t *testing.T) {
+	location := S3Location{Endpoint: "http://minio:9000", Region: "minio", Bucket: "sts-backup", Prefix: "/hbase/"}
+
+	assert.Equal(t, "s3a://sts-backup/hbase", location.URI())
+	assert.Equal(t, "s3a://sts-backup", S3Location{Bucket: "sts-backup"}.URI())
+	assert.Equal(t, "hbase/.hbase-snapshot/", location.SnapshotInfoPrefix())
+
+	name, ok := location.ExportedSnapshot("hbase/.hbase-snapshot/sts-backup-20260301T113000Z-stackgraph/.snapshotinfo")
+	assert.True(t, ok)
+	assert.Equal(t, "sts-backup-20260301T113000Z-stackgraph", name)
+	for _, key := range []string{
+		"hbase/.hbase-snapshot/sts-backup-20260301T113000Z-stackgraph/data.manifest",
+		"hbase/.hbase-snapshot/.tmp/sts-backup-20260301T113000Z-stackgraph/.snapshotinfo",
+		"hbase/.hbase-snapshot/manual/.snapshotinfo",
+	} {
+		_, ok := location.ExportedSnapshot(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestClient_TablesAndSnapshots(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{"hbase shell": "TABLE\nstackgraph\ntephra\n2 row(s)\n" +
+		"table\ttephra\ntable\tstackgraph\ntable\thbase:meta\n"}}
+	client := NewClient(runner)
+
+	tables, err := client.Tables()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stackgraph", "tephra"}, tables)
+
+	runner.outputs["hbase shell"] = "SNAPSHOT                                    TABLE + CREATION TIME\n" +
+		" sts-backup-20260301T113000Z-stackgraph      stackgraph (2026-03-01 11:30:05 +0000)\n" +
+		" sts-backup-20260301T113000Z-ns_vertices     ns:vertices (2026-03-01 11:30:07 +0000)\n" +
+		"2 row(s)\nTook 0.0210 seconds\n"
+	snapshots, err := client.Snapshots()
+	require.NoError(t, err)
+	assert.Equal(t, []Snapshot{
+		{Name: "sts-backup-20260301T113000Z-stackgraph", Table: "stackgraph"},
+		{Name: "sts-backup-20260301T113000Z-ns_vertices", Table: "ns:vertices"},
+	}, snapshots)
+	assert.Equal(t, "list_snapshots 'sts-backup-.*'", runner.stdins[1])
+}
+
+func TestClient_Scripts(t *testing.T) {
+	runner := &fakeRunner{}
+	client := NewClient(runner)
+	snapshots := []Snapshot{{Name: "sts-backup-20260301T113000Z-stackgraph", Table: "stackgraph"}, {Name: "sts-backup-20260301T113000Z-it_s", Table: "it's"}}
+
+	require.NoError(t, client.CreateSnapshots(snapshots))
+	require.NoError(t, client.RestoreSnapshots(snapshots[:1]))
+
+	assert.Equal(t, "snapshot 'stackgraph', 'sts-backup-20260301T113000Z-stackgraph'\n"+
+		`snapshot 'it\'s', 'sts-backup-20260301T113000Z-it_s'`+"\n", runner.stdins[0])
+	assert.Equal(t, "disable 'stackgraph' if exists('stackgraph') && is_enabled('stackgraph')\n"+
+		"restore_snapshot 'sts-backup-20260301T113000Z-stackgraph'\n"+
+		"enable 'stackgraph' unless is_enabled('stackgraph')\n", runner.stdins[1])
+
+	runner.errs = map[string]error{"hbase shell": errors.New("ERROR: Table stackgraph is disabled")}
+	assert.ErrorContains(t, client.CreateSnapshots(snapshots), "failed to take snapshots")
+}
+
+func TestClient_ExportAndImportSnapshot(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{"hbase org.apache.hadoop.hbase.util.HBaseConfTool": "hdfs://hdfs-nn:9000/hbase\n"}}
+	client := NewClient(runner)
+	location := S3Location{Endpoint: "https://s3.eu-west-1.amazonaws.com", Region: "eu-west-1", Bucket: "sts-backup", Prefix: "hbase",
+		AccessKey: "AKIA", SecretKey: "secret"}
+
+	require.NoError(t, client.ExportSnapshot("sts-backup-20260301T113000Z-stackgraph", location, 4))
+	rootDir, err := client.RootDir()
+	require.NoError(t, err)
+	assert.Equal(t, "hdfs://hdfs-nn:9000/hbase", rootDir)
+	require.NoError(t, client.ImportSnapshot("sts-backup-20260301T113000Z-stackgraph", location, rootDir, 2))
+
+	options := "-Dfs.s3a.endpoint=https://s3.eu-west-1.amazonaws.com -Dfs.s3a.endpoint.region=eu-west-1 " +
+		"-Dfs.s3a.path.style.access=true -Dfs.s3a.connection.ssl.enabled=true"
+	assert.Equal(t, "hbase org.apache.hadoop.hbase.snapshot.ExportSnapshot "+options+
+		" -snapshot sts-backup-20260301T113000Z-stackgraph -copy-to s3a://sts-backup/hbase -mappers 4", strings.Join(runner.commands[0][3:], " "))
+	assert.Equal(t, "hbase org.apache.hadoop.hbase.snapshot.ExportSnapshot "+options+
+		" -snapshot sts-backup-20260301T113000Z-stackgraph -copy-from s3a://sts-backup/hbase -copy-to hdfs://hdfs-nn:9000/hbase -mappers 2",
+		strings.Join(runner.commands[2][3:], " "))
+	for _, i := range []int{0, 2} {
+		assert.Equal(t, "sh", runner.commands[i][0])
+		assert.NotContains(t, strings.Join(runner.commands[i], " "), "secret", "credentials are not part of the command line")
+		assert.Equal(t, "AKIA\nsecret\n", runner.stdins[i])
+	}
+}
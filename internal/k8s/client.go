@@ -4,13 +4,16 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
+	"strconv"
 
+	"github.com/stackvista/stackstate-backup-cli/internal/proxy"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -20,11 +23,16 @@ import (
 	"k8s.io/client-go/transport/spdy"
 )
 
+// ErrPortForwardFailed is returned when a port-forward to a service or pod cannot be set up
+var ErrPortForwardFailed = errors.New("port-forward failed")
+
 // Client wraps the Kubernetes clientset
 type Client struct {
 	clientset  kubernetes.Interface
 	restConfig *rest.Config
-	debug      bool
+	// contextName is the kubeconfig context the client was created for, empty in-cluster
+	contextName string
+	debug       bool
 }
 
 // Clientset returns the underlying Kubernetes clientset
@@ -32,20 +40,33 @@ func (c *Client) Clientset() kubernetes.Interface {
 	return c.clientset
 }
 
-// NewClient creates a new Kubernetes client
-func NewClient(kubeconfigPath string, debug bool) (*Client, error) {
-	if kubeconfigPath == "" {
-		// Use default kubeconfig location
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
-		}
-		kubeconfigPath = filepath.Join(home, ".kube", "config")
+// NewClient creates a new Kubernetes client.
+// Without an explicit kubeconfig path the in-cluster configuration is used when running in a pod
+// (e.g. as a CronJob), otherwise the kubeconfig is loaded like kubectl does: from KUBECONFIG, which may list
+// several files to merge, or ~/.kube/config.
+func NewClient(kubeconfigPath string, debug bool, opts ...Option) (*Client, error) {
+	config, contextName, err := restConfig(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
+	return newClient(config, contextName, debug, opts)
+}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+// NewClientForContext creates a Kubernetes client for a named context of the kubeconfig,
+// e.g. to reach another cluster than the current context points at
+func NewClientForContext(kubeconfigPath, contextName string, debug bool, opts ...Option) (*Client, error) {
+	config, err := contextRestConfig(kubeconfigPath, contextName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build config: %w", err)
+		return nil, fmt.Errorf("failed to build config for context '%s': %w", contextName, err)
+	}
+	return newClient(config, contextName, debug, opts)
+}
+
+func newClient(config *rest.Config, contextName string, debug bool, opts []Option) (*Client, error) {
+	for _, opt := range opts {
+		if err := opt(config); err != nil {
+			return nil, err
+		}
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
@@ -54,20 +75,99 @@ func NewClient(kubeconfigPath string, debug bool) (*Client, error) {
 	}
 
 	return &Client{
-		clientset:  clientset,
-		restConfig: config,
-		debug:      debug,
+		clientset:   clientset,
+		restConfig:  config,
+		contextName: contextName,
+		debug:       debug,
 	}, nil
 }
 
-// PortForwardService creates a port-forward to a Kubernetes service
+// Option configures optional Client behavior
+type Option func(*rest.Config) error
+
+// WithProxy sends the requests to the API server, including port-forwards, through proxyURL. With an empty
+// proxyURL the proxy configured in the kubeconfig (proxy-url) or the environment (HTTPS_PROXY, NO_PROXY) is used.
+func WithProxy(proxyURL string) Option {
+	return func(config *rest.Config) error {
+		if proxyURL == "" {
+			return nil
+		}
+		proxyFunc, err := proxy.Func(proxyURL)
+		if err != nil {
+			return err
+		}
+		config.Proxy = proxyFunc
+		return nil
+	}
+}
+
+// WithImpersonation makes the requests as another user and groups, like kubectl --as and --as-group, so operations
+// run by a break-glass account are audited under the impersonated identity. The user is required for groups.
+func WithImpersonation(userName string, groups []string) Option {
+	return func(config *rest.Config) error {
+		if userName == "" && len(groups) > 0 {
+			return fmt.Errorf("impersonating groups requires a user to impersonate (--as)")
+		}
+		if userName != "" {
+			config.Impersonate = rest.ImpersonationConfig{UserName: userName, Groups: groups}
+		}
+		return nil
+	}
+}
+
+// InCluster reports whether the CLI runs inside a Kubernetes pod
+func InCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != ""
+}
+
+// restConfig builds the REST config from the kubeconfig or the in-cluster service account, and returns
+// the name of the current context, which is empty in-cluster.
+// The in-cluster configuration is only used when neither --kubeconfig nor KUBECONFIG is set.
+func restConfig(kubeconfigPath string) (*rest.Config, string, error) {
+	if kubeconfigPath == "" && os.Getenv(clientcmd.RecommendedConfigPathEnvVar) == "" && InCluster() {
+		config, err := rest.InClusterConfig()
+		return config, "", err
+	}
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules(kubeconfigPath),
+		&clientcmd.ConfigOverrides{},
+	)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	raw, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	return config, raw.CurrentContext, nil
+}
+
+// contextRestConfig builds the REST config of a named context from the kubeconfig
+func contextRestConfig(kubeconfigPath, contextName string) (*rest.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		loadingRules(kubeconfigPath),
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	).ClientConfig()
+}
+
+// loadingRules returns the kubeconfig loading rules of kubectl: an explicit path when given, otherwise the
+// files listed in KUBECONFIG merged in order, otherwise ~/.kube/config
+func loadingRules(kubeconfigPath string) *clientcmd.ClientConfigLoadingRules {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigPath
+	return rules
+}
+
+// PortForwardService creates a port-forward to a Kubernetes service. Errors match ErrPortForwardFailed.
 func (c *Client) PortForwardService(namespace, serviceName string, localPort, remotePort int) (chan struct{}, chan struct{}, error) {
 	ctx := context.Background()
 
 	// Get service to find pods
 	svc, err := c.clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get service: %w", err)
+		return nil, nil, fmt.Errorf("%w: failed to get service: %w", ErrPortForwardFailed, err)
 	}
 
 	// Find pod matching service selector
@@ -77,42 +177,78 @@ func (c *Client) PortForwardService(namespace, serviceName string, localPort, re
 		}),
 	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to list pods: %w", err)
+		return nil, nil, fmt.Errorf("%w: failed to list pods: %w", ErrPortForwardFailed, err)
 	}
 
 	if len(podList.Items) == 0 {
-		return nil, nil, fmt.Errorf("no pods found for service %s", serviceName)
-	}
-
-	// Find a running pod
-	var targetPod *corev1.Pod
-	for i := range podList.Items {
-		if podList.Items[i].Status.Phase == corev1.PodRunning {
-			targetPod = &podList.Items[i]
-			break
-		}
+		return nil, nil, fmt.Errorf("%w: no pods found for service %s", ErrPortForwardFailed, serviceName)
 	}
 
+	targetPod := selectPortForwardPod(podList.Items)
 	if targetPod == nil {
-		return nil, nil, fmt.Errorf("no running pods found for service %s", serviceName)
+		return nil, nil, fmt.Errorf("%w: no running pods found for service %s", ErrPortForwardFailed, serviceName)
 	}
 	// Setup port-forward
 	return c.PortForwardPod(namespace, targetPod.Name, localPort, remotePort)
 }
 
-// PortForwardPod creates a port-forward to a specific pod
+// selectPortForwardPod returns the healthiest pod to port-forward to: a Ready pod rather than one that is only
+// Running (e.g. still recovering shards or failing its readiness probe), and among those the one restarted least.
+// Terminating pods are skipped. It returns nil when no pod is running.
+func selectPortForwardPod(pods []corev1.Pod) *corev1.Pod {
+	var selected *corev1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if selected == nil || healthierPod(pod, selected) {
+			selected = pod
+		}
+	}
+	return selected
+}
+
+// healthierPod reports whether pod is a better port-forward target than other
+func healthierPod(pod, other *corev1.Pod) bool {
+	if podReady(pod) != podReady(other) {
+		return podReady(pod)
+	}
+	return podRestarts(pod) < podRestarts(other)
+}
+
+// podReady reports whether the Ready condition of pod is true
+func podReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podRestarts returns the total number of container restarts of pod
+func podRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+	return restarts
+}
+
+// PortForwardPod creates a port-forward to a specific pod. Errors match ErrPortForwardFailed.
 func (c *Client) PortForwardPod(namespace, podName string, localPort, remotePort int) (chan struct{}, chan struct{}, error) {
 	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace, podName)
 	hostIP := c.restConfig.Host
 	url, err := url.Parse(hostIP)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to parse host: %w", err)
+		return nil, nil, fmt.Errorf("%w: failed to parse host: %w", ErrPortForwardFailed, err)
 	}
 	url.Path = path
 
 	transport, upgrader, err := spdy.RoundTripperFor(c.restConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create round tripper: %w", err)
+		return nil, nil, fmt.Errorf("%w: failed to create round tripper: %w", ErrPortForwardFailed, err)
 	}
 
 	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
@@ -132,7 +268,7 @@ func (c *Client) PortForwardPod(namespace, podName string, localPort, remotePort
 
 	fw, err := portforward.New(dialer, ports, stopChan, readyChan, outWriter, errWriter)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create port forwarder: %w", err)
+		return nil, nil, fmt.Errorf("%w: failed to create port forwarder: %w", ErrPortForwardFailed, err)
 	}
 
 	go func() {
@@ -146,14 +282,20 @@ func (c *Client) PortForwardPod(namespace, podName string, localPort, remotePort
 	return stopChan, readyChan, nil
 }
 
+// OriginalReplicasAnnotation records the replica count of a deployment before it was scaled down, so it can
+// be scaled back up even when the process that scaled it down is gone
+const OriginalReplicasAnnotation = "sts-backup/original-replicas"
+
 // DeploymentScale holds the name and original replica count of a deployment
 type DeploymentScale struct {
-	Name     string
-	Replicas int32
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
 }
 
 // ScaleDownDeployments scales down deployments matching a label selector to 0 replicas
-// Returns a map of deployment names to their original replica counts
+// Returns a map of deployment names to their original replica counts.
+// The original replica count is also stored in the OriginalReplicasAnnotation of each deployment. A deployment
+// still annotated by an interrupted earlier run keeps its annotated count, since it is already scaled down.
 func (c *Client) ScaleDownDeployments(namespace, labelSelector string) ([]DeploymentScale, error) {
 	ctx := context.Background()
 
@@ -177,6 +319,9 @@ func (c *Client) ScaleDownDeployments(namespace, labelSelector string) ([]Deploy
 		if deployment.Spec.Replicas != nil {
 			originalReplicas = *deployment.Spec.Replicas
 		}
+		if annotated, ok := annotatedReplicas(&deployment); ok {
+			originalReplicas = annotated
+		}
 
 		// Store original replica count
 		scaledDeployments = append(scaledDeployments, DeploymentScale{
@@ -188,6 +333,10 @@ func (c *Client) ScaleDownDeployments(namespace, labelSelector string) ([]Deploy
 		if originalReplicas > 0 {
 			replicas := int32(0)
 			deployment.Spec.Replicas = &replicas
+			if deployment.Annotations == nil {
+				deployment.Annotations = map[string]string{}
+			}
+			deployment.Annotations[OriginalReplicasAnnotation] = strconv.Itoa(int(originalReplicas))
 
 			_, err := c.clientset.AppsV1().Deployments(namespace).Update(ctx, &deployment, metav1.UpdateOptions{})
 			if err != nil {
@@ -199,7 +348,8 @@ func (c *Client) ScaleDownDeployments(namespace, labelSelector string) ([]Deploy
 	return scaledDeployments, nil
 }
 
-// ScaleUpDeployments restores deployments to their original replica counts
+// ScaleUpDeployments restores deployments to their original replica counts.
+// The OriginalReplicasAnnotation takes precedence over the given count and is removed.
 func (c *Client) ScaleUpDeployments(namespace string, deploymentScales []DeploymentScale) error {
 	ctx := context.Background()
 
@@ -209,17 +359,86 @@ func (c *Client) ScaleUpDeployments(namespace string, deploymentScales []Deploym
 			return fmt.Errorf("failed to get deployment %s: %w", scale.Name, err)
 		}
 
-		deployment.Spec.Replicas = &scale.Replicas
+		if err := c.scaleUpDeployment(ctx, namespace, deployment, scale.Replicas); err != nil {
+			return err
+		}
+	}
 
-		_, err = c.clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to scale up deployment %s: %w", scale.Name, err)
+	return nil
+}
+
+// ScaleUpAnnotatedDeployments scales the deployments matching a label selector that carry the
+// OriginalReplicasAnnotation back up to the annotated replica count. It recovers from a restore that was
+// interrupted before it could scale the deployments back up. Returns the deployments that were scaled up.
+func (c *Client) ScaleUpAnnotatedDeployments(namespace, labelSelector string) ([]DeploymentScale, error) {
+	ctx := context.Background()
+
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	scaled := []DeploymentScale{}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		replicas, ok := annotatedReplicas(deployment)
+		if !ok {
+			continue
 		}
+		if err := c.scaleUpDeployment(ctx, namespace, deployment, replicas); err != nil {
+			return scaled, err
+		}
+		scaled = append(scaled, DeploymentScale{Name: deployment.Name, Replicas: replicas})
+	}
+
+	return scaled, nil
+}
+
+// scaleUpDeployment sets the replicas of a deployment, preferring its OriginalReplicasAnnotation, and removes
+// the annotation
+func (c *Client) scaleUpDeployment(ctx context.Context, namespace string, deployment *appsv1.Deployment, replicas int32) error {
+	if annotated, ok := annotatedReplicas(deployment); ok {
+		replicas = annotated
 	}
+	deployment.Spec.Replicas = &replicas
+	delete(deployment.Annotations, OriginalReplicasAnnotation)
 
+	if _, err := c.clientset.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to scale up deployment %s: %w", deployment.Name, err)
+	}
 	return nil
 }
 
+// annotatedReplicas returns the replica count recorded in the OriginalReplicasAnnotation of a deployment or
+// StatefulSet
+func annotatedReplicas(object metav1.Object) (int32, bool) {
+	value, ok := object.GetAnnotations()[OriginalReplicasAnnotation]
+	if !ok {
+		return 0, false
+	}
+	replicas, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || replicas < 0 {
+		return 0, false
+	}
+	return int32(replicas), true
+}
+
+// ListNamespaces returns the names of all namespaces in the cluster
+func (c *Client) ListNamespaces() ([]string, error) {
+	namespaces, err := c.clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	names := make([]string, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
+
 // NewTestClient creates a k8s Client for testing with a fake clientset.
 // This function is exported so it can be used in other package tests.
 func NewTestClient(clientset kubernetes.Interface) *Client {
@@ -2,6 +2,9 @@ package k8s
 
 import (
 	"context"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,6 +13,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 func TestClient_ScaleDownDeployments(t *testing.T) {
@@ -205,6 +209,74 @@ func TestClient_ScaleUpDeployments_NonExistent(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get deployment")
 }
 
+func TestClient_ScaleDeployments_Annotations(t *testing.T) {
+	ctx := context.Background()
+	labels := map[string]string{"app": "test"}
+
+	t.Run("scale down annotates and scale up removes the annotation", func(t *testing.T) {
+		deploy := createDeployment("deploy1", "test-ns", labels, 3)
+		fakeClient := fake.NewSimpleClientset(&deploy)
+		client := &Client{clientset: fakeClient}
+
+		scales, err := client.ScaleDownDeployments("test-ns", "app=test")
+		require.NoError(t, err)
+		scaledDown, err := fakeClient.AppsV1().Deployments("test-ns").Get(ctx, "deploy1", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "3", scaledDown.Annotations[OriginalReplicasAnnotation])
+
+		require.NoError(t, client.ScaleUpDeployments("test-ns", scales))
+		scaledUp, err := fakeClient.AppsV1().Deployments("test-ns").Get(ctx, "deploy1", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), *scaledUp.Spec.Replicas)
+		assert.NotContains(t, scaledUp.Annotations, OriginalReplicasAnnotation)
+	})
+
+	t.Run("interrupted scale down keeps the annotated count", func(t *testing.T) {
+		deploy := createDeployment("deploy1", "test-ns", labels, 0)
+		deploy.Annotations = map[string]string{OriginalReplicasAnnotation: "4"}
+		fakeClient := fake.NewSimpleClientset(&deploy)
+		client := &Client{clientset: fakeClient}
+
+		scales, err := client.ScaleDownDeployments("test-ns", "app=test")
+
+		require.NoError(t, err)
+		assert.Equal(t, []DeploymentScale{{Name: "deploy1", Replicas: 4}}, scales)
+	})
+
+	t.Run("scale up prefers the annotation over the given count", func(t *testing.T) {
+		deploy := createDeployment("deploy1", "test-ns", labels, 0)
+		deploy.Annotations = map[string]string{OriginalReplicasAnnotation: "4"}
+		fakeClient := fake.NewSimpleClientset(&deploy)
+		client := &Client{clientset: fakeClient}
+
+		require.NoError(t, client.ScaleUpDeployments("test-ns", []DeploymentScale{{Name: "deploy1", Replicas: 0}}))
+
+		updated, err := fakeClient.AppsV1().Deployments("test-ns").Get(ctx, "deploy1", metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int32(4), *updated.Spec.Replicas)
+	})
+}
+
+func TestClient_ScaleUpAnnotatedDeployments(t *testing.T) {
+	labels := map[string]string{"app": "test"}
+	annotated := createDeployment("annotated", "test-ns", labels, 0)
+	annotated.Annotations = map[string]string{OriginalReplicasAnnotation: "2"}
+	invalid := createDeployment("invalid", "test-ns", labels, 0)
+	invalid.Annotations = map[string]string{OriginalReplicasAnnotation: "many"}
+	plain := createDeployment("plain", "test-ns", labels, 1)
+	fakeClient := fake.NewSimpleClientset(&annotated, &invalid, &plain)
+	client := &Client{clientset: fakeClient}
+
+	scaled, err := client.ScaleUpAnnotatedDeployments("test-ns", "app=test")
+
+	require.NoError(t, err)
+	assert.Equal(t, []DeploymentScale{{Name: "annotated", Replicas: 2}}, scaled)
+	updated, err := fakeClient.AppsV1().Deployments("test-ns").Get(context.Background(), "annotated", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *updated.Spec.Replicas)
+	assert.NotContains(t, updated.Annotations, OriginalReplicasAnnotation)
+}
+
 func TestClient_Clientset(t *testing.T) {
 	fakeClient := fake.NewSimpleClientset()
 	client := &Client{
@@ -223,7 +295,7 @@ func TestClient_PortForwardService_ServiceNotFound(t *testing.T) {
 	}
 
 	_, _, err := client.PortForwardService("test-ns", "nonexistent-svc", 8080, 9200)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrPortForwardFailed)
 	assert.Contains(t, err.Error(), "failed to get service")
 }
 
@@ -250,7 +322,7 @@ func TestClient_PortForwardService_NoPodsFound(t *testing.T) {
 	}
 
 	_, _, err = client.PortForwardService("test-ns", "test-svc", 8080, 9200)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrPortForwardFailed)
 	assert.Contains(t, err.Error(), "no pods found for service")
 }
 
@@ -293,10 +365,256 @@ func TestClient_PortForwardService_NoRunningPods(t *testing.T) {
 	}
 
 	_, _, err = client.PortForwardService("test-ns", "test-svc", 8080, 9200)
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrPortForwardFailed)
 	assert.Contains(t, err.Error(), "no running pods found for service")
 }
 
+func TestSelectPortForwardPod(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, ready bool, restarts int32) corev1.Pod {
+		readyStatus := corev1.ConditionFalse
+		if ready {
+			readyStatus = corev1.ConditionTrue
+		}
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.PodStatus{
+				Phase:             phase,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: readyStatus}},
+				ContainerStatuses: []corev1.ContainerStatus{{RestartCount: restarts}},
+			},
+		}
+	}
+	terminating := pod("es-terminating", corev1.PodRunning, true, 0)
+	terminating.DeletionTimestamp = &metav1.Time{}
+
+	tests := []struct {
+		name     string
+		pods     []corev1.Pod
+		expected string
+	}{
+		{
+			name:     "ready pod preferred over running pod",
+			pods:     []corev1.Pod{pod("es-0", corev1.PodRunning, false, 0), pod("es-1", corev1.PodRunning, true, 0)},
+			expected: "es-1",
+		},
+		{
+			name:     "fewest restarts among ready pods",
+			pods:     []corev1.Pod{pod("es-0", corev1.PodRunning, true, 5), pod("es-1", corev1.PodRunning, true, 1), pod("es-2", corev1.PodRunning, true, 3)},
+			expected: "es-1",
+		},
+		{
+			name:     "running pod when none is ready",
+			pods:     []corev1.Pod{pod("es-0", corev1.PodPending, false, 0), pod("es-1", corev1.PodRunning, false, 0)},
+			expected: "es-1",
+		},
+		{
+			name:     "terminating pod skipped",
+			pods:     []corev1.Pod{terminating, pod("es-1", corev1.PodRunning, false, 0)},
+			expected: "es-1",
+		},
+		{
+			name: "no running pod",
+			pods: []corev1.Pod{pod("es-0", corev1.PodPending, false, 0), terminating},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := selectPortForwardPod(tt.pods)
+			if tt.expected == "" {
+				assert.Nil(t, selected)
+				return
+			}
+			require.NotNil(t, selected)
+			assert.Equal(t, tt.expected, selected.Name)
+		})
+	}
+}
+
+func TestClient_ListNamespaces(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "suse-observability"}},
+	)
+	client := NewTestClient(fakeClient)
+
+	namespaces, err := client.ListNamespaces()
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"default", "suse-observability"}, namespaces)
+}
+
+func TestRestConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://test-cluster:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: abc
+`), 0o600))
+
+	t.Run("explicit kubeconfig wins over in-cluster", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		config, contextName, err := restConfig(kubeconfig)
+		require.NoError(t, err)
+		assert.Equal(t, "https://test-cluster:6443", config.Host)
+		assert.Equal(t, "test", contextName)
+	})
+
+	t.Run("KUBECONFIG wins over in-cluster", func(t *testing.T) {
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		t.Setenv("KUBECONFIG", kubeconfig)
+		config, _, err := restConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "https://test-cluster:6443", config.Host)
+	})
+
+	t.Run("in-cluster when running in a pod", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", "")
+		t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+		t.Setenv("KUBERNETES_SERVICE_PORT", "443")
+		// The service account token is not mounted in tests, so loading fails in the in-cluster code path
+		_, _, err := restConfig("")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestContextRestConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: production
+  cluster:
+    server: https://production:6443
+- name: staging
+  cluster:
+    server: https://staging:6443
+contexts:
+- name: production
+  context:
+    cluster: production
+    user: test
+- name: staging
+  context:
+    cluster: staging
+    user: test
+current-context: production
+users:
+- name: test
+  user:
+    token: abc
+`), 0o600))
+
+	config, err := contextRestConfig(kubeconfig, "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging:6443", config.Host)
+
+	_, err = contextRestConfig(kubeconfig, "missing")
+	assert.Error(t, err)
+}
+
+func TestRestConfig_MergedKubeconfigs(t *testing.T) {
+	dir := t.TempDir()
+	contexts := filepath.Join(dir, "contexts")
+	require.NoError(t, os.WriteFile(contexts, []byte(`apiVersion: v1
+kind: Config
+contexts:
+- name: staging
+  context:
+    cluster: staging
+    user: test
+current-context: staging
+`), 0o600))
+	clusters := filepath.Join(dir, "clusters")
+	require.NoError(t, os.WriteFile(clusters, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: staging
+  cluster:
+    server: https://staging:6443
+current-context: ignored
+users:
+- name: test
+  user:
+    token: abc
+`), 0o600))
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", contexts+string(os.PathListSeparator)+clusters)
+
+	// The first file setting the current context wins, the cluster and user come from the second file
+	config, contextName, err := restConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging:6443", config.Host)
+	assert.Equal(t, "abc", config.BearerToken)
+	assert.Equal(t, "staging", contextName)
+
+	config, err = contextRestConfig("", "staging")
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging:6443", config.Host)
+}
+
+func TestWithProxy(t *testing.T) {
+	config := &rest.Config{Host: "https://api.example.com:6443"}
+	require.NoError(t, WithProxy("")(config))
+	assert.Nil(t, config.Proxy, "the proxy from the kubeconfig or environment is used")
+
+	require.NoError(t, WithProxy("http://jump-host:8080")(config))
+	require.NotNil(t, config.Proxy)
+	req, err := http.NewRequest(http.MethodGet, config.Host, nil)
+	require.NoError(t, err)
+	proxyURL, err := config.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://jump-host:8080", proxyURL.String())
+
+	assert.Error(t, WithProxy("jump-host")(config))
+}
+
+func TestWithImpersonation(t *testing.T) {
+	tests := []struct {
+		name        string
+		userName    string
+		groups      []string
+		expected    rest.ImpersonationConfig
+		expectError bool
+	}{
+		{name: "no impersonation"},
+		{name: "user", userName: "break-glass", expected: rest.ImpersonationConfig{UserName: "break-glass"}},
+		{
+			name:     "user and groups",
+			userName: "break-glass",
+			groups:   []string{"system:masters", "sre"},
+			expected: rest.ImpersonationConfig{UserName: "break-glass", Groups: []string{"system:masters", "sre"}},
+		},
+		{name: "groups without user", groups: []string{"sre"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &rest.Config{}
+			err := WithImpersonation(tt.userName, tt.groups)(config)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config.Impersonate)
+		})
+	}
+}
+
 // Helper function to create a deployment for testing
 func createDeployment(name, namespace string, labels map[string]string, replicas int32) appsv1.Deployment {
 	return appsv1.Deployment{
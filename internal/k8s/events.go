@@ -0,0 +1,68 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// EventComponent is the source component reported on Kubernetes Events
+	EventComponent = "sts-backup"
+	// RunIDLabel is the label carrying the run ID on objects created by the CLI
+	RunIDLabel = "sts-backup/run-id"
+
+	EventTypeNormal  = corev1.EventTypeNormal
+	EventTypeWarning = corev1.EventTypeWarning
+)
+
+// Event describes a Kubernetes Event recorded against an object in the namespace
+type Event struct {
+	// ObjectKind and ObjectName identify the involved object (e.g. the backup ConfigMap)
+	ObjectKind string
+	ObjectName string
+	Type       string // EventTypeNormal or EventTypeWarning
+	Reason     string
+	Message    string
+	RunID      string
+}
+
+// RecordEvent creates a Kubernetes Event so operations show up in `kubectl get events`
+func (c *Client) RecordEvent(namespace string, event Event) error {
+	now := metav1.NewTime(time.Now())
+
+	k8sEvent := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", event.ObjectName, now.UnixNano()),
+			Namespace: namespace,
+			Labels:    map[string]string{},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      event.ObjectKind,
+			Name:      event.ObjectName,
+			Namespace: namespace,
+		},
+		Type:    event.Type,
+		Reason:  event.Reason,
+		Message: event.Message,
+		Source: corev1.EventSource{
+			Component: EventComponent,
+		},
+		ReportingController: EventComponent,
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+	if event.RunID != "" {
+		k8sEvent.Labels[RunIDLabel] = event.RunID
+		k8sEvent.Message = fmt.Sprintf("%s (run %s)", event.Message, event.RunID)
+	}
+
+	if _, err := c.clientset.CoreV1().Events(namespace).Create(context.Background(), k8sEvent, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create event: %w", err)
+	}
+	return nil
+}
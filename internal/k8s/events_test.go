@@ -0,0 +1,64 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestClient_RecordEvent(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	client := &Client{
+		clientset: fakeClient,
+	}
+
+	err := client.RecordEvent("test-ns", Event{
+		ObjectKind: "ConfigMap",
+		ObjectName: "backup-config",
+		Type:       EventTypeNormal,
+		Reason:     "RestoreStarted",
+		Message:    "Restoring snapshot snap-1",
+		RunID:      "20250101T000000-abc123",
+	})
+	require.NoError(t, err)
+
+	events, err := fakeClient.CoreV1().Events("test-ns").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+
+	event := events.Items[0]
+	assert.Equal(t, "ConfigMap", event.InvolvedObject.Kind)
+	assert.Equal(t, "backup-config", event.InvolvedObject.Name)
+	assert.Equal(t, "RestoreStarted", event.Reason)
+	assert.Equal(t, EventTypeNormal, event.Type)
+	assert.Equal(t, EventComponent, event.Source.Component)
+	assert.Equal(t, "20250101T000000-abc123", event.Labels[RunIDLabel])
+	assert.Contains(t, event.Message, "Restoring snapshot snap-1")
+	assert.Contains(t, event.Message, "20250101T000000-abc123")
+}
+
+func TestClient_RecordEvent_WithoutRunID(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	client := &Client{
+		clientset: fakeClient,
+	}
+
+	err := client.RecordEvent("test-ns", Event{
+		ObjectKind: "ConfigMap",
+		ObjectName: "backup-config",
+		Type:       EventTypeWarning,
+		Reason:     "RestoreFailed",
+		Message:    "Restore failed",
+	})
+	require.NoError(t, err)
+
+	events, err := fakeClient.CoreV1().Events("test-ns").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	assert.Equal(t, "Restore failed", events.Items[0].Message)
+	assert.Empty(t, events.Items[0].Labels)
+}
@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// ExecError is returned when a command run in a pod exits with an error, with what it wrote to stderr
+type ExecError struct {
+	Command string
+	Stderr  string
+	Err     error
+}
+
+// Error implements the error interface
+func (e *ExecError) Error() string {
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		return fmt.Sprintf("command '%s' failed: %v: %s", e.Command, e.Err, stderr)
+	}
+	return fmt.Sprintf("command '%s' failed: %v", e.Command, e.Err)
+}
+
+// Unwrap returns the error of the command
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// ReadyPod returns the name of a Ready pod matching a label selector, preferring the pod restarted least like
+// PortForwardService does
+func (c *Client) ReadyPod(namespace, labelSelector string) (string, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods: %w", err)
+	}
+	pod := selectPortForwardPod(pods.Items)
+	if pod == nil {
+		return "", fmt.Errorf("no running pods found (selector: %s)", labelSelector)
+	}
+	return pod.Name, nil
+}
+
+// ExecPod runs a command in a container of a pod, like kubectl exec, and returns what it wrote to stdout. A
+// command that exits with an error returns an *ExecError.
+func (c *Client) ExecPod(namespace, podName, container string, command []string) (string, error) {
+	var stdout bytes.Buffer
+	err := c.ExecPodStream(namespace, podName, container, command, nil, &stdout)
+	return stdout.String(), err
+}
+
+// ExecPodStream runs a command in a container of a pod like ExecPod, streaming stdin to the command when it is not
+// nil and what the command writes to stdout to stdout, so large outputs such as database dumps are never held in
+// memory
+func (c *Client) ExecPodStream(namespace, podName, container string, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to exec in pod %s: %w", podName, err)
+	}
+
+	var stderr bytes.Buffer
+	err = executor.StreamWithContext(context.Background(), remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: &stderr})
+	if err != nil {
+		return &ExecError{Command: strings.Join(command, " "), Stderr: stderr.String(), Err: err}
+	}
+	return nil
+}
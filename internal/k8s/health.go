@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrClusterUnhealthy is returned when the pods of a data store, such as the Elasticsearch StatefulSets, do not
+// have all replicas Ready or are crash looping
+var ErrClusterUnhealthy = errors.New("not healthy")
+
+// crashLoopBackOff is the waiting reason of a container that keeps crashing
+const crashLoopBackOff = "CrashLoopBackOff"
+
+// StatefulSetHealth is the health of a StatefulSet running the pods of a service
+type StatefulSetHealth struct {
+	Name          string
+	Replicas      int32
+	ReadyReplicas int32
+	// CrashLoopingPods lists the pods with a container in CrashLoopBackOff
+	CrashLoopingPods []string
+}
+
+// Healthy reports whether all replicas are Ready and no pod is crash looping
+func (h StatefulSetHealth) Healthy() bool {
+	return h.ReadyReplicas >= h.Replicas && len(h.CrashLoopingPods) == 0
+}
+
+// ServiceStatefulSetHealth returns the health of the StatefulSets owning the pods selected by a service, in the
+// order their pods are listed. Pods not owned by a StatefulSet are ignored.
+func (c *Client) ServiceStatefulSetHealth(namespace, serviceName string) ([]StatefulSetHealth, error) {
+	ctx := context.Background()
+
+	svc, err := c.clientset.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(&metav1.LabelSelector{MatchLabels: svc.Spec.Selector}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var names []string
+	crashLooping := make(map[string][]string)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		owner := statefulSetOwner(pod)
+		if owner == "" {
+			continue
+		}
+		if _, seen := crashLooping[owner]; !seen {
+			names = append(names, owner)
+			crashLooping[owner] = nil
+		}
+		if podCrashLooping(pod) {
+			crashLooping[owner] = append(crashLooping[owner], pod.Name)
+		}
+	}
+
+	health := make([]StatefulSetHealth, 0, len(names))
+	for _, name := range names {
+		sts, err := c.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get statefulset %s: %w", name, err)
+		}
+		health = append(health, StatefulSetHealth{
+			Name:             name,
+			Replicas:         statefulSetReplicas(sts),
+			ReadyReplicas:    sts.Status.ReadyReplicas,
+			CrashLoopingPods: crashLooping[name],
+		})
+	}
+	return health, nil
+}
+
+// statefulSetOwner returns the name of the StatefulSet owning pod, or an empty string
+func statefulSetOwner(pod *corev1.Pod) string {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "StatefulSet" {
+			return owner.Name
+		}
+	}
+	return ""
+}
+
+// podCrashLooping reports whether a container of pod is waiting in CrashLoopBackOff
+func podCrashLooping(pod *corev1.Pod) bool {
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == crashLoopBackOff {
+			return true
+		}
+	}
+	return false
+}
+
+// statefulSetReplicas returns the desired number of replicas, which defaults to one
+func statefulSetReplicas(sts *appsv1.StatefulSet) int32 {
+	if sts.Spec.Replicas == nil {
+		return 1
+	}
+	return *sts.Spec.Replicas
+}
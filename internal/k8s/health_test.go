@@ -0,0 +1,104 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func esPod(name, owner, waitingReason string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test-ns",
+			Labels:    map[string]string{"app": "elasticsearch"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if owner != "" {
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: "StatefulSet", Name: owner}}
+	}
+	if waitingReason != "" {
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  "elasticsearch",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: waitingReason}},
+		}}
+	}
+	return pod
+}
+
+func esStatefulSet(name string, replicas, ready int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestClient_ServiceStatefulSetHealth(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "elasticsearch", Namespace: "test-ns"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "elasticsearch"}},
+	}
+
+	tests := []struct {
+		name     string
+		objects  []runtime.Object
+		expected []StatefulSetHealth
+		healthy  []bool
+	}{
+		{
+			name: "all replicas ready",
+			objects: []runtime.Object{
+				esStatefulSet("es-master", 3, 3),
+				esPod("es-master-0", "es-master", ""), esPod("es-master-1", "es-master", ""), esPod("es-master-2", "es-master", ""),
+			},
+			expected: []StatefulSetHealth{{Name: "es-master", Replicas: 3, ReadyReplicas: 3}},
+			healthy:  []bool{true},
+		},
+		{
+			name: "crash looping pod",
+			objects: []runtime.Object{
+				esStatefulSet("es-master", 3, 2),
+				esPod("es-master-0", "es-master", ""), esPod("es-master-1", "es-master", crashLoopBackOff), esPod("es-master-2", "es-master", ""),
+			},
+			expected: []StatefulSetHealth{{Name: "es-master", Replicas: 3, ReadyReplicas: 2, CrashLoopingPods: []string{"es-master-1"}}},
+			healthy:  []bool{false},
+		},
+		{
+			name: "pods without statefulset are ignored",
+			objects: []runtime.Object{
+				esPod("es-standalone", "", ""),
+			},
+			expected: []StatefulSetHealth{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(append([]runtime.Object{service}, tt.objects...)...)
+			client := NewTestClient(fakeClient)
+
+			health, err := client.ServiceStatefulSetHealth("test-ns", "elasticsearch")
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, health)
+			for i, healthy := range tt.healthy {
+				assert.Equal(t, healthy, health[i].Healthy())
+			}
+		})
+	}
+}
+
+func TestClient_ServiceStatefulSetHealth_ServiceNotFound(t *testing.T) {
+	client := NewTestClient(fake.NewSimpleClientset())
+
+	_, err := client.ServiceStatefulSetHealth("test-ns", "elasticsearch")
+	assert.Error(t, err)
+}
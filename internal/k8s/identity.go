@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"context"
+	"os/user"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// unknownUser is reported when no identity can be determined
+	unknownUser = "unknown"
+	// localUserPrefix marks the local OS user, which the cluster has not verified
+	localUserPrefix = "local:"
+)
+
+// CurrentUser returns the Kubernetes username the client is authenticated as (via SelfSubjectReview).
+// When the cluster does not support the API it falls back to the local OS user, marked as unverified with the
+// prefix local:, e.g. local:jdoe, since the audit log must not present it as the authenticated identity.
+func (c *Client) CurrentUser() string {
+	review, err := c.clientset.AuthenticationV1().SelfSubjectReviews().Create(
+		context.Background(), &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{},
+	)
+	if err == nil && review.Status.UserInfo.Username != "" {
+		return review.Status.UserInfo.Username
+	}
+
+	if osUser, err := user.Current(); err == nil && osUser.Username != "" {
+		return localUserPrefix + osUser.Username
+	}
+	return unknownUser
+}
+
+// inClusterContext is reported as context name when the client uses the in-cluster service account
+const inClusterContext = "(in-cluster)"
+
+// Host returns the URL of the API server the client talks to
+func (c *Client) Host() string {
+	if c.restConfig == nil {
+		return ""
+	}
+	return c.restConfig.Host
+}
+
+// ContextName returns the kubeconfig context the client was created for
+func (c *Client) ContextName() string {
+	if c.contextName == "" {
+		return inClusterContext
+	}
+	return c.contextName
+}
@@ -0,0 +1,51 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestClient_CurrentUser_FromSelfSubjectReview(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	fakeClient.PrependReactor("create", "selfsubjectreviews", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+		return true, &authenticationv1.SelfSubjectReview{
+			Status: authenticationv1.SelfSubjectReviewStatus{
+				UserInfo: authenticationv1.UserInfo{Username: "system:admin"},
+			},
+		}, nil
+	})
+
+	client := &Client{
+		clientset: fakeClient,
+	}
+
+	assert.Equal(t, "system:admin", client.CurrentUser())
+}
+
+func TestClient_CurrentUser_FallsBackToOSUser(t *testing.T) {
+	client := &Client{
+		clientset: fake.NewSimpleClientset(),
+	}
+
+	// The fake clientset returns an empty review, so the local user is used, marked as unverified
+	assert.Regexp(t, `^(local:.+|unknown)$`, client.CurrentUser())
+}
+
+func TestClient_ClusterIdentity(t *testing.T) {
+	client := &Client{
+		restConfig:  &rest.Config{Host: "https://production:6443"},
+		contextName: "production",
+	}
+	assert.Equal(t, "https://production:6443", client.Host())
+	assert.Equal(t, "production", client.ContextName())
+
+	inCluster := &Client{restConfig: &rest.Config{Host: "https://10.0.0.1:443"}}
+	assert.Equal(t, "(in-cluster)", inCluster.ContextName())
+	assert.Empty(t, NewTestClient(fake.NewSimpleClientset()).Host())
+}
@@ -1,6 +1,12 @@
 package k8s
 
-import "k8s.io/client-go/kubernetes"
+import (
+	"io"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/client-go/kubernetes"
+)
 
 // Interface defines the contract for Kubernetes client operations
 // This interface allows for easy mocking in tests
@@ -9,12 +15,47 @@ type Interface interface {
 	// Useful for direct API access when needed
 	Clientset() kubernetes.Interface
 
+	// Namespace operations
+	ListNamespaces() ([]string, error)
+
 	// Port forwarding operations
 	PortForwardService(namespace, serviceName string, localPort, remotePort int) (stopChan chan struct{}, readyChan chan struct{}, err error)
 
 	// Deployment scaling operations
 	ScaleDownDeployments(namespace, labelSelector string) ([]DeploymentScale, error)
 	ScaleUpDeployments(namespace string, deployments []DeploymentScale) error
+	ScaleUpAnnotatedDeployments(namespace, labelSelector string) ([]DeploymentScale, error)
+
+	// StatefulSet scaling operations
+	ScaleDownStatefulSets(namespace, labelSelector string) ([]StatefulSetScale, error)
+	ScaleUpStatefulSet(namespace string, scale StatefulSetScale) error
+	ScaleUpAnnotatedStatefulSets(namespace, labelSelector string) ([]StatefulSetScale, error)
+	WaitForStatefulSetScale(namespace, name string, replicas int32, timeout, interval time.Duration) error
+
+	// Pod exec operations
+	ReadyPod(namespace, labelSelector string) (string, error)
+	ExecPod(namespace, podName, container string, command []string) (string, error)
+	ExecPodStream(namespace, podName, container string, command []string, stdin io.Reader, stdout io.Writer) error
+
+	// Job operations
+	CreateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error)
+	ListJobs(namespace, labelSelector string) ([]batchv1.Job, error)
+	WaitForJobPod(namespace, jobName string, timeout, interval time.Duration) (string, error)
+	StreamPodLogs(namespace, podName, container string, w io.Writer) error
+	JobStatus(namespace, name string) (JobResult, error)
+	WaitForJob(namespace, name string, timeout, interval time.Duration) (JobResult, error)
+	ContainerExitCode(namespace, podName, container string) (int, bool, error)
+
+	// Health operations
+	ServiceStatefulSetHealth(namespace, serviceName string) ([]StatefulSetHealth, error)
+
+	// Event operations
+	RecordEvent(namespace string, event Event) error
+
+	// Identity operations
+	CurrentUser() string
+	Host() string
+	ContextName() string
 }
 
 // Ensure *Client implements Interface
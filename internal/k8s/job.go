@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrJobPodTimeout is returned when the pod of a Job has not started within the timeout
+var ErrJobPodTimeout = errors.New("timed out waiting for the pod of job")
+
+// JobResult is the outcome of a Job
+type JobResult string
+
+const (
+	// JobRunning is a Job that has not completed or failed yet
+	JobRunning JobResult = "Running"
+	// JobSucceeded is a Job whose pod completed successfully
+	JobSucceeded JobResult = "Succeeded"
+	// JobFailed is a Job whose pod failed and that is not retried anymore
+	JobFailed JobResult = "Failed"
+)
+
+// CreateJob creates a Job and returns it as stored by the API server
+func (c *Client) CreateJob(namespace string, job *batchv1.Job) (*batchv1.Job, error) {
+	created, err := c.clientset.BatchV1().Jobs(namespace).Create(context.Background(), job, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job %s: %w", job.Name, err)
+	}
+	return created, nil
+}
+
+// ListJobs returns the Jobs matching a label selector
+func (c *Client) ListJobs(namespace, labelSelector string) ([]batchv1.Job, error) {
+	jobs, err := c.clientset.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	return jobs.Items, nil
+}
+
+// WaitForJobPod polls every interval until a pod of a Job is running or has finished, and returns its name, or
+// returns ErrJobPodTimeout after timeout. Pods pending for e.g. an image pull are waited for, so their logs can
+// be followed from the start.
+func (c *Client) WaitForJobPod(namespace, jobName string, timeout, interval time.Duration) (string, error) {
+	ctx := context.Background()
+	deadline := time.Now().Add(timeout)
+
+	for {
+		pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+		if err != nil {
+			return "", fmt.Errorf("failed to list pods of job %s: %w", jobName, err)
+		}
+		if pod := latestStartedPod(pods.Items); pod != nil {
+			return pod.Name, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("%w %s after %s", ErrJobPodTimeout, jobName, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// latestStartedPod returns the most recently created pod that is running or has finished, or nil
+func latestStartedPod(pods []corev1.Pod) *corev1.Pod {
+	var latest *corev1.Pod
+	for i := range pods {
+		if pods[i].Status.Phase == corev1.PodPending || pods[i].Status.Phase == corev1.PodUnknown {
+			continue
+		}
+		if latest == nil || pods[i].CreationTimestamp.After(latest.CreationTimestamp.Time) {
+			latest = &pods[i]
+		}
+	}
+	return latest
+}
+
+// StreamPodLogs follows the logs of a container of a pod, like kubectl logs -f, writing them to w until the
+// container exits
+func (c *Client) StreamPodLogs(namespace, podName, container string, w io.Writer) error {
+	stream, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    true,
+	}).Stream(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to stream logs of pod %s: %w", podName, err)
+	}
+	defer func() { _ = stream.Close() }()
+
+	if _, err := io.Copy(w, stream); err != nil {
+		return fmt.Errorf("failed to stream logs of pod %s: %w", podName, err)
+	}
+	return nil
+}
+
+// JobStatus returns the outcome of a Job
+func (c *Client) JobStatus(namespace, name string) (JobResult, error) {
+	job, err := c.clientset.BatchV1().Jobs(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get job %s: %w", name, err)
+	}
+	return jobResult(job), nil
+}
+
+// WaitForJob polls a Job every interval until it succeeded or failed and returns its outcome, or JobRunning when it
+// is still running after timeout
+func (c *Client) WaitForJob(namespace, name string, timeout, interval time.Duration) (JobResult, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		result, err := c.JobStatus(namespace, name)
+		if err != nil || result != JobRunning || time.Now().After(deadline) {
+			return result, err
+		}
+		time.Sleep(interval)
+	}
+}
+
+// ContainerExitCode returns the exit code of a container of a pod, and false while it has not terminated
+func (c *Client) ContainerExitCode(namespace, podName, container string) (int, bool, error) {
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get pod %s: %w", podName, err)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container && status.State.Terminated != nil {
+			return int(status.State.Terminated.ExitCode), true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// jobResult derives the outcome of a Job from its conditions
+func jobResult(job *batchv1.Job) JobResult {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return JobSucceeded
+		case batchv1.JobFailed:
+			return JobFailed
+		}
+	}
+	return JobRunning
+}
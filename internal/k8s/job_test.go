@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func createJobPod(name string, phase corev1.PodPhase, created time.Time) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "test-ns",
+			Labels:            map[string]string{"job-name": "restore"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestClient_CreateJobAndStatus(t *testing.T) {
+	client := &Client{clientset: fake.NewSimpleClientset()}
+
+	created, err := client.CreateJob("test-ns", &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "restore"}})
+	require.NoError(t, err)
+	assert.Equal(t, "restore", created.Name)
+
+	result, err := client.JobStatus("test-ns", "restore")
+	require.NoError(t, err)
+	assert.Equal(t, JobRunning, result)
+
+	_, err = client.JobStatus("test-ns", "missing")
+	assert.Error(t, err)
+}
+
+func TestClient_ListJobs(t *testing.T) {
+	client := &Client{clientset: fake.NewSimpleClientset(
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "test-ns", Labels: map[string]string{"app": "sts-backup"}}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test-ns"}},
+	)}
+
+	jobs, err := client.ListJobs("test-ns", "app=sts-backup")
+
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "restore", jobs[0].Name)
+}
+
+func TestJobResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []batchv1.JobCondition
+		expected   JobResult
+	}{
+		{name: "no conditions", expected: JobRunning},
+		{name: "complete", conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}, expected: JobSucceeded},
+		{name: "failed", conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}, expected: JobFailed},
+		{name: "condition not true", conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionFalse}}, expected: JobRunning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &batchv1.Job{Status: batchv1.JobStatus{Conditions: tt.conditions}}
+			assert.Equal(t, tt.expected, jobResult(job))
+		})
+	}
+}
+
+func TestClient_WaitForJobPod(t *testing.T) {
+	now := time.Now()
+	client := &Client{clientset: fake.NewSimpleClientset(
+		createJobPod("restore-old", corev1.PodFailed, now.Add(-time.Minute)),
+		createJobPod("restore-new", corev1.PodRunning, now),
+		createJobPod("restore-pending", corev1.PodPending, now.Add(time.Minute)),
+	)}
+
+	pod, err := client.WaitForJobPod("test-ns", "restore", time.Second, time.Millisecond)
+
+	require.NoError(t, err)
+	assert.Equal(t, "restore-new", pod, "the latest pod that started is followed")
+}
+
+func TestClient_WaitForJobPod_Timeout(t *testing.T) {
+	client := &Client{clientset: fake.NewSimpleClientset(createJobPod("restore-pending", corev1.PodPending, time.Now()))}
+
+	_, err := client.WaitForJobPod("test-ns", "restore", 0, time.Millisecond)
+
+	assert.ErrorIs(t, err, ErrJobPodTimeout)
+}
+
+func TestClient_StreamPodLogs(t *testing.T) {
+	client := &Client{clientset: fake.NewSimpleClientset(createJobPod("restore-new", corev1.PodRunning, time.Now()))}
+	var logs bytes.Buffer
+
+	require.NoError(t, client.StreamPodLogs("test-ns", "restore-new", "sts-backup", &logs))
+
+	assert.Equal(t, "fake logs", logs.String())
+}
+
+func TestClient_WaitForJob(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "test-ns"},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue}}},
+	}
+	client := &Client{clientset: fake.NewSimpleClientset(job, &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "test-ns"}})}
+
+	result, err := client.WaitForJob("test-ns", "restore", time.Second, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, JobFailed, result)
+
+	result, err = client.WaitForJob("test-ns", "running", 0, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, JobRunning, result, "a job still running after the timeout")
+}
+
+func TestClient_ContainerExitCode(t *testing.T) {
+	pod := createJobPod("restore-new", corev1.PodFailed, time.Now())
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "sts-backup",
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 6}},
+	}}
+	client := &Client{clientset: fake.NewSimpleClientset(pod, createJobPod("restore-running", corev1.PodRunning, time.Now()))}
+
+	code, terminated, err := client.ContainerExitCode("test-ns", "restore-new", "sts-backup")
+	require.NoError(t, err)
+	assert.True(t, terminated)
+	assert.Equal(t, 6, code)
+
+	_, terminated, err = client.ContainerExitCode("test-ns", "restore-running", "sts-backup")
+	require.NoError(t, err)
+	assert.False(t, terminated)
+}
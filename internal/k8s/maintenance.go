@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaintenanceFlag is a ConfigMap key that makes the receivers stop accepting data while it holds Value
+type MaintenanceFlag struct {
+	ConfigMap string
+	Key       string
+	Value     string
+}
+
+// MaintenanceState is the state of a maintenance flag before SetMaintenanceFlag, which ClearMaintenanceFlag returns to
+type MaintenanceState struct {
+	// created is set when the ConfigMap did not exist
+	created bool
+	// previous is the value of the key before, when it had one
+	previous    string
+	hadPrevious bool
+}
+
+// SetMaintenanceFlag sets the key of the flag to its value, creating the ConfigMap when it does not exist, and
+// returns the state before so the flag can be cleared with ClearMaintenanceFlag
+func (c *Client) SetMaintenanceFlag(namespace string, flag MaintenanceFlag) (*MaintenanceState, error) {
+	ctx := context.Background()
+	configMaps := c.clientset.CoreV1().ConfigMaps(namespace)
+
+	cm, err := configMaps.Get(ctx, flag.ConfigMap, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: flag.ConfigMap, Namespace: namespace},
+			Data:       map[string]string{flag.Key: flag.Value},
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to create maintenance ConfigMap %s: %w", flag.ConfigMap, err)
+		}
+		return &MaintenanceState{created: true}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get maintenance ConfigMap %s: %w", flag.ConfigMap, err)
+	}
+
+	state := &MaintenanceState{}
+	state.previous, state.hadPrevious = cm.Data[flag.Key]
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[flag.Key] = flag.Value
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to set maintenance flag %s in ConfigMap %s: %w", flag.Key, flag.ConfigMap, err)
+	}
+	return state, nil
+}
+
+// ClearMaintenanceFlag returns the flag to the state before SetMaintenanceFlag: the previous value is restored,
+// the key removed when it had none, and the ConfigMap deleted when SetMaintenanceFlag created it
+func (c *Client) ClearMaintenanceFlag(namespace string, flag MaintenanceFlag, state *MaintenanceState) error {
+	ctx := context.Background()
+	configMaps := c.clientset.CoreV1().ConfigMaps(namespace)
+
+	if state.created {
+		if err := configMaps.Delete(ctx, flag.ConfigMap, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete maintenance ConfigMap %s: %w", flag.ConfigMap, err)
+		}
+		return nil
+	}
+
+	cm, err := configMaps.Get(ctx, flag.ConfigMap, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get maintenance ConfigMap %s: %w", flag.ConfigMap, err)
+	}
+	if state.hadPrevious {
+		cm.Data[flag.Key] = state.previous
+	} else {
+		delete(cm.Data, flag.Key)
+	}
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to clear maintenance flag %s in ConfigMap %s: %w", flag.Key, flag.ConfigMap, err)
+	}
+	return nil
+}
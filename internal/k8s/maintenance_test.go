@@ -0,0 +1,62 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testMaintenanceFlag = MaintenanceFlag{ConfigMap: "receiver-config", Key: "maintenance", Value: "true"}
+
+func TestClient_MaintenanceFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     map[string]string
+		expected map[string]string
+	}{
+		{name: "previous value restored", data: map[string]string{"maintenance": "false", "other": "x"}, expected: map[string]string{"maintenance": "false", "other": "x"}},
+		{name: "key removed", data: map[string]string{"other": "x"}, expected: map[string]string{"other": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeClient := fake.NewSimpleClientset(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "receiver-config", Namespace: "test-ns"},
+				Data:       tt.data,
+			})
+			client := &Client{clientset: fakeClient}
+
+			state, err := client.SetMaintenanceFlag("test-ns", testMaintenanceFlag)
+			require.NoError(t, err)
+			cm, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), "receiver-config", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "true", cm.Data["maintenance"])
+			assert.Equal(t, "x", cm.Data["other"])
+
+			require.NoError(t, client.ClearMaintenanceFlag("test-ns", testMaintenanceFlag, state))
+			cm, err = fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), "receiver-config", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cm.Data)
+		})
+	}
+}
+
+func TestClient_MaintenanceFlag_CreatesConfigMap(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	client := &Client{clientset: fakeClient}
+
+	state, err := client.SetMaintenanceFlag("test-ns", testMaintenanceFlag)
+	require.NoError(t, err)
+	cm, err := fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), "receiver-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"maintenance": "true"}, cm.Data)
+
+	require.NoError(t, client.ClearMaintenanceFlag("test-ns", testMaintenanceFlag, state))
+	_, err = fakeClient.CoreV1().ConfigMaps("test-ns").Get(context.Background(), "receiver-config", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
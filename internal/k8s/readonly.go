@@ -0,0 +1,44 @@
+package k8s
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/readonly"
+	"k8s.io/client-go/rest"
+)
+
+// readOnlyCreates are the resources that are created to ask the API server something, without storing anything, such
+// as the ServiceAccount tokens Elasticsearch is authenticated with
+var readOnlyCreates = []string{"/selfsubjectreviews", "/selfsubjectaccessreviews", "/selfsubjectrulesreviews", "/token"}
+
+// WithReadOnly refuses every request that could change the cluster, such as scaling, writing ConfigMaps or exec in
+// pods, with an error matching readonly.ErrReadOnly. Port-forwards are allowed, so services can still be read
+// through them. It is a no-op unless enabled.
+func WithReadOnly(enabled bool) Option {
+	return func(config *rest.Config) error {
+		if enabled {
+			config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+				return &readonly.Transport{Next: rt, Allow: readOnlyRequest}
+			})
+		}
+		return nil
+	}
+}
+
+// readOnlyRequest reports whether a request with a method other than GET only reads: opening a port-forward,
+// reviewing the permissions of the client or requesting a token
+func readOnlyRequest(req *http.Request) bool {
+	if req.Method != http.MethodPost {
+		return false
+	}
+	if strings.HasSuffix(req.URL.Path, "/portforward") {
+		return true
+	}
+	for _, suffix := range readOnlyCreates {
+		if strings.HasSuffix(req.URL.Path, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/readonly"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func TestWithReadOnly(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind": "ConfigMapList", "apiVersion": "v1", "items": []}`))
+	}))
+	defer server.Close()
+
+	client, err := newClient(&rest.Config{Host: server.URL}, "", false, []Option{WithReadOnly(true)})
+	require.NoError(t, err)
+	configMaps := client.Clientset().CoreV1().ConfigMaps("sts")
+
+	_, err = configMaps.List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+
+	err = configMaps.Delete(context.Background(), "backup-config", metav1.DeleteOptions{})
+	assert.ErrorIs(t, err, readonly.ErrReadOnly)
+	assert.Equal(t, []string{"GET /api/v1/namespaces/sts/configmaps"}, received)
+}
+
+func TestReadOnlyRequest(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected bool
+	}{
+		{method: http.MethodPost, path: "/api/v1/namespaces/sts/pods/es-0/portforward", expected: true},
+		{method: http.MethodPost, path: "/apis/authentication.k8s.io/v1/selfsubjectreviews", expected: true},
+		{method: http.MethodPost, path: "/api/v1/namespaces/sts/serviceaccounts/sts-backup/token", expected: true},
+		{method: http.MethodPost, path: "/api/v1/namespaces/sts/pods/es-0/exec"},
+		{method: http.MethodPost, path: "/api/v1/namespaces/sts/configmaps"},
+		{method: http.MethodPatch, path: "/apis/apps/v1/namespaces/sts/statefulsets/es/scale"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, "https://api.example.com"+tt.path, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expected, readOnlyRequest(req))
+		})
+	}
+}
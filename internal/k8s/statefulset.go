@@ -0,0 +1,139 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrScaleTimeout is returned when a StatefulSet does not reach its replica count in time
+var ErrScaleTimeout = errors.New("timed out waiting for statefulset")
+
+// StatefulSetScale holds the name and original replica count of a StatefulSet
+type StatefulSetScale struct {
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+// ScaleDownStatefulSets scales down the StatefulSets matching a label selector to 0 replicas, like
+// ScaleDownDeployments, recording the original replica count in the OriginalReplicasAnnotation.
+// Returns the StatefulSets with their original replica counts, also those scaled down before an error.
+func (c *Client) ScaleDownStatefulSets(namespace, labelSelector string) ([]StatefulSetScale, error) {
+	ctx := context.Background()
+
+	statefulSets, err := c.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	scaled := []StatefulSetScale{}
+	for i := range statefulSets.Items {
+		sts := &statefulSets.Items[i]
+		originalReplicas := statefulSetReplicas(sts)
+		if annotated, ok := annotatedReplicas(sts); ok {
+			originalReplicas = annotated
+		}
+		scaled = append(scaled, StatefulSetScale{Name: sts.Name, Replicas: originalReplicas})
+
+		if originalReplicas > 0 {
+			replicas := int32(0)
+			sts.Spec.Replicas = &replicas
+			if sts.Annotations == nil {
+				sts.Annotations = map[string]string{}
+			}
+			sts.Annotations[OriginalReplicasAnnotation] = strconv.Itoa(int(originalReplicas))
+
+			if _, err := c.clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{}); err != nil {
+				return scaled, fmt.Errorf("failed to scale down statefulset %s: %w", sts.Name, err)
+			}
+		}
+	}
+
+	return scaled, nil
+}
+
+// ScaleUpStatefulSet restores a StatefulSet to its original replica count. The OriginalReplicasAnnotation takes
+// precedence over the given count and is removed.
+func (c *Client) ScaleUpStatefulSet(namespace string, scale StatefulSetScale) error {
+	ctx := context.Background()
+
+	sts, err := c.clientset.AppsV1().StatefulSets(namespace).Get(ctx, scale.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get statefulset %s: %w", scale.Name, err)
+	}
+
+	replicas := scale.Replicas
+	if annotated, ok := annotatedReplicas(sts); ok {
+		replicas = annotated
+	}
+	sts.Spec.Replicas = &replicas
+	delete(sts.Annotations, OriginalReplicasAnnotation)
+
+	if _, err := c.clientset.AppsV1().StatefulSets(namespace).Update(ctx, sts, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to scale up statefulset %s: %w", sts.Name, err)
+	}
+	return nil
+}
+
+// ScaleUpAnnotatedStatefulSets scales the StatefulSets matching a label selector that carry the
+// OriginalReplicasAnnotation back up, like ScaleUpAnnotatedDeployments. Returns the StatefulSets that were scaled up.
+func (c *Client) ScaleUpAnnotatedStatefulSets(namespace, labelSelector string) ([]StatefulSetScale, error) {
+	statefulSets, err := c.clientset.AppsV1().StatefulSets(namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+
+	scaled := []StatefulSetScale{}
+	for i := range statefulSets.Items {
+		replicas, ok := annotatedReplicas(&statefulSets.Items[i])
+		if !ok {
+			continue
+		}
+		scale := StatefulSetScale{Name: statefulSets.Items[i].Name, Replicas: replicas}
+		if err := c.ScaleUpStatefulSet(namespace, scale); err != nil {
+			return scaled, err
+		}
+		scaled = append(scaled, scale)
+	}
+
+	return scaled, nil
+}
+
+// WaitForStatefulSetScale polls a StatefulSet every interval until it has replicas pods, all Ready, or returns
+// ErrScaleTimeout after timeout. Waiting for 0 replicas waits until all pods have terminated, so ingest nodes
+// have flushed what they buffered.
+func (c *Client) WaitForStatefulSetScale(namespace, name string, replicas int32, timeout, interval time.Duration) error {
+	ctx := context.Background()
+	deadline := time.Now().Add(timeout)
+
+	for {
+		sts, err := c.clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get statefulset %s: %w", name, err)
+		}
+		if statefulSetScaled(sts, replicas) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w %s to reach %d replica(s) (%d running, %d ready)",
+				ErrScaleTimeout, name, replicas, sts.Status.Replicas, sts.Status.ReadyReplicas)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// statefulSetScaled reports whether the pods of sts match replicas, all Ready
+func statefulSetScaled(sts *appsv1.StatefulSet, replicas int32) bool {
+	return sts.Status.ObservedGeneration >= sts.Generation &&
+		sts.Status.Replicas == replicas &&
+		sts.Status.ReadyReplicas == replicas
+}
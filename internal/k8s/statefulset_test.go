@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func createStatefulSet(name string, labels map[string]string, replicas, running int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-ns", Labels: labels},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status:     appsv1.StatefulSetStatus{Replicas: running, ReadyReplicas: running},
+	}
+}
+
+func TestClient_ScaleDownAndUpStatefulSets(t *testing.T) {
+	labels := map[string]string{"role": "ingest"}
+	fakeClient := fake.NewSimpleClientset(
+		createStatefulSet("es-ingest", labels, 2, 2),
+		createStatefulSet("es-master", map[string]string{"role": "master"}, 3, 3),
+	)
+	client := &Client{clientset: fakeClient}
+
+	scaled, err := client.ScaleDownStatefulSets("test-ns", "role=ingest")
+
+	require.NoError(t, err)
+	assert.Equal(t, []StatefulSetScale{{Name: "es-ingest", Replicas: 2}}, scaled)
+	sts, err := fakeClient.AppsV1().StatefulSets("test-ns").Get(context.Background(), "es-ingest", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), *sts.Spec.Replicas)
+	assert.Equal(t, "2", sts.Annotations[OriginalReplicasAnnotation])
+	master, err := fakeClient.AppsV1().StatefulSets("test-ns").Get(context.Background(), "es-master", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *master.Spec.Replicas, "statefulsets outside the selector are left alone")
+
+	// A second run keeps the annotated count of the StatefulSet that is already scaled down
+	scaled, err = client.ScaleDownStatefulSets("test-ns", "role=ingest")
+	require.NoError(t, err)
+	assert.Equal(t, []StatefulSetScale{{Name: "es-ingest", Replicas: 2}}, scaled)
+
+	require.NoError(t, client.ScaleUpStatefulSet("test-ns", StatefulSetScale{Name: "es-ingest", Replicas: 0}))
+	sts, err = fakeClient.AppsV1().StatefulSets("test-ns").Get(context.Background(), "es-ingest", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), *sts.Spec.Replicas, "the annotation takes precedence")
+	assert.NotContains(t, sts.Annotations, OriginalReplicasAnnotation)
+}
+
+func TestClient_ScaleUpAnnotatedStatefulSets(t *testing.T) {
+	labels := map[string]string{"role": "ingest"}
+	annotated := createStatefulSet("es-ingest", labels, 0, 0)
+	annotated.Annotations = map[string]string{OriginalReplicasAnnotation: "2"}
+	fakeClient := fake.NewSimpleClientset(annotated, createStatefulSet("es-coordinating", labels, 1, 1))
+	client := &Client{clientset: fakeClient}
+
+	scaled, err := client.ScaleUpAnnotatedStatefulSets("test-ns", "role=ingest")
+
+	require.NoError(t, err)
+	assert.Equal(t, []StatefulSetScale{{Name: "es-ingest", Replicas: 2}}, scaled)
+}
+
+func TestClient_WaitForStatefulSetScale(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset(createStatefulSet("es-ingest", nil, 0, 1))
+	client := &Client{clientset: fakeClient}
+
+	err := client.WaitForStatefulSetScale("test-ns", "es-ingest", 0, 20*time.Millisecond, 5*time.Millisecond)
+	require.ErrorIs(t, err, ErrScaleTimeout)
+	assert.Contains(t, err.Error(), "1 running")
+
+	assert.NoError(t, client.WaitForStatefulSetScale("test-ns", "es-ingest", 1, time.Second, 5*time.Millisecond))
+
+	err = client.WaitForStatefulSetScale("test-ns", "missing", 0, time.Second, 5*time.Millisecond)
+	assert.ErrorContains(t, err, "failed to get statefulset missing")
+}
@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// serviceAccountTokenExpiration is the lifetime of the tokens requested with the TokenRequest API
+	serviceAccountTokenExpiration = time.Hour
+	// tokenRefreshMargin is how long before it expires a token is replaced, so requests never carry an expired one
+	tokenRefreshMargin = 5 * time.Minute
+	// tokenFileRereadInterval is how often the mounted token is read again, since the kubelet rotates it
+	tokenFileRereadInterval = time.Minute
+)
+
+// ServiceAccountTokenSource returns a function returning a token of a ServiceAccount, e.g. to authenticate to a
+// service that accepts Kubernetes tokens. In a pod it is the token mounted at tokenFile, the token of the pod's own
+// ServiceAccount; outside of the cluster a token of serviceAccount in namespace is requested with the TokenRequest
+// API. Tokens are cached until shortly before they expire, or a minute for the mounted token.
+func (c *Client) ServiceAccountTokenSource(namespace, serviceAccount, tokenFile string, audiences []string) func() (string, error) {
+	fetch := func() (string, time.Time, error) {
+		return c.requestServiceAccountToken(namespace, serviceAccount, audiences)
+	}
+	if InCluster() {
+		fetch = func() (string, time.Time, error) {
+			return readTokenFile(tokenFile)
+		}
+	} else if serviceAccount == "" {
+		fetch = func() (string, time.Time, error) {
+			return "", time.Time{}, fmt.Errorf("a ServiceAccount to request a token for is required outside of the cluster")
+		}
+	}
+
+	cache := &tokenCache{fetch: fetch, now: time.Now}
+	return cache.Token
+}
+
+// requestServiceAccountToken requests a token of a ServiceAccount with the TokenRequest API and returns it with the
+// time it expires
+func (c *Client) requestServiceAccountToken(namespace, serviceAccount string, audiences []string) (string, time.Time, error) {
+	expiration := int64(serviceAccountTokenExpiration.Seconds())
+	request := &authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{Audiences: audiences, ExpirationSeconds: &expiration}}
+	response, err := c.clientset.CoreV1().ServiceAccounts(namespace).CreateToken(context.Background(), serviceAccount, request, metav1.CreateOptions{})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request a token for ServiceAccount %s: %w", serviceAccount, err)
+	}
+	return response.Status.Token, response.Status.ExpirationTimestamp.Time, nil
+}
+
+// readTokenFile reads a mounted token, which is cached for tokenFileRereadInterval
+func readTokenFile(path string) (string, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to read ServiceAccount token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", time.Time{}, fmt.Errorf("ServiceAccount token file %s is empty", path)
+	}
+	return token, time.Now().Add(tokenFileRereadInterval + tokenRefreshMargin), nil
+}
+
+// tokenCache holds a token until shortly before it expires
+type tokenCache struct {
+	fetch   func() (string, time.Time, error)
+	now     func() time.Time
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Token returns the cached token, fetching a new one when it is about to expire
+func (t *tokenCache) Token() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && t.now().Add(tokenRefreshMargin).Before(t.expires) {
+		return t.token, nil
+	}
+	token, expires, err := t.fetch()
+	if err != nil {
+		return "", err
+	}
+	t.token, t.expires = token, expires
+	return token, nil
+}
@@ -0,0 +1,84 @@
+package k8s
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTokenCache(t *testing.T) {
+	now := time.Date(2025, 1, 15, 3, 0, 0, 0, time.UTC)
+	fetched := 0
+	cache := &tokenCache{
+		now: func() time.Time { return now },
+		fetch: func() (string, time.Time, error) {
+			fetched++
+			return fmt.Sprintf("token-%d", fetched), now.Add(time.Hour), nil
+		},
+	}
+
+	token, err := cache.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	now = now.Add(50 * time.Minute)
+	token, err = cache.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token, "the token is cached while it is valid")
+
+	now = now.Add(6 * time.Minute)
+	token, err = cache.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token, "the token is replaced shortly before it expires")
+}
+
+func TestClient_ServiceAccountTokenSource_TokenRequest(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	fakeClient := fake.NewSimpleClientset()
+	var requested *authenticationv1.TokenRequest
+	fakeClient.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		requested = action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		return true, &authenticationv1.TokenRequest{Status: authenticationv1.TokenRequestStatus{
+			Token:               "requested-token",
+			ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+		}}, nil
+	})
+	client := &Client{clientset: fakeClient}
+
+	token, err := client.ServiceAccountTokenSource("test-ns", "sts-backup", "/nonexistent", []string{"elasticsearch"})()
+
+	require.NoError(t, err)
+	assert.Equal(t, "requested-token", token)
+	require.NotNil(t, requested)
+	assert.Equal(t, []string{"elasticsearch"}, requested.Spec.Audiences)
+
+	_, err = client.ServiceAccountTokenSource("test-ns", "", "/nonexistent", nil)()
+	assert.ErrorContains(t, err, "ServiceAccount to request a token for is required")
+}
+
+func TestClient_ServiceAccountTokenSource_InCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("mounted-token\n"), 0o600))
+	client := &Client{clientset: fake.NewSimpleClientset()}
+
+	token, err := client.ServiceAccountTokenSource("test-ns", "", tokenFile, nil)()
+	require.NoError(t, err)
+	assert.Equal(t, "mounted-token", token)
+
+	_, err = client.ServiceAccountTokenSource("test-ns", "", filepath.Join(t.TempDir(), "missing"), nil)()
+	assert.ErrorContains(t, err, "failed to read ServiceAccount token")
+}
@@ -0,0 +1,342 @@
+// Package kafka reads and recreates the metadata of a Kafka cluster: topics with their configuration, ACLs and
+// the cluster identity. Kafka does not keep this metadata anywhere a storage backup covers, so after a full
+// platform disaster recovery the messaging layer is rebuilt from a metadata snapshot. The Kafka command line tools
+// are run in a broker pod, so no Kafka client or network access to the brokers is needed.
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Cluster modes
+const (
+	ModeKRaft     = "kraft"
+	ModeZookeeper = "zookeeper"
+)
+
+// ErrSecurityDisabled is returned when ACLs are listed on a cluster without an authorizer
+var ErrSecurityDisabled = errors.New("no authorizer is configured on the brokers")
+
+// Runner runs a Kafka command line tool, such as kafka-topics.sh, and returns what it wrote to stdout
+type Runner interface {
+	Run(command []string) (string, error)
+}
+
+// Metadata is the metadata of a cluster that is snapshotted and restored
+type Metadata struct {
+	Cluster Cluster `json:"cluster"`
+	Topics  []Topic `json:"topics"`
+	ACLs    []ACL   `json:"acls"`
+}
+
+// Cluster identifies a cluster. It is recorded for reference only: the identity of a cluster is created when its
+// storage is formatted and cannot be restored.
+type Cluster struct {
+	ID   string `json:"id"`
+	Mode string `json:"mode"`
+	// Status is what the cluster reports about its controllers: the KRaft quorum status, or the broker IDs
+	// registered in Zookeeper
+	Status map[string]string `json:"status,omitempty"`
+}
+
+// Topic is a topic with the configuration set on it; configuration left at the broker default is not included
+type Topic struct {
+	Name              string            `json:"name"`
+	Partitions        int               `json:"partitions"`
+	ReplicationFactor int               `json:"replicationFactor"`
+	Configs           map[string]string `json:"configs,omitempty"`
+}
+
+// ACL is an access control entry on a resource pattern
+type ACL struct {
+	ResourceType string `json:"resourceType"` // e.g. TOPIC, GROUP or CLUSTER
+	ResourceName string `json:"resourceName"`
+	PatternType  string `json:"patternType"` // LITERAL or PREFIXED
+	Principal    string `json:"principal"`   // e.g. User:alice
+	Host         string `json:"host"`
+	Operation    string `json:"operation"`  // e.g. READ or DESCRIBE_CONFIGS
+	Permission   string `json:"permission"` // ALLOW or DENY
+}
+
+// String describes the ACL in one line
+func (a ACL) String() string {
+	return fmt.Sprintf("%s %s %s on %s %s:%s from %s", a.Permission, a.Principal, a.Operation, strings.ToLower(a.PatternType),
+		a.ResourceType, a.ResourceName, a.Host)
+}
+
+// Client reads and changes the metadata of a cluster through the Kafka command line tools
+type Client struct {
+	runner           Runner
+	bootstrapServer  string
+	zookeeperConnect string
+}
+
+// NewClient creates a client running the tools with runner, connecting to bootstrapServer. zookeeperConnect is
+// the Zookeeper connection string of a Zookeeper-based cluster, or empty.
+func NewClient(runner Runner, bootstrapServer, zookeeperConnect string) *Client {
+	return &Client{runner: runner, bootstrapServer: bootstrapServer, zookeeperConnect: zookeeperConnect}
+}
+
+// Metadata reads the cluster identity, the topics and the ACLs. ACLs are empty when no authorizer is configured.
+func (c *Client) Metadata() (*Metadata, error) {
+	cluster, err := c.Cluster()
+	if err != nil {
+		return nil, err
+	}
+	topics, err := c.Topics()
+	if err != nil {
+		return nil, err
+	}
+	acls, err := c.ACLs()
+	if err != nil && !errors.Is(err, ErrSecurityDisabled) {
+		return nil, err
+	}
+	return &Metadata{Cluster: *cluster, Topics: topics, ACLs: acls}, nil
+}
+
+// Cluster reads the identity of the cluster. A cluster that reports a KRaft quorum runs in KRaft mode, any other
+// in Zookeeper mode.
+func (c *Client) Cluster() (*Cluster, error) {
+	out, err := c.runner.Run([]string{"kafka-metadata-quorum.sh", "--bootstrap-server", c.bootstrapServer, "describe", "--status"})
+	if err == nil {
+		status := parseKeyValues(out)
+		return &Cluster{ID: status["ClusterId"], Mode: ModeKRaft, Status: status}, nil
+	}
+
+	out, err = c.runner.Run([]string{"kafka-cluster.sh", "cluster-id", "--bootstrap-server", c.bootstrapServer})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the cluster ID: %w", err)
+	}
+	cluster := &Cluster{ID: parseKeyValues(out)["Cluster ID"], Mode: ModeZookeeper}
+	if c.zookeeperConnect != "" {
+		out, err := c.runner.Run([]string{"zookeeper-shell.sh", c.zookeeperConnect, "ls", "/brokers/ids"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the brokers in Zookeeper: %w", err)
+		}
+		cluster.Status = map[string]string{"BrokerIds": lastLine(out)}
+	}
+	return cluster, nil
+}
+
+// Topics describes the topics, except the internal ones, sorted by name
+func (c *Client) Topics() ([]Topic, error) {
+	out, err := c.runner.Run([]string{"kafka-topics.sh", "--bootstrap-server", c.bootstrapServer, "--describe", "--exclude-internal"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe topics: %w", err)
+	}
+	return parseTopics(out)
+}
+
+// ACLs lists the ACLs, or returns ErrSecurityDisabled when the brokers have no authorizer
+func (c *Client) ACLs() ([]ACL, error) {
+	out, err := c.runner.Run([]string{"kafka-acls.sh", "--bootstrap-server", c.bootstrapServer, "--list"})
+	if err != nil {
+		if strings.Contains(err.Error(), "SecurityDisabledException") {
+			return nil, ErrSecurityDisabled
+		}
+		return nil, fmt.Errorf("failed to list ACLs: %w", err)
+	}
+	return parseACLs(out), nil
+}
+
+// CreateTopic creates a topic with its partitions, replication factor and configuration
+func (c *Client) CreateTopic(topic Topic) error {
+	command := []string{"kafka-topics.sh", "--bootstrap-server", c.bootstrapServer, "--create", "--topic", topic.Name,
+		"--partitions", strconv.Itoa(topic.Partitions), "--replication-factor", strconv.Itoa(topic.ReplicationFactor)}
+	for _, key := range slices.Sorted(maps.Keys(topic.Configs)) {
+		command = append(command, "--config", key+"="+topic.Configs[key])
+	}
+	if _, err := c.runner.Run(command); err != nil {
+		return fmt.Errorf("failed to create topic %s: %w", topic.Name, err)
+	}
+	return nil
+}
+
+// AlterTopicConfigs sets configuration of a topic, leaving the other configuration unchanged
+func (c *Client) AlterTopicConfigs(name string, configs map[string]string) error {
+	command := []string{"kafka-configs.sh", "--bootstrap-server", c.bootstrapServer, "--alter", "--entity-type", "topics",
+		"--entity-name", name, "--add-config", formatConfigs(configs)}
+	if _, err := c.runner.Run(command); err != nil {
+		return fmt.Errorf("failed to alter the configuration of topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// IncreasePartitions raises the number of partitions of a topic; Kafka cannot lower it
+func (c *Client) IncreasePartitions(name string, partitions int) error {
+	command := []string{"kafka-topics.sh", "--bootstrap-server", c.bootstrapServer, "--alter", "--topic", name,
+		"--partitions", strconv.Itoa(partitions)}
+	if _, err := c.runner.Run(command); err != nil {
+		return fmt.Errorf("failed to increase the partitions of topic %s: %w", name, err)
+	}
+	return nil
+}
+
+// AddACL adds an ACL
+func (c *Client) AddACL(acl ACL) error {
+	resourceFlag, ok := aclResourceFlags[acl.ResourceType]
+	if !ok {
+		return fmt.Errorf("failed to add ACL %s: unsupported resource type %s", acl, acl.ResourceType)
+	}
+
+	permission := "allow"
+	if acl.Permission == "DENY" {
+		permission = "deny"
+	}
+	command := []string{"kafka-acls.sh", "--bootstrap-server", c.bootstrapServer, "--add",
+		"--" + permission + "-principal", acl.Principal, "--" + permission + "-host", acl.Host,
+		"--operation", pascalCase(acl.Operation), "--resource-pattern-type", strings.ToLower(acl.PatternType)}
+	// The cluster resource has no name
+	command = append(command, resourceFlag)
+	if acl.ResourceType != "CLUSTER" {
+		command = append(command, acl.ResourceName)
+	}
+
+	if _, err := c.runner.Run(command); err != nil {
+		return fmt.Errorf("failed to add ACL %s: %w", acl, err)
+	}
+	return nil
+}
+
+// aclResourceFlags maps the resource types of ACLs to the kafka-acls.sh flag selecting a resource of that type
+var aclResourceFlags = map[string]string{
+	"TOPIC":            "--topic",
+	"GROUP":            "--group",
+	"CLUSTER":          "--cluster",
+	"TRANSACTIONAL_ID": "--transactional-id",
+	"DELEGATION_TOKEN": "--delegation-token",
+	"USER":             "--user-principal",
+}
+
+// parseTopics parses the output of kafka-topics.sh --describe: a line per topic, followed by an indented line
+// per partition, with tab-separated "Key: value" fields. Older versions leave out the space after the colon.
+func parseTopics(out string) ([]Topic, error) {
+	var topics []Topic
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "Topic:") {
+			continue
+		}
+		fields := make(map[string]string)
+		for _, field := range strings.Split(line, "\t") {
+			key, value, _ := strings.Cut(field, ":")
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+
+		partitions, err := strconv.Atoi(fields["PartitionCount"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the partition count of topic %s: %w", fields["Topic"], err)
+		}
+		replicationFactor, err := strconv.Atoi(fields["ReplicationFactor"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the replication factor of topic %s: %w", fields["Topic"], err)
+		}
+		topics = append(topics, Topic{
+			Name:              fields["Topic"],
+			Partitions:        partitions,
+			ReplicationFactor: replicationFactor,
+			Configs:           parseConfigs(fields["Configs"]),
+		})
+	}
+	slices.SortFunc(topics, func(a, b Topic) int { return strings.Compare(a.Name, b.Name) })
+	return topics, nil
+}
+
+// parseConfigs parses comma-separated key=value pairs. Values may contain commas themselves, e.g.
+// cleanup.policy=compact,delete, so a part without = belongs to the value before it.
+func parseConfigs(value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+	configs := make(map[string]string)
+	var last string
+	for _, part := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok && last != "" {
+			configs[last] += "," + part
+			continue
+		}
+		configs[key] = val
+		last = key
+	}
+	return configs
+}
+
+// formatConfigs formats configuration for kafka-configs.sh --add-config, which takes values containing commas
+// in square brackets
+func formatConfigs(configs map[string]string) string {
+	parts := make([]string, 0, len(configs))
+	for _, key := range slices.Sorted(maps.Keys(configs)) {
+		value := configs[key]
+		if strings.Contains(value, ",") {
+			value = "[" + value + "]"
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, ",")
+}
+
+var (
+	aclResourcePattern = regexp.MustCompile(`ResourcePattern\(resourceType=(\w+), name=(.*), patternType=(\w+)\)`)
+	aclEntryPattern    = regexp.MustCompile(`\(principal=(.*), host=(.*), operation=(\w+), permissionType=(\w+)\)`)
+)
+
+// parseACLs parses the output of kafka-acls.sh --list: a line per resource pattern, followed by an indented line
+// per access control entry
+func parseACLs(out string) []ACL {
+	var acls []ACL
+	var resource []string
+	for _, line := range strings.Split(out, "\n") {
+		if match := aclResourcePattern.FindStringSubmatch(line); match != nil {
+			resource = match
+			continue
+		}
+		match := aclEntryPattern.FindStringSubmatch(line)
+		if match == nil || resource == nil {
+			continue
+		}
+		acls = append(acls, ACL{
+			ResourceType: resource[1],
+			ResourceName: resource[2],
+			PatternType:  resource[3],
+			Principal:    match[1],
+			Host:         match[2],
+			Operation:    match[3],
+			Permission:   match[4],
+		})
+	}
+	return acls
+}
+
+// parseKeyValues parses "Key: value" lines
+func parseKeyValues(out string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if key, value, ok := strings.Cut(line, ":"); ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
+
+// lastLine returns the last non-empty line of out
+func lastLine(out string) string {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// pascalCase turns an operation as listed, e.g. DESCRIBE_CONFIGS, into the form kafka-acls.sh takes, DescribeConfigs
+func pascalCase(value string) string {
+	words := strings.Split(strings.ToLower(value), "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,105 @@
+package kafka
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner returns canned output per tool and records the commands it ran
+type fakeRunner struct {
+	outputs  map[string]string
+	errs     map[string]error
+	commands [][]string
+}
+
+func (f *fakeRunner) Run(command []string) (string, error) {
+	f.commands = append(f.commands, command)
+	return f.outputs[command[0]], f.errs[command[0]]
+}
+
+const topicsOutput = `Topic: sts_topo_process_agents	TopicId: 2tCV8K1gRLeZ7w3eQ6GmfA	PartitionCount: 10	ReplicationFactor: 3	Configs: cleanup.policy=compact,delete,retention.ms=86400000
+	Topic: sts_topo_process_agents	Partition: 0	Leader: 0	Replicas: 0,1,2	Isr: 0,1,2
+Topic:sts_internal_events	PartitionCount:1	ReplicationFactor:1	Configs:
+	Topic: sts_internal_events	Partition: 0	Leader: 0	Replicas: 0	Isr: 0
+`
+
+const aclsOutput = "Current ACLs for resource `ResourcePattern(resourceType=TOPIC, name=sts_, patternType=PREFIXED)`: \n" +
+	" \t(principal=User:receiver, host=*, operation=WRITE, permissionType=ALLOW)\n" +
+	" \t(principal=User:guest, host=10.0.0.1, operation=DESCRIBE_CONFIGS, permissionType=DENY) \n\n" +
+	"Current ACLs for resource `ResourcePattern(resourceType=CLUSTER, name=kafka-cluster, patternType=LITERAL)`: \n" +
+	" \t(principal=User:admin, host=*, operation=ALL, permissionType=ALLOW)\n"
+
+func TestClient_Metadata_KRaft(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{
+		"kafka-metadata-quorum.sh": "ClusterId:              MkU3OEVBNTcwNTJENDM2Qg\nLeaderId:               1\nCurrentVoters:          [1,2,3]\n",
+		"kafka-topics.sh":          topicsOutput,
+		"kafka-acls.sh":            aclsOutput,
+	}}
+
+	metadata, err := NewClient(runner, "localhost:9092", "").Metadata()
+
+	require.NoError(t, err)
+	assert.Equal(t, "MkU3OEVBNTcwNTJENDM2Qg", metadata.Cluster.ID)
+	assert.Equal(t, ModeKRaft, metadata.Cluster.Mode)
+	assert.Equal(t, "[1,2,3]", metadata.Cluster.Status["CurrentVoters"])
+	assert.Equal(t, []Topic{
+		{Name: "sts_internal_events", Partitions: 1, ReplicationFactor: 1},
+		{Name: "sts_topo_process_agents", Partitions: 10, ReplicationFactor: 3,
+			Configs: map[string]string{"cleanup.policy": "compact,delete", "retention.ms": "86400000"}},
+	}, metadata.Topics)
+	assert.Equal(t, []ACL{
+		{ResourceType: "TOPIC", ResourceName: "sts_", PatternType: "PREFIXED", Principal: "User:receiver", Host: "*", Operation: "WRITE", Permission: "ALLOW"},
+		{ResourceType: "TOPIC", ResourceName: "sts_", PatternType: "PREFIXED", Principal: "User:guest", Host: "10.0.0.1", Operation: "DESCRIBE_CONFIGS", Permission: "DENY"},
+		{ResourceType: "CLUSTER", ResourceName: "kafka-cluster", PatternType: "LITERAL", Principal: "User:admin", Host: "*", Operation: "ALL", Permission: "ALLOW"},
+	}, metadata.ACLs)
+}
+
+func TestClient_Metadata_Zookeeper(t *testing.T) {
+	runner := &fakeRunner{
+		outputs: map[string]string{
+			"kafka-cluster.sh":   "Cluster ID: 3Db5QLSqSZieL3rJBUUegA\n",
+			"zookeeper-shell.sh": "Connecting to zookeeper:2181\n\nWATCHER::\n\n[0, 1, 2]\n",
+		},
+		errs: map[string]error{
+			"kafka-metadata-quorum.sh": errors.New("UnsupportedVersionException"),
+			"kafka-acls.sh":            errors.New("command failed: SecurityDisabledException: No Authorizer is configured"),
+		},
+	}
+
+	metadata, err := NewClient(runner, "localhost:9092", "zookeeper:2181").Metadata()
+
+	require.NoError(t, err)
+	assert.Equal(t, Cluster{ID: "3Db5QLSqSZieL3rJBUUegA", Mode: ModeZookeeper, Status: map[string]string{"BrokerIds": "[0, 1, 2]"}}, metadata.Cluster)
+	assert.Empty(t, metadata.ACLs, "ACLs are empty without an authorizer")
+}
+
+func TestClient_Changes(t *testing.T) {
+	runner := &fakeRunner{}
+	client := NewClient(runner, "localhost:9092", "")
+
+	require.NoError(t, client.CreateTopic(Topic{Name: "sts_events", Partitions: 3, ReplicationFactor: 2,
+		Configs: map[string]string{"retention.ms": "1000", "cleanup.policy": "compact,delete"}}))
+	require.NoError(t, client.AlterTopicConfigs("sts_events", map[string]string{"retention.ms": "1000", "cleanup.policy": "compact,delete"}))
+	require.NoError(t, client.IncreasePartitions("sts_events", 6))
+	require.NoError(t, client.AddACL(ACL{ResourceType: "GROUP", ResourceName: "sts", PatternType: "LITERAL", Principal: "User:guest",
+		Host: "*", Operation: "DESCRIBE_CONFIGS", Permission: "DENY"}))
+	require.NoError(t, client.AddACL(ACL{ResourceType: "CLUSTER", ResourceName: "kafka-cluster", PatternType: "LITERAL", Principal: "User:admin",
+		Host: "*", Operation: "ALL", Permission: "ALLOW"}))
+	assert.Error(t, client.AddACL(ACL{ResourceType: "UNKNOWN"}))
+
+	commands := make([]string, 0, len(runner.commands))
+	for _, command := range runner.commands {
+		commands = append(commands, strings.Join(command, " "))
+	}
+	assert.Equal(t, []string{
+		"kafka-topics.sh --bootstrap-server localhost:9092 --create --topic sts_events --partitions 3 --replication-factor 2 --config cleanup.policy=compact,delete --config retention.ms=1000",
+		"kafka-configs.sh --bootstrap-server localhost:9092 --alter --entity-type topics --entity-name sts_events --add-config cleanup.policy=[compact,delete],retention.ms=1000",
+		"kafka-topics.sh --bootstrap-server localhost:9092 --alter --topic sts_events --partitions 6",
+		"kafka-acls.sh --bootstrap-server localhost:9092 --add --deny-principal User:guest --deny-host * --operation DescribeConfigs --resource-pattern-type literal --group sts",
+		"kafka-acls.sh --bootstrap-server localhost:9092 --add --allow-principal User:admin --allow-host * --operation All --resource-pattern-type literal --cluster",
+	}, commands)
+}
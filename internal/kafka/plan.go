@@ -0,0 +1,108 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Plan is what restoring a metadata snapshot changes in a cluster. Restoring only adds: topics, configuration
+// and ACLs that are not in the snapshot are left in place.
+type Plan struct {
+	CreateTopics []Topic
+	AlterTopics  []TopicChange
+	AddACLs      []ACL
+	// Warnings are differences that cannot be restored, such as a lower partition count in the snapshot
+	Warnings []string
+}
+
+// TopicChange is a change to an existing topic
+type TopicChange struct {
+	Name string
+	// Configs are the configuration values to set
+	Configs map[string]string
+	// Partitions is the partition count to increase to, 0 to leave it
+	Partitions int
+}
+
+// Empty reports whether the plan changes nothing
+func (p *Plan) Empty() bool {
+	return len(p.CreateTopics) == 0 && len(p.AlterTopics) == 0 && len(p.AddACLs) == 0
+}
+
+// NewPlan compares a metadata snapshot with the current metadata of a cluster
+func NewPlan(snapshot, current *Metadata) *Plan {
+	plan := &Plan{}
+
+	currentTopics := make(map[string]Topic, len(current.Topics))
+	for _, topic := range current.Topics {
+		currentTopics[topic.Name] = topic
+	}
+	for _, topic := range snapshot.Topics {
+		existing, ok := currentTopics[topic.Name]
+		if !ok {
+			plan.CreateTopics = append(plan.CreateTopics, topic)
+			continue
+		}
+
+		change := TopicChange{Name: topic.Name, Configs: map[string]string{}}
+		for key, value := range topic.Configs {
+			if existing.Configs[key] != value {
+				change.Configs[key] = value
+			}
+		}
+		switch {
+		case topic.Partitions > existing.Partitions:
+			change.Partitions = topic.Partitions
+		case topic.Partitions < existing.Partitions:
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("topic %s has %d partitions, more than the %d of the snapshot; Kafka cannot lower it",
+				topic.Name, existing.Partitions, topic.Partitions))
+		}
+		if topic.ReplicationFactor != existing.ReplicationFactor {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("topic %s has replication factor %d instead of the %d of the snapshot; reassign its partitions to change it",
+				topic.Name, existing.ReplicationFactor, topic.ReplicationFactor))
+		}
+		if len(change.Configs) > 0 || change.Partitions > 0 {
+			plan.AlterTopics = append(plan.AlterTopics, change)
+		}
+	}
+
+	for _, acl := range snapshot.ACLs {
+		if !slices.Contains(current.ACLs, acl) {
+			plan.AddACLs = append(plan.AddACLs, acl)
+		}
+	}
+	return plan
+}
+
+// Apply makes the changes of the plan. A failed change does not stop the others; all failures are returned
+// together.
+func (c *Client) Apply(plan *Plan) error {
+	var failed []error
+	for _, topic := range plan.CreateTopics {
+		if err := c.CreateTopic(topic); err != nil {
+			failed = append(failed, err)
+		}
+	}
+	for _, change := range plan.AlterTopics {
+		if len(change.Configs) > 0 {
+			if err := c.AlterTopicConfigs(change.Name, change.Configs); err != nil {
+				failed = append(failed, err)
+			}
+		}
+		if change.Partitions > 0 {
+			if err := c.IncreasePartitions(change.Name, change.Partitions); err != nil {
+				failed = append(failed, err)
+			}
+		}
+	}
+	for _, acl := range plan.AddACLs {
+		if err := c.AddACL(acl); err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to make %d change(s): %w", len(failed), errors.Join(failed...))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPlan(t *testing.T) {
+	reader := ACL{ResourceType: "TOPIC", ResourceName: "sts_", PatternType: "PREFIXED", Principal: "User:reader", Host: "*", Operation: "READ", Permission: "ALLOW"}
+	writer := ACL{ResourceType: "TOPIC", ResourceName: "sts_", PatternType: "PREFIXED", Principal: "User:writer", Host: "*", Operation: "WRITE", Permission: "ALLOW"}
+	snapshot := &Metadata{
+		Topics: []Topic{
+			{Name: "sts_events", Partitions: 3, ReplicationFactor: 1, Configs: map[string]string{"retention.ms": "1000"}},
+			{Name: "sts_metrics", Partitions: 6, ReplicationFactor: 3, Configs: map[string]string{"retention.ms": "2000", "cleanup.policy": "delete"}},
+			{Name: "sts_traces", Partitions: 2, ReplicationFactor: 1},
+			{Name: "sts_logs", Partitions: 2, ReplicationFactor: 1},
+		},
+		ACLs: []ACL{reader, writer},
+	}
+	current := &Metadata{
+		Topics: []Topic{
+			{Name: "sts_metrics", Partitions: 3, ReplicationFactor: 1, Configs: map[string]string{"retention.ms": "2000", "cleanup.policy": "compact"}},
+			{Name: "sts_traces", Partitions: 4, ReplicationFactor: 1},
+			{Name: "sts_logs", Partitions: 2, ReplicationFactor: 1, Configs: map[string]string{"retention.ms": "5000"}},
+			{Name: "sts_unknown", Partitions: 1, ReplicationFactor: 1},
+		},
+		ACLs: []ACL{reader},
+	}
+
+	plan := NewPlan(snapshot, current)
+
+	assert.Equal(t, []Topic{snapshot.Topics[0]}, plan.CreateTopics)
+	assert.Equal(t, []TopicChange{{Name: "sts_metrics", Configs: map[string]string{"cleanup.policy": "delete"}, Partitions: 6}}, plan.AlterTopics,
+		"configuration that is not in the snapshot is left in place")
+	assert.Equal(t, []ACL{writer}, plan.AddACLs)
+	require.Len(t, plan.Warnings, 2)
+	assert.Contains(t, plan.Warnings[0], "replication factor 1 instead of the 3")
+	assert.Contains(t, plan.Warnings[1], "topic sts_traces has 4 partitions")
+	assert.False(t, plan.Empty())
+	assert.True(t, NewPlan(snapshot, snapshot).Empty())
+}
+
+func TestClient_Apply(t *testing.T) {
+	runner := &fakeRunner{errs: map[string]error{"kafka-configs.sh": errors.New("InvalidConfigurationException")}}
+	plan := &Plan{
+		CreateTopics: []Topic{{Name: "sts_events", Partitions: 1, ReplicationFactor: 1}},
+		AlterTopics:  []TopicChange{{Name: "sts_metrics", Configs: map[string]string{"retention.ms": "1000"}, Partitions: 6}},
+		AddACLs:      []ACL{{ResourceType: "TOPIC", ResourceName: "sts_", PatternType: "PREFIXED", Principal: "User:writer", Host: "*", Operation: "WRITE", Permission: "ALLOW"}},
+	}
+
+	err := NewClient(runner, "localhost:9092", "").Apply(plan)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to make 1 change(s)")
+	assert.Contains(t, err.Error(), "InvalidConfigurationException")
+	assert.Len(t, runner.commands, 4, "a failed change does not stop the others")
+}
@@ -4,60 +4,170 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"sync"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/color"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 )
 
+// Level controls which messages are written by the Logger.
+// The zero value is LevelInfo.
+type Level int
+
+const (
+	LevelError Level = iota - 2
+	LevelWarn
+	LevelInfo
+	LevelDebug
+	LevelTrace
+)
+
+// levelNames maps levels to their flag values
+var levelNames = map[Level]string{
+	LevelError: "error",
+	LevelWarn:  "warn",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+	LevelTrace: "trace",
+}
+
+// ParseLevel parses a level name (error, warn, info, debug, trace)
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level '%s' (expected one of: error, warn, info, debug, trace)", name)
+}
+
+// String returns the level name
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Set implements pflag.Value so a Level can be bound directly to a flag
+func (l *Level) Set(name string) error {
+	level, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Type implements pflag.Value
+func (l *Level) Type() string {
+	return "level"
+}
+
 // Logger handles operational logging to stderr, keeping stdout clean for data output
 type Logger struct {
 	writer io.Writer
-	quiet  bool
-	debug  bool
+	level  Level
+	runID  string
+
+	// collecting is set by CollectWarnings; warnings then holds the warnings logged since, for reports
+	mu         sync.Mutex
+	collecting bool
+	warnings   []string
 }
 
-// New creates a new logger that writes to stderr
-func New(quiet, debug bool) *Logger {
+// New creates a new logger that writes to stderr.
+// When runID is set, every log line is prefixed with it so a run can be traced across logs and events.
+func New(level Level, runID string) *Logger {
 	return &Logger{
 		writer: os.Stderr,
-		quiet:  quiet,
-		debug:  debug,
+		level:  level,
+		runID:  runID,
+	}
+}
+
+// Enabled reports whether messages of the given level are written
+func (l *Logger) Enabled(level Level) bool {
+	return l.level >= level
+}
+
+// printf writes a single log line, prefixed with the run ID if set. Registered secrets are masked (see redact).
+func (l *Logger) printf(prefix, format string, args ...interface{}) {
+	if l.runID != "" {
+		prefix = "[" + l.runID + "] " + prefix
 	}
+	_, _ = fmt.Fprintln(l.writer, prefix+redact.String(fmt.Sprintf(format, args...)))
 }
 
 // Infof logs an informational message
 func (l *Logger) Infof(format string, args ...interface{}) {
-	if !l.quiet {
-		_, _ = fmt.Fprintf(l.writer, format+"\n", args...)
+	if l.Enabled(LevelInfo) {
+		l.printf("", format, args...)
 	}
 }
 
 // Successf logs a success message
 func (l *Logger) Successf(format string, args ...interface{}) {
-	if !l.quiet {
-		_, _ = fmt.Fprintf(l.writer, "✓ "+format+"\n", args...)
+	if l.Enabled(LevelInfo) {
+		l.printf(color.Sprint(l.writer, color.Green, "✓")+" ", format, args...)
 	}
 }
 
 // Warningf logs a warning message
 func (l *Logger) Warningf(format string, args ...interface{}) {
-	if !l.quiet {
-		_, _ = fmt.Fprintf(l.writer, "Warning: "+format+"\n", args...)
+	l.collect(format, args...)
+	if l.Enabled(LevelWarn) {
+		l.printf(color.Sprint(l.writer, color.Yellow, "Warning:")+" ", format, args...)
+	}
+}
+
+// CollectWarnings makes the logger keep the warnings logged from now on, also those below the log level,
+// so they can be listed in a report (see Warnings)
+func (l *Logger) CollectWarnings() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.collecting = true
+}
+
+// Warnings returns the warnings logged since CollectWarnings, with registered secrets masked
+func (l *Logger) Warnings() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.warnings...)
+}
+
+// collect keeps a warning when collecting warnings
+func (l *Logger) collect(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.collecting {
+		l.warnings = append(l.warnings, redact.String(fmt.Sprintf(format, args...)))
 	}
 }
 
-// Errorf logs an error message (always shown, even in quiet mode)
+// Errorf logs an error message (always shown, even at the lowest level)
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	_, _ = fmt.Fprintf(l.writer, "Error: "+format+"\n", args...)
+	l.printf(color.Sprint(l.writer, color.Red, "Error:")+" ", format, args...)
 }
 
-// Debug logs a debug message (only shown when debug mode is enabled)
+// Debugf logs a debug message (only shown at debug level or higher)
 func (l *Logger) Debugf(format string, args ...interface{}) {
-	if l.debug {
-		_, _ = fmt.Fprintf(l.writer, "DEBUG: "+format+"\n", args...)
+	if l.Enabled(LevelDebug) {
+		l.printf("DEBUG: ", format, args...)
+	}
+}
+
+// Tracef logs a trace message such as HTTP request/response dumps (only shown at trace level)
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	if l.Enabled(LevelTrace) {
+		l.printf("TRACE: ", format, args...)
 	}
 }
 
 // Println prints a blank line (for spacing)
 func (l *Logger) Println() {
-	if !l.quiet {
+	if l.Enabled(LevelInfo) {
 		_, _ = fmt.Fprintln(l.writer)
 	}
 }
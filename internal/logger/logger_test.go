@@ -5,52 +5,69 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNew(t *testing.T) {
+	levels := []Level{LevelError, LevelWarn, LevelInfo, LevelDebug, LevelTrace}
+
+	for _, level := range levels {
+		t.Run(level.String(), func(t *testing.T) {
+			logger := New(level, "")
+			assert.NotNil(t, logger)
+			assert.Equal(t, level, logger.level)
+			assert.NotNil(t, logger.writer)
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
 	tests := []struct {
-		name  string
-		quiet bool
-		debug bool
+		name        string
+		input       string
+		expected    Level
+		expectError bool
 	}{
-		{
-			name:  "normal mode",
-			quiet: false,
-			debug: false,
-		},
-		{
-			name:  "quiet mode",
-			quiet: true,
-			debug: false,
-		},
-		{
-			name:  "debug mode",
-			quiet: false,
-			debug: true,
-		},
-		{
-			name:  "quiet and debug mode",
-			quiet: true,
-			debug: true,
-		},
+		{name: "error", input: "error", expected: LevelError},
+		{name: "warn", input: "warn", expected: LevelWarn},
+		{name: "info", input: "info", expected: LevelInfo},
+		{name: "debug", input: "debug", expected: LevelDebug},
+		{name: "trace", input: "trace", expected: LevelTrace},
+		{name: "case insensitive", input: "DEBUG", expected: LevelDebug},
+		{name: "invalid level", input: "verbose", expectError: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			logger := New(tt.quiet, tt.debug)
-			assert.NotNil(t, logger)
-			assert.Equal(t, tt.quiet, logger.quiet)
-			assert.Equal(t, tt.debug, logger.debug)
-			assert.NotNil(t, logger.writer)
+			level, err := ParseLevel(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
 		})
 	}
 }
 
+func TestLevel_ZeroValueIsInfo(t *testing.T) {
+	var level Level
+	assert.Equal(t, LevelInfo, level)
+}
+
+func TestLevel_Set(t *testing.T) {
+	var level Level
+	assert.NoError(t, level.Set("trace"))
+	assert.Equal(t, LevelTrace, level)
+	assert.Equal(t, "trace", level.String())
+	assert.Error(t, level.Set("invalid"))
+}
+
 func TestLogger_Infof(t *testing.T) {
 	tests := []struct {
 		name           string
-		quiet          bool
+		level          Level
 		message        string
 		args           []interface{}
 		expectedOutput string
@@ -58,15 +75,15 @@ func TestLogger_Infof(t *testing.T) {
 	}{
 		{
 			name:           "info message in normal mode",
-			quiet:          false,
+			level:          LevelInfo,
 			message:        "Processing %s",
 			args:           []interface{}{"test"},
 			expectedOutput: "Processing test\n",
 			shouldOutput:   true,
 		},
 		{
-			name:           "info message in quiet mode",
-			quiet:          true,
+			name:           "info message at error level",
+			level:          LevelError,
 			message:        "Processing %s",
 			args:           []interface{}{"test"},
 			expectedOutput: "",
@@ -79,7 +96,7 @@ func TestLogger_Infof(t *testing.T) {
 			buf := &bytes.Buffer{}
 			logger := &Logger{
 				writer: buf,
-				quiet:  tt.quiet,
+				level:  tt.level,
 			}
 
 			logger.Infof(tt.message, tt.args...)
@@ -96,7 +113,7 @@ func TestLogger_Infof(t *testing.T) {
 func TestLogger_Successf(t *testing.T) {
 	tests := []struct {
 		name           string
-		quiet          bool
+		level          Level
 		message        string
 		args           []interface{}
 		shouldOutput   bool
@@ -104,15 +121,15 @@ func TestLogger_Successf(t *testing.T) {
 	}{
 		{
 			name:           "success message in normal mode",
-			quiet:          false,
+			level:          LevelInfo,
 			message:        "Completed %s",
 			args:           []interface{}{"task"},
 			shouldOutput:   true,
 			containsSymbol: true,
 		},
 		{
-			name:           "success message in quiet mode",
-			quiet:          true,
+			name:           "success message at error level",
+			level:          LevelError,
 			message:        "Completed %s",
 			args:           []interface{}{"task"},
 			shouldOutput:   false,
@@ -125,7 +142,7 @@ func TestLogger_Successf(t *testing.T) {
 			buf := &bytes.Buffer{}
 			logger := &Logger{
 				writer: buf,
-				quiet:  tt.quiet,
+				level:  tt.level,
 			}
 
 			logger.Successf(tt.message, tt.args...)
@@ -147,21 +164,21 @@ func TestLogger_Successf(t *testing.T) {
 func TestLogger_Warningf(t *testing.T) {
 	tests := []struct {
 		name         string
-		quiet        bool
+		level        Level
 		message      string
 		args         []interface{}
 		shouldOutput bool
 	}{
 		{
 			name:         "warning in normal mode",
-			quiet:        false,
+			level:        LevelInfo,
 			message:      "Deprecated %s",
 			args:         []interface{}{"feature"},
 			shouldOutput: true,
 		},
 		{
-			name:         "warning in quiet mode",
-			quiet:        true,
+			name:         "warning at error level",
+			level:        LevelError,
 			message:      "Deprecated %s",
 			args:         []interface{}{"feature"},
 			shouldOutput: false,
@@ -173,7 +190,7 @@ func TestLogger_Warningf(t *testing.T) {
 			buf := &bytes.Buffer{}
 			logger := &Logger{
 				writer: buf,
-				quiet:  tt.quiet,
+				level:  tt.level,
 			}
 
 			logger.Warningf(tt.message, tt.args...)
@@ -192,19 +209,19 @@ func TestLogger_Warningf(t *testing.T) {
 func TestLogger_Errorf(t *testing.T) {
 	tests := []struct {
 		name    string
-		quiet   bool
+		level   Level
 		message string
 		args    []interface{}
 	}{
 		{
 			name:    "error in normal mode",
-			quiet:   false,
+			level:   LevelInfo,
 			message: "Failed to %s",
 			args:    []interface{}{"connect"},
 		},
 		{
-			name:    "error in quiet mode (still outputs)",
-			quiet:   true,
+			name:    "error at error level (still outputs)",
+			level:   LevelError,
 			message: "Failed to %s",
 			args:    []interface{}{"connect"},
 		},
@@ -215,12 +232,12 @@ func TestLogger_Errorf(t *testing.T) {
 			buf := &bytes.Buffer{}
 			logger := &Logger{
 				writer: buf,
-				quiet:  tt.quiet,
+				level:  tt.level,
 			}
 
 			logger.Errorf(tt.message, tt.args...)
 
-			// Errors always output, regardless of quiet mode
+			// Errors always output, regardless of level
 			output := buf.String()
 			assert.Contains(t, output, "Error:")
 			assert.Contains(t, output, "Failed to connect")
@@ -232,21 +249,21 @@ func TestLogger_Errorf(t *testing.T) {
 func TestLogger_Debugf(t *testing.T) {
 	tests := []struct {
 		name         string
-		debug        bool
+		level        Level
 		message      string
 		args         []interface{}
 		shouldOutput bool
 	}{
 		{
 			name:         "debug message with debug enabled",
-			debug:        true,
+			level:        LevelDebug,
 			message:      "Debug info: %s",
 			args:         []interface{}{"details"},
 			shouldOutput: true,
 		},
 		{
 			name:         "debug message with debug disabled",
-			debug:        false,
+			level:        LevelInfo,
 			message:      "Debug info: %s",
 			args:         []interface{}{"details"},
 			shouldOutput: false,
@@ -258,7 +275,7 @@ func TestLogger_Debugf(t *testing.T) {
 			buf := &bytes.Buffer{}
 			logger := &Logger{
 				writer: buf,
-				debug:  tt.debug,
+				level:  tt.level,
 			}
 
 			logger.Debugf(tt.message, tt.args...)
@@ -277,17 +294,17 @@ func TestLogger_Debugf(t *testing.T) {
 func TestLogger_Println(t *testing.T) {
 	tests := []struct {
 		name         string
-		quiet        bool
+		level        Level
 		shouldOutput bool
 	}{
 		{
 			name:         "blank line in normal mode",
-			quiet:        false,
+			level:        LevelInfo,
 			shouldOutput: true,
 		},
 		{
-			name:         "blank line in quiet mode",
-			quiet:        true,
+			name:         "blank line at error level",
+			level:        LevelError,
 			shouldOutput: false,
 		},
 	}
@@ -297,7 +314,7 @@ func TestLogger_Println(t *testing.T) {
 			buf := &bytes.Buffer{}
 			logger := &Logger{
 				writer: buf,
-				quiet:  tt.quiet,
+				level:  tt.level,
 			}
 
 			logger.Println()
@@ -315,8 +332,7 @@ func TestLogger_MultipleCalls(t *testing.T) {
 	buf := &bytes.Buffer{}
 	logger := &Logger{
 		writer: buf,
-		quiet:  false,
-		debug:  true,
+		level:  LevelDebug,
 	}
 
 	logger.Infof("Starting process")
@@ -333,3 +349,100 @@ func TestLogger_MultipleCalls(t *testing.T) {
 	assert.Contains(t, output, "✓ Process completed")
 	assert.Contains(t, output, "Warning: Cleanup recommended")
 }
+
+//nolint:dupl // Test functions are intentionally similar for consistency
+func TestLogger_Tracef(t *testing.T) {
+	tests := []struct {
+		name         string
+		level        Level
+		shouldOutput bool
+	}{
+		{name: "trace message at trace level", level: LevelTrace, shouldOutput: true},
+		{name: "trace message at debug level", level: LevelDebug, shouldOutput: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := &Logger{
+				writer: buf,
+				level:  tt.level,
+			}
+
+			logger.Tracef("GET %s", "/_snapshot")
+
+			if tt.shouldOutput {
+				assert.Contains(t, buf.String(), "TRACE: GET /_snapshot")
+			} else {
+				assert.Empty(t, buf.String())
+			}
+		})
+	}
+}
+
+func TestLogger_WarnLevelSuppressesInfo(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := &Logger{
+		writer: buf,
+		level:  LevelWarn,
+	}
+
+	logger.Infof("info")
+	logger.Warningf("warning")
+
+	assert.NotContains(t, buf.String(), "info")
+	assert.Contains(t, buf.String(), "Warning: warning")
+}
+
+func TestLogger_RunIDPrefix(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := &Logger{
+		writer: buf,
+		level:  LevelDebug,
+		runID:  "20250101T000000-abc123",
+	}
+
+	logger.Infof("Starting %s", "restore")
+	logger.Debugf("details")
+	logger.Println()
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "[20250101T000000-abc123] Starting restore", lines[0])
+	assert.Equal(t, "[20250101T000000-abc123] DEBUG: details", lines[1])
+	// Blank spacing lines are not prefixed
+	assert.Equal(t, "", lines[2])
+}
+
+func TestLogger_RedactsSecrets(t *testing.T) {
+	redact.Register("logger-test-secret-key")
+
+	buf := &bytes.Buffer{}
+	logger := &Logger{
+		writer: buf,
+		level:  LevelTrace,
+	}
+
+	logger.Tracef("HTTP request:\n%s", `{"settings":{"secret_key":"logger-test-secret-key"}}`)
+
+	assert.NotContains(t, buf.String(), "logger-test-secret-key")
+	assert.Contains(t, buf.String(), `"secret_key":"****"`)
+}
+
+func TestLogger_CollectWarnings(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := &Logger{
+		writer: buf,
+		level:  LevelError,
+	}
+
+	logger.Warningf("before collecting")
+	assert.Empty(t, logger.Warnings())
+
+	logger.CollectWarnings()
+	logger.Warningf("snapshot %s is %s", "sts-backup-1", "PARTIAL")
+	logger.Infof("not a warning")
+
+	// Warnings are collected also when the level suppresses them
+	assert.Equal(t, []string{"snapshot sts-backup-1 is PARTIAL"}, logger.Warnings())
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// metricsPrefix is prepended to every exported metric name
+const metricsPrefix = "sts_backup_"
+
+// WriteMetrics writes the most recent result in the Prometheus text exposition format
+func (m *Monitor) WriteMetrics(w io.Writer) {
+	result := m.Last()
+	if result == nil {
+		return
+	}
+
+	gauge(w, "last_check_timestamp_seconds", "Time of the last backup health check.", unixSeconds(result.CheckedAt))
+	gauge(w, "healthy", "Whether all checks passed and no threshold is breached (1) or not (0).", boolValue(result.Healthy()))
+	gauge(w, "threshold_breaches", "Number of breached thresholds.", float64(len(result.Breaches)))
+	gauge(w, "check_errors", "Number of checks that could not be performed.", float64(len(result.Errors)))
+
+	if result.LastSuccessfulSnapshot != nil {
+		taken := time.UnixMilli(result.LastSuccessfulSnapshot.StartTimeMillis)
+		gauge(w, "last_successful_snapshot_timestamp_seconds", "Start time of the most recent successful snapshot.", unixSeconds(taken))
+		gauge(w, "last_successful_snapshot_age_seconds", "Age of the most recent successful snapshot at the last check.", result.CheckedAt.Sub(taken).Seconds())
+	}
+
+	writeHeader(w, "snapshots", "Number of snapshots in the repository by state.")
+	states := make([]string, 0, len(result.SnapshotCounts))
+	for state := range result.SnapshotCounts {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	for _, state := range states {
+		_, _ = fmt.Fprintf(w, "%ssnapshots{state=%q} %d\n", metricsPrefix, state, result.SnapshotCounts[state])
+	}
+
+	gauge(w, "repository_healthy", "Whether the snapshot repository could be verified (1) or not (0).", boolValue(result.RepositoryHealthy))
+	gauge(w, "slm_running", "Whether Snapshot Lifecycle Management is running (1) or not (0).", boolValue(result.SLMRunning))
+	if !result.SLMLastSuccess.IsZero() {
+		gauge(w, "slm_last_success_timestamp_seconds", "Time of the last successful SLM policy run.", unixSeconds(result.SLMLastSuccess))
+	}
+	if !result.SLMLastFailure.IsZero() {
+		gauge(w, "slm_last_failure_timestamp_seconds", "Time of the last failed SLM policy run.", unixSeconds(result.SLMLastFailure))
+	}
+}
+
+// Handler serves /metrics and /healthz. The health endpoint reports whether checks are still
+// running (a check completed within maxCheckAge), not whether the backups are healthy.
+func (m *Monitor) Handler(maxCheckAge time.Duration) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteMetrics(w)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		result := m.Last()
+		switch {
+		case result == nil:
+			http.Error(w, "no check completed yet", http.StatusServiceUnavailable)
+		case time.Since(result.CheckedAt) > maxCheckAge:
+			http.Error(w, fmt.Sprintf("last check completed %s ago", time.Since(result.CheckedAt).Round(time.Second)), http.StatusServiceUnavailable)
+		default:
+			_, _ = fmt.Fprintln(w, "ok")
+		}
+	})
+	return mux
+}
+
+func gauge(w io.Writer, name, help string, value float64) {
+	writeHeader(w, name, help)
+	_, _ = fmt.Fprintf(w, "%s%s %s\n", metricsPrefix, name, strconv.FormatFloat(value, 'f', -1, 64))
+}
+
+func writeHeader(w io.Writer, name, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s gauge\n", metricsPrefix, name, help, metricsPrefix, name)
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixMilli()) / float64(time.Second/time.Millisecond)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
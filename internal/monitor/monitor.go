@@ -0,0 +1,171 @@
+// Package monitor periodically evaluates backup health (snapshot age, SLM policy and
+// repository state) and exposes the results as Prometheus metrics.
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+)
+
+const (
+	// slmRunning is the SLM operation mode when snapshots are being taken
+	slmRunning = "RUNNING"
+)
+
+// Client is the subset of the Elasticsearch client used by the monitor
+type Client interface {
+	ListSnapshots(repository string) ([]elasticsearch.Snapshot, error)
+	VerifyRepository(name string) (int, error)
+	SLMStatus() (string, error)
+	GetSLMPolicy(name string) (*elasticsearch.SLMPolicy, error)
+}
+
+// Target identifies what is monitored
+type Target struct {
+	// Repository is the repository snapshots are listed from
+	Repository string
+	// SLMPolicy is the name of the SLM policy taking the snapshots
+	SLMPolicy string
+}
+
+// Thresholds configures when backup health is considered breached
+type Thresholds struct {
+	// MaxSnapshotAge is the maximum age of the most recent successful snapshot
+	MaxSnapshotAge time.Duration
+}
+
+// Result is the outcome of a single health check
+type Result struct {
+	CheckedAt              time.Time
+	LastSuccessfulSnapshot *elasticsearch.Snapshot
+	SnapshotCounts         map[string]int
+	RepositoryHealthy      bool
+	SLMRunning             bool
+	SLMLastSuccess         time.Time
+	SLMLastFailure         time.Time
+	// Breaches lists the thresholds that are exceeded
+	Breaches []string
+	// Errors lists checks that could not be performed
+	Errors []string
+}
+
+// Healthy reports whether all checks ran and no threshold is breached
+func (r *Result) Healthy() bool {
+	return len(r.Breaches) == 0 && len(r.Errors) == 0
+}
+
+// Problems returns the breaches and errors of the result
+func (r *Result) Problems() []string {
+	problems := make([]string, 0, len(r.Breaches)+len(r.Errors))
+	problems = append(problems, r.Breaches...)
+	return append(problems, r.Errors...)
+}
+
+// Monitor runs health checks and keeps the most recent result
+type Monitor struct {
+	client     Client
+	target     Target
+	thresholds Thresholds
+
+	mu   sync.RWMutex
+	last *Result
+}
+
+// New creates a monitor
+func New(client Client, target Target, thresholds Thresholds) *Monitor {
+	return &Monitor{
+		client:     client,
+		target:     target,
+		thresholds: thresholds,
+	}
+}
+
+// Last returns the most recent result, or nil if no check has run yet
+func (m *Monitor) Last() *Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.last
+}
+
+// Check runs all health checks and stores the result
+func (m *Monitor) Check(now time.Time) *Result {
+	result := &Result{
+		CheckedAt:      now,
+		SnapshotCounts: make(map[string]int),
+	}
+
+	m.checkSnapshots(result, now)
+	m.checkRepository(result)
+	m.checkSLM(result)
+
+	m.mu.Lock()
+	m.last = result
+	m.mu.Unlock()
+
+	return result
+}
+
+// checkSnapshots finds the most recent successful snapshot and counts snapshots by state
+func (m *Monitor) checkSnapshots(result *Result, now time.Time) {
+	snapshots, err := m.client.ListSnapshots(m.target.Repository)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to list snapshots: %v", err))
+		return
+	}
+
+	for _, snapshot := range snapshots {
+		result.SnapshotCounts[snapshot.State]++
+	}
+	result.LastSuccessfulSnapshot = elasticsearch.LatestSuccessful(snapshots)
+
+	if result.LastSuccessfulSnapshot == nil {
+		result.Breaches = append(result.Breaches, fmt.Sprintf("no successful snapshot in repository '%s'", m.target.Repository))
+		return
+	}
+
+	age := now.Sub(time.UnixMilli(result.LastSuccessfulSnapshot.StartTimeMillis))
+	if m.thresholds.MaxSnapshotAge > 0 && age > m.thresholds.MaxSnapshotAge {
+		result.Breaches = append(result.Breaches, fmt.Sprintf("last successful snapshot '%s' is %s old (threshold %s)",
+			result.LastSuccessfulSnapshot.Snapshot, age.Round(time.Minute), m.thresholds.MaxSnapshotAge))
+	}
+}
+
+// checkRepository verifies the snapshot repository
+func (m *Monitor) checkRepository(result *Result) {
+	if _, err := m.client.VerifyRepository(m.target.Repository); err != nil {
+		result.Breaches = append(result.Breaches, fmt.Sprintf("repository '%s' verification failed: %v", m.target.Repository, err))
+		return
+	}
+	result.RepositoryHealthy = true
+}
+
+// checkSLM checks that SLM is running and the policy's last run did not fail
+func (m *Monitor) checkSLM(result *Result) {
+	mode, err := m.client.SLMStatus()
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to get SLM status: %v", err))
+		return
+	}
+	result.SLMRunning = mode == slmRunning
+	if !result.SLMRunning {
+		result.Breaches = append(result.Breaches, fmt.Sprintf("SLM is %s", mode))
+	}
+
+	policy, err := m.client.GetSLMPolicy(m.target.SLMPolicy)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to get SLM policy '%s': %v", m.target.SLMPolicy, err))
+		return
+	}
+	if policy.LastSuccess != nil {
+		result.SLMLastSuccess = time.UnixMilli(policy.LastSuccess.Time)
+	}
+	if policy.LastFailure != nil {
+		result.SLMLastFailure = time.UnixMilli(policy.LastFailure.Time)
+		if result.SLMLastFailure.After(result.SLMLastSuccess) {
+			result.Breaches = append(result.Breaches, fmt.Sprintf("SLM policy '%s' last run failed: %s", m.target.SLMPolicy, policy.LastFailure.Details))
+		}
+	}
+}
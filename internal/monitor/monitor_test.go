@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// mockClient is a configurable Client for testing
+type mockClient struct {
+	snapshots    []elasticsearch.Snapshot
+	snapshotsErr error
+	verifyErr    error
+	slmMode      string
+	slmErr       error
+	policy       *elasticsearch.SLMPolicy
+	policyErr    error
+}
+
+func (m *mockClient) ListSnapshots(_ string) ([]elasticsearch.Snapshot, error) {
+	return m.snapshots, m.snapshotsErr
+}
+
+func (m *mockClient) VerifyRepository(_ string) (int, error) {
+	return 1, m.verifyErr
+}
+
+func (m *mockClient) SLMStatus() (string, error) {
+	return m.slmMode, m.slmErr
+}
+
+func (m *mockClient) GetSLMPolicy(_ string) (*elasticsearch.SLMPolicy, error) {
+	return m.policy, m.policyErr
+}
+
+func snapshotAt(name, state string, ago time.Duration) elasticsearch.Snapshot {
+	return elasticsearch.Snapshot{Snapshot: name, State: state, StartTimeMillis: testNow.Add(-ago).UnixMilli()}
+}
+
+func healthyClient() *mockClient {
+	return &mockClient{
+		snapshots: []elasticsearch.Snapshot{
+			snapshotAt("snap-old", "SUCCESS", 30*time.Hour),
+			snapshotAt("snap-new", "SUCCESS", 6*time.Hour),
+			snapshotAt("snap-failed", "FAILED", 2*time.Hour),
+		},
+		slmMode: "RUNNING",
+		policy: &elasticsearch.SLMPolicy{
+			LastSuccess: &elasticsearch.SLMExecution{Time: testNow.Add(-6 * time.Hour).UnixMilli()},
+		},
+	}
+}
+
+func TestMonitor_Check(t *testing.T) {
+	tests := []struct {
+		name             string
+		client           func() *mockClient
+		expectHealthy    bool
+		expectedBreaches int
+		expectedErrors   int
+	}{
+		{
+			name:          "healthy",
+			client:        healthyClient,
+			expectHealthy: true,
+		},
+		{
+			name: "last snapshot too old",
+			client: func() *mockClient {
+				c := healthyClient()
+				c.snapshots = []elasticsearch.Snapshot{snapshotAt("snap-old", "SUCCESS", 30*time.Hour)}
+				return c
+			},
+			expectedBreaches: 1,
+		},
+		{
+			name: "no successful snapshot",
+			client: func() *mockClient {
+				c := healthyClient()
+				c.snapshots = []elasticsearch.Snapshot{snapshotAt("snap-failed", "FAILED", time.Hour)}
+				return c
+			},
+			expectedBreaches: 1,
+		},
+		{
+			name: "repository verification fails and SLM stopped",
+			client: func() *mockClient {
+				c := healthyClient()
+				c.verifyErr = fmt.Errorf("access denied")
+				c.slmMode = "STOPPED"
+				return c
+			},
+			expectedBreaches: 2,
+		},
+		{
+			name: "SLM last run failed",
+			client: func() *mockClient {
+				c := healthyClient()
+				c.policy.LastFailure = &elasticsearch.SLMExecution{Time: testNow.Add(-time.Hour).UnixMilli(), Details: "boom"}
+				return c
+			},
+			expectedBreaches: 1,
+		},
+		{
+			name: "elasticsearch unreachable",
+			client: func() *mockClient {
+				c := healthyClient()
+				c.snapshotsErr = fmt.Errorf("connection refused")
+				c.slmErr = fmt.Errorf("connection refused")
+				return c
+			},
+			expectedErrors: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New(tt.client(), Target{Repository: "sts-backup", SLMPolicy: "auto"}, Thresholds{MaxSnapshotAge: 25 * time.Hour})
+
+			result := m.Check(testNow)
+
+			assert.Equal(t, tt.expectHealthy, result.Healthy())
+			assert.Len(t, result.Breaches, tt.expectedBreaches, result.Breaches)
+			assert.Len(t, result.Errors, tt.expectedErrors, result.Errors)
+			assert.Len(t, result.Problems(), tt.expectedBreaches+tt.expectedErrors)
+			assert.Same(t, result, m.Last())
+		})
+	}
+}
+
+func TestMonitor_WriteMetrics(t *testing.T) {
+	m := New(healthyClient(), Target{Repository: "sts-backup", SLMPolicy: "auto"}, Thresholds{MaxSnapshotAge: 25 * time.Hour})
+
+	buf := &bytes.Buffer{}
+	m.WriteMetrics(buf)
+	assert.Empty(t, buf.String(), "no metrics before the first check")
+
+	m.Check(testNow)
+	m.WriteMetrics(buf)
+	metrics := buf.String()
+
+	assert.Contains(t, metrics, "# TYPE sts_backup_healthy gauge\nsts_backup_healthy 1\n")
+	assert.Contains(t, metrics, fmt.Sprintf("sts_backup_last_successful_snapshot_timestamp_seconds %d\n", testNow.Add(-6*time.Hour).Unix()))
+	assert.Contains(t, metrics, "sts_backup_last_successful_snapshot_age_seconds 21600\n")
+	assert.Contains(t, metrics, "sts_backup_snapshots{state=\"FAILED\"} 1\n")
+	assert.Contains(t, metrics, "sts_backup_snapshots{state=\"SUCCESS\"} 2\n")
+	assert.Contains(t, metrics, "sts_backup_repository_healthy 1\n")
+	assert.Contains(t, metrics, "sts_backup_slm_running 1\n")
+	assert.NotContains(t, metrics, "sts_backup_slm_last_failure_timestamp_seconds")
+}
+
+func TestMonitor_Handler(t *testing.T) {
+	m := New(healthyClient(), Target{Repository: "sts-backup", SLMPolicy: "auto"}, Thresholds{})
+	server := httptest.NewServer(m.Handler(time.Minute))
+	defer server.Close()
+
+	get := func(path string) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, get("/healthz"), "no check yet")
+
+	m.Check(time.Now().Add(-time.Hour))
+	assert.Equal(t, http.StatusServiceUnavailable, get("/healthz"), "check is stale")
+
+	m.Check(time.Now())
+	assert.Equal(t, http.StatusOK, get("/healthz"))
+	assert.Equal(t, http.StatusOK, get("/metrics"))
+}
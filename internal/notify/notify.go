@@ -0,0 +1,156 @@
+// Package notify sends structured notifications about completed or failed
+// operations to generic webhooks, Slack incoming webhooks and the health API of SUSE Observability.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+const (
+	// defaultTimeout bounds each notification request so a slow endpoint cannot block the CLI
+	defaultTimeout = 10 * time.Second
+
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// Payload is the structured body sent to webhooks
+type Payload struct {
+	RunID      string            `json:"runId"`
+	Operation  string            `json:"operation"`
+	Status     string            `json:"status"`
+	Namespace  string            `json:"namespace"`
+	Message    string            `json:"message"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Duration   string            `json:"duration"`
+	Details    map[string]string `json:"details,omitempty"`
+}
+
+// NewPayload builds a payload for an operation that started at startedAt and finished now.
+// A nil opErr results in a success payload.
+func NewPayload(runID, operation, namespace string, startedAt time.Time, opErr error, details map[string]string) Payload {
+	finishedAt := time.Now()
+	payload := Payload{
+		RunID:      runID,
+		Operation:  operation,
+		Status:     StatusSuccess,
+		Namespace:  namespace,
+		Message:    fmt.Sprintf("%s completed successfully in namespace %s", operation, namespace),
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		Duration:   finishedAt.Sub(startedAt).Round(time.Second).String(),
+		Details:    details,
+	}
+	if opErr != nil {
+		payload.Status = StatusFailure
+		payload.Message = fmt.Sprintf("%s failed in namespace %s", operation, namespace)
+		payload.Error = redact.Error(opErr)
+	}
+	return payload
+}
+
+// Notifier delivers payloads to the configured targets
+type Notifier struct {
+	cfg        config.NotificationsConfig
+	httpClient *http.Client
+}
+
+// New creates a notifier for the given configuration
+func New(cfg config.NotificationsConfig) *Notifier {
+	return &Notifier{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// Enabled reports whether any notification target is configured
+func (n *Notifier) Enabled() bool {
+	return n.cfg.Webhook.URL != "" || n.cfg.Slack.WebhookURL != "" || n.cfg.Observability.Enabled()
+}
+
+// Send delivers the payload to every configured target.
+// Successful runs are skipped when onlyOnFailure is configured, except by SUSE Observability: its health state
+// has to return to CLEAR after a failure.
+func (n *Notifier) Send(payload Payload) error {
+	var errs []error
+	if n.cfg.Observability.Enabled() {
+		if err := n.post(intakeURL(n.cfg.Observability), nil, healthIntake(n.cfg.Observability, payload)); err != nil {
+			errs = append(errs, fmt.Errorf("observability: %w", err))
+		}
+	}
+	if payload.Status == StatusSuccess && n.cfg.OnlyOnFailure {
+		return errors.Join(errs...)
+	}
+
+	if n.cfg.Webhook.URL != "" {
+		if err := n.post(n.cfg.Webhook.URL, n.cfg.Webhook.Headers, payload); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if n.cfg.Slack.WebhookURL != "" {
+		if err := n.post(n.cfg.Slack.WebhookURL, nil, slackMessage(payload)); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends body as JSON to url with the given extra headers
+func (n *Notifier) post(url string, headers map[string]string, body interface{}) error {
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(bodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("endpoint returned status %s", res.Status)
+	}
+	return nil
+}
+
+// slackMessage converts a payload into a Slack incoming-webhook message
+func slackMessage(payload Payload) map[string]string {
+	icon := ":white_check_mark:"
+	if payload.Status == StatusFailure {
+		icon = ":x:"
+	}
+
+	text := fmt.Sprintf("%s *%s* (run `%s`, duration %s)", icon, payload.Message, payload.RunID, payload.Duration)
+	for _, key := range slices.Sorted(maps.Keys(payload.Details)) {
+		text += fmt.Sprintf("\n• %s: %s", key, payload.Details[key])
+	}
+	if payload.Error != "" {
+		text += fmt.Sprintf("\n```%s```", payload.Error)
+	}
+	return map[string]string{"text": text}
+}
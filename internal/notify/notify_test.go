@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPayload(t *testing.T) {
+	startedAt := time.Now().Add(-2 * time.Minute)
+
+	success := NewPayload("run-1", "restore", "test-ns", startedAt, nil, map[string]string{"snapshot": "snap-1"})
+	assert.Equal(t, StatusSuccess, success.Status)
+	assert.Equal(t, "run-1", success.RunID)
+	assert.Empty(t, success.Error)
+	assert.Equal(t, "2m0s", success.Duration)
+	assert.Equal(t, "snap-1", success.Details["snapshot"])
+
+	failure := NewPayload("run-1", "restore", "test-ns", startedAt, fmt.Errorf("boom"), nil)
+	assert.Equal(t, StatusFailure, failure.Status)
+	assert.Equal(t, "boom", failure.Error)
+	assert.Contains(t, failure.Message, "failed")
+}
+
+func TestNotifier_Enabled(t *testing.T) {
+	assert.False(t, New(config.NotificationsConfig{}).Enabled())
+	assert.True(t, New(config.NotificationsConfig{Webhook: config.WebhookConfig{URL: "http://example"}}).Enabled())
+	assert.True(t, New(config.NotificationsConfig{Slack: config.SlackConfig{WebhookURL: "http://example"}}).Enabled())
+}
+
+func TestNotifier_SendWebhook(t *testing.T) {
+	var received Payload
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		authHeader = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{
+		Webhook: config.WebhookConfig{
+			URL:     server.URL,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+		},
+	})
+
+	payload := NewPayload("run-1", "restore", "test-ns", time.Now(), nil, nil)
+	require.NoError(t, notifier.Send(payload))
+
+	assert.Equal(t, "Bearer token", authHeader)
+	assert.Equal(t, "run-1", received.RunID)
+	assert.Equal(t, "restore", received.Operation)
+	assert.Equal(t, StatusSuccess, received.Status)
+}
+
+func TestNotifier_SendSlack(t *testing.T) {
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{
+		Slack: config.SlackConfig{WebhookURL: server.URL},
+	})
+
+	payload := NewPayload("run-1", "restore", "test-ns", time.Now(), fmt.Errorf("snapshot not found"), nil)
+	require.NoError(t, notifier.Send(payload))
+
+	assert.Contains(t, received["text"], ":x:")
+	assert.Contains(t, received["text"], "run-1")
+	assert.Contains(t, received["text"], "snapshot not found")
+}
+
+func TestSlackMessage_DetailsSorted(t *testing.T) {
+	payload := NewPayload("run-1", "restore", "test-ns", time.Now(), nil, map[string]string{"snapshot": "snap-1", "indices": "12", "duration": "5m"})
+
+	text := slackMessage(payload)["text"]
+
+	assert.Contains(t, text, "• duration: 5m\n• indices: 12\n• snapshot: snap-1")
+}
+
+func TestNotifier_SendErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{
+		Webhook: config.WebhookConfig{URL: server.URL},
+	})
+
+	err := notifier.Send(NewPayload("run-1", "restore", "test-ns", time.Now(), nil, nil))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "500")
+}
+
+func TestNotifier_OnlyOnFailure(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{
+		Webhook:       config.WebhookConfig{URL: server.URL},
+		OnlyOnFailure: true,
+	})
+
+	require.NoError(t, notifier.Send(NewPayload("run-1", "restore", "test-ns", time.Now(), nil, nil)))
+	assert.Equal(t, 0, calls)
+
+	require.NoError(t, notifier.Send(NewPayload("run-1", "restore", "test-ns", time.Now(), fmt.Errorf("failed"), nil)))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+)
+
+const (
+	// Health states of a check state in SUSE Observability
+	healthClear    = "CLEAR"
+	healthCritical = "CRITICAL"
+
+	// healthRepeatInterval is how often SUSE Observability can expect a new health snapshot of an operation.
+	// Operations usually run daily, from a CronJob.
+	healthRepeatInterval = 24 * time.Hour
+
+	// intakePath is the path of the agent intake API below the receiver URL
+	intakePath = "/stsAgent/intake"
+	// intakeHostname identifies the CLI as the sender of the health data
+	intakeHostname = "sts-backup"
+)
+
+// intakeURL returns the URL of the agent intake API of the receiver, authenticated with the token
+func intakeURL(cfg config.ObservabilityConfig) string {
+	return strings.TrimSuffix(cfg.Endpoint, "/") + intakePath + "?" + url.Values{"api_key": {cfg.Token}}.Encode()
+}
+
+// healthIntake converts a payload into an intake request carrying a health snapshot with a single check state:
+// CLEAR when the operation succeeded and CRITICAL when it failed. Every operation has its own sub stream, so the
+// snapshot of one operation does not remove the check state of another.
+func healthIntake(cfg config.ObservabilityConfig, payload Payload) map[string]interface{} {
+	health := healthClear
+	message := payload.Message
+	if payload.Status == StatusFailure {
+		health = healthCritical
+		message += ": " + payload.Error
+	}
+	message += fmt.Sprintf(" (run %s, duration %s)", payload.RunID, payload.Duration)
+
+	checkState := map[string]interface{}{
+		"checkStateId":              "sts-backup-" + payload.Operation,
+		"name":                      "Backup CLI " + payload.Operation,
+		"health":                    health,
+		"message":                   message,
+		"topologyElementIdentifier": cfg.ComponentIdentifier,
+	}
+
+	return map[string]interface{}{
+		"apiKey":               cfg.Token,
+		"collection_timestamp": payload.FinishedAt.Unix(),
+		"internalHostname":     intakeHostname,
+		"events":               map[string]interface{}{},
+		"metrics":              []interface{}{},
+		"service_checks":       []interface{}{},
+		"topologies":           []interface{}{},
+		"health": []interface{}{
+			map[string]interface{}{
+				"consistency_model": "REPEAT_SNAPSHOTS",
+				"start_snapshot":    map[string]interface{}{"repeat_interval_s": int(healthRepeatInterval.Seconds())},
+				"stream":            map[string]interface{}{"urn": cfg.StreamURN, "sub_stream_id": payload.Operation},
+				"check_states":      []interface{}{checkState},
+				"stop_snapshot":     map[string]interface{}{},
+			},
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// intakeRequest is the part of an intake request the tests check
+type intakeRequest struct {
+	APIKey string `json:"apiKey"`
+	Health []struct {
+		ConsistencyModel string `json:"consistency_model"`
+		Stream           struct {
+			URN         string `json:"urn"`
+			SubStreamID string `json:"sub_stream_id"`
+		} `json:"stream"`
+		CheckStates []struct {
+			CheckStateID              string `json:"checkStateId"`
+			Health                    string `json:"health"`
+			Message                   string `json:"message"`
+			TopologyElementIdentifier string `json:"topologyElementIdentifier"`
+		} `json:"check_states"`
+	} `json:"health"`
+}
+
+func TestNotifier_SendObservability(t *testing.T) {
+	var received []intakeRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/receiver/stsAgent/intake", r.URL.Path)
+		assert.Equal(t, "secret-token", r.URL.Query().Get("api_key"))
+		var request intakeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		received = append(received, request)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := New(config.NotificationsConfig{
+		Observability: config.ObservabilityConfig{
+			Endpoint:            server.URL + "/receiver/",
+			Token:               "secret-token",
+			ComponentIdentifier: "urn:kubernetes:/cluster:suse-observability:statefulset/elasticsearch",
+			StreamURN:           config.DefaultHealthStreamURN,
+		},
+		// The health state is sent on success too, to clear an earlier failure
+		OnlyOnFailure: true,
+	})
+	assert.True(t, notifier.Enabled())
+
+	require.NoError(t, notifier.Send(NewPayload("run-1", "restore", "test-ns", time.Now(), fmt.Errorf("snapshot not found"), nil)))
+	require.NoError(t, notifier.Send(NewPayload("run-2", "restore", "test-ns", time.Now(), nil, nil)))
+
+	require.Len(t, received, 2)
+	failure := received[0]
+	assert.Equal(t, "secret-token", failure.APIKey)
+	require.Len(t, failure.Health, 1)
+	assert.Equal(t, "REPEAT_SNAPSHOTS", failure.Health[0].ConsistencyModel)
+	assert.Equal(t, config.DefaultHealthStreamURN, failure.Health[0].Stream.URN)
+	assert.Equal(t, "restore", failure.Health[0].Stream.SubStreamID)
+	require.Len(t, failure.Health[0].CheckStates, 1)
+	state := failure.Health[0].CheckStates[0]
+	assert.Equal(t, "sts-backup-restore", state.CheckStateID)
+	assert.Equal(t, healthCritical, state.Health)
+	assert.Contains(t, state.Message, "snapshot not found")
+	assert.Contains(t, state.Message, "run-1")
+	assert.Equal(t, "urn:kubernetes:/cluster:suse-observability:statefulset/elasticsearch", state.TopologyElementIdentifier)
+
+	assert.Equal(t, healthClear, received[1].Health[0].CheckStates[0].Health)
+}
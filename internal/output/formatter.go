@@ -1,12 +1,19 @@
 package output
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/tabwriter"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/color"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"sigs.k8s.io/yaml"
 )
 
 // Format represents supported output formats
@@ -15,78 +22,271 @@ type Format string
 const (
 	FormatTable Format = "table"
 	FormatJSON  Format = "json"
+	FormatCSV   Format = "csv"
+	FormatYAML  Format = "yaml"
+	// FormatJUnit prints verification results as JUnit XML (see PrintChecks); other output is printed as a table
+	FormatJUnit Format = "junit"
 
 	// tabwriterPadding is the padding between columns in table output
 	tabwriterPadding = 2
+
+	// outputFileMode is the permission of files written with WithOutputFile
+	outputFileMode = 0o644
 )
 
 // Formatter handles output formatting for list commands
 type Formatter struct {
 	writer io.Writer
 	format Format
+	runID  string
+	// outputFile receives the data instead of writer when set; messages are still written to writer
+	outputFile string
+}
+
+// Option configures a Formatter
+type Option func(*Formatter)
+
+// WithOutputFile writes tables and details to path instead of stdout, replacing the file atomically so a partially
+// written file is never picked up (e.g. by a Job uploading it as an artifact). An empty path writes to stdout.
+func WithOutputFile(path string) Option {
+	return func(f *Formatter) {
+		f.outputFile = path
+	}
+}
+
+// Envelope wraps JSON and YAML output with the run ID of the invocation that produced it
+type Envelope struct {
+	RunID string      `json:"runId"`
+	Items interface{} `json:"items"`
 }
 
 // NewFormatter creates a new output formatter
-// Defaults to table format if invalid format provided
-func NewFormatter(format string) *Formatter {
+// Defaults to table format if invalid format provided.
+// When runID is set, JSON and YAML output is wrapped in an Envelope carrying it.
+func NewFormatter(format, runID string, opts ...Option) *Formatter {
 	f := Format(format)
-	if f != FormatTable && f != FormatJSON {
+	switch f {
+	case FormatTable, FormatJSON, FormatCSV, FormatYAML, FormatJUnit:
+	default:
 		f = FormatTable
 	}
-	return &Formatter{
+	formatter := &Formatter{
 		writer: os.Stdout,
 		format: f,
+		runID:  runID,
+	}
+	for _, opt := range opts {
+		opt(formatter)
 	}
+	return formatter
 }
 
 // Table represents a table with headers and rows
 type Table struct {
 	Headers []string
 	Rows    [][]string
+	// StateColumns lists headers whose values are states (SUCCESS, PARTIAL, FAILED, ...)
+	// and are colored in table format when writing to a terminal
+	StateColumns []string
 }
 
-// PrintTable prints data in the configured format (table or json)
+// PrintTable prints data in the configured format (table, json, csv or yaml)
 func (f *Formatter) PrintTable(table Table) error {
-	if len(table.Rows) == 0 {
-		if f.format == FormatTable {
-			fmt.Fprintln(f.writer, "No data found")
-		} else {
-			// For JSON, output empty array
-			return f.printJSON([]map[string]string{})
+	return f.write(func(w io.Writer) error {
+		switch f.format {
+		case FormatJSON:
+			// For an empty table, output an empty array
+			return f.printJSON(w, tableToMaps(table))
+		case FormatYAML:
+			return f.printYAML(w, tableToMaps(table))
+		case FormatCSV:
+			return printCSV(w, table)
+		default:
+			if len(table.Rows) == 0 {
+				_, err := fmt.Fprintln(w, "No data found")
+				return err
+			}
+			return f.printTable(w, table)
+		}
+	})
+}
+
+// PrintDetail prints a single object. In JSON and YAML format the object itself is printed; in table and CSV
+// format the tables describing it are printed one after another, separated by a blank line. Empty tables are skipped.
+func (f *Formatter) PrintDetail(object interface{}, tables ...Table) error {
+	return f.write(func(w io.Writer) error {
+		switch f.format {
+		case FormatJSON:
+			return f.printJSON(w, object)
+		case FormatYAML:
+			return f.printYAML(w, object)
+		}
+
+		printed := false
+		for _, table := range tables {
+			if len(table.Rows) == 0 {
+				continue
+			}
+			if printed {
+				fmt.Fprintln(w)
+			}
+			var err error
+			if f.format == FormatCSV {
+				err = printCSV(w, table)
+			} else {
+				err = f.printTable(w, table)
+			}
+			if err != nil {
+				return err
+			}
+			printed = true
 		}
 		return nil
+	})
+}
+
+// write renders data to stdout, or to the output file when configured
+func (f *Formatter) write(render func(w io.Writer) error) error {
+	if f.outputFile == "" {
+		return render(f.writer)
 	}
 
-	switch f.format {
-	case FormatJSON:
-		return f.printJSON(tableToMaps(table))
-	case FormatTable:
-		return f.printTable(table)
-	default:
-		return f.printTable(table)
+	var buf bytes.Buffer
+	if err := render(&buf); err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(f.outputFile, buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	return nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it over path, so readers never see
+// a partially written file
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	// Removing fails harmlessly once the file has been renamed
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
 	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), outputFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // printTable prints data in table format using tabwriter
-func (f *Formatter) printTable(table Table) error {
-	w := tabwriter.NewWriter(f.writer, 0, 0, tabwriterPadding, ' ', 0)
+func (f *Formatter) printTable(out io.Writer, table Table) error {
+	w := tabwriter.NewWriter(out, 0, 0, tabwriterPadding, ' ', 0)
+
+	stateColumns := f.stateColumnIndexes(out, table)
 
 	// Print header
-	fmt.Fprintln(w, strings.Join(table.Headers, "\t"))
+	fmt.Fprintln(w, strings.Join(colorize(out, table.Headers, stateColumns, true), "\t"))
 
-	// Print rows
+	// Print rows, masking secrets before tabwriter measures the cells
 	for _, row := range table.Rows {
-		fmt.Fprintln(w, strings.Join(row, "\t"))
+		fmt.Fprintln(w, redact.String(strings.Join(colorize(out, row, stateColumns, false), "\t")))
 	}
 
 	return w.Flush()
 }
 
-// printJSON prints data in JSON format
-func (f *Formatter) printJSON(data interface{}) error {
-	encoder := json.NewEncoder(f.writer)
+// stateColumnIndexes returns the column indexes of the table's state columns, when out supports colors
+func (f *Formatter) stateColumnIndexes(out io.Writer, table Table) map[int]bool {
+	indexes := make(map[int]bool)
+	if !color.Enabled(out) {
+		return indexes
+	}
+	for i, header := range table.Headers {
+		for _, stateColumn := range table.StateColumns {
+			if header == stateColumn {
+				indexes[i] = true
+			}
+		}
+	}
+	return indexes
+}
+
+// colorize colors the state cells of a row. Headers are wrapped in the default color
+// so every cell of a colored column carries the same escape sequence length, keeping
+// tabwriter alignment intact.
+func colorize(out io.Writer, cells []string, stateColumns map[int]bool, header bool) []string {
+	if len(stateColumns) == 0 {
+		return cells
+	}
+	result := make([]string, len(cells))
+	for i, cell := range cells {
+		switch {
+		case !stateColumns[i]:
+			result[i] = cell
+		case header:
+			result[i] = color.Sprint(out, color.Default, cell)
+		default:
+			result[i] = color.State(out, cell)
+		}
+	}
+	return result
+}
+
+// printJSON prints data in JSON format, with secrets masked
+func (f *Formatter) printJSON(out io.Writer, data interface{}) error {
+	if f.runID != "" {
+		data = Envelope{RunID: f.runID, Items: data}
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, redact.String(buf.String()))
+	return err
+}
+
+// printYAML prints data in YAML format, with the field names of the JSON output and secrets masked
+func (f *Formatter) printYAML(out io.Writer, data interface{}) error {
+	if f.runID != "" {
+		data = Envelope{RunID: f.runID, Items: data}
+	}
+	encoded, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, redact.String(string(encoded)))
+	return err
+}
+
+// printCSV prints a table as CSV with a header row, with secrets masked
+func printCSV(out io.Writer, table Table) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(table.Headers); err != nil {
+		return err
+	}
+	for _, row := range table.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = redact.String(cell)
+		}
+		if err := w.Write(cells); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
 }
 
 // tableToMaps converts a Table to a slice of maps for JSON output
@@ -104,16 +304,17 @@ func tableToMaps(table Table) []map[string]string {
 	return result
 }
 
-// PrintMessage prints a simple message (only in table format, ignored in JSON)
+// PrintMessage prints a simple message (only in table format, ignored in JSON, CSV and YAML).
+// Messages are always written to stdout, also with an output file.
 func (f *Formatter) PrintMessage(message string) {
 	if f.format == FormatTable {
-		fmt.Fprintln(f.writer, message)
+		fmt.Fprintln(f.writer, redact.String(message))
 	}
 }
 
-// PrintError prints an error message (only in table format, ignored in JSON)
+// PrintError prints an error message (only in table format, ignored in JSON, CSV and YAML)
 func (f *Formatter) PrintError(err error) {
 	if f.format == FormatTable {
-		fmt.Fprintf(f.writer, "Errorf: %v\n", err)
+		fmt.Fprintf(f.writer, "Errorf: %s\n", redact.Error(err))
 	}
 }
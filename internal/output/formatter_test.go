@@ -3,9 +3,12 @@ package output
 import (
 	"bytes"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,6 +29,16 @@ func TestNewFormatter(t *testing.T) {
 			format:         "json",
 			expectedFormat: FormatJSON,
 		},
+		{
+			name:           "csv format",
+			format:         "csv",
+			expectedFormat: FormatCSV,
+		},
+		{
+			name:           "yaml format",
+			format:         "yaml",
+			expectedFormat: FormatYAML,
+		},
 		{
 			name:           "invalid format defaults to table",
 			format:         "invalid",
@@ -40,7 +53,7 @@ func TestNewFormatter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			formatter := NewFormatter(tt.format)
+			formatter := NewFormatter(tt.format, "")
 			assert.NotNil(t, formatter)
 			assert.Equal(t, tt.expectedFormat, formatter.format)
 			assert.NotNil(t, formatter.writer)
@@ -104,6 +117,28 @@ func TestFormatter_PrintTable_TableFormat(t *testing.T) {
 	}
 }
 
+func TestFormatter_PrintTable_StateColumnsWithoutTerminal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{
+		writer: buf,
+		format: FormatTable,
+	}
+
+	table := Table{
+		Headers:      []string{"NAME", "STATE"},
+		Rows:         [][]string{{"snapshot-1", "SUCCESS"}, {"snapshot-2", "FAILED"}},
+		StateColumns: []string{"STATE"},
+	}
+
+	err := formatter.PrintTable(table)
+	require.NoError(t, err)
+
+	// Non-terminal writers never receive escape sequences
+	assert.NotContains(t, buf.String(), "\x1b[")
+	assert.Contains(t, buf.String(), "SUCCESS")
+	assert.Contains(t, buf.String(), "FAILED")
+}
+
 func TestFormatter_PrintTable_JSONFormat(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -177,6 +212,30 @@ func TestFormatter_PrintTable_JSONFormat(t *testing.T) {
 	}
 }
 
+func TestFormatter_PrintTable_JSONEnvelope(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{
+		writer: buf,
+		format: FormatJSON,
+		runID:  "20250101T000000-abc123",
+	}
+
+	err := formatter.PrintTable(Table{
+		Headers: []string{"NAME"},
+		Rows:    [][]string{{"snapshot-1"}},
+	})
+	require.NoError(t, err)
+
+	var result struct {
+		RunID string              `json:"runId"`
+		Items []map[string]string `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, "20250101T000000-abc123", result.RunID)
+	require.Len(t, result.Items, 1)
+	assert.Equal(t, "snapshot-1", result.Items[0]["NAME"])
+}
+
 func TestFormatter_PrintMessage(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -377,3 +436,126 @@ func TestFormatter_JSONIndentation(t *testing.T) {
 	err = json.Unmarshal(buf.Bytes(), &result)
 	require.NoError(t, err)
 }
+
+func TestFormatter_PrintDetail(t *testing.T) {
+	object := map[string]interface{}{"snapshot": "snapshot-1", "indices": []string{"sts_a"}}
+	summary := Table{Headers: []string{"FIELD", "VALUE"}, Rows: [][]string{{"Snapshot", "snapshot-1"}}}
+	indices := Table{Headers: []string{"INDEX"}, Rows: [][]string{{"sts_a"}}}
+	failures := Table{Headers: []string{"FAILURE"}}
+
+	t.Run("table format prints the non-empty tables", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		formatter := &Formatter{writer: buf, format: FormatTable}
+
+		require.NoError(t, formatter.PrintDetail(object, summary, indices, failures))
+
+		assert.Equal(t, "FIELD     VALUE\nSnapshot  snapshot-1\n\nINDEX\nsts_a\n", buf.String())
+	})
+
+	t.Run("json format prints the object", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		formatter := &Formatter{writer: buf, format: FormatJSON, runID: "run-1"}
+
+		require.NoError(t, formatter.PrintDetail(object, summary, indices, failures))
+
+		var result struct {
+			RunID string `json:"runId"`
+			Items struct {
+				Snapshot string   `json:"snapshot"`
+				Indices  []string `json:"indices"`
+			} `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, "run-1", result.RunID)
+		assert.Equal(t, "snapshot-1", result.Items.Snapshot)
+		assert.Equal(t, []string{"sts_a"}, result.Items.Indices)
+	})
+}
+
+func TestFormatter_RedactsSecrets(t *testing.T) {
+	redact.Register("formatter-test-secret")
+	table := Table{
+		Headers: []string{"CHECK", "DETAILS"},
+		Rows:    [][]string{{"S3", "access denied for formatter-test-secret"}},
+	}
+
+	for _, format := range []Format{FormatTable, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			formatter := &Formatter{
+				writer: buf,
+				format: format,
+			}
+
+			require.NoError(t, formatter.PrintTable(table))
+			assert.NotContains(t, buf.String(), "formatter-test-secret")
+			assert.Contains(t, buf.String(), "access denied for ****")
+		})
+	}
+}
+
+func TestFormatter_PrintTable_CSVFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{
+		writer: buf,
+		format: FormatCSV,
+	}
+
+	err := formatter.PrintTable(Table{
+		Headers: []string{"NAME", "INDICES"},
+		Rows:    [][]string{{"snapshot-1", "sts_a,sts_b"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "NAME,INDICES\nsnapshot-1,\"sts_a,sts_b\"\n", buf.String())
+}
+
+func TestFormatter_PrintTable_YAMLFormat(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{
+		writer: buf,
+		format: FormatYAML,
+		runID:  "run-1",
+	}
+
+	err := formatter.PrintTable(Table{
+		Headers: []string{"NAME", "STATE"},
+		Rows:    [][]string{{"snapshot-1", "SUCCESS"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "items:\n- NAME: snapshot-1\n  STATE: SUCCESS\nrunId: run-1\n", buf.String())
+}
+
+func TestFormatter_WithOutputFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots.json")
+	require.NoError(t, os.WriteFile(path, []byte("previous run"), 0o600))
+
+	stdout := &bytes.Buffer{}
+	formatter := NewFormatter("json", "", WithOutputFile(path))
+	formatter.writer = stdout
+
+	err := formatter.PrintTable(Table{
+		Headers: []string{"NAME"},
+		Rows:    [][]string{{"snapshot-1"}},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"NAME":"snapshot-1"}]`, string(data))
+	assert.Empty(t, stdout.String())
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestFormatter_WithOutputFile_MissingDirectory(t *testing.T) {
+	formatter := NewFormatter("json", "", WithOutputFile(filepath.Join(t.TempDir(), "missing", "out.json")))
+
+	err := formatter.PrintTable(Table{Headers: []string{"NAME"}, Rows: [][]string{{"snapshot-1"}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write output file")
+}
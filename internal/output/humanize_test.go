@@ -0,0 +1,47 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{bytes: 0, expected: "0 B"},
+		{bytes: 1023, expected: "1023 B"},
+		{bytes: 1024, expected: "1.0 KiB"},
+		{bytes: 1536, expected: "1.5 KiB"},
+		{bytes: 5 * 1024 * 1024, expected: "5.0 MiB"},
+		{bytes: 3 * 1024 * 1024 * 1024 / 2, expected: "1.5 GiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatBytes(tt.bytes))
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{duration: -time.Second, expected: "0s"},
+		{duration: 42 * time.Second, expected: "42s"},
+		{duration: 5*time.Minute + 3*time.Second, expected: "5m3s"},
+		{duration: 5*time.Hour + 12*time.Minute, expected: "5h12m"},
+		{duration: 76 * time.Hour, expected: "3d4h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatAge(tt.duration))
+		})
+	}
+}
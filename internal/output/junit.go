@@ -0,0 +1,100 @@
+package output
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// Check statuses rendered by PrintChecks. In JUnit output FAIL becomes a failure and SKIP a skipped test case;
+// any other status passes.
+const (
+	CheckFail = "FAIL"
+	CheckSkip = "SKIP"
+)
+
+// Check is the result of a single verification, such as a doctor check
+type Check struct {
+	Name    string
+	Status  string
+	Details string
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	ID       string          `xml:"id,attr,omitempty"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// PrintChecks prints verification results. In JUnit format they are printed as a test suite named suite with a
+// test case per check, so CI systems can show them in their test report; in the other formats as a table with
+// the columns CHECK, STATUS and DETAILS.
+func (f *Formatter) PrintChecks(suite string, checks []Check) error {
+	if f.format != FormatJUnit {
+		return f.PrintTable(checksTable(checks))
+	}
+	return f.write(func(w io.Writer) error {
+		return f.printJUnit(w, suite, checks)
+	})
+}
+
+// checksTable converts checks into a table with a colored STATUS column
+func checksTable(checks []Check) Table {
+	table := Table{
+		Headers:      []string{"CHECK", "STATUS", "DETAILS"},
+		Rows:         make([][]string, 0, len(checks)),
+		StateColumns: []string{"STATUS"},
+	}
+	for _, check := range checks {
+		table.Rows = append(table.Rows, []string{check.Name, check.Status, check.Details})
+	}
+	return table
+}
+
+// printJUnit prints checks as a JUnit XML test suite, with secrets masked. The run ID is the ID of the suite.
+func (f *Formatter) printJUnit(out io.Writer, suite string, checks []Check) error {
+	testSuite := junitTestSuite{Name: suite, Tests: len(checks), ID: f.runID, Cases: make([]junitTestCase, 0, len(checks))}
+	for _, check := range checks {
+		testCase := junitTestCase{Name: check.Name, ClassName: suite}
+		switch check.Status {
+		case CheckFail:
+			testSuite.Failures++
+			testCase.Failure = &junitMessage{Message: check.Details}
+		case CheckSkip:
+			testSuite.Skipped++
+			testCase.Skipped = &junitMessage{Message: check.Details}
+		default:
+			testCase.SystemOut = check.Status + ": " + check.Details
+		}
+		testSuite.Cases = append(testSuite.Cases, testCase)
+	}
+
+	encoded, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{testSuite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, xml.Header+redact.String(string(encoded))+"\n")
+	return err
+}
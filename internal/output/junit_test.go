@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testChecks = []Check{
+	{Name: "Kubernetes API", Status: "PASS", Details: "connected"},
+	{Name: "Secret", Status: "WARN", Details: "not found"},
+	{Name: "Snapshot repository", Status: "FAIL", Details: "repository <sts-backup> missing"},
+	{Name: "SLM policy", Status: "SKIP", Details: "repository missing"},
+}
+
+func TestFormatter_PrintChecks_JUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{
+		writer: buf,
+		format: FormatJUnit,
+		runID:  "run-1",
+	}
+
+	require.NoError(t, formatter.PrintChecks("doctor", testChecks))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="doctor" tests="4" failures="1" skipped="1" id="run-1">
+    <testcase name="Kubernetes API" classname="doctor">
+      <system-out>PASS: connected</system-out>
+    </testcase>
+    <testcase name="Secret" classname="doctor">
+      <system-out>WARN: not found</system-out>
+    </testcase>
+    <testcase name="Snapshot repository" classname="doctor">
+      <failure message="repository &lt;sts-backup&gt; missing"></failure>
+    </testcase>
+    <testcase name="SLM policy" classname="doctor">
+      <skipped message="repository missing"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestFormatter_PrintChecks_Table(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := &Formatter{
+		writer: buf,
+		format: FormatCSV,
+	}
+
+	require.NoError(t, formatter.PrintChecks("doctor", testChecks[:1]))
+
+	assert.Equal(t, "CHECK,STATUS,DETAILS\nKubernetes API,PASS,connected\n", buf.String())
+}
+
+func TestFormatter_PrintTable_JUnitFallsBackToTable(t *testing.T) {
+	buf := &bytes.Buffer{}
+	formatter := NewFormatter("junit", "")
+	formatter.writer = buf
+
+	require.NoError(t, formatter.PrintTable(Table{Headers: []string{"NAME"}, Rows: [][]string{{"snapshot-1"}}}))
+
+	assert.Equal(t, "NAME\nsnapshot-1\n", buf.String())
+}
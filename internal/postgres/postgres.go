@@ -0,0 +1,192 @@
+// Package postgres dumps and restores the databases of a PostgreSQL server, such as the settings database, with
+// pg_dump and psql. The tools are run in the server pod, so no PostgreSQL client or network access to the server is
+// needed. Dumps are plain SQL compressed with gzip and are restored in a single transaction.
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Prefix is the key prefix under which dumps are stored in the bucket
+	Prefix = "dumps/postgres/"
+
+	// keyTimeFormat makes dump keys of one database sort chronologically
+	keyTimeFormat = "20060102T150405Z"
+	dumpExtension = ".sql.gz"
+
+	// maintenanceDatabase is connected to for queries about other databases
+	maintenanceDatabase = "postgres"
+)
+
+// ErrDatabaseNotFound is returned when a database does not exist
+var ErrDatabaseNotFound = errors.New("database does not exist")
+
+// envName matches the name of an environment variable
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Runner runs a command with the PostgreSQL tools, streaming stdin to it when it is not nil and what it writes to
+// stdout to stdout
+type Runner interface {
+	Stream(command []string, stdin io.Reader, stdout io.Writer) error
+}
+
+// Client runs the PostgreSQL tools as a user
+type Client struct {
+	runner      Runner
+	user        string
+	passwordEnv string
+}
+
+// NewClient creates a client running the tools as user. passwordEnv is the environment variable of the container
+// holding the password of the user, empty when the user needs none.
+func NewClient(runner Runner, user, passwordEnv string) (*Client, error) {
+	if passwordEnv != "" && !envName.MatchString(passwordEnv) {
+		return nil, fmt.Errorf("invalid password environment variable '%s'", passwordEnv)
+	}
+	return &Client{runner: runner, user: user, passwordEnv: passwordEnv}, nil
+}
+
+// DumpKey returns the object key of a dump of database created at createdAt
+func DumpKey(database string, createdAt time.Time) string {
+	return Prefix + path.Join(database, createdAt.UTC().Format(keyTimeFormat)) + dumpExtension
+}
+
+// ParseDumpKey returns the database and creation time of the dump stored under key
+func ParseDumpKey(key string) (string, time.Time, bool) {
+	rest, found := strings.CutPrefix(key, Prefix)
+	if !found {
+		return "", time.Time{}, false
+	}
+	rest, found = strings.CutSuffix(rest, dumpExtension)
+	if !found {
+		return "", time.Time{}, false
+	}
+	database, timestamp := path.Split(rest)
+	createdAt, err := time.Parse(keyTimeFormat, timestamp)
+	if err != nil || database == "" {
+		return "", time.Time{}, false
+	}
+	return strings.TrimSuffix(database, "/"), createdAt, true
+}
+
+// Dump writes a gzip-compressed plain SQL dump of database to w. The dump drops the objects it recreates, so it can
+// be restored into an existing database.
+func (c *Client) Dump(database string, w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	if err := c.runner.Stream(c.command("pg_dump", "--dbname", database, "--clean", "--if-exists"), nil, gz); err != nil {
+		return fmt.Errorf("failed to dump database %s: %w", database, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to dump database %s: %w", database, err)
+	}
+	return nil
+}
+
+// Restore runs a gzip-compressed dump read from r against database in a single transaction, so a failing restore
+// leaves the database as it was
+func (c *Client) Restore(database string, r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to read dump: %w", err)
+	}
+	defer gz.Close()
+
+	command := c.command("psql", "--dbname", database, "--single-transaction", "--quiet", "--set", "ON_ERROR_STOP=1")
+	if err := c.runner.Stream(command, gz, io.Discard); err != nil {
+		return fmt.Errorf("failed to restore database %s: %w", database, err)
+	}
+	return nil
+}
+
+// ConnectionLimit returns the connection limit of database, -1 for no limit
+func (c *Client) ConnectionLimit(database string) (int, error) {
+	statement := fmt.Sprintf("SELECT datconnlimit FROM pg_database WHERE datname = %s", quoteLiteral(database))
+	out, err := c.query(statement)
+	if err != nil {
+		return 0, err
+	}
+	if out == "" {
+		return 0, fmt.Errorf("%w: %s", ErrDatabaseNotFound, database)
+	}
+	return parseInt(statement, out)
+}
+
+// SetConnectionLimit sets the connection limit of database, -1 for no limit. Superusers are not limited, so a limit
+// of 0 blocks new connections of all other users.
+func (c *Client) SetConnectionLimit(database string, limit int) error {
+	_, err := c.query(fmt.Sprintf("ALTER DATABASE %s CONNECTION LIMIT %d", quoteIdentifier(database), limit))
+	return err
+}
+
+// Connections returns the number of sessions connected to database
+func (c *Client) Connections(database string) (int, error) {
+	return c.queryInt(fmt.Sprintf("SELECT count(*) FROM pg_stat_activity WHERE datname = %s AND pid <> pg_backend_pid()",
+		quoteLiteral(database)))
+}
+
+// TerminateConnections terminates the sessions connected to database and returns how many there were
+func (c *Client) TerminateConnections(database string) (int, error) {
+	return c.queryInt(fmt.Sprintf(
+		"SELECT count(pg_terminate_backend(pid)) FROM pg_stat_activity WHERE datname = %s AND pid <> pg_backend_pid()",
+		quoteLiteral(database)))
+}
+
+// query runs a statement against the maintenance database and returns its unaligned output
+func (c *Client) query(statement string) (string, error) {
+	var stdout bytes.Buffer
+	command := c.command("psql", "--dbname", maintenanceDatabase, "--no-align", "--tuples-only", "--set", "ON_ERROR_STOP=1",
+		"--command", statement)
+	if err := c.runner.Stream(command, nil, &stdout); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// queryInt runs a query returning a single number
+func (c *Client) queryInt(statement string) (int, error) {
+	out, err := c.query(statement)
+	if err != nil {
+		return 0, err
+	}
+	return parseInt(statement, out)
+}
+
+// parseInt parses the output of a query returning a single number
+func parseInt(statement, out string) (int, error) {
+	value, err := strconv.Atoi(out)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output of '%s': %q", statement, out)
+	}
+	return value, nil
+}
+
+// command returns the command running a tool as the user. With a password the tool is started by a shell that
+// passes the password from the container environment, so it never appears in the command line.
+func (c *Client) command(tool string, args ...string) []string {
+	args = append([]string{tool, "--username", c.user}, args...)
+	if c.passwordEnv == "" {
+		return args
+	}
+	script := fmt.Sprintf(`PGPASSWORD="$%s" exec "$0" "$@"`, c.passwordEnv)
+	return append([]string{"sh", "-c", script}, args...)
+}
+
+// quoteLiteral quotes a string literal
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quoteIdentifier quotes an identifier
+func quoteIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner writes canned output per tool, keeps what is streamed to it and records the commands it ran
+type fakeRunner struct {
+	outputs  map[string]string
+	errs     map[string]error
+	stdin    string
+	commands [][]string
+}
+
+func (f *fakeRunner) Stream(command []string, stdin io.Reader, stdout io.Writer) error {
+	f.commands = append(f.commands, command)
+	tool := command[0]
+	if tool == "sh" {
+		tool = command[3]
+	}
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return err
+		}
+		f.stdin = string(data)
+	}
+	if _, err := io.WriteString(stdout, f.outputs[tool]); err != nil {
+		return err
+	}
+	return f.errs[tool]
+}
+
+func TestDumpKey(t *testing.T) {
+	key := DumpKey("settings", time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC))
+	assert.Equal(t, "dumps/postgres/settings/20260301T123000Z.sql.gz", key)
+
+	database, createdAt, ok := ParseDumpKey(key)
+	assert.True(t, ok)
+	assert.Equal(t, "settings", database)
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC), createdAt)
+
+	for _, invalid := range []string{"exports/pipelines/20260301T123000Z.json", "dumps/postgres/settings/latest.sql.gz", "dumps/postgres/20260301T123000Z.sql.gz"} {
+		_, _, ok := ParseDumpKey(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestNewClient_InvalidPasswordEnv(t *testing.T) {
+	_, err := NewClient(&fakeRunner{}, "postgres", "PASSWORD; rm -rf /")
+	assert.Error(t, err)
+}
+
+func TestClient_DumpAndRestore(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{"pg_dump": "CREATE TABLE settings (id int);\n"}}
+	client, err := NewClient(runner, "postgres", "POSTGRES_PASSWORD")
+	require.NoError(t, err)
+
+	var dump bytes.Buffer
+	require.NoError(t, client.Dump("settings", &dump))
+	gz, err := gzip.NewReader(bytes.NewReader(dump.Bytes()))
+	require.NoError(t, err)
+	sql, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE TABLE settings (id int);\n", string(sql), "the dump is compressed")
+
+	require.NoError(t, client.Restore("settings", &dump))
+	assert.Equal(t, "CREATE TABLE settings (id int);\n", runner.stdin, "the dump is decompressed")
+
+	assert.Equal(t, [][]string{
+		{"sh", "-c", `PGPASSWORD="$POSTGRES_PASSWORD" exec "$0" "$@"`,
+			"pg_dump", "--username", "postgres", "--dbname", "settings", "--clean", "--if-exists"},
+		{"sh", "-c", `PGPASSWORD="$POSTGRES_PASSWORD" exec "$0" "$@"`,
+			"psql", "--username", "postgres", "--dbname", "settings", "--single-transaction", "--quiet", "--set", "ON_ERROR_STOP=1"},
+	}, runner.commands)
+}
+
+func TestClient_Dump_Fails(t *testing.T) {
+	runner := &fakeRunner{errs: map[string]error{"pg_dump": errors.New(`database "settings" does not exist`)}}
+	client, err := NewClient(runner, "postgres", "")
+	require.NoError(t, err)
+
+	err = client.Dump("settings", io.Discard)
+
+	require.ErrorContains(t, err, "failed to dump database settings")
+	assert.Equal(t, "pg_dump", runner.commands[0][0], "without a password the tool is run directly")
+}
+
+func TestClient_Connections(t *testing.T) {
+	runner := &fakeRunner{outputs: map[string]string{"psql": "3\n"}}
+	client, err := NewClient(runner, "postgres", "")
+	require.NoError(t, err)
+
+	count, err := client.TerminateConnections("o'brien")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	require.NoError(t, client.SetConnectionLimit(`my"db`, 0))
+
+	statements := make([]string, 0, len(runner.commands))
+	for _, command := range runner.commands {
+		statements = append(statements, command[len(command)-1])
+	}
+	assert.Equal(t, []string{
+		"SELECT count(pg_terminate_backend(pid)) FROM pg_stat_activity WHERE datname = 'o''brien' AND pid <> pg_backend_pid()",
+		`ALTER DATABASE "my""db" CONNECTION LIMIT 0`,
+	}, statements)
+	assert.Equal(t, "--dbname postgres", strings.Join(runner.commands[0][3:5], " "), "queries run against the maintenance database")
+
+	runner.outputs["psql"] = "ERROR"
+	_, err = client.Connections("settings")
+	assert.ErrorContains(t, err, "unexpected output")
+}
@@ -0,0 +1,118 @@
+// Package prompt asks the operator to confirm destructive operations.
+// When stdin is not a terminal (CI pipelines, Kubernetes Jobs) prompts fail
+// immediately instead of blocking forever, unless --yes was given.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/color"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"golang.org/x/term"
+)
+
+// Prompter asks yes/no questions before destructive operations
+type Prompter struct {
+	in          *bufio.Reader
+	out         io.Writer
+	assumeYes   bool
+	interactive bool
+}
+
+// New creates a Prompter reading from stdin and writing to stdout.
+// With assumeYes every confirmation is granted without asking.
+func New(assumeYes bool) *Prompter {
+	return &Prompter{
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stdout,
+		assumeYes:   assumeYes,
+		interactive: term.IsTerminal(int(os.Stdin.Fd())),
+	}
+}
+
+// NewWithIO creates a Prompter for the given reader and writer (used in tests)
+func NewWithIO(in io.Reader, out io.Writer, assumeYes, interactive bool) *Prompter {
+	return &Prompter{
+		in:          bufio.NewReader(in),
+		out:         out,
+		assumeYes:   assumeYes,
+		interactive: interactive,
+	}
+}
+
+// Interactive reports whether stdin is a terminal the user can answer prompts on
+func (p *Prompter) Interactive() bool {
+	return p.interactive
+}
+
+// Confirm asks question and returns nil if the user answered yes.
+// A declined prompt returns an error with exit code Cancelled; a prompt that cannot be
+// shown because stdin is not a terminal returns an error with exit code Usage.
+func (p *Prompter) Confirm(question string) error {
+	if p.assumeYes {
+		return nil
+	}
+	if err := p.requireInteractive(); err != nil {
+		return err
+	}
+
+	response, err := p.ask(color.Sprint(p.out, color.Yellow, question+" (yes/no):"))
+	if err != nil {
+		return err
+	}
+	response = strings.ToLower(response)
+	if response != "yes" && response != "y" {
+		return exitcode.Wrap(exitcode.Cancelled, fmt.Errorf("cancelled by user"))
+	}
+	return nil
+}
+
+// ConfirmValue asks the user to type expected (e.g. a snapshot name) to confirm an operation.
+// This guards high-impact operations better than a yes/no answer, which is easily given by reflex.
+func (p *Prompter) ConfirmValue(question, expected string) error {
+	if p.assumeYes {
+		return nil
+	}
+	if err := p.requireInteractive(); err != nil {
+		return err
+	}
+
+	response, err := p.ask(color.Sprint(p.out, color.Yellow, question))
+	if err != nil {
+		return err
+	}
+	if response != expected {
+		return exitcode.Wrap(exitcode.Cancelled, fmt.Errorf("cancelled by user: input did not match '%s'", expected))
+	}
+	return nil
+}
+
+// Ask prints question and returns the trimmed answer. It always requires a terminal, even with --yes.
+func (p *Prompter) Ask(question string) (string, error) {
+	if err := p.requireInteractive(); err != nil {
+		return "", err
+	}
+	return p.ask(question)
+}
+
+// requireInteractive fails when prompts cannot be answered because stdin is not a terminal
+func (p *Prompter) requireInteractive() error {
+	if !p.interactive {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("confirmation required but stdin is not a terminal: use --yes to run non-interactively"))
+	}
+	return nil
+}
+
+// ask prints question and reads a single line of input
+func (p *Prompter) ask(question string) (string, error) {
+	_, _ = fmt.Fprint(p.out, "\n"+question+" ")
+	response, err := p.in.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
@@ -0,0 +1,98 @@
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrompter_Confirm(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		assumeYes    bool
+		interactive  bool
+		expectedCode int
+		expectPrompt bool
+	}{
+		{name: "yes", input: "yes\n", interactive: true, expectedCode: exitcode.Success, expectPrompt: true},
+		{name: "short yes with whitespace", input: "  Y \n", interactive: true, expectedCode: exitcode.Success, expectPrompt: true},
+		{name: "no", input: "no\n", interactive: true, expectedCode: exitcode.Cancelled, expectPrompt: true},
+		{name: "empty answer", input: "\n", interactive: true, expectedCode: exitcode.Cancelled, expectPrompt: true},
+		{name: "eof", input: "", interactive: true, expectedCode: exitcode.Failure, expectPrompt: true},
+		{name: "assume yes", input: "", assumeYes: true, expectedCode: exitcode.Success},
+		{name: "assume yes without terminal", input: "", assumeYes: true, interactive: false, expectedCode: exitcode.Success},
+		{name: "not a terminal", input: "yes\n", interactive: false, expectedCode: exitcode.Usage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			p := NewWithIO(strings.NewReader(tt.input), out, tt.assumeYes, tt.interactive)
+
+			err := p.Confirm("Delete everything?")
+
+			assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+			if tt.expectPrompt {
+				assert.Contains(t, out.String(), "Delete everything? (yes/no):")
+			} else {
+				assert.Empty(t, out.String())
+			}
+		})
+	}
+}
+
+func TestPrompter_NonInteractiveMentionsYesFlag(t *testing.T) {
+	p := NewWithIO(strings.NewReader(""), &bytes.Buffer{}, false, false)
+
+	err := p.Confirm("Delete everything?")
+
+	assert.ErrorContains(t, err, "--yes")
+}
+
+func TestPrompter_ConfirmValue(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		assumeYes    bool
+		interactive  bool
+		expectedCode int
+	}{
+		{name: "matching value", input: "snap-1\n", interactive: true, expectedCode: exitcode.Success},
+		{name: "different value", input: "snap-2\n", interactive: true, expectedCode: exitcode.Cancelled},
+		{name: "yes is not enough", input: "yes\n", interactive: true, expectedCode: exitcode.Cancelled},
+		{name: "assume yes", input: "", assumeYes: true, expectedCode: exitcode.Success},
+		{name: "not a terminal", input: "snap-1\n", interactive: false, expectedCode: exitcode.Usage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewWithIO(strings.NewReader(tt.input), &bytes.Buffer{}, tt.assumeYes, tt.interactive)
+
+			err := p.ConfirmValue("Type the snapshot name to confirm:", "snap-1")
+
+			assert.Equal(t, tt.expectedCode, exitcode.Of(err))
+		})
+	}
+}
+
+func TestPrompter_AskReadsSuccessiveLines(t *testing.T) {
+	p := NewWithIO(strings.NewReader("3\nyes\n"), &bytes.Buffer{}, false, true)
+
+	answer, err := p.Ask("Select:")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", answer)
+
+	assert.NoError(t, p.Confirm("Continue?"))
+}
+
+func TestPrompter_AskRequiresTerminalEvenWithYes(t *testing.T) {
+	p := NewWithIO(strings.NewReader("3\n"), &bytes.Buffer{}, true, false)
+
+	_, err := p.Ask("Select:")
+
+	assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+}
@@ -0,0 +1,49 @@
+// Package proxy selects the HTTP proxy for connections to the Kubernetes API server and Elasticsearch, for
+// environments where the cluster is only reachable through a corporate proxy or jump host.
+//
+// Without an explicit proxy the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used, like
+// kubectl and curl do. Requests to localhost, such as to port-forwarded services, are never proxied.
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// Func returns the function selecting the proxy of a request. With an empty proxyURL the proxy is taken from
+// the environment, otherwise all requests go through proxyURL except those to hosts listed in NO_PROXY.
+func Func(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	cfg := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		if err := Validate(proxyURL); err != nil {
+			return nil, err
+		}
+		cfg.HTTPProxy = proxyURL
+		cfg.HTTPSProxy = proxyURL
+	}
+
+	proxyFunc := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}, nil
+}
+
+// Validate checks that proxyURL is an absolute http, https or socks5 URL
+func Validate(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL '%s': %w", proxyURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy URL '%s': scheme must be http, https or socks5", proxyURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy URL '%s': no host", proxyURL)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+// Package readonly refuses the requests that change state when the CLI runs with --read-only. The clients of
+// Kubernetes, Elasticsearch and S3 wrap their transport with it, so no command can delete, restore, configure or
+// scale anything, whatever it was written to do.
+package readonly
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+)
+
+// ErrReadOnly is returned for requests refused in read-only mode
+var ErrReadOnly = errors.New("refused in read-only mode (--read-only)")
+
+// Transport is an http.RoundTripper that only passes requests with a safe method (GET, HEAD, OPTIONS) and the
+// requests Allow accepts, e.g. searches that are sent as POST but do not change anything
+type Transport struct {
+	Next http.RoundTripper
+	// Allow accepts requests with other methods; nil accepts none
+	Allow func(req *http.Request) bool
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !SafeMethod(req.Method) && (t.Allow == nil || !t.Allow(req)) {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, exitcode.Wrap(exitcode.Usage, fmt.Errorf("%w: %s %s", ErrReadOnly, req.Method, req.URL.Path))
+	}
+	return t.Next.RoundTrip(req)
+}
+
+// SafeMethod reports whether an HTTP method does not change state
+func SafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
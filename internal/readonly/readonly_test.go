@@ -0,0 +1,52 @@
+package readonly
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &Transport{
+		Next:  http.DefaultTransport,
+		Allow: func(req *http.Request) bool { return strings.HasSuffix(req.URL.Path, "/_search") },
+	}}
+
+	for _, tt := range []struct {
+		method  string
+		path    string
+		allowed bool
+	}{
+		{method: http.MethodGet, path: "/index", allowed: true},
+		{method: http.MethodHead, path: "/index", allowed: true},
+		{method: http.MethodPost, path: "/index/_search", allowed: true},
+		{method: http.MethodPost, path: "/index/_doc"},
+		{method: http.MethodPut, path: "/index"},
+		{method: http.MethodDelete, path: "/index"},
+	} {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader("{}"))
+			require.NoError(t, err)
+
+			res, err := client.Do(req)
+
+			if tt.allowed {
+				require.NoError(t, err)
+				_ = res.Body.Close()
+				return
+			}
+			assert.ErrorIs(t, err, ErrReadOnly)
+			assert.Equal(t, exitcode.Usage, exitcode.Of(err))
+			assert.ErrorContains(t, err, tt.method+" "+tt.path)
+		})
+	}
+}
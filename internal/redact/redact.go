@@ -0,0 +1,74 @@
+// Package redact masks known secret values, such as the snapshot repository credentials, in text before it is
+// printed or written to files. Secrets are registered once when the configuration is loaded.
+package redact
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// Mask replaces secret values
+	Mask = "****"
+
+	// minSecretLength ignores values too short to be a real secret, which would mask unrelated text
+	minSecretLength = 4
+)
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// Register adds values to mask in all redacted output. Empty and very short values are ignored.
+func Register(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, value := range values {
+		if len(value) < minSecretLength {
+			continue
+		}
+		// Also mask the secret as it appears inside JSON strings, e.g. with escaped quotes or ampersands
+		for _, variant := range []string{value, jsonEscaped(value)} {
+			if !slices.Contains(secrets, variant) {
+				secrets = append(secrets, variant)
+			}
+		}
+	}
+	// Longest first, so a secret containing another one is masked as a whole
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+}
+
+// String returns s with every registered secret replaced by Mask
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return s
+}
+
+// Error returns the message of err with every registered secret replaced by Mask, or "" for a nil err
+func Error(err error) string {
+	if err == nil {
+		return ""
+	}
+	return String(err.Error())
+}
+
+// jsonEscaped returns value as encoded inside a JSON string
+func jsonEscaped(value string) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(encoded[1 : len(encoded)-1])
+}
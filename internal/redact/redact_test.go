@@ -0,0 +1,65 @@
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reset clears the registered secrets between tests
+func reset(t *testing.T) {
+	t.Helper()
+	mu.Lock()
+	secrets = nil
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		secrets = nil
+		mu.Unlock()
+	})
+}
+
+func TestString(t *testing.T) {
+	reset(t)
+	Register("AKIAEXAMPLE", "wJalrXUtnFEMI/K7MDENG", "")
+
+	body := `{"settings":{"access_key":"AKIAEXAMPLE","secret_key":"wJalrXUtnFEMI/K7MDENG","bucket":"backups"}}`
+	assert.Equal(t, `{"settings":{"access_key":"****","secret_key":"****","bucket":"backups"}}`, String(body))
+}
+
+func TestString_NothingRegistered(t *testing.T) {
+	reset(t)
+	assert.Equal(t, "secret_key: value", String("secret_key: value"))
+}
+
+func TestRegister_IgnoresShortValues(t *testing.T) {
+	reset(t)
+	Register("abc")
+
+	assert.Equal(t, "abc", String("abc"))
+}
+
+func TestRegister_LongestFirst(t *testing.T) {
+	reset(t)
+	Register("token", "token-with-suffix")
+
+	assert.Equal(t, "url?t=****", String("url?t=token-with-suffix"))
+}
+
+func TestError(t *testing.T) {
+	reset(t)
+	Register("hunter22")
+
+	err := fmt.Errorf("failed to create repository: %w", errors.New(`invalid secret_key "hunter22"`))
+	assert.Equal(t, `failed to create repository: invalid secret_key "****"`, Error(err))
+	assert.Empty(t, Error(nil))
+}
+
+func TestString_JSONEscaped(t *testing.T) {
+	reset(t)
+	Register("https://hooks.example.com/T0?a=1&b=2")
+
+	assert.Equal(t, `{"url":"****"}`, String(`{"url":"https://hooks.example.com/T0?a=1\u0026b=2"}`))
+}
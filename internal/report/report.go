@@ -0,0 +1,233 @@
+// Package report renders a summary of an operation, such as a restore, as a Markdown or HTML document that
+// can be attached to a change ticket.
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/output"
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+)
+
+// Check statuses
+const (
+	StatusPass = "PASS"
+	StatusWarn = "WARN"
+	StatusFail = "FAIL"
+)
+
+// Report is the summary of a single operation
+type Report struct {
+	Title      string
+	RunID      string
+	Namespace  string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Error is the error the operation failed with, empty when it succeeded
+	Error string
+	// Details are the parameters of the operation, such as the snapshot and its size, in order
+	Details []Detail
+	// Steps are the timed steps of the operation, in the order they ran
+	Steps []Step
+	// Indices are the indices the operation restored or created
+	Indices []string
+	// Checks are the validations done after the operation
+	Checks   []Check
+	Warnings []string
+}
+
+// Detail is a named parameter of an operation
+type Detail struct {
+	Name  string
+	Value string
+}
+
+// Step is a timed step of an operation
+type Step struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Check is the result of a validation
+type Check struct {
+	Name    string
+	Status  string
+	Details string
+}
+
+// Outcome is "succeeded" or "failed"
+func (r *Report) Outcome() string {
+	if r.Error != "" {
+		return "failed"
+	}
+	return "succeeded"
+}
+
+// Duration is the time the operation took, rounded to the second
+func (r *Report) Duration() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt).Round(time.Second)
+}
+
+// Write writes the report to path, as HTML when the file name ends in .html or .htm and as Markdown otherwise.
+// Registered secrets are masked (see redact).
+func Write(path string, r *Report) error {
+	var data []byte
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		html, err := HTML(r)
+		if err != nil {
+			return err
+		}
+		data = html
+	default:
+		data = Markdown(r)
+	}
+
+	if err := output.WriteFileAtomic(path, []byte(redact.String(string(data)))); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// Markdown renders the report as a Markdown document
+func Markdown(r *Report) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", r.Title)
+
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	for _, detail := range r.summary() {
+		fmt.Fprintf(&b, "| %s | %s |\n", detail.Name, markdownCell(detail.Value))
+	}
+	if r.Error != "" {
+		fmt.Fprintf(&b, "\n**Error:** %s\n", r.Error)
+	}
+
+	if len(r.Steps) > 0 {
+		fmt.Fprintf(&b, "\n## Timings\n\n| Step | Duration |\n|---|---|\n")
+		for _, step := range r.Steps {
+			fmt.Fprintf(&b, "| %s | %s |\n", markdownCell(step.Name), step.Duration.Round(time.Second))
+		}
+	}
+
+	if len(r.Checks) > 0 {
+		fmt.Fprintf(&b, "\n## Validation\n\n| Check | Status | Details |\n|---|---|---|\n")
+		for _, check := range r.Checks {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(check.Name), check.Status, markdownCell(check.Details))
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		fmt.Fprintf(&b, "\n## Warnings\n\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+	}
+
+	if len(r.Indices) > 0 {
+		fmt.Fprintf(&b, "\n## Indices (%d)\n\n", len(r.Indices))
+		for _, index := range r.Indices {
+			fmt.Fprintf(&b, "- `%s`\n", index)
+		}
+	}
+	return b.Bytes()
+}
+
+// markdownCell escapes the characters that would break a Markdown table cell
+func markdownCell(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Report.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+.PASS { color: #2e7d32; } .WARN { color: #ef6c00; } .FAIL { color: #c62828; }
+</style>
+</head>
+<body>
+<h1>{{.Report.Title}}</h1>
+<table>
+{{- range .Summary}}
+<tr><th>{{.Name}}</th><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- if .Report.Error}}
+<p><strong>Error:</strong> {{.Report.Error}}</p>
+{{- end}}
+{{- if .Report.Steps}}
+<h2>Timings</h2>
+<table>
+<tr><th>Step</th><th>Duration</th></tr>
+{{- range .Report.Steps}}
+<tr><td>{{.Name}}</td><td>{{.Duration.Round 1000000000}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Report.Checks}}
+<h2>Validation</h2>
+<table>
+<tr><th>Check</th><th>Status</th><th>Details</th></tr>
+{{- range .Report.Checks}}
+<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Details}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Report.Warnings}}
+<h2>Warnings</h2>
+<ul>
+{{- range .Report.Warnings}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Report.Indices}}
+<h2>Indices ({{len .Report.Indices}})</h2>
+<ul>
+{{- range .Report.Indices}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone HTML document
+func HTML(r *Report) ([]byte, error) {
+	var b bytes.Buffer
+	data := struct {
+		Report  *Report
+		Summary []Detail
+	}{Report: r, Summary: r.summary()}
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+// summary returns the overview rows shown at the top of the report, followed by the details
+func (r *Report) summary() []Detail {
+	summary := []Detail{{Name: "Outcome", Value: r.Outcome()}}
+	if r.Namespace != "" {
+		summary = append(summary, Detail{Name: "Namespace", Value: r.Namespace})
+	}
+	if r.RunID != "" {
+		summary = append(summary, Detail{Name: "Run ID", Value: r.RunID})
+	}
+	summary = append(summary,
+		Detail{Name: "Started", Value: r.StartedAt.UTC().Format(time.RFC3339)},
+		Detail{Name: "Finished", Value: r.FinishedAt.UTC().Format(time.RFC3339)},
+		Detail{Name: "Duration", Value: r.Duration().String()},
+	)
+	return append(summary, r.Details...)
+}
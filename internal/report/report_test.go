@@ -0,0 +1,99 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	started := time.Date(2025, 3, 4, 5, 0, 0, 0, time.UTC)
+	return &Report{
+		Title:      "Restore of snapshot sts-backup-20250304-0300",
+		RunID:      "20250304T050000-abc123",
+		Namespace:  "suse-observability",
+		StartedAt:  started,
+		FinishedAt: started.Add(12*time.Minute + 30*time.Second),
+		Details:    []Detail{{Name: "Snapshot size", Value: "1.5 GiB"}},
+		Steps:      []Step{{Name: "Restore snapshot", Duration: 10*time.Minute + 400*time.Millisecond}},
+		Indices:    []string{"sts_topology"},
+		Checks:     []Check{{Name: "Restored indices", Status: StatusPass, Details: "1 of 1 snapshot index(es) present"}},
+		Warnings:   []string{"Excluding from the restore: sts_k8s_logs*"},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := string(Markdown(testReport()))
+
+	assert.Contains(t, md, "# Restore of snapshot sts-backup-20250304-0300\n")
+	assert.Contains(t, md, "| Outcome | succeeded |")
+	assert.Contains(t, md, "| Run ID | 20250304T050000-abc123 |")
+	assert.Contains(t, md, "| Duration | 12m30s |")
+	assert.Contains(t, md, "| Snapshot size | 1.5 GiB |")
+	assert.Contains(t, md, "| Restore snapshot | 10m0s |")
+	assert.Contains(t, md, "| Restored indices | PASS | 1 of 1 snapshot index(es) present |")
+	assert.Contains(t, md, "- Excluding from the restore: sts_k8s_logs*")
+	assert.Contains(t, md, "## Indices (1)\n\n- `sts_topology`")
+	assert.NotContains(t, md, "**Error:**")
+}
+
+func TestMarkdown_Failed(t *testing.T) {
+	r := testReport()
+	r.Error = "restore incomplete | 2 shard(s) failed"
+
+	md := string(Markdown(r))
+
+	assert.Contains(t, md, "| Outcome | failed |")
+	assert.Contains(t, md, "**Error:** restore incomplete | 2 shard(s) failed")
+}
+
+func TestHTML(t *testing.T) {
+	r := testReport()
+	r.Warnings = []string{"<script>alert(1)</script>"}
+
+	html, err := HTML(r)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(html), "<h1>Restore of snapshot sts-backup-20250304-0300</h1>")
+	assert.Contains(t, string(html), `<td class="PASS">PASS</td>`)
+	assert.Contains(t, string(html), "<tr><td>Restore snapshot</td><td>10m0s</td></tr>")
+	assert.Contains(t, string(html), "&lt;script&gt;")
+	assert.NotContains(t, string(html), "<script>")
+}
+
+func TestWrite(t *testing.T) {
+	redact.Register("report-test-secret")
+	r := testReport()
+	r.Error = "upload to http://minio?key=report-test-secret failed"
+
+	tests := []struct {
+		file     string
+		contains string
+	}{
+		{file: "restore-report.md", contains: "# Restore of snapshot"},
+		{file: "restore-report.html", contains: "<!DOCTYPE html>"},
+		{file: "restore-report.HTM", contains: "<!DOCTYPE html>"},
+		{file: "restore-report", contains: "# Restore of snapshot"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, Write(path, r))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), tt.contains)
+			assert.NotContains(t, string(data), "report-test-secret")
+		})
+	}
+}
+
+func TestWrite_MissingDirectory(t *testing.T) {
+	err := Write(filepath.Join(t.TempDir(), "missing", "report.md"), testReport())
+	assert.ErrorContains(t, err, "failed to write report")
+}
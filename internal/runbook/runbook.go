@@ -0,0 +1,136 @@
+// Package runbook renders a disaster-recovery runbook: the ordered steps restoring every component of an
+// installation from its latest backups, with the commands to run and how long they are expected to take, as a
+// Markdown document for the people executing the recovery.
+package runbook
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Runbook is the recovery plan of one installation
+type Runbook struct {
+	Namespace   string    `json:"namespace"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	RunID       string    `json:"runId,omitempty"`
+	// Steps are the steps of the recovery, in the order they are to be run
+	Steps []Step `json:"steps"`
+	// Warnings are the components that cannot be recovered and other findings the plan could not resolve
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Step is a step of the recovery
+type Step struct {
+	Number      int    `json:"number"`
+	Component   string `json:"component"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	// Backups are the backups the step restores, e.g. snapshot names or object keys
+	Backups []string `json:"backups,omitempty"`
+	// Commands are run one after another
+	Commands []string `json:"commands"`
+	// Operation is the audited operation of the commands the expected duration is taken from, empty for steps that
+	// are not timed, such as checks
+	Operation string `json:"operation,omitempty"`
+	// ExpectedDurationMillis is the expected duration of the commands, 0 when no earlier run is known
+	ExpectedDurationMillis int64 `json:"expectedDurationMillis,omitempty"`
+	// DurationSamples is the number of earlier runs ExpectedDurationMillis is derived from
+	DurationSamples int `json:"durationSamples,omitempty"`
+}
+
+// ExpectedDuration is the expected duration of the step, 0 when unknown
+func (s *Step) ExpectedDuration() time.Duration {
+	return time.Duration(s.ExpectedDurationMillis) * time.Millisecond
+}
+
+// ExpectedDuration returns the sum of the expected durations of the steps and whether it is known for every timed step
+func (r *Runbook) ExpectedDuration() (time.Duration, bool) {
+	var total time.Duration
+	complete := true
+	for _, step := range r.Steps {
+		if step.Operation != "" && step.DurationSamples == 0 {
+			complete = false
+		}
+		total += step.ExpectedDuration()
+	}
+	return total, complete
+}
+
+// Add appends a step, numbering it after the existing steps
+func (r *Runbook) Add(step Step) {
+	step.Number = len(r.Steps) + 1
+	r.Steps = append(r.Steps, step)
+}
+
+// Markdown renders the runbook as a Markdown document
+func Markdown(r *Runbook) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Disaster recovery runbook: %s\n\n", r.Namespace)
+
+	total, complete := r.ExpectedDuration()
+	expected := formatDuration(total)
+	if !complete {
+		expected = "at least " + expected
+	}
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Namespace | %s |\n", markdownCell(r.Namespace))
+	fmt.Fprintf(&b, "| Generated | %s |\n", r.GeneratedAt.UTC().Format(time.RFC3339))
+	if r.RunID != "" {
+		fmt.Fprintf(&b, "| Run ID | %s |\n", markdownCell(r.RunID))
+	}
+	fmt.Fprintf(&b, "| Expected duration | %s |\n", expected)
+
+	if len(r.Warnings) > 0 {
+		fmt.Fprintf(&b, "\n## Warnings\n\n")
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", warning)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n## Overview\n\n| Step | Component | Backup | Expected duration |\n|---|---|---|---|\n")
+	for _, step := range r.Steps {
+		fmt.Fprintf(&b, "| %d. %s | %s | %s | %s |\n", step.Number, markdownCell(step.Title), markdownCell(step.Component),
+			markdownCell(strings.Join(step.Backups, ", ")), stepDuration(step))
+	}
+
+	for _, step := range r.Steps {
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", step.Number, step.Title)
+		if step.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", step.Description)
+		}
+		if step.Operation != "" {
+			fmt.Fprintf(&b, "Expected duration: %s\n\n", stepDuration(step))
+		}
+		fmt.Fprintf(&b, "```sh\n%s\n```\n", strings.Join(step.Commands, "\n"))
+	}
+	return b.Bytes()
+}
+
+// stepDuration describes the expected duration of a step and what it is based on
+func stepDuration(step Step) string {
+	switch {
+	case step.Operation == "":
+		return "-"
+	case step.DurationSamples == 0:
+		return "unknown"
+	case step.DurationSamples == 1:
+		return formatDuration(step.ExpectedDuration()) + " (1 earlier run)"
+	default:
+		return fmt.Sprintf("%s (median of %d earlier runs)", formatDuration(step.ExpectedDuration()), step.DurationSamples)
+	}
+}
+
+// formatDuration rounds d to the second, or to the minute from an hour on
+func formatDuration(d time.Duration) string {
+	if d >= time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return d.Round(time.Second).String()
+}
+
+// markdownCell escapes the characters that would break a Markdown table cell
+func markdownCell(value string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+}
@@ -0,0 +1,60 @@
+package runbook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRunbook() *Runbook {
+	r := &Runbook{
+		Namespace:   "suse-observability",
+		GeneratedAt: time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC),
+		RunID:       "20260304T050000-abc123",
+		Warnings:    []string{"Kafka: no metadata snapshot found"},
+	}
+	r.Add(Step{Component: "all", Title: "Check the installation", Commands: []string{"sts-backup doctor --namespace suse-observability"}})
+	r.Add(Step{
+		Component:              "postgres",
+		Title:                  "Restore the PostgreSQL databases",
+		Description:            "Replace each database with its latest dump.",
+		Backups:                []string{"dumps/postgres/a/20260301T000000Z.sql.gz", "dumps/postgres/b|c/20260301T000000Z.sql.gz"},
+		Commands:               []string{"sts-backup postgres restore --key a", "sts-backup postgres restore --key b"},
+		Operation:              "postgres-restore",
+		ExpectedDurationMillis: (90 * time.Second).Milliseconds(),
+		DurationSamples:        3,
+	})
+	r.Add(Step{Component: "stackgraph", Title: "Restore the StackGraph tables", Commands: []string{"sts-backup stackgraph hbase restore"}, Operation: "stackgraph-restore"})
+	return r
+}
+
+func TestExpectedDuration(t *testing.T) {
+	r := testRunbook()
+	total, complete := r.ExpectedDuration()
+	assert.Equal(t, 90*time.Second, total)
+	assert.False(t, complete, "the StackGraph restore never ran")
+
+	r.Steps[2].ExpectedDurationMillis = time.Hour.Milliseconds()
+	r.Steps[2].DurationSamples = 1
+	total, complete = r.ExpectedDuration()
+	assert.Equal(t, time.Hour+90*time.Second, total)
+	assert.True(t, complete, "untimed steps do not count")
+	assert.Equal(t, []int{1, 2, 3}, []int{r.Steps[0].Number, r.Steps[1].Number, r.Steps[2].Number})
+}
+
+func TestMarkdown(t *testing.T) {
+	md := string(Markdown(testRunbook()))
+
+	assert.Contains(t, md, "# Disaster recovery runbook: suse-observability\n")
+	assert.Contains(t, md, "| Generated | 2026-03-04T05:00:00Z |")
+	assert.Contains(t, md, "| Expected duration | at least 1m30s |")
+	assert.Contains(t, md, "- Kafka: no metadata snapshot found\n")
+	assert.Contains(t, md, "| 1. Check the installation | all |  | - |")
+	assert.Contains(t, md, `| 2. Restore the PostgreSQL databases | postgres | dumps/postgres/a/20260301T000000Z.sql.gz, dumps/postgres/b\|c/20260301T000000Z.sql.gz | 1m30s (median of 3 earlier runs) |`)
+	assert.Contains(t, md, "| 3. Restore the StackGraph tables | stackgraph |  | unknown |")
+	assert.Contains(t, md, "## 2. Restore the PostgreSQL databases\n\nReplace each database with its latest dump.\n\n"+
+		"Expected duration: 1m30s (median of 3 earlier runs)\n\n"+
+		"```sh\nsts-backup postgres restore --key a\nsts-backup postgres restore --key b\n```\n")
+	assert.Contains(t, md, "## 1. Check the installation\n\n```sh\nsts-backup doctor --namespace suse-observability\n```\n")
+}
@@ -0,0 +1,191 @@
+package s3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// serverInfoPath is the MinIO admin API resource describing the deployment
+const serverInfoPath = "/minio/admin/v3/info"
+
+// ErrNotMinIO is returned by the MinIO admin requests when the endpoint is not MinIO, e.g. AWS S3
+var ErrNotMinIO = errors.New("the S3 endpoint is not MinIO")
+
+// DriveStateOK is the state of a MinIO drive that is online and working
+const DriveStateOK = "ok"
+
+// Erasure set statuses
+const (
+	// SetStatusOK is a set with all drives online and none healing
+	SetStatusOK = "ok"
+	// SetStatusDegraded is a set with drives offline or healing that still has write quorum
+	SetStatusDegraded = "degraded"
+	// SetStatusReadOnly is a set below write quorum: existing objects can be read, new snapshots cannot be written
+	SetStatusReadOnly = "read-only"
+	// SetStatusUnavailable is a set below read quorum
+	SetStatusUnavailable = "unavailable"
+)
+
+// ServerInfo is the state of a MinIO deployment as reported by its admin API
+type ServerInfo struct {
+	Mode         string `json:"mode"`
+	DeploymentID string `json:"deploymentID"`
+	Buckets      struct {
+		Count uint64 `json:"count"`
+	} `json:"buckets"`
+	Objects struct {
+		Count uint64 `json:"count"`
+	} `json:"objects"`
+	Usage struct {
+		Size int64 `json:"size"`
+	} `json:"usage"`
+	Backend MinIOBackend  `json:"backend"`
+	Servers []MinIOServer `json:"servers"`
+}
+
+// MinIOBackend describes the erasure coding of a MinIO deployment
+type MinIOBackend struct {
+	Type         string `json:"backendType"`
+	OnlineDisks  int    `json:"onlineDisks"`
+	OfflineDisks int    `json:"offlineDisks"`
+	// StandardSCParity is the number of parity drives of every erasure set for the standard storage class
+	StandardSCParity  int   `json:"standardSCParity"`
+	TotalSets         []int `json:"totalSets"`
+	TotalDrivesPerSet []int `json:"totalDrivesPerSet"`
+}
+
+// MinIOServer is a node of a MinIO deployment
+type MinIOServer struct {
+	State    string       `json:"state"`
+	Endpoint string       `json:"endpoint"`
+	Uptime   int64        `json:"uptime"`
+	Version  string       `json:"version"`
+	Drives   []MinIODrive `json:"drives"`
+}
+
+// MinIODrive is a drive of a MinIO node
+type MinIODrive struct {
+	Endpoint   string `json:"endpoint"`
+	State      string `json:"state"`
+	Healing    bool   `json:"healing"`
+	TotalSpace int64  `json:"totalspace"`
+	UsedSpace  int64  `json:"usedspace"`
+	AvailSpace int64  `json:"availspace"`
+	PoolIndex  int    `json:"pool_index"`
+	SetIndex   int    `json:"set_index"`
+}
+
+// ErasureSet is the health of an erasure set, the group of drives every object is striped over
+type ErasureSet struct {
+	Pool    int    `json:"pool"`
+	Set     int    `json:"set"`
+	Drives  int    `json:"drives"`
+	Online  int    `json:"online"`
+	Healing int    `json:"healing"`
+	Parity  int    `json:"parity"`
+	Status  string `json:"status"`
+}
+
+// ErasureSets returns the erasure sets of the deployment, ordered by pool and set, with their status derived from the
+// number of online drives and the read and write quorum of the standard storage class
+func (i *ServerInfo) ErasureSets() []ErasureSet {
+	type setKey struct{ pool, set int }
+	sets := map[setKey]*ErasureSet{}
+	var keys []setKey
+	for _, server := range i.Servers {
+		for _, drive := range server.Drives {
+			key := setKey{drive.PoolIndex, drive.SetIndex}
+			set, ok := sets[key]
+			if !ok {
+				set = &ErasureSet{Pool: key.pool, Set: key.set, Parity: i.Backend.StandardSCParity}
+				sets[key] = set
+				keys = append(keys, key)
+			}
+			set.Drives++
+			if drive.State == DriveStateOK {
+				set.Online++
+			}
+			if drive.Healing {
+				set.Healing++
+			}
+		}
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].pool != keys[b].pool {
+			return keys[a].pool < keys[b].pool
+		}
+		return keys[a].set < keys[b].set
+	})
+
+	result := make([]ErasureSet, 0, len(keys))
+	for _, key := range keys {
+		set := sets[key]
+		set.Status = set.status()
+		result = append(result, *set)
+	}
+	return result
+}
+
+// status compares the online drives with the quorum: reads need all but parity drives, writes one more when
+// exactly half of the drives are parity
+func (s *ErasureSet) status() string {
+	readQuorum := s.Drives - s.Parity
+	writeQuorum := readQuorum
+	if s.Parity*2 == s.Drives {
+		writeQuorum++
+	}
+	switch {
+	case s.Online < readQuorum:
+		return SetStatusUnavailable
+	case s.Online < writeQuorum:
+		return SetStatusReadOnly
+	case s.Online < s.Drives || s.Healing > 0:
+		return SetStatusDegraded
+	default:
+		return SetStatusOK
+	}
+}
+
+// ServerInfo returns the state of the MinIO deployment behind the endpoint. It requires credentials with the
+// admin:ServerInfo permission and returns ErrNotMinIO when the endpoint is another S3 implementation.
+func (c *Client) ServerInfo() (*ServerInfo, error) {
+	res, err := c.do(http.MethodGet, serverInfoPath, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if !strings.HasPrefix(res.Header.Get("Server"), "MinIO") {
+		return nil, fmt.Errorf("%w (server: %s)", ErrNotMinIO, orUnknown(res.Header.Get("Server")))
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, adminResponseError(res)
+	}
+
+	var info ServerInfo
+	if err := json.NewDecoder(res.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to decode MinIO server info: %w", err)
+	}
+	return &info, nil
+}
+
+// adminResponseError converts an error response of the MinIO admin API, which is JSON rather than XML
+func adminResponseError(res *http.Response) error {
+	var body struct {
+		Code    string `json:"Code"`
+		Message string `json:"Message"`
+	}
+	_ = json.NewDecoder(res.Body).Decode(&body)
+	return &Error{StatusCode: res.StatusCode, Code: body.Code, Message: body.Message}
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
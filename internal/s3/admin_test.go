@@ -0,0 +1,106 @@
+package s3
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverInfoResponse = `{
+  "mode": "online",
+  "deploymentID": "2b1c9d4e",
+  "objects": {"count": 1200},
+  "usage": {"size": 4096},
+  "backend": {"backendType": "Erasure", "onlineDisks": 3, "offlineDisks": 1, "standardSCParity": 2, "totalSets": [1], "totalDrivesPerSet": [4]},
+  "servers": [{
+    "state": "online",
+    "endpoint": "minio-0.minio:9000",
+    "uptime": 3600,
+    "version": "2024-05-10T01:41:38Z",
+    "drives": [
+      {"endpoint": "/data0", "state": "ok", "totalspace": 1000, "usedspace": 400, "availspace": 600, "pool_index": 0, "set_index": 0},
+      {"endpoint": "/data1", "state": "ok", "healing": true, "totalspace": 1000, "usedspace": 100, "availspace": 900, "pool_index": 0, "set_index": 0},
+      {"endpoint": "/data2", "state": "ok", "totalspace": 1000, "usedspace": 400, "availspace": 600, "pool_index": 0, "set_index": 0},
+      {"endpoint": "/data3", "state": "offline", "pool_index": 0, "set_index": 0}
+    ]
+  }]
+}`
+
+func TestClient_ServerInfo(t *testing.T) {
+	tests := []struct {
+		name         string
+		server       string
+		status       int
+		body         string
+		expectedCode string
+		notMinIO     bool
+	}{
+		{name: "MinIO", server: "MinIO", status: http.StatusOK, body: serverInfoResponse},
+		{name: "missing admin permission", server: "MinIO", status: http.StatusForbidden, expectedCode: "AccessDenied",
+			body: `{"Code": "AccessDenied", "Message": "Access Denied."}`},
+		{name: "not MinIO", server: "AmazonS3", status: http.StatusForbidden, notMinIO: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/minio/admin/v3/info", r.URL.Path)
+				assert.Contains(t, r.Header.Get("Authorization"), "Credential=key/")
+				w.Header().Set("Server", tt.server)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "key", "secret", "")
+			require.NoError(t, err)
+
+			info, err := client.ServerInfo()
+
+			switch {
+			case tt.notMinIO:
+				assert.ErrorIs(t, err, ErrNotMinIO)
+			case tt.expectedCode != "":
+				var s3Err *Error
+				require.ErrorAs(t, err, &s3Err)
+				assert.Equal(t, tt.expectedCode, s3Err.Code)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, "online", info.Mode)
+				assert.Equal(t, 2, info.Backend.StandardSCParity)
+				require.Len(t, info.Servers, 1)
+				assert.Len(t, info.Servers[0].Drives, 4)
+				assert.True(t, info.Servers[0].Drives[1].Healing)
+			}
+		})
+	}
+}
+
+func TestServerInfo_ErasureSets(t *testing.T) {
+	drives := func(pool, set int, states ...string) []MinIODrive {
+		result := make([]MinIODrive, 0, len(states))
+		for _, state := range states {
+			result = append(result, MinIODrive{State: state, PoolIndex: pool, SetIndex: set})
+		}
+		return result
+	}
+	info := &ServerInfo{Backend: MinIOBackend{StandardSCParity: 2}}
+	info.Servers = []MinIOServer{
+		{Drives: append(drives(1, 0, "ok", "ok", "ok", "ok"), drives(0, 1, "ok", "ok", "offline", "offline")...)},
+		{Drives: append(drives(0, 0, "ok", "ok", "ok", "offline"), drives(0, 2, "ok", "offline", "offline", "offline")...)},
+	}
+	info.Servers[0].Drives[0].Healing = true
+
+	sets := info.ErasureSets()
+
+	statuses := make([]string, 0, len(sets))
+	for _, set := range sets {
+		statuses = append(statuses, set.Status)
+	}
+	// Sets of 4 drives with parity 2 read with 2 and write with 3 drives online
+	assert.Equal(t, []string{SetStatusDegraded, SetStatusReadOnly, SetStatusUnavailable, SetStatusDegraded}, statuses)
+	assert.Equal(t, ErasureSet{Pool: 1, Set: 0, Drives: 4, Online: 4, Healing: 1, Parity: 2, Status: SetStatusDegraded}, sets[3])
+}
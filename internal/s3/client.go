@@ -0,0 +1,394 @@
+// Package s3 provides a minimal S3 client (AWS Signature Version 4, path-style requests)
+// for the object storage backing the snapshot repository, e.g. MinIO.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/readonly"
+)
+
+const (
+	// DefaultRegion is used when no region is configured (MinIO accepts any region by default)
+	DefaultRegion = "us-east-1"
+
+	// requestTimeout bounds every S3 request
+	requestTimeout = 30 * time.Second
+
+	amzDateFormat  = "20060102T150405Z"
+	amzShortFormat = "20060102"
+	// emptyPayloadHash is the SHA-256 of an empty request body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Client is a minimal S3 client using path-style addressing
+type Client struct {
+	endpoint   *url.URL
+	accessKey  string
+	secretKey  string
+	region     string
+	httpClient *http.Client
+	now        func() time.Time
+	partSize   int
+}
+
+// Error is returned when S3 responds with an unexpected status code
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("s3 returned %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("s3 returned status %d", e.StatusCode)
+}
+
+// NewClient creates an S3 client. The endpoint may omit the scheme, in which case http is used
+// (the in-cluster MinIO endpoint is usually configured as host:port).
+func NewClient(endpoint, accessKey, secretKey, region string, opts ...Option) (*Client, error) {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint '%s': %w", endpoint, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint '%s': missing host", endpoint)
+	}
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	client := &Client{
+		endpoint:   u,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+		region:     region,
+		httpClient: &http.Client{Timeout: requestTimeout},
+		now:        time.Now,
+		partSize:   PartSize,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client, nil
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithReadOnly refuses every request that could change the bucket, such as writing or deleting objects, with an
+// error matching readonly.ErrReadOnly. It is a no-op unless enabled.
+func WithReadOnly(enabled bool) Option {
+	return func(c *Client) {
+		if enabled {
+			c.httpClient.Transport = &readonly.Transport{Next: transportOrDefault(c.httpClient.Transport)}
+		}
+	}
+}
+
+// transportOrDefault returns rt, or http.DefaultTransport when rt is nil
+func transportOrDefault(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// EndpointURL returns the URL of an endpoint configured as host:port, e.g. the snapshot repository endpoint whose
+// protocol is configured separately. Endpoints that already have a scheme are returned as they are.
+func EndpointURL(protocol, endpoint string) string {
+	if strings.Contains(endpoint, "://") || protocol == "" {
+		return endpoint
+	}
+	return protocol + "://" + endpoint
+}
+
+// Object is an entry of a bucket listing
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// HeadBucket checks that the bucket exists and the credentials are allowed to access it
+func (c *Client) HeadBucket(bucket string) error {
+	res, err := c.do(http.MethodHead, "/"+bucket, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return &Error{StatusCode: res.StatusCode, Code: "NoSuchBucket", Message: fmt.Sprintf("bucket '%s' does not exist", bucket)}
+	case http.StatusForbidden:
+		return &Error{StatusCode: res.StatusCode, Code: "AccessDenied", Message: "credentials are invalid or not allowed to access the bucket"}
+	default:
+		return &Error{StatusCode: res.StatusCode}
+	}
+}
+
+// PutObject stores body under key in the bucket
+func (c *Client) PutObject(bucket, key string, body []byte) error {
+	res, err := c.do(http.MethodPut, "/"+bucket+"/"+key, nil, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	return nil
+}
+
+// GetObject returns the contents of the object stored under key in the bucket
+func (c *Client) GetObject(bucket, key string) ([]byte, error) {
+	res, err := c.do(http.MethodGet, "/"+bucket+"/"+key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, responseError(res)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// DeleteObject removes the object stored under key from the bucket. Deleting a missing object succeeds.
+func (c *Client) DeleteObject(bucket, key string) error {
+	res, err := c.do(http.MethodDelete, "/"+bucket+"/"+key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	return nil
+}
+
+// ListObjects lists all objects in the bucket whose key starts with prefix, following continuation tokens
+func (c *Client) ListObjects(bucket, prefix string) ([]Object, error) {
+	var objects []Object
+	continuationToken := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		page, err := c.listObjectsPage(bucket, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, content := range page.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = page.NextContinuationToken
+	}
+}
+
+// listBucketResult is a page of a ListObjectsV2 response
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+func (c *Client) listObjectsPage(bucket string, query url.Values) (*listBucketResult, error) {
+	res, err := c.do(http.MethodGet, "/"+bucket, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, responseError(res)
+	}
+	var page listBucketResult
+	if err := xml.NewDecoder(res.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket listing: %w", err)
+	}
+	return &page, nil
+}
+
+// responseError builds an Error from an S3 XML error response
+func responseError(res *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.NewDecoder(res.Body).Decode(&body)
+	return &Error{StatusCode: res.StatusCode, Code: body.Code, Message: body.Message}
+}
+
+// do sends a signed request with an optional body
+func (c *Client) do(method, path string, query url.Values, body []byte) (*http.Response, error) {
+	return c.doWithHeader(method, path, query, nil, body)
+}
+
+// doWithHeader sends a signed request with additional headers, e.g. Range, and an optional body
+func (c *Client) doWithHeader(method, path string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(c.endpoint.Path, "/") + path
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(context.Background(), method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		payloadHash = hexSHA256(string(body))
+	}
+	c.sign(req, payloadHash)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach S3 endpoint %s: %w", c.endpoint.Host, err)
+	}
+	return res, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (c *Client) sign(req *http.Request, payloadHash string) {
+	now := c.now().UTC()
+	amzDate := now.Format(amzDateFormat)
+	shortDate := now.Format(amzShortFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{shortDate, c.region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), shortDate)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalHeaders returns the signed header list and canonical header block (host and x-amz-* headers)
+func canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// canonicalPath returns the URI-encoded path
+func canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalQuery returns the query string sorted by key with RFC 3986 encoding
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encodes s as required by Signature Version 4 (spaces as %20, unreserved characters kept)
+func uriEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hexSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = io.WriteString(mac, data)
+	return mac.Sum(nil)
+}
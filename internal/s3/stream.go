@@ -0,0 +1,228 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PartSize is the size of the parts PutObjectStream uploads and of the ranges OpenObject reads. S3 requires parts of
+// at least 5 MiB, except for the last one.
+const PartSize = 8 << 20
+
+// PutObjectStream stores what is read from r under key in the bucket with a multipart upload, so objects of any size
+// are stored holding a single part in memory. The upload is aborted when reading r or uploading a part fails.
+// It returns the size of the object.
+func (c *Client) PutObjectStream(bucket, key string, r io.Reader) (int64, error) {
+	path := "/" + bucket + "/" + key
+	uploadID, err := c.createMultipartUpload(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var parts []completedPart
+	var size int64
+	buf := make([]byte, c.partSize)
+	for {
+		n, readErr := io.ReadFull(r, buf)
+		// An empty stream is stored as a single empty part
+		if n > 0 || len(parts) == 0 {
+			number := len(parts) + 1
+			etag, err := c.uploadPart(path, uploadID, number, buf[:n])
+			if err != nil {
+				c.abortMultipartUpload(path, uploadID)
+				return 0, err
+			}
+			parts = append(parts, completedPart{PartNumber: number, ETag: etag})
+			size += int64(n)
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			c.abortMultipartUpload(path, uploadID)
+			return 0, fmt.Errorf("failed to read object %s: %w", key, readErr)
+		}
+	}
+
+	if err := c.completeMultipartUpload(path, uploadID, parts); err != nil {
+		c.abortMultipartUpload(path, uploadID)
+		return 0, err
+	}
+	return size, nil
+}
+
+// completedPart is a part of a CompleteMultipartUpload request
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (c *Client) createMultipartUpload(path string) (string, error) {
+	res, err := c.do(http.MethodPost, path, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", responseError(res)
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode multipart upload of %s: %w", path, err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("no upload ID in multipart upload of %s", path)
+	}
+	return result.UploadID, nil
+}
+
+func (c *Client) uploadPart(path, uploadID string, number int, body []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	res, err := c.do(http.MethodPut, path, query, body)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", responseError(res)
+	}
+	return res.Header.Get("ETag"), nil
+}
+
+func (c *Client) completeMultipartUpload(path, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("failed to encode multipart upload of %s: %w", path, err)
+	}
+
+	res, err := c.do(http.MethodPost, path, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	// S3 reports errors while completing the upload in the body of a 200 response
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(res.Body).Decode(&result); err == nil && result.XMLName.Local == "Error" {
+		return &Error{StatusCode: res.StatusCode, Code: result.Code, Message: result.Message}
+	}
+	return nil
+}
+
+// abortMultipartUpload removes the parts of a failed upload. Failures are ignored, the bucket lifecycle is left to
+// clean up the parts.
+func (c *Client) abortMultipartUpload(path, uploadID string) {
+	res, err := c.do(http.MethodDelete, path, url.Values{"uploadId": {uploadID}}, nil)
+	if err == nil {
+		_ = res.Body.Close()
+	}
+}
+
+// OpenObject returns a reader of the object stored under key in the bucket that fetches it in ranges of PartSize, so
+// objects of any size are read holding a single range in memory and no request outlives the request timeout. The
+// first range is fetched right away, so a missing object is reported by OpenObject.
+func (c *Client) OpenObject(bucket, key string) (io.Reader, error) {
+	reader := &objectReader{client: c, path: "/" + bucket + "/" + key, size: -1}
+	if err := reader.fetch(); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// objectReader reads an object range by range
+type objectReader struct {
+	client *Client
+	path   string
+	offset int64
+	// size is the size of the object, -1 until the first range is fetched
+	size int64
+	buf  []byte
+}
+
+// Read implements io.Reader
+func (r *objectReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.offset >= r.size {
+			return 0, io.EOF
+		}
+		if err := r.fetch(); err != nil {
+			return 0, err
+		}
+		if len(r.buf) == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// fetch reads the range starting at the offset
+func (r *objectReader) fetch() error {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", r.offset, r.offset+int64(r.client.partSize)-1)}}
+	res, err := r.client.doWithHeader(http.MethodGet, r.path, nil, header, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		size, err := contentRangeSize(res.Header.Get("Content-Range"))
+		if err != nil {
+			return err
+		}
+		r.size = size
+	case http.StatusOK:
+		// The whole object, from a server that ignores ranges
+		r.size = -1
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The range starts past the end, only the case for an empty object
+		r.size = r.offset
+		return nil
+	default:
+		return responseError(res)
+	}
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(res.Body); err != nil {
+		return fmt.Errorf("failed to read object %s: %w", r.path, err)
+	}
+	r.buf = buf.Bytes()
+	r.offset += int64(len(r.buf))
+	if r.size < 0 {
+		r.size = r.offset
+	}
+	return nil
+}
+
+// contentRangeSize returns the size of the object from a Content-Range header, e.g. "bytes 0-99/1234"
+func contentRangeSize(contentRange string) (int64, error) {
+	_, total, found := strings.Cut(contentRange, "/")
+	size, err := strconv.ParseInt(total, 10, 64)
+	if !found || err != nil {
+		return 0, fmt.Errorf("invalid Content-Range '%s'", contentRange)
+	}
+	return size, nil
+}
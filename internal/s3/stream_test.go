@@ -0,0 +1,162 @@
+package s3
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// multipartServer is an S3 endpoint supporting multipart uploads and ranged reads
+type multipartServer struct {
+	t       *testing.T
+	parts   map[string][][]byte
+	stored  map[string][]byte
+	aborted []string
+	// failPart is the number of the part whose upload fails, 0 for none
+	failPart int
+}
+
+func (s *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.parts[r.URL.Path] = nil
+		_, _ = w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>upload-1</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut && query.Get("uploadId") == "upload-1":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		if number == s.failPart {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<Error><Code>InternalError</Code><Message>We encountered an internal error.</Message></Error>`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(s.t, hexSHA256(string(body)), r.Header.Get("X-Amz-Content-Sha256"))
+		assert.Len(s.t, s.parts[r.URL.Path], number-1, "parts are uploaded in order")
+		s.parts[r.URL.Path] = append(s.parts[r.URL.Path], body)
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+	case r.Method == http.MethodPost && query.Get("uploadId") == "upload-1":
+		var complete struct {
+			Parts []completedPart `xml:"Part"`
+		}
+		require.NoError(s.t, xml.NewDecoder(r.Body).Decode(&complete))
+		require.Len(s.t, complete.Parts, len(s.parts[r.URL.Path]))
+		for i, part := range complete.Parts {
+			assert.Equal(s.t, fmt.Sprintf(`"etag-%d"`, i+1), part.ETag)
+		}
+		s.stored[r.URL.Path] = bytes.Join(s.parts[r.URL.Path], nil)
+		_, _ = w.Write([]byte(`<CompleteMultipartUploadResult></CompleteMultipartUploadResult>`))
+	case r.Method == http.MethodDelete && query.Get("uploadId") == "upload-1":
+		s.aborted = append(s.aborted, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet:
+		s.serveRange(w, r)
+	}
+}
+
+func (s *multipartServer) serveRange(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.stored[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`))
+		return
+	}
+	var start, end int
+	_, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+	require.NoError(s.t, err)
+	if start >= len(body) {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	end = min(end, len(body)-1)
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(body)))
+	w.WriteHeader(http.StatusPartialContent)
+	_, _ = w.Write(body[start : end+1])
+}
+
+func newMultipartServer(t *testing.T) (*multipartServer, *Client) {
+	s := &multipartServer{t: t, parts: map[string][][]byte{}, stored: map[string][]byte{}}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, "key", "secret", "")
+	require.NoError(t, err)
+	client.partSize = 4
+	return s, client
+}
+
+func TestClient_PutObjectStreamAndOpenObject(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		expectedParts int
+	}{
+		{name: "several parts", body: "0123456789", expectedParts: 3},
+		{name: "exact parts", body: "01234567", expectedParts: 2},
+		{name: "empty", body: "", expectedParts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newMultipartServer(t)
+
+			size, err := client.PutObjectStream("sts-backup", "dumps/a.sql.gz", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.body)), size)
+			assert.Len(t, server.parts["/sts-backup/dumps/a.sql.gz"], tt.expectedParts)
+			assert.Equal(t, tt.body, string(server.stored["/sts-backup/dumps/a.sql.gz"]))
+
+			reader, err := client.OpenObject("sts-backup", "dumps/a.sql.gz")
+			require.NoError(t, err)
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(data))
+		})
+	}
+}
+
+func TestClient_PutObjectStream_Aborts(t *testing.T) {
+	t.Run("failed part", func(t *testing.T) {
+		server, client := newMultipartServer(t)
+		server.failPart = 2
+
+		_, err := client.PutObjectStream("sts-backup", "dumps/a.sql.gz", strings.NewReader("0123456789"))
+
+		var s3Err *Error
+		require.ErrorAs(t, err, &s3Err)
+		assert.Equal(t, "InternalError", s3Err.Code)
+		assert.Equal(t, []string{"/sts-backup/dumps/a.sql.gz"}, server.aborted)
+		assert.NotContains(t, server.stored, "/sts-backup/dumps/a.sql.gz")
+	})
+
+	t.Run("failed read", func(t *testing.T) {
+		server, client := newMultipartServer(t)
+		reader := io.MultiReader(strings.NewReader("01234"), iotest.ErrReader(errors.New("pg_dump failed")))
+
+		_, err := client.PutObjectStream("sts-backup", "dumps/a.sql.gz", reader)
+
+		require.ErrorContains(t, err, "pg_dump failed")
+		assert.Equal(t, []string{"/sts-backup/dumps/a.sql.gz"}, server.aborted)
+		assert.NotContains(t, server.stored, "/sts-backup/dumps/a.sql.gz")
+	})
+}
+
+func TestClient_OpenObject_Missing(t *testing.T) {
+	_, client := newMultipartServer(t)
+
+	_, err := client.OpenObject("sts-backup", "dumps/missing.sql.gz")
+
+	var s3Err *Error
+	require.ErrorAs(t, err, &s3Err)
+	assert.Equal(t, "NoSuchKey", s3Err.Code)
+}
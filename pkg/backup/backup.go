@@ -0,0 +1,182 @@
+// Package backup is the Go API of sts-backup, for tools such as the operator that embed backup and restore of
+// SUSE Observability instead of running the CLI. Functions return errors instead of exiting the process and never
+// prompt; ExitCode maps an error to the exit code the CLI would have exited with.
+package backup
+
+import (
+	"errors"
+	"time"
+
+	escmd "github.com/stackvista/stackstate-backup-cli/cmd/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/audit"
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/k8s"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+)
+
+// Errors returned by the functions of this package can be matched with errors.Is against these errors
+var (
+	// ErrSnapshotNotFound is returned when the snapshot does not exist in the restore repository
+	ErrSnapshotNotFound = elasticsearch.ErrSnapshotNotFound
+	// ErrRepositoryMissing is returned when the snapshot repository is not registered in Elasticsearch
+	ErrRepositoryMissing = elasticsearch.ErrRepositoryMissing
+	// ErrClusterUnhealthy is returned when the Elasticsearch pods do not have all replicas Ready or are crash looping
+	ErrClusterUnhealthy = k8s.ErrClusterUnhealthy
+	// ErrPortForwardFailed is returned when the port-forward to Elasticsearch cannot be set up
+	ErrPortForwardFailed = k8s.ErrPortForwardFailed
+)
+
+// Options selects the SUSE Observability installation to operate on, like the global flags of the CLI
+type Options struct {
+	// Namespace of the installation (required)
+	Namespace string
+	// Kubeconfig is the kubeconfig file (default: $KUBECONFIG or ~/.kube/config)
+	Kubeconfig string
+	// ConfigMapName and SecretName hold the backup configuration (default: as the CLI)
+	ConfigMapName string
+	SecretName    string
+	// LogLevel of the messages written to stderr: error, warn, info, debug or trace (default: error)
+	LogLevel string
+	// RunID identifies the operation in logs, Events and the audit log (default: a new run ID)
+	RunID string
+}
+
+// Snapshot is an Elasticsearch snapshot in the restore repository
+type Snapshot struct {
+	Name      string
+	State     string
+	StartTime time.Time
+	EndTime   time.Time
+	Indices   []string
+	// FailedShards is the number of shards that could not be snapshotted
+	FailedShards int
+}
+
+// ProgressFunc is called after every step of a restore, such as "Scale down deployments" or "Restore snapshot",
+// with the time the step took
+type ProgressFunc func(step string, duration time.Duration)
+
+// RestoreOptions are the options of a restore, like the flags of restore-snapshot
+type RestoreOptions struct {
+	SnapshotName string
+	// DropAllIndices deletes the existing STS indices before the restore, after a safety snapshot of them unless
+	// SkipSafetySnapshot is set
+	DropAllIndices     bool
+	SkipSafetySnapshot bool
+	// ExcludeIndices lists indices or patterns of the snapshot that are not restored
+	ExcludeIndices []string
+	// Domains limit the restore to the indices of these data domains, such as topology, see restore-snapshot --domain
+	Domains []string
+	// FeatureStates overrides the feature states to restore from the configuration
+	FeatureStates []string
+	// IgnoreUnavailable skips indices of the restore pattern that are missing in the snapshot
+	IgnoreUnavailable bool
+	// AllowPartial restores a snapshot with failed shards, leaving out the data of those shards
+	AllowPartial bool
+	// Progress, when set, is called after every step of the restore
+	Progress ProgressFunc
+}
+
+// ListSnapshots returns the snapshots in the restore repository of the installation
+func ListSnapshots(opts Options) ([]Snapshot, error) {
+	cliCtx, err := newContext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := escmd.ListSnapshots(cliCtx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Snapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		result = append(result, newSnapshot(snapshot))
+	}
+	return result, nil
+}
+
+// Restore restores a snapshot into the installation like restore-snapshot --yes: the deployments writing to
+// Elasticsearch are scaled down during the restore and up again afterwards, also when it fails
+func Restore(opts Options, restore RestoreOptions) error {
+	if restore.SnapshotName == "" {
+		return exitcode.Wrap(exitcode.Usage, errors.New("snapshot name is required"))
+	}
+	cliCtx, err := newContext(opts)
+	if err != nil {
+		return err
+	}
+
+	return escmd.Restore(cliCtx, escmd.RestoreRequest{
+		SnapshotName:       restore.SnapshotName,
+		DropAllIndices:     restore.DropAllIndices,
+		SkipSafetySnapshot: restore.SkipSafetySnapshot,
+		ExcludeIndices:     restore.ExcludeIndices,
+		Domains:            restore.Domains,
+		FeatureStates:      restore.FeatureStates,
+		IgnoreUnavailable:  restore.IgnoreUnavailable,
+		AllowPartial:       restore.AllowPartial,
+		Progress:           restore.Progress,
+	})
+}
+
+// ExitCode returns the exit code of the CLI for an error returned by this package: 0 for nil, 2 for invalid
+// options, 3 for configuration errors, 4 for connectivity errors, 5 for failed validation, 6 for an incomplete
+// restore and 1 otherwise
+func ExitCode(err error) int {
+	return exitcode.Of(err)
+}
+
+// newContext returns the context of the CLI that corresponds to opts
+func newContext(opts Options) (*config.Context, error) {
+	if opts.Namespace == "" {
+		return nil, exitcode.Wrap(exitcode.Usage, errors.New("namespace is required"))
+	}
+
+	cliCtx := config.NewContext()
+	if opts.RunID != "" {
+		cliCtx.RunID = opts.RunID
+	}
+	cliCtx.Config.LogLevel = logger.LevelError
+	if opts.LogLevel != "" {
+		level, err := logger.ParseLevel(opts.LogLevel)
+		if err != nil {
+			return nil, exitcode.Wrap(exitcode.Usage, err)
+		}
+		cliCtx.Config.LogLevel = level
+	}
+
+	cliCtx.Config.Namespace = opts.Namespace
+	cliCtx.Config.Kubeconfig = opts.Kubeconfig
+	cliCtx.Config.ConfigMapName = valueOr(opts.ConfigMapName, config.DefaultConfigMapName)
+	cliCtx.Config.SecretName = valueOr(opts.SecretName, config.DefaultSecretName)
+	cliCtx.Config.AuditConfigMapName = audit.DefaultConfigMapName
+	cliCtx.Config.MaxRequestsPerSecond = elasticsearch.DefaultMaxRequestsPerSecond
+	return cliCtx, nil
+}
+
+// newSnapshot converts an Elasticsearch snapshot
+func newSnapshot(snapshot elasticsearch.Snapshot) Snapshot {
+	result := Snapshot{
+		Name:         snapshot.Snapshot,
+		State:        snapshot.State,
+		Indices:      snapshot.Indices,
+		FailedShards: snapshot.Shards.Failed,
+	}
+	if snapshot.StartTimeMillis > 0 {
+		result.StartTime = time.UnixMilli(snapshot.StartTimeMillis)
+	}
+	if snapshot.EndTimeMillis > 0 {
+		result.EndTime = time.UnixMilli(snapshot.EndTimeMillis)
+	}
+	return result
+}
+
+// valueOr returns value, or def when it is empty
+func valueOr(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,79 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stackvista/stackstate-backup-cli/internal/config"
+	"github.com/stackvista/stackstate-backup-cli/internal/elasticsearch"
+	"github.com/stackvista/stackstate-backup-cli/internal/exitcode"
+	"github.com/stackvista/stackstate-backup-cli/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContext(t *testing.T) {
+	cliCtx, err := newContext(Options{Namespace: "suse-observability", LogLevel: "debug", RunID: "run-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "run-1", cliCtx.RunID)
+	assert.Equal(t, "suse-observability", cliCtx.Config.Namespace)
+	assert.Equal(t, logger.LevelDebug, cliCtx.Config.LogLevel)
+	assert.Equal(t, config.DefaultConfigMapName, cliCtx.Config.ConfigMapName)
+	assert.Equal(t, config.DefaultSecretName, cliCtx.Config.SecretName)
+	assert.InDelta(t, elasticsearch.DefaultMaxRequestsPerSecond, cliCtx.Config.MaxRequestsPerSecond, 0)
+
+	quiet, err := newContext(Options{Namespace: "suse-observability"})
+	require.NoError(t, err)
+	assert.Equal(t, logger.LevelError, quiet.Config.LogLevel)
+	assert.NotEmpty(t, quiet.RunID)
+}
+
+func TestNewContext_Invalid(t *testing.T) {
+	_, err := newContext(Options{})
+	assert.Equal(t, exitcode.Usage, ExitCode(err))
+
+	_, err = newContext(Options{Namespace: "suse-observability", LogLevel: "loud"})
+	assert.Equal(t, exitcode.Usage, ExitCode(err))
+}
+
+func TestRestore_RequiresSnapshotName(t *testing.T) {
+	err := Restore(Options{Namespace: "suse-observability"}, RestoreOptions{})
+	assert.Equal(t, exitcode.Usage, ExitCode(err))
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("boom")))
+	assert.Equal(t, exitcode.PartialRestore, ExitCode(exitcode.Wrap(exitcode.PartialRestore, errors.New("incomplete"))))
+}
+
+func TestNewSnapshot(t *testing.T) {
+	start := time.Date(2025, 3, 5, 3, 0, 0, 0, time.UTC)
+	snapshot := elasticsearch.Snapshot{
+		Snapshot:        "sts-backup-20250305-0300",
+		State:           "PARTIAL",
+		StartTimeMillis: start.UnixMilli(),
+		EndTimeMillis:   start.Add(time.Minute).UnixMilli(),
+		Indices:         []string{"sts_topology"},
+	}
+	snapshot.Shards.Failed = 2
+
+	result := newSnapshot(snapshot)
+	assert.Equal(t, "sts-backup-20250305-0300", result.Name)
+	assert.Equal(t, "PARTIAL", result.State)
+	assert.True(t, start.Equal(result.StartTime))
+	assert.Equal(t, time.Minute, result.EndTime.Sub(result.StartTime))
+	assert.Equal(t, []string{"sts_topology"}, result.Indices)
+	assert.Equal(t, 2, result.FailedShards)
+
+	assert.True(t, newSnapshot(elasticsearch.Snapshot{Snapshot: "in-progress"}).EndTime.IsZero())
+}
+
+func TestErrors(t *testing.T) {
+	assert.ErrorIs(t, fmt.Errorf("failed to get snapshot: %w", elasticsearch.ErrSnapshotNotFound), ErrSnapshotNotFound)
+	assert.ErrorIs(t, ErrRepositoryMissing, elasticsearch.ErrNotFound)
+	assert.NotErrorIs(t, ErrRepositoryMissing, ErrSnapshotNotFound)
+}